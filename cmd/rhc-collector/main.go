@@ -5,10 +5,13 @@ import (
 	"log/slog"
 	"os"
 	"os/exec"
+	"slices"
 	"time"
 
 	"github.com/redhatinsights/rhc/internal/collector"
 	httpapi "github.com/redhatinsights/rhc/internal/http"
+	"github.com/redhatinsights/rhc/internal/logging"
+	"github.com/redhatinsights/rhc/internal/schedule"
 	"github.com/redhatinsights/rhc/pkg/exitcode"
 	"github.com/redhatinsights/rhc/pkg/version"
 )
@@ -22,19 +25,23 @@ const (
 )
 
 func main() {
-	if len(os.Args) <= 2 {
-		slog.Error("usage: rhc-collector COMMAND COLLECTOR-ID")
+	args := os.Args[1:]
+	// Runs activated by the systemd timer are delayed by the schedule of the collector
+	scheduled := slices.Contains(args, "--scheduled")
+	args = slices.DeleteFunc(args, func(arg string) bool { return arg == "--scheduled" })
+	if len(args) < 2 {
+		slog.Error("usage: rhc-collector COMMAND [--scheduled] COLLECTOR-ID")
 		os.Exit(exitcode.Usage)
 	}
-	command, collectorId := os.Args[1], os.Args[2]
+	command, collectorId := args[0], args[1]
 	slog.Info("starting rhc-collector", slog.String("id", collectorId))
-	if err := run(collectorId, command); err != nil {
+	if err := run(collectorId, command, scheduled); err != nil {
 		slog.Error("rhc-collector exited with error", "error", err)
 		os.Exit(exitcode.Err)
 	}
 }
 
-func run(collectorId, command string) error {
+func run(collectorId, command string, scheduled bool) error {
 	collectorId, err := collector.ValidateID(collectorId)
 	if err != nil {
 		slog.Error("invalid collector ID", "error", err)
@@ -51,6 +58,10 @@ func run(collectorId, command string) error {
 		return err
 	}
 
+	if scheduled {
+		waitForSchedule(config.Schedule)
+	}
+
 	tmpDir, err := createTmpDir()
 	if err != nil {
 		return err
@@ -71,6 +82,17 @@ func run(collectorId, command string) error {
 	return nil
 }
 
+// waitForSchedule delays the run by the splay of the host and a random jitter,
+// so collectors of many hosts activated at the same time do not upload at once.
+func waitForSchedule(s schedule.Schedule) {
+	delay := s.NextDelay(schedule.HostID(), true)
+	if delay <= 0 {
+		return
+	}
+	slog.Info("delaying scheduled run", "delay", delay.String(), "splay", s.Splay.String(), "jitter", s.Jitter.String())
+	time.Sleep(delay)
+}
+
 // createTmpDir ensures rhcTmpDir exists with root-only permissions (0700)
 // and creates a collector-specific temporary directory inside it. If the
 // parent directory exists with different permissions, they are reset to
@@ -135,9 +157,10 @@ func executeCollector(collectorId, tmpDir string) error {
 	cmd.Dir = tmpDir
 
 	// Capture start/end time and execute the command
-	startTime := time.Now()
+	startTime := logging.CommandStart(cmd)
 	output, err := cmd.CombinedOutput()
 	endTime := time.Now()
+	logging.Command(cmd, startTime, err)
 
 	exitCode := 0
 	if err != nil {
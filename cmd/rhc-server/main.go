@@ -10,11 +10,13 @@ import (
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/coreos/go-systemd/v22/activation"
 	govarlink "github.com/emersion/go-varlink"
 	"github.com/redhatinsights/rhc/varlink/rhsmapi"
 
+	"github.com/redhatinsights/rhc/internal/logging"
 	"github.com/redhatinsights/rhc/internal/util"
 	"github.com/redhatinsights/rhc/pkg/exitcode"
 	"github.com/redhatinsights/rhc/pkg/version"
@@ -31,9 +33,17 @@ const (
 	// Channel buffer sizes for graceful shutdown
 	signalChanBuffer = 1
 	errorChanBuffer  = 1
+
+	// Identical warnings and errors are logged at most once per interval
+	logRateLimitInterval = time.Minute
 )
 
 func main() {
+	// Prevent flooding the journal with identical errors during extended outages
+	handler := slog.NewTextHandler(os.Stderr, nil)
+	rateLimit := logging.NewRateLimitHandler(handler, logRateLimitInterval)
+	slog.SetDefault(slog.New(rateLimit))
+
 	// Acquire PID lock to ensure at most one instance runs at any given time
 	cleanup, err := acquirePIDLock()
 	if err != nil {
@@ -42,8 +52,13 @@ func main() {
 	}
 	defer cleanup()
 
-	if err := run(); err != nil {
+	err = run()
+	if err != nil {
 		slog.Error("rhc-server error", "error", err)
+	}
+	// Write the summaries of suppressed records before exiting
+	_ = rateLimit.Close()
+	if err != nil {
 		os.Exit(exitcode.Err)
 	}
 }
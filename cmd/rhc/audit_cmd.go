@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/audit"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// identitySigner returns the identity key of the host used to sign audit
+// entries and results, together with the identity certificate.
+func identitySigner() (crypto.Signer, *x509.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(subman.ConsumerCertPath, subman.ConsumerKeyPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot load identity key: %w", err)
+	}
+	signer, ok := cert.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("identity key cannot be used for signing")
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse identity certificate: %w", err)
+	}
+	return signer, leaf, nil
+}
+
+// rhsmCARoots returns the CA certificates trusted by RHSM, the issuers of
+// identity certificates. System CAs are not included, as certificates they
+// issue do not identify hosts.
+func rhsmCARoots() *x509.CertPool {
+	caCertDir := subman.DefaultCACertDir
+	if client, err := subman.NewRHSMClient(); err == nil {
+		if dir, err := client.CACertDir(); err == nil {
+			caCertDir = dir
+		}
+	}
+	roots := x509.NewCertPool()
+	paths, _ := filepath.Glob(filepath.Join(caCertDir, "*.pem"))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			slog.Debug("Unable to read CA certificate", "path", path, "err", err)
+			continue
+		}
+		roots.AppendCertsFromPEM(data)
+	}
+	return roots
+}
+
+// beforeAuditVerifyAction ensures the user has supplied a correct `--format` flag.
+func beforeAuditVerifyAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// auditVerifyAction verifies signatures of the audit log entries with the
+// identity certificates embedded in the log, issued by the CAs trusted by RHSM.
+func auditVerifyAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot verify audit log", exitcode.NoPerm)
+	}
+
+	result, err := audit.Verify(AuditLogPath, rhsmCARoots())
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+	for _, problem := range result.Problems {
+		slog.Warn("Audit log entry failed verification", "line", problem.Line, "problem", problem.Message)
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print verification result as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+	} else {
+		for _, problem := range result.Problems {
+			ui.Printf("%s[%v] Line %d: %s\n", ui.Indent.Small, ui.Icons.Error, problem.Line, problem.Message)
+		}
+		icon := ui.Icons.Ok
+		if len(result.Problems) > 0 {
+			icon = ui.Icons.Error
+		}
+		ui.Printf(
+			"%s[%v] %d of %d entries signed, %d failed verification\n",
+			ui.Indent.Small, icon, result.Signed, result.Entries, len(result.Problems),
+		)
+	}
+
+	if len(result.Problems) > 0 {
+		return cli.Exit("", exitcode.DataErr)
+	}
+	return nil
+}
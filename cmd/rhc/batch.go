@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+	"github.com/redhatinsights/rhc/pkg/interactive"
+)
+
+// MissingInputsResult is structure holding the inputs that rhc would prompt
+// for, when --batch is used. The result could be printed in machine-readable format.
+type MissingInputsResult struct {
+	Error         string   `json:"error"`
+	MissingInputs []string `json:"missing_inputs"`
+}
+
+// isBatch reports whether prompts are disabled by --batch.
+func isBatch(cmd *cli.Command) bool {
+	return cmd.Bool("batch")
+}
+
+// missingConnectInputs returns the flags of the inputs 'rhc connect' would
+// prompt for.
+func missingConnectInputs(username, password string, activationKeys []string) []string {
+	if len(activationKeys) > 0 {
+		return nil
+	}
+	var missing []string
+	if username == "" {
+		missing = append(missing, "--username")
+	}
+	if username == "" || password == "" {
+		missing = append(missing, "--password")
+	}
+	return missing
+}
+
+// missingInputsError returns the error reported instead of prompting for the
+// missing inputs. In machine-readable format, the inputs are printed as well.
+func missingInputsError(missing []string) error {
+	message := fmt.Sprintf(
+		"missing required input %s: prompts are disabled by --batch",
+		strings.Join(missing, ", "),
+	)
+	if ui.IsOutputMachineReadable() {
+		_ = ui.PrintJSON(MissingInputsResult{Error: message, MissingInputs: missing})
+	}
+	return cli.Exit(message, exitcode.Usage)
+}
+
+// confirmOperation asks the user to confirm the operation described by question
+// and details, see interactive.Confirm. An error is returned, when the operation
+// is not confirmed.
+func confirmOperation(question string, defaultYes bool, details ...string) error {
+	confirmed, err := interactive.Confirm(question, defaultYes, details...)
+	if err != nil {
+		return cli.Exit(err.Error(), exitcode.Usage)
+	}
+	if !confirmed {
+		return cli.Exit("the operation was canceled", exitcode.Usage)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMissingConnectInputs(t *testing.T) {
+	tests := []struct {
+		description    string
+		username       string
+		password       string
+		activationKeys []string
+		want           []string
+	}{
+		{description: "no credentials", want: []string{"--username", "--password"}},
+		{description: "username only", username: "user", want: []string{"--password"}},
+		// The password is not used without the username it belongs to
+		{description: "password only", password: "secret", want: []string{"--username", "--password"}},
+		{description: "username and password", username: "user", password: "secret"},
+		{description: "activation key", activationKeys: []string{"key"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := missingConnectInputs(test.username, test.password, test.activationKeys)
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+
+	"github.com/pelletier/go-toml"
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// BootstrapPath is the bootstrap file consumed by 'rhc connect --from-bootstrap'.
+// Image-based provisioning pipelines place it into the image, and the file is
+// shredded once the system is connected.
+const BootstrapPath = "/etc/rhc/bootstrap.toml"
+
+// Bootstrap is the content of the bootstrap file consumed by
+// 'rhc connect --from-bootstrap', provisioned into images of a fleet.
+type Bootstrap struct {
+	Organization   string
+	ActivationKeys []string
+	// ActivationKeyFile is the path to a file listing the activation keys, one
+	// per line, so the keys do not have to be written into the bootstrap file.
+	ActivationKeyFile string
+	// ServerURL is the URL of the entitlement server; the configured server is
+	// used when empty.
+	ServerURL       string
+	BaseURL         string
+	EnableFeatures  []string
+	DisableFeatures []string
+	Tags            map[string]string
+}
+
+// bootstrapConflicts are the flags of 'rhc connect' set by the bootstrap file.
+var bootstrapConflicts = []string{
+	"username", "password", "password-file", "organization", "activation-key", "activation-key-file",
+	"enable-feature", "disable-feature", "server-url", "base-url",
+}
+
+// loadBootstrap reads the bootstrap file at path. The file has to set
+// 'organization', and either 'activation-keys' or 'activation-key-file'.
+func loadBootstrap(path string) (*Bootstrap, error) {
+	tree, err := toml.LoadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read bootstrap file: %w", err)
+	}
+
+	var bootstrap Bootstrap
+	for _, option := range []struct {
+		key   string
+		value *string
+	}{
+		{key: "organization", value: &bootstrap.Organization},
+		{key: "activation-key-file", value: &bootstrap.ActivationKeyFile},
+		{key: "server-url", value: &bootstrap.ServerURL},
+		{key: "base-url", value: &bootstrap.BaseURL},
+	} {
+		value := tree.Get(option.key)
+		if value == nil {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid bootstrap file %s: '%s' has to be a string", path, option.key)
+		}
+		*option.value = str
+	}
+	for _, option := range []struct {
+		key   string
+		value *[]string
+	}{
+		{key: "activation-keys", value: &bootstrap.ActivationKeys},
+		{key: "enable-features", value: &bootstrap.EnableFeatures},
+		{key: "disable-features", value: &bootstrap.DisableFeatures},
+	} {
+		value := tree.Get(option.key)
+		if value == nil {
+			continue
+		}
+		items, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid bootstrap file %s: '%s' has to be an array of strings", path, option.key)
+		}
+		for _, item := range items {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid bootstrap file %s: '%s' has to be an array of strings", path, option.key)
+			}
+			*option.value = append(*option.value, str)
+		}
+	}
+	if bootstrap.Tags, err = getStringTable(tree, "tags"); err != nil {
+		return nil, fmt.Errorf("invalid bootstrap file %s: %w", path, err)
+	}
+
+	if bootstrap.Organization == "" || (len(bootstrap.ActivationKeys) == 0 && bootstrap.ActivationKeyFile == "") {
+		return nil, fmt.Errorf("invalid bootstrap file %s: 'organization' and 'activation-keys' have to be set", path)
+	}
+	if len(bootstrap.ActivationKeys) > 0 && bootstrap.ActivationKeyFile != "" {
+		return nil, fmt.Errorf("invalid bootstrap file %s: 'activation-keys' and 'activation-key-file' cannot be used together", path)
+	}
+	if bootstrap.ActivationKeyFile != "" {
+		if bootstrap.ActivationKeys, err = readSecretFile(bootstrap.ActivationKeyFile, parseActivationKeyList); err != nil {
+			return nil, fmt.Errorf("invalid bootstrap file %s: cannot read 'activation-key-file': %w", path, err)
+		}
+	}
+	if bootstrap.BaseURL != "" && bootstrap.ServerURL == "" {
+		return nil, fmt.Errorf("invalid bootstrap file %s: 'base-url' requires 'server-url'", path)
+	}
+	for _, option := range []struct{ key, value string }{
+		{key: "server-url", value: bootstrap.ServerURL},
+		{key: "base-url", value: bootstrap.BaseURL},
+	} {
+		if option.value == "" {
+			continue
+		}
+		if parsed, err := url.Parse(option.value); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid bootstrap file %s: '%s' has to be an https URL", path, option.key)
+		}
+	}
+	if err = checkFeatureFlags(bootstrap.EnableFeatures, bootstrap.DisableFeatures); err != nil {
+		return nil, fmt.Errorf("invalid bootstrap file %s: %w", path, err)
+	}
+	return &bootstrap, nil
+}
+
+// applyBootstrap sets the flags of 'rhc connect' from the bootstrap file, and
+// merges its tags into the configured ones. The flags set by the file cannot
+// be used together with --from-bootstrap.
+func applyBootstrap(cmd *cli.Command, path string) (*Bootstrap, error) {
+	for _, name := range bootstrapConflicts {
+		if cmd.IsSet(name) {
+			return nil, cli.Exit(fmt.Sprintf("--%s cannot be used with --from-bootstrap", name), exitcode.Usage)
+		}
+	}
+	bootstrap, err := loadBootstrap(path)
+	if err != nil {
+		return nil, cli.Exit(err.Error(), exitcode.Config)
+	}
+
+	flags := [][2]string{{"organization", bootstrap.Organization}}
+	for _, key := range bootstrap.ActivationKeys {
+		flags = append(flags, [2]string{"activation-key", key})
+	}
+	for _, id := range bootstrap.EnableFeatures {
+		flags = append(flags, [2]string{"enable-feature", id})
+	}
+	for _, id := range bootstrap.DisableFeatures {
+		flags = append(flags, [2]string{"disable-feature", id})
+	}
+	for _, flag := range flags {
+		if err = cmd.Set(flag[0], flag[1]); err != nil {
+			return nil, cli.Exit(err, exitcode.Software)
+		}
+	}
+
+	if len(bootstrap.Tags) > 0 && conf.Config.Tags == nil {
+		conf.Config.Tags = make(map[string]string)
+	}
+	for name, value := range bootstrap.Tags {
+		conf.Config.Tags[name] = value
+	}
+	return bootstrap, nil
+}
+
+// configureBootstrapServer configures the entitlement server of the bootstrap
+// file in rhsm.conf.
+func configureBootstrapServer(bootstrap *Bootstrap) error {
+	rhsmClient, err := subman.NewRHSMClient()
+	if err != nil {
+		return fmt.Errorf("unable to read RHSM configuration: %w", err)
+	}
+	if err = rhsmClient.SetServer(bootstrap.ServerURL, bootstrap.BaseURL); err != nil {
+		return fmt.Errorf("cannot configure entitlement server of bootstrap file: %w", err)
+	}
+	slog.Info("Configured entitlement server of bootstrap file", "server", bootstrap.ServerURL)
+	return nil
+}
+
+// shredFile overwrites the content of the file at path with zeros before it
+// removes the file, so the credentials it held cannot be recovered from the
+// file system.
+func shredFile(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	if _, err = file.Write(make([]byte, info.Size())); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
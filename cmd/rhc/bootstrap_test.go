@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadBootstrap(t *testing.T) {
+	tests := []struct {
+		description string
+		input       string
+		want        *Bootstrap
+		wantError   string
+	}{
+		{
+			description: "complete",
+			input: `organization = "1234"
+activation-keys = ["key1", "key2"]
+server-url = "https://satellite.example.com/rhsm"
+base-url = "https://satellite.example.com/pulp/content"
+disable-features = ["remote-management"]
+
+[tags]
+env = "production"
+`,
+			want: &Bootstrap{
+				Organization:    "1234",
+				ActivationKeys:  []string{"key1", "key2"},
+				ServerURL:       "https://satellite.example.com/rhsm",
+				BaseURL:         "https://satellite.example.com/pulp/content",
+				DisableFeatures: []string{"remote-management"},
+				Tags:            map[string]string{"env": "production"},
+			},
+		},
+		{
+			description: "minimal",
+			input:       "organization = \"1234\"\nactivation-keys = [\"key\"]\n",
+			want:        &Bootstrap{Organization: "1234", ActivationKeys: []string{"key"}},
+		},
+		{
+			description: "missing activation keys",
+			input:       "organization = \"1234\"\n",
+			wantError:   "'organization' and 'activation-keys' have to be set",
+		},
+		{
+			description: "base URL without server URL",
+			input:       "organization = \"1234\"\nactivation-keys = [\"key\"]\nbase-url = \"https://cdn.example.com\"\n",
+			wantError:   "'base-url' requires 'server-url'",
+		},
+		{
+			description: "both activation keys and activation key file",
+			input:       "organization = \"1234\"\nactivation-keys = [\"key\"]\nactivation-key-file = \"/etc/rhc/keys\"\n",
+			wantError:   "'activation-keys' and 'activation-key-file' cannot be used together",
+		},
+		{
+			description: "missing activation key file",
+			input:       "organization = \"1234\"\nactivation-key-file = \"/nonexistent/keys\"\n",
+			wantError:   "cannot read 'activation-key-file'",
+		},
+		{
+			description: "plain HTTP server URL",
+			input:       "organization = \"1234\"\nactivation-keys = [\"key\"]\nserver-url = \"http://satellite.example.com/rhsm\"\n",
+			wantError:   "'server-url' has to be an https URL",
+		},
+		{
+			description: "invalid activation keys",
+			input:       "organization = \"1234\"\nactivation-keys = \"key\"\n",
+			wantError:   "'activation-keys' has to be an array of strings",
+		},
+		{
+			description: "conflicting features",
+			input:       "organization = \"1234\"\nactivation-keys = [\"key\"]\nenable-features = [\"analytics\"]\ndisable-features = [\"analytics\"]\n",
+			wantError:   "invalid combination",
+		},
+		{
+			description: "invalid tags",
+			input:       "organization = \"1234\"\nactivation-keys = [\"key\"]\ntags = \"env\"\n",
+			wantError:   "'tags' has to be a table",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bootstrap.toml")
+			if err := os.WriteFile(path, []byte(test.input), 0600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := loadBootstrap(path)
+			if test.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantError) {
+					t.Fatalf("got error %v, want %q", err, test.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
+
+func TestLoadBootstrapActivationKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "keys")
+	if err := os.WriteFile(keyPath, []byte("# fleet keys\nkey1\nkey2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "bootstrap.toml")
+	input := "organization = \"1234\"\nactivation-key-file = \"" + keyPath + "\"\n"
+	if err := os.WriteFile(path, []byte(input), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loadBootstrap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Bootstrap{Organization: "1234", ActivationKeys: []string{"key1", "key2"}, ActivationKeyFile: keyPath}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
+
+func TestShredFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bootstrap.toml")
+	if err := os.WriteFile(path, []byte("organization = \"1234\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := shredFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v, want the file to be removed", err)
+	}
+	if err := shredFile(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v for a missing file, want os.ErrNotExist", err)
+	}
+}
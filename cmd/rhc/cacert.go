@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	httpapi "github.com/redhatinsights/rhc/internal/http"
+	"github.com/redhatinsights/rhc/internal/subman"
+)
+
+// customCACertName is the name of the CA certificate installed by 'rhc connect --ca-cert'
+// into the directory of CA certificates trusted by RHSM.
+const customCACertName = "rhc-custom-ca.pem"
+
+// installCACert validates the PEM-encoded CA certificate in src, and installs
+// it into dir as customCACertName. The path of the installed file is returned.
+func installCACert(src string, dir string) (string, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("cannot read CA certificate: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", fmt.Errorf("cannot read CA certificate: no PEM certificate found in %s", src)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("cannot read CA certificate: %w", err)
+	}
+	if !cert.IsCA {
+		return "", fmt.Errorf("certificate in %s is not a CA certificate", src)
+	}
+
+	path := filepath.Join(dir, customCACertName)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("cannot install CA certificate: %w", err)
+	}
+	return path, nil
+}
+
+// loadTrustedRoots returns the system CA certificates together with the
+// PEM files in dir, the CA certificates trusted by RHSM.
+func loadTrustedRoots(dir string) *x509.CertPool {
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		slog.Debug("Unable to load system CA certificates", "err", err)
+		roots = x509.NewCertPool()
+	}
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.pem"))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			slog.Debug("Unable to read CA certificate", "path", path, "err", err)
+			continue
+		}
+		roots.AppendCertsFromPEM(data)
+	}
+	return roots
+}
+
+// checkTLSInterception compares the certificate chain presented by the entitlement
+// server with the trusted CA certificates, and returns a warning when the connection
+// is intercepted by a proxy. Nil is returned when the check cannot be made; connection
+// errors are reported by the registration itself.
+func checkTLSInterception(client *subman.RHSMClient, caCertDir string) *Warning {
+	serverURL, err := client.ServerURL()
+	if err != nil {
+		slog.Debug("Unable to check TLS interception", "err", err)
+		return nil
+	}
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		slog.Debug("Unable to check TLS interception", "err", err)
+		return nil
+	}
+
+	chain, err := httpapi.ProbeTLS(context.Background(), serverURL)
+	if err != nil {
+		slog.Debug("Unable to check TLS interception", "url", serverURL, "err", err)
+		return nil
+	}
+	authority, intercepted := httpapi.DetectInterception(chain, parsed.Hostname(), loadTrustedRoots(caCertDir))
+	if !intercepted {
+		return nil
+	}
+
+	warning := &Warning{
+		Code: "tls-intercepted",
+		Message: fmt.Sprintf(
+			"your connection to %s is being intercepted by %s; install its CA via --ca-cert",
+			parsed.Hostname(), authority,
+		),
+	}
+	slog.Warn(warning.Message, "code", warning.Code)
+	return warning
+}
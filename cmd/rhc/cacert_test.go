@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCertificate writes a self-signed PEM certificate to path.
+func writeTestCertificate(t *testing.T, path string, isCA bool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"Acme Firewall"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInstallCACert(t *testing.T) {
+	tests := []struct {
+		description string
+		setup       func(t *testing.T, path string)
+		wantError   bool
+	}{
+		{
+			description: "CA certificate",
+			setup:       func(t *testing.T, path string) { writeTestCertificate(t, path, true) },
+		},
+		{
+			description: "not a CA certificate",
+			setup:       func(t *testing.T, path string) { writeTestCertificate(t, path, false) },
+			wantError:   true,
+		},
+		{
+			description: "not a PEM file",
+			setup: func(t *testing.T, path string) {
+				if err := os.WriteFile(path, []byte("certificate"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantError: true,
+		},
+		{
+			description: "missing file",
+			setup:       func(t *testing.T, path string) {},
+			wantError:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			src := filepath.Join(t.TempDir(), "proxy-ca.pem")
+			dir := t.TempDir()
+			test.setup(t, src)
+
+			path, err := installCACert(src, dir)
+			if test.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if path != filepath.Join(dir, customCACertName) {
+				t.Errorf("installed to %s", path)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			block, _ := pem.Decode(data)
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := cert.Verify(x509.VerifyOptions{Roots: loadTrustedRoots(dir)}); err != nil {
+				t.Errorf("installed certificate is not trusted: %v", err)
+			}
+		})
+	}
+}
@@ -2,23 +2,23 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/redhatinsights/rhc/internal/canonical_facts"
+	"github.com/redhatinsights/rhc/internal/ui"
 	"github.com/redhatinsights/rhc/pkg/exitcode"
 	"github.com/urfave/cli/v3"
 )
 
 // canonicalFactAction tries to gather canonical facts about system,
 // and it prints JSON with facts to stdout.
-func canonicalFactAction(_ context.Context, _ *cli.Command) error {
-	// NOTE: CLI context is not useful for anything
+func canonicalFactAction(_ context.Context, cmd *cli.Command) error {
+	configureUI(cmd)
 	facts, err := canonical_facts.GetCanonicalFacts()
 	if err != nil {
 		return cli.Exit(fmt.Errorf("cannot generate canonical facts: %v", err), exitcode.Err)
 	}
-	data, err := json.MarshalIndent(facts, "", "   ")
+	data, err := ui.MarshalJSON(facts)
 	if err != nil {
 		return err
 	}
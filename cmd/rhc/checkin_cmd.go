@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/datacollection"
+	"github.com/redhatinsights/rhc/internal/schedule"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// CheckinResult is structure holding the result of the check-in.
+// The result could be printed in machine-readable format.
+type CheckinResult struct {
+	Hostname         string    `json:"hostname"`
+	PreviousHostname string    `json:"previous_hostname,omitempty"`
+	HostnameSynced   bool      `json:"hostname_synced"`
+	RHSMSyncError    string    `json:"rhsm_sync_error,omitempty"`
+	Server           string    `json:"server,omitempty"`
+	InsightsError    string    `json:"insights_error,omitempty"`
+	Warnings         []Warning `json:"warnings"`
+	// QueuedOperations are the failed operations retried by following check-ins.
+	QueuedOperations []QueuedOperation `json:"queued_operations"`
+}
+
+// readSyncedHostname returns the hostname last propagated to Red Hat services,
+// or an empty string when it has not been recorded yet.
+func readSyncedHostname(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot read synced hostname: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// writeSyncedHostname records the hostname propagated to Red Hat services.
+func writeSyncedHostname(path string, hostname string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot record synced hostname: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(hostname+"\n"), 0644); err != nil {
+		return fmt.Errorf("cannot record synced hostname: %w", err)
+	}
+	return nil
+}
+
+// updateFactsWithFailover updates the facts of the system in Red Hat Subscription
+// Management, failing over between the servers of the '[servers]' section. The
+// server that served the update is recorded in result.
+func updateFactsWithFailover(result *CheckinResult) error {
+	if len(conf.Config.Servers) == 0 {
+		return subman.UpdateFacts()
+	}
+	client, err := subman.NewRHSMClient()
+	if err != nil {
+		return err
+	}
+	result.Server, err = withServerFailover(client, conf.Config.Servers, subman.UpdateFacts)
+	return err
+}
+
+// syncHostname propagates the hostname to the name of the consumer in Red Hat
+// Subscription Management, after its facts are refreshed, and to the display
+// name in Inventory. The change is recorded in the audit log.
+func syncHostname(ctx context.Context, result *CheckinResult, analytics bool) {
+	slog.Info("Synchronizing hostname", "previous", result.PreviousHostname, "hostname", result.Hostname)
+
+	err := ui.Spinner(func() error {
+		if err := updateFactsWithFailover(result); err != nil {
+			return err
+		}
+		client, err := subman.NewRHSMClient()
+		if err != nil {
+			return err
+		}
+		return client.RenameConsumer(ctx, result.Hostname)
+	}, ui.Indent.Medium, "Updating hostname in Red Hat Subscription Management...")
+	if err != nil {
+		result.RHSMSyncError = err.Error()
+		slog.Error("Unable to update hostname in Red Hat Subscription Management", "err", err)
+		ui.Printf("%s[%v] Unable to update hostname in Red Hat Subscription Management\n", ui.Indent.Medium, ui.Icons.Error)
+	} else {
+		ui.Printf("%s[%v] Hostname updated in Red Hat Subscription Management\n", ui.Indent.Medium, ui.Icons.Ok)
+	}
+
+	if analytics {
+		err = ui.Spinner(func() error {
+			return datacollection.SetInsightsDisplayName(result.Hostname)
+		}, ui.Indent.Medium, "Updating display name in Inventory...")
+		if err != nil {
+			result.InsightsError = err.Error()
+			slog.Error("Unable to update display name in Inventory", "err", err)
+			ui.Printf("%s[%v] Unable to update display name in Inventory\n", ui.Indent.Medium, ui.Icons.Error)
+		} else {
+			ui.Printf("%s[%v] Display name updated in Inventory\n", ui.Indent.Medium, ui.Icons.Ok)
+		}
+	}
+
+	if result.RHSMSyncError != "" || result.InsightsError != "" {
+		return
+	}
+	result.HostnameSynced = true
+	if err = writeSyncedHostname(SyncedHostnamePath, result.Hostname); err != nil {
+		slog.Warn(err.Error())
+	}
+	recordAudit("hostname-sync", map[string]string{
+		"previous_hostname": result.PreviousHostname,
+		"hostname":          result.Hostname,
+	})
+}
+
+// runCheckin checks the system in with Red Hat Lightspeed. When the hostname
+// changed since it was last propagated, when syncRequested is set, or when its
+// propagation failed before, the new hostname is propagated to Red Hat
+// services. Failures of the services are stored in the result, and the failed
+// operations are queued for following check-ins; an error is returned only
+// when the check-in cannot start.
+func runCheckin(ctx context.Context, syncRequested bool) (CheckinResult, error) {
+	var err error
+	result := CheckinResult{Warnings: collectWarnings()}
+	result.Hostname, err = os.Hostname()
+	if err != nil {
+		slog.Error("error retrieving system hostname", "err", err)
+		return result, fmt.Errorf("cannot get hostname: %w", err)
+	}
+	result.PreviousHostname, err = readSyncedHostname(SyncedHostnamePath)
+	if err != nil {
+		slog.Warn(err.Error())
+	}
+
+	analytics, err := datacollection.InsightsClientHasRegisteredMarker()
+	if err != nil {
+		slog.Warn("Unable to check registration of Red Hat Lightspeed", "err", err)
+	}
+
+	queue := loadQueue(conf.Config.Queue.MaxAge)
+	if !analytics {
+		queue = dequeue(queue, opDisplayName, opInsightsCheckin)
+	}
+
+	ui.Printf("Checking in %v with Red Hat.\n\n", result.Hostname)
+
+	renamed := result.PreviousHostname != "" && result.PreviousHostname != result.Hostname
+	if renamed {
+		ui.Printf("%s[%v] Hostname changed from %s\n", ui.Indent.Small, ui.Icons.Info, result.PreviousHostname)
+	}
+	retry := isQueued(queue, opUpdateFacts, opDisplayName)
+	if retry {
+		ui.Printf("%s[%v] Retrying operations queued by previous check-ins\n", ui.Indent.Small, ui.Icons.Info)
+	}
+	if renamed || syncRequested || retry {
+		syncHostname(ctx, &result, analytics)
+		queue = settle(queue, opUpdateFacts, result.RHSMSyncError)
+		if analytics {
+			queue = settle(queue, opDisplayName, result.InsightsError)
+		}
+	} else if result.PreviousHostname == "" {
+		// Record the current hostname, so later changes can be detected
+		if err = writeSyncedHostname(SyncedHostnamePath, result.Hostname); err != nil {
+			slog.Warn(err.Error())
+		}
+	}
+
+	if analytics {
+		// Tags changed in the configuration since connect are uploaded with
+		// the check-in
+		if err = datacollection.WriteInsightsTags(conf.Config.Tags); err != nil {
+			warning := Warning{Code: "tags", Message: fmt.Sprintf("cannot apply tags: %v", err)}
+			slog.Warn(warning.Message, "code", warning.Code)
+			result.Warnings = append(result.Warnings, warning)
+		}
+	}
+	if analytics && result.InsightsError == "" {
+		err = ui.Spinner(datacollection.InsightsClientCheckIn, ui.Indent.Medium, "Checking in with Red Hat Lightspeed...")
+		if err != nil {
+			result.InsightsError = err.Error()
+			// Retrying does not help, until insights-client is updated
+			var unsupported *datacollection.UnsupportedFlagError
+			if !errors.As(err, &unsupported) {
+				queue = settle(queue, opInsightsCheckin, result.InsightsError)
+			}
+			slog.Error("Unable to check in with Red Hat Lightspeed", "err", err)
+			ui.Printf("%s[%v] Unable to check in with Red Hat Lightspeed\n", ui.Indent.Medium, ui.Icons.Error)
+		} else {
+			queue = settle(queue, opInsightsCheckin, "")
+			slog.Info("Checked in with Red Hat Lightspeed")
+			ui.Printf("%s[%v] Checked in with Red Hat Lightspeed\n", ui.Indent.Medium, ui.Icons.Ok)
+		}
+	}
+
+	if err = writeQueue(QueuePath, queue); err != nil {
+		slog.Warn(err.Error())
+	}
+	result.QueuedOperations = append([]QueuedOperation{}, queue...)
+	if len(queue) > 0 {
+		slog.Info("Operations queued for following check-ins", "count", len(queue))
+		ui.Printf("%s[%v] %d operation(s) queued until Red Hat services can be reached\n", ui.Indent.Small, ui.Icons.Info, len(queue))
+	}
+
+	return result, nil
+}
+
+// checkinDaemon checks the system in periodically following the '[checkin]'
+// configuration, until ctx is canceled. Failed check-ins are logged; check-ins
+// during a maintenance window are skipped.
+func checkinDaemon(ctx context.Context) error {
+	s := checkinSchedule()
+	hostID := schedule.HostID()
+	slog.Info("Starting check-in daemon", "interval", s.Interval, "jitter", s.Jitter, "splay", s.Splay)
+
+	for first := true; ; first = false {
+		delay := s.NextDelay(hostID, first)
+		slog.Info("Next check-in scheduled", "at", time.Now().Add(delay).Format(time.RFC3339))
+		select {
+		case <-ctx.Done():
+			slog.Info("Stopping check-in daemon")
+			return nil
+		case <-time.After(delay):
+		}
+		if inMaintenance() {
+			continue
+		}
+
+		result, err := runCheckin(ctx, false)
+		if err != nil {
+			slog.Error("Check-in failed", "err", err)
+		} else if result.RHSMSyncError != "" || result.InsightsError != "" {
+			slog.Warn("Check-in finished with errors")
+		}
+	}
+}
+
+// beforeCheckinAction ensures the user has supplied a correct `--format` flag.
+func beforeCheckinAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	if cmd.Bool("daemon") {
+		// The daemon does not print results, so the format of the configuration file does not apply
+		if cmd.IsSet("format") {
+			return ctx, cli.Exit("--daemon cannot be used with --format", exitcode.Usage)
+		}
+	} else if err := checkFormatFlag(cmd); err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err := checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// checkinAction checks the system in with Red Hat Lightspeed once, or
+// periodically when --daemon is set.
+func checkinAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot check in system", exitcode.NoPerm)
+	}
+
+	client, err := subman.NewRHSMClient()
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("unable to check connection status: %s", err), exitcode.Software)
+	}
+	registered, err := client.IsRegistered()
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("unable to check connection status: %s", err), exitcode.Software)
+	}
+	if !registered {
+		return cli.Exit("this system is not connected", exitcode.Usage)
+	}
+
+	if cmd.Bool("daemon") {
+		return checkinDaemon(ctx)
+	}
+	if cmd.Bool("scheduled") {
+		// Spread check-ins of hosts activated by their timers at the same time
+		delay := checkinSchedule().RandomDelay()
+		slog.Info("Delaying scheduled check-in", "delay", delay.String())
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		if inMaintenance() {
+			return nil
+		}
+	}
+
+	result, err := runCheckin(ctx, cmd.Bool("sync-hostname"))
+	if err != nil {
+		return cli.Exit(err, exitcode.Err)
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print check-in result as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+	} else {
+		printWarnings(result.Warnings)
+	}
+
+	if result.RHSMSyncError != "" || result.InsightsError != "" {
+		return cli.Exit("", exitcode.Err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSyncedHostname(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rhc", "hostname")
+
+	hostname, err := readSyncedHostname(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hostname != "" {
+		t.Fatalf("got hostname %q, want empty", hostname)
+	}
+
+	if err = writeSyncedHostname(path, "node1.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	hostname, err = readSyncedHostname(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hostname != "node1.example.com" {
+		t.Errorf("got hostname %q, want %q", hostname, "node1.example.com")
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/schedule"
+	"github.com/redhatinsights/rhc/internal/systemd"
+)
+
+const (
+	// checkinServiceName is the name of the generated service running 'rhc checkin'.
+	checkinServiceName = "rhc-checkin.service"
+	// checkinTimerName is the name of the generated timer activating checkinServiceName.
+	checkinTimerName = "rhc-checkin.timer"
+	// checkinStartDelay is the delay of the first check-in after the timer is activated.
+	checkinStartDelay = 5 * time.Minute
+)
+
+// checkinSchedule returns the check-in schedule of the '[checkin]' configuration.
+func checkinSchedule() schedule.Schedule {
+	return schedule.Schedule{
+		Interval: conf.Config.Checkin.Interval,
+		Jitter:   conf.Config.Checkin.Jitter,
+		Splay:    conf.Config.Checkin.Splay,
+	}
+}
+
+// formatTimeSpan formats d as a systemd time span in seconds.
+func formatTimeSpan(d time.Duration) string {
+	return fmt.Sprintf("%ds", int64(d.Round(time.Second)/time.Second))
+}
+
+// formatCheckinService returns the unit file of the check-in service.
+func formatCheckinService() string {
+	return "# This file is managed by rhc; changes will be overwritten.\n" +
+		"[Unit]\n" +
+		"Description=rhc check-in\n" +
+		"Documentation=https://github.com/RedHatInsights/rhc\n" +
+		"Wants=network-online.target\n" +
+		"After=network-online.target\n" +
+		"\n" +
+		"[Service]\n" +
+		"Type=oneshot\n" +
+		"ExecStart=rhc checkin --scheduled\n"
+}
+
+// formatCheckinTimer returns the unit file of the check-in timer following s.
+// The timer is shifted by offset, the splay of the host; the jitter is added
+// by 'rhc checkin --scheduled'.
+func formatCheckinTimer(s schedule.Schedule, offset time.Duration) string {
+	return "# This file is managed by rhc; changes will be overwritten.\n" +
+		"[Unit]\n" +
+		"Description=rhc check-in timer\n" +
+		"Documentation=https://github.com/RedHatInsights/rhc\n" +
+		"\n" +
+		"[Timer]\n" +
+		"OnActiveSec=" + formatTimeSpan(checkinStartDelay+offset) + "\n" +
+		"OnUnitActiveSec=" + formatTimeSpan(s.Interval) + "\n" +
+		"\n" +
+		"[Install]\n" +
+		"WantedBy=timers.target\n"
+}
+
+// installCheckinTimer generates the check-in service and timer following the
+// '[checkin]' configuration, and enables the timer.
+func installCheckinTimer() error {
+	s := checkinSchedule()
+	if err := systemd.WriteUnit(checkinServiceName, formatCheckinService()); err != nil {
+		return err
+	}
+	if err := systemd.WriteUnit(checkinTimerName, formatCheckinTimer(s, s.Offset(schedule.HostID()))); err != nil {
+		return err
+	}
+
+	conn, err := systemd.NewConnectionContext(context.Background(), systemd.ConnectionTypeSystem)
+	if err != nil {
+		return fmt.Errorf("cannot connect to systemd: %v", err)
+	}
+	defer conn.Close()
+	if err = conn.Reload(); err != nil {
+		return fmt.Errorf("cannot reload systemd: %v", err)
+	}
+	slog.Debug("Enabling " + checkinTimerName)
+	return conn.EnableUnit(checkinTimerName, true, false)
+}
+
+// disableCheckinTimer disables and stops the check-in timer, when it is
+// installed. The generated units are kept.
+func disableCheckinTimer() error {
+	if _, err := os.Stat(filepath.Join(systemd.UnitDir, checkinTimerName)); err != nil {
+		return nil
+	}
+	conn, err := systemd.NewConnectionContext(context.Background(), systemd.ConnectionTypeSystem)
+	if err != nil {
+		return fmt.Errorf("cannot connect to systemd: %v", err)
+	}
+	defer conn.Close()
+
+	state, _ := conn.GetUnitState(checkinTimerName)
+	slog.Debug("Disabling " + checkinTimerName)
+	return conn.DisableUnit(checkinTimerName, state == "active", false)
+}
+
+// removeCheckinTimer disables the check-in timer and removes the generated units.
+func removeCheckinTimer() error {
+	if err := disableCheckinTimer(); err != nil {
+		return err
+	}
+	conn, err := systemd.NewConnectionContext(context.Background(), systemd.ConnectionTypeSystem)
+	if err != nil {
+		return fmt.Errorf("cannot connect to systemd: %v", err)
+	}
+	defer conn.Close()
+
+	if err = systemd.RemoveUnit(checkinTimerName); err != nil {
+		return err
+	}
+	if err = systemd.RemoveUnit(checkinServiceName); err != nil {
+		return err
+	}
+	if err = conn.Reload(); err != nil {
+		return fmt.Errorf("cannot reload systemd: %v", err)
+	}
+	return nil
+}
+
+// CheckinSchedule describes periodic check-ins of the system.
+type CheckinSchedule struct {
+	Interval string `json:"interval"`
+	Jitter   string `json:"jitter"`
+	Splay    string `json:"splay"`
+	// Offset is the splay of this host.
+	Offset string `json:"offset"`
+	// NextCheckin is the time of the next activation of the check-in timer.
+	NextCheckin *time.Time `json:"next_checkin,omitempty"`
+}
+
+// getCheckinSchedule returns the check-in schedule of the host. The next check-in
+// is known only when the check-in timer is active.
+func getCheckinSchedule() CheckinSchedule {
+	s := checkinSchedule()
+	result := CheckinSchedule{
+		Interval: s.Interval.String(),
+		Jitter:   s.Jitter.String(),
+		Splay:    s.Splay.String(),
+		Offset:   s.Offset(schedule.HostID()).Round(time.Second).String(),
+	}
+	timerInfo, err := systemd.GetTimerInfo(checkinTimerName)
+	if err != nil {
+		slog.Debug("Unable to get next check-in", "err", err)
+	} else if timerInfo.Next > 0 {
+		next := time.UnixMicro(int64(timerInfo.Next))
+		result.NextCheckin = &next
+	}
+	return result
+}
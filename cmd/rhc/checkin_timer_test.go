@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/schedule"
+)
+
+func TestFormatCheckinTimer(t *testing.T) {
+	tests := []struct {
+		description string
+		schedule    schedule.Schedule
+		offset      time.Duration
+		want        string
+	}{
+		{
+			description: "defaults",
+			schedule:    schedule.Schedule{Interval: 24 * time.Hour, Jitter: time.Hour},
+			want: "# This file is managed by rhc; changes will be overwritten.\n" +
+				"[Unit]\n" +
+				"Description=rhc check-in timer\n" +
+				"Documentation=https://github.com/RedHatInsights/rhc\n" +
+				"\n" +
+				"[Timer]\n" +
+				"OnActiveSec=300s\n" +
+				"OnUnitActiveSec=86400s\n" +
+				"\n" +
+				"[Install]\n" +
+				"WantedBy=timers.target\n",
+		},
+		{
+			description: "splay offset",
+			schedule:    schedule.Schedule{Interval: 12 * time.Hour, Splay: 6 * time.Hour},
+			offset:      90*time.Minute + 400*time.Millisecond,
+			want: "# This file is managed by rhc; changes will be overwritten.\n" +
+				"[Unit]\n" +
+				"Description=rhc check-in timer\n" +
+				"Documentation=https://github.com/RedHatInsights/rhc\n" +
+				"\n" +
+				"[Timer]\n" +
+				"OnActiveSec=5700s\n" +
+				"OnUnitActiveSec=43200s\n" +
+				"\n" +
+				"[Install]\n" +
+				"WantedBy=timers.target\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := formatCheckinTimer(test.schedule, test.offset)
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
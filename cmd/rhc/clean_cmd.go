@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/collector"
+	"github.com/redhatinsights/rhc/internal/datacollection"
+	"github.com/redhatinsights/rhc/internal/remotemanagement"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/systemd"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// CleanResult is structure holding the outcome of 'rhc clean'. The result
+// could be printed in machine-readable format.
+type CleanResult struct {
+	DryRun bool `json:"dry_run"`
+	// RemovedPaths lists the local state that has been removed, or that would
+	// be removed in a dry run.
+	RemovedPaths []string `json:"removed_paths"`
+	// RemoveErrors maps paths that could not be removed to the errors.
+	RemoveErrors map[string]string `json:"remove_errors,omitempty"`
+	Warnings     []Warning         `json:"warnings"`
+}
+
+// cleanPaths returns local state written by rhc and cached identities of the
+// system, which are regenerated on the next connection. The registration with
+// RHSM, the disconnect lock, the audit log and the configuration are kept.
+func cleanPaths() []string {
+	return []string{
+		// Cached identities
+		datacollection.InsightsMachineIDPath,
+		datacollection.InsightsRegisteredMarkerPath,
+		datacollection.InsightsLastUploadPath,
+		datacollection.InsightsHostDetailsPath,
+		remotemanagement.YggdrasilClientIDPath,
+		// State of rhc
+		SyncedHostnamePath,
+		ConnectFeaturesPrefsPath,
+		FeatureStatePath,
+		MaintenancePath,
+		ConnectionPath,
+		ConsentPath,
+		ActivationKeysPath,
+		QueuePath,
+		// Facts
+		canonicalFactsPath,
+		collector.TimerDir,
+		// Check-in timer
+		filepath.Join(systemd.UnitDir, checkinTimerName),
+		filepath.Join(systemd.UnitDir, checkinServiceName),
+		// Drop-ins of services
+		systemd.DropInPath("yggdrasil.service", proxyDropInName),
+		systemd.DropInPath("insights-client.service", proxyDropInName),
+		systemd.EnvironmentFilePath("yggdrasil.service", proxyDropInName),
+		systemd.EnvironmentFilePath("insights-client.service", proxyDropInName),
+	}
+}
+
+// existingPaths returns paths that exist, cleaned.
+func existingPaths(paths []string) []string {
+	existing := []string{}
+	for _, path := range paths {
+		if _, err := os.Lstat(path); err == nil {
+			existing = append(existing, filepath.Clean(path))
+		}
+	}
+	return existing
+}
+
+// reloadSystemd makes systemd pick up removed drop-ins.
+func reloadSystemd() error {
+	conn, err := systemd.NewConnectionContext(context.Background(), systemd.ConnectionTypeSystem)
+	if err != nil {
+		return fmt.Errorf("cannot connect to systemd: %v", err)
+	}
+	defer conn.Close()
+	if err = conn.Reload(); err != nil {
+		return fmt.Errorf("cannot reload systemd: %v", err)
+	}
+	return nil
+}
+
+// beforeCleanAction ensures the user has supplied a correct `--format` flag.
+func beforeCleanAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// cleanAction removes local state written by rhc, e.g. when it got corrupted.
+// Connected systems are refused unless --force is used, because they depend on
+// the state; so are systems whose connection status cannot be checked.
+func cleanAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot clean local state", exitcode.NoPerm)
+	}
+
+	result := CleanResult{DryRun: cmd.Bool("dry-run"), Warnings: collectWarnings()}
+
+	if !result.DryRun && !cmd.Bool("force") {
+		client, err := subman.NewRHSMClient()
+		if err != nil {
+			return cli.Exit(
+				fmt.Sprintf("unable to check connection status: %v; use --force to remove the local state anyway", err),
+				exitcode.Software,
+			)
+		}
+		registered, err := client.IsRegistered()
+		if err != nil {
+			return cli.Exit(
+				fmt.Sprintf("unable to check connection status: %v; use --force to remove the local state anyway", err),
+				exitcode.Software,
+			)
+		}
+		if registered {
+			return cli.Exit(
+				"this system is connected; run 'rhc disconnect' first, or use --force to remove the local state anyway",
+				exitcode.Usage,
+			)
+		}
+	}
+
+	paths := existingPaths(cleanPaths())
+	if result.DryRun {
+		result.RemovedPaths = paths
+	} else {
+		if err := disableCheckinTimer(); err != nil {
+			slog.Warn(fmt.Sprintf("cannot disable periodic check-in: %v", err))
+		}
+		result.RemovedPaths, result.RemoveErrors = removeLocalState(paths)
+		slog.Info("Local state cleaned", "removed", len(result.RemovedPaths))
+		if slices.ContainsFunc(result.RemovedPaths, func(path string) bool {
+			return strings.HasPrefix(path, systemd.UnitDir+string(filepath.Separator))
+		}) {
+			if err := reloadSystemd(); err != nil {
+				slog.Warn(err.Error())
+			}
+		}
+		recordAudit("clean", map[string]string{"removed": strconv.Itoa(len(result.RemovedPaths))})
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err := ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print result as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+	} else {
+		verb := "Removed"
+		if result.DryRun {
+			verb = "Would remove"
+		}
+		if len(result.RemovedPaths) == 0 && len(result.RemoveErrors) == 0 {
+			ui.Printf("%s[%v] No local state to remove\n", ui.Indent.Small, ui.Icons.Ok)
+		}
+		for _, path := range result.RemovedPaths {
+			ui.Printf("%s[%v] %s %s\n", ui.Indent.Small, ui.Icons.Ok, verb, path)
+		}
+		for _, path := range slices.Sorted(maps.Keys(result.RemoveErrors)) {
+			ui.Printf("%s[%v] Cannot remove %s: %s\n", ui.Indent.Small, ui.Icons.Error, path, result.RemoveErrors[path])
+		}
+		printWarnings(result.Warnings)
+	}
+
+	if len(result.RemoveErrors) > 0 {
+		return cli.Exit("", exitcode.Err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhatinsights/rhc/internal/systemd"
+)
+
+func TestExistingPaths(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "connection.json")
+	cacheDir := filepath.Join(dir, "collectors")
+	if err := os.WriteFile(file, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got := existingPaths([]string{file, cacheDir + "/", filepath.Join(dir, "missing")})
+	want := []string{file, cacheDir}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
+
+func TestCleanPathsKeepRegistration(t *testing.T) {
+	for _, kept := range []string{DisconnectLockPath, AuditLogPath, "/etc/pki/consumer/cert.pem"} {
+		for _, path := range cleanPaths() {
+			if path == kept {
+				t.Errorf("%s must not be cleaned", kept)
+			}
+		}
+	}
+}
+
+func TestCleanPathsCheckinTimer(t *testing.T) {
+	for _, unit := range []string{checkinTimerName, checkinServiceName} {
+		path := filepath.Join(systemd.UnitDir, unit)
+		if !slices.Contains(cleanPaths(), path) {
+			t.Errorf("%s is not cleaned", path)
+		}
+	}
+}
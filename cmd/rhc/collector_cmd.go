@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -14,6 +13,7 @@ import (
 	"github.com/emersion/go-varlink"
 
 	"github.com/redhatinsights/rhc/internal/collector"
+	"github.com/redhatinsights/rhc/internal/systemd"
 	"github.com/redhatinsights/rhc/internal/ui"
 	"github.com/redhatinsights/rhc/pkg/exitcode"
 	"github.com/redhatinsights/rhc/varlink/collectorapi"
@@ -65,6 +65,13 @@ func collectorInfoAction(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+// CollectorListResult is structure holding the result of 'rhc collector list'.
+// The result could be printed in machine-readable format.
+type CollectorListResult struct {
+	Collectors []collectorapi.CollectorInfo `json:"collectors"`
+	Warnings   []Warning                    `json:"warnings"`
+}
+
 // beforeCollectorListAction validates the collector list command configuration.
 func beforeCollectorListAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
 	return ctx, validateCollectorCommand(cmd, false, true)
@@ -87,15 +94,16 @@ func collectorListAction(ctx context.Context, cmd *cli.Command) error {
 	}
 
 	if ui.IsOutputMachineReadable() {
-		if len(response.Collectors) == 0 {
-			fmt.Println("{}")
-			return nil
+		result := CollectorListResult{Collectors: response.Collectors, Warnings: collectWarnings()}
+		if result.Collectors == nil {
+			result.Collectors = []collectorapi.CollectorInfo{}
 		}
-		jsonData, err := json.Marshal(response.Collectors)
-		if err != nil {
-			return cli.Exit(fmt.Sprintf("failed to marshal collectors: %v", err), exitcode.Err)
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print collectors as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
 		}
-		fmt.Println(string(jsonData))
 		return nil
 	}
 
@@ -157,7 +165,7 @@ func collectorEnableAction(ctx context.Context, cmd *cli.Command) error {
 
 	err = conn.EnableUnit(timerName, true, false)
 	if err != nil {
-		if strings.Contains(fmt.Sprintf("%v", err), "does not exist") {
+		if errors.Is(err, systemd.ErrUnitNotFound) {
 			return cli.Exit(fmt.Sprintf("timer unit %s does not exist, collector systemd units need to be installed first", timerName), exitcode.OSFile)
 		}
 		return cli.Exit(fmt.Sprintf("failed to enable timer %s: %v", timerName, err), exitcode.OSFile)
@@ -202,7 +210,7 @@ func collectorDisableAction(ctx context.Context, cmd *cli.Command) error {
 
 	err = conn.DisableUnit(timerName, true, false)
 	if err != nil {
-		if strings.Contains(fmt.Sprintf("%v", err), "does not exist") {
+		if errors.Is(err, systemd.ErrUnitNotFound) {
 			return cli.Exit(fmt.Sprintf("timer unit %s does not exist. Collector systemd units need to be installed first.", timerName), exitcode.OSFile)
 		}
 		return cli.Exit(fmt.Sprintf("failed to disable timer %s: %v", timerName, err), exitcode.OSFile)
@@ -0,0 +1,935 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pelletier/go-toml"
+	altsrc "github.com/urfave/cli-altsrc/v3"
+	altsrctoml "github.com/urfave/cli-altsrc/v3/toml"
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/logging"
+	"github.com/redhatinsights/rhc/internal/ui"
+)
+
+// ConfigDropInDir is the directory with configuration drop-in files. Files
+// with the '.toml' suffix are read in lexical order after the main configuration
+// file; configuration management tools can use them instead of editing config.toml.
+const ConfigDropInDir = "/etc/rhc/config.toml.d"
+
+// configValueSources returns the sources of the top-level key of a global
+// flag: the drop-in files at dropInPaths, the last one first, followed by the
+// configuration file of configSource.
+func configValueSources(key string, configSource altsrc.Sourcer, dropInPaths []string) cli.ValueSourceChain {
+	var sources []cli.ValueSource
+	for _, path := range slices.Backward(dropInPaths) {
+		sources = append(sources, altsrctoml.TOML(key, altsrc.StringSourcer(path)))
+	}
+	sources = append(sources, altsrctoml.TOML(key, configSource))
+	return cli.NewValueSourceChain(sources...)
+}
+
+// getStringTable returns the table identified by key as a map of strings.
+// Nil is returned when the key is not present.
+func getStringTable(tree *toml.Tree, key string) (map[string]string, error) {
+	value := tree.Get(key)
+	if value == nil {
+		return nil, nil
+	}
+	table, ok := value.(*toml.Tree)
+	if !ok {
+		return nil, fmt.Errorf("'%s' has to be a table", key)
+	}
+	result := make(map[string]string)
+	for name, item := range table.ToMap() {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("'%s.%s' has to be a string", key, name)
+		}
+		result[name] = str
+	}
+	return result, nil
+}
+
+// loadUIConf reads the '[ui]' section of the configuration file. The section
+// is optional; nil tree results in the default configuration.
+func loadUIConf(tree *toml.Tree) (conf.UIConf, error) {
+	var uiConf conf.UIConf
+	if tree == nil {
+		return uiConf, nil
+	}
+
+	if value := tree.Get("ui.theme"); value != nil {
+		theme, ok := value.(string)
+		if !ok {
+			return uiConf, fmt.Errorf("'ui.theme' has to be a string")
+		}
+		uiConf.Theme = theme
+	}
+	if value := tree.Get("ui.json"); value != nil {
+		style, ok := value.(string)
+		if !ok || (style != string(ui.JSONPretty) && style != string(ui.JSONCompact)) {
+			return uiConf, fmt.Errorf("'ui.json' has to be %q or %q", ui.JSONPretty, ui.JSONCompact)
+		}
+		uiConf.JSON = style
+	}
+
+	var err error
+	if uiConf.Colors, err = getStringTable(tree, "ui.colors"); err != nil {
+		return uiConf, err
+	}
+	if uiConf.Symbols, err = getStringTable(tree, "ui.symbols"); err != nil {
+		return uiConf, err
+	}
+	return uiConf, nil
+}
+
+// loadFactsConf reads the '[facts]' section of the configuration file. The section
+// is optional; nil tree results in the default configuration.
+func loadFactsConf(tree *toml.Tree) (conf.FactsConf, error) {
+	var factsConf conf.FactsConf
+	if tree == nil {
+		return factsConf, nil
+	}
+
+	if value := tree.Get("facts.collectors"); value != nil {
+		items, ok := value.([]any)
+		if !ok {
+			return factsConf, fmt.Errorf("'facts.collectors' has to be an array of strings")
+		}
+		for _, item := range items {
+			name, ok := item.(string)
+			if !ok {
+				return factsConf, fmt.Errorf("'facts.collectors' has to be an array of strings")
+			}
+			factsConf.Collectors = append(factsConf.Collectors, name)
+		}
+	}
+
+	timeouts, err := getStringTable(tree, "facts.timeouts")
+	if err != nil {
+		return factsConf, err
+	}
+	for name, value := range timeouts {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return factsConf, fmt.Errorf("'facts.timeouts.%s' has to be a positive duration (e.g. \"5s\")", name)
+		}
+		if factsConf.Timeouts == nil {
+			factsConf.Timeouts = make(map[string]time.Duration)
+		}
+		factsConf.Timeouts[name] = timeout
+	}
+	return factsConf, nil
+}
+
+// checkProxyURL returns an error, when proxyURL is not an http or https URL.
+// Credentials are configured separately, so they are not part of the URL.
+func checkProxyURL(proxyURL string) error {
+	parsed, err := url.Parse(proxyURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return fmt.Errorf("has to be an http or https URL")
+	}
+	if parsed.User != nil {
+		return fmt.Errorf("cannot contain credentials, use 'proxy.user' and 'proxy.password'")
+	}
+	return nil
+}
+
+// loadProxyConf reads the '[proxy]' section of the configuration file. The section
+// is optional; nil tree results in the default configuration. 'no-proxy' is either
+// an array of strings, or a comma-separated string like the NO_PROXY variable.
+func loadProxyConf(tree *toml.Tree) (conf.ProxyConf, error) {
+	var proxyConf conf.ProxyConf
+	if tree == nil {
+		return proxyConf, nil
+	}
+
+	if value := tree.Get("proxy.url"); value != nil {
+		proxyURL, ok := value.(string)
+		if !ok {
+			return proxyConf, fmt.Errorf("'proxy.url' has to be a string")
+		}
+		if err := checkProxyURL(proxyURL); err != nil {
+			return proxyConf, fmt.Errorf("'proxy.url' %w", err)
+		}
+		proxyConf.URL = proxyURL
+	}
+	if value := tree.Get("proxy.user"); value != nil {
+		user, ok := value.(string)
+		if !ok {
+			return proxyConf, fmt.Errorf("'proxy.user' has to be a string")
+		}
+		proxyConf.User = user
+	}
+	if value := tree.Get("proxy.password"); value != nil {
+		password, ok := value.(string)
+		if !ok {
+			return proxyConf, fmt.Errorf("'proxy.password' has to be a string")
+		}
+		proxyConf.Password = password
+	}
+	if value := tree.Get("proxy.password-file"); value != nil {
+		passwordFile, ok := value.(string)
+		if !ok {
+			return proxyConf, fmt.Errorf("'proxy.password-file' has to be a string")
+		}
+		if passwordFile != "" && !filepath.IsAbs(passwordFile) {
+			return proxyConf, fmt.Errorf("'proxy.password-file' has to be an absolute path")
+		}
+		proxyConf.PasswordFile = passwordFile
+	}
+	if proxyConf.Password != "" && proxyConf.PasswordFile != "" {
+		return proxyConf, fmt.Errorf("'proxy.password' and 'proxy.password-file' can not be used together")
+	}
+	if (proxyConf.Password != "" || proxyConf.PasswordFile != "") && proxyConf.User == "" {
+		return proxyConf, fmt.Errorf("'proxy.password' and 'proxy.password-file' require 'proxy.user'")
+	}
+
+	switch value := tree.Get("proxy.no-proxy").(type) {
+	case nil:
+	case string:
+		for _, entry := range strings.Split(value, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				proxyConf.NoProxy = append(proxyConf.NoProxy, entry)
+			}
+		}
+	case []any:
+		for _, item := range value {
+			entry, ok := item.(string)
+			if !ok {
+				return proxyConf, fmt.Errorf("'proxy.no-proxy' has to be an array of strings")
+			}
+			proxyConf.NoProxy = append(proxyConf.NoProxy, entry)
+		}
+	default:
+		return proxyConf, fmt.Errorf("'proxy.no-proxy' has to be an array of strings")
+	}
+	return proxyConf, nil
+}
+
+// loadNetworkConf reads the '[network]' section of the configuration file. The section
+// is optional; nil tree results in the default configuration.
+func loadNetworkConf(tree *toml.Tree) (conf.NetworkConf, error) {
+	var networkConf conf.NetworkConf
+	if tree == nil {
+		return networkConf, nil
+	}
+
+	if value := tree.Get("network.interface"); value != nil {
+		iface, ok := value.(string)
+		if !ok {
+			return networkConf, fmt.Errorf("'network.interface' has to be a string")
+		}
+		networkConf.Interface = iface
+	}
+
+	if value := tree.Get("network.source-address"); value != nil {
+		address, ok := value.(string)
+		if !ok || net.ParseIP(address) == nil {
+			return networkConf, fmt.Errorf("'network.source-address' has to be an IP address")
+		}
+		networkConf.SourceAddress = address
+	}
+	return networkConf, nil
+}
+
+// Default values of the '[checkin]' section of the configuration file.
+const (
+	defaultCheckinInterval = 24 * time.Hour
+	defaultCheckinJitter   = time.Hour
+	// lowBandwidthCheckinInterval is the default interval of check-ins in the
+	// low-bandwidth mode.
+	lowBandwidthCheckinInterval = 4 * defaultCheckinInterval
+)
+
+// loadCheckinConf reads the '[checkin]' section of the configuration file. The section
+// is optional; nil tree results in the default configuration.
+func loadCheckinConf(tree *toml.Tree) (conf.CheckinConf, error) {
+	checkinConf := conf.CheckinConf{
+		Interval: defaultCheckinInterval,
+		Jitter:   defaultCheckinJitter,
+	}
+	if tree == nil {
+		return checkinConf, nil
+	}
+
+	durations := []struct {
+		key      string
+		value    *time.Duration
+		positive bool
+	}{
+		{key: "checkin.interval", value: &checkinConf.Interval, positive: true},
+		{key: "checkin.jitter", value: &checkinConf.Jitter},
+		{key: "checkin.splay", value: &checkinConf.Splay},
+	}
+	for _, d := range durations {
+		value := tree.Get(d.key)
+		if value == nil {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return checkinConf, fmt.Errorf("'%s' has to be a duration (e.g. \"30m\")", d.key)
+		}
+		duration, err := time.ParseDuration(str)
+		if err != nil || duration < 0 || (d.positive && duration == 0) {
+			return checkinConf, fmt.Errorf("'%s' has to be a duration (e.g. \"30m\")", d.key)
+		}
+		*d.value = duration
+	}
+
+	if checkinConf.Splay > checkinConf.Interval {
+		return checkinConf, fmt.Errorf("'checkin.splay' cannot be longer than 'checkin.interval'")
+	}
+	return checkinConf, nil
+}
+
+// defaultQueueMaxAge is the default time failed operations of check-ins are
+// retried for.
+const defaultQueueMaxAge = 7 * 24 * time.Hour
+
+// loadQueueConf reads the '[queue]' section of the configuration file. The section
+// is optional; nil tree results in the default configuration.
+func loadQueueConf(tree *toml.Tree) (conf.QueueConf, error) {
+	queueConf := conf.QueueConf{MaxAge: defaultQueueMaxAge}
+	if tree == nil {
+		return queueConf, nil
+	}
+	value := tree.Get("queue.max-age")
+	if value == nil {
+		return queueConf, nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return queueConf, fmt.Errorf("'queue.max-age' has to be a duration (e.g. \"72h\")")
+	}
+	maxAge, err := time.ParseDuration(str)
+	if err != nil || maxAge < 0 {
+		return queueConf, fmt.Errorf("'queue.max-age' has to be a duration (e.g. \"72h\")")
+	}
+	queueConf.MaxAge = maxAge
+	return queueConf, nil
+}
+
+// Defaults of the '[retry]' section of the configuration file.
+const (
+	defaultRetryAttempts = 3
+	defaultRetryDelay    = 2 * time.Second
+	defaultRetryMaxDelay = 30 * time.Second
+)
+
+// loadRetryConf reads the '[retry]' section of the configuration file. The section
+// is optional; nil tree results in the default configuration.
+func loadRetryConf(tree *toml.Tree) (conf.RetryConf, error) {
+	retryConf := conf.RetryConf{
+		Attempts: defaultRetryAttempts,
+		Delay:    defaultRetryDelay,
+		MaxDelay: defaultRetryMaxDelay,
+	}
+	if tree == nil {
+		return retryConf, nil
+	}
+
+	if value := tree.Get("retry.attempts"); value != nil {
+		attempts, ok := value.(int64)
+		if !ok || attempts < 1 || attempts > 100 {
+			return retryConf, fmt.Errorf("'retry.attempts' has to be a number from 1 to 100")
+		}
+		retryConf.Attempts = int(attempts)
+	}
+	for _, d := range []struct {
+		key   string
+		value *time.Duration
+	}{
+		{key: "retry.delay", value: &retryConf.Delay},
+		{key: "retry.max-delay", value: &retryConf.MaxDelay},
+	} {
+		value := tree.Get(d.key)
+		if value == nil {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return retryConf, fmt.Errorf("'%s' has to be a duration (e.g. \"5s\")", d.key)
+		}
+		duration, err := time.ParseDuration(str)
+		if err != nil || duration < 0 {
+			return retryConf, fmt.Errorf("'%s' has to be a duration (e.g. \"5s\")", d.key)
+		}
+		*d.value = duration
+	}
+
+	if retryConf.Delay > retryConf.MaxDelay {
+		return retryConf, fmt.Errorf("'retry.delay' cannot be longer than 'retry.max-delay'")
+	}
+	return retryConf, nil
+}
+
+const (
+	defaultRHSMTimeout       = 5 * time.Minute
+	defaultInsightsTimeout   = 10 * time.Minute
+	defaultActivationTimeout = 2 * time.Minute
+)
+
+// loadTimeoutsConf reads the '[timeouts]' section of the configuration file.
+// The section is optional; nil tree results in the default configuration.
+func loadTimeoutsConf(tree *toml.Tree) (conf.TimeoutsConf, error) {
+	timeoutsConf := conf.TimeoutsConf{
+		RHSM:       defaultRHSMTimeout,
+		Insights:   defaultInsightsTimeout,
+		Activation: defaultActivationTimeout,
+	}
+	if tree == nil {
+		return timeoutsConf, nil
+	}
+
+	for _, d := range []struct {
+		key   string
+		value *time.Duration
+	}{
+		{key: "timeouts.rhsm", value: &timeoutsConf.RHSM},
+		{key: "timeouts.insights", value: &timeoutsConf.Insights},
+		{key: "timeouts.activation", value: &timeoutsConf.Activation},
+	} {
+		value := tree.Get(d.key)
+		if value == nil {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return timeoutsConf, fmt.Errorf("'%s' has to be a duration (e.g. \"5m\")", d.key)
+		}
+		duration, err := time.ParseDuration(str)
+		if err != nil || duration < 0 {
+			return timeoutsConf, fmt.Errorf("'%s' has to be a duration (e.g. \"5m\")", d.key)
+		}
+		*d.value = duration
+	}
+	return timeoutsConf, nil
+}
+
+// loadFormat reads the default output format from the configuration file.
+// An empty string is returned when the format is not set.
+func loadFormat(tree *toml.Tree) (string, error) {
+	if tree == nil {
+		return "", nil
+	}
+	value := tree.Get("format")
+	if value == nil {
+		return "", nil
+	}
+	format, ok := value.(string)
+	if !ok || (format != "" && format != "json") {
+		return "", fmt.Errorf("'format' has to be one of the supported formats: \"json\"")
+	}
+	return format, nil
+}
+
+// loadLowBandwidth reads whether the low-bandwidth mode is enabled from the
+// configuration file. It is disabled when not set.
+func loadLowBandwidth(tree *toml.Tree) (bool, error) {
+	if tree == nil {
+		return false, nil
+	}
+	value := tree.Get("low-bandwidth")
+	if value == nil {
+		return false, nil
+	}
+	lowBandwidth, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("'low-bandwidth' has to be a boolean")
+	}
+	return lowBandwidth, nil
+}
+
+// loadServerURL reads the URLs of the entitlement server and of the content
+// delivery network 'rhc connect' configures in rhsm.conf, e.g. of a Satellite
+// or of the stage environment. Empty strings are returned when they are not set.
+func loadServerURL(tree *toml.Tree) (string, string, error) {
+	var serverURL, baseURL string
+	if tree == nil {
+		return serverURL, baseURL, nil
+	}
+	for _, option := range []struct {
+		key   string
+		value *string
+	}{
+		{key: "server-url", value: &serverURL},
+		{key: "base-url", value: &baseURL},
+	} {
+		value := tree.Get(option.key)
+		if value == nil {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return "", "", fmt.Errorf("'%s' has to be a string", option.key)
+		}
+		if parsed, err := url.Parse(str); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return "", "", fmt.Errorf("'%s' has to be an https URL", option.key)
+		}
+		*option.value = str
+	}
+	if baseURL != "" && serverURL == "" {
+		return "", "", fmt.Errorf("'base-url' requires 'server-url'")
+	}
+	return serverURL, baseURL, nil
+}
+
+// loadLogRemote reads the URL of the remote syslog collector from the
+// configuration file. An empty string is returned when it is not set.
+func loadLogRemote(tree *toml.Tree) (string, error) {
+	if tree == nil {
+		return "", nil
+	}
+	value := tree.Get("log-remote")
+	if value == nil {
+		return "", nil
+	}
+	logRemote, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("'log-remote' has to be a string")
+	}
+	if logRemote == "" {
+		return "", nil
+	}
+	if _, _, err := logging.ParseSyslogURL(logRemote); err != nil {
+		return "", fmt.Errorf("'log-remote' has to be a URL of a syslog collector: %w", err)
+	}
+	return logRemote, nil
+}
+
+// maxAssetTagLength limits the length of the asset tag to the length of values
+// of Red Hat Lightspeed tags.
+const maxAssetTagLength = 255
+
+// assetTagName is the name of the Red Hat Lightspeed tag holding the asset tag.
+const assetTagName = "asset-tag"
+
+// loadAssetTag reads the asset tag of the system from the configuration file.
+// An empty string is returned when it is not set.
+func loadAssetTag(tree *toml.Tree) (string, error) {
+	if tree == nil {
+		return "", nil
+	}
+	value := tree.Get("asset-tag")
+	if value == nil {
+		return "", nil
+	}
+	assetTag, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("'asset-tag' has to be a string")
+	}
+	assetTag = strings.TrimSpace(assetTag)
+	if len(assetTag) > maxAssetTagLength || strings.ContainsFunc(assetTag, unicode.IsControl) {
+		return "", fmt.Errorf("'asset-tag' has to be at most %d characters without control characters", maxAssetTagLength)
+	}
+	return assetTag, nil
+}
+
+// loadAuditConf reads the '[audit]' section of the configuration file. The section
+// is optional; nil tree results in the default configuration.
+func loadAuditConf(tree *toml.Tree) (conf.AuditConf, error) {
+	var auditConf conf.AuditConf
+	if tree == nil {
+		return auditConf, nil
+	}
+
+	if value := tree.Get("audit.sign"); value != nil {
+		sign, ok := value.(bool)
+		if !ok {
+			return auditConf, fmt.Errorf("'audit.sign' has to be a boolean")
+		}
+		auditConf.Sign = sign
+	}
+	return auditConf, nil
+}
+
+// loadNotifyConf reads the '[notify]' section of the configuration file. The section
+// is optional; nil tree results in the default configuration.
+func loadNotifyConf(tree *toml.Tree) (conf.NotifyConf, error) {
+	var notifyConf conf.NotifyConf
+	if tree == nil {
+		return notifyConf, nil
+	}
+
+	for _, option := range []struct {
+		key   string
+		value *string
+	}{
+		{key: "notify.url", value: &notifyConf.URL},
+		{key: "notify.secret-file", value: &notifyConf.SecretFile},
+	} {
+		value := tree.Get(option.key)
+		if value == nil {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return notifyConf, fmt.Errorf("'%s' has to be a string", option.key)
+		}
+		*option.value = str
+	}
+
+	if notifyConf.URL != "" && !isWebhookURL(notifyConf.URL) {
+		return notifyConf, fmt.Errorf("'notify.url' has to be an http or https URL")
+	}
+	return notifyConf, nil
+}
+
+// loadConsentConf reads the '[consent]' section of the configuration file. The
+// section is optional; nil tree results in the default configuration.
+func loadConsentConf(tree *toml.Tree) (conf.ConsentConf, error) {
+	var consentConf conf.ConsentConf
+	if tree == nil {
+		return consentConf, nil
+	}
+
+	if value := tree.Get("consent.notice-file"); value != nil {
+		noticeFile, ok := value.(string)
+		if !ok {
+			return consentConf, fmt.Errorf("'consent.notice-file' has to be a string")
+		}
+		if noticeFile != "" && !filepath.IsAbs(noticeFile) {
+			return consentConf, fmt.Errorf("'consent.notice-file' has to be an absolute path")
+		}
+		consentConf.NoticeFile = noticeFile
+	}
+	return consentConf, nil
+}
+
+// loadInsightsConf reads the '[insights]' section of the configuration file.
+// The section is optional; nil tree results in the default configuration.
+func loadInsightsConf(tree *toml.Tree) (conf.InsightsConf, error) {
+	var insightsConf conf.InsightsConf
+	if tree == nil {
+		return insightsConf, nil
+	}
+
+	if value := tree.Get("insights.gateway-url"); value != nil {
+		gatewayURL, ok := value.(string)
+		if !ok {
+			return insightsConf, fmt.Errorf("'insights.gateway-url' has to be a string")
+		}
+		if gatewayURL != "" {
+			parsed, err := url.Parse(gatewayURL)
+			if err != nil || parsed.Scheme != "https" || parsed.Host == "" || parsed.User != nil {
+				return insightsConf, fmt.Errorf("'insights.gateway-url' has to be an https URL without credentials")
+			}
+		}
+		insightsConf.GatewayURL = gatewayURL
+	}
+	return insightsConf, nil
+}
+
+const (
+	defaultAPIURL      = "https://console.redhat.com/api"
+	defaultAPITokenURL = "https://sso.redhat.com/auth/realms/redhat-external/protocol/openid-connect/token"
+)
+
+// loadAPIConf reads the '[api]' section of the configuration file. The client
+// secret of the service account is not read, only the path to its file. The
+// section is optional; nil tree results in the default configuration.
+func loadAPIConf(tree *toml.Tree) (conf.APIConf, error) {
+	apiConf := conf.APIConf{URL: defaultAPIURL, TokenURL: defaultAPITokenURL}
+	if tree == nil {
+		return apiConf, nil
+	}
+
+	for _, option := range []struct {
+		key   string
+		value *string
+	}{
+		{key: "api.url", value: &apiConf.URL},
+		{key: "api.token-url", value: &apiConf.TokenURL},
+		{key: "api.client-id", value: &apiConf.ClientID},
+		{key: "api.client-secret-file", value: &apiConf.ClientSecretFile},
+	} {
+		value := tree.Get(option.key)
+		if value == nil {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return apiConf, fmt.Errorf("'%s' has to be a string", option.key)
+		}
+		*option.value = str
+	}
+
+	for _, option := range []struct {
+		key   string
+		value string
+	}{
+		{key: "api.url", value: apiConf.URL},
+		{key: "api.token-url", value: apiConf.TokenURL},
+	} {
+		parsed, err := url.Parse(option.value)
+		if err != nil || parsed.Scheme != "https" || parsed.Host == "" || parsed.User != nil {
+			return apiConf, fmt.Errorf("'%s' has to be an https URL without credentials", option.key)
+		}
+	}
+	if (apiConf.ClientID == "") != (apiConf.ClientSecretFile == "") {
+		return apiConf, fmt.Errorf("'api.client-id' and 'api.client-secret-file' have to be set together")
+	}
+	if apiConf.ClientSecretFile != "" && !filepath.IsAbs(apiConf.ClientSecretFile) {
+		return apiConf, fmt.Errorf("'api.client-secret-file' has to be an absolute path")
+	}
+	return apiConf, nil
+}
+
+// loadSyspurposeConf reads the '[syspurpose]' section of the configuration
+// file. The section is optional; nil tree results in the default configuration.
+func loadSyspurposeConf(tree *toml.Tree) (conf.SyspurposeConf, error) {
+	var syspurposeConf conf.SyspurposeConf
+	if tree == nil {
+		return syspurposeConf, nil
+	}
+
+	for _, s := range []struct {
+		key   string
+		value *string
+	}{
+		{key: "syspurpose.role", value: &syspurposeConf.Role},
+		{key: "syspurpose.sla", value: &syspurposeConf.SLA},
+		{key: "syspurpose.usage", value: &syspurposeConf.Usage},
+	} {
+		value := tree.Get(s.key)
+		if value == nil {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return syspurposeConf, fmt.Errorf("'%s' has to be a string", s.key)
+		}
+		*s.value = strings.TrimSpace(str)
+	}
+	return syspurposeConf, nil
+}
+
+// loadProfilesConf reads the '[profiles.NAME]' sections of the configuration
+// file. The sections are optional; nil tree results in no profiles.
+func loadProfilesConf(tree *toml.Tree) (map[string]conf.ProfileConf, error) {
+	if tree == nil || tree.Get("profiles") == nil {
+		return nil, nil
+	}
+	profilesTree, ok := tree.Get("profiles").(*toml.Tree)
+	if !ok {
+		return nil, fmt.Errorf("'profiles' has to be a table")
+	}
+
+	profiles := make(map[string]conf.ProfileConf)
+	for _, name := range profilesTree.Keys() {
+		section, ok := profilesTree.Get(name).(*toml.Tree)
+		if !ok {
+			return nil, fmt.Errorf("'profiles.%s' has to be a table", name)
+		}
+
+		var profile conf.ProfileConf
+		for _, option := range []struct {
+			key   string
+			value *string
+		}{
+			{key: "server-url", value: &profile.ServerURL},
+			{key: "base-url", value: &profile.BaseURL},
+			{key: "ca-cert", value: &profile.CACert},
+			{key: "organization", value: &profile.Organization},
+		} {
+			value := section.Get(option.key)
+			if value == nil {
+				continue
+			}
+			str, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("'profiles.%s.%s' has to be a string", name, option.key)
+			}
+			*option.value = str
+		}
+		for _, option := range []struct {
+			key   string
+			value *[]string
+		}{
+			{key: "activation-keys", value: &profile.ActivationKeys},
+			{key: "content-templates", value: &profile.ContentTemplates},
+			{key: "enable-features", value: &profile.EnableFeatures},
+			{key: "disable-features", value: &profile.DisableFeatures},
+		} {
+			value := section.Get(option.key)
+			if value == nil {
+				continue
+			}
+			items, ok := value.([]any)
+			if !ok {
+				return nil, fmt.Errorf("'profiles.%s.%s' has to be an array of strings", name, option.key)
+			}
+			for _, item := range items {
+				str, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("'profiles.%s.%s' has to be an array of strings", name, option.key)
+				}
+				*option.value = append(*option.value, str)
+			}
+		}
+
+		for _, option := range []struct {
+			key   string
+			value string
+		}{
+			{key: "server-url", value: profile.ServerURL},
+			{key: "base-url", value: profile.BaseURL},
+		} {
+			if option.value == "" {
+				continue
+			}
+			if parsed, err := url.Parse(option.value); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+				return nil, fmt.Errorf("'profiles.%s.%s' has to be an https URL", name, option.key)
+			}
+		}
+		if profile.ServerURL == "" {
+			return nil, fmt.Errorf("'profiles.%s.server-url' is required", name)
+		}
+		profiles[name] = profile
+	}
+	return profiles, nil
+}
+
+// loadServersConf reads the '[servers.NAME]' sections of the configuration
+// file, and returns the servers ordered by priority, then by name. The sections
+// are optional; nil tree results in no servers.
+func loadServersConf(tree *toml.Tree) ([]conf.ServerConf, error) {
+	if tree == nil || tree.Get("servers") == nil {
+		return nil, nil
+	}
+	serversTree, ok := tree.Get("servers").(*toml.Tree)
+	if !ok {
+		return nil, fmt.Errorf("'servers' has to be a table")
+	}
+
+	var servers []conf.ServerConf
+	for _, name := range serversTree.Keys() {
+		section, ok := serversTree.Get(name).(*toml.Tree)
+		if !ok {
+			return nil, fmt.Errorf("'servers.%s' has to be a table", name)
+		}
+
+		server := conf.ServerConf{Name: name}
+		for _, option := range []struct {
+			key   string
+			value *string
+		}{
+			{key: "url", value: &server.URL},
+			{key: "base-url", value: &server.BaseURL},
+		} {
+			value := section.Get(option.key)
+			if value == nil {
+				continue
+			}
+			str, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("'servers.%s.%s' has to be a string", name, option.key)
+			}
+			if parsed, err := url.Parse(str); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+				return nil, fmt.Errorf("'servers.%s.%s' has to be an https URL", name, option.key)
+			}
+			*option.value = str
+		}
+		if server.URL == "" {
+			return nil, fmt.Errorf("'servers.%s.url' is required", name)
+		}
+		if value := section.Get("priority"); value != nil {
+			priority, ok := value.(int64)
+			if !ok {
+				return nil, fmt.Errorf("'servers.%s.priority' has to be an integer", name)
+			}
+			server.Priority = int(priority)
+		}
+		servers = append(servers, server)
+	}
+	slices.SortFunc(servers, func(a, b conf.ServerConf) int {
+		return cmp.Or(cmp.Compare(a.Priority, b.Priority), strings.Compare(a.Name, b.Name))
+	})
+	return servers, nil
+}
+
+// loadTags reads the 'tags' table from the configuration file and from the
+// drop-in files in dropInDir. Tags from drop-in files read later override
+// tags with the same name.
+func loadTags(tree *toml.Tree, dropInDir string) (map[string]string, error) {
+	tags := make(map[string]string)
+	if tree != nil {
+		t, err := getStringTable(tree, "tags")
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range t {
+			tags[name] = value
+		}
+	}
+
+	paths, err := conf.DropInPaths(dropInDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		dropIn, err := toml.LoadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		t, err := getStringTable(dropIn, "tags")
+		if err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		for name, value := range t {
+			tags[name] = value
+		}
+	}
+	return tags, nil
+}
+
+// validateConfigTree checks that the sections of tree known to rhc hold valid
+// values, e.g. before a configuration drop-in file is installed.
+func validateConfigTree(tree *toml.Tree) error {
+	loaders := []func(*toml.Tree) error{
+		func(tree *toml.Tree) error { _, err := loadUIConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadFactsConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadProxyConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadNetworkConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadCheckinConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadQueueConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadRetryConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadTimeoutsConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadFormat(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadLogRemote(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadAssetTag(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadLowBandwidth(tree); return err },
+		func(tree *toml.Tree) error { _, _, err := loadServerURL(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadAuditConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadNotifyConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadConsentConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadInsightsConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadAPIConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadSyspurposeConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadProfilesConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := loadServersConf(tree); return err },
+		func(tree *toml.Tree) error { _, err := getStringTable(tree, "tags"); return err },
+	}
+	for _, load := range loaders {
+		if err := load(tree); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,519 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/configbundle"
+	httpapi "github.com/redhatinsights/rhc/internal/http"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// maxBundleSize is the upper bound of the size of a downloaded configuration bundle.
+const maxBundleSize = 1024 * 1024
+
+// ConfigFetchResult is structure holding the result of 'rhc config fetch'.
+// The result could be printed in machine-readable format.
+type ConfigFetchResult struct {
+	URL      string    `json:"url"`
+	Signer   string    `json:"signer"`
+	Files    []string  `json:"files"`
+	Warnings []Warning `json:"warnings"`
+}
+
+// beforeConfigFetchAction ensures the URL argument is an HTTPS URL.
+func beforeConfigFetchAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	if cmd.Args().Len() != 1 {
+		return ctx, cli.Exit(
+			fmt.Sprintf("%s requires URL argument", getFullCommandName(cmd)),
+			exitcode.Usage,
+		)
+	}
+	for _, value := range []string{cmd.Args().First(), cmd.String("signature-url")} {
+		if value == "" {
+			continue
+		}
+		if u, err := url.Parse(value); err != nil || u.Scheme != "https" || u.Host == "" {
+			return ctx, cli.Exit(fmt.Sprintf("invalid URL %q: has to be an https URL", value), exitcode.Usage)
+		}
+	}
+	return ctx, nil
+}
+
+// configFetchAction downloads a signed configuration bundle, verifies its
+// signature with the trusted keys and installs its files into ConfigDropInDir.
+func configFetchAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot fetch configuration", exitcode.NoPerm)
+	}
+
+	bundleURL := cmd.Args().First()
+	signatureURL := cmd.String("signature-url")
+	if signatureURL == "" {
+		signatureURL = bundleURL + ".sig"
+	}
+
+	bundle, err := httpapi.Fetch(ctx, bundleURL, maxBundleSize)
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.Unavailable)
+	}
+	signature, err := httpapi.Fetch(ctx, signatureURL, maxBundleSize)
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.Unavailable)
+	}
+
+	signer, err := configbundle.Verify(bundle, signature, cmd.String("keyring"))
+	if err != nil {
+		slog.Error(err.Error(), "url", bundleURL)
+		return cli.Exit(fmt.Sprintf("cannot verify configuration bundle: %v", err), exitcode.DataErr)
+	}
+	slog.Debug("Verified configuration bundle", "url", bundleURL, "signer", signer)
+
+	files, err := configbundle.Extract(bundle)
+	if err != nil {
+		slog.Error(err.Error(), "url", bundleURL)
+		return cli.Exit(err, exitcode.DataErr)
+	}
+	for _, file := range files {
+		if err = validateConfigTree(file.Tree); err != nil {
+			return cli.Exit(fmt.Sprintf("invalid bundle: %s: %v", file.Name, err), exitcode.DataErr)
+		}
+	}
+
+	paths, err := configbundle.Install(files, ConfigDropInDir)
+	if len(paths) > 0 {
+		recordAudit("config-fetch", map[string]string{
+			"url":    bundleURL,
+			"signer": signer,
+			"files":  strings.Join(paths, ","),
+		})
+	}
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.CantCreat)
+	}
+
+	result := ConfigFetchResult{
+		URL:      bundleURL,
+		Signer:   signer,
+		Files:    paths,
+		Warnings: collectWarnings(),
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print configuration bundle as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+
+	ui.Printf("%s[%v] Verified configuration bundle signed by %s\n", ui.Indent.Small, ui.Icons.Ok, signer)
+	for _, path := range paths {
+		ui.Printf("%s[%v] Installed %s\n", ui.Indent.Small, ui.Icons.Ok, path)
+	}
+	printWarnings(result.Warnings)
+	return nil
+}
+
+// LocalConfigPath is the drop-in file written by 'rhc config set' and
+// 'rhc config unset'. It is read after other drop-in files, so its values take
+// precedence, and it is not replaced by package upgrades.
+var LocalConfigPath = filepath.Join(ConfigDropInDir, "99-local.toml")
+
+// Kinds of values of configuration keys managed by 'rhc config'.
+const (
+	configString = "string"
+	configBool   = "boolean"
+	configList   = "list"
+	configInt    = "integer"
+)
+
+// configKeys are the configuration keys managed by 'rhc config', by the kind
+// of their values. Keys of tables hold any name after the prefix, e.g.
+// "tags.environment".
+var configKeys = map[string]string{
+	"format":                 configString,
+	"log-remote":             configString,
+	"server-url":             configString,
+	"base-url":               configString,
+	"ui.theme":               configString,
+	"ui.json":                configString,
+	"facts.collectors":       configList,
+	"proxy.url":              configString,
+	"proxy.no-proxy":         configList,
+	"proxy.user":             configString,
+	"proxy.password":         configString,
+	"proxy.password-file":    configString,
+	"network.interface":      configString,
+	"network.source-address": configString,
+	"checkin.interval":       configString,
+	"checkin.jitter":         configString,
+	"checkin.splay":          configString,
+	"retry.attempts":         configInt,
+	"retry.delay":            configString,
+	"retry.max-delay":        configString,
+	"timeouts.rhsm":          configString,
+	"timeouts.insights":      configString,
+	"timeouts.activation":    configString,
+	"audit.sign":             configBool,
+	"notify.url":             configString,
+	"notify.secret-file":     configString,
+	"consent.notice-file":    configString,
+	"insights.gateway-url":   configString,
+	"api.url":                configString,
+	"api.token-url":          configString,
+	"api.client-id":          configString,
+	"api.client-secret-file": configString,
+	"asset-tag":              configString,
+	"syspurpose.role":        configString,
+	"syspurpose.sla":         configString,
+	"syspurpose.usage":       configString,
+}
+
+// secretConfigKeys are the configuration keys holding secrets, whose values
+// are not printed by 'rhc config list', logged or recorded in the audit log.
+// 'rhc config set' refuses to store them; their file keys are set instead.
+var secretConfigKeys = map[string]string{"proxy.password": "proxy.password-file"}
+
+// redactedConfigValue replaces values of secretConfigKeys.
+const redactedConfigValue = "xxxxx"
+
+// configTables are the prefixes of keys of tables of strings managed by 'rhc config'.
+var configTables = []string{"tags.", "ui.colors.", "ui.symbols.", "facts.timeouts."}
+
+// ConfigValue is a value of the effective configuration, together with the
+// file setting it.
+type ConfigValue struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+// ConfigListResult is structure holding the effective configuration printed
+// by 'rhc config list'. The result could be printed in machine-readable format.
+type ConfigListResult struct {
+	Values   []ConfigValue `json:"values"`
+	Warnings []Warning     `json:"warnings"`
+}
+
+// configKeyKind returns the kind of the value of key, or an error listing the
+// keys managed by 'rhc config'.
+func configKeyKind(key string) (string, error) {
+	if kind, ok := configKeys[key]; ok {
+		return kind, nil
+	}
+	for _, prefix := range configTables {
+		if name, ok := strings.CutPrefix(key, prefix); ok && name != "" && !strings.Contains(name, ".") {
+			return configString, nil
+		}
+	}
+	keys := slices.Sorted(maps.Keys(configKeys))
+	for _, prefix := range configTables {
+		keys = append(keys, prefix+"NAME")
+	}
+	return "", fmt.Errorf("unsupported key %q (supported keys: %s)", key, strings.Join(keys, ", "))
+}
+
+// parseConfigValue converts value given on the command line to the value of
+// key stored in the configuration file. Items of lists are separated by commas.
+func parseConfigValue(key, value string) (any, error) {
+	kind, err := configKeyKind(key)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case configBool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' has to be a boolean", key)
+		}
+		return parsed, nil
+	case configInt:
+		// Integers read from configuration files are int64
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' has to be an integer", key)
+		}
+		return parsed, nil
+	case configList:
+		// Arrays read from configuration files hold values of any type
+		items := []any{}
+		for item := range strings.SplitSeq(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	default:
+		return value, nil
+	}
+}
+
+// formatConfigValue returns value of the configuration in the format accepted
+// by 'rhc config set'.
+func formatConfigValue(value any) string {
+	switch value := value.(type) {
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// configValues returns the values of the effective configuration of layers,
+// ordered by their keys. Tables are flattened into dotted keys, and values of
+// secretConfigKeys are redacted.
+func configValues(layers []conf.Layer) []ConfigValue {
+	merged := conf.Merge(layers)
+	if merged == nil {
+		return []ConfigValue{}
+	}
+	values := []ConfigValue{}
+	var flatten func(tree *toml.Tree, prefix string)
+	flatten = func(tree *toml.Tree, prefix string) {
+		for _, key := range tree.Keys() {
+			value := tree.GetPath([]string{key})
+			if subtree, ok := value.(*toml.Tree); ok {
+				flatten(subtree, prefix+key+".")
+				continue
+			}
+			if _, ok := secretConfigKeys[prefix+key]; ok {
+				value = redactedConfigValue
+			}
+			values = append(values, ConfigValue{Key: prefix + key, Value: value, Source: conf.Source(layers, prefix+key)})
+		}
+	}
+	flatten(merged, "")
+	slices.SortFunc(values, func(a, b ConfigValue) int { return strings.Compare(a.Key, b.Key) })
+	return values
+}
+
+// beforeConfigKeyAction ensures the KEY argument, and the VALUE argument of
+// 'rhc config set', have been supplied.
+func beforeConfigKeyAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	args := 1
+	usage := "KEY argument"
+	if cmd.Name == "set" {
+		args = 2
+		usage = "KEY and VALUE arguments"
+	}
+	if cmd.Args().Len() != args {
+		return ctx, cli.Exit(
+			fmt.Sprintf("%s requires %s", getFullCommandName(cmd), usage),
+			exitcode.Usage,
+		)
+	}
+	if _, err = configKeyKind(cmd.Args().First()); err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// beforeConfigListAction ensures the user has supplied a correct `--format` flag.
+func beforeConfigListAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// loadConfigLayers reads the configuration file and its drop-in files.
+func loadConfigLayers(cmd *cli.Command) ([]conf.Layer, error) {
+	layers, err := conf.LoadLayers(cmd.Root().String("config"), ConfigDropInDir)
+	if err != nil {
+		return nil, cli.Exit(err.Error(), exitcode.Config)
+	}
+	return layers, nil
+}
+
+// configGetAction prints the effective value of KEY.
+func configGetAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	key := cmd.Args().First()
+	layers, err := loadConfigLayers(cmd)
+	if err != nil {
+		return err
+	}
+	merged := conf.Merge(layers)
+	if merged == nil || !merged.Has(key) {
+		return cli.Exit(fmt.Sprintf("%s is not set", key), exitcode.Err)
+	}
+	value := ConfigValue{Key: key, Value: merged.Get(key), Source: conf.Source(layers, key)}
+	if _, ok := value.Value.(*toml.Tree); ok {
+		return cli.Exit(fmt.Sprintf("%s is not set", key), exitcode.Err)
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(value); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print configuration as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+	fmt.Println(formatConfigValue(value.Value))
+	return nil
+}
+
+// configListAction prints the effective configuration with the files setting
+// the values.
+func configListAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	layers, err := loadConfigLayers(cmd)
+	if err != nil {
+		return err
+	}
+	result := ConfigListResult{Values: configValues(layers), Warnings: collectWarnings()}
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print configuration as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+
+	var rows [][]string
+	for _, value := range result.Values {
+		rows = append(rows, []string{value.Key, formatConfigValue(value.Value), value.Source})
+	}
+	ui.PrintTable([]string{"KEY", "VALUE", "SOURCE"}, rows)
+	printWarnings(result.Warnings)
+	return nil
+}
+
+// configSetAction sets KEY to VALUE in LocalConfigPath. The change is written
+// only when the resulting configuration is valid.
+func configSetAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot change configuration", exitcode.NoPerm)
+	}
+
+	key := cmd.Args().Get(0)
+	if fileKey, ok := secretConfigKeys[key]; ok {
+		return cli.Exit(
+			fmt.Sprintf("%s is not stored by 'rhc config set'; write it to a file readable by root only, and set %s to its path", key, fileKey),
+			exitcode.Usage,
+		)
+	}
+	value, err := parseConfigValue(key, cmd.Args().Get(1))
+	if err != nil {
+		return cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return updateLocalConfig(cmd, key, func(tree *toml.Tree) error {
+		tree.Set(key, value)
+		return nil
+	})
+}
+
+// configUnsetAction removes KEY from LocalConfigPath.
+func configUnsetAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot change configuration", exitcode.NoPerm)
+	}
+
+	key := cmd.Args().First()
+	return updateLocalConfig(cmd, key, func(tree *toml.Tree) error {
+		if !conf.Unset(tree, key) {
+			return cli.Exit(fmt.Sprintf("%s is not set in %s", key, LocalConfigPath), exitcode.Usage)
+		}
+		return nil
+	})
+}
+
+// updateLocalConfig applies change of key to LocalConfigPath, validates the
+// resulting configuration and writes it.
+func updateLocalConfig(cmd *cli.Command, key string, change func(*toml.Tree) error) error {
+	layers, err := loadConfigLayers(cmd)
+	if err != nil {
+		return err
+	}
+	local, err := conf.LoadDropIn(LocalConfigPath)
+	if err != nil {
+		return cli.Exit(err.Error(), exitcode.Config)
+	}
+	if err = change(local); err != nil {
+		return err
+	}
+
+	// Replace the local drop-in file in the layers, so it is validated together with the others
+	layers = slices.DeleteFunc(layers, func(layer conf.Layer) bool { return layer.Path == LocalConfigPath })
+	layers = append(layers, conf.Layer{Path: LocalConfigPath, Tree: local})
+	if err = validateConfigTree(conf.Merge(layers)); err != nil {
+		return cli.Exit(fmt.Sprintf("invalid configuration: %v", err), exitcode.DataErr)
+	}
+	if err = conf.WriteDropIn(LocalConfigPath, local); err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.CantCreat)
+	}
+
+	value := "(unset)"
+	if _, ok := secretConfigKeys[key]; ok && local.Has(key) {
+		value = redactedConfigValue
+	} else if local.Has(key) {
+		value = formatConfigValue(local.Get(key))
+	}
+	slog.Info("Configuration changed", "key", key, "value", value, "path", LocalConfigPath)
+	recordAudit("config-"+cmd.Name, map[string]string{"key": key, "value": value, "path": LocalConfigPath})
+
+	ui.Printf("%s[%v] Updated %s\n", ui.Indent.Small, ui.Icons.Ok, LocalConfigPath)
+	if source := conf.Source(layers, key); source != "" && source != LocalConfigPath {
+		ui.Printf("%s[%v] %s is still set in %s\n", ui.Indent.Small, ui.Icons.Info, key, source)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseConfigValue(t *testing.T) {
+	tests := []struct {
+		key       string
+		value     string
+		want      any
+		wantError bool
+	}{
+		{key: "proxy.url", value: "http://proxy:3128", want: "http://proxy:3128"},
+		{key: "proxy.no-proxy", value: "a.example.com, b.example.com,", want: []any{"a.example.com", "b.example.com"}},
+		{key: "audit.sign", value: "true", want: true},
+		{key: "audit.sign", value: "yes", wantError: true},
+		{key: "retry.attempts", value: "5", want: int64(5)},
+		{key: "retry.attempts", value: "five", wantError: true},
+		{key: "tags.environment", value: "production", want: "production"},
+		{key: "tags.", value: "production", wantError: true},
+		{key: "tags.a.b", value: "production", wantError: true},
+		{key: "profiles.staging.organization", value: "1234", wantError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.key+"="+test.value, func(t *testing.T) {
+			got, err := parseConfigValue(test.key, test.value)
+			if test.wantError {
+				if err == nil {
+					t.Errorf("got %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
+
+func TestFormatConfigValue(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{value: "json", want: "json"},
+		{value: true, want: "true"},
+		{value: []any{"a.example.com", "b.example.com"}, want: "a.example.com,b.example.com"},
+	}
+
+	for _, test := range tests {
+		if got := formatConfigValue(test.value); got != test.want {
+			t.Errorf("got %q, want %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/remotemanagement"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// LegacyConfigPath is the configuration file shared by earlier versions of rhc
+// and rhcd. The package upgrade saves it under this name, when it was changed
+// locally.
+const LegacyConfigPath = "/etc/rhc/config.toml.rpmsave"
+
+// MigratedConfigPath is the drop-in file written by 'rhc config migrate'. It is
+// read before LocalConfigPath, so values set by 'rhc config set' take
+// precedence.
+var MigratedConfigPath = filepath.Join(ConfigDropInDir, "50-migrated.toml")
+
+// rhcdConfigKeys are the keys of the legacy configuration file read only by
+// rhcd, mapped to the keys of the configuration of yggdrasil replacing them.
+var rhcdConfigKeys = map[string]string{
+	"broker":      "server",
+	"protocol":    "protocol",
+	"data-host":   "data-host",
+	"ca-root":     "ca-root",
+	"client-id":   "client-id",
+	"path-prefix": "path-prefix",
+	"facts-file":  "facts-file",
+}
+
+// ConfigMigrateResult is structure holding the result of 'rhc config migrate'.
+// The result could be printed in machine-readable format.
+type ConfigMigrateResult struct {
+	Source string `json:"source"`
+	// Path is the drop-in file written; it is empty, when there was nothing
+	// to migrate.
+	Path     string    `json:"path"`
+	Keys     []string  `json:"keys"`
+	Warnings []Warning `json:"warnings"`
+}
+
+// migrateLegacyConfig returns the configuration of legacy, which is still read
+// by rhc, and warnings about values that cannot be migrated. Settings of rhcd
+// belong to the configuration of yggdrasil now, and the log level "trace" of
+// rhcd is lowered to "debug".
+func migrateLegacyConfig(legacy *toml.Tree) (*toml.Tree, []Warning, error) {
+	migrated, err := toml.TreeFromMap(map[string]any{})
+	if err != nil {
+		return nil, nil, err
+	}
+	var warnings []Warning
+	for _, key := range legacy.Keys() {
+		value := legacy.Get(key)
+		if yggdrasilKey, ok := rhcdConfigKeys[key]; ok {
+			warnings = append(warnings, Warning{
+				Code: "rhcd-config",
+				Message: fmt.Sprintf(
+					"%s is a setting of rhcd, which is not migrated; set '%s' in %s instead",
+					key, yggdrasilKey, remotemanagement.YggdrasilConfigPath,
+				),
+			})
+			continue
+		}
+		if key == cliLogLevel {
+			level, ok := value.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("%s is not a string", key)
+			}
+			if strings.EqualFold(level, "trace") {
+				warnings = append(warnings, Warning{
+					Code:    "log-level",
+					Message: fmt.Sprintf("log level %q is not supported, %q is used instead", level, "debug"),
+				})
+				value = "debug"
+			} else if err = new(slog.Level).UnmarshalText([]byte(level)); err != nil {
+				warnings = append(warnings, Warning{
+					Code:    "log-level",
+					Message: fmt.Sprintf("log level %q is not supported, it is not migrated", level),
+				})
+				continue
+			}
+		}
+		migrated.Set(key, value)
+	}
+	return migrated, warnings, nil
+}
+
+// beforeConfigMigrateAction ensures at most one FILE argument is given.
+func beforeConfigMigrateAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	if cmd.Args().Len() > 1 {
+		return ctx, cli.Exit(
+			fmt.Sprintf("%s accepts at most one FILE argument", getFullCommandName(cmd)),
+			exitcode.Usage,
+		)
+	}
+	return ctx, nil
+}
+
+// configMigrateAction converts the legacy configuration file of rhc and rhcd
+// into the drop-in file MigratedConfigPath. The drop-in file is written only
+// when the resulting configuration is valid; the legacy file is kept.
+func configMigrateAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot change configuration", exitcode.NoPerm)
+	}
+
+	source := LegacyConfigPath
+	if cmd.Args().Present() {
+		source = cmd.Args().First()
+	}
+	legacy, err := toml.LoadFile(source)
+	if errors.Is(err, os.ErrNotExist) {
+		return cli.Exit(fmt.Sprintf("no legacy configuration file %s", source), exitcode.NoInput)
+	}
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("invalid config file %s: %v", source, err), exitcode.DataErr)
+	}
+	migrated, warnings, err := migrateLegacyConfig(legacy)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("invalid config file %s: %v", source, err), exitcode.DataErr)
+	}
+	for _, warning := range warnings {
+		slog.Warn(warning.Message, "code", warning.Code, "path", source)
+	}
+
+	result := ConfigMigrateResult{Source: source, Keys: migrated.Keys()}
+	slices.Sort(result.Keys)
+	if len(result.Keys) > 0 {
+		layers, err := loadConfigLayers(cmd)
+		if err != nil {
+			return err
+		}
+		// Replace the migrated drop-in file in the layers, so it is validated together with the others
+		layers = slices.DeleteFunc(layers, func(layer conf.Layer) bool { return layer.Path == MigratedConfigPath })
+		layers = append(layers, conf.Layer{Path: MigratedConfigPath, Tree: migrated})
+		if err = validateConfigTree(conf.Merge(layers)); err != nil {
+			return cli.Exit(fmt.Sprintf("invalid configuration: %v", err), exitcode.DataErr)
+		}
+		if err = conf.WriteDropIn(MigratedConfigPath, migrated); err != nil {
+			slog.Error(err.Error())
+			return cli.Exit(err, exitcode.CantCreat)
+		}
+		result.Path = MigratedConfigPath
+		slog.Info("Configuration migrated", "source", source, "path", MigratedConfigPath, "keys", strings.Join(result.Keys, ","))
+		recordAudit("config-migrate", map[string]string{
+			"source": source,
+			"path":   MigratedConfigPath,
+			"keys":   strings.Join(result.Keys, ","),
+		})
+	}
+	result.Warnings = append(warnings, collectWarnings()...)
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print migrated configuration as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+
+	if result.Path == "" {
+		ui.Printf("%s[%v] Nothing to migrate from %s\n", ui.Indent.Small, ui.Icons.Info, source)
+	} else {
+		ui.Printf("%s[%v] Migrated %s from %s to %s\n", ui.Indent.Small, ui.Icons.Ok, strings.Join(result.Keys, ", "), source, result.Path)
+	}
+	printWarnings(result.Warnings)
+	return nil
+}
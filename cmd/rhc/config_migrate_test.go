@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pelletier/go-toml"
+	altsrc "github.com/urfave/cli-altsrc/v3"
+)
+
+func TestMigrateLegacyConfig(t *testing.T) {
+	tests := []struct {
+		description  string
+		input        string
+		want         map[string]any
+		wantWarnings []string
+		wantError    bool
+	}{
+		{
+			description: "rhcd",
+			input: "broker = [\"wss://connect.cloud.redhat.com:443\"]\nprotocol = \"mqtt\"\n" +
+				"cert-file = \"/etc/pki/consumer/cert.pem\"\nkey-file = \"/etc/pki/consumer/key.pem\"\nlog-level = \"error\"\n",
+			want: map[string]any{
+				"cert-file": "/etc/pki/consumer/cert.pem",
+				"key-file":  "/etc/pki/consumer/key.pem",
+				"log-level": "error",
+			},
+			wantWarnings: []string{"rhcd-config", "rhcd-config"},
+		},
+		{
+			description:  "trace",
+			input:        "log-level = \"trace\"\n",
+			want:         map[string]any{"log-level": "debug"},
+			wantWarnings: []string{"log-level"},
+		},
+		{
+			description:  "unsupported log level",
+			input:        "log-level = \"verbose\"\n",
+			want:         map[string]any{},
+			wantWarnings: []string{"log-level"},
+		},
+		{
+			description: "tables",
+			input:       "[proxy]\nurl = \"http://proxy.example.com:3128\"\n",
+			want:        map[string]any{"proxy": map[string]any{"url": "http://proxy.example.com:3128"}},
+		},
+		{
+			description: "invalid log level",
+			input:       "log-level = 1\n",
+			wantError:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			legacy, err := toml.Load(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, warnings, err := migrateLegacyConfig(legacy)
+			if test.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(got.ToMap(), test.want) {
+				t.Errorf("%v", cmp.Diff(got.ToMap(), test.want))
+			}
+			var codes []string
+			for _, warning := range warnings {
+				codes = append(codes, warning.Code)
+			}
+			if !cmp.Equal(codes, test.wantWarnings) {
+				t.Errorf("%v", cmp.Diff(codes, test.wantWarnings))
+			}
+		})
+	}
+}
+
+func TestConfigValueSources(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.toml":      "log-level = \"info\"\ncert-file = \"/etc/pki/consumer/cert.pem\"\n",
+		"50-migrated.toml": "log-level = \"debug\"\nkey-file = \"/etc/pki/consumer/key.pem\"\n",
+		"99-local.toml":    "log-level = \"error\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	configPath := filepath.Join(dir, "config.toml")
+	dropInPaths := []string{filepath.Join(dir, "50-migrated.toml"), filepath.Join(dir, "99-local.toml")}
+
+	want := map[string]string{
+		cliLogLevel: "error",
+		cliCertFile: "/etc/pki/consumer/cert.pem",
+		cliKeyFile:  "/etc/pki/consumer/key.pem",
+	}
+	for key, wantValue := range want {
+		sources := configValueSources(key, altsrc.NewStringPtrSourcer(&configPath), dropInPaths)
+		got, ok := sources.Lookup()
+		if !ok || got != wantValue {
+			t.Errorf("%s: got %q (%v), want %q", key, got, ok, wantValue)
+		}
+	}
+}
@@ -28,6 +28,7 @@ type ConfigureFeaturesStatus struct {
 		Analytics        ConfigureFeatureStatus `json:"analytics"`
 		RemoteManagement ConfigureFeatureStatus `json:"remote_management"`
 	} `json:"features"`
+	Warnings   []Warning `json:"warnings"`
 	returnCode int
 }
 
@@ -114,7 +115,7 @@ func featuresStatusActionNotRegistered(_ context.Context, cmd *cli.Command) erro
 		return err
 	}
 
-	var status = ConfigureFeaturesStatus{Connected: false}
+	var status = ConfigureFeaturesStatus{Connected: false, Warnings: collectWarnings()}
 	headers := []string{"FEATURE", "PREFERENCE", "DESCRIPTION"}
 	rows := [][]string{}
 	for _, f := range feature.All() {
@@ -142,7 +143,7 @@ func featuresStatusActionNotRegistered(_ context.Context, cmd *cli.Command) erro
 }
 
 func featuresStatusActionRegistered(_ context.Context, cmd *cli.Command) (err error) {
-	var status = ConfigureFeaturesStatus{Connected: true}
+	var status = ConfigureFeaturesStatus{Connected: true, Warnings: collectWarnings()}
 	headers := []string{"FEATURE", "STATE", "DESCRIPTION"}
 	rows := [][]string{}
 	for _, f := range feature.All() {
@@ -258,7 +259,19 @@ func featuresEnableActionNotRegistered(_ context.Context, _ *cli.Command, target
 }
 
 // featuresEnableActionRegistered handles enabling a feature on a registered system.
-func featuresEnableActionRegistered(_ context.Context, _ *cli.Command, targetNames []string) error {
+func featuresEnableActionRegistered(ctx context.Context, cmd *cli.Command, targetNames []string) error {
+	wanted := map[string]bool{}
+	for _, targetName := range targetNames {
+		for id := range featureChanges(targetName, true) {
+			wanted[id] = true
+		}
+	}
+	consent, err := checkAnalyticsConsent(cmd, wanted)
+	if err != nil {
+		return err
+	}
+	recordConsent(consent)
+
 	for _, targetName := range targetNames {
 		target := feature.MustGet(targetName)
 		// enable required features
@@ -272,7 +285,7 @@ func featuresEnableActionRegistered(_ context.Context, _ *cli.Command, targetNam
 				slog.Debug("feature is already enabled", "feature", requiredName)
 				continue
 			}
-			if err = required.Enable(); err != nil {
+			if err = required.Enable(ctx); err != nil {
 				return cli.Exit(fmt.Sprintf("failed to enable required feature '%s': %v", requiredName, err), exitcode.Software)
 			}
 			fmt.Printf("Feature '%s' enabled (required by '%s').\n", requiredName, targetName)
@@ -287,13 +300,14 @@ func featuresEnableActionRegistered(_ context.Context, _ *cli.Command, targetNam
 				slog.Debug("feature is already enabled", "feature", targetName)
 				continue
 			}
-			if err = target.Enable(); err != nil {
+			if err = target.Enable(ctx); err != nil {
 				return cli.Exit(fmt.Sprintf("failed to enable target feature '%s': %v", targetName, err), exitcode.Software)
 			}
 			fmt.Printf("Feature '%s' enabled.\n", targetName)
 		}
 	}
 
+	recordFeatureSelection()
 	return nil
 }
 
@@ -386,7 +400,7 @@ func featuresDisableActionNotRegistered(_ context.Context, _ *cli.Command, targe
 }
 
 // featuresDisableActionRegistered handles disabling a feature on a registered system.
-func featuresDisableActionRegistered(_ context.Context, _ *cli.Command, targetNames []string) error {
+func featuresDisableActionRegistered(ctx context.Context, _ *cli.Command, targetNames []string) error {
 	for _, targetName := range targetNames {
 		target := feature.MustGet(targetName)
 		// disable dependent features
@@ -400,7 +414,7 @@ func featuresDisableActionRegistered(_ context.Context, _ *cli.Command, targetNa
 				slog.Debug("feature is already disabled", "feature", dependentName)
 				continue
 			}
-			if err = dependent.Disable(); err != nil {
+			if err = dependent.Disable(ctx); err != nil {
 				return cli.Exit(fmt.Sprintf("failed to disable dependent feature '%s': %v", dependentName, err), exitcode.Software)
 			}
 			fmt.Printf("Feature '%s' disabled (depends on '%s').\n", dependentName, targetName)
@@ -415,12 +429,13 @@ func featuresDisableActionRegistered(_ context.Context, _ *cli.Command, targetNa
 				slog.Debug("feature is already disabled", "feature", targetName)
 				continue
 			}
-			if err = target.Disable(); err != nil {
+			if err = target.Disable(ctx); err != nil {
 				return cli.Exit(fmt.Sprintf("failed to disable target feature '%s': %v", targetName, err), exitcode.Software)
 			}
 			fmt.Printf("Feature '%s' disabled.\n", targetName)
 		}
 	}
 
+	recordFeatureSelection()
 	return nil
 }
@@ -2,20 +2,25 @@ package main
 
 import (
 	"bufio"
+	"cmp"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"regexp"
 	"strings"
 	"text/tabwriter"
 	"time"
+	"unicode"
 
 	"github.com/briandowns/spinner"
 	"github.com/urfave/cli/v3"
 	"golang.org/x/term"
 
+	"github.com/redhatinsights/rhc/internal/cleanup"
+	"github.com/redhatinsights/rhc/internal/cloud"
+	"github.com/redhatinsights/rhc/internal/conf"
 	"github.com/redhatinsights/rhc/internal/datacollection"
 	"github.com/redhatinsights/rhc/internal/remotemanagement"
 	"github.com/redhatinsights/rhc/internal/subman"
@@ -29,23 +34,65 @@ type FeatureResult struct {
 	Enabled    bool   `json:"enabled"`
 	Successful bool   `json:"successful"`
 	Error      string `json:"error,omitempty"`
+	ErrorCode  string `json:"error_code,omitempty"`
 	Skipped    bool   `json:"skipped,omitempty"`
+	// Unchanged is true when the feature already was enabled.
+	Unchanged bool `json:"unchanged,omitempty"`
+	// UploadPending is true when the system is registered with Red Hat
+	// Lightspeed, but its initial upload failed and is retried in the background.
+	UploadPending bool `json:"upload_pending,omitempty"`
+	// Diagnostics describe the unit that failed to activate.
+	Diagnostics *remotemanagement.UnitDiagnostics `json:"diagnostics,omitempty"`
+}
+
+// ContentCheckResult is the result of the content access smoke test.
+type ContentCheckResult struct {
+	Repository string `json:"repository,omitempty"`
+	Successful bool   `json:"successful"`
+	Error      string `json:"error,omitempty"`
+	ErrorCode  string `json:"error_code,omitempty"`
 }
 
 // ConnectResult is an external DTO representing the result of 'rhc connect' user action.
+// Error messages may be translated by the services reporting them; the error codes
+// next to them are stable identifiers for machine-readable output.
 type ConnectResult struct {
-	Hostname         string `json:"hostname"`
-	HostnameError    string `json:"hostname_error,omitempty"`
-	UID              int    `json:"uid"`
-	UIDError         string `json:"uid_error,omitempty"`
-	RHSMConnected    bool   `json:"rhsm_connected"`
-	RHSMConnectError string `json:"rhsm_connect_error,omitempty"`
-	Features         struct {
+	Hostname             string `json:"hostname"`
+	HostnameError        string `json:"hostname_error,omitempty"`
+	UID                  int    `json:"uid"`
+	UIDError             string `json:"uid_error,omitempty"`
+	RHSMConnected        bool   `json:"rhsm_connected"`
+	RHSMConnectError     string `json:"rhsm_connect_error,omitempty"`
+	RHSMConnectErrorCode string `json:"rhsm_connect_error_code,omitempty"`
+	// RHSMUnchanged is true when the system already was registered.
+	RHSMUnchanged bool   `json:"rhsm_unchanged,omitempty"`
+	Server        string `json:"server,omitempty"`
+	// CloudProvider is the cloud whose instance identity the system was
+	// registered with, see --auto.
+	CloudProvider string `json:"cloud_provider,omitempty"`
+	// Syspurpose is the system purpose set before registration.
+	Syspurpose   *subman.Syspurpose  `json:"syspurpose,omitempty"`
+	ContentCheck *ContentCheckResult `json:"content_check,omitempty"`
+	Warnings     []Warning           `json:"warnings"`
+	// Changed is false when the system already was connected with the
+	// requested features, so nothing was changed.
+	Changed  bool       `json:"changed"`
+	DryRun   bool       `json:"dry_run,omitempty"`
+	Plan     []PlanStep `json:"plan,omitempty"`
+	Features struct {
 		Content          FeatureResult `json:"content"`
 		Analytics        FeatureResult `json:"analytics"`
 		RemoteManagement FeatureResult `json:"remote_management"`
 	} `json:"features"`
-	format string
+	// PlaybookKeys lists the keys used to verify playbooks run by remote management.
+	PlaybookKeys []remotemanagement.PlaybookKey `json:"playbook_keys,omitempty"`
+	Timeline     Timeline                       `json:"timeline,omitempty"`
+	// Signature attests the result was produced by the host, see --sign-result.
+	Signature *ResultSignature `json:"signature,omitempty"`
+	format    string
+	// restoreServer restores the entitlement server configured before
+	// TryConfigureServer, see TryRestoreServer.
+	restoreServer func() error
 }
 
 // Error implement error interface for structure ConnectResult
@@ -53,7 +100,7 @@ func (connectResult *ConnectResult) Error() string {
 	var result string
 	switch connectResult.format {
 	case "json":
-		data, err := json.MarshalIndent(connectResult, "", "    ")
+		data, err := ui.MarshalJSON(connectResult)
 		if err != nil {
 			return err.Error()
 		}
@@ -71,6 +118,12 @@ func (connectResult *ConnectResult) errorMessages() map[string]string {
 	if connectResult.RHSMConnectError != "" {
 		errorMessages["rhsm"] = connectResult.RHSMConnectError
 	}
+	if connectResult.Features.Content.Error != "" {
+		errorMessages["content"] = connectResult.Features.Content.Error
+	}
+	if connectResult.ContentCheck != nil && connectResult.ContentCheck.Error != "" {
+		errorMessages["content"] = connectResult.ContentCheck.Error
+	}
 	if connectResult.Features.Analytics.Error != "" && !connectResult.Features.Analytics.Skipped {
 		errorMessages["insights"] = connectResult.Features.Analytics.Error
 	}
@@ -80,11 +133,12 @@ func (connectResult *ConnectResult) errorMessages() map[string]string {
 	return errorMessages
 }
 
-// rhsmFailed records an RHSM registration failure into the result and prints
-// the appropriate error messages.
-func (connectResult *ConnectResult) rhsmFailed(msg string) {
+// rhsmFailed records an RHSM registration failure identified by code into the
+// result and prints the appropriate error messages.
+func (connectResult *ConnectResult) rhsmFailed(code string, msg string) {
 	connectResult.RHSMConnected = false
 	connectResult.RHSMConnectError = msg
+	connectResult.RHSMConnectErrorCode = code
 	connectResult.Features.Content.Successful = false
 	slog.Error(msg)
 	ui.Printf(
@@ -100,15 +154,138 @@ func (connectResult *ConnectResult) rhsmFailed(msg string) {
 	)
 }
 
+// changed reports whether the connection changed the system: it was registered,
+// or a feature was enabled, which was not enabled before.
+func (connectResult *ConnectResult) changed() bool {
+	if !connectResult.RHSMUnchanged {
+		return true
+	}
+	for _, result := range []FeatureResult{
+		connectResult.Features.Content,
+		connectResult.Features.Analytics,
+		connectResult.Features.RemoteManagement,
+	} {
+		if result.Successful && !result.Unchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// keepFeature reports whether the feature id is enabled already, and marks it
+// unchanged in result when it is. It is used to connect registered systems
+// again without changing the enabled features.
+func keepFeature(id string, result *FeatureResult) bool {
+	enabled, err := feature.MustGet(id).IsEnabled()
+	if err != nil {
+		slog.Debug("cannot check state of feature", "feature", id, "err", err)
+		return false
+	}
+	if enabled {
+		result.Successful = true
+		result.Unchanged = true
+	}
+	return enabled
+}
+
+// TryKeepRHSM reports the registration of a system registered already as
+// unchanged. The content is enabled, when enableContent is true and it is not
+// enabled yet; it is never disabled.
+func (connectResult *ConnectResult) TryKeepRHSM(ctx context.Context, enableContent bool) {
+	connectResult.RHSMConnected = true
+	connectResult.RHSMUnchanged = true
+	slog.Info("System is already registered with Red Hat Subscription Management")
+	ui.Printf("%s[%v] Already connected to Red Hat Subscription Management\n", ui.Indent.Small, ui.Icons.Ok)
+
+	if keepFeature("content", &connectResult.Features.Content) {
+		infoMsg := "System already has access to content"
+		slog.Info(infoMsg)
+		ui.Printf("%s[%v] Content ... %v\n", ui.Indent.Medium, ui.Icons.Ok, infoMsg)
+		return
+	}
+	if !enableContent {
+		infoMsg := "System has no access to content"
+		slog.Info(infoMsg)
+		ui.Printf("%s[ ] Content ... %v\n", ui.Indent.Medium, infoMsg)
+		return
+	}
+	if err := feature.MustGet("content").Enable(ctx); err != nil {
+		connectResult.Features.Content.Successful = false
+		connectResult.Features.Content.Error = fmt.Sprintf("cannot enable content: %v", err)
+		connectResult.Features.Content.ErrorCode = cmp.Or(subman.ErrorCode(err), "content-failed")
+		slog.Error(connectResult.Features.Content.Error)
+		ui.Printf("%s[%v] Content ... Cannot enable content\n", ui.Indent.Medium, ui.Icons.Error)
+		return
+	}
+	connectResult.Features.Content.Successful = true
+	infoMsg := "System has access to content"
+	slog.Info(infoMsg)
+	ui.Printf("%s[%v] Content ... %v\n", ui.Indent.Medium, ui.Icons.Ok, infoMsg)
+}
+
+// connectSyspurpose returns the system purpose set by --role, --sla and --usage,
+// or by the '[syspurpose]' section of the configuration file.
+func connectSyspurpose(cmd *cli.Command) subman.Syspurpose {
+	purpose := subman.Syspurpose{
+		Role:         conf.Config.Syspurpose.Role,
+		ServiceLevel: conf.Config.Syspurpose.SLA,
+		Usage:        conf.Config.Syspurpose.Usage,
+	}
+	if cmd.IsSet("role") {
+		purpose.Role = strings.TrimSpace(cmd.String("role"))
+	}
+	if cmd.IsSet("sla") {
+		purpose.ServiceLevel = strings.TrimSpace(cmd.String("sla"))
+	}
+	if cmd.IsSet("usage") {
+		purpose.Usage = strings.TrimSpace(cmd.String("usage"))
+	}
+	return purpose
+}
+
+// connectInsightsRegistration returns the display name and the inventory group
+// set by --display-name and --group.
+func connectInsightsRegistration(cmd *cli.Command) datacollection.InsightsRegistration {
+	return datacollection.InsightsRegistration{
+		DisplayName: strings.TrimSpace(cmd.String("display-name")),
+		Group:       strings.TrimSpace(cmd.String("group")),
+	}
+}
+
+// TrySetSyspurpose sets the system purpose, so it is sent to the entitlement
+// server on registration. The registration does not depend on the system
+// purpose, so a failure to set it is reported as a warning.
+func (connectResult *ConnectResult) TrySetSyspurpose(client *subman.RHSMClient, purpose subman.Syspurpose) {
+	if err := client.SetSyspurpose(purpose); err != nil {
+		warning := Warning{Code: "syspurpose", Message: err.Error()}
+		slog.Warn(warning.Message, "code", warning.Code)
+		connectResult.Warnings = append(connectResult.Warnings, warning)
+		ui.Printf("%s[%v] Warning: %s\n", ui.Indent.Small, ui.Icons.Warning, warning.Message)
+		return
+	}
+	connectResult.Syspurpose = &purpose
+	slog.Info("System purpose set", "role", purpose.Role, "sla", purpose.ServiceLevel, "usage", purpose.Usage)
+	recordAudit("syspurpose-set", map[string]string{
+		"role":  purpose.Role,
+		"sla":   purpose.ServiceLevel,
+		"usage": purpose.Usage,
+	})
+	ui.Printf("%s[%v] Set system purpose\n", ui.Indent.Small, ui.Icons.Ok)
+}
+
 // TryRegisterRHSM will attempt to register the system with Red Hat Subscription Management.
 // If this fails, then both RHSMConnected and Features.Content.Successful will be set to false,
-// and the error message will be stored in RHSMConnectError.
-func (connectResult *ConnectResult) TryRegisterRHSM(cmd *cli.Command, enableContent bool) {
+// and the error message will be stored in RHSMConnectError. Every attempt to register
+// is limited by the '[timeouts]' section or --timeout; prompts are not.
+func (connectResult *ConnectResult) TryRegisterRHSM(ctx context.Context, cmd *cli.Command, enableContent bool) {
 	slog.Info("Registering the system with Red Hat Subscription Management")
 
 	client, err := subman.NewRHSMClient()
 	if err != nil {
-		connectResult.rhsmFailed(fmt.Sprintf("cannot connect to subscription-manager: %s", err))
+		connectResult.rhsmFailed(
+			cmp.Or(subman.ErrorCode(err), "rhsm-unavailable"),
+			fmt.Sprintf("cannot connect to subscription-manager: %s", err),
+		)
 		return
 	}
 
@@ -118,7 +295,11 @@ func (connectResult *ConnectResult) TryRegisterRHSM(cmd *cli.Command, enableCont
 	activationKeys := cmd.StringSlice("activation-key")
 	contentTemplates := cmd.StringSlice("content-template")
 
-	if len(activationKeys) == 0 {
+	// The cloud provider is detected by beforeConnectAction, when --auto is used
+	provider, _ := cmd.Root().Metadata[cloudProviderKey].(string)
+
+	// Prompts are ruled out by beforeConnectAction, when --batch is used
+	if len(activationKeys) == 0 && provider == "" && !isBatch(cmd) {
 		if username == "" {
 			password = ""
 			scanner := bufio.NewScanner(os.Stdin)
@@ -130,7 +311,7 @@ func (connectResult *ConnectResult) TryRegisterRHSM(cmd *cli.Command, enableCont
 			fmt.Print("Password: ")
 			data, err := term.ReadPassword(int(os.Stdin.Fd()))
 			if err != nil {
-				connectResult.rhsmFailed(fmt.Sprintf("unable to read password: %s", err))
+				connectResult.rhsmFailed("password-unreadable", fmt.Sprintf("unable to read password: %s", err))
 				return
 			}
 			password = string(data)
@@ -138,29 +319,63 @@ func (connectResult *ConnectResult) TryRegisterRHSM(cmd *cli.Command, enableCont
 		}
 	}
 
+	if purpose := connectSyspurpose(cmd); purpose != (subman.Syspurpose{}) {
+		connectResult.TrySetSyspurpose(client, purpose)
+	}
+
 	var s *spinner.Spinner
 	if ui.IsOutputRich() {
-		s = spinner.New(spinner.CharSets[9], 100*time.Millisecond)
-		s.Prefix = ui.Indent.Small + "["
-		s.Suffix = "] Connecting to Red Hat Subscription Management..."
+		s = ui.NewSpinner(ui.Indent.Small, "Connecting to Red Hat Subscription Management...")
 		s.Start()
-		defer s.Stop()
+		release := cleanup.Push("spinner", func() error {
+			s.Stop()
+			return nil
+		})
+		defer func() { _ = release() }()
 	}
 
 	opts := subman.RegisterOptions{
 		EnvironmentNames: contentTemplates,
 		EnableContent:    enableContent,
 	}
+	timeout := stepTimeout(cmd, conf.Config.Timeouts.RHSM)
+	registerCtx, cancel := stepContext(ctx, timeout)
+	defer cancel()
 
-	if len(activationKeys) > 0 {
+	if provider != "" {
+		slog.Debug("Registering system with the cloud instance identity", "provider", provider)
+		connectResult.CloudProvider = provider
+		err = client.RegisterWithCloudIdentity(registerCtx)
+		// The registration of the cloud flow does not take the options of content
+		if err == nil {
+			if contentErr := client.SetContentManagement(enableContent); contentErr != nil {
+				slog.Warn(fmt.Sprintf("cannot configure content management: %v", contentErr))
+				connectResult.Warnings = append(connectResult.Warnings, Warning{
+					Code:    "content-management",
+					Message: fmt.Sprintf("cannot configure content management: %v", contentErr),
+				})
+			}
+		}
+	} else if len(activationKeys) > 0 {
 		slog.Debug("Registering system with activation keys")
-		err = client.RegisterWithActivationKeys(organization, activationKeys, opts)
+		connectResult.Server, err = withServerFailover(client, conf.Config.Servers, func() error {
+			return client.RegisterWithActivationKeys(registerCtx, organization, activationKeys, opts)
+		})
 	} else {
 		slog.Debug("Registering system with username and password")
-		err = client.RegisterWithPassword(username, password, organization, opts)
+		connectResult.Server, err = withServerFailover(client, conf.Config.Servers, func() error {
+			return client.RegisterWithPassword(registerCtx, username, password, organization, opts)
+		})
 		if errors.Is(err, subman.ErrOrganizationRequired) {
 			if ui.IsOutputMachineReadable() {
-				connectResult.rhsmFailed("no organization specified")
+				connectResult.rhsmFailed("organization-required", "no organization specified")
+				return
+			}
+			if isBatch(cmd) {
+				connectResult.rhsmFailed(
+					"organization-required",
+					"no organization specified: prompts are disabled by --batch, use --organization",
+				)
 				return
 			}
 			// Stop spinner to display the organization list and prompt the user
@@ -168,9 +383,12 @@ func (connectResult *ConnectResult) TryRegisterRHSM(cmd *cli.Command, enableCont
 				s.Stop()
 			}
 
-			orgs, orgsErr := client.GetOrganizations(username, password)
+			orgs, orgsErr := client.GetOrganizations(registerCtx, username, password)
 			if orgsErr != nil {
-				connectResult.rhsmFailed(fmt.Sprintf("cannot retrieve organizations: %s", orgsErr))
+				connectResult.rhsmFailed(
+					cmp.Or(subman.ErrorCode(orgsErr), "organizations-unavailable"),
+					fmt.Sprintf("cannot retrieve organizations: %s", orgsErr),
+				)
 				return
 			}
 
@@ -194,18 +412,32 @@ func (connectResult *ConnectResult) TryRegisterRHSM(cmd *cli.Command, enableCont
 			}
 
 			slog.Debug("Re-attempting registration with username, password and organization")
-			err = client.RegisterWithPassword(username, password, organization, opts)
+			registerCtx, cancel = stepContext(ctx, timeout)
+			defer cancel()
+			err = client.RegisterWithPassword(registerCtx, username, password, organization, opts)
 		}
 	}
 
 	if err != nil {
-		connectResult.rhsmFailed(fmt.Sprintf("cannot connect to Red Hat Subscription Management: %s", err))
+		connectResult.rhsmFailed(
+			cmp.Or(subman.ErrorCode(err), "registration-failed"),
+			fmt.Sprintf("cannot connect to Red Hat Subscription Management: %s", err),
+		)
 		return
 	}
 
 	connectResult.RHSMConnected = true
-	slog.Debug("Connected to Red Hat Subscription Management")
-	ui.Printf("%s[%v] %s\n", ui.Indent.Small, ui.Icons.Ok, "Connected to Red Hat Subscription Management")
+	slog.Debug("Connected to Red Hat Subscription Management", "server", connectResult.Server)
+	if provider != "" {
+		ui.Printf(
+			"%s[%v] Connected to Red Hat Subscription Management with the identity of the %s instance\n",
+			ui.Indent.Small, ui.Icons.Ok, cloud.Name(provider),
+		)
+	} else if connectResult.Server != "" {
+		ui.Printf("%s[%v] Connected to Red Hat Subscription Management through %s\n", ui.Indent.Small, ui.Icons.Ok, connectResult.Server)
+	} else {
+		ui.Printf("%s[%v] %s\n", ui.Indent.Small, ui.Icons.Ok, "Connected to Red Hat Subscription Management")
+	}
 	if enableContent {
 		connectResult.Features.Content.Successful = true
 		infoMsg := "System has access to content"
@@ -219,15 +451,132 @@ func (connectResult *ConnectResult) TryRegisterRHSM(cmd *cli.Command, enableCont
 	}
 }
 
-// TryRegisterInsightsClient will attempt to register the system with Red Hat Lightspeed.
-// If this fails, then Features.Analytics.Successful will be set to false, and the
-// error message will be stored in Features.Analytics.Error.
-func (connectResult *ConnectResult) TryRegisterInsightsClient() {
+// TryPreflightTLS installs the CA certificate caCert, when set, into the directory
+// of CA certificates trusted by RHSM. Then it checks whether the connection to the
+// entitlement server is intercepted by a proxy, and adds a warning when it is.
+// An error is returned only when the CA certificate cannot be installed.
+func (connectResult *ConnectResult) TryPreflightTLS(caCert string) error {
+	client, err := subman.NewRHSMClient()
+	if err != nil {
+		slog.Debug("Skipping TLS preflight check", "err", err)
+		return nil
+	}
+	caCertDir, err := client.CACertDir()
+	if err != nil {
+		slog.Debug("Unable to read CA certificate directory, using default", "err", err)
+		caCertDir = subman.DefaultCACertDir
+	}
+
+	if caCert != "" {
+		path, err := installCACert(caCert, caCertDir)
+		if err != nil {
+			slog.Error(err.Error())
+			if ui.IsOutputMachineReadable() {
+				connectResult.RHSMConnectError = err.Error()
+				connectResult.RHSMConnectErrorCode = "ca-cert-invalid"
+				return cli.Exit(connectResult, exitcode.DataErr)
+			}
+			return cli.Exit(err, exitcode.DataErr)
+		}
+		slog.Info("Installed CA certificate", "path", path)
+		recordAudit("ca-cert-install", map[string]string{"source": caCert, "path": path})
+		ui.Printf("%s[%v] Installed CA certificate %s\n", ui.Indent.Small, ui.Icons.Ok, caCert)
+	}
+
+	if warning := checkTLSInterception(client, caCertDir); warning != nil {
+		connectResult.Warnings = append(connectResult.Warnings, *warning)
+		ui.Printf("%s[%v] Warning: %s\n", ui.Indent.Small, ui.Icons.Warning, warning.Message)
+	}
+	return nil
+}
+
+// TryCheckContentAccess will attempt to download metadata of the first enabled
+// repository from the content delivery network using the entitlement certificate.
+// The result is stored in ContentCheck.
+func (connectResult *ConnectResult) TryCheckContentAccess() {
+	slog.Info("Checking access to content")
+	connectResult.ContentCheck = &ContentCheckResult{}
+
+	repos, err := subman.ReadRepositories(subman.RedHatRepoPath)
+	if err == nil {
+		var repo *subman.Repository
+		for i := range repos {
+			if repos[i].Enabled {
+				repo = &repos[i]
+				break
+			}
+		}
+		if repo == nil {
+			err = fmt.Errorf("no repository is enabled")
+		} else {
+			connectResult.ContentCheck.Repository = repo.ID
+			err = ui.Spinner(func() error {
+				return subman.CheckContentAccess(*repo)
+			}, ui.Indent.Medium, "Checking access to content...")
+		}
+	}
+
+	if err != nil {
+		connectResult.ContentCheck.Successful = false
+		connectResult.ContentCheck.Error = fmt.Sprintf("cannot access content: %v", err)
+		connectResult.ContentCheck.ErrorCode = "content-unavailable"
+		slog.Error(connectResult.ContentCheck.Error)
+		ui.Printf("%s[%v] Content ... Cannot access content\n", ui.Indent.Medium, ui.Icons.Error)
+		return
+	}
+
+	connectResult.ContentCheck.Successful = true
+	infoMsg := "Verified access to content"
+	slog.Info(infoMsg, "repository", connectResult.ContentCheck.Repository)
+	ui.Printf("%s[%v] Content ... %s\n", ui.Indent.Medium, ui.Icons.Ok, infoMsg)
+}
+
+// TryRegisterInsightsClient will attempt to register the system with Red Hat Lightspeed
+// as described by registration. If this fails, then Features.Analytics.Successful will
+// be set to false, and the error message will be stored in Features.Analytics.Error.
+// The run of insights-client is killed, when ctx is done.
+func (connectResult *ConnectResult) TryRegisterInsightsClient(ctx context.Context, registration datacollection.InsightsRegistration) {
 	slog.Info("Connecting to Red Hat Lightspeed")
-	err := ui.Spinner(datacollection.RegisterInsightsClient, ui.Indent.Medium, "Connecting to Red Hat Lightspeed (formerly Insights)...")
+
+	// Tags set in the configuration are uploaded during registration
+	// and on every check-in of insights-client
+	if err := datacollection.WriteInsightsTags(conf.Config.Tags); err != nil {
+		slog.Warn(fmt.Sprintf("cannot apply tags: %v", err))
+	}
+
+	// The periodic upload of insights-client has to use the same proxy
+	if err := configureServiceProxy("insights-client.service"); err != nil {
+		slog.Warn(fmt.Sprintf("cannot configure proxy of insights-client: %v", err))
+	}
+
+	// insights-client uploads through the gateway, when one is configured
+	if gatewayURL := conf.Config.Insights.GatewayURL; gatewayURL != "" {
+		if err := datacollection.SetInsightsConfigValue("base_url", gatewayURL); err != nil {
+			connectResult.Features.Analytics.Successful = false
+			connectResult.Features.Analytics.Error = fmt.Sprintf("cannot configure Red Hat Lightspeed gateway: %v", err)
+			connectResult.Features.Analytics.ErrorCode = "insights-gateway-config"
+			slog.Error(connectResult.Features.Analytics.Error)
+			ui.Printf(
+				"%s[%v] Analytics ... Cannot configure Red Hat Lightspeed gateway\n",
+				ui.Indent.Medium,
+				ui.Icons.Error,
+			)
+			return
+		}
+		slog.Info("Configured Red Hat Lightspeed gateway", "url", gatewayURL)
+		recordAudit("insights-gateway", map[string]string{"url": gatewayURL})
+	}
+
+	err := ui.Spinner(func() error {
+		return datacollection.RegisterInsightsClient(ctx, registration)
+	}, ui.Indent.Medium, "Connecting to Red Hat Lightspeed (formerly Insights)...")
+	if err != nil && connectResult.TryScheduleInsightsUpload(err) {
+		return
+	}
 	if err != nil {
 		connectResult.Features.Analytics.Successful = false
 		connectResult.Features.Analytics.Error = fmt.Sprintf("cannot connect to Red Hat Lightspeed (formerly Insights): %v", err)
+		connectResult.Features.Analytics.ErrorCode = cmp.Or(timeoutCode(err), "insights-registration-failed")
 		slog.Error(fmt.Sprintf("cannot connect to Red Hat Lightspeed: %v", err))
 		ui.Printf(
 			"%s[%v] Analytics ... Cannot connect to Red Hat Lightspeed (formerly Insights)\n",
@@ -242,21 +591,76 @@ func (connectResult *ConnectResult) TryRegisterInsightsClient() {
 	ui.Printf("%s[%v] Analytics ... Connected to Red Hat Lightspeed (formerly Insights)\n", ui.Indent.Medium, ui.Icons.Ok)
 }
 
+// TryScheduleInsightsUpload retries the initial upload of insights-client in the
+// background, when the system was registered with Red Hat Lightspeed, but the
+// registration failed with err, e.g. on a weak network. Otherwise, or when the
+// retry cannot be scheduled, false is returned and the failure is reported by
+// the caller.
+func (connectResult *ConnectResult) TryScheduleInsightsUpload(err error) bool {
+	registered, markerErr := datacollection.InsightsClientHasRegisteredMarker()
+	if markerErr != nil || !registered {
+		return false
+	}
+	slog.Warn("Initial upload to Red Hat Lightspeed failed", "err", err)
+	if err = scheduleInsightsUpload(); err != nil {
+		slog.Error(fmt.Sprintf("cannot retry the initial upload in the background: %v", err))
+		return false
+	}
+
+	connectResult.Features.Analytics.Successful = true
+	connectResult.Features.Analytics.UploadPending = true
+	warning := Warning{
+		Code:    "upload-pending",
+		Message: "the initial upload to Red Hat Lightspeed failed and is retried in the background",
+	}
+	slog.Warn(warning.Message, "code", warning.Code, "unit", insightsUploadServiceName)
+	connectResult.Warnings = append(connectResult.Warnings, warning)
+	recordAudit("insights-upload-scheduled", map[string]string{"unit": insightsUploadServiceName})
+	ui.Printf(
+		"%s[%v] Analytics ... Connected to Red Hat Lightspeed (formerly Insights), upload pending\n",
+		ui.Indent.Medium,
+		ui.Icons.Warning,
+	)
+	return true
+}
+
 // TryEnableYggdrasil will attempt to activate the yggdrasil service.
 // If this fails, then Features.RemoteManagement.Successful will be set to false, and the
-// error message will be stored in Features.RemoteManagement.Error.
-func (connectResult *ConnectResult) TryEnableYggdrasil() {
+// error message will be stored in Features.RemoteManagement.Error. Waiting for the
+// activation is given up, when ctx is done.
+func (connectResult *ConnectResult) TryEnableYggdrasil(ctx context.Context) {
 	slog.Info("Activating yggdrasil service")
-	err := ui.Spinner(remotemanagement.ActivateServices, ui.Indent.Medium, " Activating the yggdrasil service")
+	if err := configureServiceProxy("yggdrasil.service"); err != nil {
+		slog.Warn(fmt.Sprintf("cannot configure proxy of yggdrasil: %v", err))
+	}
+	connectResult.TryConfigureWorkers()
+	err := ui.Spinner(func() error {
+		return remotemanagement.ActivateServices(ctx)
+	}, ui.Indent.Medium, " Activating the yggdrasil service")
+	if errors.Is(err, remotemanagement.ErrBrokerUnverified) {
+		warning := Warning{Code: "broker-unverified", Message: err.Error()}
+		slog.Warn(warning.Message, "code", warning.Code)
+		connectResult.Warnings = append(connectResult.Warnings, warning)
+		ui.Printf("%s[%v] Warning: %s\n", ui.Indent.Medium, ui.Icons.Warning, warning.Message)
+		err = nil
+	}
 	if err != nil {
 		connectResult.Features.RemoteManagement.Successful = false
 		connectResult.Features.RemoteManagement.Error = fmt.Sprintf("cannot activate the yggdrasil service: %v", err)
+		connectResult.Features.RemoteManagement.ErrorCode = cmp.Or(timeoutCode(err), "activation-failed")
 		slog.Error(connectResult.Features.RemoteManagement.Error)
 		ui.Printf(
 			"%s[%v] Remote Management ... Cannot activate the yggdrasil service\n",
 			ui.Indent.Medium,
 			ui.Icons.Error,
 		)
+		var activationErr *remotemanagement.ActivationError
+		if errors.As(err, &activationErr) && activationErr.Diagnostics != nil {
+			connectResult.Features.RemoteManagement.Diagnostics = activationErr.Diagnostics
+			for _, line := range activationErr.Diagnostics.Journal {
+				ui.Printf("%s%s%s\n", ui.Indent.Medium, ui.Indent.Medium, line)
+			}
+		}
 		return
 	}
 
@@ -264,6 +668,60 @@ func (connectResult *ConnectResult) TryEnableYggdrasil() {
 	infoMsg := "Activated the yggdrasil service"
 	slog.Debug(infoMsg)
 	ui.Printf("%s[%v] Remote Management ... %s\n", ui.Indent.Medium, ui.Icons.Ok, infoMsg)
+
+	connectResult.TryInstallPlaybookKeys()
+}
+
+// TryConfigureWorkers will attempt to generate the configuration files of the
+// installed workers from the values of the rhc configuration. Workers keep
+// working with their previous configuration, so a failure is reported as a warning.
+func (connectResult *ConnectResult) TryConfigureWorkers() {
+	slog.Info("Configuring yggdrasil workers")
+	written, err := remotemanagement.ConfigureWorkers(workerValues())
+	for _, path := range written {
+		recordAudit("worker-config", map[string]string{"path": path})
+	}
+	if err != nil {
+		warning := Warning{Code: "worker-config", Message: err.Error()}
+		slog.Warn(warning.Message, "code", warning.Code)
+		connectResult.Warnings = append(connectResult.Warnings, warning)
+		ui.Printf("%s[%v] Warning: %s\n", ui.Indent.Medium, ui.Icons.Warning, warning.Message)
+	}
+}
+
+// workerValues returns the values of the rhc configuration used in templates of
+// worker configuration files.
+func workerValues() remotemanagement.WorkerValues {
+	return remotemanagement.WorkerValues{
+		LogLevel: strings.ToLower(conf.Config.LogLevel.String()),
+		ProxyURL: conf.Config.Proxy.URL,
+		NoProxy:  conf.Config.Proxy.NoProxy,
+	}
+}
+
+// TryInstallPlaybookKeys will attempt to install and verify the keys used to verify
+// playbooks run by remote management. The fingerprints of the keys are stored in
+// PlaybookKeys. Playbooks cannot be run without the keys, so a failure is reported
+// as a warning.
+func (connectResult *ConnectResult) TryInstallPlaybookKeys() {
+	slog.Info("Verifying playbook verification keys")
+	keys, installed, err := remotemanagement.InstallPlaybookKeys()
+	if installed {
+		recordAudit("playbook-keys-install", map[string]string{"path": remotemanagement.PlaybookKeyringPath})
+	}
+	if err != nil {
+		warning := Warning{Code: "playbook-keys", Message: err.Error()}
+		slog.Warn(warning.Message, "code", warning.Code)
+		connectResult.Warnings = append(connectResult.Warnings, warning)
+		ui.Printf("%s[%v] Warning: %s\n", ui.Indent.Medium, ui.Icons.Warning, warning.Message)
+		return
+	}
+
+	connectResult.PlaybookKeys = keys
+	for _, key := range keys {
+		slog.Debug("Verified playbook verification key", "fingerprint", key.Fingerprint, "uid", key.UserID)
+		ui.Printf("%s[%v] Playbook verification key %s\n", ui.Indent.Medium, ui.Icons.Ok, key.Fingerprint)
+	}
 }
 
 // checkFeatureFlags validates --enable-feature and --disable-feature flag combinations.
@@ -314,6 +772,197 @@ func checkFeatureFlags(toEnable, toDisable []string) error {
 	return nil
 }
 
+// FlagErrorResult is structure holding an invalid use of flags. The result
+// could be printed in machine-readable format.
+type FlagErrorResult struct {
+	Error     string `json:"error"`
+	ErrorCode string `json:"error_code"`
+}
+
+// FlagError is an invalid combination of flags. Code is a stable identifier of
+// the problem, which is not translated.
+type FlagError struct {
+	Code    string
+	Message string
+}
+
+func (e *FlagError) Error() string {
+	return e.Message
+}
+
+// checkConnectFlags verifies that the credentials flags of 'rhc connect' are
+// not mixed, and that activation keys are accompanied by an organization.
+func checkConnectFlags(username, password, organization string, activationKeys []string) error {
+	if len(activationKeys) == 0 {
+		return nil
+	}
+	if username != "" || password != "" {
+		flag := "--username"
+		if username == "" {
+			flag = "--password"
+		}
+		return &FlagError{
+			Code: "conflicting-credentials",
+			Message: fmt.Sprintf(
+				"%s and --activation-key can not be used together: "+
+					"use either --username/--password or --organization/--activation-key",
+				flag,
+			),
+		}
+	}
+	if organization == "" {
+		return &FlagError{
+			Code:    "organization-required",
+			Message: "--organization is required, when --activation-key is used",
+		}
+	}
+	return nil
+}
+
+// checkAutoFlag verifies that --auto is not mixed with the flags of credentials
+// in inputs, or with content templates, which the cloud registration flow does
+// not take.
+func checkAutoFlag(inputs map[string][]string, contentTemplates []string) error {
+	for _, name := range credentialFlags {
+		if _, ok := inputs[name]; ok {
+			return &FlagError{
+				Code: "conflicting-credentials",
+				Message: fmt.Sprintf(
+					"--%s and --auto can not be used together: "+
+						"with --auto, the system is registered with the identity of its cloud instance",
+					name,
+				),
+			}
+		}
+	}
+	if len(contentTemplates) > 0 {
+		return &FlagError{
+			Code:    "conflicting-credentials",
+			Message: "--content-template and --auto can not be used together",
+		}
+	}
+	return nil
+}
+
+// checkContentTemplateFlag verifies that content templates are only requested
+// when the content feature is enabled.
+func checkContentTemplateFlag(contentTemplates []string, contentEnabled bool) error {
+	if contentEnabled || len(contentTemplates) == 0 {
+		return nil
+	}
+	return &FlagError{
+		Code: "content-disabled",
+		Message: "content feature is disabled, cannot use --content-template: " +
+			"enable it with --enable-feature content",
+	}
+}
+
+const (
+	// maxDisplayNameLength is the longest display name accepted by Inventory.
+	maxDisplayNameLength = 200
+	// maxGroupLength is the longest name of an inventory group.
+	maxGroupLength = 255
+)
+
+// checkInsightsRegistrationFlags verifies that the display name and the group
+// of registration are accepted by Inventory, and that they are only requested
+// when the analytics feature is enabled.
+func checkInsightsRegistrationFlags(registration datacollection.InsightsRegistration, analyticsEnabled bool) error {
+	if len(registration.DisplayName) > maxDisplayNameLength {
+		return &FlagError{
+			Code:    "invalid-display-name",
+			Message: fmt.Sprintf("--display-name is longer than %d characters", maxDisplayNameLength),
+		}
+	}
+	if len(registration.Group) > maxGroupLength {
+		return &FlagError{
+			Code:    "invalid-group",
+			Message: fmt.Sprintf("--group is longer than %d characters", maxGroupLength),
+		}
+	}
+	if analyticsEnabled || (registration.DisplayName == "" && registration.Group == "") {
+		return nil
+	}
+	return &FlagError{
+		Code: "analytics-disabled",
+		Message: "analytics feature is disabled, cannot use --display-name or --group: " +
+			"enable it with --enable-feature analytics",
+	}
+}
+
+// flagUsageError returns the error reported for an invalid use of flags. In
+// machine-readable format, the error and its code are printed as well.
+func flagUsageError(err error) error {
+	if ui.IsOutputMachineReadable() {
+		result := FlagErrorResult{Error: err.Error()}
+		var flagErr *FlagError
+		if errors.As(err, &flagErr) {
+			result.ErrorCode = flagErr.Code
+		}
+		_ = ui.PrintJSON(result)
+	}
+	return cli.Exit(err.Error(), exitcode.Usage)
+}
+
+// credentialFlags are the flags of credentials in the order they are validated.
+var credentialFlags = []string{"username", "password", "organization", "activation-key"}
+
+// identifierPattern matches names of organizations and activation keys accepted
+// by the entitlement server.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// checkCredentialInputs validates the values of credential flags before they are
+// sent to the entitlement server, which reports such mistakes only as failed
+// authentication. inputs maps the names of the flags set on the command line to
+// their values. Returns an error describing the first invalid value.
+func checkCredentialInputs(inputs map[string][]string) error {
+	for _, name := range credentialFlags {
+		for _, value := range inputs[name] {
+			if value == "" {
+				return fmt.Errorf("--%s cannot be empty", name)
+			}
+			// Passwords are used verbatim, they may start or end with spaces
+			if name == "password" {
+				continue
+			}
+			if strings.TrimSpace(value) != value {
+				return fmt.Errorf("--%s %q has leading or trailing whitespace", name, value)
+			}
+			switch name {
+			case "username":
+				if strings.ContainsFunc(value, unicode.IsSpace) {
+					return fmt.Errorf("--username %q cannot contain whitespace", value)
+				}
+			case "organization", "activation-key":
+				if !identifierPattern.MatchString(value) {
+					return fmt.Errorf(
+						"--%s %q contains invalid characters: only letters, digits, '-' and '_' are allowed",
+						name, value,
+					)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// credentialInputs returns the values of the credential flags set on the
+// command line of cmd, as validated by checkCredentialInputs.
+func credentialInputs(cmd *cli.Command) map[string][]string {
+	inputs := make(map[string][]string)
+	for _, name := range credentialFlags {
+		if !cmd.IsSet(name) {
+			continue
+		}
+		if name == "activation-key" {
+			inputs[name] = cmd.StringSlice(name)
+		} else {
+			inputs[name] = []string{cmd.String(name)}
+		}
+	}
+	return inputs
+}
+
 // beforeConnectAction ensures correct CLI flags have been passed in:
 // correct values, no conflicts. On error, this method invokes cli.Exit()
 // with appropriate message and error code.
@@ -326,6 +975,22 @@ func beforeConnectAction(ctx context.Context, cmd *cli.Command) (context.Context
 	// Configure UI globals
 	configureUI(cmd)
 
+	// Secrets are read from files and the standard input, so they are not
+	// passed as plain arguments; the others are warned about
+	cmd.Root().Metadata[plainSecretsKey] = plainSecretFlags(cmd)
+	if err = applySecretInputs(cmd); err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+
+	// The bootstrap file provides the flags of credentials and features
+	if cmd.Bool("from-bootstrap") {
+		bootstrap, err := applyBootstrap(cmd, BootstrapPath)
+		if err != nil {
+			return ctx, err
+		}
+		cmd.Root().Metadata[bootstrapKey] = bootstrap
+	}
+
 	// Validate --enable-feature/--disable-feature combinations make sense
 	err = checkFeatureFlags(
 		cmd.StringSlice("enable-feature"),
@@ -335,7 +1000,59 @@ func beforeConnectAction(ctx context.Context, cmd *cli.Command) (context.Context
 		return ctx, cli.Exit(err.Error(), exitcode.Usage)
 	}
 
-	// Do not continue if the host is already registered
+	if err = checkNotifyURLFlag(cmd); err != nil {
+		return ctx, err
+	}
+
+	if err = checkServerFlags(cmd); err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+
+	if cmd.Bool("sign-result") && !ui.IsOutputMachineReadable() {
+		return ctx, cli.Exit("--sign-result requires --format", exitcode.Usage)
+	}
+
+	// Catch mistakes in credentials before they are sent to the entitlement server
+	if err = checkCredentialInputs(credentialInputs(cmd)); err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+
+	err = checkConnectFlags(
+		cmd.String("username"),
+		cmd.String("password"),
+		cmd.String("organization"),
+		cmd.StringSlice("activation-key"),
+	)
+	if err != nil {
+		return ctx, flagUsageError(err)
+	}
+	if cmd.Bool("auto") {
+		if err = checkAutoFlag(credentialInputs(cmd), cmd.StringSlice("content-template")); err != nil {
+			return ctx, flagUsageError(err)
+		}
+	}
+
+	// Only the connectivity is checked, the system is not connected
+	if cmd.Bool("check-only") {
+		return ctx, nil
+	}
+
+	// Registration and the services of features require systemd, which does not
+	// run in most containers; calls to it would fail with obscure errors
+	if manager := detectContainer(); manager != "" && !systemdBooted() {
+		slog.Error("Refusing to connect a container without systemd", "container", manager)
+		return ctx, cli.Exit(
+			fmt.Sprintf(
+				"this system is a container (%s) without systemd and cannot be connected; "+
+					"connect the host instead, containers receive content through the subscription of the host",
+				manager,
+			),
+			exitcode.Unavailable,
+		)
+	}
+
+	// A registered system is not registered again; the requested features,
+	// which are not enabled yet, are enabled
 	slog.Info("Checking system connection status")
 	rhsmClient, err := subman.NewRHSMClient()
 	if err != nil {
@@ -353,7 +1070,48 @@ func beforeConnectAction(ctx context.Context, cmd *cli.Command) (context.Context
 	}
 	if registered {
 		slog.Info("System is already connected")
-		return ctx, cli.Exit("this system is already connected", exitcode.Usage)
+		if organization := cmd.String("organization"); organization != "" {
+			owner, err := rhsmClient.GetOwner()
+			if err != nil {
+				return ctx, cli.Exit(
+					fmt.Sprintf("unable to check connection status: %s", err),
+					exitcode.Software,
+				)
+			}
+			if owner.Key != organization {
+				return ctx, cli.Exit(
+					fmt.Sprintf(
+						"this system is already connected to organization %s; run 'rhc disconnect' before connecting it to organization %s",
+						owner.Key, organization,
+					),
+					exitcode.Usage,
+				)
+			}
+		}
+		// The existing consumer is known only to the server it is registered with
+		requested, _ := serverURLs(cmd)
+		if bootstrap, _ := cmd.Root().Metadata[bootstrapKey].(*Bootstrap); bootstrap != nil && bootstrap.ServerURL != "" {
+			requested = bootstrap.ServerURL
+		}
+		if requested != "" {
+			current, err := rhsmClient.ServerURL()
+			if err != nil {
+				return ctx, cli.Exit(
+					fmt.Sprintf("unable to check connection status: %s", err),
+					exitcode.Software,
+				)
+			}
+			if !subman.SameServer(current, requested) {
+				return ctx, cli.Exit(
+					fmt.Sprintf(
+						"this system is already connected to the entitlement server %s; run 'rhc disconnect' before connecting it to %s",
+						current, requested,
+					),
+					exitcode.Usage,
+				)
+			}
+		}
+		cmd.Root().Metadata[connectedKey] = true
 	}
 
 	username := cmd.String("username")
@@ -362,38 +1120,29 @@ func beforeConnectAction(ctx context.Context, cmd *cli.Command) (context.Context
 	activationKeys := cmd.StringSlice("activation-key")
 	contentTemplates := cmd.StringSlice("content-template")
 
-	if len(activationKeys) > 0 {
-		if username != "" {
-			exitErr := cli.Exit(
-				"--username and --activation-key can not be used together",
-				exitcode.Usage,
-			)
-			return ctx, exitErr
-
-		}
-		if password != "" {
-			exitErr := cli.Exit(
-				"--password and --activation-key can not be used together",
-				exitcode.Usage,
+	// Exit if username/password or activation key/organization haven't been provided,
+	// and we cannot ask interactively. A missing password is prompted for with
+	// the input hidden. Registered systems do not need them.
+	if registered {
+		slog.Debug("Credentials are not needed, the system is registered")
+	} else if cmd.Bool("auto") {
+		provider := cloud.Provider()
+		if provider == "" {
+			return ctx, cli.Exit(
+				"--auto requires an instance of AWS, Azure or Google Cloud, no cloud provider was detected",
+				exitcode.Unavailable,
 			)
-			return ctx, exitErr
-
 		}
-		if organization == "" {
-			exitErr := cli.Exit(
-				"--organization is required, when --activation-key is used",
-				exitcode.Usage,
-			)
-			return ctx, exitErr
+		slog.Info("Detected cloud provider", "provider", provider)
+		cmd.Root().Metadata[cloudProviderKey] = provider
+	} else if isBatch(cmd) {
+		if missing := missingConnectInputs(username, password, activationKeys); len(missing) > 0 {
+			return ctx, missingInputsError(missing)
 		}
-	}
-
-	// Exit if username/password or activation key/organization haven't been provided,
-	// and we cannot ask interactively.
-	if !ui.IsInteractive() {
+	} else if !ui.CanPrompt() {
 		if (username == "" || password == "") && (len(activationKeys) == 0 || organization == "") {
 			exitErr := cli.Exit(
-				"--username/--password or --organization/--activation-key are required when a machine-readable format is used",
+				"--username/--password or --organization/--activation-key are required when rhc cannot prompt for them (e.g. standard input is not a terminal)",
 				exitcode.Usage,
 			)
 			return ctx, exitErr
@@ -436,8 +1185,26 @@ func beforeConnectAction(ctx context.Context, cmd *cli.Command) (context.Context
 	if err != nil {
 		return ctx, cli.Exit(fmt.Sprintf("failed to get content preference: %v", err), exitcode.Software)
 	}
-	if !contentEnabled && len(contentTemplates) > 0 {
-		return ctx, cli.Exit("content feature is disabled, cannot use --content-template", exitcode.Usage)
+	if err = checkContentTemplateFlag(contentTemplates, contentEnabled); err != nil {
+		return ctx, flagUsageError(err)
+	}
+
+	// The notice of the organization has to be accepted before data is collected
+	analyticsEnabled, err := cache.Get("analytics")
+	if err != nil {
+		return ctx, cli.Exit(fmt.Sprintf("failed to get analytics preference: %v", err), exitcode.Software)
+	}
+	if err = checkInsightsRegistrationFlags(connectInsightsRegistration(cmd), analyticsEnabled); err != nil {
+		return ctx, flagUsageError(err)
+	}
+	if analyticsEnabled && !cmd.Bool("dry-run") {
+		consent, err := checkConsent(cmd)
+		if err != nil {
+			return ctx, err
+		}
+		if consent != nil {
+			cmd.Root().Metadata[connectConsentKey] = consent
+		}
 	}
 
 	err = checkForUnknownArgs(cmd)
@@ -453,6 +1220,9 @@ func beforeConnectAction(ctx context.Context, cmd *cli.Command) (context.Context
 // then we enable data collection for Red Hat Lightspeed services,
 // then we start remote management service yggdrasil.
 func connectAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Bool("check-only") {
+		return preflightAction(ctx, cmd)
+	}
 	logCommandStart(cmd)
 	cache := cmd.Root().Metadata[connectCacheKey].(*prefcache.PreferenceCache)
 
@@ -465,6 +1235,21 @@ func connectAction(ctx context.Context, cmd *cli.Command) error {
 
 	var connectResult ConnectResult
 	connectResult.format = cmd.String("format")
+	if reportPhase(cmd, &connectResult) {
+		connectResult.format = ""
+	}
+	connectResult.Warnings = collectWarnings()
+	if flags, ok := cmd.Root().Metadata[plainSecretsKey].([]string); ok {
+		for _, warning := range plainSecretWarnings(flags) {
+			slog.Warn(warning.Message, "code", warning.Code)
+			connectResult.Warnings = append(connectResult.Warnings, warning)
+		}
+	}
+	defer func() {
+		if !connectResult.DryRun {
+			notifyWebhook(ctx, cmd, "connect", &connectResult)
+		}
+	}()
 
 	uid := os.Getuid()
 	if uid != 0 {
@@ -490,6 +1275,40 @@ func connectAction(ctx context.Context, cmd *cli.Command) error {
 	}
 	connectResult.Hostname = hostname
 
+	if cmd.Bool("dry-run") {
+		plan, err := connectPlan(cmd, cache)
+		if err != nil {
+			return cli.Exit(err.Error(), exitcode.Software)
+		}
+		connectResult.DryRun = true
+		connectResult.Plan = plan
+		if ui.IsOutputMachineReadable() {
+			fmt.Println(connectResult.Error())
+		} else {
+			printPlan(plan)
+		}
+		return nil
+	}
+
+	bootstrap, _ := cmd.Root().Metadata[bootstrapKey].(*Bootstrap)
+	connected, _ := cmd.Root().Metadata[connectedKey].(bool)
+	serverURL, baseURL := serverURLs(cmd)
+	// A connected system stays with its server, checked to be the requested one
+	if connected {
+		slog.Debug("Keeping entitlement server of connected system")
+	} else if bootstrap != nil && bootstrap.ServerURL != "" {
+		if err = configureBootstrapServer(bootstrap); err != nil {
+			slog.Error(err.Error())
+			return cli.Exit(err, exitcode.Config)
+		}
+	} else if serverURL != "" {
+		if err = connectResult.TryConfigureServer(
+			ctx, serverURL, baseURL, cmd.String("ca-cert"), cmd.String("ca-fingerprint"),
+		); err != nil {
+			return err
+		}
+	}
+
 	ui.Printf("Connecting %v to Red Hat.", hostname)
 	var toEnableList []string
 	contentEnabled, err := cache.Get("content")
@@ -519,8 +1338,26 @@ func connectAction(ctx context.Context, cmd *cli.Command) error {
 	}
 	ui.Printf("\nThis might take some time.\n\n")
 
+	started := time.Now()
 	var start time.Time
-	durations := make(map[string]time.Duration)
+
+	// Display the overall completion while the steps of connection run
+	steps := 1
+	for _, id := range []string{"analytics", "remote-management"} {
+		if requested, _ := cache.Get(id); requested {
+			steps++
+		}
+	}
+	if cmd.Bool("check-content") {
+		steps++
+	}
+	progress := ui.StartProgress(steps)
+	defer progress.Stop()
+
+	// Detect TLS-intercepting proxies before registration fails on them
+	if err = connectResult.TryPreflightTLS(cmd.String("ca-cert")); err != nil {
+		return err
+	}
 
 	// Register to Red Hat Subscription Management
 	{
@@ -529,11 +1366,27 @@ func connectAction(ctx context.Context, cmd *cli.Command) error {
 		if err != nil {
 			return cli.Exit(fmt.Sprintf("failed to get content preference: %v", err), exitcode.Software)
 		}
-		connectResult.TryRegisterRHSM(
-			cmd,
-			contentRequested,
+		if connected {
+			connectResult.TryKeepRHSM(ctx, contentRequested)
+			connectResult.Timeline.record(started, "rhsm", start, time.Now(), stepUnchanged)
+		} else {
+			connectResult.TryRegisterRHSM(
+				ctx,
+				cmd,
+				contentRequested,
+			)
+			connectResult.Timeline.record(started, "rhsm", start, time.Now(), stepResult(connectResult.RHSMConnected))
+			connectResult.TryRestoreServer()
+		}
+	}
+
+	// Verify the content is accessible
+	if cmd.Bool("check-content") && connectResult.Features.Content.Successful {
+		start = time.Now()
+		connectResult.TryCheckContentAccess()
+		connectResult.Timeline.record(
+			started, "content-check", start, time.Now(), stepResult(connectResult.ContentCheck.Successful),
 		)
-		durations["rhsm"] = time.Since(start)
 	}
 
 	// Enable data collection
@@ -541,11 +1394,31 @@ func connectAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return cli.Exit(fmt.Sprintf("failed to get analytics preference: %v", err), exitcode.Software)
 	}
-	if analyticsRequested {
+	if connected && keepFeature("analytics", &connectResult.Features.Analytics) {
+		connectResult.Timeline.record(started, "insights", time.Now(), time.Now(), stepUnchanged)
+		ui.Printf("%s[%v] Analytics ... Already connected to Red Hat Lightspeed (formerly Insights)\n", ui.Indent.Medium, ui.Icons.Ok)
+		if registration := connectInsightsRegistration(cmd); registration != (datacollection.InsightsRegistration{}) {
+			warning := Warning{
+				Code:    "insights-registered",
+				Message: "the system is connected to Red Hat Lightspeed already, --display-name and --group are not applied",
+			}
+			slog.Warn(warning.Message, "code", warning.Code)
+			connectResult.Warnings = append(connectResult.Warnings, warning)
+			ui.Printf("%s[%v] Warning: %s\n", ui.Indent.Medium, ui.Icons.Warning, warning.Message)
+		}
+	} else if analyticsRequested {
+		if consent, ok := cmd.Root().Metadata[connectConsentKey].(*Consent); ok {
+			recordConsent(consent)
+		}
 		start = time.Now()
-		connectResult.TryRegisterInsightsClient()
-		durations["insights"] = time.Since(start)
+		insightsCtx, cancel := stepContext(ctx, stepTimeout(cmd, conf.Config.Timeouts.Insights))
+		connectResult.TryRegisterInsightsClient(insightsCtx, connectInsightsRegistration(cmd))
+		cancel()
+		connectResult.Timeline.record(
+			started, "insights", start, time.Now(), stepResult(connectResult.Features.Analytics.Successful),
+		)
 	} else {
+		connectResult.Timeline.skip(started, "insights", time.Now())
 		ui.Printf("%s[%v] Analytics ... Skipped\n", ui.Indent.Medium, ui.Icons.Info)
 	}
 
@@ -554,12 +1427,17 @@ func connectAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return cli.Exit(fmt.Sprintf("failed to get remote-management preference: %v", err), exitcode.Software)
 	}
-	if remoteManagementRequested {
+	if connected && keepFeature("remote-management", &connectResult.Features.RemoteManagement) {
+		connectResult.Timeline.record(started, "yggdrasil", time.Now(), time.Now(), stepUnchanged)
+		ui.Printf("%s[%v] Remote Management ... The yggdrasil service is already active\n", ui.Indent.Medium, ui.Icons.Ok)
+	} else if remoteManagementRequested {
 		if !connectResult.Features.Content.Successful {
 			connectResult.Features.RemoteManagement.Skipped = true
 			connectResult.Features.RemoteManagement.Successful = false
 			connectResult.Features.RemoteManagement.Error = "skipped: dependency 'content' failed"
+			connectResult.Features.RemoteManagement.ErrorCode = "dependency-failed"
 			slog.Warn("Skipping remote-management (dependency 'content' failed)")
+			connectResult.Timeline.skip(started, "yggdrasil", time.Now())
 			ui.Printf(
 				"%s[%v] Remote Management ... Skipped (dependency 'content' failed)\n",
 				ui.Indent.Medium,
@@ -569,7 +1447,9 @@ func connectAction(ctx context.Context, cmd *cli.Command) error {
 			connectResult.Features.RemoteManagement.Skipped = true
 			connectResult.Features.RemoteManagement.Successful = false
 			connectResult.Features.RemoteManagement.Error = "skipped: dependency 'analytics' failed"
+			connectResult.Features.RemoteManagement.ErrorCode = "dependency-failed"
 			slog.Warn("Skipping remote-management (dependency 'analytics' failed)")
+			connectResult.Timeline.skip(started, "yggdrasil", time.Now())
 			ui.Printf(
 				"%s[%v] Remote Management ... Skipped (dependency 'analytics' failed)\n",
 				ui.Indent.Medium,
@@ -577,15 +1457,57 @@ func connectAction(ctx context.Context, cmd *cli.Command) error {
 			)
 		} else {
 			start = time.Now()
-			connectResult.TryEnableYggdrasil()
-			durations["yggdrasil"] = time.Since(start)
+			activationCtx, cancel := stepContext(ctx, stepTimeout(cmd, conf.Config.Timeouts.Activation))
+			connectResult.TryEnableYggdrasil(activationCtx)
+			cancel()
+			connectResult.Timeline.record(
+				started, "yggdrasil", start, time.Now(), stepResult(connectResult.Features.RemoteManagement.Successful),
+			)
 		}
 	} else {
+		connectResult.Timeline.skip(started, "yggdrasil", time.Now())
 		ui.Printf("%s[%v] Remote Management ... Skipped\n", ui.Indent.Medium, ui.Icons.Info)
 	}
 
+	connectResult.Changed = connectResult.changed()
 	if connectResult.RHSMConnected {
-		ui.Printf("\nSuccessfully connected to Red Hat!\n")
+		// Record the hostname, so 'rhc checkin' can detect when it changes
+		if err = writeSyncedHostname(SyncedHostnamePath, hostname); err != nil {
+			slog.Warn(err.Error())
+		}
+		if err = installCheckinTimer(); err != nil {
+			slog.Warn(fmt.Sprintf("cannot enable periodic check-in: %v", err))
+		}
+		if err = saveFeatureSelection(cache); err != nil {
+			slog.Warn(err.Error())
+		}
+		if connectResult.RHSMUnchanged {
+			slog.Debug("Connection is not recorded, the system was registered already")
+		} else if connectResult.CloudProvider != "" {
+			recordConnection(connectionCloudAuto)
+		} else if len(cmd.StringSlice("activation-key")) > 0 {
+			recordConnection(connectionActivationKey)
+			// The keys of a bootstrap file are not kept once the file is shredded
+			if bootstrap == nil {
+				if err = writeActivationKeys(ActivationKeysPath, cmd.StringSlice("activation-key")); err != nil {
+					slog.Warn(err.Error())
+				}
+			}
+		} else {
+			recordConnection(connectionCredentials)
+		}
+		if bootstrap != nil {
+			if err = shredFile(BootstrapPath); err != nil {
+				slog.Warn(fmt.Sprintf("cannot shred bootstrap file: %v", err))
+			} else {
+				recordAudit("bootstrap-consume", map[string]string{"file": BootstrapPath})
+			}
+		}
+		if connectResult.Changed {
+			ui.Printf("\nSuccessfully connected to Red Hat!\n")
+		} else {
+			ui.Printf("\nThe system is already connected to Red Hat, nothing was changed.\n")
+		}
 	}
 
 	if !ui.IsOutputMachineReadable() {
@@ -593,7 +1515,7 @@ func connectAction(ctx context.Context, cmd *cli.Command) error {
 		ui.Printf("\nManage your connected systems: https://red.ht/connector\n")
 
 		// If enabled, display time statistics
-		showTimeDuration(durations)
+		showTimeline(connectResult.Timeline)
 	}
 
 	err = showErrorMessages("connect", connectResult.errorMessages())
@@ -601,10 +1523,13 @@ func connectAction(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	if ui.IsOutputMachineReadable() {
-		connectResult.Features.Content.Enabled, _ = feature.MustGet("content").IsEnabled()
-		connectResult.Features.Analytics.Enabled, _ = feature.MustGet("analytics").IsEnabled()
-		connectResult.Features.RemoteManagement.Enabled, _ = feature.MustGet("remote-management").IsEnabled()
+	connectResult.Features.Content.Enabled, _ = feature.MustGet("content").IsEnabled()
+	connectResult.Features.Analytics.Enabled, _ = feature.MustGet("analytics").IsEnabled()
+	connectResult.Features.RemoteManagement.Enabled, _ = feature.MustGet("remote-management").IsEnabled()
+	if cmd.Bool("sign-result") {
+		connectResult.trySign()
+	}
+	if ui.IsOutputMachineReadable() && connectResult.format != "" {
 		fmt.Println(connectResult.Error())
 	}
 
@@ -612,5 +1537,8 @@ func connectAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		slog.Debug("could not delete preferences cache", "err", err)
 	}
+	if !connectResult.Changed {
+		return cli.Exit("", exitcode.Unchanged)
+	}
 	return nil
 }
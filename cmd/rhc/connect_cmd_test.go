@@ -1,7 +1,11 @@
 package main
 
 import (
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/redhatinsights/rhc/internal/datacollection"
 )
 
 func TestCheckFeatureFlags(t *testing.T) {
@@ -72,3 +76,293 @@ func TestCheckFeatureFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckCredentialInputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		inputs  map[string][]string
+		wantErr string
+	}{
+		{
+			name:    "no inputs",
+			inputs:  map[string][]string{},
+			wantErr: "",
+		},
+		{
+			name: "valid activation keys",
+			inputs: map[string][]string{
+				"organization":   {"12345678"},
+				"activation-key": {"prod_web-01", "base"},
+			},
+			wantErr: "",
+		},
+		{
+			name: "password with spaces",
+			inputs: map[string][]string{
+				"username": {"jdoe@example.com"},
+				"password": {" secret "},
+			},
+			wantErr: "",
+		},
+		{
+			name:    "empty organization",
+			inputs:  map[string][]string{"organization": {""}},
+			wantErr: "--organization cannot be empty",
+		},
+		{
+			name:    "empty password",
+			inputs:  map[string][]string{"username": {"jdoe"}, "password": {""}},
+			wantErr: "--password cannot be empty",
+		},
+		{
+			name:    "whitespace-padded username",
+			inputs:  map[string][]string{"username": {"jdoe "}},
+			wantErr: "--username \"jdoe \" has leading or trailing whitespace",
+		},
+		{
+			name:    "whitespace in username",
+			inputs:  map[string][]string{"username": {"j doe"}},
+			wantErr: "--username \"j doe\" cannot contain whitespace",
+		},
+		{
+			name:    "malformed activation key",
+			inputs:  map[string][]string{"organization": {"12345678"}, "activation-key": {"prod,web"}},
+			wantErr: "--activation-key \"prod,web\" contains invalid characters: only letters, digits, '-' and '_' are allowed",
+		},
+		{
+			name:    "malformed organization reported first",
+			inputs:  map[string][]string{"organization": {"org/1"}, "activation-key": {"prod web"}},
+			wantErr: "--organization \"org/1\" contains invalid characters: only letters, digits, '-' and '_' are allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCredentialInputs(tt.inputs)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkCredentialInputs() error = %v, wantErr nil", err)
+				}
+			} else {
+				if err == nil {
+					t.Errorf("checkCredentialInputs() error = nil, wantErr %v", tt.wantErr)
+				} else if err.Error() != tt.wantErr {
+					t.Errorf("checkCredentialInputs() error = %v, wantErr %v", err.Error(), tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckConnectFlags(t *testing.T) {
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		organization   string
+		activationKeys []string
+		wantCode       string
+	}{
+		{
+			name:     "username and password",
+			username: "jdoe",
+			password: "secret",
+			wantCode: "",
+		},
+		{
+			name:           "organization and activation key",
+			organization:   "12345678",
+			activationKeys: []string{"prod"},
+			wantCode:       "",
+		},
+		{
+			name:           "username and activation key",
+			username:       "jdoe",
+			organization:   "12345678",
+			activationKeys: []string{"prod"},
+			wantCode:       "conflicting-credentials",
+		},
+		{
+			name:           "password and activation key",
+			password:       "secret",
+			organization:   "12345678",
+			activationKeys: []string{"prod"},
+			wantCode:       "conflicting-credentials",
+		},
+		{
+			name:           "activation key without organization",
+			activationKeys: []string{"prod"},
+			wantCode:       "organization-required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkConnectFlags(tt.username, tt.password, tt.organization, tt.activationKeys)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("checkConnectFlags() error = %v, want nil", err)
+				}
+				return
+			}
+			var flagErr *FlagError
+			if !errors.As(err, &flagErr) {
+				t.Fatalf("checkConnectFlags() error = %v, want FlagError", err)
+			}
+			if flagErr.Code != tt.wantCode {
+				t.Errorf("checkConnectFlags() code = %v, want %v", flagErr.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestCheckAutoFlag(t *testing.T) {
+	tests := []struct {
+		name             string
+		inputs           map[string][]string
+		contentTemplates []string
+		wantError        bool
+	}{
+		{name: "no credentials", inputs: map[string][]string{}},
+		{name: "organization", inputs: map[string][]string{"organization": {"12345678"}}, wantError: true},
+		{name: "activation key", inputs: map[string][]string{"activation-key": {"prod"}}, wantError: true},
+		{name: "content template", inputs: map[string][]string{}, contentTemplates: []string{"rhel-9"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAutoFlag(tt.inputs, tt.contentTemplates)
+			if !tt.wantError {
+				if err != nil {
+					t.Errorf("checkAutoFlag() error = %v, want nil", err)
+				}
+				return
+			}
+			var flagErr *FlagError
+			if !errors.As(err, &flagErr) {
+				t.Fatalf("checkAutoFlag() error = %v, want FlagError", err)
+			}
+			if flagErr.Code != "conflicting-credentials" {
+				t.Errorf("checkAutoFlag() code = %v, want conflicting-credentials", flagErr.Code)
+			}
+		})
+	}
+}
+
+func TestCheckContentTemplateFlag(t *testing.T) {
+	if err := checkContentTemplateFlag([]string{"rhel-9"}, true); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checkContentTemplateFlag(nil, false); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	var flagErr *FlagError
+	if err := checkContentTemplateFlag([]string{"rhel-9"}, false); !errors.As(err, &flagErr) || flagErr.Code != "content-disabled" {
+		t.Errorf("checkContentTemplateFlag() error = %v, want content-disabled", err)
+	}
+}
+
+func TestCheckInsightsRegistrationFlags(t *testing.T) {
+	tests := []struct {
+		name             string
+		registration     datacollection.InsightsRegistration
+		analyticsEnabled bool
+		wantCode         string
+	}{
+		{name: "defaults", analyticsEnabled: false},
+		{
+			name:             "display name and group",
+			registration:     datacollection.InsightsRegistration{DisplayName: "web-01", Group: "web-servers"},
+			analyticsEnabled: true,
+		},
+		{
+			name:         "analytics disabled",
+			registration: datacollection.InsightsRegistration{Group: "web-servers"},
+			wantCode:     "analytics-disabled",
+		},
+		{
+			name:             "long display name",
+			registration:     datacollection.InsightsRegistration{DisplayName: strings.Repeat("a", 201)},
+			analyticsEnabled: true,
+			wantCode:         "invalid-display-name",
+		},
+		{
+			name:             "long group",
+			registration:     datacollection.InsightsRegistration{Group: strings.Repeat("a", 256)},
+			analyticsEnabled: true,
+			wantCode:         "invalid-group",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkInsightsRegistrationFlags(tt.registration, tt.analyticsEnabled)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("checkInsightsRegistrationFlags() error = %v, want nil", err)
+				}
+				return
+			}
+			var flagErr *FlagError
+			if !errors.As(err, &flagErr) || flagErr.Code != tt.wantCode {
+				t.Errorf("checkInsightsRegistrationFlags() error = %v, want %v", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestConnectResultChanged(t *testing.T) {
+	tests := []struct {
+		description string
+		result      func(result *ConnectResult)
+		want        bool
+	}{
+		{
+			description: "registered",
+			result: func(result *ConnectResult) {
+				result.RHSMConnected = true
+				result.Features.Content.Successful = true
+			},
+			want: true,
+		},
+		{
+			description: "already connected",
+			result: func(result *ConnectResult) {
+				result.RHSMConnected = true
+				result.RHSMUnchanged = true
+				result.Features.Content = FeatureResult{Successful: true, Unchanged: true}
+				result.Features.Analytics = FeatureResult{Successful: true, Unchanged: true}
+			},
+			want: false,
+		},
+		{
+			description: "feature enabled on connected system",
+			result: func(result *ConnectResult) {
+				result.RHSMConnected = true
+				result.RHSMUnchanged = true
+				result.Features.Content = FeatureResult{Successful: true, Unchanged: true}
+				result.Features.Analytics = FeatureResult{Successful: true}
+			},
+			want: true,
+		},
+		{
+			description: "feature failed on connected system",
+			result: func(result *ConnectResult) {
+				result.RHSMConnected = true
+				result.RHSMUnchanged = true
+				result.Features.Analytics = FeatureResult{Error: "cannot connect"}
+			},
+			want: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			var result ConnectResult
+			test.result(&result)
+			if got := result.changed(); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
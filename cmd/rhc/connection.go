@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/util"
+)
+
+// Methods of connecting the system recorded in ConnectionPath.
+const (
+	connectionActivationKey  = "activation-key"
+	connectionCredentials    = "credentials"
+	connectionCloudAuto      = "cloud-auto"
+	connectionIdentityImport = "identity-import"
+)
+
+// Connection describes how the system was connected. Troubleshooting of the
+// connection differs by the way the system was registered.
+type Connection struct {
+	// Method is one of "activation-key", "credentials", "cloud-auto" and
+	// "identity-import".
+	Method string `json:"method"`
+	// Server is the URL of the entitlement server the system was registered with.
+	Server string `json:"server,omitempty"`
+	// ConnectedAt is the time of the connection; it is unknown for systems
+	// registered automatically.
+	ConnectedAt *time.Time `json:"connected_at,omitempty"`
+}
+
+// describe returns a human-readable description of the connection method.
+func (connection *Connection) describe() string {
+	switch connection.Method {
+	case connectionActivationKey:
+		return "activation key"
+	case connectionCredentials:
+		return "username and password"
+	case connectionCloudAuto:
+		return "cloud auto-registration"
+	case connectionIdentityImport:
+		return "imported identity"
+	default:
+		return connection.Method
+	}
+}
+
+// readConnection returns the connection recorded in path, or nil when none
+// has been recorded.
+func readConnection(path string) (*Connection, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read connection method: %w", err)
+	}
+	var connection Connection
+	if err = json.Unmarshal(data, &connection); err != nil {
+		return nil, fmt.Errorf("cannot parse connection method %s: %w", path, err)
+	}
+	return &connection, nil
+}
+
+// writeConnection records the connection in path.
+func writeConnection(path string, connection Connection) error {
+	data, err := json.Marshal(connection)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot record connection method: %w", err)
+	}
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("cannot record connection method: %w", err)
+	}
+	return nil
+}
+
+// recordConnection records that the system has just been connected by method.
+// A failure is only logged, because the system is connected anyway.
+func recordConnection(method string) {
+	now := time.Now().UTC()
+	connection := Connection{Method: method, ConnectedAt: &now}
+	if client, err := subman.NewRHSMClient(); err == nil {
+		connection.Server, err = client.ServerURL()
+		if err != nil {
+			slog.Debug("Unable to read entitlement server", "err", err)
+		}
+	}
+	if err := writeConnection(ConnectionPath, connection); err != nil {
+		slog.Warn(err.Error())
+	}
+}
+
+// getConnection returns the connection of a connected system. Systems connected
+// without rhc are reported as "cloud-auto", when automatic registration is
+// enabled in rhsm.conf; otherwise nil is returned.
+func getConnection() (*Connection, error) {
+	connection, err := readConnection(ConnectionPath)
+	if err != nil || connection != nil {
+		return connection, err
+	}
+
+	client, err := subman.NewRHSMClient()
+	if err != nil {
+		slog.Debug("Unable to check automatic registration", "err", err)
+		return nil, nil
+	}
+	autoRegistration, err := client.GetConfigValue("rhsmcertd.auto_registration")
+	if err != nil {
+		slog.Debug("Unable to check automatic registration", "err", err)
+		return nil, nil
+	}
+	if autoRegistration != "1" {
+		return nil, nil
+	}
+	connection = &Connection{Method: connectionCloudAuto}
+	if connection.Server, err = client.ServerURL(); err != nil {
+		slog.Debug("Unable to read entitlement server", "err", err)
+	}
+	return connection, nil
+}
+
+// readActivationKeys returns the activation keys recorded in path, or nil when
+// none have been recorded.
+func readActivationKeys(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read activation keys: %w", err)
+	}
+	var keys []string
+	if err = json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("cannot parse activation keys %s: %w", path, err)
+	}
+	return keys, nil
+}
+
+// writeActivationKeys records the activation keys the system was connected
+// with in path, readable only by root.
+func writeActivationKeys(path string, keys []string) error {
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot record activation keys: %w", err)
+	}
+	if err = util.WriteFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("cannot record activation keys: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadConnection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "connection.json")
+
+	got, err := readConnection(path)
+	if err != nil || got != nil {
+		t.Fatalf("got (%v, %v) without connection, want (nil, nil)", got, err)
+	}
+
+	connectedAt := time.Date(2025, 1, 31, 2, 0, 0, 0, time.UTC)
+	want := Connection{
+		Method:      connectionActivationKey,
+		Server:      "https://subscription.rhsm.redhat.com/subscription",
+		ConnectedAt: &connectedAt,
+	}
+	if err = writeConnection(path, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err = readConnection(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(*got, want) {
+		t.Errorf("%v", cmp.Diff(*got, want))
+	}
+
+	if err = os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = readConnection(path); err == nil {
+		t.Error("expected error for malformed connection")
+	}
+}
+
+func TestReadActivationKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "activation-keys.json")
+
+	got, err := readActivationKeys(path)
+	if err != nil || got != nil {
+		t.Fatalf("got (%v, %v) without activation keys, want (nil, nil)", got, err)
+	}
+
+	want := []string{"key1", "key2"}
+	if err = writeActivationKeys(path, want); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("got mode %v, want 0600", info.Mode().Perm())
+	}
+	got, err = readActivationKeys(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+	"github.com/redhatinsights/rhc/pkg/feature"
+	"github.com/redhatinsights/rhc/pkg/interactive"
+)
+
+// Consent is the acceptance of the data collection notice configured by
+// 'notice-file' in the '[consent]' section, recorded in ConsentPath.
+type Consent struct {
+	Notice     string    `json:"notice"`
+	SHA256     string    `json:"sha256"`
+	AcceptedAt time.Time `json:"accepted_at"`
+}
+
+// pendingConsent returns the consent to the notice in noticeFile, which has
+// not been accepted yet according to the record in consentPath. Nil is returned
+// when no notice is configured, or when the notice has already been accepted.
+// A changed notice has to be accepted again.
+func pendingConsent(noticeFile, consentPath string) (*Consent, string, error) {
+	if noticeFile == "" {
+		return nil, "", nil
+	}
+	notice, err := os.ReadFile(noticeFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot read data collection notice: %w", err)
+	}
+	sum := sha256.Sum256(notice)
+	consent := &Consent{Notice: noticeFile, SHA256: hex.EncodeToString(sum[:])}
+
+	data, err := os.ReadFile(consentPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, "", fmt.Errorf("cannot read consent: %w", err)
+	}
+	if err == nil {
+		var accepted Consent
+		if err = json.Unmarshal(data, &accepted); err != nil {
+			return nil, "", fmt.Errorf("cannot parse consent %s: %w", consentPath, err)
+		}
+		if accepted.SHA256 == consent.SHA256 {
+			return nil, "", nil
+		}
+	}
+	return consent, string(notice), nil
+}
+
+// promptConsent prints the notice and asks the user to accept it, see
+// interactive.ConfirmByUser; --assume-yes does not accept it.
+func promptConsent(notice string) (bool, error) {
+	return interactive.ConfirmByUser(
+		"Do you accept the data collection notice?",
+		strings.TrimRight(notice, "\n"),
+	)
+}
+
+// checkConsent makes sure the data collection notice configured in the
+// [consent] section is accepted, before a command registers insights-client.
+// The notice is accepted by --accept-notice, or by the user on a terminal.
+// The returned consent is nil, when no notice is pending; otherwise it has to
+// be recorded by recordConsent before insights-client is registered.
+func checkConsent(cmd *cli.Command) (*Consent, error) {
+	consent, notice, err := pendingConsent(conf.Config.Consent.NoticeFile, ConsentPath)
+	if err != nil {
+		return nil, cli.Exit(err.Error(), exitcode.Config)
+	}
+	if consent == nil || cmd.Bool("accept-notice") {
+		return consent, nil
+	}
+	if isBatch(cmd) {
+		return nil, missingInputsError([]string{"--accept-notice"})
+	}
+	accepted, err := promptConsent(notice)
+	if errors.Is(err, interactive.ErrPromptDisabled) {
+		return nil, cli.Exit(
+			fmt.Sprintf("--accept-notice is required to accept the data collection notice %s, when the user cannot be asked", consent.Notice),
+			exitcode.Usage,
+		)
+	}
+	if err != nil {
+		return nil, cli.Exit(err.Error(), exitcode.Software)
+	}
+	if !accepted {
+		return nil, cli.Exit(
+			"the data collection notice was not accepted; Red Hat Lightspeed cannot be enabled without it",
+			exitcode.Usage,
+		)
+	}
+	return consent, nil
+}
+
+// checkAnalyticsConsent checks the consent to the data collection notice, see
+// checkConsent, when the analytics feature is going to be enabled by wanted.
+func checkAnalyticsConsent(cmd *cli.Command, wanted map[string]bool) (*Consent, error) {
+	if !wanted["analytics"] {
+		return nil, nil
+	}
+	enabled, err := feature.MustGet("analytics").IsEnabled()
+	if err != nil {
+		return nil, cli.Exit(fmt.Sprintf("cannot check state of feature analytics: %v", err), exitcode.Software)
+	}
+	if enabled {
+		return nil, nil
+	}
+	return checkConsent(cmd)
+}
+
+// recordConsent records the acceptance of consent in ConsentPath and in the
+// audit log. Nothing is recorded, when consent is nil.
+func recordConsent(consent *Consent) {
+	if consent == nil {
+		return
+	}
+	consent.AcceptedAt = time.Now().UTC()
+	if err := writeConsent(ConsentPath, *consent); err != nil {
+		slog.Warn(err.Error())
+	}
+	recordAudit("consent-accept", map[string]string{"notice": consent.Notice, "sha256": consent.SHA256})
+}
+
+// writeConsent records the acceptance of the notice in path.
+func writeConsent(path string, consent Consent) error {
+	data, err := json.Marshal(consent)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot record consent: %w", err)
+	}
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("cannot record consent: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+)
+
+func TestPendingConsent(t *testing.T) {
+	dir := t.TempDir()
+	noticeFile := filepath.Join(dir, "notice.txt")
+	consentPath := filepath.Join(dir, "consent.json")
+
+	consent, _, err := pendingConsent("", consentPath)
+	if err != nil || consent != nil {
+		t.Fatalf("got (%v, %v) without notice, want (nil, nil)", consent, err)
+	}
+
+	if _, _, err = pendingConsent(noticeFile, consentPath); err == nil {
+		t.Error("expected error for missing notice")
+	}
+
+	if err = os.WriteFile(noticeFile, []byte("Data of this system is collected.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	consent, notice, err := pendingConsent(noticeFile, consentPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if consent == nil || notice != "Data of this system is collected.\n" {
+		t.Fatalf("got (%v, %q), want pending consent", consent, notice)
+	}
+
+	consent.AcceptedAt = time.Date(2025, 1, 31, 2, 0, 0, 0, time.UTC)
+	if err = writeConsent(consentPath, *consent); err != nil {
+		t.Fatal(err)
+	}
+	if consent, _, err = pendingConsent(noticeFile, consentPath); err != nil || consent != nil {
+		t.Errorf("got (%v, %v) after acceptance, want (nil, nil)", consent, err)
+	}
+
+	// A changed notice has to be accepted again
+	if err = os.WriteFile(noticeFile, []byte("Data of this system is collected daily.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if consent, _, err = pendingConsent(noticeFile, consentPath); err != nil || consent == nil {
+		t.Errorf("got (%v, %v) after change of notice, want pending consent", consent, err)
+	}
+}
+
+func TestCheckConsent(t *testing.T) {
+	noticeFile := filepath.Join(t.TempDir(), "notice.txt")
+	if err := os.WriteFile(noticeFile, []byte("Data of this system is collected.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		description string
+		noticeFile  string
+		args        []string
+		wantConsent bool
+		wantError   bool
+	}{
+		{description: "no notice", args: []string{"enable"}},
+		{description: "accepted by flag", noticeFile: noticeFile, args: []string{"enable", "--accept-notice"}, wantConsent: true},
+		{description: "batch", noticeFile: noticeFile, args: []string{"enable", "--batch"}, wantError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			previous := conf.Config.Consent
+			conf.Config.Consent = conf.ConsentConf{NoticeFile: test.noticeFile}
+			defer func() { conf.Config.Consent = previous }()
+
+			var consent *Consent
+			var err error
+			cmd := &cli.Command{
+				Name: "enable",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "accept-notice"},
+					&cli.BoolFlag{Name: "batch"},
+				},
+				Action: func(_ context.Context, cmd *cli.Command) error {
+					consent, err = checkConsent(cmd)
+					return nil
+				},
+			}
+			if runErr := cmd.Run(context.Background(), test.args); runErr != nil {
+				t.Fatal(runErr)
+			}
+			if (err != nil) != test.wantError {
+				t.Fatalf("got error %v, want error %v", err, test.wantError)
+			}
+			if (consent != nil) != test.wantConsent {
+				t.Errorf("got consent %v, want consent %v", consent, test.wantConsent)
+			}
+		})
+	}
+}
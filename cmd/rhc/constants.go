@@ -1,21 +1,55 @@
 package main
 
+import "path/filepath"
+
 const (
 	// ConnectFeaturesPrefsPath is the path to the feature preferences cache file
 	ConnectFeaturesPrefsPath = "/var/lib/rhc/rhc-connect-features-prefs.json"
+	// DisconnectLockPath is the path to the protection lock created by 'rhc lock'
+	DisconnectLockPath = "/var/lib/rhc/disconnect.lock"
+	// SyncedHostnamePath is the path to the file holding the hostname last
+	// propagated to the name of the consumer in Red Hat Subscription Management
+	// and to the display name in Inventory
+	SyncedHostnamePath = "/var/lib/rhc/hostname"
+	// FeatureStatePath is the path to the selection of features made when the
+	// system was connected, re-applied by 'rhc feature reconcile'
+	FeatureStatePath = "/var/lib/rhc/features.json"
+	// MaintenancePath is the path to the maintenance window started by 'rhc maintenance on'
+	MaintenancePath = "/var/lib/rhc/maintenance.json"
+	// ConnectionPath is the path to the record of how the system was connected
+	ConnectionPath = "/var/lib/rhc/connection.json"
+	// ConsentPath is the path to the acceptance of the data collection notice
+	ConsentPath = "/var/lib/rhc/consent.json"
+	// ActivationKeysPath is the path to the activation keys the system was
+	// connected with, reused by 'rhc reconnect'
+	ActivationKeysPath = "/var/lib/rhc/activation-keys.json"
+	// QueuePath is the path to the operations of check-ins queued until Red
+	// Hat services can be reached
+	QueuePath = "/var/lib/rhc/queue.json"
 )
 
 const (
-	connectCacheKey = "connect-cache"
+	connectCacheKey   = "connect-cache"
+	connectConsentKey = "connect-consent"
+	connectedKey      = "connected"
+	cloudProviderKey  = "cloud-provider"
+	bootstrapKey      = "bootstrap"
+	phaseResultsKey   = "phase-results"
+	uiSettingsKey     = "ui-settings"
 )
 
 var (
 	// LogDir points to the log file directory
 	LogDir string
+	// AuditLogPath points to the audit log file
+	AuditLogPath string
 )
 
 func init() {
 	if LogDir == "" {
 		LogDir = "/var/log/rhc/"
 	}
+	if AuditLogPath == "" {
+		AuditLogPath = filepath.Join(LogDir, "audit.log")
+	}
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+var (
+	// containerMarkerPath is written by systemd with the container manager, when
+	// it detects it runs inside a container.
+	containerMarkerPath = "/run/systemd/container"
+	// podmanMarkerPath and dockerMarkerPath are created by the container engines
+	// in every container.
+	podmanMarkerPath = "/run/.containerenv"
+	dockerMarkerPath = "/.dockerenv"
+	// systemdRuntimeDir exists, when the system has been booted with systemd.
+	systemdRuntimeDir = "/run/systemd/system"
+)
+
+// detectContainer returns the container manager (e.g. "podman"), when rhc
+// runs inside a container, or an empty string otherwise.
+func detectContainer() string {
+	if manager := os.Getenv("container"); manager != "" {
+		return manager
+	}
+	if data, err := os.ReadFile(containerMarkerPath); err == nil {
+		if manager := strings.TrimSpace(string(data)); manager != "" {
+			return manager
+		}
+	}
+	if _, err := os.Stat(podmanMarkerPath); err == nil {
+		return "podman"
+	}
+	if _, err := os.Stat(dockerMarkerPath); err == nil {
+		return "docker"
+	}
+	return ""
+}
+
+// systemdBooted reports whether the system runs systemd, which manages the
+// services of the features of rhc.
+func systemdBooted() bool {
+	info, err := os.Stat(systemdRuntimeDir)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectContainer(t *testing.T) {
+	dir := t.TempDir()
+	oldMarker, oldPodman, oldDocker := containerMarkerPath, podmanMarkerPath, dockerMarkerPath
+	containerMarkerPath = filepath.Join(dir, "container")
+	podmanMarkerPath = filepath.Join(dir, ".containerenv")
+	dockerMarkerPath = filepath.Join(dir, ".dockerenv")
+	t.Cleanup(func() { containerMarkerPath, podmanMarkerPath, dockerMarkerPath = oldMarker, oldPodman, oldDocker })
+	t.Setenv("container", "")
+
+	if got := detectContainer(); got != "" {
+		t.Errorf("got %q, want no container", got)
+	}
+
+	if err := os.WriteFile(dockerMarkerPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := detectContainer(); got != "docker" {
+		t.Errorf("got %q, want docker", got)
+	}
+
+	if err := os.WriteFile(podmanMarkerPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := detectContainer(); got != "podman" {
+		t.Errorf("got %q, want podman", got)
+	}
+
+	if err := os.WriteFile(containerMarkerPath, []byte("systemd-nspawn\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := detectContainer(); got != "systemd-nspawn" {
+		t.Errorf("got %q, want systemd-nspawn", got)
+	}
+
+	t.Setenv("container", "oci")
+	if got := detectContainer(); got != "oci" {
+		t.Errorf("got %q, want oci", got)
+	}
+}
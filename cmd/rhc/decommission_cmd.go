@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/collector"
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/datacollection"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/systemd"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+const (
+	// rhsmFactsCachePath is the path to the facts cached by RHSM.
+	rhsmFactsCachePath = "/var/lib/rhsm/facts/facts.json"
+	// canonicalFactsPath is the path to the canonical facts generated for yggdrasil.
+	canonicalFactsPath = "/var/lib/yggdrasil/canonical-facts.json"
+)
+
+// DecommissionResult is structure holding the final report of decommission.
+// The result could be printed in machine-readable format.
+type DecommissionResult struct {
+	Hostname   string           `json:"hostname"`
+	LockError  string           `json:"lock_error,omitempty"`
+	Disconnect DisconnectResult `json:"disconnect"`
+	// StepErrors maps steps of the disconnection, which could not check the
+	// state of their service, to the errors.
+	StepErrors map[string]string `json:"step_errors,omitempty"`
+	// RemovedPaths lists local files holding identity, facts and audit state
+	// of the system that have been removed.
+	RemovedPaths []string `json:"removed_paths"`
+	// RemoveErrors maps paths that could not be removed to the errors.
+	RemoveErrors map[string]string `json:"remove_errors,omitempty"`
+	Warnings     []Warning         `json:"warnings"`
+}
+
+// failed reports whether any step of the decommission failed.
+func (result *DecommissionResult) failed() bool {
+	return len(result.Disconnect.errorMessages()) > 0 || len(result.StepErrors) > 0 || len(result.RemoveErrors) > 0
+}
+
+// disconnected reports whether every step of the disconnection succeeded, so
+// the local state of the system can be removed.
+func (result *DecommissionResult) disconnected() bool {
+	return result.Disconnect.YggdrasilStopped && result.Disconnect.InsightsDisconnected && result.Disconnect.RHSMDisconnected
+}
+
+// decommissionPaths returns local files holding identity, facts and audit state
+// of the system. Directories are removed with their content.
+func decommissionPaths() []string {
+	return []string{
+		// Identity
+		subman.ConsumerCertPath,
+		subman.ConsumerKeyPath,
+		datacollection.InsightsMachineIDPath,
+		datacollection.InsightsRegisteredMarkerPath,
+		datacollection.InsightsLastUploadPath,
+		datacollection.InsightsHostDetailsPath,
+		datacollection.InsightsTagsPath,
+		SyncedHostnamePath,
+		ConnectFeaturesPrefsPath,
+		FeatureStatePath,
+		MaintenancePath,
+		ConnectionPath,
+		ConsentPath,
+		ActivationKeysPath,
+		QueuePath,
+		// Facts
+		rhsmFactsCachePath,
+		canonicalFactsPath,
+		collector.TimerDir,
+		// Audit state
+		DisconnectLockPath,
+		AuditLogPath,
+	}
+}
+
+// removePaths removes paths, and records the result. Missing paths are skipped.
+func (result *DecommissionResult) removePaths(paths []string) {
+	result.RemovedPaths, result.RemoveErrors = removeLocalState(paths)
+}
+
+// removeLocalState removes paths, and returns the removed paths and the errors
+// of paths that could not be removed. Missing paths are skipped.
+func removeLocalState(paths []string) ([]string, map[string]string) {
+	removed := []string{}
+	var removeErrors map[string]string
+	for _, path := range paths {
+		if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			slog.Error("Unable to remove local state", "path", path, "err", err)
+			if removeErrors == nil {
+				removeErrors = make(map[string]string)
+			}
+			removeErrors[path] = err.Error()
+			continue
+		}
+		slog.Debug("Removed local state", "path", path)
+		removed = append(removed, filepath.Clean(path))
+	}
+	return removed, removeErrors
+}
+
+// removeServiceConfiguration removes the check-in timer and the proxy drop-ins
+// generated by 'rhc connect'.
+func removeServiceConfiguration() error {
+	if err := removeCheckinTimer(); err != nil {
+		return err
+	}
+	for _, unit := range []string{"yggdrasil.service", "insights-client.service"} {
+		if err := systemd.WriteEnvironmentDropIn(unit, proxyDropInName, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printDecommissionReport prints the final report in human-readable format.
+func printDecommissionReport(result *DecommissionResult) {
+	ui.Printf("\nDecommission report for %s:\n\n", result.Hostname)
+	steps := []struct {
+		name string
+		done bool
+	}{
+		{"Remote management deactivated", result.Disconnect.YggdrasilStopped},
+		{"Removed from Red Hat Lightspeed (formerly Insights) and Inventory", result.Disconnect.InsightsDisconnected},
+		{"Unregistered from Red Hat Subscription Management", result.Disconnect.RHSMDisconnected},
+		{"Local identity, facts and audit state removed", result.RemovedPaths != nil && len(result.RemoveErrors) == 0},
+	}
+	for _, step := range steps {
+		icon := ui.Icons.Ok
+		if !step.done {
+			icon = ui.Icons.Error
+		}
+		ui.Printf("%s[%v] %s\n", ui.Indent.Small, icon, step.name)
+	}
+	for _, path := range result.RemovedPaths {
+		ui.Printf("%s    removed %s\n", ui.Indent.Small, path)
+	}
+}
+
+// beforeDecommissionAction ensures the user has supplied a correct `--format` flag.
+func beforeDecommissionAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// decommissionAction disconnects the system from Red Hat, and removes local
+// identity, facts and audit state of the system. Local state is kept when the
+// disconnection fails, so the decommission can be retried.
+func decommissionAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot decommission system", exitcode.NoPerm)
+	}
+
+	result := DecommissionResult{Warnings: collectWarnings()}
+	result.Disconnect.Warnings = []Warning{}
+
+	if errMsg := disconnectLockError(DisconnectLockPath, cmd.Bool("force"), "force"); errMsg != "" {
+		slog.Error(errMsg)
+		if ui.IsOutputMachineReadable() {
+			result.LockError = errMsg
+			_ = ui.PrintJSON(result)
+			return cli.Exit("", exitcode.Usage)
+		}
+		return cli.Exit(errMsg, exitcode.Usage)
+	}
+
+	var err error
+	result.Hostname, err = os.Hostname()
+	if err != nil {
+		slog.Error("error retrieving system hostname", "err", err)
+		return cli.Exit(fmt.Errorf("cannot get hostname: %w", err), exitcode.Err)
+	}
+	result.Disconnect.Hostname = result.Hostname
+
+	if err = confirmOperation(
+		fmt.Sprintf("Decommission %v? It is removed from Inventory and its local state is deleted.", result.Hostname),
+		false,
+	); err != nil {
+		return err
+	}
+
+	slog.Info(fmt.Sprintf("Decommissioning %v", result.Hostname))
+	ui.Printf("Decommissioning %v.\nThis might take a few seconds.\n\n", result.Hostname)
+
+	for _, step := range []struct {
+		name    string
+		timeout time.Duration
+		try     func(context.Context) error
+	}{
+		{name: "yggdrasil", timeout: conf.Config.Timeouts.Activation, try: result.Disconnect.TryDeactivateServices},
+		{name: "insights", timeout: conf.Config.Timeouts.Insights, try: result.Disconnect.TryUnregisterInsightsClient},
+		{name: "rhsm", timeout: conf.Config.Timeouts.RHSM, try: result.Disconnect.TryUnregisterRHSM},
+	} {
+		stepCtx, cancel := stepContext(ctx, step.timeout)
+		err = step.try(stepCtx)
+		cancel()
+		if err != nil {
+			slog.Error("Cannot disconnect", "target", step.name, "err", err)
+			if result.StepErrors == nil {
+				result.StepErrors = make(map[string]string)
+			}
+			result.StepErrors[step.name] = err.Error()
+		}
+	}
+
+	// A step failing to check its service leaves no error in the disconnect
+	// result, so the local state is removed only when every step succeeded
+	if result.disconnected() {
+		if err = removeServiceConfiguration(); err != nil {
+			slog.Warn(fmt.Sprintf("cannot remove service configuration: %v", err))
+		}
+		result.removePaths(decommissionPaths())
+		slog.Info("Decommissioned", "hostname", result.Hostname, "removed", len(result.RemovedPaths))
+	} else {
+		slog.Warn("Keeping local state, the system could not be disconnected")
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print decommission report as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+	} else {
+		printDecommissionReport(&result)
+		errorMessages := result.Disconnect.errorMessages()
+		for name, errMsg := range result.StepErrors {
+			errorMessages[name] = errMsg
+		}
+		for path, errMsg := range result.RemoveErrors {
+			errorMessages[path] = errMsg
+		}
+		if err = showErrorMessages("decommission", errorMessages); err != nil {
+			return err
+		}
+	}
+
+	if result.failed() {
+		return cli.Exit("", exitcode.Err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecommissionRemovePaths(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "hostname")
+	cacheDir := filepath.Join(dir, "collectors")
+	missing := filepath.Join(dir, "missing")
+	if err := os.WriteFile(file, []byte("host\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cacheDir, "com.redhat.minimal.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var result DecommissionResult
+	result.removePaths([]string{file, cacheDir + "/", missing})
+
+	want := []string{file, cacheDir}
+	if !cmp.Equal(result.RemovedPaths, want) {
+		t.Errorf("%v", cmp.Diff(result.RemovedPaths, want))
+	}
+	if len(result.RemoveErrors) > 0 {
+		t.Errorf("unexpected errors: %v", result.RemoveErrors)
+	}
+	for _, path := range want {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", path)
+		}
+	}
+	if result.failed() {
+		t.Error("result reports failure")
+	}
+}
+
+func TestDecommissionDisconnected(t *testing.T) {
+	tests := []struct {
+		description string
+		result      DecommissionResult
+		want        bool
+	}{
+		{
+			description: "all steps succeeded",
+			result: DecommissionResult{Disconnect: DisconnectResult{
+				YggdrasilStopped: true, InsightsDisconnected: true, RHSMDisconnected: true,
+			}},
+			want: true,
+		},
+		{
+			// A failed registration check returns an error without setting
+			// the error fields of the disconnect result
+			description: "registration check failed",
+			result: DecommissionResult{
+				Disconnect: DisconnectResult{YggdrasilStopped: true, InsightsDisconnected: true},
+				StepErrors: map[string]string{"rhsm": "cannot connect to D-Bus"},
+			},
+			want: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if got := test.result.disconnected(); got != test.want {
+				t.Errorf("disconnected() = %v, want %v", got, test.want)
+			}
+			if got := test.result.failed(); got == test.want {
+				t.Errorf("failed() = %v, want %v", got, !test.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,19 @@
 package main
 
 import (
+	"cmp"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/urfave/cli/v3"
 
+	"github.com/redhatinsights/rhc/internal/conf"
 	"github.com/redhatinsights/rhc/internal/datacollection"
 	"github.com/redhatinsights/rhc/internal/remotemanagement"
 	"github.com/redhatinsights/rhc/internal/subman"
@@ -19,18 +23,30 @@ import (
 
 // DisconnectResult is structure holding information about result of
 // disconnect command. The result could be printed in machine-readable format.
+// Error messages may be translated by the services reporting them; the error codes
+// next to them are stable identifiers for machine-readable output.
 type DisconnectResult struct {
-	Hostname                  string `json:"hostname"`
-	HostnameError             string `json:"hostname_error,omitempty"`
-	UID                       int    `json:"uid"`
-	UIDError                  string `json:"uid_error,omitempty"`
-	RHSMDisconnected          bool   `json:"rhsm_disconnected"`
-	RHSMDisconnectedError     string `json:"rhsm_disconnect_error,omitempty"`
-	InsightsDisconnected      bool   `json:"insights_disconnected"`
-	InsightsDisconnectedError string `json:"insights_disconnected_error,omitempty"`
-	YggdrasilStopped          bool   `json:"yggdrasil_stopped"`
-	YggdrasilStoppedError     string `json:"yggdrasil_stopped_error,omitempty"`
-	format                    string
+	Hostname                      string     `json:"hostname"`
+	HostnameError                 string     `json:"hostname_error,omitempty"`
+	UID                           int        `json:"uid"`
+	UIDError                      string     `json:"uid_error,omitempty"`
+	LockError                     string     `json:"lock_error,omitempty"`
+	RHSMDisconnected              bool       `json:"rhsm_disconnected"`
+	RHSMDisconnectedError         string     `json:"rhsm_disconnect_error,omitempty"`
+	RHSMDisconnectedErrorCode     string     `json:"rhsm_disconnect_error_code,omitempty"`
+	InsightsDisconnected          bool       `json:"insights_disconnected"`
+	InsightsDisconnectedError     string     `json:"insights_disconnected_error,omitempty"`
+	InsightsDisconnectedErrorCode string     `json:"insights_disconnected_error_code,omitempty"`
+	YggdrasilStopped              bool       `json:"yggdrasil_stopped"`
+	YggdrasilStoppedError         string     `json:"yggdrasil_stopped_error,omitempty"`
+	YggdrasilStoppedErrorCode     string     `json:"yggdrasil_stopped_error_code,omitempty"`
+	Kept                          []string   `json:"kept,omitempty"`
+	CleanedOffline                []string   `json:"cleaned_offline,omitempty"`
+	Warnings                      []Warning  `json:"warnings"`
+	DryRun                        bool       `json:"dry_run,omitempty"`
+	Plan                          []PlanStep `json:"plan,omitempty"`
+	Timeline                      Timeline   `json:"timeline,omitempty"`
+	format                        string
 }
 
 // Error implement error interface for structure DisconnectResult
@@ -38,7 +54,7 @@ func (disconnectResult *DisconnectResult) Error() string {
 	var result string
 	switch disconnectResult.format {
 	case "json":
-		data, err := json.MarshalIndent(disconnectResult, "", "    ")
+		data, err := ui.MarshalJSON(disconnectResult)
 		if err != nil {
 			return err.Error()
 		}
@@ -65,9 +81,116 @@ func (disconnectResult *DisconnectResult) errorMessages() map[string]string {
 	return errorMessages
 }
 
+// disconnectTargets are the targets of 'rhc disconnect', in the order they are
+// disconnected.
+var disconnectTargets = []string{"yggdrasil", "insights", "rhsm"}
+
+// disconnectDependents maps targets to the targets depending on them, which
+// stop working when the target is disconnected.
+var disconnectDependents = map[string][]string{
+	"insights": {"yggdrasil"},
+	"rhsm":     {"insights", "yggdrasil"},
+}
+
+// removedMessages describe what is removed by disconnecting the targets.
+var removedMessages = map[string]string{
+	"yggdrasil": "the yggdrasil service is deactivated, Red Hat can no longer manage the system remotely",
+	"insights":  "the system is removed from Red Hat Lightspeed (formerly Insights)",
+	"rhsm":      "the registration with Red Hat Subscription Management and the access to content are removed",
+}
+
+// keptMessages describe the targets kept connected.
+var keptMessages = map[string]string{
+	"yggdrasil": "Kept the yggdrasil service active",
+	"insights":  "Kept the connection to Red Hat Lightspeed (formerly Insights)",
+	"rhsm":      "Kept the registration with Red Hat Subscription Management",
+}
+
+// selectDisconnectTargets returns the targets selected by --only, or all the
+// targets except for those kept by --keep-*, in the order they are disconnected.
+func selectDisconnectTargets(only []string, keep []string) ([]string, error) {
+	for _, target := range slices.Concat(only, keep) {
+		if !slices.Contains(disconnectTargets, target) {
+			return nil, &FlagError{
+				Code: "invalid-target",
+				Message: fmt.Sprintf(
+					"unknown target %q (allowed values: %s)", target, strings.Join(disconnectTargets, ", "),
+				),
+			}
+		}
+	}
+	if len(only) > 0 && len(keep) > 0 {
+		return nil, &FlagError{
+			Code:    "conflicting-targets",
+			Message: "--only and --keep-* can not be used together",
+		}
+	}
+
+	targets := []string{}
+	for _, target := range disconnectTargets {
+		if (len(only) == 0 || slices.Contains(only, target)) && !slices.Contains(keep, target) {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, &FlagError{Code: "no-target", Message: "all targets are kept, there is nothing to disconnect"}
+	}
+	return targets, nil
+}
+
+// keptDisconnectTargets returns the targets set by --keep-rhsm, --keep-insights
+// and --keep-yggdrasil.
+func keptDisconnectTargets(cmd *cli.Command) []string {
+	var keep []string
+	for _, target := range disconnectTargets {
+		if cmd.Bool("keep-" + target) {
+			keep = append(keep, target)
+		}
+	}
+	return keep
+}
+
+// checkDisconnectDependents verifies that no target left connected depends on
+// one of targets. connected reports whether a target is connected.
+func checkDisconnectDependents(targets []string, connected func(target string) (bool, error)) error {
+	for _, target := range targets {
+		for _, dependent := range disconnectDependents[target] {
+			if slices.Contains(targets, dependent) {
+				continue
+			}
+			isConnected, err := connected(dependent)
+			if err != nil {
+				return err
+			}
+			if isConnected {
+				return &FlagError{
+					Code: "dependent-target",
+					Message: fmt.Sprintf(
+						"cannot disconnect %s while %s depends on it; disconnect %s too", target, dependent, dependent,
+					),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// disconnectTargetConnected reports whether target is still connected.
+func disconnectTargetConnected(target string) (bool, error) {
+	switch target {
+	case "yggdrasil":
+		isInactive, err := remotemanagement.AssertYggdrasilServiceState("inactive")
+		return !isInactive, err
+	case "insights":
+		return datacollection.InsightsClientHasRegisteredMarker()
+	}
+	return false, fmt.Errorf("unknown target %q", target)
+}
+
 // TryDeactivateServices tries to stop yggdrasil.service, when it hasn't
-// been already stopped.
-func (disconnectResult *DisconnectResult) TryDeactivateServices() error {
+// been already stopped. Waiting for the deactivation is given up, when ctx
+// is done.
+func (disconnectResult *DisconnectResult) TryDeactivateServices(ctx context.Context) error {
 	slog.Info("Deactivating the yggdrasil service")
 
 	// First check if the service hasn't been already stopped
@@ -84,11 +207,14 @@ func (disconnectResult *DisconnectResult) TryDeactivateServices() error {
 	}
 	// When the service is not inactive, then try to get this service to this state
 	progressMessage := "Deactivating the yggdrasil service"
-	err = ui.Spinner(remotemanagement.DeactivateServices, ui.Indent.Small, progressMessage)
+	err = ui.Spinner(func() error {
+		return remotemanagement.DeactivateServices(ctx)
+	}, ui.Indent.Small, progressMessage)
 	if err != nil {
 		errMsg := fmt.Sprintf("Cannot deactivate yggdrasil service: %v", err)
 		disconnectResult.YggdrasilStopped = false
 		disconnectResult.YggdrasilStoppedError = errMsg
+		disconnectResult.YggdrasilStoppedErrorCode = cmp.Or(timeoutCode(err), "deactivation-failed")
 		slog.Error(errMsg)
 		ui.Printf(" [%v] %v\n", ui.Icons.Error, errMsg)
 	} else {
@@ -100,11 +226,96 @@ func (disconnectResult *DisconnectResult) TryDeactivateServices() error {
 	return nil
 }
 
+// offlineCleanupPaths are the local identities of targets, which are removed
+// when the servers of the targets cannot be reached and --force is used.
+var offlineCleanupPaths = map[string][]string{
+	"insights": {
+		datacollection.InsightsMachineIDPath,
+		datacollection.InsightsRegisteredMarkerPath,
+		datacollection.InsightsLastUploadPath,
+		datacollection.InsightsHostDetailsPath,
+	},
+	"rhsm": {
+		remotemanagement.YggdrasilClientIDPath,
+		canonicalFactsPath,
+	},
+}
+
+// offlineCleanupMessages are the warnings about the records of targets left at
+// Red Hat, when only the local state of the targets has been removed.
+var offlineCleanupMessages = map[string]string{
+	"insights": "the local registration with Red Hat Lightspeed was removed, but the host stays in Inventory; remove it from console.redhat.com",
+	"rhsm":     "the local registration with Red Hat Subscription Management was removed, but the system stays registered at the entitlement server; remove it from console.redhat.com",
+}
+
+// offlineErrorCodes are the error codes of disconnections, which failed because
+// the servers of Red Hat cannot be reached.
+var offlineErrorCodes = []string{"timeout", "server-unreachable", "insights-unreachable"}
+
+// isOfflineError reports whether the disconnection failed with errCode because
+// the servers of Red Hat cannot be reached, so only the local registration
+// state can be removed.
+func isOfflineError(errCode string) bool {
+	return slices.Contains(offlineErrorCodes, errCode)
+}
+
+// TryCleanOffline removes the local registration state of target, which could
+// not be disconnected, because the servers of Red Hat cannot be reached. When
+// the state is removed, the target is reported as disconnected, and the error
+// of the disconnection is turned into a warning.
+func (disconnectResult *DisconnectResult) TryCleanOffline(target string) {
+	slog.Warn(fmt.Sprintf("Removing local registration state of %s, Red Hat cannot be reached", target))
+	var err error
+	if target == "rhsm" {
+		err = subman.CleanLocalData()
+	}
+	if err == nil {
+		_, removeErrors := removeLocalState(offlineCleanupPaths[target])
+		for _, path := range offlineCleanupPaths[target] {
+			if removeErr, ok := removeErrors[path]; ok {
+				err = errors.Join(err, fmt.Errorf("cannot remove %s: %s", path, removeErr))
+			}
+		}
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("Cannot remove local registration state of %s: %v", target, err))
+		ui.Printf(" [%v] Cannot remove local registration state: %v\n", ui.Icons.Error, err)
+		return
+	}
+
+	var cause string
+	switch target {
+	case "insights":
+		cause = disconnectResult.InsightsDisconnectedError
+		disconnectResult.InsightsDisconnected = true
+		disconnectResult.InsightsDisconnectedError = ""
+		disconnectResult.InsightsDisconnectedErrorCode = ""
+	case "rhsm":
+		cause = disconnectResult.RHSMDisconnectedError
+		disconnectResult.RHSMDisconnected = true
+		disconnectResult.RHSMDisconnectedError = ""
+		disconnectResult.RHSMDisconnectedErrorCode = ""
+	}
+	message := offlineCleanupMessages[target]
+	if cause != "" {
+		message = fmt.Sprintf("%s: %s", cause, message)
+	}
+	disconnectResult.CleanedOffline = append(disconnectResult.CleanedOffline, target)
+	disconnectResult.Warnings = append(disconnectResult.Warnings, Warning{Code: "cleaned-offline", Message: message})
+	recordAudit("disconnect-offline", map[string]string{"target": target})
+	ui.Printf(" [%v] %v\n", ui.Icons.Warning, offlineCleanupMessages[target])
+}
+
 // TryUnregisterInsightsClient tries to unregister insights-client if the client hasn't been
-// already unregistered
-func (disconnectResult *DisconnectResult) TryUnregisterInsightsClient() error {
+// already unregistered. The run of insights-client is killed, when ctx is done.
+func (disconnectResult *DisconnectResult) TryUnregisterInsightsClient(ctx context.Context) error {
 	slog.Info("Disconnecting from Red Hat Lightspeed")
 
+	// A pending initial upload must not be retried after the disconnection
+	if err := cancelInsightsUpload(); err != nil {
+		slog.Warn(fmt.Sprintf("cannot cancel the pending upload: %v", err))
+	}
+
 	isRegistered, err := datacollection.InsightsClientIsRegistered()
 	if err != nil {
 		return err
@@ -115,11 +326,18 @@ func (disconnectResult *DisconnectResult) TryUnregisterInsightsClient() error {
 		ui.Printf(" [%v] %v\n", ui.Icons.Info, "Already disconnected from Red Hat Lightspeed (formerly Insights)")
 		return nil
 	}
-	err = ui.Spinner(datacollection.UnregisterInsightsClient, ui.Indent.Small, "Disconnecting from Red Hat Lightspeed (formerly Insights)...")
+	err = ui.Spinner(func() error {
+		return datacollection.UnregisterInsightsClient(ctx)
+	}, ui.Indent.Small, "Disconnecting from Red Hat Lightspeed (formerly Insights)...")
 	if err != nil {
 		errMsg := fmt.Sprintf("Cannot disconnect from Red Hat Lightspeed (formerly Insights): %v", err)
 		disconnectResult.InsightsDisconnected = false
 		disconnectResult.InsightsDisconnectedError = errMsg
+		errCode := "insights-unregistration-failed"
+		if errors.Is(err, datacollection.ErrNetwork) {
+			errCode = "insights-unreachable"
+		}
+		disconnectResult.InsightsDisconnectedErrorCode = cmp.Or(timeoutCode(err), errCode)
 		slog.Error(fmt.Sprintf("Cannot disconnect from Red Hat Lightspeed: %v", err))
 		ui.Printf(" [%v] %v\n", ui.Icons.Error, errMsg)
 	} else {
@@ -131,8 +349,8 @@ func (disconnectResult *DisconnectResult) TryUnregisterInsightsClient() error {
 }
 
 // TryUnregisterRHSM tries to unregister system from RHSM if the client hasn't been already
-// unregistered from RHSM
-func (disconnectResult *DisconnectResult) TryUnregisterRHSM() error {
+// unregistered from RHSM. Unregistration is given up, when ctx is done.
+func (disconnectResult *DisconnectResult) TryUnregisterRHSM(ctx context.Context) error {
 	slog.Info("Unregistering system from Red Hat Subscription Management")
 
 	client, err := subman.NewRHSMClient()
@@ -151,7 +369,7 @@ func (disconnectResult *DisconnectResult) TryUnregisterRHSM() error {
 		return nil
 	}
 	err = ui.Spinner(
-		client.Unregister,
+		func() error { return client.Unregister(ctx) },
 		ui.Indent.Small,
 		"Disconnecting from Red Hat Subscription Management...",
 	)
@@ -159,6 +377,7 @@ func (disconnectResult *DisconnectResult) TryUnregisterRHSM() error {
 		errMsg := fmt.Sprintf("Cannot disconnect from Red Hat Subscription Management: %v", err)
 		disconnectResult.RHSMDisconnected = false
 		disconnectResult.RHSMDisconnectedError = errMsg
+		disconnectResult.RHSMDisconnectedErrorCode = cmp.Or(subman.ErrorCode(err), "unregistration-failed")
 		slog.Error(errMsg)
 		ui.Printf(" [%v] %v\n", ui.Icons.Error, errMsg)
 		return nil
@@ -180,16 +399,35 @@ func beforeDisconnectAction(ctx context.Context, cmd *cli.Command) (context.Cont
 
 	configureUI(cmd)
 
+	if err = checkNotifyURLFlag(cmd); err != nil {
+		return ctx, err
+	}
+
+	if _, err = selectDisconnectTargets(cmd.StringSlice("only"), keptDisconnectTargets(cmd)); err != nil {
+		return ctx, flagUsageError(err)
+	}
+
 	return ctx, checkForUnknownArgs(cmd)
 }
 
 // disconnectAction tries to stop (yggdrasil) rhcd service, disconnect from Red Hat Lightspeed,
-// and finally it unregisters system from Red Hat Subscription Management
+// and finally it unregisters system from Red Hat Subscription Management. Targets not
+// selected by --only or kept by --keep-* are left connected.
 func disconnectAction(ctx context.Context, cmd *cli.Command) error {
 	logCommandStart(cmd)
 
 	var disconnectResult DisconnectResult
 	disconnectResult.format = cmd.String("format")
+	phase := reportPhase(cmd, &disconnectResult)
+	if phase {
+		disconnectResult.format = ""
+	}
+	disconnectResult.Warnings = collectWarnings()
+	defer func() {
+		if !disconnectResult.DryRun {
+			notifyWebhook(ctx, cmd, "disconnect", &disconnectResult)
+		}
+	}()
 
 	uid := os.Getuid()
 	if uid != 0 {
@@ -204,6 +442,15 @@ func disconnectAction(ctx context.Context, cmd *cli.Command) error {
 		}
 	}
 
+	if errMsg := disconnectLockError(DisconnectLockPath, cmd.Bool("ignore-lock"), "ignore-lock"); errMsg != "" {
+		slog.Error(errMsg)
+		if ui.IsOutputMachineReadable() {
+			disconnectResult.LockError = errMsg
+			return cli.Exit(disconnectResult, exitcode.Usage)
+		}
+		return cli.Exit(errMsg, exitcode.Usage)
+	}
+
 	hostname, err := os.Hostname()
 	disconnectResult.Hostname = hostname
 	if err != nil {
@@ -216,29 +463,122 @@ func disconnectAction(ctx context.Context, cmd *cli.Command) error {
 		}
 	}
 
+	targets, err := selectDisconnectTargets(cmd.StringSlice("only"), keptDisconnectTargets(cmd))
+	if err != nil {
+		return flagUsageError(err)
+	}
+	if err = checkDisconnectDependents(targets, disconnectTargetConnected); err != nil {
+		var flagErr *FlagError
+		if errors.As(err, &flagErr) {
+			return flagUsageError(err)
+		}
+		return cli.Exit(fmt.Sprintf("cannot check targets of disconnection: %v", err), exitcode.Software)
+	}
+
+	if cmd.Bool("dry-run") {
+		disconnectResult.DryRun = true
+		disconnectResult.Plan = disconnectPlan(targets)
+		if ui.IsOutputMachineReadable() {
+			fmt.Println(disconnectResult.Error())
+		} else {
+			printPlan(disconnectResult.Plan)
+		}
+		return nil
+	}
+
+	// Commands running disconnect as their phase ask for confirmation themselves
+	if !phase {
+		removed := make([]string, 0, len(targets))
+		for _, target := range targets {
+			removed = append(removed, removedMessages[target])
+		}
+		if err = confirmOperation(fmt.Sprintf("Disconnect %v from Red Hat?", hostname), true, removed...); err != nil {
+			return err
+		}
+	}
+
 	slog.Info(fmt.Sprintf("Disconnecting %v from Red Hat", hostname))
 	ui.Printf("Disconnecting %v from Red Hat.\nThis might take a few seconds.\n\n", hostname)
 
+	started := time.Now()
 	var start time.Time
-	durations := make(map[string]time.Duration)
+
+	// Targets not selected by --only or kept by --keep-* stay connected
+	keep := func(target string) bool {
+		if slices.Contains(targets, target) {
+			return false
+		}
+		disconnectResult.Kept = append(disconnectResult.Kept, target)
+		disconnectResult.Timeline.skip(started, target, time.Now())
+		slog.Info(keptMessages[target])
+		ui.Printf(" [%v] %v\n", ui.Icons.Info, keptMessages[target])
+		return true
+	}
 
 	/* 1. Deactivate yggdrasil (rhcd) service */
-	start = time.Now()
-	_ = disconnectResult.TryDeactivateServices()
-	durations["yggdrasil"] = time.Since(start)
+	if !keep("yggdrasil") {
+		start = time.Now()
+		stepCtx, cancel := stepContext(ctx, stepTimeout(cmd, conf.Config.Timeouts.Activation))
+		_ = disconnectResult.TryDeactivateServices(stepCtx)
+		cancel()
+		disconnectResult.Timeline.record(
+			started, "yggdrasil", start, time.Now(), stepResult(disconnectResult.YggdrasilStopped),
+		)
+	}
 
 	/* 2. Disconnect from Red Hat Lightspeed */
-	start = time.Now()
-	_ = disconnectResult.TryUnregisterInsightsClient()
-	durations["insights"] = time.Since(start)
+	if !keep("insights") {
+		start = time.Now()
+		stepCtx, cancel := stepContext(ctx, stepTimeout(cmd, conf.Config.Timeouts.Insights))
+		err = disconnectResult.TryUnregisterInsightsClient(stepCtx)
+		cancel()
+		if err != nil {
+			slog.Error(fmt.Sprintf("Cannot check registration with Red Hat Lightspeed: %v", err))
+		}
+		if !disconnectResult.InsightsDisconnected && cmd.Bool("force") &&
+			isOfflineError(disconnectResult.InsightsDisconnectedErrorCode) {
+			disconnectResult.TryCleanOffline("insights")
+		}
+		disconnectResult.Timeline.record(
+			started, "insights", start, time.Now(), stepResult(disconnectResult.InsightsDisconnected),
+		)
+	}
 
 	/* 3. Unregister system from Red Hat Subscription Management */
-	start = time.Now()
-	_ = disconnectResult.TryUnregisterRHSM()
-	durations["rhsm"] = time.Since(start)
+	if !keep("rhsm") {
+		start = time.Now()
+		stepCtx, cancel := stepContext(ctx, stepTimeout(cmd, conf.Config.Timeouts.RHSM))
+		err = disconnectResult.TryUnregisterRHSM(stepCtx)
+		cancel()
+		if err != nil {
+			slog.Error(fmt.Sprintf("Cannot check registration with Red Hat Subscription Management: %v", err))
+		}
+		if !disconnectResult.RHSMDisconnected && cmd.Bool("force") &&
+			isOfflineError(disconnectResult.RHSMDisconnectedErrorCode) {
+			disconnectResult.TryCleanOffline("rhsm")
+		}
+		disconnectResult.Timeline.record(
+			started, "rhsm", start, time.Now(), stepResult(disconnectResult.RHSMDisconnected),
+		)
+	}
+
+	if disconnectResult.RHSMDisconnected {
+		if err = removeCheckinTimer(); err != nil {
+			slog.Warn(fmt.Sprintf("cannot disable periodic check-in: %v", err))
+		}
+		if err = os.Remove(FeatureStatePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn(fmt.Sprintf("cannot remove feature selection: %v", err))
+		}
+		if err = os.Remove(ConnectionPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn(fmt.Sprintf("cannot remove connection method: %v", err))
+		}
+		if err = os.Remove(ActivationKeysPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn(fmt.Sprintf("cannot remove activation keys: %v", err))
+		}
+	}
 
 	if !ui.IsOutputMachineReadable() {
-		showTimeDuration(durations)
+		showTimeline(disconnectResult.Timeline)
 
 		err = showErrorMessages("disconnect", disconnectResult.errorMessages())
 		if err != nil {
@@ -246,7 +586,7 @@ func disconnectAction(ctx context.Context, cmd *cli.Command) error {
 		}
 	}
 
-	if ui.IsOutputMachineReadable() {
+	if ui.IsOutputMachineReadable() && disconnectResult.format != "" {
 		fmt.Println(disconnectResult.Error())
 	}
 
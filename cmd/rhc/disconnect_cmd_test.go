@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSelectDisconnectTargets(t *testing.T) {
+	tests := []struct {
+		description string
+		only        []string
+		keep        []string
+		want        []string
+		wantCode    string
+	}{
+		{
+			description: "all",
+			want:        []string{"yggdrasil", "insights", "rhsm"},
+		},
+		{
+			description: "only",
+			only:        []string{"rhsm", "yggdrasil"},
+			want:        []string{"yggdrasil", "rhsm"},
+		},
+		{
+			description: "keep",
+			keep:        []string{"rhsm"},
+			want:        []string{"yggdrasil", "insights"},
+		},
+		{
+			description: "unknown target",
+			only:        []string{"content"},
+			wantCode:    "invalid-target",
+		},
+		{
+			description: "only and keep",
+			only:        []string{"yggdrasil"},
+			keep:        []string{"rhsm"},
+			wantCode:    "conflicting-targets",
+		},
+		{
+			description: "all kept",
+			keep:        []string{"rhsm", "insights", "yggdrasil"},
+			wantCode:    "no-target",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := selectDisconnectTargets(test.only, test.keep)
+			if test.wantCode != "" {
+				var flagErr *FlagError
+				if !errors.As(err, &flagErr) || flagErr.Code != test.wantCode {
+					t.Errorf("selectDisconnectTargets() error = %v, want %v", err, test.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
+
+func TestCheckDisconnectDependents(t *testing.T) {
+	tests := []struct {
+		description string
+		targets     []string
+		connected   map[string]bool
+		wantError   bool
+	}{
+		{
+			description: "yggdrasil only",
+			targets:     []string{"yggdrasil"},
+			connected:   map[string]bool{"insights": true, "yggdrasil": true},
+		},
+		{
+			description: "registration with connected dependents",
+			targets:     []string{"rhsm"},
+			connected:   map[string]bool{"insights": true, "yggdrasil": false},
+			wantError:   true,
+		},
+		{
+			description: "registration with disconnected dependents",
+			targets:     []string{"rhsm"},
+			connected:   map[string]bool{"insights": false, "yggdrasil": false},
+		},
+		{
+			description: "insights with active yggdrasil",
+			targets:     []string{"insights", "rhsm"},
+			connected:   map[string]bool{"yggdrasil": true},
+			wantError:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			err := checkDisconnectDependents(test.targets, func(target string) (bool, error) {
+				return test.connected[target], nil
+			})
+			if test.wantError {
+				var flagErr *FlagError
+				if !errors.As(err, &flagErr) || flagErr.Code != "dependent-target" {
+					t.Errorf("checkDisconnectDependents() error = %v, want dependent-target", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDisconnectPlan(t *testing.T) {
+	var got []string
+	for _, step := range disconnectPlan([]string{"yggdrasil", "insights"}) {
+		got = append(got, step.Operation)
+	}
+	want := []string{"services-deactivate", "insights-unregister"}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
+
+func TestTryCleanOffline(t *testing.T) {
+	dir := t.TempDir()
+	machineID := filepath.Join(dir, "machine-id")
+	if err := os.WriteFile(machineID, []byte("id"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	savedPaths, savedAuditLog := offlineCleanupPaths["insights"], AuditLogPath
+	offlineCleanupPaths["insights"] = []string{machineID, filepath.Join(dir, "missing")}
+	AuditLogPath = filepath.Join(dir, "audit.log")
+	t.Cleanup(func() {
+		offlineCleanupPaths["insights"], AuditLogPath = savedPaths, savedAuditLog
+	})
+
+	result := DisconnectResult{
+		InsightsDisconnectedError:     "Cannot disconnect from Red Hat Lightspeed (formerly Insights): timeout",
+		InsightsDisconnectedErrorCode: "insights-unreachable",
+	}
+	result.TryCleanOffline("insights")
+
+	if _, err := os.Stat(machineID); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("%s was not removed: %v", machineID, err)
+	}
+	if !result.InsightsDisconnected || len(result.errorMessages()) > 0 {
+		t.Errorf("insights not reported as disconnected: %+v", result)
+	}
+	if !cmp.Equal(result.CleanedOffline, []string{"insights"}) {
+		t.Errorf("%v", cmp.Diff(result.CleanedOffline, []string{"insights"}))
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Code != "cleaned-offline" {
+		t.Errorf("unexpected warnings: %+v", result.Warnings)
+	}
+}
+
+func TestIsOfflineError(t *testing.T) {
+	for errCode, want := range map[string]bool{
+		"server-unreachable":             true,
+		"insights-unreachable":           true,
+		"timeout":                        true,
+		"authentication-failed":          false,
+		"insights-unregistration-failed": false,
+		"":                               false,
+	} {
+		if got := isOfflineError(errCode); got != want {
+			t.Errorf("isOfflineError(%q) = %v, want %v", errCode, got, want)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	httpapi "github.com/redhatinsights/rhc/internal/http"
+	"github.com/redhatinsights/rhc/internal/logging"
+	"github.com/redhatinsights/rhc/internal/remotemanagement"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+	"github.com/redhatinsights/rhc/pkg/feature"
+)
+
+// Statuses of doctor checks.
+const (
+	checkOK      = "ok"
+	checkWarning = "warning"
+	checkFailed  = "failed"
+	checkSkipped = "skipped"
+)
+
+// DoctorCheck is the outcome of a single check of 'rhc doctor'.
+type DoctorCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	// Hints are actionable remediation steps of problems found by the check.
+	Hints []string `json:"hints,omitempty"`
+}
+
+// DoctorResult is structure holding the outcomes of checks of 'rhc doctor'.
+// The result could be printed in machine-readable format.
+type DoctorResult struct {
+	Checks   []DoctorCheck `json:"checks"`
+	Warnings []Warning     `json:"warnings"`
+}
+
+// doctorCheck is a check run by 'rhc doctor'.
+type doctorCheck struct {
+	name string
+	run  func(ctx context.Context, cmd *cli.Command) DoctorCheck
+}
+
+// doctorChecks are the checks run by 'rhc doctor', in this order.
+var doctorChecks = []doctorCheck{
+	{name: "config", run: checkConfigFiles},
+	{name: "consumer-certificate", run: checkConsumerCertificate},
+	{name: "dbus", run: checkDBus},
+	{name: "yggdrasil-unit", run: checkYggdrasilUnit},
+	{name: "yggdrasil-unit-drift", run: checkYggdrasilDrift},
+	{name: "connectivity", run: checkConnectivity},
+	{name: "selinux", run: checkSELinuxContexts},
+}
+
+// certificateExpiryWarning is the period before expiration of the consumer
+// certificate, in which 'rhc doctor' warns about it.
+const certificateExpiryWarning = 30 * 24 * time.Hour
+
+// selinuxPaths are the paths, whose SELinux contexts are checked by 'rhc doctor'.
+var selinuxPaths = []string{"/etc/rhc", "/var/lib/rhc", "/var/log/rhc", "/etc/pki/consumer"}
+
+// checkConfigFiles validates the configuration file and each of its drop-in
+// files on its own, so the file with an invalid value is reported.
+func checkConfigFiles(ctx context.Context, cmd *cli.Command) DoctorCheck {
+	var paths []string
+	if configPath := cmd.Root().String("config"); configPath != "" {
+		if _, err := os.Stat(configPath); err == nil {
+			paths = append(paths, configPath)
+		}
+	}
+	dropIns, err := conf.DropInPaths(ConfigDropInDir)
+	if err != nil {
+		return DoctorCheck{Status: checkFailed, Message: fmt.Sprintf("cannot list drop-in files: %v", err)}
+	}
+	paths = append(paths, dropIns...)
+
+	check := DoctorCheck{Status: checkOK}
+	invalid := 0
+	for _, path := range paths {
+		tree, err := toml.LoadFile(path)
+		if err == nil {
+			err = validateConfigTree(tree)
+		}
+		if err != nil {
+			invalid++
+			check.Hints = append(check.Hints, fmt.Sprintf("fix or remove %s: %v", path, err))
+		}
+	}
+	if invalid > 0 {
+		check.Status = checkFailed
+		check.Message = fmt.Sprintf("%d of %d configuration file(s) are invalid", invalid, len(paths))
+		return check
+	}
+	check.Message = fmt.Sprintf("%d configuration file(s) are valid", len(paths))
+	return check
+}
+
+// checkConsumerCertificate verifies that the consumer certificate and key of a
+// connected system are present, and that the certificate has not expired.
+func checkConsumerCertificate(ctx context.Context, cmd *cli.Command) DoctorCheck {
+	connected, err := subman.HasConsumerCertificate()
+	if err != nil {
+		return DoctorCheck{Status: checkFailed, Message: err.Error()}
+	}
+	if !connected {
+		return DoctorCheck{Status: checkSkipped, Message: "the system is not connected"}
+	}
+	if _, err = os.Stat(subman.ConsumerKeyPath); err != nil {
+		return DoctorCheck{
+			Status:  checkFailed,
+			Message: fmt.Sprintf("the consumer key is missing: %v", err),
+			Hints:   []string{"connect the system again: rhc reconnect"},
+		}
+	}
+	cert, err := subman.GetConsumerCertificate()
+	if err != nil {
+		return DoctorCheck{
+			Status:  checkFailed,
+			Message: err.Error(),
+			Hints:   []string{"connect the system again: rhc reconnect"},
+		}
+	}
+	return certificateExpiryCheck(cert.NotAfter, time.Now())
+}
+
+// certificateExpiryCheck reports whether the consumer certificate valid until
+// notAfter has expired at now, or expires soon.
+func certificateExpiryCheck(notAfter, now time.Time) DoctorCheck {
+	expiry := notAfter.UTC().Format(time.RFC3339)
+	switch {
+	case !now.Before(notAfter):
+		return DoctorCheck{
+			Status:  checkFailed,
+			Message: fmt.Sprintf("the consumer certificate expired on %s", expiry),
+			Hints:   []string{"connect the system again: rhc reconnect"},
+		}
+	case notAfter.Sub(now) < certificateExpiryWarning:
+		return DoctorCheck{
+			Status:  checkWarning,
+			Message: fmt.Sprintf("the consumer certificate expires on %s", expiry),
+			Hints: []string{
+				"ensure rhsmcertd renews the certificate: systemctl enable --now rhsmcertd.service",
+				"renew the certificate now: subscription-manager identity --regenerate",
+			},
+		}
+	default:
+		return DoctorCheck{Status: checkOK, Message: fmt.Sprintf("the consumer certificate is valid until %s", expiry)}
+	}
+}
+
+// checkDBus verifies that the system D-Bus and the RHSM service are available.
+func checkDBus(ctx context.Context, cmd *cli.Command) DoctorCheck {
+	rhsmClient, err := subman.NewRHSMClient()
+	if err != nil {
+		return DoctorCheck{
+			Status:  checkFailed,
+			Message: err.Error(),
+			Hints:   []string{"start the system D-Bus: systemctl start dbus.service"},
+		}
+	}
+	if _, err = rhsmClient.IsRegistered(); err != nil {
+		return DoctorCheck{
+			Status:  checkFailed,
+			Message: fmt.Sprintf("the RHSM service is not available: %v", err),
+			Hints: []string{
+				"start the RHSM service: systemctl start rhsm.service",
+				"check the RHSM service: journalctl -u rhsm.service",
+			},
+		}
+	}
+	return DoctorCheck{Status: checkOK, Message: "the system D-Bus and the RHSM service are available"}
+}
+
+// checkYggdrasilUnit verifies that yggdrasil is running, when remote
+// management has been selected.
+func checkYggdrasilUnit(ctx context.Context, cmd *cli.Command) DoctorCheck {
+	state, err := remotemanagement.GetUnitState("yggdrasil.service")
+	if err != nil {
+		return DoctorCheck{Status: checkFailed, Message: err.Error()}
+	}
+	if state.LoadState == "not-found" {
+		return DoctorCheck{Status: checkSkipped, Message: "yggdrasil is not installed"}
+	}
+	switch state.ActiveState {
+	case "active":
+		return DoctorCheck{Status: checkOK, Message: "yggdrasil.service is active"}
+	case "failed":
+		return DoctorCheck{
+			Status:  checkFailed,
+			Message: "yggdrasil.service has failed",
+			Hints: []string{
+				"check the log of yggdrasil: journalctl -u yggdrasil.service",
+				"restart yggdrasil: systemctl restart yggdrasil.service",
+			},
+		}
+	}
+
+	selection, err := feature.LoadState(FeatureStatePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Debug("Unable to read feature selection", "err", err)
+	}
+	if selection != nil && selection.Features["remote-management"] {
+		return DoctorCheck{
+			Status:  checkWarning,
+			Message: fmt.Sprintf("yggdrasil.service is %s, although remote management was selected", state.ActiveState),
+			Hints:   []string{"re-apply the selected features: rhc feature reconcile"},
+		}
+	}
+	return DoctorCheck{Status: checkOK, Message: fmt.Sprintf("yggdrasil.service is %s; remote management is not selected", state.ActiveState)}
+}
+
+// checkConnectivity verifies that Red Hat Hybrid Cloud Console, or the Red Hat
+// Lightspeed gateway used instead, and the entitlement server can be reached
+// through the configured proxy.
+func checkConnectivity(ctx context.Context, cmd *cli.Command) DoctorCheck {
+	urls := []string{consoleURL, "https://subscription.rhsm.redhat.com/subscription"}
+	if conf.Config.Insights.GatewayURL != "" {
+		urls[0] = conf.Config.Insights.GatewayURL
+	}
+	if rhsmClient, err := subman.NewRHSMClient(); err == nil {
+		if serverURL, err := rhsmClient.ServerURL(); err == nil {
+			urls[1] = serverURL
+		}
+	}
+
+	check := DoctorCheck{Status: checkOK}
+	for _, rawURL := range urls {
+		if _, err := httpapi.ProbeTLS(ctx, rawURL); err != nil {
+			check.Status = checkFailed
+			check.Hints = append(check.Hints, fmt.Sprintf("%s cannot be reached: %v", rawURL, err))
+		}
+	}
+	if check.Status == checkFailed {
+		check.Message = "Red Hat services cannot be reached"
+		check.Hints = append(check.Hints, "check the network, the firewall and the '[proxy]' section of the configuration file")
+		return check
+	}
+	check.Message = fmt.Sprintf("%s can be reached", strings.Join(urls, " and "))
+	return check
+}
+
+// checkSELinuxContexts verifies that files of rhc have the SELinux contexts
+// defined by the policy.
+func checkSELinuxContexts(ctx context.Context, cmd *cli.Command) DoctorCheck {
+	if _, err := os.Stat("/sys/fs/selinux/enforce"); err != nil {
+		return DoctorCheck{Status: checkSkipped, Message: "SELinux is disabled"}
+	}
+	var paths []string
+	for _, path := range selinuxPaths {
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return DoctorCheck{Status: checkSkipped, Message: "no files of rhc to check"}
+	}
+
+	args := append([]string{"-n", "-v", "-R"}, paths...)
+	slog.Debug(fmt.Sprintf("Executing /usr/sbin/restorecon %s", strings.Join(args, " ")))
+	restorecon := exec.CommandContext(ctx, "/usr/sbin/restorecon", args...)
+	start := logging.CommandStart(restorecon)
+	output, err := restorecon.CombinedOutput()
+	logging.Command(restorecon, start, err)
+	if errors.Is(err, os.ErrNotExist) {
+		return DoctorCheck{Status: checkSkipped, Message: "restorecon is not installed"}
+	}
+	if err != nil {
+		return DoctorCheck{
+			Status:  checkFailed,
+			Message: fmt.Sprintf("cannot check SELinux contexts: %v: %s", err, strings.TrimSpace(string(output))),
+		}
+	}
+
+	relabeled := parseRelabeledPaths(string(output))
+	if len(relabeled) == 0 {
+		return DoctorCheck{Status: checkOK, Message: "files of rhc have the expected SELinux contexts"}
+	}
+	check := DoctorCheck{
+		Status:  checkWarning,
+		Message: fmt.Sprintf("%d file(s) of rhc have unexpected SELinux contexts", len(relabeled)),
+	}
+	for _, path := range relabeled {
+		check.Hints = append(check.Hints, fmt.Sprintf("restore the context: restorecon -v %s", path))
+	}
+	return check
+}
+
+// parseRelabeledPaths returns the paths restorecon would relabel according to
+// its verbose output of a dry run.
+func parseRelabeledPaths(output string) []string {
+	var paths []string
+	for _, line := range strings.Split(output, "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), "Would relabel ")
+		if !ok {
+			continue
+		}
+		if path, _, ok := strings.Cut(rest, " from "); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// checkYggdrasilDrift reports local overrides of the yggdrasil unit and
+// configuration, which commonly break remote management.
+func checkYggdrasilDrift(ctx context.Context, cmd *cli.Command) DoctorCheck {
+	drifts, err := remotemanagement.DetectYggdrasilDrift()
+	if err != nil {
+		return DoctorCheck{Status: checkFailed, Message: err.Error()}
+	}
+	if len(drifts) == 0 {
+		return DoctorCheck{Status: checkOK, Message: "yggdrasil unit and configuration match the expected settings"}
+	}
+
+	check := DoctorCheck{
+		Status:  checkWarning,
+		Message: fmt.Sprintf("%d local override(s) of yggdrasil may break remote management", len(drifts)),
+	}
+	for _, drift := range drifts {
+		hint := fmt.Sprintf("%s: %s", drift.Path, drift.Problem)
+		if drift.Value != "" {
+			hint = fmt.Sprintf("%s: %s=%s: %s", drift.Path, drift.Setting, drift.Value, drift.Problem)
+		}
+		check.Hints = append(check.Hints, hint)
+	}
+	return check
+}
+
+// beforeDoctorAction ensures the user has supplied a correct `--format` flag.
+func beforeDoctorAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	if cmd.Bool("permissions") && cmd.Bool("convert") {
+		return ctx, cli.Exit("--permissions and --convert cannot be used together", exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// doctorAction runs the checks of the system, and prints their outcomes
+// together with hints how to fix the problems found. With --permissions, it
+// prints the capabilities of the current user instead. With --convert, it runs
+// the checks of conversion of the system to RHEL instead.
+func doctorAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+	if cmd.Bool("permissions") {
+		return permissionsAction(cmd)
+	}
+	if cmd.Bool("convert") {
+		return runChecks(ctx, cmd, convertChecks)
+	}
+	return runChecks(ctx, cmd, doctorChecks)
+}
+
+// runChecks runs checks in order, and prints their outcomes together with hints
+// how to fix the problems found. It fails, when any check fails.
+func runChecks(ctx context.Context, cmd *cli.Command, checks []doctorCheck) error {
+	result := DoctorResult{Checks: []DoctorCheck{}, Warnings: collectWarnings()}
+	failed := false
+	for _, c := range checks {
+		check := c.run(ctx, cmd)
+		check.Name = c.name
+		slog.Info("Check finished", "command", cmd.Name, "check", check.Name, "status", check.Status, "message", check.Message)
+		if check.Status == checkFailed {
+			failed = true
+		}
+		result.Checks = append(result.Checks, check)
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err := ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print checks as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+	} else {
+		for _, check := range result.Checks {
+			icon := ui.Icons.Ok
+			switch check.Status {
+			case checkWarning:
+				icon = ui.Icons.Warning
+			case checkFailed:
+				icon = ui.Icons.Error
+			case checkSkipped:
+				icon = ui.Icons.Info
+			}
+			ui.Printf("%s[%v] %s: %s\n", ui.Indent.Small, icon, check.Name, check.Message)
+			for _, hint := range check.Hints {
+				ui.Printf("%s%s- %s\n", ui.Indent.Medium, ui.Indent.Medium, hint)
+			}
+		}
+		printWarnings(result.Warnings)
+	}
+
+	if failed {
+		return cli.Exit("", exitcode.Err)
+	}
+	return nil
+}
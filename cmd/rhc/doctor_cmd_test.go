@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCertificateExpiryCheck(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		description string
+		notAfter    time.Time
+		want        string
+	}{
+		{description: "valid", notAfter: now.AddDate(1, 0, 0), want: checkOK},
+		{description: "expires soon", notAfter: now.AddDate(0, 0, 7), want: checkWarning},
+		{description: "expired", notAfter: now.AddDate(0, 0, -1), want: checkFailed},
+		{description: "expires now", notAfter: now, want: checkFailed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := certificateExpiryCheck(test.notAfter, now)
+			if got.Status != test.want {
+				t.Errorf("got status %q (%s), want %q", got.Status, got.Message, test.want)
+			}
+		})
+	}
+}
+
+func TestParseRelabeledPaths(t *testing.T) {
+	output := `Would relabel /var/lib/rhc/connection.json from unconfined_u:object_r:user_tmp_t:s0 to system_u:object_r:rhc_var_lib_t:s0
+Would relabel /etc/rhc/config.toml from unconfined_u:object_r:admin_home_t:s0 to system_u:object_r:etc_t:s0
+`
+	want := []string{"/var/lib/rhc/connection.json", "/etc/rhc/config.toml"}
+	got := parseRelabeledPaths(output)
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+
+	if got = parseRelabeledPaths(""); got != nil {
+		t.Errorf("got %v without output, want nil", got)
+	}
+}
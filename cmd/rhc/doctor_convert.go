@@ -0,0 +1,212 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"golang.org/x/sys/unix"
+
+	"github.com/redhatinsights/rhc/internal/datacollection"
+	"github.com/redhatinsights/rhc/internal/logging"
+	"github.com/redhatinsights/rhc/internal/subman"
+)
+
+// convertChecks are the checks run by 'rhc doctor --convert', in this order.
+var convertChecks = []doctorCheck{
+	{name: "third-party-repositories", run: checkConvertRepositories},
+	{name: "kernel", run: checkConvertKernel},
+	{name: "secure-boot", run: checkConvertSecureBoot},
+	{name: "pre-conversion-analysis", run: checkConvertAnalysis},
+}
+
+// convertVendorDomains are the domains of repositories of the distributions
+// convert2rhel converts from, and of Red Hat.
+var convertVendorDomains = []string{"centos.org", "almalinux.org", "rockylinux.org", "oracle.com", "redhat.com"}
+
+// convertKernelVendors are the vendors of kernels supported by convert2rhel.
+var convertKernelVendors = []string{"centos", "almalinux", "rocky", "oracle", "red hat"}
+
+// secureBootPath is the EFI variable holding the state of Secure Boot.
+const secureBootPath = "/sys/firmware/efi/efivars/SecureBoot-8be4df61-93ca-11d2-aa0d-00e098032b8c"
+
+// isThirdPartyRepository reports whether repo is served from outside of the
+// domains of the vendors. Repositories without a URL of a server (e.g. local
+// mirrors) are not reported, as their origin is unknown.
+func isThirdPartyRepository(repo subman.Repository) bool {
+	for _, rawURL := range []string{repo.BaseURL, repo.MirrorList, repo.Metalink} {
+		fields := strings.FieldsFunc(rawURL, func(r rune) bool { return r == ',' || r == ' ' })
+		if len(fields) == 0 {
+			continue
+		}
+		parsed, err := url.Parse(fields[0])
+		if err != nil || parsed.Hostname() == "" {
+			continue
+		}
+		host := strings.ToLower(parsed.Hostname())
+		return !slices.ContainsFunc(convertVendorDomains, func(domain string) bool {
+			return host == domain || strings.HasSuffix(host, "."+domain)
+		})
+	}
+	return false
+}
+
+// checkConvertRepositories warns about enabled third-party repositories, whose
+// packages are kept back or removed by the conversion.
+func checkConvertRepositories(ctx context.Context, cmd *cli.Command) DoctorCheck {
+	repos, err := subman.ReadRepositoryDir(subman.RepositoryDir)
+	if err != nil {
+		return DoctorCheck{Status: checkFailed, Message: err.Error()}
+	}
+	check := DoctorCheck{Status: checkOK, Message: "no third-party repository is enabled"}
+	var ids []string
+	for _, repo := range repos {
+		if repo.Enabled && isThirdPartyRepository(repo) {
+			ids = append(ids, repo.ID)
+			check.Hints = append(check.Hints, fmt.Sprintf("disable the repository: dnf config-manager --set-disabled %s", repo.ID))
+		}
+	}
+	if len(ids) > 0 {
+		check.Status = checkWarning
+		check.Message = fmt.Sprintf(
+			"third-party repositories are enabled, their packages are not converted: %s", strings.Join(ids, ", "),
+		)
+	}
+	return check
+}
+
+// kernelCheck checks the running kernel of release built by vendor; vendor is
+// empty, when it is not known.
+func kernelCheck(release, vendor string) DoctorCheck {
+	lower := strings.ToLower(release)
+	switch {
+	case strings.Contains(lower, "uek"):
+		return DoctorCheck{
+			Status:  checkFailed,
+			Message: fmt.Sprintf("the Unbreakable Enterprise Kernel %s cannot be converted", release),
+			Hints:   []string{"boot the Red Hat Compatible Kernel before the conversion"},
+		}
+	case strings.Contains(lower, "plus"):
+		return DoctorCheck{
+			Status:  checkFailed,
+			Message: fmt.Sprintf("the kernel %s of an add-on repository cannot be converted", release),
+			Hints:   []string{"boot the standard kernel of the distribution before the conversion"},
+		}
+	}
+	if vendor != "" && !slices.ContainsFunc(convertKernelVendors, func(known string) bool {
+		return strings.Contains(strings.ToLower(vendor), known)
+	}) {
+		return DoctorCheck{
+			Status:  checkWarning,
+			Message: fmt.Sprintf("the kernel %s is built by %s", release, vendor),
+			Hints:   []string{"boot the standard kernel of the distribution before the conversion"},
+		}
+	}
+	return DoctorCheck{Status: checkOK, Message: fmt.Sprintf("the kernel %s can be converted", release)}
+}
+
+// checkConvertKernel verifies the running kernel is the standard kernel of the
+// distribution.
+func checkConvertKernel(ctx context.Context, cmd *cli.Command) DoctorCheck {
+	var uname unix.Utsname
+	if err := unix.Uname(&uname); err != nil {
+		return DoctorCheck{Status: checkFailed, Message: fmt.Sprintf("cannot read the running kernel: %v", err)}
+	}
+	release := unix.ByteSliceToString(uname.Release[:])
+	rpm := exec.CommandContext(
+		ctx, "/usr/bin/rpm", "--query", "--queryformat", "%{VENDOR}", "--file", "/lib/modules/"+release+"/vmlinuz",
+	)
+	start := logging.CommandStart(rpm)
+	output, err := rpm.Output()
+	logging.Command(rpm, start, err)
+	vendor := strings.TrimSpace(string(output))
+	if err != nil || vendor == "(none)" {
+		vendor = ""
+	}
+	return kernelCheck(release, vendor)
+}
+
+// secureBootEnabled reports whether the EFI variable data of Secure Boot, which
+// starts with 4 bytes of attributes, enables Secure Boot.
+func secureBootEnabled(data []byte) bool {
+	return len(data) == 5 && data[4] == 1
+}
+
+// checkConvertSecureBoot verifies Secure Boot is disabled, as convert2rhel
+// cannot convert systems booted with Secure Boot.
+func checkConvertSecureBoot(ctx context.Context, cmd *cli.Command) DoctorCheck {
+	data, err := os.ReadFile(secureBootPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return DoctorCheck{Status: checkOK, Message: "the system is not booted with Secure Boot"}
+	}
+	if err != nil {
+		return DoctorCheck{Status: checkFailed, Message: fmt.Sprintf("cannot read the state of Secure Boot: %v", err)}
+	}
+	if secureBootEnabled(data) {
+		return DoctorCheck{
+			Status:  checkFailed,
+			Message: "Secure Boot is enabled",
+			Hints:   []string{"disable Secure Boot in the firmware settings before the conversion"},
+		}
+	}
+	return DoctorCheck{Status: checkOK, Message: "Secure Boot is disabled"}
+}
+
+// checkConvertAnalysis runs the pre-conversion analysis of convert2rhel, which
+// the task of Red Hat Lightspeed runs, and summarizes its findings.
+func checkConvertAnalysis(ctx context.Context, cmd *cli.Command) DoctorCheck {
+	if os.Getuid() != 0 {
+		return DoctorCheck{
+			Status:  checkSkipped,
+			Message: "the pre-conversion analysis requires root",
+			Hints:   []string{"run the checks as root: rhc doctor --convert"},
+		}
+	}
+	if _, err := exec.LookPath("/usr/bin/convert2rhel"); err != nil {
+		return DoctorCheck{
+			Status:  checkSkipped,
+			Message: "convert2rhel is not installed",
+			Hints: []string{
+				"install convert2rhel: dnf install convert2rhel",
+				"or run the task \"Pre-conversion analysis for converting to RHEL\" in Red Hat Lightspeed",
+			},
+		}
+	}
+
+	analysis, err := datacollection.RunConvertAnalysis(ctx)
+	if err != nil {
+		return DoctorCheck{Status: checkFailed, Message: err.Error()}
+	}
+	check := DoctorCheck{Status: checkOK, Message: "no finding blocks the conversion"}
+	for _, finding := range analysis.Blockers {
+		check.Hints = append(check.Hints, convertFindingHint(finding))
+	}
+	for _, finding := range analysis.Findings {
+		check.Hints = append(check.Hints, convertFindingHint(finding))
+	}
+	switch {
+	case !analysis.Ready:
+		check.Status = checkFailed
+		check.Message = fmt.Sprintf("%d findings block the conversion, see %s", len(analysis.Blockers), datacollection.ConvertReportPath)
+	case len(analysis.Findings) > 0:
+		check.Status = checkWarning
+		check.Message = fmt.Sprintf("%d findings should be reviewed, see %s", len(analysis.Findings), datacollection.ConvertReportPath)
+	}
+	return check
+}
+
+// convertFindingHint returns the hint of finding of the pre-conversion analysis.
+func convertFindingHint(finding datacollection.ConvertFinding) string {
+	hint := fmt.Sprintf("%s: %s", finding.Level, cmp.Or(finding.Title, finding.ActionID))
+	if finding.Remediations != "" {
+		hint += ": " + finding.Remediations
+	}
+	return hint
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/redhatinsights/rhc/internal/subman"
+)
+
+func TestIsThirdPartyRepository(t *testing.T) {
+	tests := []struct {
+		description string
+		repo        subman.Repository
+		want        bool
+	}{
+		{
+			description: "vendor",
+			repo:        subman.Repository{ID: "baseos", MirrorList: "http://mirrorlist.centos.org/?release=$releasever&arch=$basearch&repo=BaseOS"},
+			want:        false,
+		},
+		{
+			description: "vendor domain",
+			repo:        subman.Repository{ID: "appstream", BaseURL: "https://dl.rockylinux.org/$contentdir/$releasever/AppStream/$basearch/os/"},
+			want:        false,
+		},
+		{
+			description: "third party",
+			repo:        subman.Repository{ID: "epel", Metalink: "https://mirrors.fedoraproject.org/metalink?repo=epel-8&arch=$basearch"},
+			want:        true,
+		},
+		{
+			description: "similar domain",
+			repo:        subman.Repository{ID: "mirror", BaseURL: "https://notcentos.org/8/BaseOS/"},
+			want:        true,
+		},
+		{
+			description: "local mirror",
+			repo:        subman.Repository{ID: "local", BaseURL: "file:///srv/mirror/BaseOS"},
+			want:        false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if got := isThirdPartyRepository(test.repo); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestKernelCheck(t *testing.T) {
+	tests := []struct {
+		description string
+		release     string
+		vendor      string
+		want        string
+	}{
+		{description: "standard kernel", release: "4.18.0-553.el8_10.x86_64", vendor: "CentOS", want: checkOK},
+		{description: "unknown vendor", release: "4.18.0-553.el8_10.x86_64", vendor: "", want: checkOK},
+		{description: "UEK", release: "5.15.0-200.131.27.el8uek.x86_64", vendor: "Oracle America", want: checkFailed},
+		{description: "centosplus", release: "4.18.0-553.el8.centos.plus.x86_64", vendor: "CentOS", want: checkFailed},
+		{description: "custom kernel", release: "6.9.0-custom", vendor: "Example Corp", want: checkWarning},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := kernelCheck(test.release, test.vendor)
+			if got.Status != test.want {
+				t.Errorf("got status %q (%s), want %q", got.Status, got.Message, test.want)
+			}
+		})
+	}
+}
+
+func TestSecureBootEnabled(t *testing.T) {
+	if !secureBootEnabled([]byte{0x06, 0x00, 0x00, 0x00, 0x01}) {
+		t.Error("enabled Secure Boot not detected")
+	}
+	if secureBootEnabled([]byte{0x06, 0x00, 0x00, 0x00, 0x00}) {
+		t.Error("disabled Secure Boot detected as enabled")
+	}
+	if secureBootEnabled(nil) {
+		t.Error("empty variable detected as enabled")
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/audit"
+	"github.com/redhatinsights/rhc/internal/systemd"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// eventJournalUnits are the units whose journal entries are included in the events.
+var eventJournalUnits = []string{"yggdrasil.service", "insights-client.service", checkinServiceName}
+
+// Event is an entry of the merged view of the audit log, the journal and the rhc log.
+type Event struct {
+	Time time.Time `json:"time"`
+	// Source is "audit", "rhc", or the name of the unit that logged the event.
+	Source  string `json:"source"`
+	Level   string `json:"level,omitempty"`
+	Message string `json:"message"`
+}
+
+// EventsResult is structure holding the events of a time window, oldest first.
+// The result could be printed in machine-readable format.
+type EventsResult struct {
+	Since    time.Time `json:"since"`
+	Until    time.Time `json:"until"`
+	Events   []Event   `json:"events"`
+	Warnings []Warning `json:"warnings"`
+}
+
+// parseEventTime parses the bound of the time window of events. A duration
+// (e.g. "2h") is relative to now; a time of day (e.g. "02:00") refers to today.
+// Dates and times are interpreted in the local time zone.
+func parseEventTime(value string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{time.DateTime, "2006-01-02 15:04", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{time.TimeOnly, "15:04"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			year, month, day := now.Date()
+			return time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), 0, now.Location()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf(
+		"invalid time %q: use a duration (e.g. \"2h\"), a time (e.g. \"02:00\") or a date (e.g. \"2025-01-31 02:00\")",
+		value,
+	)
+}
+
+// eventWindow returns the time window of events requested by --since and --until.
+func eventWindow(cmd *cli.Command, now time.Time) (time.Time, time.Time, error) {
+	since, err := parseEventTime(cmd.String("since"), now)
+	if err != nil {
+		return since, now, err
+	}
+	until := now
+	if cmd.IsSet("until") {
+		if until, err = parseEventTime(cmd.String("until"), now); err != nil {
+			return since, until, err
+		}
+	}
+	if !since.Before(until) {
+		return since, until, fmt.Errorf("--since has to be before --until")
+	}
+	return since, until, nil
+}
+
+// inWindow reports whether t is in the window [since, until].
+func inWindow(t, since, until time.Time) bool {
+	return !t.Before(since) && !t.After(until)
+}
+
+// auditEvents returns entries of the audit log at path within the window.
+func auditEvents(path string, since, until time.Time) ([]Event, error) {
+	entries, err := audit.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	events := []Event{}
+	for _, entry := range entries {
+		if !inWindow(entry.Time, since, until) {
+			continue
+		}
+		message := entry.Action
+		for _, key := range slices.Sorted(maps.Keys(entry.Details)) {
+			message += fmt.Sprintf(" %s=%s", key, strconv.Quote(entry.Details[key]))
+		}
+		events = append(events, Event{
+			Time:    entry.Time,
+			Source:  "audit",
+			Message: fmt.Sprintf("%s (uid %d)", message, entry.UID),
+		})
+	}
+	return events, nil
+}
+
+// journalPriorities maps syslog priorities to the names of log levels.
+var journalPriorities = []string{"EMERG", "ALERT", "CRIT", "ERROR", "WARN", "NOTICE", "INFO", "DEBUG"}
+
+// journalEvents returns journal entries of eventJournalUnits within the window.
+func journalEvents(since, until time.Time) ([]Event, error) {
+	entries, err := systemd.GetJournalEntries(eventJournalUnits, since, until)
+	if err != nil {
+		return nil, err
+	}
+	events := []Event{}
+	for _, entry := range entries {
+		event := Event{Time: entry.Time, Source: entry.Unit, Message: entry.Message}
+		if entry.Priority >= 0 && entry.Priority < len(journalPriorities) {
+			event.Level = journalPriorities[entry.Priority]
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// rhcLogEvents returns entries of the rhc log at path within the window.
+// Lines that are not written by rhc are skipped.
+func rhcLogEvents(path string, since, until time.Time) ([]Event, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Event{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open rhc log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	events := []Event{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		event, ok := parseLogLine(scanner.Text())
+		if ok && inWindow(event.Time, since, until) {
+			events = append(events, event)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read rhc log: %w", err)
+	}
+	return events, nil
+}
+
+// parseLogLine parses a line written by slog.TextHandler into an event. Attributes
+// other than the time, the level and the message are appended to the message.
+func parseLogLine(line string) (Event, bool) {
+	event := Event{Source: "rhc"}
+	var attrs []string
+	for _, pair := range parseLogfmt(line) {
+		switch pair[0] {
+		case slog.TimeKey:
+			t, err := time.Parse(time.RFC3339Nano, pair[1])
+			if err != nil {
+				return event, false
+			}
+			event.Time = t
+		case slog.LevelKey:
+			event.Level = pair[1]
+		case slog.MessageKey:
+			event.Message = pair[1]
+		default:
+			attrs = append(attrs, pair[0]+"="+strconv.Quote(pair[1]))
+		}
+	}
+	if event.Time.IsZero() {
+		return event, false
+	}
+	if len(attrs) > 0 {
+		event.Message = strings.TrimSpace(event.Message + " " + strings.Join(attrs, " "))
+	}
+	return event, true
+}
+
+// parseLogfmt splits a line of key=value pairs. Quoted values are unquoted.
+// Parsing stops at the first malformed pair.
+func parseLogfmt(line string) [][2]string {
+	var pairs [][2]string
+	for line = strings.TrimSpace(line); line != ""; line = strings.TrimLeft(line, " ") {
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 || strings.ContainsRune(line[:eq], ' ') {
+			break
+		}
+		key := line[:eq]
+		line = line[eq+1:]
+
+		var value string
+		if strings.HasPrefix(line, `"`) {
+			quoted, err := strconv.QuotedPrefix(line)
+			if err != nil {
+				break
+			}
+			value, _ = strconv.Unquote(quoted)
+			line = line[len(quoted):]
+		} else {
+			end := strings.IndexByte(line, ' ')
+			if end < 0 {
+				end = len(line)
+			}
+			value = line[:end]
+			line = line[end:]
+		}
+		pairs = append(pairs, [2]string{key, value})
+	}
+	return pairs
+}
+
+// rhcLogPath returns the path of the log file of rhc used by the current user.
+func rhcLogPath() string {
+	if logFile != nil {
+		return logFile.Name()
+	}
+	return filepath.Join(LogDir, "rhc.log")
+}
+
+// collectEvents merges events of all sources within the window. Sources that
+// cannot be read are reported as warnings.
+func collectEvents(since, until time.Time) EventsResult {
+	result := EventsResult{Since: since, Until: until, Events: []Event{}, Warnings: []Warning{}}
+
+	sources := []struct {
+		name    string
+		collect func() ([]Event, error)
+	}{
+		{name: "audit log", collect: func() ([]Event, error) { return auditEvents(AuditLogPath, since, until) }},
+		{name: "journal", collect: func() ([]Event, error) { return journalEvents(since, until) }},
+		{name: "rhc log", collect: func() ([]Event, error) { return rhcLogEvents(rhcLogPath(), since, until) }},
+	}
+	for _, source := range sources {
+		events, err := source.collect()
+		if err != nil {
+			slog.Warn("Unable to read events", "source", source.name, "err", err)
+			result.Warnings = append(result.Warnings, Warning{
+				Code:    "events-unavailable",
+				Message: fmt.Sprintf("events of %s are not included: %v", source.name, err),
+			})
+			continue
+		}
+		result.Events = append(result.Events, events...)
+	}
+
+	slices.SortStableFunc(result.Events, func(a, b Event) int {
+		return a.Time.Compare(b.Time)
+	})
+	return result
+}
+
+// eventsAction prints the events of the time window requested by --since and --until.
+func eventsAction(ctx context.Context, cmd *cli.Command) error {
+	since, until, err := eventWindow(cmd, time.Now())
+	if err != nil {
+		return cli.Exit(err.Error(), exitcode.Usage)
+	}
+
+	result := collectEvents(since, until)
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print events as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+
+	ui.Printf("Events from %s to %s:\n\n", since.Local().Format(time.DateTime), until.Local().Format(time.DateTime))
+	if len(result.Events) == 0 {
+		ui.Printf("%s[%v] No events found\n", ui.Indent.Small, ui.Icons.Info)
+	} else {
+		rows := make([][]string, 0, len(result.Events))
+		for _, event := range result.Events {
+			rows = append(rows, []string{
+				event.Time.Local().Format(time.DateTime),
+				event.Source,
+				cmp.Or(event.Level, "-"),
+				event.Message,
+			})
+		}
+		ui.PrintTable([]string{"TIME", "SOURCE", "LEVEL", "MESSAGE"}, rows)
+	}
+	printWarnings(result.Warnings)
+	return nil
+}
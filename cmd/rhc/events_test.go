@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseEventTime(t *testing.T) {
+	location := time.FixedZone("CET", 3600)
+	now := time.Date(2025, 1, 31, 10, 30, 0, 0, location)
+
+	tests := []struct {
+		value     string
+		want      time.Time
+		wantError bool
+	}{
+		{value: "2h", want: now.Add(-2 * time.Hour)},
+		{value: "02:00", want: time.Date(2025, 1, 31, 2, 0, 0, 0, location)},
+		{value: "02:00:30", want: time.Date(2025, 1, 31, 2, 0, 30, 0, location)},
+		{value: "2025-01-30", want: time.Date(2025, 1, 30, 0, 0, 0, 0, location)},
+		{value: "2025-01-30 23:15", want: time.Date(2025, 1, 30, 23, 15, 0, 0, location)},
+		{value: "2025-01-30T23:15:00Z", want: time.Date(2025, 1, 30, 23, 15, 0, 0, time.UTC)},
+		{value: "-2h", wantError: true},
+		{value: "yesterday", wantError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, err := parseEventTime(test.value, now)
+			if test.wantError {
+				if err == nil {
+					t.Errorf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		description string
+		line        string
+		want        Event
+		wantOK      bool
+	}{
+		{
+			description: "message with attributes",
+			line:        `time=2025-01-31T02:00:01.123+01:00 level=WARN msg="Unable to record audit entry" action=lock err="cannot open audit log: permission denied"`,
+			want: Event{
+				Time:    time.Date(2025, 1, 31, 2, 0, 1, 123000000, time.FixedZone("", 3600)),
+				Source:  "rhc",
+				Level:   "WARN",
+				Message: `Unable to record audit entry action="lock" err="cannot open audit log: permission denied"`,
+			},
+			wantOK: true,
+		},
+		{
+			description: "message without attributes",
+			line:        `time=2025-01-31T02:00:01Z level=INFO msg="Command 'rhc status' started"`,
+			want: Event{
+				Time:    time.Date(2025, 1, 31, 2, 0, 1, 0, time.UTC),
+				Source:  "rhc",
+				Level:   "INFO",
+				Message: "Command 'rhc status' started",
+			},
+			wantOK: true,
+		},
+		{
+			description: "empty line",
+			line:        "",
+		},
+		{
+			description: "foreign line",
+			line:        "Traceback (most recent call last):",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, ok := parseLogLine(test.line)
+			if ok != test.wantOK {
+				t.Fatalf("got ok %v, want %v", ok, test.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if !got.Time.Equal(test.want.Time) {
+				t.Errorf("got time %v, want %v", got.Time, test.want.Time)
+			}
+			got.Time = test.want.Time
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
+
+func TestRhcLogEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rhc.log")
+	content := `time=2025-01-31T01:00:00Z level=INFO msg="before"
+
+time=2025-01-31T02:00:00Z level=INFO msg="within"
+time=2025-01-31T03:00:00Z level=INFO msg="after"
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	since := time.Date(2025, 1, 31, 1, 30, 0, 0, time.UTC)
+	until := time.Date(2025, 1, 31, 2, 30, 0, 0, time.UTC)
+	got, err := rhcLogEvents(path, since, until)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Event{{Time: time.Date(2025, 1, 31, 2, 0, 0, 0, time.UTC), Source: "rhc", Level: "INFO", Message: "within"}}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/cleanup"
+)
+
+// signalGracePeriod is the time an action has to return after the context is
+// canceled by a signal, before rhc exits without waiting for it.
+const signalGracePeriod = 5 * time.Second
+
+// runCleanup releases resources registered for cleanup, which have not been
+// released by the action.
+func runCleanup() {
+	if err := cleanup.Run(); err != nil {
+		slog.Warn("Unable to clean up", "err", err)
+	}
+}
+
+// notifySignalContext returns a context canceled on SIGINT or SIGTERM, so actions
+// can stop gracefully. When the action does not return within signalGracePeriod,
+// or another signal is received, registered resources are released and rhc exits.
+func notifySignalContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			slog.Info("Received signal, stopping", "signal", sig.String())
+			cancel()
+			select {
+			case <-time.After(signalGracePeriod):
+			case <-signals:
+			}
+			exitOnSignal(sig)
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+// exitOnSignal releases registered resources and exits with the status of a
+// process terminated by sig.
+func exitOnSignal(sig os.Signal) {
+	slog.Warn("Exiting without waiting for the command to stop", "signal", sig.String())
+	runCleanup()
+	_ = closeLogFile()
+	code := 1
+	if s, ok := sig.(syscall.Signal); ok {
+		code = 128 + int(s)
+	}
+	os.Exit(code)
+}
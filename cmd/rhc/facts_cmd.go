@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+	"github.com/redhatinsights/rhc/pkg/facts"
+)
+
+// factsRegistry returns the registry of facts collectors with timeouts
+// overridden by the configuration.
+func factsRegistry() (*facts.Registry, error) {
+	registry := facts.DefaultRegistry(facts.CustomFactsDir, facts.ProviderDir)
+	for name, timeout := range conf.Config.Facts.Timeouts {
+		if err := registry.SetTimeout(name, timeout); err != nil {
+			return nil, fmt.Errorf("invalid configuration: %w", err)
+		}
+	}
+	return registry, nil
+}
+
+// selectedCollectors returns names of collectors selected by the --collector flag,
+// or by the configuration when the flag is not used.
+func selectedCollectors(cmd *cli.Command) []string {
+	if names := cmd.StringSlice("collector"); len(names) > 0 {
+		return names
+	}
+	return conf.Config.Facts.Collectors
+}
+
+// beforeFactsAction ensures only known collectors have been selected.
+func beforeFactsAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	configureUI(cmd)
+
+	err := checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+
+	registry, err := factsRegistry()
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Config)
+	}
+	for _, name := range selectedCollectors(cmd) {
+		if !slices.Contains(registry.Names(), name) {
+			return ctx, cli.Exit(
+				fmt.Sprintf("unknown collector '%s' (available collectors: %s)", name, strings.Join(registry.Names(), ", ")),
+				exitcode.Usage,
+			)
+		}
+	}
+	return ctx, nil
+}
+
+// factsAction runs the selected facts collectors and prints the facts document
+// to stdout. A failing collector does not prevent the others from reporting facts.
+func factsAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	registry, err := factsRegistry()
+	if err != nil {
+		return cli.Exit(err.Error(), exitcode.Config)
+	}
+	results := registry.Run(ctx, selectedCollectors(cmd))
+
+	data, err := ui.MarshalJSON(facts.Document(results))
+	if err != nil {
+		return cli.Exit(fmt.Errorf("unable to print facts: %w", err), exitcode.Software)
+	}
+	fmt.Println(string(data))
+
+	failed, succeeded := 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case facts.StatusFailed:
+			failed++
+		case facts.StatusOK:
+			succeeded++
+		}
+	}
+	if failed > 0 && succeeded == 0 {
+		slog.Error("All facts collectors failed")
+		return cli.Exit("", exitcode.Err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/subman"
+)
+
+// serverConfig reads and writes the entitlement server configured in rhsm.conf.
+// It is implemented by subman.RHSMClient.
+type serverConfig interface {
+	ServerURL() (string, error)
+	SetServer(rawURL, baseURL string) error
+}
+
+// withServerFailover runs op against servers in their order, until op succeeds
+// or fails for another reason than a connection error. Every server is
+// configured in rhsm.conf before op runs. The URL of the server that served op
+// is returned. When no servers are configured, op runs against the server of
+// rhsm.conf and an empty URL is returned.
+func withServerFailover(config serverConfig, servers []conf.ServerConf, op func() error) (string, error) {
+	if len(servers) == 0 {
+		return "", op()
+	}
+
+	current, err := config.ServerURL()
+	if err != nil {
+		return "", fmt.Errorf("cannot read entitlement server: %w", err)
+	}
+	for _, server := range servers {
+		if server.URL != current {
+			slog.Info("Configuring entitlement server", "server", server.Name, "url", server.URL)
+			if err = config.SetServer(server.URL, server.BaseURL); err != nil {
+				return "", fmt.Errorf("cannot configure entitlement server %s: %w", server.Name, err)
+			}
+			current = server.URL
+		}
+		err = op()
+		if err == nil || !subman.IsConnectionError(err) {
+			return server.URL, err
+		}
+		slog.Warn("Entitlement server cannot be reached", "server", server.Name, "url", server.URL, "err", err)
+	}
+
+	// The server of the highest priority is kept configured, so it is tried
+	// first by services of RHSM
+	if current != servers[0].URL {
+		if restoreErr := config.SetServer(servers[0].URL, servers[0].BaseURL); restoreErr != nil {
+			slog.Warn("Unable to restore entitlement server", "server", servers[0].Name, "err", restoreErr)
+		}
+	}
+	return "", fmt.Errorf("none of %d entitlement servers can be reached: %w", len(servers), err)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+)
+
+// fakeServerConfig records the entitlement servers configured in rhsm.conf.
+type fakeServerConfig struct {
+	url        string
+	configured []string
+}
+
+func (c *fakeServerConfig) ServerURL() (string, error) {
+	return c.url, nil
+}
+
+func (c *fakeServerConfig) SetServer(rawURL, baseURL string) error {
+	c.url = rawURL
+	c.configured = append(c.configured, rawURL)
+	return nil
+}
+
+// unreachable is the error returned by subscription-manager when the server
+// cannot be reached.
+var unreachable = errors.New("Unable to reach the server at capsule.example.com:443/rhsm")
+
+func TestWithServerFailover(t *testing.T) {
+	servers := []conf.ServerConf{
+		{Name: "capsule1", URL: "https://capsule1.example.com/rhsm"},
+		{Name: "capsule2", URL: "https://capsule2.example.com/rhsm"},
+	}
+
+	tests := []struct {
+		description    string
+		servers        []conf.ServerConf
+		current        string
+		results        map[string]error
+		want           string
+		wantError      bool
+		wantConfigured []string
+	}{
+		{
+			description: "no servers",
+			current:     "https://subscription.rhsm.redhat.com/subscription",
+			want:        "",
+		},
+		{
+			description: "first server",
+			servers:     servers,
+			current:     "https://capsule1.example.com/rhsm",
+			want:        "https://capsule1.example.com/rhsm",
+		},
+		{
+			description:    "failover",
+			servers:        servers,
+			current:        "https://capsule1.example.com/rhsm",
+			results:        map[string]error{"https://capsule1.example.com/rhsm": unreachable},
+			want:           "https://capsule2.example.com/rhsm",
+			wantConfigured: []string{"https://capsule2.example.com/rhsm"},
+		},
+		{
+			description:    "no failover on other errors",
+			servers:        servers,
+			current:        "https://capsule2.example.com/rhsm",
+			results:        map[string]error{"https://capsule1.example.com/rhsm": errors.New("invalid credentials")},
+			want:           "https://capsule1.example.com/rhsm",
+			wantError:      true,
+			wantConfigured: []string{"https://capsule1.example.com/rhsm"},
+		},
+		{
+			description: "all servers unreachable",
+			servers:     servers,
+			current:     "https://capsule1.example.com/rhsm",
+			results: map[string]error{
+				"https://capsule1.example.com/rhsm": unreachable,
+				"https://capsule2.example.com/rhsm": unreachable,
+			},
+			want:      "",
+			wantError: true,
+			wantConfigured: []string{
+				"https://capsule2.example.com/rhsm",
+				"https://capsule1.example.com/rhsm",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			config := &fakeServerConfig{url: test.current}
+			got, err := withServerFailover(config, test.servers, func() error {
+				return test.results[config.url]
+			})
+			if (err != nil) != test.wantError {
+				t.Fatalf("got error %v, want error %v", err, test.wantError)
+			}
+			if got != test.want {
+				t.Errorf("got server %q, want %q", got, test.want)
+			}
+			if !cmp.Equal(config.configured, test.wantConfigured) {
+				t.Errorf("%v", cmp.Diff(config.configured, test.wantConfigured))
+			}
+		})
+	}
+}
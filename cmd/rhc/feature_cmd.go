@@ -0,0 +1,419 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+	"github.com/redhatinsights/rhc/pkg/feature"
+	"github.com/redhatinsights/rhc/pkg/feature/prefcache"
+)
+
+// FeatureReconcileResult is structure holding the outcome of 'rhc feature reconcile'.
+// The result could be printed in machine-readable format.
+type FeatureReconcileResult struct {
+	Features []feature.Reconciliation `json:"features"`
+	Warnings []Warning                `json:"warnings"`
+}
+
+// FeatureChangeResult is structure holding the outcome of 'rhc feature enable'
+// and 'rhc feature disable'. The result could be printed in machine-readable
+// format.
+type FeatureChangeResult struct {
+	Features []feature.Reconciliation `json:"features"`
+	Warnings []Warning                `json:"warnings"`
+}
+
+// FeatureInfo is the state of a feature listed by 'rhc feature list'. Selected
+// is the state recorded when the system was connected, or when the feature was
+// enabled or disabled later.
+type FeatureInfo struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Selected    *bool  `json:"selected,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// FeatureListResult is structure holding the output of 'rhc feature list'.
+// The result could be printed in machine-readable format.
+type FeatureListResult struct {
+	Features []FeatureInfo `json:"features"`
+	Warnings []Warning     `json:"warnings"`
+}
+
+// saveFeatureSelection records the features selected in cache, when the system
+// is connected, so 'rhc feature reconcile' can re-apply them.
+func saveFeatureSelection(cache *prefcache.PreferenceCache) error {
+	selection := map[string]bool{}
+	for _, f := range feature.All() {
+		enabled, err := cache.Get(f.ID())
+		if err != nil {
+			return err
+		}
+		selection[f.ID()] = enabled
+	}
+	return feature.SaveState(FeatureStatePath, selection)
+}
+
+// recordFeatureSelection records the current state of features as the selected
+// one, after features of a connected system have been changed on purpose.
+func recordFeatureSelection() {
+	selection := map[string]bool{}
+	for _, f := range feature.All() {
+		enabled, err := f.IsEnabled()
+		if err != nil {
+			slog.Warn("Unable to record feature selection", "feature", f.ID(), "err", err)
+			return
+		}
+		selection[f.ID()] = enabled
+	}
+	if err := feature.SaveState(FeatureStatePath, selection); err != nil {
+		slog.Warn("Unable to record feature selection", "err", err)
+	}
+}
+
+// beforeFeatureReconcileAction ensures the user has supplied a correct `--format` flag.
+func beforeFeatureReconcileAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// featureReconcileAction re-applies the features selected when the system was
+// connected, e.g. re-activates yggdrasil after it has been disabled manually.
+func featureReconcileAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot reconcile features", exitcode.NoPerm)
+	}
+
+	connected, err := subman.HasConsumerCertificate()
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+	if !connected {
+		return cli.Exit("this system is not connected", exitcode.Usage)
+	}
+
+	state, err := feature.LoadState(FeatureStatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return cli.Exit(
+			"no feature selection has been recorded; use 'rhc configure features' to select features",
+			exitcode.NoInput,
+		)
+	}
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.DataErr)
+	}
+
+	consent, err := checkAnalyticsConsent(cmd, state.Features)
+	if err != nil {
+		return err
+	}
+	recordConsent(consent)
+
+	result := FeatureReconcileResult{
+		Features: feature.Reconcile(ctx, feature.All(), state.Features),
+		Warnings: collectWarnings(),
+	}
+	failed := false
+	changes := 0
+	for _, reconciliation := range result.Features {
+		if reconciliation.Error != "" {
+			failed = true
+			slog.Warn("Unable to reconcile feature", "feature", reconciliation.ID, "err", reconciliation.Error)
+		}
+		if reconciliation.Changed {
+			changes++
+		}
+	}
+	if changes > 0 {
+		recordAudit("feature-reconcile", map[string]string{"changes": fmt.Sprint(changes)})
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print reconciliation as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+	} else {
+		for _, reconciliation := range result.Features {
+			wanted := "enabled"
+			if !reconciliation.Wanted {
+				wanted = "disabled"
+			}
+			switch {
+			case reconciliation.Error != "":
+				ui.Printf("%s[%v] %s: %s\n", ui.Indent.Small, ui.Icons.Error, reconciliation.ID, reconciliation.Error)
+			case reconciliation.Changed:
+				ui.Printf("%s[%v] %s: %s again\n", ui.Indent.Small, ui.Icons.Ok, reconciliation.ID, wanted)
+			default:
+				ui.Printf("%s[%v] %s: %s\n", ui.Indent.Small, ui.Icons.Ok, reconciliation.ID, wanted)
+			}
+		}
+		printWarnings(result.Warnings)
+	}
+
+	if failed {
+		return cli.Exit("", exitcode.Err)
+	}
+	return nil
+}
+
+// featureChanges returns the intended state of features, when the feature id
+// is enabled together with the features it requires, or disabled together with
+// the features requiring it.
+func featureChanges(id string, enable bool) map[string]bool {
+	wanted := map[string]bool{}
+	var walk func(id string)
+	walk = func(id string) {
+		if _, ok := wanted[id]; ok {
+			return
+		}
+		wanted[id] = enable
+		f := feature.MustGet(id)
+		dependencies := f.Requires()
+		if !enable {
+			dependencies = f.RequiredBy()
+		}
+		for _, dependency := range dependencies {
+			walk(dependency)
+		}
+	}
+	walk(id)
+	return wanted
+}
+
+// updateFeatureSelection records the features changed successfully as selected,
+// so 'rhc feature reconcile' keeps them in the new state. Selection of other
+// features is kept; when none has been recorded, their current state is used.
+func updateFeatureSelection(results []feature.Reconciliation) error {
+	var selection map[string]bool
+	state, err := feature.LoadState(FeatureStatePath)
+	switch {
+	case err == nil:
+		selection = state.Features
+	case errors.Is(err, os.ErrNotExist):
+		selection = map[string]bool{}
+		for _, f := range feature.All() {
+			enabled, err := f.IsEnabled()
+			if err != nil {
+				return fmt.Errorf("cannot check state of feature %s: %w", f.ID(), err)
+			}
+			selection[f.ID()] = enabled
+		}
+	default:
+		return err
+	}
+	for _, result := range results {
+		if result.Error == "" {
+			selection[result.ID] = result.Wanted
+		}
+	}
+	return feature.SaveState(FeatureStatePath, selection)
+}
+
+// beforeFeatureChangeAction ensures the user has supplied a correct `--format`
+// flag and a single known FEATURE argument.
+func beforeFeatureChangeAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	if cmd.Args().Len() != 1 {
+		return ctx, cli.Exit("this command requires exactly 1 FEATURE argument", exitcode.Usage)
+	}
+	if _, err = feature.Get(cmd.Args().First()); err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.DataErr)
+	}
+	return ctx, nil
+}
+
+// featureEnableAction enables a feature of a connected system, together with
+// the features it requires, and records it as selected.
+func featureEnableAction(ctx context.Context, cmd *cli.Command) error {
+	return featureChangeAction(ctx, cmd, true)
+}
+
+// featureDisableAction disables a feature of a connected system, together with
+// the features requiring it, and records it as not selected.
+func featureDisableAction(ctx context.Context, cmd *cli.Command) error {
+	return featureChangeAction(ctx, cmd, false)
+}
+
+// featureChangeAction enables or disables the feature given as argument.
+func featureChangeAction(ctx context.Context, cmd *cli.Command, enable bool) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit(fmt.Sprintf("non-root user cannot %s features", cmd.Name), exitcode.NoPerm)
+	}
+
+	connected, err := subman.HasConsumerCertificate()
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+	if !connected {
+		return cli.Exit(
+			"this system is not connected; use 'rhc configure features' to select features of the connection",
+			exitcode.Usage,
+		)
+	}
+
+	id := cmd.Args().First()
+	wanted := featureChanges(id, enable)
+	consent, err := checkAnalyticsConsent(cmd, wanted)
+	if err != nil {
+		return err
+	}
+	recordConsent(consent)
+
+	result := FeatureChangeResult{
+		Features: feature.Reconcile(ctx, feature.All(), wanted),
+	}
+	failed := false
+	changes := 0
+	for _, reconciliation := range result.Features {
+		if reconciliation.Error != "" {
+			failed = true
+			slog.Warn("Unable to change feature", "feature", reconciliation.ID, "err", reconciliation.Error)
+		}
+		if reconciliation.Changed {
+			changes++
+		}
+	}
+	if err = updateFeatureSelection(result.Features); err != nil {
+		slog.Warn(fmt.Sprintf("cannot record feature selection: %v", err))
+	}
+	recordAudit("feature-"+cmd.Name, map[string]string{"feature": id, "changes": fmt.Sprint(changes)})
+	result.Warnings = collectWarnings()
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print result as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+	} else {
+		for _, reconciliation := range result.Features {
+			wanted := "enabled"
+			if !reconciliation.Wanted {
+				wanted = "disabled"
+			}
+			switch {
+			case reconciliation.Error != "":
+				ui.Printf("%s[%v] %s: %s\n", ui.Indent.Small, ui.Icons.Error, reconciliation.ID, reconciliation.Error)
+			case reconciliation.Changed:
+				ui.Printf("%s[%v] %s: %s\n", ui.Indent.Small, ui.Icons.Ok, reconciliation.ID, wanted)
+			default:
+				ui.Printf("%s[%v] %s: already %s\n", ui.Indent.Small, ui.Icons.Info, reconciliation.ID, wanted)
+			}
+		}
+		printWarnings(result.Warnings)
+	}
+
+	if failed {
+		return cli.Exit("", exitcode.Err)
+	}
+	return nil
+}
+
+// beforeFeatureListAction ensures the user has supplied a correct `--format` flag.
+func beforeFeatureListAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// featureListAction lists the features with their current and selected state.
+func featureListAction(_ context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	state, err := feature.LoadState(FeatureStatePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn(err.Error())
+	}
+
+	var result FeatureListResult
+	for _, f := range feature.All() {
+		info := FeatureInfo{ID: f.ID(), Description: f.Description()}
+		if info.Enabled, err = f.IsEnabled(); err != nil {
+			info.Error = fmt.Sprintf("cannot check state: %v", err)
+		}
+		if state != nil {
+			if selected, ok := state.Features[f.ID()]; ok {
+				info.Selected = &selected
+			}
+		}
+		result.Features = append(result.Features, info)
+	}
+	result.Warnings = collectWarnings()
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print features as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+
+	rows := [][]string{}
+	for _, info := range result.Features {
+		enabled := "disabled"
+		switch {
+		case info.Error != "":
+			enabled = "unknown"
+		case info.Enabled:
+			enabled = "enabled"
+		}
+		selected := "-"
+		if info.Selected != nil {
+			selected = "disabled"
+			if *info.Selected {
+				selected = "enabled"
+			}
+		}
+		rows = append(rows, []string{info.ID, enabled, selected, info.Description})
+	}
+	ui.PrintTable([]string{"FEATURE", "STATE", "SELECTED", "DESCRIPTION"}, rows)
+	printWarnings(result.Warnings)
+	return nil
+}
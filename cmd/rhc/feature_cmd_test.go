@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFeatureChanges(t *testing.T) {
+	tests := []struct {
+		id     string
+		enable bool
+		want   map[string]bool
+	}{
+		{id: "content", enable: true, want: map[string]bool{"content": true}},
+		{
+			id:     "remote-management",
+			enable: true,
+			want:   map[string]bool{"content": true, "analytics": true, "remote-management": true},
+		},
+		{id: "remote-management", enable: false, want: map[string]bool{"remote-management": false}},
+		{id: "analytics", enable: false, want: map[string]bool{"analytics": false, "remote-management": false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			got := featureChanges(test.id, test.enable)
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
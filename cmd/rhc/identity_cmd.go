@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
+
+	"github.com/redhatinsights/rhc/internal/datacollection"
+	"github.com/redhatinsights/rhc/internal/identity"
+	"github.com/redhatinsights/rhc/internal/remotemanagement"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/internal/util"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// IdentityResult is structure holding the identifiers of the system printed
+// by 'rhc identity show'. Identifiers that cannot be determined are omitted.
+// The result could be printed in machine-readable format.
+type IdentityResult struct {
+	ConsumerUUID      string        `json:"consumer_uuid"`
+	InsightsMachineID string        `json:"insights_machine_id,omitempty"`
+	Organization      string        `json:"organization,omitempty"`
+	YggdrasilClientID string        `json:"yggdrasil_client_id,omitempty"`
+	Owner             *subman.Owner `json:"owner,omitempty"`
+	Warnings          []Warning     `json:"warnings"`
+}
+
+// beforeIdentityShowAction ensures the user has supplied a correct `--format` flag.
+func beforeIdentityShowAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// identityShowAction prints the identifiers of the connected system in RHSM,
+// Red Hat Lightspeed and yggdrasil.
+func identityShowAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	connected, err := subman.HasConsumerCertificate()
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+	if !connected {
+		return cli.Exit("this system is not connected", exitcode.Usage)
+	}
+	cert, err := subman.GetConsumerCertificate()
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.DataErr)
+	}
+
+	result := IdentityResult{ConsumerUUID: cert.Subject.CommonName}
+	if len(cert.Subject.Organization) > 0 {
+		result.Organization = cert.Subject.Organization[0]
+	}
+	if result.InsightsMachineID, err = datacollection.InsightsMachineID(); err != nil {
+		if !errors.Is(err, datacollection.ErrSystemNotFound) {
+			slog.Warn(err.Error())
+		}
+	}
+	if result.YggdrasilClientID, err = remotemanagement.YggdrasilClientID(); err != nil {
+		slog.Warn(err.Error())
+	}
+	if rhsmClient, err := subman.NewRHSMClient(); err != nil {
+		slog.Warn(err.Error())
+	} else if result.Owner, err = rhsmClient.GetOwner(); err != nil {
+		slog.Warn(err.Error())
+	}
+	result.Warnings = collectWarnings()
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print identity as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+
+	unknown := func(value string) string {
+		if value == "" {
+			return "unknown"
+		}
+		return value
+	}
+	ui.Printf("Consumer UUID:       %s\n", result.ConsumerUUID)
+	ui.Printf("Insights machine ID: %s\n", unknown(result.InsightsMachineID))
+	ui.Printf("Organization:        %s\n", unknown(result.Organization))
+	ui.Printf("yggdrasil client ID: %s\n", unknown(result.YggdrasilClientID))
+	if result.Owner == nil {
+		ui.Printf("Subscription owner:  unknown\n")
+	} else if result.Owner.DisplayName != "" {
+		ui.Printf("Subscription owner:  %s (%s)\n", result.Owner.DisplayName, result.Owner.Key)
+	} else {
+		ui.Printf("Subscription owner:  %s\n", result.Owner.Key)
+	}
+	printWarnings(result.Warnings)
+	return nil
+}
+
+// beforeIdentityAction ensures the FILE argument has been passed in, and that
+// the passphrase can be read without a prompt, when --batch is used.
+func beforeIdentityAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	configureUI(cmd)
+
+	if cmd.Args().Len() != 1 {
+		return ctx, cli.Exit(
+			fmt.Sprintf("%s requires FILE argument", getFullCommandName(cmd)),
+			exitcode.Usage,
+		)
+	}
+	if !cmd.IsSet("passphrase-file") {
+		if isBatch(cmd) {
+			return ctx, missingInputsError([]string{"--passphrase-file"})
+		}
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return ctx, cli.Exit(
+				"--passphrase-file is required, when standard input is not a terminal",
+				exitcode.Usage,
+			)
+		}
+	}
+	return ctx, nil
+}
+
+// readPassphrase returns the passphrase of the identity backup, read from
+// --passphrase-file or prompted for. A prompted passphrase of a new backup
+// has to be repeated.
+func readPassphrase(cmd *cli.Command, repeat bool) (string, error) {
+	if cmd.IsSet("passphrase-file") {
+		data, err := os.ReadFile(cmd.String("passphrase-file"))
+		if err != nil {
+			return "", fmt.Errorf("cannot read passphrase: %w", err)
+		}
+		passphrase := strings.TrimRight(string(data), "\r\n")
+		if passphrase == "" {
+			return "", fmt.Errorf("passphrase file %s is empty", cmd.String("passphrase-file"))
+		}
+		return passphrase, nil
+	}
+
+	fmt.Print("Passphrase: ")
+	data, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Printf("\n")
+	if err != nil {
+		return "", fmt.Errorf("unable to read passphrase: %w", err)
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("passphrase cannot be empty")
+	}
+	if repeat {
+		fmt.Print("Repeat passphrase: ")
+		again, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Printf("\n")
+		if err != nil {
+			return "", fmt.Errorf("unable to read passphrase: %w", err)
+		}
+		if string(again) != string(data) {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	fmt.Printf("\n")
+	return string(data), nil
+}
+
+// identityExportAction writes the identity material of the host to an
+// encrypted backup.
+func identityExportAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot export identity", exitcode.NoPerm)
+	}
+
+	files, err := identity.Collect()
+	if errors.Is(err, os.ErrNotExist) {
+		return cli.Exit("this system is not connected, there is no identity to export", exitcode.Usage)
+	}
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+
+	passphrase, err := readPassphrase(cmd, true)
+	if err != nil {
+		return cli.Exit(err, exitcode.Usage)
+	}
+	data, err := identity.Encrypt(files, passphrase)
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.Software)
+	}
+
+	path := cmd.Args().First()
+	// The backup holds the private key of the host
+	if err = util.WriteFileAtomic(path, data, 0600); err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(fmt.Sprintf("cannot write identity backup: %s", err), exitcode.CantCreat)
+	}
+	recordAudit("identity-export", map[string]string{"file": path})
+	slog.Info("Identity exported", "file", path, "files", len(files))
+
+	ui.Printf("%s[%v] Identity of the system exported to %s\n", ui.Indent.Small, ui.Icons.Ok, path)
+	return nil
+}
+
+// identityImportAction restores the identity material of the host from an
+// encrypted backup.
+func identityImportAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot import identity", exitcode.NoPerm)
+	}
+
+	connected, err := subman.HasConsumerCertificate()
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+	if connected {
+		return cli.Exit("this system is already connected, disconnect it before importing identity", exitcode.Usage)
+	}
+
+	path := cmd.Args().First()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("cannot read identity backup: %s", err), exitcode.NoInput)
+	}
+	passphrase, err := readPassphrase(cmd, false)
+	if err != nil {
+		return cli.Exit(err, exitcode.Usage)
+	}
+	files, err := identity.Decrypt(data, passphrase)
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.DataErr)
+	}
+	if err = identity.Restore(files); err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+	recordAudit("identity-import", map[string]string{"file": path})
+	recordConnection(connectionIdentityImport)
+	slog.Info("Identity imported", "file", path, "files", len(files))
+
+	for _, file := range files {
+		ui.Printf("%s[%v] Restored %s\n", ui.Indent.Small, ui.Icons.Ok, file.Path)
+	}
+	ui.Printf("\nIdentity of the system imported from %s\n", path)
+	return nil
+}
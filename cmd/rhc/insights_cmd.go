@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/datacollection"
+	"github.com/redhatinsights/rhc/internal/localization"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// beforeInsightsConfigShowAction ensures the user has supplied a correct `--format` flag.
+func beforeInsightsConfigShowAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// insightsConfigShowAction prints the insights-client options managed by rhc.
+func insightsConfigShowAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	values, err := datacollection.GetInsightsConfigValues()
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+	if proxy, ok := values["proxy"]; ok && proxy != "" {
+		values["proxy"] = datacollection.RedactURL(proxy)
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(values); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print options as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+
+	rows := [][]string{}
+	for _, key := range datacollection.InsightsConfigKeys {
+		value, ok := values[key]
+		if !ok {
+			value = "(default)"
+		}
+		rows = append(rows, []string{key, value})
+	}
+	ui.PrintTable([]string{"OPTION", "VALUE"}, rows)
+	return nil
+}
+
+// beforeInsightsConfigSetAction ensures KEY and VALUE arguments have been passed in,
+// and that the value is valid for the option.
+func beforeInsightsConfigSetAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	configureUI(cmd)
+
+	if cmd.Args().Len() != 2 {
+		return ctx, cli.Exit(
+			fmt.Sprintf("%s requires KEY and VALUE arguments", getFullCommandName(cmd)),
+			exitcode.Usage,
+		)
+	}
+	if _, err := datacollection.NormalizeInsightsConfigValue(cmd.Args().Get(0), cmd.Args().Get(1)); err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// insightsConfigSetAction sets an option in the insights-client configuration file.
+func insightsConfigSetAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot change insights-client configuration", exitcode.NoPerm)
+	}
+
+	key := cmd.Args().Get(0)
+	value := cmd.Args().Get(1)
+	if err := datacollection.SetInsightsConfigValue(key, value); err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+
+	if key == "proxy" && value != "" {
+		value = datacollection.RedactURL(value)
+	}
+	recordAudit("insights-config-set", map[string]string{"key": key, "value": value})
+	slog.Info("insights-client option set", "key", key)
+
+	if value == "" {
+		ui.Printf("%s[%v] Option '%s' removed from %s\n", ui.Indent.Small, ui.Icons.Ok, key, datacollection.InsightsConfigPath)
+	} else {
+		ui.Printf("%s[%v] Option '%s' set in %s\n", ui.Indent.Small, ui.Icons.Ok, key, datacollection.InsightsConfigPath)
+	}
+	return nil
+}
+
+// beforeInsightsSchedulingAction ensures no arguments have been passed in.
+func beforeInsightsSchedulingAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	configureUI(cmd)
+
+	err := checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// insightsEnableSchedulingAction enables the scheduled collection and upload of
+// insights-client.
+func insightsEnableSchedulingAction(ctx context.Context, cmd *cli.Command) error {
+	return setInsightsScheduling(cmd, true)
+}
+
+// insightsDisableSchedulingAction disables the scheduled collection and upload
+// of insights-client.
+func insightsDisableSchedulingAction(ctx context.Context, cmd *cli.Command) error {
+	return setInsightsScheduling(cmd, false)
+}
+
+// setInsightsScheduling enables or disables the scheduled collection of
+// insights-client. The change is recorded in the audit log.
+func setInsightsScheduling(cmd *cli.Command, enabled bool) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot change scheduling of insights-client", exitcode.NoPerm)
+	}
+
+	state := "disabled"
+	if enabled {
+		state = "enabled"
+	}
+	err := ui.Spinner(func() error {
+		return datacollection.SetInsightsScheduling(enabled)
+	}, ui.Indent.Small, "Changing scheduling of insights-client...")
+	if err != nil {
+		slog.Error(fmt.Sprintf("cannot change scheduling of insights-client: %v", err))
+		var unsupportedErr *datacollection.UnsupportedFlagError
+		if errors.As(err, &unsupportedErr) {
+			return cli.Exit(err, exitcode.Unavailable)
+		}
+		return cli.Exit(fmt.Sprintf("cannot change scheduling of insights-client: %v", err), exitcode.Software)
+	}
+
+	recordAudit("insights-scheduling", map[string]string{"state": state})
+	slog.Info("insights-client scheduling changed", "state", state)
+	ui.Printf("%s[%v] Scheduled collection of insights-client %s\n", ui.Indent.Small, ui.Icons.Ok, state)
+	return nil
+}
+
+// InsightsLastUploadResult is structure holding the result of 'rhc insights
+// last-upload'. The result could be printed in machine-readable format.
+type InsightsLastUploadResult struct {
+	LastUpload  *time.Time                      `json:"last_upload,omitempty"`
+	Archive     *datacollection.InsightsArchive `json:"archive,omitempty"`
+	ExtractedTo string                          `json:"extracted_to,omitempty"`
+	Warnings    []Warning                       `json:"warnings"`
+}
+
+// beforeInsightsLastUploadAction ensures the user has supplied a correct `--format` flag.
+func beforeInsightsLastUploadAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	if cmd.IsSet("extract") && cmd.String("extract") == "" {
+		return ctx, cli.Exit("--extract requires a directory", exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// insightsLastUploadAction prints when insights-client uploaded the last archive,
+// and the archive kept by insights-client. With --extract, the kept archive is
+// extracted, so its content can be reviewed.
+func insightsLastUploadAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot inspect archives of insights-client", exitcode.NoPerm)
+	}
+
+	result := InsightsLastUploadResult{Warnings: collectWarnings()}
+	lastUpload, err := datacollection.InsightsClientLastUpload()
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+	if !lastUpload.IsZero() {
+		result.LastUpload = &lastUpload
+	}
+	result.Archive, err = datacollection.LastInsightsArchive()
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+
+	if dir := cmd.String("extract"); dir != "" {
+		if result.Archive == nil {
+			return cli.Exit(
+				"no archive is kept by insights-client; enable 'keep_archive' with 'rhc insights config set keep_archive true'",
+				exitcode.NoInput,
+			)
+		}
+		if err = datacollection.ExtractInsightsArchive(result.Archive.Path, dir); err != nil {
+			slog.Error(err.Error())
+			return cli.Exit(err, exitcode.CantCreat)
+		}
+		result.ExtractedTo = dir
+		recordAudit("insights-archive-extract", map[string]string{"archive": result.Archive.Path, "directory": dir})
+		slog.Info("Archive extracted", "archive", result.Archive.Path, "directory", dir)
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print last upload as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+
+	locale := localization.GetLocale()
+	if result.LastUpload == nil {
+		ui.Printf("%s[ ] No archive has been uploaded yet\n", ui.Indent.Small)
+	} else {
+		ui.Printf(
+			"%s[%v] Last upload %s (%s)\n",
+			ui.Indent.Small, ui.Icons.Info,
+			result.LastUpload.Local().Format(time.DateTime),
+			localization.FormatTimeAgo(locale, time.Since(*result.LastUpload)),
+		)
+	}
+	if result.Archive == nil {
+		ui.Printf(
+			"%s[ ] No archive is kept; enable it with 'rhc insights config set keep_archive true'\n",
+			ui.Indent.Small,
+		)
+	} else {
+		ui.Printf(
+			"%s[%v] Kept archive %s (%s)\n",
+			ui.Indent.Small, ui.Icons.Info,
+			result.Archive.Path, localization.FormatSize(locale, result.Archive.Size),
+		)
+	}
+	if result.ExtractedTo != "" {
+		ui.Printf("%s[%v] Archive extracted to %s\n", ui.Indent.Small, ui.Icons.Ok, result.ExtractedTo)
+	}
+	printWarnings(result.Warnings)
+	return nil
+}
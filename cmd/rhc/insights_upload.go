@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/datacollection"
+	"github.com/redhatinsights/rhc/internal/systemd"
+)
+
+// insightsUploadServiceName is the name of the transient service retrying the
+// initial upload of insights-client.
+const insightsUploadServiceName = "rhc-insights-upload.service"
+
+// insightsUploadRetryDelays are the delays before the attempts of the transient
+// service to upload the initial archive.
+var insightsUploadRetryDelays = []time.Duration{
+	time.Minute, 5 * time.Minute, 15 * time.Minute, time.Hour, 4 * time.Hour,
+}
+
+// scheduleInsightsUpload starts the transient service retrying the initial
+// upload of insights-client in the background, with a growing delay between
+// the attempts.
+func scheduleInsightsUpload() error {
+	conn, err := systemd.NewConnectionContext(context.Background(), systemd.ConnectionTypeSystem)
+	if err != nil {
+		return fmt.Errorf("cannot connect to systemd: %v", err)
+	}
+	defer conn.Close()
+	slog.Debug("Starting " + insightsUploadServiceName)
+	return conn.StartTransientService(
+		insightsUploadServiceName,
+		"Retry the initial upload of Red Hat Lightspeed",
+		datacollection.InsightsUploadRetryCommand(insightsUploadRetryDelays),
+	)
+}
+
+// cancelInsightsUpload stops the transient service retrying the initial upload,
+// when it is running.
+func cancelInsightsUpload() error {
+	conn, err := systemd.NewConnectionContext(context.Background(), systemd.ConnectionTypeSystem)
+	if err != nil {
+		return fmt.Errorf("cannot connect to systemd: %v", err)
+	}
+	defer conn.Close()
+	slog.Debug("Stopping " + insightsUploadServiceName)
+	if err = conn.StopUnit(insightsUploadServiceName, false); err != nil && !errors.Is(err, systemd.ErrUnitNotFound) {
+		return err
+	}
+	return nil
+}
@@ -5,23 +5,36 @@ import (
 	"log/slog"
 	"os"
 	"text/tabwriter"
-	"time"
 
 	"github.com/urfave/cli/v3"
 
 	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/localization"
 	"github.com/redhatinsights/rhc/internal/ui"
 	"github.com/redhatinsights/rhc/pkg/exitcode"
 )
 
-// showTimeDuration shows a table with the duration of each sub-action
-func showTimeDuration(durations map[string]time.Duration) {
+// showTimeline shows a table with the start offset, duration and result of
+// each sub-action in the order they were run. The durations are formatted in
+// the language of the locale; the JSON document holds them in milliseconds.
+func showTimeline(timeline Timeline) {
+	for _, step := range timeline {
+		slog.Debug("Finished step", "step", step.Step, "start", step.start, "duration", step.duration, "result", step.Result)
+	}
 	if conf.Config.LogLevel <= slog.LevelDebug {
 		fmt.Println()
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		_, _ = fmt.Fprintln(w, "STEP\tDURATION\t")
-		for step, duration := range durations {
-			_, _ = fmt.Fprintf(w, "%v\t%v\t\n", step, duration.Truncate(time.Millisecond))
+		_, _ = fmt.Fprintln(w, "STEP\tSTART\tDURATION\tRESULT\t")
+		locale := localization.GetLocale()
+		for _, step := range timeline {
+			_, _ = fmt.Fprintf(
+				w,
+				"%v\t+%v\t%v\t%v\t\n",
+				step.Step,
+				localization.FormatDuration(locale, step.start),
+				localization.FormatDuration(locale, step.duration),
+				step.Result,
+			)
 		}
 		_ = w.Flush()
 	}
@@ -35,10 +48,25 @@ func showErrorMessages(action string, errorMessages map[string]string) error {
 
 	fmt.Println()
 	fmt.Printf("The following errors were encountered during %s:\n\n", action)
+	// Long error messages are wrapped to fit the terminal width
+	stepWidth := len("STEP")
+	for step := range errorMessages {
+		stepWidth = max(stepWidth, len(step))
+	}
+	errWidth := 0
+	if width := ui.TerminalWidth(); width > 0 {
+		errWidth = width - stepWidth - 2
+	}
+
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	_, _ = fmt.Fprintln(w, "STEP\tERROR\t")
 	for step, errMsg := range errorMessages {
-		_, _ = fmt.Fprintf(w, "%v\t%v\n", step, errMsg)
+		for i, line := range ui.Wrap(errMsg, errWidth) {
+			if i > 0 {
+				step = ""
+			}
+			_, _ = fmt.Fprintf(w, "%v\t%v\n", step, line)
+		}
 	}
 	_ = w.Flush()
 	fmt.Println()
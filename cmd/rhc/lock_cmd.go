@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// DisconnectLock is the content of the protection lock file. While the file
+// exists, 'rhc disconnect' refuses to disconnect the system without --ignore-lock.
+type DisconnectLock struct {
+	LockedAt time.Time `json:"locked_at"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// readDisconnectLock returns the protection lock, or nil when the system is not locked.
+func readDisconnectLock(path string) (*DisconnectLock, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read protection lock: %w", err)
+	}
+	var lock DisconnectLock
+	if err = json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("cannot parse protection lock %s: %w", path, err)
+	}
+	return &lock, nil
+}
+
+// disconnectLockError returns the message refusing the disconnection of a
+// system protected by the lock at path, or an empty string when the system may
+// be disconnected. A lock file that cannot be read or parsed protects the
+// system too, so the protection does not fail open. force overrides the lock;
+// flag is the name of the flag setting it, suggested in the message.
+func disconnectLockError(path string, force bool, flag string) string {
+	lock, err := readDisconnectLock(path)
+	if lock == nil && err == nil {
+		return ""
+	}
+	if force {
+		slog.Warn("Disconnecting locked system, protection overridden by --" + flag)
+		return ""
+	}
+	if err != nil {
+		return fmt.Sprintf("system is treated as locked against disconnection (%v); run 'rhc unlock' or use --%s", err, flag)
+	}
+	if lock.Reason != "" {
+		return fmt.Sprintf("system is locked against disconnection (reason: %s); run 'rhc unlock' or use --%s", lock.Reason, flag)
+	}
+	return fmt.Sprintf("system is locked against disconnection; run 'rhc unlock' or use --%s", flag)
+}
+
+// writeDisconnectLock creates the protection lock file.
+func writeDisconnectLock(path string, lock DisconnectLock) error {
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot create protection lock: %w", err)
+	}
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("cannot create protection lock: %w", err)
+	}
+	return nil
+}
+
+// beforeLockAction ensures no arguments have been passed in.
+func beforeLockAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	configureUI(cmd)
+
+	err := checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// lockAction protects the system against disconnection.
+func lockAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot lock system", exitcode.NoPerm)
+	}
+
+	lock := DisconnectLock{LockedAt: time.Now().UTC(), Reason: cmd.String("reason")}
+	if err := writeDisconnectLock(DisconnectLockPath, lock); err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.CantCreat)
+	}
+
+	slog.Info("System locked against disconnection", "reason", lock.Reason)
+	ui.Printf("%s[%v] System is protected against disconnection\n", ui.Indent.Small, ui.Icons.Ok)
+	ui.Printf("\nRun 'rhc unlock' to remove the protection, or 'rhc disconnect --ignore-lock' to override it.\n")
+	return nil
+}
+
+// unlockAction removes the protection against disconnection.
+func unlockAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot unlock system", exitcode.NoPerm)
+	}
+
+	err := os.Remove(DisconnectLockPath)
+	if errors.Is(err, os.ErrNotExist) {
+		ui.Printf("%s[%v] System is not locked\n", ui.Indent.Small, ui.Icons.Info)
+		return nil
+	}
+	if err != nil {
+		slog.Error("cannot remove protection lock", "err", err)
+		return cli.Exit(fmt.Errorf("cannot remove protection lock: %w", err), exitcode.IOErr)
+	}
+
+	slog.Info("System unlocked")
+	ui.Printf("%s[%v] System is no longer protected against disconnection\n", ui.Indent.Small, ui.Icons.Ok)
+	return nil
+}
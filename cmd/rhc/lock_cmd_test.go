@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDisconnectLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rhc", "disconnect.lock")
+
+	lock, err := readDisconnectLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock != nil {
+		t.Fatalf("got lock %+v, want nil", lock)
+	}
+
+	want := DisconnectLock{LockedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Reason: "production database"}
+	if err = writeDisconnectLock(path, want); err != nil {
+		t.Fatal(err)
+	}
+	lock, err = readDisconnectLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock == nil || *lock != want {
+		t.Errorf("got lock %+v, want %+v", lock, want)
+	}
+}
+
+func TestDisconnectLockError(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "corrupt.lock")
+	if err := os.WriteFile(corrupt, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	locked := filepath.Join(dir, "disconnect.lock")
+	if err := writeDisconnectLock(locked, DisconnectLock{Reason: "production database"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		description string
+		path        string
+		force       bool
+		wantLocked  bool
+	}{
+		{description: "not locked", path: filepath.Join(dir, "missing.lock")},
+		{description: "locked", path: locked, wantLocked: true},
+		{description: "locked, forced", path: locked, force: true},
+		{description: "corrupt lock", path: corrupt, wantLocked: true},
+		{description: "corrupt lock, forced", path: corrupt, force: true},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := disconnectLockError(test.path, test.force, "force")
+			if (got != "") != test.wantLocked {
+				t.Errorf("disconnectLockError() = %q, want locked %v", got, test.wantLocked)
+			}
+		})
+	}
+}
@@ -4,14 +4,27 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/redhatinsights/rhc/internal/conf"
+	httpapi "github.com/redhatinsights/rhc/internal/http"
+	"github.com/redhatinsights/rhc/internal/logging"
 	"github.com/redhatinsights/rhc/internal/ui"
 )
 
+// Identical warnings and errors are logged at most once per interval, e.g.
+// during retries of an unreachable server
+const logRateLimitInterval = time.Minute
+
 var (
 	logFile *os.File = nil
+	// remoteLog is the connection to the remote syslog collector, when logs are forwarded
+	remoteLog net.Conn = nil
+	// logRateLimit deduplicates log records; its summaries are written on close
+	logRateLimit *logging.RateLimitHandler = nil
 )
 
 // ensureLogDirectory ensures that the log directory exists and is writable by the current user.
@@ -83,15 +96,53 @@ func configureFileLogging(logLevel slog.Leveler) {
 		Level: logLevel,
 	})
 
-	logger := slog.New(h)
+	logRateLimit = logging.NewRateLimitHandler(configureRemoteLogging(h), logRateLimitInterval)
+	logger := slog.New(logRateLimit)
 	slog.SetDefault(logger)
 
 	// write empty line to separate log entries between runs of the program
 	_, _ = fmt.Fprintln(logFile)
 }
 
-// closeLogFile syncs and then closes the log file.
+// configureRemoteLogging connects to the remote syslog collector configured by
+// 'log-remote', and returns a handler forwarding records passed to h to it. When
+// the collector cannot be reached, h is returned, so rhc works without it.
+func configureRemoteLogging(h slog.Handler) slog.Handler {
+	if conf.Config.LogRemote == "" {
+		return h
+	}
+	dialer := &net.Dialer{}
+	if !httpapi.Bind.IsZero() {
+		var err error
+		if dialer, err = httpapi.Bind.Dialer(); err != nil {
+			slog.New(h).Warn("Unable to forward logs to remote syslog", "url", conf.Config.LogRemote, "err", err)
+			return h
+		}
+	}
+	dialer.Timeout = 5 * time.Second
+	conn, framed, err := logging.DialSyslog(conf.Config.LogRemote, dialer)
+	if err != nil {
+		slog.New(h).Warn("Unable to forward logs to remote syslog", "url", conf.Config.LogRemote, "err", err)
+		return h
+	}
+	remoteLog = conn
+
+	// An unknown hostname is sent as the nil value "-"
+	hostname, _ := os.Hostname()
+	return logging.NewSyslogHandler(h, conn, framed, hostname, "rhc", os.Getpid())
+}
+
+// closeLogFile writes the summaries of suppressed log records, syncs and then
+// closes the log file, and closes the connection to the remote syslog collector.
 func closeLogFile() error {
+	if logRateLimit != nil {
+		_ = logRateLimit.Close()
+		logRateLimit = nil
+	}
+	if remoteLog != nil {
+		_ = remoteLog.Close()
+		remoteLog = nil
+	}
 	if logFile == nil {
 		return nil
 	}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/remotemanagement"
+	"github.com/redhatinsights/rhc/internal/supportbundle"
+	"github.com/redhatinsights/rhc/internal/systemd"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// supportBundleDir is the directory the support bundle is written to, unless
+// --output is used.
+const supportBundleDir = "/var/tmp"
+
+// supportBundleFiles are the files of rhc and the services it manages included
+// in the support bundle, by their name in the bundle.
+var supportBundleFiles = []struct {
+	name string
+	path string
+}{
+	{name: "rhc/state/features.json", path: FeatureStatePath},
+	{name: "rhc/state/connection.json", path: ConnectionPath},
+	{name: "rhc/state/maintenance.json", path: MaintenancePath},
+	{name: "rhc/state/queue.json", path: QueuePath},
+	{name: "rhc/state/disconnect.lock", path: DisconnectLockPath},
+	{name: "rhsm/rhsm.conf", path: "/etc/rhsm/rhsm.conf"},
+	{name: "rhsm/rhsm.log", path: "/var/log/rhsm/rhsm.log"},
+	{name: "insights-client/insights-client.conf", path: "/etc/insights-client/insights-client.conf"},
+	{name: "insights-client/insights-client.log", path: "/var/log/insights-client/insights-client.log"},
+	{name: "yggdrasil/config.toml", path: remotemanagement.YggdrasilConfigPath},
+}
+
+// supportBundleUnits are the units whose journal entries are included in the
+// support bundle.
+var supportBundleUnits = []string{
+	"rhsm.service", "rhsmcertd.service", "insights-client.service", "yggdrasil.service", checkinServiceName,
+}
+
+// LogsResult is structure holding the outcome of 'rhc logs'. The result could
+// be printed in machine-readable format.
+type LogsResult struct {
+	Path string `json:"path"`
+	// Files are the names of the files in the support bundle.
+	Files []string `json:"files"`
+	// Skipped are the files and units that could not be included.
+	Skipped  []string  `json:"skipped,omitempty"`
+	Warnings []Warning `json:"warnings"`
+}
+
+// formatJournal returns the journal entries as lines of text.
+func formatJournal(entries []systemd.JournalEntry) []byte {
+	var builder strings.Builder
+	for _, entry := range entries {
+		_, _ = fmt.Fprintf(
+			&builder,
+			"%s %s[%d]: %s\n",
+			entry.Time.UTC().Format(time.RFC3339),
+			entry.Unit,
+			entry.Priority,
+			entry.Message,
+		)
+	}
+	return []byte(builder.String())
+}
+
+// effectiveConfig returns the configuration of rhc merged from the
+// configuration file at path and its drop-in files.
+func effectiveConfig(path string) ([]byte, error) {
+	layers, err := conf.LoadLayers(path, ConfigDropInDir)
+	if err != nil {
+		return nil, err
+	}
+	merged := conf.Merge(layers)
+	if merged == nil {
+		return nil, nil
+	}
+	data, err := merged.ToTomlString()
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode configuration: %w", err)
+	}
+	return []byte(data), nil
+}
+
+// gatherSupportBundle reads the logs of rhc, the journal of the services it
+// manages since since, and the effective configuration. Files and units that
+// cannot be read are reported as skipped.
+func gatherSupportBundle(configPath string, since, until time.Time) ([]supportbundle.File, []string) {
+	var files []supportbundle.File
+	var skipped []string
+
+	logPaths, _ := filepath.Glob(filepath.Join(LogDir, "*.log*"))
+	for _, path := range logPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			slog.Debug("Unable to read log file", "path", path, "err", err)
+			skipped = append(skipped, path)
+			continue
+		}
+		files = append(files, supportbundle.File{Name: "rhc/" + filepath.Base(path), Data: data})
+	}
+
+	if data, err := effectiveConfig(configPath); err != nil {
+		slog.Debug("Unable to read configuration", "err", err)
+		skipped = append(skipped, configPath)
+	} else if data != nil {
+		files = append(files, supportbundle.File{Name: "rhc/config.toml", Data: data})
+	}
+
+	for _, file := range supportBundleFiles {
+		data, err := os.ReadFile(file.path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				slog.Debug("Unable to read file", "path", file.path, "err", err)
+				skipped = append(skipped, file.path)
+			}
+			continue
+		}
+		files = append(files, supportbundle.File{Name: file.name, Data: data})
+	}
+
+	for _, unit := range supportBundleUnits {
+		entries, err := systemd.GetJournalEntries([]string{unit}, since, until)
+		if err != nil {
+			slog.Debug("Unable to read journal", "unit", unit, "err", err)
+			skipped = append(skipped, unit)
+			continue
+		}
+		if len(entries) > 0 {
+			files = append(files, supportbundle.File{Name: "journal/" + unit + ".log", Data: formatJournal(entries)})
+		}
+	}
+	return files, skipped
+}
+
+// beforeLogsAction ensures the user has supplied a correct `--format` flag and
+// a valid --since time.
+func beforeLogsAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	if _, err = parseEventTime(cmd.String("since"), time.Now()); err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// logsAction gathers the logs of rhc, subscription-manager, insights-client and
+// yggdrasil, and the effective configuration into a support bundle with secrets
+// redacted.
+func logsAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot gather logs", exitcode.NoPerm)
+	}
+
+	now := time.Now()
+	since, _ := parseEventTime(cmd.String("since"), now)
+	path := cmd.String("output")
+	if path == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "localhost"
+		}
+		path = filepath.Join(supportBundleDir, fmt.Sprintf("rhc-logs-%s-%s.tar.gz", hostname, now.Format("20060102-150405")))
+	}
+
+	files, skipped := gatherSupportBundle(cmd.Root().String("config"), since, now)
+	if err := supportbundle.Write(path, files, now); err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.CantCreat)
+	}
+	slog.Info("Support bundle written", "path", path, "files", len(files))
+	recordAudit("logs", map[string]string{"path": path})
+
+	result := LogsResult{Path: path, Files: []string{}, Skipped: skipped, Warnings: collectWarnings()}
+	for _, file := range files {
+		result.Files = append(result.Files, file.Name)
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err := ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print result as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+
+	ui.Printf("%s[%v] Support bundle with %d file(s) written to %s\n", ui.Indent.Small, ui.Icons.Ok, len(result.Files), path)
+	for _, name := range result.Skipped {
+		ui.Printf("%s[%v] %s could not be read\n", ui.Indent.Small, ui.Icons.Warning, name)
+	}
+	ui.Printf("\nPasswords, tokens and activation keys have been redacted. Review the bundle before attaching it to a support case.\n")
+	printWarnings(result.Warnings)
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/systemd"
+)
+
+func TestFormatJournal(t *testing.T) {
+	entries := []systemd.JournalEntry{
+		{
+			Time:     time.Date(2025, 1, 31, 2, 0, 0, 0, time.UTC),
+			Unit:     "yggdrasil.service",
+			Message:  "connected to broker",
+			Priority: 6,
+		},
+		{
+			Time:     time.Date(2025, 1, 31, 2, 0, 5, 0, time.UTC),
+			Unit:     "rhsm.service",
+			Message:  "unable to refresh entitlement certificates",
+			Priority: 3,
+		},
+	}
+	want := "2025-01-31T02:00:00Z yggdrasil.service[6]: connected to broker\n" +
+		"2025-01-31T02:00:05Z rhsm.service[3]: unable to refresh entitlement certificates\n"
+	if got := string(formatJournal(entries)); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -5,20 +5,24 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
-	"os/signal"
 	"strings"
-	"syscall"
 
-	"github.com/pelletier/go-toml"
 	altsrc "github.com/urfave/cli-altsrc/v3"
-	altsrctoml "github.com/urfave/cli-altsrc/v3/toml"
 	docs "github.com/urfave/cli-docs/v3"
 	"github.com/urfave/cli/v3"
 
+	"github.com/redhatinsights/rhc/internal/canonical_facts"
 	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/configbundle"
+	"github.com/redhatinsights/rhc/internal/datacollection"
+	httpapi "github.com/redhatinsights/rhc/internal/http"
+	"github.com/redhatinsights/rhc/internal/localization"
+	"github.com/redhatinsights/rhc/internal/retry"
+	"github.com/redhatinsights/rhc/internal/subman"
 	"github.com/redhatinsights/rhc/internal/ui"
 	"github.com/redhatinsights/rhc/pkg/exitcode"
 	"github.com/redhatinsights/rhc/pkg/feature"
+	"github.com/redhatinsights/rhc/pkg/interactive"
 	"github.com/redhatinsights/rhc/pkg/version"
 )
 
@@ -48,43 +52,91 @@ func mainAction(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
-// configureUI sets up the global UI state by calling ui.ConfigureOutput
-// with appropriate parameters.
-func configureUI(cmd *cli.Command) {
-	ui.ConfigureOutput(
+// uiSettings computes the UI settings of the current invocation. The settings
+// are computed only once and stored in the metadata of the root command, so
+// every Before and Action function shares the same view of the output.
+func uiSettings(cmd *cli.Command) ui.Settings {
+	if settings, ok := cmd.Root().Metadata[uiSettingsKey].(ui.Settings); ok {
+		return settings
+	}
+	settings := ui.Settings{
 		// Rich output (animations) is only enabled when all are true:
 		// - we're printing in human-friendly format,
 		// - stdout is an interactive console.
-		!cmd.IsSet("format") && ui.IsInteractive(),
+		Rich: !cmd.IsSet("format") && ui.IsInteractive(),
 		// Colors are only enabled when all are true:
 		// output is rich,
 		// --no-color/$NO_COLOR are not set.
-		!cmd.IsSet("no-color"),
+		Colored: !cmd.IsSet("no-color"),
 		// Machine-readable output is enabled when all are true:
 		// - we're printing in JSON or other parseable format.
-		cmd.IsSet("format"),
-	)
+		MachineReadable: cmd.IsSet("format"),
+		// Long messages are truncated or wrapped to the terminal width,
+		// unless --no-truncate is set.
+		NoTruncate: cmd.Bool("no-truncate"),
+		// --json-compact and --json-pretty override 'ui.json'.
+		JSONStyle: ui.JSONStyle(conf.Config.UI.JSON),
+	}
+	if cmd.Bool("json-compact") {
+		settings.JSONStyle = ui.JSONCompact
+	} else if cmd.Bool("json-pretty") {
+		settings.JSONStyle = ui.JSONPretty
+	}
+	theme, err := ui.NewTheme(conf.Config.UI.Theme, conf.Config.UI.Colors, conf.Config.UI.Symbols)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("invalid UI theme: %v, using default theme", err))
+	}
+	settings.Theme = theme
+	cmd.Root().Metadata[uiSettingsKey] = settings
+	return settings
+}
+
+// configureUI sets up the global UI state by calling ui.ConfigureOutput
+// with the settings of the current invocation. Machine-readable output is not
+// localized: messages of services and of executed tools are requested in
+// localization.MachineLocale.
+func configureUI(cmd *cli.Command) {
+	ui.ConfigureOutput(uiSettings(cmd))
+	interactive.Configure(interactive.Settings{AssumeYes: cmd.Bool("assume-yes"), Batch: isBatch(cmd)})
+	if ui.IsOutputMachineReadable() {
+		localization.SetLocale(localization.MachineLocale)
+		if err := os.Setenv("LC_ALL", localization.MachineLocale); err != nil {
+			slog.Debug("Unable to set locale of executed tools", "err", err)
+		}
+	}
 }
 
 // beforeAction is triggered before other actions are triggered
 func beforeAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	// Other commands would change the system despite --dry-run
+	if cmd.Bool("dry-run") && cmd.Args().Present() {
+		if err := checkDryRunCommand(cmd.Args().First()); err != nil {
+			return ctx, cli.Exit(err.Error(), exitcode.Usage)
+		}
+	}
+
+	if cmd.Bool("json-compact") && cmd.Bool("json-pretty") {
+		return ctx, cli.Exit("--json-compact and --json-pretty cannot be used together", exitcode.Usage)
+	}
+
 	// check if --log-level was set via command line
 	var logLevelSrc string
 	if cmd.IsSet(cliLogLevel) {
 		logLevelSrc = "command line"
 	}
 
-	// validate file is parseable TOML
+	// validate files are parseable TOML; values of drop-in files override
+	// values of the configuration file
 	configPath := cmd.String("config")
-	if configPath != "" {
-		if _, err := toml.LoadFile(configPath); err != nil {
-			return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
-		}
+	layers, err := conf.LoadLayers(configPath, ConfigDropInDir)
+	if err != nil {
+		return ctx, err
 	}
+	configTree := conf.Merge(layers)
 
 	// check if log-level was set via config file (command line has precedence)
 	if logLevelSrc == "" && cmd.IsSet(cliLogLevel) {
-		logLevelSrc = fmt.Sprintf("config file: '%s'", cmd.String("config"))
+		logLevelSrc = fmt.Sprintf("config file: '%s' or drop-in files in '%s'", cmd.String("config"), ConfigDropInDir)
 	}
 
 	conf.Config = conf.Conf{
@@ -92,6 +144,181 @@ func beforeAction(ctx context.Context, cmd *cli.Command) (context.Context, error
 		KeyFile:  cmd.String(cliKeyFile),
 	}
 
+	uiConf, err := loadUIConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.UI = uiConf
+
+	factsConf, err := loadFactsConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.Facts = factsConf
+
+	proxyConf, err := loadProxyConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	proxyConf, err = applyProxyFlags(cmd, proxyConf)
+	if err != nil {
+		return ctx, cli.Exit(err, exitcode.Usage)
+	}
+	// The password file is usually readable by root only, commands of other
+	// users connect to the proxy without the password
+	if proxyConf, err = readProxyPassword(proxyConf); err != nil {
+		slog.Warn(err.Error())
+	}
+	conf.Config.Proxy = proxyConf
+	configureProxy(proxyConf)
+
+	networkConf, err := loadNetworkConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.Network = networkConf
+	httpapi.Bind = httpapi.BindConfig{
+		Interface:     networkConf.Interface,
+		SourceAddress: networkConf.SourceAddress,
+	}
+
+	lowBandwidth, err := loadLowBandwidth(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.LowBandwidth = lowBandwidth || cmd.Bool("low-bandwidth")
+	httpapi.LowBandwidth = conf.Config.LowBandwidth
+	datacollection.SkipInitialUpload = conf.Config.LowBandwidth
+
+	conf.Config.ServerURL, conf.Config.BaseURL, err = loadServerURL(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+
+	syspurposeConf, err := loadSyspurposeConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.Syspurpose = syspurposeConf
+
+	insightsConf, err := loadInsightsConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.Insights = insightsConf
+	if insightsConf.GatewayURL != "" {
+		datacollection.InsightsAPIURL = insightsConf.GatewayURL
+	}
+
+	apiConf, err := loadAPIConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.API = apiConf
+	// The service account is authenticated by the Red Hat API, not by the
+	// certificate-authenticated endpoint or a gateway
+	if apiConf.ClientID != "" {
+		datacollection.InsightsAPIURL = apiConf.URL
+	}
+
+	checkinConf, err := loadCheckinConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	// Check-ins are less frequent on constrained links, unless configured
+	if conf.Config.LowBandwidth && (configTree == nil || !configTree.Has("checkin.interval")) {
+		checkinConf.Interval = lowBandwidthCheckinInterval
+	}
+	conf.Config.Checkin = checkinConf
+
+	queueConf, err := loadQueueConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.Queue = queueConf
+
+	retryConf, err := loadRetryConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.Retry = retryConf
+	retryPolicy := retry.Policy{
+		Attempts: retryConf.Attempts,
+		Delay:    retryConf.Delay,
+		MaxDelay: retryConf.MaxDelay,
+	}
+	subman.Retry = retryPolicy
+	datacollection.Retry = retryPolicy
+
+	timeoutsConf, err := loadTimeoutsConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.Timeouts = timeoutsConf
+
+	format, err := loadFormat(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.Format = format
+
+	logRemote, err := loadLogRemote(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.LogRemote = logRemote
+
+	auditConf, err := loadAuditConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.Audit = auditConf
+
+	notifyConf, err := loadNotifyConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.Notify = notifyConf
+
+	consentConf, err := loadConsentConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.Consent = consentConf
+
+	profiles, err := loadProfilesConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.Profiles = profiles
+
+	servers, err := loadServersConf(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.Servers = servers
+
+	if cmd.Bool("log-http") {
+		httpapi.LogHTTP = os.Stderr
+	}
+
+	tags, err := loadTags(configTree, ConfigDropInDir)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid configuration: %w", err)
+	}
+	conf.Config.Tags = tags
+
+	assetTag, err := loadAssetTag(configTree)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	conf.Config.AssetTag = assetTag
+	canonical_facts.AssetTag = assetTag
+	if assetTag != "" {
+		// The asset tag overrides a tag of the same name
+		conf.Config.Tags[assetTagName] = assetTag
+	}
+
 	logLevelStr := cmd.String(cliLogLevel)
 	if err := conf.Config.LogLevel.UnmarshalText([]byte(logLevelStr)); err != nil {
 		slog.Error(fmt.Sprintf("invalid log level '%s' set via %s", logLevelStr, logLevelSrc))
@@ -115,19 +342,22 @@ func beforeAction(ctx context.Context, cmd *cli.Command) (context.Context, error
 		}
 	}
 
-	// Set up standard output preference: colors, icons, etc.
-	configureUI(cmd)
+	// Standard output preference (colors, icons, etc.) is set up by the
+	// Before function of the sub-command, because only the sub-command knows
+	// whether machine-readable output was requested.
 
 	return ctx, nil
 }
 
 // afterAction is triggered after other actions are triggered
 func afterAction(ctx context.Context, cmd *cli.Command) error {
+	runCleanup()
 	return closeLogFile()
 }
 
 // exitErrHandler is triggered when an action returns a cli.ExitCoder (e.g cli.Exit("error", 1))
 func exitErrHandler(ctx context.Context, cmd *cli.Command, err error) {
+	runCleanup()
 	_ = closeLogFile()
 
 	// continue with default ExitErrHandler behavior
@@ -161,6 +391,10 @@ func main() {
 	}
 
 	configSource := altsrc.NewStringPtrSourcer(&configFilePath)
+	dropInPaths, err := conf.DropInPaths(ConfigDropInDir)
+	if err != nil {
+		slog.Warn("Unable to list drop-in files", "dir", ConfigDropInDir, "err", err)
+	}
 
 	app.Flags = []cli.Flag{
 		&cli.BoolFlag{
@@ -177,6 +411,52 @@ func main() {
 			Value:   false,
 			Sources: cli.EnvVars("NO_COLOR"),
 		},
+		&cli.BoolFlag{
+			Name:  "no-truncate",
+			Usage: "do not truncate or wrap long messages to the terminal width",
+		},
+		&cli.BoolFlag{
+			Name:  "json-compact",
+			Usage: "print machine-readable output as single-line JSON documents",
+		},
+		&cli.BoolFlag{
+			Name:  "json-pretty",
+			Usage: "print machine-readable output as indented JSON documents",
+		},
+		&cli.BoolFlag{
+			Name:    "batch",
+			Aliases: []string{"no-prompt"},
+			Usage:   "never prompt for input, fail listing the missing inputs instead",
+		},
+		&cli.BoolFlag{
+			Name:    "assume-yes",
+			Aliases: []string{"y", "yes"},
+			Usage:   "confirm disconnect, reconnect and decommission without asking",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: fmt.Sprintf("print the operations %s would execute, without executing them", strings.Join(dryRunCommands, ", ")),
+		},
+		&cli.BoolFlag{
+			Name:  "low-bandwidth",
+			Usage: "minimize the traffic to Red Hat services on constrained links",
+		},
+		&cli.StringFlag{
+			Name:  "proxy",
+			Usage: "connect to Red Hat services through the proxy at `URL`, overriding proxy.url",
+		},
+		&cli.StringFlag{
+			Name:  "proxy-user",
+			Usage: "authenticate to the proxy as `USER`, overriding proxy.user",
+		},
+		&cli.StringFlag{
+			Name:  "proxy-password",
+			Usage: "authenticate to the proxy with `PASSWORD`, or with the password read from the standard input when PASSWORD is \"-\", overriding proxy.password and proxy.password-file",
+		},
+		&cli.BoolFlag{
+			Name:  "log-http",
+			Usage: "print HTTP requests and TLS sessions of Red Hat endpoints to standard error",
+		},
 		&cli.StringFlag{
 			Name:        "config",
 			Hidden:      true,
@@ -186,29 +466,23 @@ func main() {
 			Usage:       "Read config values from `FILE`",
 		},
 		&cli.StringFlag{
-			Name:   cliCertFile,
-			Hidden: true,
-			Usage:  "Use `FILE` as the client certificate",
-			Sources: cli.NewValueSourceChain(
-				altsrctoml.TOML(cliCertFile, configSource),
-			),
+			Name:    cliCertFile,
+			Hidden:  true,
+			Usage:   "Use `FILE` as the client certificate",
+			Sources: configValueSources(cliCertFile, configSource, dropInPaths),
 		},
 		&cli.StringFlag{
-			Name:   cliKeyFile,
-			Hidden: true,
-			Usage:  "Use `FILE` as the client's private key",
-			Sources: cli.NewValueSourceChain(
-				altsrctoml.TOML(cliKeyFile, configSource),
-			),
+			Name:    cliKeyFile,
+			Hidden:  true,
+			Usage:   "Use `FILE` as the client's private key",
+			Sources: configValueSources(cliKeyFile, configSource, dropInPaths),
 		},
 		&cli.StringFlag{
-			Name:   cliLogLevel,
-			Value:  "info",
-			Hidden: true,
-			Usage:  "Set the logging output level to `LEVEL`",
-			Sources: cli.NewValueSourceChain(
-				altsrctoml.TOML(cliLogLevel, configSource),
-			),
+			Name:    cliLogLevel,
+			Value:   "info",
+			Hidden:  true,
+			Usage:   "Set the logging output level to `LEVEL`",
+			Sources: configValueSources(cliLogLevel, configSource, dropInPaths),
 		},
 	}
 
@@ -223,9 +497,14 @@ func main() {
 				},
 				&cli.StringFlag{
 					Name:    "password",
-					Usage:   "register with `PASSWORD`",
+					Usage:   "register with `PASSWORD`; \"-\" reads it from standard input",
 					Aliases: []string{"p"},
 				},
+				&cli.StringFlag{
+					Name:      "password-file",
+					Usage:     "register with the password in `FILE`",
+					TakesFile: true,
+				},
 				&cli.StringFlag{
 					Name:    "organization",
 					Usage:   "register with `ID`",
@@ -236,11 +515,65 @@ func main() {
 					Usage:   "register with `KEY`",
 					Aliases: []string{"a"},
 				},
+				&cli.StringFlag{
+					Name:      "activation-key-file",
+					Usage:     "register with the activation keys listed in `FILE`, one per line",
+					TakesFile: true,
+				},
+				&cli.BoolFlag{
+					Name:  "auto",
+					Usage: "register with the instance identity of AWS, Azure or Google Cloud instead of credentials",
+				},
 				&cli.StringSliceFlag{
 					Name:    "content-template",
 					Usage:   "register with `CONTENT_TEMPLATE`",
 					Aliases: []string{"c"},
 				},
+				&cli.StringFlag{
+					Name:  "role",
+					Usage: "set the system purpose role to `ROLE` (e.g. \"Red Hat Enterprise Linux Server\"), overriding syspurpose.role",
+				},
+				&cli.StringFlag{
+					Name:  "sla",
+					Usage: "set the system purpose service level to `SLA` (e.g. \"Premium\"), overriding syspurpose.sla",
+				},
+				&cli.StringFlag{
+					Name:  "usage",
+					Usage: "set the system purpose usage to `USAGE` (e.g. \"Production\"), overriding syspurpose.usage",
+				},
+				&cli.StringFlag{
+					Name:  "display-name",
+					Usage: "display the system under `NAME` in Inventory instead of its hostname (requires the analytics feature)",
+				},
+				&cli.StringFlag{
+					Name:  "group",
+					Usage: "add the system to the inventory group `GROUP` (requires the analytics feature)",
+				},
+				&cli.StringFlag{
+					Name:  "notify-url",
+					Usage: "post the result as JSON document to the webhook at `URL`",
+				},
+				&cli.StringFlag{
+					Name:  "server-url",
+					Usage: "register with the entitlement server at `URL`, e.g. of a Satellite, overriding server-url; its CA certificate is installed, unless it is trusted already",
+				},
+				&cli.StringFlag{
+					Name:  "base-url",
+					Usage: "download content from `URL`, overriding base-url",
+				},
+				&cli.StringFlag{
+					Name:      "ca-cert",
+					Usage:     "trust the CA certificate in `FILE`, e.g. of a TLS-intercepting proxy",
+					TakesFile: true,
+				},
+				&cli.StringFlag{
+					Name:  "ca-fingerprint",
+					Usage: "trust the CA certificate of --server-url with the SHA-256 fingerprint `FINGERPRINT`; without it, the certificate has to be confirmed on a terminal",
+				},
+				&cli.BoolFlag{
+					Name:  "check-content",
+					Usage: "verify access to content of an enabled repository after connection",
+				},
 				&cli.StringSliceFlag{
 					Name:    "enable-feature",
 					Usage:   fmt.Sprintf("enable `FEATURE` during connection (allowed values: %s)", featureIDs),
@@ -251,6 +584,26 @@ func main() {
 					Usage:   fmt.Sprintf("disable `FEATURE` during connection (allowed values: %s)", featureIDs),
 					Aliases: []string{"d"},
 				},
+				&cli.BoolFlag{
+					Name:  "from-bootstrap",
+					Usage: fmt.Sprintf("connect with the organization, activation keys, features, tags and server of %s, and shred the file afterwards", BootstrapPath),
+				},
+				&cli.BoolFlag{
+					Name:  "accept-notice",
+					Usage: "accept the data collection notice configured in the [consent] section without a prompt",
+				},
+				&cli.BoolFlag{
+					Name:  "check-only",
+					Usage: "verify that the endpoints of Red Hat services can be reached, without connecting the system",
+				},
+				&cli.BoolFlag{
+					Name:  "sign-result",
+					Usage: "sign the machine-readable result with the identity key of the system",
+				},
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "give up a step of the connection not finished within `DURATION`, overriding the '[timeouts]' section (\"0\" disables the limit)",
+				},
 				&cli.StringFlag{
 					Name:    "format",
 					Usage:   "prints output of connection in machine-readable format (supported formats: \"json\")",
@@ -266,6 +619,39 @@ func main() {
 		{
 			Name: "disconnect",
 			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "notify-url",
+					Usage: "post the result as JSON document to the webhook at `URL`",
+				},
+				&cli.BoolFlag{
+					Name:    "force",
+					Aliases: []string{"offline"},
+					Usage:   "remove the local registration of targets, whose servers cannot be reached",
+				},
+				&cli.BoolFlag{
+					Name:  "ignore-lock",
+					Usage: "disconnect the system even when it is locked by 'rhc lock'",
+				},
+				&cli.StringSliceFlag{
+					Name:  "only",
+					Usage: fmt.Sprintf("disconnect only `TARGET`, repeated for each target (allowed values: %s)", strings.Join(disconnectTargets, ", ")),
+				},
+				&cli.BoolFlag{
+					Name:  "keep-rhsm",
+					Usage: "keep the registration with Red Hat Subscription Management",
+				},
+				&cli.BoolFlag{
+					Name:  "keep-insights",
+					Usage: "keep the connection to Red Hat Lightspeed (formerly Insights)",
+				},
+				&cli.BoolFlag{
+					Name:  "keep-yggdrasil",
+					Usage: "keep the yggdrasil service active",
+				},
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "give up a step of the disconnection not finished within `DURATION`, overriding the '[timeouts]' section (\"0\" disables the limit)",
+				},
 				&cli.StringFlag{
 					Name:    "format",
 					Usage:   "prints output of disconnection in machine-readable format (supported formats: \"json\")",
@@ -278,6 +664,484 @@ func main() {
 			Before:      beforeDisconnectAction,
 			Action:      disconnectAction,
 		},
+		{
+			Name: "reconnect",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "username",
+					Usage:   "register with `USERNAME`",
+					Aliases: []string{"u"},
+				},
+				&cli.StringFlag{
+					Name:    "password",
+					Usage:   "register with `PASSWORD`",
+					Aliases: []string{"p"},
+				},
+				&cli.StringFlag{
+					Name:    "organization",
+					Usage:   "register with `ID`",
+					Aliases: []string{"o"},
+				},
+				&cli.StringSliceFlag{
+					Name:    "activation-key",
+					Usage:   "register with `KEY`",
+					Aliases: []string{"a"},
+				},
+				&cli.BoolFlag{
+					Name:  "force",
+					Usage: "disconnect the system even when it is locked by 'rhc lock'; the local registration is not removed",
+				},
+				&cli.StringFlag{
+					Name:    "format",
+					Usage:   "prints output of disconnection and connection in machine-readable format (supported formats: \"json\")",
+					Aliases: []string{"f"},
+				},
+			},
+			Usage:       "Disconnects the system and connects it again",
+			UsageText:   fmt.Sprintf("%v reconnect [command options]", app.Name),
+			Description: fmt.Sprintf("The reconnect command disconnects the system and connects it again with the same features. A system connected with an activation key is connected with the same organization and activation keys, recorded in %s; for a system connected with a username and password, the password is prompted for. Credentials given by flags are used instead. The JSON document holds the results of the disconnect and connect phases.", ActivationKeysPath),
+			Before:      beforeReconnectAction,
+			Action:      reconnectAction,
+		},
+		{
+			Name: "switch",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "profile",
+					Usage: "connect the system using the profile `NAME`",
+				},
+				&cli.BoolFlag{
+					Name:  "force",
+					Usage: "disconnect the system even when it is locked by 'rhc lock'; the local registration is not removed",
+				},
+			},
+			Usage:       "Reconnect the system to another environment",
+			UsageText:   fmt.Sprintf("%v switch --profile NAME", app.Name),
+			Description: "The switch command disconnects the system, configures the entitlement server of the profile NAME, and connects the system with the organization, activation keys and features of the profile, e.g. to move the system from a staging Satellite to the production console. Profiles are configured in the [profiles.NAME] sections of the configuration file; a profile has to set 'server-url', 'organization' and 'activation-keys'.",
+			Before:      beforeSwitchAction,
+			Action:      switchAction,
+		},
+		{
+			Name: "decommission",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "force",
+					Usage: "decommission the system even when it is locked by 'rhc lock'",
+				},
+				&cli.StringFlag{
+					Name:    "format",
+					Usage:   "prints the final report in machine-readable format (supported formats: \"json\")",
+					Aliases: []string{"f"},
+				},
+			},
+			Usage:       "Decommissions the system at the end of its lifecycle",
+			UsageText:   fmt.Sprintf("%v decommission", app.Name),
+			Description: "The decommission command disconnects the system from Red Hat, removes it from Inventory, and deletes the local identity, facts and audit state of the system. When the system cannot be disconnected, the local state is kept, so the command can be run again. The decommission has to be confirmed on a terminal; without a terminal, or with a machine-readable format, it is refused, unless --assume-yes is used.",
+			Before:      beforeDecommissionAction,
+			Action:      decommissionAction,
+		},
+		{
+			Name: "clean",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "force",
+					Usage: "remove the local state even when the system is connected",
+				},
+				&cli.StringFlag{
+					Name:    "format",
+					Usage:   "prints the result in machine-readable format (supported formats: \"json\")",
+					Aliases: []string{"f"},
+				},
+			},
+			Usage:       "Remove local state of rhc",
+			UsageText:   fmt.Sprintf("%v clean [--dry-run]", app.Name),
+			Description: "The clean command removes the state written by rhc, the cached identities of the system, the canonical facts, the check-in timer and the proxy drop-ins of services, e.g. when they got corrupted. The registration with Red Hat Subscription Management, the disconnect lock, the audit log and the configuration are kept. A connected system, or a system whose connection status cannot be checked, is refused unless --force is used; run 'rhc disconnect' first.",
+			Before:      beforeCleanAction,
+			Action:      cleanAction,
+		},
+		{
+			Name:      "audit",
+			Usage:     "Inspect the audit log of changes made by rhc",
+			UsageText: fmt.Sprintf("%v audit COMMAND", app.Name),
+			Commands: []*cli.Command{
+				{
+					Name: "verify",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints the result in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:       "Verify signatures of the audit log",
+					UsageText:   fmt.Sprintf("%v audit verify", app.Name),
+					Description: fmt.Sprintf("The verify command checks signatures of the entries of %s, and reports modified, removed and unsigned entries. Entries are signed when 'sign' is enabled in the [audit] section of the configuration file.", AuditLogPath),
+					Before:      beforeAuditVerifyAction,
+					Action:      auditVerifyAction,
+				},
+			},
+		},
+		{
+			Name:      "config",
+			Usage:     "Manage configuration of rhc",
+			UsageText: fmt.Sprintf("%v config COMMAND", app.Name),
+			Commands: []*cli.Command{
+				{
+					Name: "fetch",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "signature-url",
+							Usage: "download the detached signature of the bundle from `URL` (default: URL of the bundle with the '.sig' suffix)",
+						},
+						&cli.StringFlag{
+							Name:      "keyring",
+							Value:     configbundle.KeyringPath,
+							TakesFile: true,
+							Usage:     "verify the signature with the keys of `FILE`",
+						},
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints the result in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:       "Install a signed bundle of configuration files",
+					UsageText:   fmt.Sprintf("%v config fetch URL", app.Name),
+					Description: fmt.Sprintf("The fetch command downloads a bundle of configuration files from URL, verifies its detached GPG signature with the trusted keys, and installs the files into %s. The bundle is a tar archive, optionally compressed by gzip, of '.toml' files; files with invalid configuration are rejected. The signature does not protect against an older signed bundle served again, so bundles should be fetched over HTTPS from a trusted server.", ConfigDropInDir),
+					Before:      beforeConfigFetchAction,
+					Action:      configFetchAction,
+				},
+				{
+					Name: "get",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints the value in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:       "Print a value of the configuration",
+					UsageText:   fmt.Sprintf("%v config get KEY", app.Name),
+					Description: "The get command prints the value of KEY in the configuration file and its drop-in files, e.g. 'proxy.url'. Items of lists are separated by commas.",
+					Before:      beforeConfigKeyAction,
+					Action:      configGetAction,
+				},
+				{
+					Name:        "set",
+					Usage:       "Set a value of the configuration",
+					UsageText:   fmt.Sprintf("%v config set KEY VALUE", app.Name),
+					Description: fmt.Sprintf("The set command sets KEY to VALUE in the drop-in file %s, which is not replaced by package upgrades and takes precedence over the configuration file and other drop-in files. Items of lists are separated by commas. The change is rejected when the resulting configuration is invalid. Secrets are not stored: instead of proxy.password, the path to a file holding the password is set by proxy.password-file.", LocalConfigPath),
+					Before:      beforeConfigKeyAction,
+					Action:      configSetAction,
+				},
+				{
+					Name:        "unset",
+					Usage:       "Remove a value of the configuration",
+					UsageText:   fmt.Sprintf("%v config unset KEY", app.Name),
+					Description: fmt.Sprintf("The unset command removes KEY from the drop-in file %s, so the value of the configuration file or other drop-in files applies again.", LocalConfigPath),
+					Before:      beforeConfigKeyAction,
+					Action:      configUnsetAction,
+				},
+				{
+					Name: "migrate",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints the result in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:       "Migrate the configuration of earlier versions of rhc and rhcd",
+					UsageText:   fmt.Sprintf("%v config migrate [FILE]", app.Name),
+					Description: fmt.Sprintf("The migrate command converts the configuration file shared by earlier versions of rhc and rhcd, FILE (default: %s), into the drop-in file %s. Values still read by rhc, e.g. 'cert-file', 'key-file' and 'log-level', are migrated; settings of rhcd, e.g. 'broker', are reported as warnings, as they belong to the configuration of yggdrasil now. The legacy file is kept. The change is rejected when the resulting configuration is invalid.", LegacyConfigPath, MigratedConfigPath),
+					Before:      beforeConfigMigrateAction,
+					Action:      configMigrateAction,
+				},
+				{
+					Name: "list",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints the configuration in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:       "Print the configuration",
+					UsageText:   fmt.Sprintf("%v config list", app.Name),
+					Description: fmt.Sprintf("The list command prints the values of the configuration file and the drop-in files in %s, together with the file setting every value.", ConfigDropInDir),
+					Before:      beforeConfigListAction,
+					Action:      configListAction,
+				},
+			},
+		},
+		{
+			Name: "doctor",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "format",
+					Usage:   "prints the checks in machine-readable format (supported formats: \"json\")",
+					Aliases: []string{"f"},
+				},
+				&cli.BoolFlag{
+					Name:  "permissions",
+					Usage: "print which operations rhc relies on the current user can perform (e.g. reading the consumer certificate, managing systemd units), without changing the system, instead of the checks",
+				},
+				&cli.BoolFlag{
+					Name:  "convert",
+					Usage: "run the checks of conversion of the system to RHEL by convert2rhel (third-party repositories, kernel, Secure Boot, pre-conversion analysis), instead of the checks",
+				},
+			},
+			Usage:       "Check the system for common problems",
+			UsageText:   fmt.Sprintf("%v doctor", app.Name),
+			Description: "The doctor command runs checks of the system, and prints hints how to fix the problems found. It exits with an error, when any check fails.",
+			Before:      beforeDoctorAction,
+			Action:      doctorAction,
+		},
+		{
+			Name: "self-test",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "format",
+					Usage:   "prints the checks in machine-readable format (supported formats: \"json\")",
+					Aliases: []string{"f"},
+				},
+			},
+			Usage:       "Verify the installation of rhc",
+			UsageText:   fmt.Sprintf("%v self-test", app.Name),
+			Description: "The self-test command verifies the runtime assumptions of rhc after it has been installed, e.g. in %post of a kickstart or when an image is built. It exits with an error, when any check fails.",
+			Before:      beforeSelfTestAction,
+			Action:      selfTestAction,
+		},
+		{
+			Name: "logs",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:      "output",
+					Usage:     fmt.Sprintf("write the support bundle to `FILE` (default: %s/rhc-logs-HOSTNAME-TIME.tar.gz)", supportBundleDir),
+					TakesFile: true,
+				},
+				&cli.StringFlag{
+					Name:  "since",
+					Usage: "include journal entries since `TIME` (e.g. \"24h\", \"02:00\", \"2025-01-31 02:00\")",
+					Value: "72h",
+				},
+				&cli.StringFlag{
+					Name:    "format",
+					Usage:   "prints the result in machine-readable format (supported formats: \"json\")",
+					Aliases: []string{"f"},
+				},
+			},
+			Usage:       "Gather logs into a support bundle",
+			UsageText:   fmt.Sprintf("%v logs [--output FILE]", app.Name),
+			Description: "The logs command gathers the logs of rhc, the journal of subscription-manager, insights-client and yggdrasil, their configuration files and the effective configuration of rhc into a single tarball, which can be attached to a support case. Passwords, tokens and activation keys are redacted.",
+			Before:      beforeLogsAction,
+			Action:      logsAction,
+		},
+		{
+			Name: "preflight",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "format",
+					Usage:   "prints the result in machine-readable format (supported formats: \"json\")",
+					Aliases: []string{"f"},
+				},
+			},
+			Usage:       "Verify connectivity to Red Hat services",
+			UsageText:   fmt.Sprintf("%v preflight", app.Name),
+			Description: "The preflight command verifies, without connecting the system, that the entitlement server, the content delivery network, Red Hat Lightspeed (formerly Insights) and the Hybrid Cloud Console can be reached. It exits with an error, when any endpoint cannot be reached.",
+			Before:      beforePreflightAction,
+			Action:      preflightAction,
+		},
+		{
+			Name:      "feature",
+			Usage:     "Manage features of the connected system",
+			UsageText: fmt.Sprintf("%v feature COMMAND", app.Name),
+			Commands: []*cli.Command{
+				{
+					Name: "reconcile",
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "accept-notice",
+							Usage: "accept the data collection notice configured in the [consent] section without a prompt, when analytics is enabled",
+						},
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints the result in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:       "Re-apply the selected features",
+					UsageText:   fmt.Sprintf("%v feature reconcile", app.Name),
+					Description: fmt.Sprintf("The reconcile command enables and disables features, so they match the selection recorded in %s when the system was connected, or when features were configured later, e.g. it re-activates remote management after yggdrasil has been disabled manually.", FeatureStatePath),
+					Before:      beforeFeatureReconcileAction,
+					Action:      featureReconcileAction,
+				},
+				{
+					Name: "list",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints the features in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:       "List features and their state",
+					UsageText:   fmt.Sprintf("%v feature list", app.Name),
+					Description: fmt.Sprintf("The list command prints the current state of every feature, and the state selected when the system was connected, or when the feature was enabled or disabled later, as recorded in %s.", FeatureStatePath),
+					Before:      beforeFeatureListAction,
+					Action:      featureListAction,
+				},
+				{
+					Name: "enable",
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "accept-notice",
+							Usage: "accept the data collection notice configured in the [consent] section without a prompt, when analytics is enabled",
+						},
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints the result in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:       "Enable a feature of the connected system",
+					UsageText:   fmt.Sprintf("%v feature enable FEATURE", app.Name),
+					ArgsUsage:   fmt.Sprintf("FEATURE (allowed values: %s)", featureIDs),
+					Description: "The enable command enables a feature of the connected system, together with the features it requires, e.g. it starts remote management. The feature is recorded as selected, so 'rhc feature reconcile' keeps it enabled.",
+					Before:      beforeFeatureChangeAction,
+					Action:      featureEnableAction,
+				},
+				{
+					Name: "disable",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints the result in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:       "Disable a feature of the connected system",
+					UsageText:   fmt.Sprintf("%v feature disable FEATURE", app.Name),
+					ArgsUsage:   fmt.Sprintf("FEATURE (allowed values: %s)", featureIDs),
+					Description: "The disable command disables a feature of the connected system, together with the features requiring it, e.g. it turns analytics off. The feature is recorded as not selected, so 'rhc feature reconcile' keeps it disabled.",
+					Before:      beforeFeatureChangeAction,
+					Action:      featureDisableAction,
+				},
+			},
+		},
+		{
+			Name:      "identity",
+			Usage:     "Show, back up and restore the identity of the system",
+			UsageText: fmt.Sprintf("%v identity COMMAND", app.Name),
+			Commands: []*cli.Command{
+				{
+					Name: "show",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints identity in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:       "Show the identifiers of the system",
+					UsageText:   fmt.Sprintf("%v identity show", app.Name),
+					Description: "The show command prints the identifiers of the connected system: the RHSM consumer UUID, the Red Hat Lightspeed machine ID, the organization, the client ID of yggdrasil and the owner of the subscriptions. Identifiers that cannot be determined are printed as unknown.",
+					Before:      beforeIdentityShowAction,
+					Action:      identityShowAction,
+				},
+				{
+					Name: "export",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "passphrase-file",
+							Usage: "read the passphrase encrypting the backup from `FILE`",
+						},
+					},
+					Usage:       "Export the identity of the system to an encrypted backup",
+					UsageText:   fmt.Sprintf("%v identity export FILE", app.Name),
+					Description: "The export command writes the consumer certificate and key, and the Red Hat Lightspeed identifiers of the system to FILE, encrypted with a passphrase. After a reinstall, the backup can be imported to keep the history of the system in the console.",
+					Before:      beforeIdentityAction,
+					Action:      identityExportAction,
+				},
+				{
+					Name: "import",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "passphrase-file",
+							Usage: "read the passphrase decrypting the backup from `FILE`",
+						},
+					},
+					Usage:       "Import the identity of the system from an encrypted backup",
+					UsageText:   fmt.Sprintf("%v identity import FILE", app.Name),
+					Description: "The import command restores the identity of the system from FILE created by 'identity export'. The system has to be disconnected.",
+					Before:      beforeIdentityAction,
+					Action:      identityImportAction,
+				},
+			},
+		},
+		{
+			Name:      "maintenance",
+			Usage:     "Pause check-ins during planned outages",
+			UsageText: fmt.Sprintf("%v maintenance COMMAND", app.Name),
+			Commands: []*cli.Command{
+				{
+					Name: "on",
+					Flags: []cli.Flag{
+						&cli.DurationFlag{
+							Name:  "duration",
+							Usage: "end the maintenance window and resume check-ins after `DURATION` (e.g. \"2h\")",
+						},
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints the result in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:       "Start a maintenance window",
+					UsageText:   fmt.Sprintf("%v maintenance on --duration DURATION", app.Name),
+					Description: "The on command pauses scheduled check-ins until the end of the maintenance window, and marks the host as in maintenance in Inventory, so staleness alerts of the host can be suppressed.",
+					Before:      beforeMaintenanceAction,
+					Action:      maintenanceAction,
+				},
+				{
+					Name: "off",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints the result in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:       "End the maintenance window",
+					UsageText:   fmt.Sprintf("%v maintenance off", app.Name),
+					Description: "The off command resumes scheduled check-ins, and clears the maintenance facts of the host in Inventory.",
+					Before:      beforeMaintenanceAction,
+					Action:      maintenanceAction,
+				},
+			},
+		},
+		{
+			Name: "lock",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "reason",
+					Usage: "record `REASON` of the protection",
+				},
+			},
+			Usage:       "Protects the system against disconnection",
+			UsageText:   fmt.Sprintf("%v lock [--reason REASON]", app.Name),
+			Description: "The lock command protects the system against accidental disconnection. The disconnect command refuses to disconnect a locked system unless --ignore-lock is used.",
+			Before:      beforeLockAction,
+			Action:      lockAction,
+		},
+		{
+			Name:        "unlock",
+			Usage:       "Removes the protection against disconnection",
+			UsageText:   fmt.Sprintf("%v unlock", app.Name),
+			Description: "The unlock command removes the protection created by the lock command.",
+			Before:      beforeLockAction,
+			Action:      unlockAction,
+		},
 		{
 			Name:        "configure",
 			Usage:       "Configure system features",
@@ -304,7 +1168,13 @@ func main() {
 							Action: featuresStatusAction,
 						},
 						{
-							Name:      "enable",
+							Name: "enable",
+							Flags: []cli.Flag{
+								&cli.BoolFlag{
+									Name:  "accept-notice",
+									Usage: "accept the data collection notice configured in the [consent] section without a prompt, when analytics is enabled",
+								},
+							},
 							Usage:     "Enable features",
 							ArgsUsage: fmt.Sprintf("FEATURE [FEATURE...] (allowed values: %s)", featureIDs),
 							Before:    beforeFeaturesEnableAction,
@@ -329,6 +1199,48 @@ func main() {
 			Description: "The canonical-facts command prints data that uniquely identifies the system in the Red Hat inventory service. Use only as directed for debugging purposes.",
 			Action:      canonicalFactAction,
 		},
+		{
+			Name: "checkin",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "sync-hostname",
+					Usage: "set the current hostname as the name of the system in RHSM and the display name in Inventory, even if it did not change",
+				},
+				&cli.BoolFlag{
+					Name:  "daemon",
+					Usage: "keep running and check in periodically following interval, jitter and splay of the [checkin] configuration",
+				},
+				&cli.BoolFlag{
+					Name:   "scheduled",
+					Usage:  "delay the check-in by a random jitter (used by rhc-checkin.service)",
+					Hidden: true,
+				},
+				&cli.StringFlag{
+					Name:    "format",
+					Usage:   "prints output of check-in in machine-readable format (supported formats: \"json\")",
+					Aliases: []string{"f"},
+				},
+			},
+			Usage:       "Checks the system in with Red Hat",
+			UsageText:   fmt.Sprintf("%v checkin", app.Name),
+			Description: "The checkin command updates the last check-in time of the system in Red Hat Lightspeed (formerly Insights), and propagates a changed hostname to Red Hat services. Connected systems are checked in periodically by the rhc-checkin.timer.",
+			Before:      beforeCheckinAction,
+			Action:      checkinAction,
+		},
+		{
+			Name: "facts",
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:  "collector",
+					Usage: "run only the facts collector `NAME` (available collectors: canonical, hardware, network, cloud, custom, providers)",
+				},
+			},
+			Usage:       "Prints facts about the system",
+			UsageText:   fmt.Sprintf("%v facts [command options]", app.Name),
+			Description: "The facts command collects facts about the system and prints them as a JSON document. Collectors run with their own timeouts; a failing collector does not prevent the others from reporting facts. The status of every collector is reported in the metadata section of the document.",
+			Before:      beforeFactsAction,
+			Action:      factsAction,
+		},
 		{
 			Name: "status",
 			Flags: []cli.Flag{
@@ -337,6 +1249,36 @@ func main() {
 					Usage:   "prints status in machine-readable format (supported formats: \"json\")",
 					Aliases: []string{"f"},
 				},
+				&cli.BoolFlag{
+					Name:  "upgrade-readiness",
+					Usage: "summarize pre-upgrade findings of Red Hat Lightspeed advisor blocking in-place upgrade, and fail when any blocks it",
+				},
+				&cli.BoolFlag{
+					Name:    "verbose",
+					Usage:   "print links to the records of the system in Red Hat web consoles",
+					Aliases: []string{"v"},
+				},
+				&cli.StringFlag{
+					Name:  "since",
+					Usage: "print events of rhc, yggdrasil and insights-client since `TIME` (e.g. \"2h\", \"02:00\", \"2025-01-31 02:00\")",
+				},
+				&cli.StringFlag{
+					Name:  "until",
+					Usage: "print events until `TIME` (default: now)",
+				},
+				&cli.BoolFlag{
+					Name:  "watch",
+					Usage: "refresh the status continuously until interrupted; emit a JSON line on every change in machine-readable format",
+				},
+				&cli.DurationFlag{
+					Name:  "interval",
+					Usage: "refresh the status every `DURATION` with --watch",
+					Value: defaultWatchInterval,
+				},
+				&cli.BoolFlag{
+					Name:  "exit-zero",
+					Usage: "exit with zero status when the system is not connected or not ready for upgrade; errors still fail",
+				},
 			},
 			Usage:       "Prints status of the system's connection to Red Hat",
 			UsageText:   fmt.Sprintf("%v status", app.Name),
@@ -344,6 +1286,159 @@ func main() {
 			Before:      beforeStatusAction,
 			Action:      statusAction,
 		},
+		{
+			Name: "whoami",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "format",
+					Usage:   "prints identity in machine-readable format (supported formats: \"json\")",
+					Aliases: []string{"f"},
+				},
+			},
+			Usage:       "Prints identity of the connected system",
+			UsageText:   fmt.Sprintf("%v whoami", app.Name),
+			Description: fmt.Sprintf("The whoami command prints the consumer UUID and organization of the connected system, and how it was connected: with an activation key, with a username and password, by cloud auto-registration, or by importing a backed up identity. The connection method is recorded in %s by 'rhc connect' and 'rhc identity import'.", ConnectionPath),
+			Before:      beforeWhoamiAction,
+			Action:      whoamiAction,
+		},
+		{
+			Name:        "release",
+			Usage:       "Manage the release version of the system",
+			UsageText:   fmt.Sprintf("%v release COMMAND", app.Name),
+			Description: "The release command manages the minor release version the system is locked to. Content is then provided only for this release.",
+			Commands: []*cli.Command{
+				{
+					Name: "show",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints release version in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:     "Show the release version",
+					UsageText: fmt.Sprintf("%v release show", app.Name),
+					Before:    beforeReleaseShowAction,
+					Action:    releaseShowAction,
+				},
+				{
+					Name:      "set",
+					Usage:     "Lock the system to a release version",
+					UsageText: fmt.Sprintf("%v release set VERSION", app.Name),
+					Before:    beforeReleaseSetAction,
+					Action:    releaseSetAction,
+				},
+				{
+					Name:      "unset",
+					Usage:     "Remove the release version lock",
+					UsageText: fmt.Sprintf("%v release unset", app.Name),
+					Before:    beforeReleaseUnsetAction,
+					Action:    releaseUnsetAction,
+				},
+			},
+		},
+		{
+			Name:      "repos",
+			Usage:     "Inspect repositories provided by Red Hat",
+			UsageText: fmt.Sprintf("%v repos COMMAND", app.Name),
+			Commands: []*cli.Command{
+				{
+					Name: "list",
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "enabled",
+							Usage: "list only enabled repositories",
+						},
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints list of repositories in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:       "List available repositories",
+					UsageText:   fmt.Sprintf("%v repos list [--enabled]", app.Name),
+					Description: "The list command lists repositories generated by Red Hat Subscription Management in " + subman.RedHatRepoPath + " together with their status.",
+					Before:      beforeReposListAction,
+					Action:      reposListAction,
+				},
+			},
+		},
+		{
+			Name:      "insights",
+			Usage:     "Manage Red Hat Lightspeed (formerly Insights) client",
+			UsageText: fmt.Sprintf("%v insights COMMAND", app.Name),
+			Commands: []*cli.Command{
+				{
+					Name:      "config",
+					Usage:     "Manage configuration of insights-client",
+					UsageText: fmt.Sprintf("%v insights config COMMAND", app.Name),
+					Commands: []*cli.Command{
+						{
+							Name: "show",
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:    "format",
+									Usage:   "prints options in machine-readable format (supported formats: \"json\")",
+									Aliases: []string{"f"},
+								},
+							},
+							Usage:       "Show options of insights-client managed by rhc",
+							UsageText:   fmt.Sprintf("%v insights config show", app.Name),
+							Description: "The show command prints the options of " + datacollection.InsightsConfigPath + " managed by rhc.",
+							Before:      beforeInsightsConfigShowAction,
+							Action:      insightsConfigShowAction,
+						},
+						{
+							Name:      "set",
+							Usage:     "Set an option of insights-client",
+							UsageText: fmt.Sprintf("%v insights config set KEY VALUE", app.Name),
+							Description: fmt.Sprintf(
+								"The set command validates VALUE and sets the option KEY in %s. Supported options: %s. Boolean options accept true or false; an empty proxy removes the option.",
+								datacollection.InsightsConfigPath,
+								strings.Join(datacollection.InsightsConfigKeys, ", "),
+							),
+							Before: beforeInsightsConfigSetAction,
+							Action: insightsConfigSetAction,
+						},
+					},
+				},
+				{
+					Name:        "enable-scheduling",
+					Usage:       "Enable the scheduled collection of insights-client",
+					UsageText:   fmt.Sprintf("%v insights enable-scheduling", app.Name),
+					Description: "The enable-scheduling command enables the scheduled collection and upload of insights-client (the insights-client.timer, or the cron job of old versions), so the data in Red Hat Lightspeed stays current. The state is shown by 'rhc status'.",
+					Before:      beforeInsightsSchedulingAction,
+					Action:      insightsEnableSchedulingAction,
+				},
+				{
+					Name:        "disable-scheduling",
+					Usage:       "Disable the scheduled collection of insights-client",
+					UsageText:   fmt.Sprintf("%v insights disable-scheduling", app.Name),
+					Description: "The disable-scheduling command disables the scheduled collection and upload of insights-client. The system stays connected to Red Hat Lightspeed; its data is updated only by uploads run manually. The state is shown by 'rhc status'.",
+					Before:      beforeInsightsSchedulingAction,
+					Action:      insightsDisableSchedulingAction,
+				},
+				{
+					Name: "last-upload",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "extract",
+							Usage: "extract the kept archive into `DIR` for inspection",
+						},
+						&cli.StringFlag{
+							Name:    "format",
+							Usage:   "prints last upload in machine-readable format (supported formats: \"json\")",
+							Aliases: []string{"f"},
+						},
+					},
+					Usage:       "Show the last archive uploaded by insights-client",
+					UsageText:   fmt.Sprintf("%v insights last-upload [--extract DIR]", app.Name),
+					Description: fmt.Sprintf("The last-upload command prints when insights-client last uploaded an archive, and the archive kept in %s. Archives are kept when 'keep_archive' is enabled (see '%v insights config set'). With --extract, the kept archive is extracted into DIR, which has to be empty, so reviewers can inspect what was sent from the system.", datacollection.InsightsArchiveDir, app.Name),
+					Before:      beforeInsightsLastUploadAction,
+					Action:      insightsLastUploadAction,
+				},
+			},
+		},
 		{
 			Name:      "collector",
 			Usage:     "Collect data for analysis",
@@ -427,7 +1522,7 @@ func main() {
 	app.After = afterAction
 	app.ExitErrHandler = exitErrHandler
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	ctx, stop := notifySignalContext(context.Background())
 	defer stop()
 
 	if err := app.Run(ctx, os.Args); err != nil {
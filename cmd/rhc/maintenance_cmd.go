@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/datacollection"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// Maintenance is the content of the maintenance window file. Until the end of
+// the window, scheduled check-ins are skipped.
+type Maintenance struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+}
+
+// MaintenanceResult is structure holding the result of 'rhc maintenance'.
+// The result could be printed in machine-readable format.
+type MaintenanceResult struct {
+	Active           bool       `json:"active"`
+	Until            *time.Time `json:"until,omitempty"`
+	InventoryUpdated bool       `json:"inventory_updated"`
+	Warnings         []Warning  `json:"warnings"`
+}
+
+// readMaintenance returns the maintenance window active at the time now, or
+// nil when there is none. An expired window is not an error.
+func readMaintenance(path string, now time.Time) (*Maintenance, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read maintenance window: %w", err)
+	}
+	var maintenance Maintenance
+	if err = json.Unmarshal(data, &maintenance); err != nil {
+		return nil, fmt.Errorf("cannot parse maintenance window %s: %w", path, err)
+	}
+	if !now.Before(maintenance.Until) {
+		return nil, nil
+	}
+	return &maintenance, nil
+}
+
+// writeMaintenance creates the maintenance window file.
+func writeMaintenance(path string, maintenance Maintenance) error {
+	data, err := json.Marshal(maintenance)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot start maintenance window: %w", err)
+	}
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("cannot start maintenance window: %w", err)
+	}
+	return nil
+}
+
+// inMaintenance reports whether scheduled check-ins are paused by a maintenance window.
+func inMaintenance() bool {
+	maintenance, err := readMaintenance(MaintenancePath, time.Now())
+	if err != nil {
+		slog.Warn(err.Error())
+		return false
+	}
+	if maintenance != nil {
+		slog.Info("Skipping check-in during maintenance window", "until", maintenance.Until.Format(time.RFC3339))
+		return true
+	}
+	return false
+}
+
+// updateInventoryMaintenance sets the maintenance facts of the host in Inventory.
+// A zero until ends the maintenance.
+func updateInventoryMaintenance(ctx context.Context, until time.Time) error {
+	inventoryID, err := datacollection.InsightsInventoryID()
+	if err != nil {
+		return err
+	}
+	if inventoryID == "" {
+		return fmt.Errorf("the system is not known to Inventory")
+	}
+	client, err := apiHTTPClient()
+	if err != nil {
+		return err
+	}
+	return ui.Spinner(func() error {
+		return datacollection.SetMaintenanceFacts(ctx, client, inventoryID, until)
+	}, ui.Indent.Small, "Updating maintenance in Inventory...")
+}
+
+// beforeMaintenanceAction ensures the user has supplied a correct `--format` flag,
+// and a positive --duration of a new maintenance window.
+func beforeMaintenanceAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+
+	if cmd.Name == "on" {
+		if !cmd.IsSet("duration") {
+			return ctx, cli.Exit("--duration is required", exitcode.Usage)
+		}
+		if cmd.Duration("duration") <= 0 {
+			return ctx, cli.Exit("--duration has to be a positive duration (e.g. \"2h\")", exitcode.Usage)
+		}
+	}
+	return ctx, nil
+}
+
+// maintenanceAction starts or ends the maintenance window of the system. During
+// the window, scheduled check-ins are paused, and the host is marked by facts
+// in Inventory, so its staleness does not raise alerts.
+func maintenanceAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot change maintenance of system", exitcode.NoPerm)
+	}
+
+	connected, err := subman.HasConsumerCertificate()
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+	if !connected {
+		return cli.Exit("this system is not connected", exitcode.Usage)
+	}
+
+	result := MaintenanceResult{Warnings: collectWarnings()}
+	var until time.Time
+	if cmd.Name == "on" {
+		now := time.Now().UTC()
+		until = now.Add(cmd.Duration("duration"))
+		if err = writeMaintenance(MaintenancePath, Maintenance{Since: now, Until: until}); err != nil {
+			slog.Error(err.Error())
+			return cli.Exit(err, exitcode.CantCreat)
+		}
+		result.Active = true
+		result.Until = &until
+		slog.Info("Maintenance window started", "until", until.Format(time.RFC3339))
+		recordAudit("maintenance-on", map[string]string{"until": until.Format(time.RFC3339)})
+	} else {
+		err = os.Remove(MaintenancePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Error("cannot remove maintenance window", "err", err)
+			return cli.Exit(fmt.Errorf("cannot end maintenance window: %w", err), exitcode.IOErr)
+		}
+		slog.Info("Maintenance window ended")
+		recordAudit("maintenance-off", nil)
+	}
+
+	if err = updateInventoryMaintenance(ctx, until); err != nil {
+		warning := Warning{
+			Code:    "maintenance-inventory",
+			Message: fmt.Sprintf("cannot update maintenance in Inventory: %v", err),
+		}
+		slog.Warn(warning.Message, "code", warning.Code)
+		result.Warnings = append(result.Warnings, warning)
+	} else {
+		result.InventoryUpdated = true
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print maintenance as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+
+	if result.Active {
+		ui.Printf(
+			"%s[%v] Maintenance until %s, scheduled check-ins are paused\n",
+			ui.Indent.Small, ui.Icons.Ok, until.Local().Format(time.DateTime),
+		)
+	} else {
+		ui.Printf("%s[%v] Maintenance ended, scheduled check-ins are resumed\n", ui.Indent.Small, ui.Icons.Ok)
+	}
+	if result.InventoryUpdated {
+		ui.Printf("%s[%v] Maintenance updated in Inventory\n", ui.Indent.Small, ui.Icons.Ok)
+	}
+	printWarnings(result.Warnings)
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadMaintenance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maintenance.json")
+	since := time.Date(2025, 1, 31, 2, 0, 0, 0, time.UTC)
+	until := since.Add(2 * time.Hour)
+
+	got, err := readMaintenance(path, since)
+	if err != nil || got != nil {
+		t.Fatalf("got (%v, %v) without maintenance window, want (nil, nil)", got, err)
+	}
+
+	if err = writeMaintenance(path, Maintenance{Since: since, Until: until}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		description string
+		now         time.Time
+		wantActive  bool
+	}{
+		{description: "within window", now: since.Add(time.Hour), wantActive: true},
+		{description: "end of window", now: until},
+		{description: "after window", now: until.Add(time.Minute)},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := readMaintenance(path, test.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got != nil) != test.wantActive {
+				t.Errorf("got %v, want active %v", got, test.wantActive)
+			}
+			if got != nil && !got.Until.Equal(until) {
+				t.Errorf("got until %v, want %v", got.Until, until)
+			}
+		})
+	}
+
+	if err = os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = readMaintenance(path, since); err == nil {
+		t.Error("expected error for malformed maintenance window")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/datacollection"
+	httpapi "github.com/redhatinsights/rhc/internal/http"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// isWebhookURL reports whether rawURL is an http or https URL.
+func isWebhookURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	return err == nil && parsed.Host != "" && (parsed.Scheme == "http" || parsed.Scheme == "https")
+}
+
+// checkNotifyURLFlag ensures the user has supplied a valid `--notify-url` flag.
+func checkNotifyURLFlag(cmd *cli.Command) error {
+	if cmd.IsSet("notify-url") && !isWebhookURL(cmd.String("notify-url")) {
+		return cli.Exit("--notify-url has to be an http or https URL", exitcode.Usage)
+	}
+	return nil
+}
+
+// webhookURL returns the webhook set by --notify-url, or by the configuration file.
+func webhookURL(cmd *cli.Command) string {
+	if cmd.IsSet("notify-url") {
+		return cmd.String("notify-url")
+	}
+	return conf.Config.Notify.URL
+}
+
+// notifyWebhook posts result of event (e.g. "connect") to the webhook, when
+// one is set. The result is signed with the secret of the configuration file.
+// A failed delivery is reported, but it does not change the outcome of the command.
+func notifyWebhook(ctx context.Context, cmd *cli.Command, event string, result any) {
+	target := webhookURL(cmd)
+	if target == "" {
+		return
+	}
+
+	err := postResult(ctx, target, event, result)
+	if err != nil {
+		slog.Warn("Unable to notify webhook", "url", datacollection.RedactURL(target), "err", err)
+		if !ui.IsOutputMachineReadable() {
+			ui.Printf("%s[%v] Unable to notify webhook: %v\n", ui.Indent.Small, ui.Icons.Warning, err)
+		}
+		return
+	}
+	slog.Info("Webhook notified", "url", datacollection.RedactURL(target), "event", event)
+}
+
+// postResult posts result as JSON document to target.
+func postResult(ctx context.Context, target string, event string, result any) error {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("cannot encode result: %w", err)
+	}
+
+	var secret []byte
+	if path := conf.Config.Notify.SecretFile; path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("cannot read webhook secret: %w", err)
+		}
+		secret = []byte(strings.TrimRight(string(data), "\r\n"))
+	}
+	if len(secret) == 0 {
+		message := "the webhook payload is not signed, as 'notify.secret-file' is not set; receivers cannot verify it comes from the system"
+		slog.Warn(message, "url", datacollection.RedactURL(target))
+		if !ui.IsOutputMachineReadable() {
+			ui.Printf("%s[%v] Warning: %s\n", ui.Indent.Small, ui.Icons.Warning, message)
+		}
+	}
+
+	return httpapi.PostWebhook(ctx, target, event, payload, secret)
+}
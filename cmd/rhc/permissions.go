@@ -0,0 +1,216 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"golang.org/x/sys/unix"
+
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/systemd"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// Capability is the outcome of a probe of 'rhc doctor --permissions', whether
+// the current user can perform an operation rhc relies on.
+type Capability struct {
+	Name    string `json:"name"`
+	Allowed bool   `json:"allowed"`
+	// AuthenticationRequired is set, when the operation is allowed after the
+	// user authenticates to polkit.
+	AuthenticationRequired bool   `json:"authentication_required,omitempty"`
+	Detail                 string `json:"detail"`
+	// Commands are the commands of rhc requiring the capability.
+	Commands []string `json:"commands"`
+}
+
+// PermissionsResult is structure holding the capability matrix printed by
+// 'rhc doctor --permissions'. The result could be printed in machine-readable
+// format.
+type PermissionsResult struct {
+	User         string       `json:"user"`
+	UID          int          `json:"uid"`
+	Capabilities []Capability `json:"capabilities"`
+	Warnings     []Warning    `json:"warnings"`
+}
+
+// capabilityProbe probes a capability without changing the system.
+type capabilityProbe struct {
+	name     string
+	commands []string
+	probe    func() Capability
+}
+
+// capabilityProbes are the capabilities probed by 'rhc doctor --permissions',
+// in this order.
+var capabilityProbes = []capabilityProbe{
+	{
+		name:     "read-consumer-certificate",
+		commands: []string{"checkin", "maintenance", "status --upgrade-readiness"},
+		probe:    func() Capability { return probeAccess(subman.ConsumerKeyPath, unix.R_OK) },
+	},
+	{
+		name:     "query-rhsm",
+		commands: []string{"connect", "disconnect", "status"},
+		probe:    probeRHSM,
+	},
+	{
+		name:     "manage-systemd-units",
+		commands: []string{"connect", "disconnect", "feature enable", "feature disable", "collector"},
+		probe:    probeManageUnits,
+	},
+	{
+		name:     "write-configuration",
+		commands: []string{"config set", "config unset", "config migrate"},
+		probe:    func() Capability { return probeAccess(ConfigDropInDir, unix.W_OK) },
+	},
+	{
+		name:     "write-state",
+		commands: []string{"connect", "disconnect", "checkin", "maintenance", "lock", "clean"},
+		probe:    func() Capability { return probeAccess(filepath.Dir(ConnectionPath), unix.W_OK) },
+	},
+	{
+		name:     "write-logs",
+		commands: []string{"connect", "disconnect", "audit"},
+		probe:    func() Capability { return probeAccess(LogDir, unix.W_OK) },
+	},
+	{
+		name:     "run-insights-client",
+		commands: []string{"connect", "disconnect", "checkin", "insights"},
+		probe:    func() Capability { return probeAccess("/etc/insights-client", unix.W_OK) },
+	},
+}
+
+// existingAncestor returns path, or its closest existing ancestor, which is
+// checked for access instead of a path that would be created.
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil || !errors.Is(err, os.ErrNotExist) {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// probeAccess checks whether the current user can access path in mode
+// (unix.R_OK or unix.W_OK). A missing path is writable, when its closest
+// existing ancestor is; a missing path cannot be read.
+func probeAccess(path string, mode uint32) Capability {
+	verb := "readable"
+	if mode == unix.W_OK {
+		verb = "writable"
+	}
+	checked := path
+	if mode == unix.W_OK {
+		checked = existingAncestor(path)
+	}
+	err := unix.Access(checked, mode)
+	switch {
+	case err == nil && checked == path:
+		return Capability{Allowed: true, Detail: fmt.Sprintf("%s is %s", path, verb)}
+	case err == nil:
+		return Capability{Allowed: true, Detail: fmt.Sprintf("%s does not exist, %s is %s", path, checked, verb)}
+	case errors.Is(err, unix.ENOENT):
+		return Capability{Detail: fmt.Sprintf("%s does not exist", path)}
+	default:
+		return Capability{Detail: fmt.Sprintf("%s is not %s: %v", checked, verb, err)}
+	}
+}
+
+// probeRHSM checks whether the current user can query the RHSM service over
+// the system D-Bus.
+func probeRHSM() Capability {
+	client, err := subman.NewRHSMClient()
+	if err == nil {
+		_, err = client.IsRegistered()
+	}
+	if err != nil {
+		return Capability{Detail: err.Error()}
+	}
+	return Capability{Allowed: true, Detail: "the RHSM service answers over the system D-Bus"}
+}
+
+// probeManageUnits asks polkit whether the current user can manage units of
+// the system manager.
+func probeManageUnits() Capability {
+	authorization, err := systemd.CheckAuthorization(systemd.ManageUnitsAction)
+	if err != nil {
+		return Capability{Detail: err.Error()}
+	}
+	switch {
+	case authorization.Authorized:
+		return Capability{Allowed: true, Detail: fmt.Sprintf("polkit authorizes %s", systemd.ManageUnitsAction)}
+	case authorization.Challenge:
+		return Capability{
+			Allowed:                true,
+			AuthenticationRequired: true,
+			Detail:                 fmt.Sprintf("polkit authorizes %s after authentication", systemd.ManageUnitsAction),
+		}
+	default:
+		return Capability{Detail: fmt.Sprintf("polkit does not authorize %s", systemd.ManageUnitsAction)}
+	}
+}
+
+// probeCapabilities runs the probes in order.
+func probeCapabilities(probes []capabilityProbe) []Capability {
+	capabilities := []Capability{}
+	for _, p := range probes {
+		capability := p.probe()
+		capability.Name = p.name
+		capability.Commands = p.commands
+		slog.Info("Capability probed", "name", capability.Name, "allowed", capability.Allowed, "detail", capability.Detail)
+		capabilities = append(capabilities, capability)
+	}
+	return capabilities
+}
+
+// permissionsAction prints the capability matrix of the current user, so sudo
+// rules and polkit policies can grant the minimal permissions rhc needs.
+func permissionsAction(cmd *cli.Command) error {
+	result := PermissionsResult{
+		UID:          os.Getuid(),
+		Capabilities: probeCapabilities(capabilityProbes),
+		Warnings:     collectWarnings(),
+	}
+	result.User = strconv.Itoa(result.UID)
+	if u, err := user.LookupId(result.User); err == nil {
+		result.User = u.Username
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err := ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print capabilities as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+
+	ui.Printf("Capabilities of user %s (uid %d):\n\n", result.User, result.UID)
+	rows := [][]string{}
+	for _, capability := range result.Capabilities {
+		allowed := "no"
+		if capability.AuthenticationRequired {
+			allowed = "with authentication"
+		} else if capability.Allowed {
+			allowed = "yes"
+		}
+		rows = append(rows, []string{capability.Name, allowed, strings.Join(capability.Commands, ", "), capability.Detail})
+	}
+	ui.PrintTable([]string{"CAPABILITY", "ALLOWED", "REQUIRED BY", "DETAIL"}, rows)
+	printWarnings(result.Warnings)
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestExistingAncestor(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		description string
+		path        string
+		want        string
+	}{
+		{description: "existing", path: dir, want: dir},
+		{description: "missing", path: filepath.Join(dir, "rhc"), want: dir},
+		{description: "missing parents", path: filepath.Join(dir, "rhc", "config.toml.d"), want: dir},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if got := existingAncestor(test.path); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestProbeAccess(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "rhc")
+
+	tests := []struct {
+		description string
+		path        string
+		mode        uint32
+		wantAllowed bool
+		wantDetail  string
+	}{
+		{
+			description: "writable",
+			path:        dir,
+			mode:        unix.W_OK,
+			wantAllowed: true,
+			wantDetail:  dir + " is writable",
+		},
+		{
+			description: "created in writable parent",
+			path:        missing,
+			mode:        unix.W_OK,
+			wantAllowed: true,
+			wantDetail:  missing + " does not exist, " + dir + " is writable",
+		},
+		{
+			description: "missing file to read",
+			path:        missing,
+			mode:        unix.R_OK,
+			wantDetail:  missing + " does not exist",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := probeAccess(test.path, test.mode)
+			if got.Allowed != test.wantAllowed || got.Detail != test.wantDetail {
+				t.Errorf("got (%v, %q), want (%v, %q)", got.Allowed, got.Detail, test.wantAllowed, test.wantDetail)
+			}
+		})
+	}
+}
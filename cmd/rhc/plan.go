@@ -0,0 +1,286 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/datacollection"
+	"github.com/redhatinsights/rhc/internal/remotemanagement"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/feature/prefcache"
+)
+
+// maskedValue replaces secrets in the plan.
+const maskedValue = "********"
+
+// promptedValue replaces values that would be asked for interactively.
+const promptedValue = "<prompt>"
+
+// dryRunCommands are the commands supporting the global --dry-run flag.
+var dryRunCommands = []string{"connect", "disconnect", "clean"}
+
+// checkDryRunCommand returns an error when the command name does not support
+// the --dry-run flag.
+func checkDryRunCommand(name string) error {
+	if !slices.Contains(dryRunCommands, name) {
+		return fmt.Errorf("--dry-run is not supported by '%s'; it is supported by %s", name, strings.Join(dryRunCommands, ", "))
+	}
+	return nil
+}
+
+// PlanStep is a single operation a command would execute. Plans are printed
+// instead of executing the operations when the --dry-run flag is used.
+type PlanStep struct {
+	Operation   string         `json:"operation"`
+	Description string         `json:"description"`
+	Arguments   map[string]any `json:"arguments,omitempty"`
+}
+
+// maskSecrets returns a copy of values with every value masked.
+func maskSecrets(values []string) []string {
+	masked := make([]string, len(values))
+	for i := range values {
+		masked[i] = maskedValue
+	}
+	return masked
+}
+
+// connectPlan returns the operations 'rhc connect' would execute with the
+// flags of cmd and the feature preferences in cache.
+func connectPlan(cmd *cli.Command, cache *prefcache.PreferenceCache) ([]PlanStep, error) {
+	var plan []PlanStep
+
+	content, err := cache.Get("content")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get content preference: %v", err)
+	}
+	analytics, err := cache.Get("analytics")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analytics preference: %v", err)
+	}
+	remoteManagement, err := cache.Get("remote-management")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote-management preference: %v", err)
+	}
+
+	if serverURL, baseURL := serverURLs(cmd); serverURL != "" && !cmd.Bool("from-bootstrap") {
+		step := PlanStep{
+			Operation:   "server-configure",
+			Description: "Configure the entitlement server in Red Hat Subscription Management",
+			Arguments:   map[string]any{"server": serverURL},
+		}
+		if baseURL != "" {
+			step.Arguments["base_url"] = baseURL
+		}
+		plan = append(plan, step)
+	}
+
+	if caCert := cmd.String("ca-cert"); caCert != "" {
+		plan = append(plan, PlanStep{
+			Operation:   "ca-cert-install",
+			Description: "Install CA certificate trusted by Red Hat Subscription Management",
+			Arguments: map[string]any{
+				"source": caCert,
+				"name":   customCACertName,
+			},
+		})
+	}
+
+	// A registered system is not registered again
+	connected, _ := cmd.Root().Metadata[connectedKey].(bool)
+
+	if purpose := connectSyspurpose(cmd); purpose != (subman.Syspurpose{}) && !connected {
+		step := PlanStep{
+			Operation:   "syspurpose-set",
+			Description: "Set the system purpose sent to Red Hat Subscription Management on registration",
+			Arguments:   map[string]any{},
+		}
+		if purpose.Role != "" {
+			step.Arguments["role"] = purpose.Role
+		}
+		if purpose.ServiceLevel != "" {
+			step.Arguments["sla"] = purpose.ServiceLevel
+		}
+		if purpose.Usage != "" {
+			step.Arguments["usage"] = purpose.Usage
+		}
+		plan = append(plan, step)
+	}
+
+	register := PlanStep{
+		Operation:   "rhsm-register",
+		Description: "Register the system with Red Hat Subscription Management",
+		Arguments: map[string]any{
+			"enable_content": content,
+		},
+	}
+	if organization := cmd.String("organization"); organization != "" {
+		register.Arguments["organization"] = organization
+	}
+	if templates := cmd.StringSlice("content-template"); len(templates) > 0 {
+		register.Arguments["content_templates"] = templates
+	}
+	if cmd.Bool("auto") {
+		register.Arguments["method"] = "cloud"
+		if provider, ok := cmd.Root().Metadata[cloudProviderKey].(string); ok {
+			register.Arguments["provider"] = provider
+		}
+	} else if activationKeys := cmd.StringSlice("activation-key"); len(activationKeys) > 0 {
+		register.Arguments["method"] = "activation-key"
+		register.Arguments["activation_keys"] = maskSecrets(activationKeys)
+	} else {
+		register.Arguments["method"] = "password"
+		register.Arguments["username"] = cmp.Or(cmd.String("username"), promptedValue)
+		register.Arguments["password"] = promptedValue
+		if cmd.String("password") != "" {
+			register.Arguments["password"] = maskedValue
+		}
+	}
+	if len(conf.Config.Servers) > 0 {
+		var servers []string
+		for _, server := range conf.Config.Servers {
+			servers = append(servers, server.URL)
+		}
+		register.Arguments["servers"] = servers
+	}
+	if !connected {
+		plan = append(plan, register)
+	}
+
+	if content && cmd.Bool("check-content") {
+		plan = append(plan, PlanStep{
+			Operation:   "content-check",
+			Description: "Download metadata of the first enabled repository",
+		})
+	}
+
+	if analytics {
+		if len(conf.Config.Tags) > 0 {
+			tags := make([]string, 0, len(conf.Config.Tags))
+			for key := range conf.Config.Tags {
+				tags = append(tags, key)
+			}
+			slices.Sort(tags)
+			plan = append(plan, PlanStep{
+				Operation:   "insights-tags-write",
+				Description: "Write host tags for Red Hat Lightspeed",
+				Arguments: map[string]any{
+					"path": datacollection.InsightsTagsPath,
+					"tags": tags,
+				},
+			})
+		}
+		plan = append(plan, PlanStep{
+			Operation:   "insights-register",
+			Description: "Connect to Red Hat Lightspeed (formerly Insights)",
+			Arguments: map[string]any{
+				"command": append([]string{"/usr/bin/insights-client"}, connectInsightsRegistration(cmd).Args()...),
+			},
+		})
+	}
+
+	plan = append(plan, PlanStep{
+		Operation:   "checkin-timer-install",
+		Description: "Enable periodic check-in",
+		Arguments: map[string]any{
+			"enable":   []string{checkinTimerName},
+			"interval": conf.Config.Checkin.Interval.String(),
+		},
+	})
+
+	if remoteManagement && content && analytics {
+		workers := make([]string, 0, len(remotemanagement.WorkerConfigs))
+		for _, worker := range remotemanagement.WorkerConfigs {
+			workers = append(workers, worker.Path)
+		}
+		plan = append(plan, PlanStep{
+			Operation:   "workers-configure",
+			Description: "Generate configuration files of the installed yggdrasil workers",
+			Arguments: map[string]any{
+				"paths":     workers,
+				"templates": remotemanagement.WorkerTemplateDir,
+			},
+		})
+		plan = append(plan, PlanStep{
+			Operation:   "services-activate",
+			Description: "Activate the yggdrasil service",
+			Arguments: map[string]any{
+				"start":  []string{"rhc-canonical-facts.service"},
+				"enable": []string{"yggdrasil.service"},
+			},
+		})
+		plan = append(plan, PlanStep{
+			Operation:   "playbook-keys-verify",
+			Description: "Install and verify the keys used to verify playbooks",
+			Arguments: map[string]any{
+				"keyring": remotemanagement.PlaybookKeyringPath,
+			},
+		})
+	}
+
+	return plan, nil
+}
+
+// disconnectPlan returns the operations 'rhc disconnect' would execute to
+// disconnect targets.
+func disconnectPlan(targets []string) []PlanStep {
+	plan := []PlanStep{}
+	if slices.Contains(targets, "yggdrasil") {
+		plan = append(plan, PlanStep{
+			Operation:   "services-deactivate",
+			Description: "Deactivate the yggdrasil service",
+			Arguments: map[string]any{
+				"disable": []string{"rhc-canonical-facts.service", "yggdrasil.service"},
+			},
+		})
+	}
+	if slices.Contains(targets, "insights") {
+		plan = append(plan, PlanStep{
+			Operation:   "insights-unregister",
+			Description: "Disconnect from Red Hat Lightspeed (formerly Insights)",
+			Arguments: map[string]any{
+				"command": []string{"/usr/bin/insights-client", "--unregister"},
+			},
+		})
+	}
+	if slices.Contains(targets, "rhsm") {
+		plan = append(plan, PlanStep{
+			Operation:   "rhsm-unregister",
+			Description: "Unregister the system from Red Hat Subscription Management",
+		}, PlanStep{
+			Operation:   "checkin-timer-remove",
+			Description: "Disable periodic check-in",
+			Arguments: map[string]any{
+				"disable": []string{checkinTimerName},
+			},
+		})
+	}
+	return plan
+}
+
+// printPlan prints the plan in human-readable format.
+func printPlan(plan []PlanStep) {
+	ui.Printf("The following operations would be executed:\n\n")
+	for i, step := range plan {
+		ui.Printf("%s%d. %s\n", ui.Indent.Small, i+1, step.Description)
+		keys := make([]string, 0, len(step.Arguments))
+		for key := range step.Arguments {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			value := step.Arguments[key]
+			if list, ok := value.([]string); ok {
+				value = strings.Join(list, " ")
+			}
+			ui.Printf("%s    %s: %v\n", ui.Indent.Small, key, value)
+		}
+	}
+	ui.Printf("\nNo changes were made (--dry-run).\n")
+}
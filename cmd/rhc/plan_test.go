@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/pkg/feature/prefcache"
+)
+
+func TestConnectPlan(t *testing.T) {
+	tests := []struct {
+		description    string
+		args           []string
+		disable        []string
+		connected      bool
+		provider       string
+		wantOperations []string
+		wantArguments  map[string]any
+	}{
+		{
+			description:    "activation keys are masked",
+			args:           []string{"--organization", "1234", "--activation-key", "key1", "--activation-key", "key2"},
+			wantOperations: []string{"rhsm-register", "insights-register", "checkin-timer-install", "workers-configure", "services-activate", "playbook-keys-verify"},
+			wantArguments: map[string]any{
+				"method":          "activation-key",
+				"organization":    "1234",
+				"activation_keys": []string{maskedValue, maskedValue},
+				"enable_content":  true,
+			},
+		},
+		{
+			description:    "password is masked",
+			args:           []string{"--username", "admin", "--password", "secret", "--check-content"},
+			disable:        []string{"remote-management"},
+			wantOperations: []string{"rhsm-register", "content-check", "insights-register", "checkin-timer-install"},
+			wantArguments: map[string]any{
+				"method":         "password",
+				"username":       "admin",
+				"password":       maskedValue,
+				"enable_content": true,
+			},
+		},
+		{
+			description:    "CA certificate is installed first",
+			args:           []string{"--organization", "1234", "--activation-key", "key1", "--ca-cert", "/tmp/proxy-ca.pem"},
+			disable:        []string{"analytics", "remote-management"},
+			wantOperations: []string{"ca-cert-install", "rhsm-register", "checkin-timer-install"},
+			wantArguments: map[string]any{
+				"source": "/tmp/proxy-ca.pem",
+				"name":   customCACertName,
+			},
+		},
+		{
+			description:    "system purpose is set before registration",
+			args:           []string{"--organization", "1234", "--activation-key", "key1", "--sla", "Premium", "--usage", "Production"},
+			disable:        []string{"analytics", "remote-management"},
+			wantOperations: []string{"syspurpose-set", "rhsm-register", "checkin-timer-install"},
+			wantArguments: map[string]any{
+				"sla":   "Premium",
+				"usage": "Production",
+			},
+		},
+		{
+			description:    "missing credentials are prompted",
+			disable:        []string{"analytics", "remote-management"},
+			wantOperations: []string{"rhsm-register", "checkin-timer-install"},
+			wantArguments: map[string]any{
+				"method":         "password",
+				"username":       promptedValue,
+				"password":       promptedValue,
+				"enable_content": true,
+			},
+		},
+		{
+			description:    "cloud instance identity",
+			args:           []string{"--auto"},
+			disable:        []string{"analytics", "remote-management"},
+			provider:       "aws",
+			wantOperations: []string{"rhsm-register", "checkin-timer-install"},
+			wantArguments: map[string]any{
+				"method":         "cloud",
+				"provider":       "aws",
+				"enable_content": true,
+			},
+		},
+		{
+			description:    "connected system is not registered again",
+			args:           []string{"--organization", "1234", "--activation-key", "key1", "--role", "Server", "--check-content"},
+			disable:        []string{"analytics", "remote-management"},
+			connected:      true,
+			wantOperations: []string{"content-check", "checkin-timer-install"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cache, err := prefcache.NewDefaultCache(filepath.Join(t.TempDir(), "prefs.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range test.disable {
+				if err = cache.Set(name, false); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var plan []PlanStep
+			cmd := &cli.Command{
+				Name:     "connect",
+				Metadata: map[string]any{connectedKey: test.connected, cloudProviderKey: test.provider},
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "username"},
+					&cli.StringFlag{Name: "password"},
+					&cli.StringFlag{Name: "organization"},
+					&cli.StringSliceFlag{Name: "activation-key"},
+					&cli.StringSliceFlag{Name: "content-template"},
+					&cli.BoolFlag{Name: "check-content"},
+					&cli.StringFlag{Name: "ca-cert"},
+					&cli.StringFlag{Name: "role"},
+					&cli.StringFlag{Name: "sla"},
+					&cli.StringFlag{Name: "usage"},
+					&cli.BoolFlag{Name: "auto"},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					plan, err = connectPlan(cmd, cache)
+					return err
+				},
+			}
+			if err := cmd.Run(context.Background(), append([]string{"connect"}, test.args...)); err != nil {
+				t.Fatal(err)
+			}
+
+			var operations []string
+			for _, step := range plan {
+				operations = append(operations, step.Operation)
+			}
+			if !cmp.Equal(operations, test.wantOperations) {
+				t.Errorf("%v", cmp.Diff(operations, test.wantOperations))
+			}
+			if !cmp.Equal(plan[0].Arguments, test.wantArguments) {
+				t.Errorf("%v", cmp.Diff(plan[0].Arguments, test.wantArguments))
+			}
+		})
+	}
+}
+
+func TestCheckDryRunCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		wantError bool
+	}{
+		{name: "connect"},
+		{name: "disconnect"},
+		{name: "clean"},
+		{name: "decommission", wantError: true},
+		{name: "switch", wantError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkDryRunCommand(test.name)
+			if (err != nil) != test.wantError {
+				t.Errorf("got error %v, want error %v", err, test.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/datacollection"
+	httpapi "github.com/redhatinsights/rhc/internal/http"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// Endpoints of Red Hat services used, when rhsm.conf cannot be read.
+const (
+	defaultEntitlementURL = "https://subscription.rhsm.redhat.com/subscription"
+	defaultContentURL     = "https://cdn.redhat.com"
+)
+
+// EndpointPreflight is the outcome of the preflight check of an endpoint
+// required by 'rhc connect'.
+type EndpointPreflight struct {
+	Name string `json:"name"`
+	httpapi.PreflightResult
+}
+
+// PreflightCheckResult is structure holding the outcome of 'rhc preflight'. The
+// result could be printed in machine-readable format.
+type PreflightCheckResult struct {
+	Successful bool                `json:"successful"`
+	Endpoints  []EndpointPreflight `json:"endpoints"`
+	Warnings   []Warning           `json:"warnings"`
+}
+
+// preflightEndpoint is an endpoint checked by 'rhc preflight'.
+type preflightEndpoint struct {
+	name string
+	url  string
+	// checkPath fails the check, when the server does not serve the path
+	// of url, see httpapi.PreflightResult.CheckPath.
+	checkPath bool
+}
+
+// insightsGatewayProbePath is the path of the Red Hat Lightspeed API checked on
+// a gateway. It answers requests without a client certificate with an error
+// other than "404 Not Found".
+const insightsGatewayProbePath = "inventory/v1/hosts"
+
+// preflightEndpoints returns the endpoints required to connect the system,
+// as configured in rhsm.conf, and the directory of CA certificates trusted by
+// RHSM. The defaults are used, when rhsm.conf cannot be read. When a Red Hat
+// Lightspeed gateway is configured, its path of the API is checked instead of
+// console.redhat.com, which may not be accessible.
+func preflightEndpoints() ([]preflightEndpoint, string) {
+	entitlementURL, contentURL, caCertDir := defaultEntitlementURL, defaultContentURL, subman.DefaultCACertDir
+	if client, err := subman.NewRHSMClient(); err != nil {
+		slog.Debug("Unable to read rhsm.conf, using defaults", "err", err)
+	} else {
+		if value, err := client.ServerURL(); err != nil {
+			slog.Debug("Unable to read entitlement server URL", "err", err)
+		} else {
+			entitlementURL = value
+		}
+		if value, err := client.GetConfigValue("rhsm.baseurl"); err != nil {
+			slog.Debug("Unable to read content URL", "err", err)
+		} else if value != "" {
+			contentURL = value
+		}
+		if value, err := client.CACertDir(); err != nil {
+			slog.Debug("Unable to read CA certificate directory", "err", err)
+		} else {
+			caCertDir = value
+		}
+	}
+
+	endpoints := []preflightEndpoint{
+		{name: "entitlement", url: entitlementURL},
+		{name: "content", url: contentURL},
+	}
+	if gatewayURL := conf.Config.Insights.GatewayURL; gatewayURL != "" {
+		probeURL, err := url.JoinPath(gatewayURL, insightsGatewayProbePath)
+		if err != nil {
+			probeURL = gatewayURL
+		}
+		return append(endpoints, preflightEndpoint{name: "insights-gateway", url: probeURL, checkPath: true}), caCertDir
+	}
+	return append(endpoints,
+		preflightEndpoint{name: "insights", url: datacollection.InsightsAPIURL},
+		preflightEndpoint{name: "console", url: consoleURL},
+	), caCertDir
+}
+
+// runPreflight checks the endpoints one after another.
+func runPreflight(ctx context.Context, endpoints []preflightEndpoint, caCertDir string) PreflightCheckResult {
+	roots := loadTrustedRoots(caCertDir)
+	result := PreflightCheckResult{Successful: true, Endpoints: []EndpointPreflight{}}
+	for _, endpoint := range endpoints {
+		checked := EndpointPreflight{
+			Name:            endpoint.name,
+			PreflightResult: httpapi.PreflightEndpoint(ctx, endpoint.url, roots),
+		}
+		if endpoint.checkPath {
+			checked.CheckPath()
+		}
+		slog.Info("Endpoint checked", "name", endpoint.name, "url", endpoint.url, "successful", checked.Successful)
+		result.Successful = result.Successful && checked.Successful
+		result.Endpoints = append(result.Endpoints, checked)
+	}
+	return result
+}
+
+// endpointHost returns the host of rawURL for display, or rawURL itself when
+// it cannot be parsed.
+func endpointHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+	return parsed.Host
+}
+
+// beforePreflightAction ensures the user has supplied a correct `--format` flag.
+func beforePreflightAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// preflightAction verifies that the endpoints of Red Hat services required to
+// connect the system can be reached, without connecting it. It is used by
+// 'rhc preflight' and 'rhc connect --check-only'.
+func preflightAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	endpoints, caCertDir := preflightEndpoints()
+	result := runPreflight(ctx, endpoints, caCertDir)
+	result.Warnings = collectWarnings()
+
+	var exitErr error
+	if !result.Successful {
+		exitErr = cli.Exit("", exitcode.Unavailable)
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err := ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print preflight result as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return exitErr
+	}
+
+	for _, endpoint := range result.Endpoints {
+		icon := ui.Icons.Ok
+		if !endpoint.Successful {
+			icon = ui.Icons.Error
+		}
+		via := ""
+		if endpoint.Proxy != "" {
+			via = fmt.Sprintf(" through proxy %s", endpoint.Proxy)
+		}
+		ui.Printf("%s[%v] %s (%s)%s\n", ui.Indent.Small, icon, endpoint.Name, endpointHost(endpoint.URL), via)
+		for _, stage := range endpoint.Stages {
+			switch {
+			case stage.Skipped:
+				continue
+			case stage.Successful:
+				ui.Printf("%s[%v] %s: %s\n", ui.Indent.Medium, ui.Icons.Ok, stage.Name, stage.Detail)
+			default:
+				ui.Printf("%s[%v] %s: %s\n", ui.Indent.Medium, ui.Icons.Error, stage.Name, stage.Error)
+			}
+		}
+	}
+	if result.Successful {
+		ui.Printf("\nAll endpoints can be reached.\n")
+	} else {
+		var failed []string
+		for _, endpoint := range result.Endpoints {
+			if !endpoint.Successful {
+				failed = append(failed, endpoint.Name)
+			}
+		}
+		ui.Printf("\nEndpoints that cannot be reached: %s\n", strings.Join(failed, ", "))
+	}
+	printWarnings(result.Warnings)
+	return exitErr
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEndpointHost(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "https://cdn.redhat.com", want: "cdn.redhat.com"},
+		{input: "https://subscription.rhsm.redhat.com:8443/subscription", want: "subscription.rhsm.redhat.com:8443"},
+		{input: "cdn.redhat.com", want: "cdn.redhat.com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got := endpointHost(test.input)
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRunPreflight(t *testing.T) {
+	endpoints := []preflightEndpoint{{name: "invalid", url: "http://localhost"}}
+	result := runPreflight(context.Background(), endpoints, t.TempDir())
+	if result.Successful {
+		t.Errorf("got successful result for invalid endpoint")
+	}
+	if len(result.Endpoints) != 1 || result.Endpoints[0].Name != "invalid" {
+		t.Fatalf("got endpoints %+v, want one invalid endpoint", result.Endpoints)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	httpapi "github.com/redhatinsights/rhc/internal/http"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/systemd"
+)
+
+// proxyDropInName is the name of the drop-in passing the proxy configuration to services.
+const proxyDropInName = "rhc-proxy"
+
+// readProxyPassword sets the password of proxyConf from its password file. The
+// file is read only when the password is not set by --proxy-password.
+func readProxyPassword(proxyConf conf.ProxyConf) (conf.ProxyConf, error) {
+	if proxyConf.PasswordFile == "" || proxyConf.Password != "" {
+		return proxyConf, nil
+	}
+	password, err := readSecretFile(proxyConf.PasswordFile, readPassword)
+	if err != nil {
+		return proxyConf, fmt.Errorf("cannot read proxy password: %w", err)
+	}
+	proxyConf.Password = password
+	return proxyConf, nil
+}
+
+// applyProxyFlags overrides proxyConf by the --proxy, --proxy-user and
+// --proxy-password flags. With '--proxy-password -', the password is read from
+// the standard input.
+func applyProxyFlags(cmd *cli.Command, proxyConf conf.ProxyConf) (conf.ProxyConf, error) {
+	if cmd.IsSet("proxy") {
+		if err := checkProxyURL(cmd.String("proxy")); err != nil {
+			return proxyConf, fmt.Errorf("--proxy %w", err)
+		}
+		proxyConf.URL = cmd.String("proxy")
+	}
+	if cmd.IsSet("proxy-user") {
+		proxyConf.User = cmd.String("proxy-user")
+	}
+	if cmd.IsSet("proxy-password") {
+		password := cmd.String("proxy-password")
+		if password == stdinSecret {
+			var err error
+			if password, err = readPassword(os.Stdin); err != nil {
+				return proxyConf, fmt.Errorf("cannot read proxy password: %w", err)
+			}
+		}
+		proxyConf.Password = password
+	}
+	if proxyConf.Password != "" && proxyConf.User == "" {
+		return proxyConf, fmt.Errorf("--proxy-password requires --proxy-user or proxy.user")
+	}
+	return proxyConf, nil
+}
+
+// proxyURL returns the URL of the proxy with the credentials of the proxy, as
+// understood by the HTTP client and by the proxy environment variables.
+func proxyURL(proxyConf conf.ProxyConf) string {
+	if proxyConf.URL == "" || proxyConf.User == "" {
+		return proxyConf.URL
+	}
+	parsed, err := url.Parse(proxyConf.URL)
+	if err != nil {
+		return proxyConf.URL
+	}
+	if proxyConf.Password != "" {
+		parsed.User = url.UserPassword(proxyConf.User, proxyConf.Password)
+	} else {
+		parsed.User = url.User(proxyConf.User)
+	}
+	return parsed.String()
+}
+
+// proxyConfig converts the proxy configuration for the HTTP client.
+func proxyConfig(proxyConf conf.ProxyConf) httpapi.ProxyConfig {
+	return httpapi.ProxyConfig{URL: proxyURL(proxyConf), NoProxy: proxyConf.NoProxy}
+}
+
+// configureProxy applies the proxy configuration to the HTTP client, to RHSM
+// registration and to the environment, so tools executed by rhc (e.g.
+// insights-client) use the same proxy. Nothing is changed when no proxy is
+// configured.
+func configureProxy(proxyConf conf.ProxyConf) {
+	config := proxyConfig(proxyConf)
+	httpapi.Proxy = config
+	subman.Proxy = subman.ProxyOptions{
+		URL:      proxyConf.URL,
+		User:     proxyConf.User,
+		Password: proxyConf.Password,
+		NoProxy:  proxyConf.NoProxy,
+	}
+	for _, variable := range config.Environment() {
+		key, value, _ := strings.Cut(variable, "=")
+		if err := os.Setenv(key, value); err != nil {
+			slog.Warn("Unable to set proxy environment", "variable", key, "err", err)
+		}
+	}
+}
+
+// configureServiceProxy passes the proxy configuration to unit using a drop-in,
+// and reloads systemd. A drop-in written before is removed when no proxy is configured.
+func configureServiceProxy(unit string) error {
+	env := proxyConfig(conf.Config.Proxy).Environment()
+	if err := systemd.WriteEnvironmentDropIn(unit, proxyDropInName, env); err != nil {
+		return err
+	}
+
+	conn, err := systemd.NewConnectionContext(context.Background(), systemd.ConnectionTypeSystem)
+	if err != nil {
+		return fmt.Errorf("cannot connect to systemd: %v", err)
+	}
+	defer conn.Close()
+	if err = conn.Reload(); err != nil {
+		return fmt.Errorf("cannot reload systemd: %v", err)
+	}
+	return nil
+}
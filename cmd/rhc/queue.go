@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Operations of the check-in, which are queued when they fail.
+const (
+	// opUpdateFacts updates the facts and the name of the system in Red Hat
+	// Subscription Management.
+	opUpdateFacts = "update-facts"
+	// opDisplayName updates the display name of the host in Inventory.
+	opDisplayName = "display-name"
+	// opInsightsCheckin checks the system in with Red Hat Lightspeed.
+	opInsightsCheckin = "insights-checkin"
+)
+
+// QueuedOperation is an operation of the check-in that failed, e.g. because
+// an intermittently connected host could not reach Red Hat services. Queued
+// operations are retried by following check-ins, until they succeed or get
+// older than the maximum age of the queue.
+type QueuedOperation struct {
+	Operation string `json:"operation"`
+	// QueuedAt is the time the operation failed first.
+	QueuedAt time.Time `json:"queued_at"`
+	// Attempts is the number of failed attempts.
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error,omitempty"`
+}
+
+// readQueue returns the queued operations, oldest first. A missing queue file
+// is an empty queue.
+func readQueue(path string) ([]QueuedOperation, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read queued operations: %w", err)
+	}
+	var queue []QueuedOperation
+	if err = json.Unmarshal(data, &queue); err != nil {
+		return nil, fmt.Errorf("cannot parse queued operations %s: %w", path, err)
+	}
+	return queue, nil
+}
+
+// writeQueue stores the queued operations. The queue file is removed, when
+// the queue is empty.
+func writeQueue(path string, queue []QueuedOperation) error {
+	if len(queue) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("cannot remove queued operations: %w", err)
+		}
+		return nil
+	}
+	data, err := json.Marshal(queue)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot store queued operations: %w", err)
+	}
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("cannot store queued operations: %w", err)
+	}
+	return nil
+}
+
+// enqueue records an attempt of operation failed with errMsg at the time now.
+// An operation is queued once; its attempts are counted, and the time of its
+// first failure is kept.
+func enqueue(queue []QueuedOperation, operation string, errMsg string, now time.Time) []QueuedOperation {
+	i := slices.IndexFunc(queue, func(queued QueuedOperation) bool { return queued.Operation == operation })
+	if i < 0 {
+		queue = append(queue, QueuedOperation{Operation: operation, QueuedAt: now})
+		i = len(queue) - 1
+	}
+	queue[i].Attempts++
+	queue[i].Error = errMsg
+	return queue
+}
+
+// settle queues operation when it failed with errMsg, or removes it from the
+// queue when it succeeded (errMsg is empty).
+func settle(queue []QueuedOperation, operation string, errMsg string) []QueuedOperation {
+	if errMsg == "" {
+		return dequeue(queue, operation)
+	}
+	return enqueue(queue, operation, errMsg, time.Now())
+}
+
+// dequeue removes operations from the queue.
+func dequeue(queue []QueuedOperation, operations ...string) []QueuedOperation {
+	return slices.DeleteFunc(queue, func(queued QueuedOperation) bool {
+		return slices.Contains(operations, queued.Operation)
+	})
+}
+
+// isQueued reports whether any of operations is queued.
+func isQueued(queue []QueuedOperation, operations ...string) bool {
+	return slices.ContainsFunc(queue, func(queued QueuedOperation) bool {
+		return slices.Contains(operations, queued.Operation)
+	})
+}
+
+// expireQueue returns the operations queued for at most maxAge at the time
+// now, and the expired operations. A zero maxAge keeps all operations.
+func expireQueue(queue []QueuedOperation, now time.Time, maxAge time.Duration) ([]QueuedOperation, []QueuedOperation) {
+	if maxAge == 0 {
+		return queue, nil
+	}
+	var kept, expired []QueuedOperation
+	for _, queued := range queue {
+		if now.Sub(queued.QueuedAt) > maxAge {
+			expired = append(expired, queued)
+		} else {
+			kept = append(kept, queued)
+		}
+	}
+	return kept, expired
+}
+
+// loadQueue returns the queued operations which have not expired. Expired
+// operations are discarded with a warning, because the updates they carry
+// are outdated.
+func loadQueue(maxAge time.Duration) []QueuedOperation {
+	queue, err := readQueue(QueuePath)
+	if err != nil {
+		slog.Warn(err.Error())
+		return nil
+	}
+	queue, expired := expireQueue(queue, time.Now(), maxAge)
+	for _, queued := range expired {
+		slog.Warn(
+			"Discarding queued operation older than the maximum age",
+			"operation", queued.Operation,
+			"queued_at", queued.QueuedAt.Format(time.RFC3339),
+			"attempts", queued.Attempts,
+		)
+	}
+	return queue
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	first := time.Date(2025, 1, 31, 2, 0, 0, 0, time.UTC)
+
+	queue, err := readQueue(path)
+	if err != nil || len(queue) != 0 {
+		t.Fatalf("got (%v, %v) without queue file, want empty queue", queue, err)
+	}
+
+	queue = enqueue(queue, opUpdateFacts, "timed out", first)
+	queue = enqueue(queue, opInsightsCheckin, "timed out", first.Add(time.Minute))
+	queue = enqueue(queue, opUpdateFacts, "connection refused", first.Add(time.Hour))
+	want := []QueuedOperation{
+		{Operation: opUpdateFacts, QueuedAt: first, Attempts: 2, Error: "connection refused"},
+		{Operation: opInsightsCheckin, QueuedAt: first.Add(time.Minute), Attempts: 1, Error: "timed out"},
+	}
+	if !cmp.Equal(queue, want) {
+		t.Errorf("%v", cmp.Diff(queue, want))
+	}
+	if !isQueued(queue, opUpdateFacts, opDisplayName) || isQueued(queue, opDisplayName) {
+		t.Errorf("isQueued does not match %v", queue)
+	}
+
+	if err = writeQueue(path, queue); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+
+	if err = writeQueue(path, dequeue(got, opUpdateFacts, opInsightsCheckin)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("queue file exists with empty queue: %v", err)
+	}
+}
+
+func TestExpireQueue(t *testing.T) {
+	now := time.Date(2025, 1, 31, 2, 0, 0, 0, time.UTC)
+	queue := []QueuedOperation{
+		{Operation: opUpdateFacts, QueuedAt: now.Add(-48 * time.Hour)},
+		{Operation: opInsightsCheckin, QueuedAt: now.Add(-time.Hour)},
+	}
+
+	tests := []struct {
+		description string
+		maxAge      time.Duration
+		wantKept    []QueuedOperation
+		wantExpired []QueuedOperation
+	}{
+		{description: "unlimited", maxAge: 0, wantKept: queue},
+		{description: "none expired", maxAge: 72 * time.Hour, wantKept: queue},
+		{description: "oldest expired", maxAge: 24 * time.Hour, wantKept: queue[1:], wantExpired: queue[:1]},
+		{description: "all expired", maxAge: time.Minute, wantExpired: queue},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			kept, expired := expireQueue(queue, now, test.maxAge)
+			if !cmp.Equal(kept, test.wantKept) {
+				t.Errorf("kept: %v", cmp.Diff(kept, test.wantKept))
+			}
+			if !cmp.Equal(expired, test.wantExpired) {
+				t.Errorf("expired: %v", cmp.Diff(expired, test.wantExpired))
+			}
+		})
+	}
+}
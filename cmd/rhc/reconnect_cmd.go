@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+	"github.com/redhatinsights/rhc/pkg/feature"
+)
+
+// ReconnectResult is structure holding the results of the phases of
+// 'rhc reconnect'. A phase that has not been run is omitted. The result could
+// be printed in machine-readable format.
+type ReconnectResult struct {
+	Disconnect *DisconnectResult `json:"disconnect,omitempty"`
+	Connect    *ConnectResult    `json:"connect,omitempty"`
+}
+
+// reportPhase records result of cmd, when the command runs as a phase of
+// 'rhc reconnect'. It reports whether printing of the result is left to
+// 'rhc reconnect'.
+func reportPhase(cmd *cli.Command, result any) bool {
+	phases, ok := cmd.Root().Metadata[phaseResultsKey].(map[string]any)
+	if !ok {
+		return false
+	}
+	phases[cmd.Name] = result
+	return true
+}
+
+// reusedCredentials returns the organization and activation keys the system
+// was connected with, when they can be used to connect it again. The
+// organization is returned for systems connected with a username and password,
+// so only the credentials have to be entered.
+func reusedCredentials(connection *Connection, organization string, activationKeys []string) (string, []string) {
+	if connection == nil {
+		return "", nil
+	}
+	switch connection.Method {
+	case connectionActivationKey:
+		if organization == "" || len(activationKeys) == 0 {
+			return "", nil
+		}
+		return organization, activationKeys
+	case connectionCredentials:
+		return organization, nil
+	default:
+		return "", nil
+	}
+}
+
+// featureSelectionFlags returns the flags of 'rhc connect' restoring the
+// selection of features in state.
+func featureSelectionFlags(state *feature.State) [][2]string {
+	if state == nil {
+		return nil
+	}
+	var flags [][2]string
+	for _, id := range slices.Sorted(maps.Keys(state.Features)) {
+		if state.Features[id] {
+			flags = append(flags, [2]string{"enable-feature", id})
+		} else {
+			flags = append(flags, [2]string{"disable-feature", id})
+		}
+	}
+	return flags
+}
+
+// beforeReconnectAction ensures the user has supplied a correct `--format`
+// flag and a usable combination of credentials.
+func beforeReconnectAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+
+	if err = checkConnectFlags(
+		cmd.String("username"),
+		cmd.String("password"),
+		cmd.String("organization"),
+		cmd.StringSlice("activation-key"),
+	); err != nil {
+		return ctx, flagUsageError(err)
+	}
+	if err = checkCredentialInputs(credentialInputs(cmd)); err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// reconnectAction disconnects the system and connects it again. The
+// credentials and the selection of features of the previous connection are
+// reused, unless credentials are given by flags. All inputs are checked before
+// the system is disconnected, so it is not left disconnected waiting for them.
+func reconnectAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot reconnect system", exitcode.NoPerm)
+	}
+
+	connected, err := subman.HasConsumerCertificate()
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+	if !connected {
+		return cli.Exit("this system is not connected; use 'rhc connect' instead", exitcode.Usage)
+	}
+
+	username := cmd.String("username")
+	password := cmd.String("password")
+	organization := cmd.String("organization")
+	activationKeys := cmd.StringSlice("activation-key")
+	if username == "" && organization == "" && len(activationKeys) == 0 {
+		connection, err := getConnection()
+		if err != nil {
+			slog.Warn(err.Error())
+		}
+		var previousOrganization string
+		if cert, err := subman.GetConsumerCertificate(); err != nil {
+			slog.Warn("Unable to read organization", "err", err)
+		} else if len(cert.Subject.Organization) > 0 {
+			previousOrganization = cert.Subject.Organization[0]
+		}
+		previousKeys, err := readActivationKeys(ActivationKeysPath)
+		if err != nil {
+			slog.Warn(err.Error())
+		}
+		organization, activationKeys = reusedCredentials(connection, previousOrganization, previousKeys)
+	}
+
+	if isBatch(cmd) {
+		if missing := missingConnectInputs(username, password, activationKeys); len(missing) > 0 {
+			return missingInputsError(missing)
+		}
+	} else if !ui.CanPrompt() {
+		if (username == "" || password == "") && len(activationKeys) == 0 {
+			return cli.Exit(
+				"credentials of the connection cannot be reused; --username/--password or --organization/--activation-key are required when rhc cannot prompt for them (e.g. standard input is not a terminal)",
+				exitcode.Usage,
+			)
+		}
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return cli.Exit(fmt.Errorf("cannot get hostname: %w", err), exitcode.Err)
+	}
+	if err = confirmOperation(
+		fmt.Sprintf("Reconnect %v to Red Hat? It is registered again with a new identity.", hostname), true,
+	); err != nil {
+		return err
+	}
+
+	var connectFlags [][2]string
+	for _, flag := range [][2]string{{"username", username}, {"password", password}, {"organization", organization}} {
+		if flag[1] != "" {
+			connectFlags = append(connectFlags, flag)
+		}
+	}
+	for _, key := range activationKeys {
+		connectFlags = append(connectFlags, [2]string{"activation-key", key})
+	}
+	state, err := feature.LoadState(FeatureStatePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn(err.Error())
+	}
+	connectFlags = append(connectFlags, featureSelectionFlags(state)...)
+
+	var disconnectFlags [][2]string
+	if cmd.Bool("force") {
+		disconnectFlags = append(disconnectFlags, [2]string{"ignore-lock", "true"})
+	}
+
+	// The phases leave printing of their results to reconnect
+	phases := map[string]any{}
+	cmd.Root().Metadata[phaseResultsKey] = phases
+	var result ReconnectResult
+	defer func() {
+		delete(cmd.Root().Metadata, phaseResultsKey)
+		result.Disconnect, _ = phases["disconnect"].(*DisconnectResult)
+		result.Connect, _ = phases["connect"].(*ConnectResult)
+		if ui.IsOutputMachineReadable() {
+			if err := ui.PrintJSON(result); err != nil {
+				slog.Error(fmt.Sprintf("unable to print result as %s document: %s", cmd.String("format"), err))
+			}
+		}
+	}()
+
+	method := connectionCredentials
+	if len(activationKeys) > 0 {
+		method = connectionActivationKey
+	}
+	recordAudit("reconnect", map[string]string{"method": method})
+	if err = runSubcommand(ctx, cmd, "disconnect", disconnectFlags); err != nil {
+		return err
+	}
+	if connected, err = subman.HasConsumerCertificate(); err != nil || connected {
+		return cli.Exit("cannot reconnect: the system could not be disconnected", exitcode.Err)
+	}
+
+	ui.Printf("\n")
+	if err = runSubcommand(ctx, cmd, "connect", connectFlags); err != nil {
+		return err
+	}
+	if connected, err = subman.HasConsumerCertificate(); err != nil || !connected {
+		return cli.Exit("cannot reconnect: the system is disconnected", exitcode.Err)
+	}
+	slog.Info("Reconnected system")
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhatinsights/rhc/pkg/feature"
+)
+
+func TestReusedCredentials(t *testing.T) {
+	tests := []struct {
+		description      string
+		connection       *Connection
+		organization     string
+		activationKeys   []string
+		wantOrganization string
+		wantKeys         []string
+	}{
+		{
+			description:  "no connection",
+			organization: "123",
+		},
+		{
+			description:      "activation key",
+			connection:       &Connection{Method: connectionActivationKey},
+			organization:     "123",
+			activationKeys:   []string{"key1", "key2"},
+			wantOrganization: "123",
+			wantKeys:         []string{"key1", "key2"},
+		},
+		{
+			description:  "activation key without recorded keys",
+			connection:   &Connection{Method: connectionActivationKey},
+			organization: "123",
+		},
+		{
+			description:      "credentials",
+			connection:       &Connection{Method: connectionCredentials},
+			organization:     "123",
+			activationKeys:   []string{"key1"},
+			wantOrganization: "123",
+		},
+		{
+			description:    "cloud auto-registration",
+			connection:     &Connection{Method: connectionCloudAuto},
+			organization:   "123",
+			activationKeys: []string{"key1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			organization, keys := reusedCredentials(test.connection, test.organization, test.activationKeys)
+			if organization != test.wantOrganization {
+				t.Errorf("got organization %q, want %q", organization, test.wantOrganization)
+			}
+			if !cmp.Equal(keys, test.wantKeys) {
+				t.Errorf("%v", cmp.Diff(keys, test.wantKeys))
+			}
+		})
+	}
+}
+
+func TestFeatureSelectionFlags(t *testing.T) {
+	if got := featureSelectionFlags(nil); got != nil {
+		t.Errorf("got %v without selection, want nil", got)
+	}
+
+	state := &feature.State{Features: map[string]bool{
+		"remote-management": false,
+		"analytics":         true,
+		"content":           true,
+	}}
+	want := [][2]string{
+		{"enable-feature", "analytics"},
+		{"enable-feature", "content"},
+		{"disable-feature", "remote-management"},
+	}
+	got := featureSelectionFlags(state)
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// ReleaseResult is structure holding the release version of the system.
+// The result could be printed in machine-readable format.
+type ReleaseResult struct {
+	Release  string    `json:"release"`
+	Warnings []Warning `json:"warnings"`
+}
+
+// checkReleasePreconditions returns an error when the release cannot be managed:
+// the user is not root, or the system is not registered.
+func checkReleasePreconditions(requireRoot bool) error {
+	if requireRoot && os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot manage release version", exitcode.NoPerm)
+	}
+
+	client, err := subman.NewRHSMClient()
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("unable to check connection status: %s", err), exitcode.Software)
+	}
+	registered, err := client.IsRegistered()
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("unable to check connection status: %s", err), exitcode.Software)
+	}
+	if !registered {
+		return cli.Exit("this system is not connected", exitcode.Usage)
+	}
+	return nil
+}
+
+// beforeReleaseShowAction ensures the user has supplied a correct `--format` flag.
+func beforeReleaseShowAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// releaseShowAction prints the release version the system is locked to.
+func releaseShowAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if err := checkReleasePreconditions(false); err != nil {
+		return err
+	}
+
+	release, err := subman.GetRelease()
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.Err)
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(ReleaseResult{Release: release, Warnings: collectWarnings()}); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print release as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+
+	if release == "" {
+		ui.Printf("Release version is not set.\n")
+	} else {
+		ui.Printf("Release version: %s\n", release)
+	}
+	return nil
+}
+
+// beforeReleaseSetAction ensures exactly one VERSION argument has been passed in.
+func beforeReleaseSetAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	configureUI(cmd)
+
+	if cmd.Args().Len() != 1 {
+		return ctx, cli.Exit(
+			fmt.Sprintf("%s requires exactly one VERSION argument", getFullCommandName(cmd)),
+			exitcode.Usage,
+		)
+	}
+	return ctx, nil
+}
+
+// releaseSetAction locks the system to the release version given as argument.
+func releaseSetAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if err := checkReleasePreconditions(true); err != nil {
+		return err
+	}
+
+	release := cmd.Args().First()
+	err := ui.Spinner(func() error {
+		return subman.SetRelease(release)
+	}, ui.Indent.Small, "Setting release version...")
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.Err)
+	}
+
+	slog.Info("Release version set", "release", release)
+	ui.Printf("%s[%v] Release version set to %s\n", ui.Indent.Small, ui.Icons.Ok, release)
+	return nil
+}
+
+// beforeReleaseUnsetAction ensures no arguments have been passed in.
+func beforeReleaseUnsetAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	configureUI(cmd)
+
+	err := checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// releaseUnsetAction removes the release version lock of the system.
+func releaseUnsetAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if err := checkReleasePreconditions(true); err != nil {
+		return err
+	}
+
+	err := ui.Spinner(subman.UnsetRelease, ui.Indent.Small, "Unsetting release version...")
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.Err)
+	}
+
+	slog.Info("Release version unset")
+	ui.Printf("%s[%v] Release version unset\n", ui.Indent.Small, ui.Icons.Ok)
+	return nil
+}
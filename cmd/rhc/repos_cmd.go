@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// ReposListResult is structure holding the result of 'rhc repos list'.
+// The result could be printed in machine-readable format.
+type ReposListResult struct {
+	Repositories []subman.Repository `json:"repositories"`
+	Warnings     []Warning           `json:"warnings"`
+}
+
+// beforeReposListAction ensures the user has supplied a correct `--format` flag.
+func beforeReposListAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// reposListAction lists repositories provided by Red Hat Subscription Management
+// together with their status. With --enabled, only enabled repositories are listed.
+func reposListAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	repos, err := subman.ReadRepositories(subman.RedHatRepoPath)
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+
+	if cmd.Bool("enabled") {
+		var enabledRepos []subman.Repository
+		for _, repo := range repos {
+			if repo.Enabled {
+				enabledRepos = append(enabledRepos, repo)
+			}
+		}
+		repos = enabledRepos
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if repos == nil {
+			repos = []subman.Repository{}
+		}
+		result := ReposListResult{Repositories: repos, Warnings: collectWarnings()}
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print repositories as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+
+	if len(repos) == 0 {
+		ui.Printf("No repositories are available.\n")
+		return nil
+	}
+
+	var rows [][]string
+	for _, repo := range repos {
+		status := "disabled"
+		if repo.Enabled {
+			status = "enabled"
+		}
+		rows = append(rows, []string{repo.ID, status, repo.Name})
+	}
+	ui.PrintTable([]string{"ID", "STATUS", "NAME"}, rows)
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/redhatinsights/rhc/internal/audit"
+	"github.com/redhatinsights/rhc/internal/subman"
+)
+
+// ResultSignature is a signature of a result made with the identity key of the
+// host. Provisioning pipelines verify it with the key of Certificate, and match
+// the consumer UUID of Certificate with the host they provisioned.
+type ResultSignature struct {
+	// KeyID identifies the identity key, see audit.KeyID.
+	KeyID string `json:"key_id"`
+	// Certificate is the PEM-encoded identity certificate of the host.
+	Certificate string `json:"certificate"`
+	// Signature is the base64-encoded signature of the SHA-256 digest of the
+	// result without its signature, encoded as compact JSON (keys in the order
+	// of the document, without escaping of HTML characters).
+	Signature string `json:"signature"`
+}
+
+// resultDigest returns the SHA-256 digest of result encoded as compact JSON.
+func resultDigest(result any) ([]byte, error) {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(result); err != nil {
+		return nil, fmt.Errorf("cannot encode result: %w", err)
+	}
+	digest := sha256.Sum256(bytes.TrimSuffix(data.Bytes(), []byte("\n")))
+	return digest[:], nil
+}
+
+// signResult signs result, which has no signature set, with signer. The
+// certificate of signer is attached to the signature.
+func signResult(result any, signer crypto.Signer, certificate []byte) (*ResultSignature, error) {
+	keyID, err := audit.KeyID(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	digest, err := resultDigest(result)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signer.Sign(rand.Reader, digest, crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign result: %w", err)
+	}
+	return &ResultSignature{
+		KeyID:       keyID,
+		Certificate: string(certificate),
+		Signature:   base64.StdEncoding.EncodeToString(signature),
+	}, nil
+}
+
+// trySign signs the result of a successful connection with the identity key of
+// the host. A result of a failed connection is not signed, as the host has no
+// identity; the failure to sign is reported as a warning.
+func (connectResult *ConnectResult) trySign() {
+	var err error
+	if !connectResult.RHSMConnected {
+		err = fmt.Errorf("cannot sign result: system is not connected")
+	} else {
+		err = connectResult.sign()
+	}
+	if err != nil {
+		warning := Warning{Code: "result-signing", Message: err.Error()}
+		slog.Warn(warning.Message, "code", warning.Code)
+		connectResult.Warnings = append(connectResult.Warnings, warning)
+	}
+}
+
+// sign sets the signature of the result made with the identity key of the host.
+func (connectResult *ConnectResult) sign() error {
+	signer, _, err := identitySigner()
+	if err != nil {
+		return fmt.Errorf("cannot sign result: %w", err)
+	}
+	certificate, err := os.ReadFile(subman.ConsumerCertPath)
+	if err != nil {
+		return fmt.Errorf("cannot sign result: %w", err)
+	}
+	connectResult.Signature = nil
+	signature, err := signResult(connectResult, signer, certificate)
+	if err != nil {
+		return err
+	}
+	connectResult.Signature = signature
+	slog.Info("Result signed", "key_id", signature.KeyID)
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+func TestSignResult(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := ConnectResult{Hostname: "host<1>.example.com", RHSMConnected: true, Warnings: []Warning{}}
+	signature, err := signResult(&result, key, []byte("certificate"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signature.Certificate != "certificate" || signature.KeyID == "" {
+		t.Errorf("got signature %+v, want certificate and key ID", signature)
+	}
+	result.Signature = signature
+
+	// A verifier removes the signature from the printed document and encodes
+	// the rest as compact JSON
+	data, err := json.Marshal(&result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var printed ConnectResult
+	if err = json.Unmarshal(data, &printed); err != nil {
+		t.Fatal(err)
+	}
+	verify := func(result ConnectResult) bool {
+		result.Signature = nil
+		digest, err := resultDigest(&result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := base64.StdEncoding.DecodeString(signature.Signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ecdsa.VerifyASN1(&key.PublicKey, digest, raw)
+	}
+	if !verify(printed) {
+		t.Error("signature does not match the result")
+	}
+	printed.RHSMConnected = false
+	if verify(printed) {
+		t.Error("signature matches a modified result")
+	}
+}
+
+func TestTrySignNotConnected(t *testing.T) {
+	var result ConnectResult
+	result.trySign()
+	if result.Signature != nil {
+		t.Errorf("got signature %+v, want none", result.Signature)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Code != "result-signing" {
+		t.Errorf("got warnings %+v, want result-signing", result.Warnings)
+	}
+}
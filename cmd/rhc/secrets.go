@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// stdinSecret is the value of --password and --proxy-password reading the
+// password from the standard input.
+const stdinSecret = "-"
+
+// plainSecretsKey is the key of the metadata holding the secrets passed to
+// 'rhc connect' as plain arguments.
+const plainSecretsKey = "plain-secrets"
+
+// plainSecretFlags returns the flags of cmd holding secrets passed as plain
+// arguments, which end up in shell history and process listings.
+func plainSecretFlags(cmd *cli.Command) []string {
+	var flags []string
+	if cmd.IsSet("password") && cmd.String("password") != stdinSecret {
+		flags = append(flags, "--password")
+	}
+	if cmd.IsSet("activation-key") {
+		flags = append(flags, "--activation-key")
+	}
+	if cmd.IsSet("proxy-password") && cmd.String("proxy-password") != stdinSecret {
+		flags = append(flags, "--proxy-password")
+	}
+	return flags
+}
+
+// plainSecretWarnings returns warnings about secrets passed as plain arguments.
+func plainSecretWarnings(flags []string) []Warning {
+	var warnings []Warning
+	for _, flag := range flags {
+		alternative := "--password-file or --password -"
+		switch flag {
+		case "--activation-key":
+			alternative = "--activation-key-file"
+		case "--proxy-password":
+			alternative = "proxy.password-file or --proxy-password -"
+		}
+		warnings = append(warnings, Warning{
+			Code: "plain-secret",
+			Message: fmt.Sprintf(
+				"%s was passed as a plain argument, which may be recorded in shell history and shown in process listings; use %s instead",
+				flag, alternative,
+			),
+		})
+	}
+	return warnings
+}
+
+// readPassword returns the first line of r without its line ending. The rest
+// of the password is kept verbatim.
+func readPassword(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	password := strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	if password == "" {
+		return "", fmt.Errorf("the password is empty")
+	}
+	return password, nil
+}
+
+// parseActivationKeyList returns the activation keys listed in r, one per line.
+// Blank lines and lines starting with '#' are ignored.
+func parseActivationKeyList(r io.Reader) ([]string, error) {
+	var keys []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no activation key is listed")
+	}
+	return keys, nil
+}
+
+// readSecretFile reads a secret from the file at path with read.
+func readSecretFile[T any](path string, read func(io.Reader) (T, error)) (T, error) {
+	var zero T
+	file, err := os.Open(path)
+	if err != nil {
+		return zero, err
+	}
+	defer func() { _ = file.Close() }()
+	value, err := read(file)
+	if err != nil {
+		return zero, fmt.Errorf("%s: %w", path, err)
+	}
+	return value, nil
+}
+
+// applySecretInputs sets --password and --activation-key of 'rhc connect'
+// from --password-file, --activation-key-file or the standard input, so the
+// secrets are not passed as plain arguments.
+func applySecretInputs(cmd *cli.Command) error {
+	if cmd.IsSet("password-file") && cmd.IsSet("password") {
+		return fmt.Errorf("--password-file and --password can not be used together")
+	}
+	if cmd.IsSet("activation-key-file") && cmd.IsSet("activation-key") {
+		return fmt.Errorf("--activation-key-file and --activation-key can not be used together")
+	}
+	if cmd.String("password") == stdinSecret && cmd.String("proxy-password") == stdinSecret {
+		return fmt.Errorf("--password and --proxy-password can not both be read from the standard input")
+	}
+
+	var password string
+	var err error
+	switch {
+	case cmd.IsSet("password-file"):
+		password, err = readSecretFile(cmd.String("password-file"), readPassword)
+	case cmd.String("password") == stdinSecret:
+		password, err = readPassword(os.Stdin)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read password: %w", err)
+	}
+	if password != "" {
+		if err = cmd.Set("password", password); err != nil {
+			return err
+		}
+	}
+
+	if cmd.IsSet("activation-key-file") {
+		keys, err := readSecretFile(cmd.String("activation-key-file"), parseActivationKeyList)
+		if err != nil {
+			return fmt.Errorf("cannot read activation keys: %w", err)
+		}
+		for _, key := range keys {
+			if err = cmd.Set("activation-key", key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
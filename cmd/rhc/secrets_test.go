@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadPassword(t *testing.T) {
+	tests := []struct {
+		description string
+		input       string
+		want        string
+		wantError   bool
+	}{
+		{description: "line", input: "secret\n", want: "secret"},
+		{description: "without newline", input: "secret", want: "secret"},
+		{description: "crlf", input: "secret\r\n", want: "secret"},
+		{description: "spaces kept", input: " secret \n", want: " secret "},
+		{description: "first line only", input: "secret\nother\n", want: "secret"},
+		{description: "empty", input: "\n", wantError: true},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := readPassword(strings.NewReader(test.input))
+			if test.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseActivationKeyList(t *testing.T) {
+	got, err := parseActivationKeyList(strings.NewReader("# keys of the lab\nkey-one\n\n  key_two  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"key-one", "key_two"}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+
+	if _, err = parseActivationKeyList(strings.NewReader("# no keys\n")); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestPlainSecretWarnings(t *testing.T) {
+	got := plainSecretWarnings([]string{"--password", "--activation-key", "--proxy-password"})
+	if len(got) != 3 {
+		t.Fatalf("got %d warnings, want 3", len(got))
+	}
+	for i, alternative := range []string{"--password-file", "--activation-key-file", "proxy.password-file"} {
+		if got[i].Code != "plain-secret" || !strings.Contains(got[i].Message, alternative) {
+			t.Errorf("warning %d %v does not suggest %s", i, got[i], alternative)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/localization"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+	"github.com/redhatinsights/rhc/pkg/feature"
+)
+
+// selfTestChecks are the checks run by 'rhc self-test', in this order. They
+// verify the installation of rhc, not the state of the system, so they pass on
+// systems that have never been connected.
+var selfTestChecks = []doctorCheck{
+	{name: "config", run: checkConfigFiles},
+	{name: "defaults", run: checkDefaults},
+	{name: "dbus", run: checkDBus},
+	{name: "binaries", run: checkBinaries},
+	{name: "locale", run: checkLocale},
+}
+
+// requiredBinary is an executable run by rhc.
+type requiredBinary struct {
+	path string
+	// usedBy describes what needs the executable
+	usedBy string
+	// optional executables only disable a feature of rhc when missing
+	optional bool
+}
+
+// requiredBinaries are the executables checked by 'rhc self-test'.
+var requiredBinaries = []requiredBinary{
+	{path: "/usr/sbin/subscription-manager", usedBy: "content"},
+	{path: "/usr/bin/insights-client", usedBy: "analytics", optional: true},
+	{path: "/usr/bin/gpg", usedBy: "rhc config fetch and playbook verification keys", optional: true},
+	{path: "/usr/bin/tar", usedBy: "data collection", optional: true},
+}
+
+// checkDefaults verifies that the built-in defaults of the configuration are
+// valid, and that the features depend only on known features.
+func checkDefaults(ctx context.Context, cmd *cli.Command) DoctorCheck {
+	tree, err := toml.TreeFromMap(map[string]any{})
+	if err == nil {
+		err = validateConfigTree(tree)
+	}
+	if err != nil {
+		return DoctorCheck{
+			Status:  checkFailed,
+			Message: fmt.Sprintf("the built-in defaults of the configuration are invalid: %v", err),
+		}
+	}
+
+	check := DoctorCheck{Status: checkOK}
+	for _, f := range feature.All() {
+		for _, id := range append(f.Requires(), f.RequiredBy()...) {
+			if _, err = feature.Get(id); err != nil {
+				check.Status = checkFailed
+				check.Hints = append(check.Hints, fmt.Sprintf("feature %s depends on unknown %v", f.ID(), err))
+			}
+		}
+	}
+	if check.Status == checkFailed {
+		check.Message = "the built-in features are inconsistent"
+		return check
+	}
+	check.Message = fmt.Sprintf("the built-in configuration and %d features are valid", len(feature.All()))
+	return check
+}
+
+// checkBinaries verifies that the executables run by rhc are installed.
+func checkBinaries(ctx context.Context, cmd *cli.Command) DoctorCheck {
+	check := DoctorCheck{Status: checkOK}
+	var missing []string
+	for _, binary := range requiredBinaries {
+		info, err := os.Stat(binary.path)
+		if err == nil && (info.IsDir() || info.Mode().Perm()&0111 == 0) {
+			err = errors.New("not an executable file")
+		}
+		if err == nil {
+			continue
+		}
+		missing = append(missing, binary.path)
+		if binary.optional {
+			if check.Status == checkOK {
+				check.Status = checkWarning
+			}
+		} else {
+			check.Status = checkFailed
+		}
+		check.Hints = append(check.Hints, fmt.Sprintf("install %s, required by %s: %v", binary.path, binary.usedBy, err))
+	}
+	if len(missing) > 0 {
+		check.Message = fmt.Sprintf("%s cannot be run", strings.Join(missing, ", "))
+		return check
+	}
+	check.Message = fmt.Sprintf("%d executables are installed", len(requiredBinaries))
+	return check
+}
+
+// checkLocale verifies that values are formatted in the language of the locale.
+func checkLocale(ctx context.Context, cmd *cli.Command) DoctorCheck {
+	locale := localization.GetLocale()
+	switch {
+	case locale == "" || locale == "C" || locale == "POSIX" || strings.HasPrefix(locale, "C."):
+		return DoctorCheck{Status: checkOK, Message: "values are formatted in English"}
+	case !localization.IsSupported(locale):
+		return DoctorCheck{
+			Status:  checkWarning,
+			Message: fmt.Sprintf("the language of locale %s is not supported; values are formatted in English", locale),
+		}
+	default:
+		return DoctorCheck{Status: checkOK, Message: fmt.Sprintf("values are formatted in the language of locale %s", locale)}
+	}
+}
+
+// beforeSelfTestAction ensures the user has supplied a correct `--format` flag.
+func beforeSelfTestAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// selfTestAction verifies the runtime assumptions of rhc after it has been
+// installed, e.g. in %post of a kickstart or when an image is built.
+func selfTestAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+	return runChecks(ctx, cmd, selfTestChecks)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/redhatinsights/rhc/internal/localization"
+)
+
+func TestCheckBinaries(t *testing.T) {
+	dir := t.TempDir()
+	executable := filepath.Join(dir, "executable")
+	if err := os.WriteFile(executable, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	notExecutable := filepath.Join(dir, "not-executable")
+	if err := os.WriteFile(notExecutable, []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		description string
+		binaries    []requiredBinary
+		wantStatus  string
+	}{
+		{
+			description: "installed",
+			binaries:    []requiredBinary{{path: executable}},
+			wantStatus:  checkOK,
+		},
+		{
+			description: "optional missing",
+			binaries:    []requiredBinary{{path: executable}, {path: missing, optional: true}},
+			wantStatus:  checkWarning,
+		},
+		{
+			description: "required not executable",
+			binaries:    []requiredBinary{{path: notExecutable}, {path: missing, optional: true}},
+			wantStatus:  checkFailed,
+		},
+		{
+			description: "directory",
+			binaries:    []requiredBinary{{path: dir}},
+			wantStatus:  checkFailed,
+		},
+	}
+
+	saved := requiredBinaries
+	t.Cleanup(func() { requiredBinaries = saved })
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			requiredBinaries = test.binaries
+			check := checkBinaries(context.Background(), nil)
+			if check.Status != test.wantStatus {
+				t.Errorf("got status %q (%s), want %q", check.Status, check.Message, test.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCheckLocale(t *testing.T) {
+	tests := []struct {
+		locale     string
+		wantStatus string
+	}{
+		{locale: "C.UTF-8", wantStatus: checkOK},
+		{locale: "de_DE.UTF-8", wantStatus: checkOK},
+		{locale: "pt_BR.UTF-8", wantStatus: checkWarning},
+	}
+
+	t.Cleanup(func() { localization.SetLocale("") })
+	for _, test := range tests {
+		t.Run(test.locale, func(t *testing.T) {
+			localization.SetLocale(test.locale)
+			if check := checkLocale(context.Background(), nil); check.Status != test.wantStatus {
+				t.Errorf("got status %q (%s), want %q", check.Status, check.Message, test.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/cleanup"
+	"github.com/redhatinsights/rhc/internal/conf"
+	httpapi "github.com/redhatinsights/rhc/internal/http"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+	"github.com/redhatinsights/rhc/pkg/interactive"
+)
+
+// satelliteCACertPath is the path a Satellite serves its CA certificate at
+// over plain HTTP, so hosts can be bootstrapped before they trust it.
+const satelliteCACertPath = "/pub/katello-server-ca.crt"
+
+// satelliteCACertName is the name of the CA certificate of a Satellite in the
+// directory of CA certificates trusted by RHSM. It is the name used by the
+// katello-ca-consumer package.
+const satelliteCACertName = "katello-server-ca.pem"
+
+// maxCACertSize limits the size of a downloaded CA certificate.
+const maxCACertSize = 1 << 16
+
+// serverConfigKeys are the rhsm.conf options TryConfigureServer changes.
+var serverConfigKeys = []string{
+	"server.hostname", "server.port", "server.prefix", "rhsm.baseurl", "rhsm.repo_ca_cert",
+}
+
+// serverURLs returns the URLs of the entitlement server and of the content
+// delivery network 'rhc connect' configures; --server-url and --base-url
+// override 'server-url' and 'base-url' of the configuration file.
+func serverURLs(cmd *cli.Command) (string, string) {
+	if cmd.IsSet("server-url") {
+		return cmd.String("server-url"), cmd.String("base-url")
+	}
+	return conf.Config.ServerURL, conf.Config.BaseURL
+}
+
+// checkServerFlags validates --server-url, --base-url and --ca-fingerprint.
+func checkServerFlags(cmd *cli.Command) error {
+	if cmd.IsSet("base-url") && !cmd.IsSet("server-url") {
+		return fmt.Errorf("--base-url requires --server-url")
+	}
+	for _, name := range []string{"server-url", "base-url"} {
+		if !cmd.IsSet(name) {
+			continue
+		}
+		if parsed, err := url.Parse(cmd.String(name)); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return fmt.Errorf("--%s has to be an https URL", name)
+		}
+	}
+	if serverURL, _ := serverURLs(cmd); serverURL != "" && len(conf.Config.Servers) > 0 {
+		return fmt.Errorf("the entitlement server cannot be set, when servers are configured in the [servers] sections")
+	}
+	if cmd.IsSet("ca-fingerprint") {
+		if serverURL, _ := serverURLs(cmd); serverURL == "" {
+			return fmt.Errorf("--ca-fingerprint requires --server-url")
+		}
+		if cmd.IsSet("ca-cert") {
+			return fmt.Errorf("--ca-fingerprint cannot be used with --ca-cert")
+		}
+		sum, err := hex.DecodeString(normalizeFingerprint(cmd.String("ca-fingerprint")))
+		if err != nil || len(sum) != sha256.Size {
+			return fmt.Errorf("--ca-fingerprint has to be a SHA-256 fingerprint")
+		}
+	}
+	return nil
+}
+
+// certFingerprint returns the SHA-256 fingerprint of cert in the format of
+// 'openssl x509 -fingerprint -sha256', e.g. "AB:01:...".
+func certFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	pairs := make([]string, len(sum))
+	for i, b := range sum {
+		pairs[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(pairs, ":")
+}
+
+// normalizeFingerprint returns fingerprint in lower case without separators,
+// so fingerprints with and without colons compare equal.
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.NewReplacer(":", "", " ", "").Replace(strings.TrimSpace(fingerprint)))
+}
+
+// verifyChain reports whether chain presented by host is issued by roots.
+func verifyChain(chain []*x509.Certificate, host string, roots *x509.CertPool) bool {
+	if len(chain) == 0 {
+		return false
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{DNSName: host, Roots: roots, Intermediates: intermediates})
+	return err == nil
+}
+
+// parseCACerts returns the CA certificates of the PEM-encoded data.
+func parseCACerts(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse CA certificate: %w", err)
+		}
+		if cert.IsCA {
+			certs = append(certs, cert)
+		}
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM CA certificate found")
+	}
+	return certs, nil
+}
+
+// issuingCA returns the CA certificate of cas, which issues chain presented by
+// host, or nil, when none of them does.
+func issuingCA(chain []*x509.Certificate, host string, cas []*x509.Certificate) *x509.Certificate {
+	for _, ca := range cas {
+		roots := x509.NewCertPool()
+		roots.AddCert(ca)
+		if verifyChain(chain, host, roots) {
+			return ca
+		}
+	}
+	return nil
+}
+
+// trustServerCA checks that the user trusts ca of host. The SHA-256 fingerprint
+// of ca has to match fingerprint, given by --ca-fingerprint; without it, the
+// user is asked to compare the fingerprint with the one of the server, as the
+// certificate is downloaded over plain HTTP and can be replaced on the way.
+// --assume-yes does not trust the certificate.
+func trustServerCA(ca *x509.Certificate, host string, fingerprint string) error {
+	actual := certFingerprint(ca)
+	if fingerprint != "" {
+		if normalizeFingerprint(fingerprint) != normalizeFingerprint(actual) {
+			return fmt.Errorf("CA certificate of %s has SHA-256 fingerprint %s, not the one of --ca-fingerprint", host, actual)
+		}
+		return nil
+	}
+	trusted, err := interactive.ConfirmByUser(
+		fmt.Sprintf("Trust the CA certificate of %s?", host),
+		"subject: "+ca.Subject.String(),
+		"SHA-256 fingerprint: "+actual,
+	)
+	if errors.Is(err, interactive.ErrPromptDisabled) {
+		return fmt.Errorf(
+			"CA certificate of %s with SHA-256 fingerprint %s is not trusted; verify it and pass it by --ca-fingerprint",
+			host, actual,
+		)
+	}
+	if err != nil {
+		return err
+	}
+	if !trusted {
+		return fmt.Errorf("CA certificate of %s was not trusted", host)
+	}
+	return nil
+}
+
+// bootstrapServerCA installs the CA certificate of the Satellite at serverURL
+// into caCertDir as satelliteCACertName, unless the certificate of the server
+// is trusted already. The certificate is downloaded over plain HTTP, and it is
+// installed only when it issues the certificate the server presents, and it is
+// trusted by trustServerCA. The path of the installed file is returned; it is
+// empty when nothing was installed.
+func bootstrapServerCA(ctx context.Context, serverURL string, caCertDir string, fingerprint string) (string, error) {
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %q: %w", serverURL, err)
+	}
+	chain, err := httpapi.ProbeTLS(ctx, serverURL)
+	if err != nil {
+		return "", fmt.Errorf("cannot connect to %s: %w", parsed.Host, err)
+	}
+	if verifyChain(chain, parsed.Hostname(), loadTrustedRoots(caCertDir)) {
+		slog.Debug("Certificate of the entitlement server is trusted", "server", serverURL)
+		return "", nil
+	}
+
+	caURL := url.URL{Scheme: "http", Host: parsed.Hostname(), Path: satelliteCACertPath}
+	slog.Info("Downloading CA certificate of the entitlement server", "url", caURL.String())
+	data, err := httpapi.Fetch(ctx, caURL.String(), maxCACertSize)
+	if err != nil {
+		return "", fmt.Errorf("cannot download CA certificate of %s: %w", parsed.Host, err)
+	}
+	cas, err := parseCACerts(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid CA certificate of %s: %w", parsed.Host, err)
+	}
+	ca := issuingCA(chain, parsed.Hostname(), cas)
+	if ca == nil {
+		return "", fmt.Errorf("CA certificate served by %s does not issue its certificate", parsed.Host)
+	}
+	if err = trustServerCA(ca, parsed.Host, fingerprint); err != nil {
+		return "", err
+	}
+
+	// Only the verified CA is installed; other certificates of the bundle are
+	// not checked and could have been added on the way.
+	path := filepath.Join(caCertDir, satelliteCACertName)
+	if err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}), 0644); err != nil {
+		return "", fmt.Errorf("cannot install CA certificate: %w", err)
+	}
+	return path, nil
+}
+
+// saveServerConfig returns a function restoring the options of serverConfigKeys
+// in rhsm.conf to their current values.
+func saveServerConfig(client *subman.RHSMClient) (func() error, error) {
+	saved := make(map[string]string, len(serverConfigKeys))
+	for _, key := range serverConfigKeys {
+		value, err := client.GetConfigValue(key)
+		if err != nil {
+			return nil, err
+		}
+		saved[key] = value
+	}
+	return func() error {
+		var errs []error
+		for _, key := range serverConfigKeys {
+			errs = append(errs, client.SetConfigValue(key, saved[key]))
+		}
+		return errors.Join(errs...)
+	}, nil
+}
+
+// restoreFile returns a function restoring the file at path to its current
+// content; the file is removed, when it does not exist yet.
+func restoreFile(path string) func() error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cleanup.Remove(path)
+	}
+	return func() error {
+		return os.WriteFile(path, data, 0644)
+	}
+}
+
+// TryConfigureServer configures the entitlement server at serverURL and the
+// content delivery network at baseURL in rhsm.conf. Unless a CA certificate is
+// given by --ca-cert, the CA certificate of a Satellite is installed, when the
+// server is not trusted yet and the user trusts it, see trustServerCA; a
+// failure to do so is reported as a warning, as the registration reports the
+// untrusted server itself. The previous configuration is restored by
+// TryRestoreServer, or on exit, unless the system is registered.
+func (connectResult *ConnectResult) TryConfigureServer(
+	ctx context.Context, serverURL, baseURL string, caCert string, fingerprint string,
+) error {
+	client, err := subman.NewRHSMClient()
+	var restore, restoreCA func() error
+	if err == nil {
+		restore, err = saveServerConfig(client)
+	}
+	if err == nil {
+		connectResult.restoreServer = cleanup.Push("server-config", func() error {
+			if connectResult.RHSMConnected {
+				return nil
+			}
+			if restoreCA != nil {
+				return errors.Join(restoreCA(), restore())
+			}
+			return restore()
+		})
+		err = client.SetServer(serverURL, baseURL)
+	}
+	if err != nil {
+		err = fmt.Errorf("cannot configure entitlement server: %w", err)
+		slog.Error(err.Error())
+		if ui.IsOutputMachineReadable() {
+			connectResult.RHSMConnectError = err.Error()
+			connectResult.RHSMConnectErrorCode = "server-config"
+			return cli.Exit(connectResult, exitcode.Config)
+		}
+		return cli.Exit(err, exitcode.Config)
+	}
+	slog.Info("Configured entitlement server", "server", serverURL, "base_url", baseURL)
+	recordAudit("server-configure", map[string]string{"server": serverURL, "base_url": baseURL})
+	ui.Printf("%s[%v] Configured entitlement server %s\n", ui.Indent.Small, ui.Icons.Ok, serverURL)
+	if caCert != "" {
+		return nil
+	}
+
+	caCertDir, err := client.CACertDir()
+	if err != nil {
+		slog.Debug("Unable to read CA certificate directory, using default", "err", err)
+		caCertDir = subman.DefaultCACertDir
+	}
+	previousCA := restoreFile(filepath.Join(caCertDir, satelliteCACertName))
+	path, err := bootstrapServerCA(ctx, serverURL, caCertDir, fingerprint)
+	if err == nil && path != "" {
+		restoreCA = previousCA
+		slog.Info("Installed CA certificate of the entitlement server", "path", path)
+		recordAudit("ca-cert-install", map[string]string{"source": serverURL, "path": path})
+		ui.Printf("%s[%v] Installed CA certificate of %s\n", ui.Indent.Small, ui.Icons.Ok, serverURL)
+		// Content of a Satellite is served with the certificate of the server
+		err = client.SetConfigValue("rhsm.repo_ca_cert", "%(ca_cert_dir)s"+satelliteCACertName)
+	}
+	if err != nil {
+		warning := Warning{Code: "ca-bootstrap", Message: err.Error()}
+		slog.Warn(warning.Message, "code", warning.Code)
+		connectResult.Warnings = append(connectResult.Warnings, warning)
+		ui.Printf("%s[%v] Warning: %s\n", ui.Indent.Small, ui.Icons.Warning, warning.Message)
+	}
+	return nil
+}
+
+// TryRestoreServer restores the entitlement server configured before
+// TryConfigureServer, when the system was not registered with the new one.
+func (connectResult *ConnectResult) TryRestoreServer() {
+	if connectResult.restoreServer == nil || connectResult.RHSMConnected {
+		return
+	}
+	if err := connectResult.restoreServer(); err != nil {
+		warning := Warning{Code: "server-restore", Message: fmt.Sprintf("cannot restore entitlement server: %v", err)}
+		slog.Warn(warning.Message, "code", warning.Code)
+		connectResult.Warnings = append(connectResult.Warnings, warning)
+		ui.Printf("%s[%v] Warning: %s\n", ui.Indent.Medium, ui.Icons.Warning, warning.Message)
+		return
+	}
+	slog.Info("Restored previous entitlement server")
+	ui.Printf("%s[%v] Restored previous entitlement server\n", ui.Indent.Medium, ui.Icons.Ok)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/redhatinsights/rhc/pkg/interactive"
+)
+
+func TestParseCACerts(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	cert := server.Certificate()
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+
+	cas, err := parseCACerts(append([]byte("# CA of the server\n"), data...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cas[0])
+	if !verifyChain([]*x509.Certificate{cert}, "127.0.0.1", roots) {
+		t.Error("chain is not issued by the parsed CA certificate")
+	}
+	if verifyChain([]*x509.Certificate{cert}, "satellite.test", roots) {
+		t.Error("chain is valid for another host")
+	}
+
+	if _, err = parseCACerts([]byte("not a certificate")); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestTrustServerCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	cert := server.Certificate()
+	fingerprint := certFingerprint(cert)
+
+	if ca := issuingCA([]*x509.Certificate{cert}, "127.0.0.1", []*x509.Certificate{cert}); ca != cert {
+		t.Fatalf("got issuing CA %v, want the certificate of the server", ca)
+	}
+	for _, pin := range []string{fingerprint, normalizeFingerprint(fingerprint)} {
+		if err := trustServerCA(cert, "satellite.test", pin); err != nil {
+			t.Errorf("fingerprint %q: %v", pin, err)
+		}
+	}
+	other := strings.Repeat("00:", 31) + "00"
+	if err := trustServerCA(cert, "satellite.test", other); err == nil {
+		t.Error("expected error of another fingerprint, got nil")
+	}
+
+	interactive.Configure(interactive.Settings{AssumeYes: true})
+	defer interactive.Configure(interactive.Settings{})
+	if err := trustServerCA(cert, "satellite.test", ""); err == nil {
+		t.Error("expected error without fingerprint with --assume-yes, got nil")
+	}
+}
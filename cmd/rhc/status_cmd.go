@@ -2,14 +2,17 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/urfave/cli/v3"
 
 	"github.com/redhatinsights/rhc/internal/datacollection"
+	"github.com/redhatinsights/rhc/internal/localization"
 	"github.com/redhatinsights/rhc/internal/remotemanagement"
 	"github.com/redhatinsights/rhc/internal/subman"
 	"github.com/redhatinsights/rhc/internal/ui"
@@ -22,13 +25,18 @@ import (
 func rhsmStatus(systemStatus *SystemStatus) error {
 	slog.Info("Checking status of Red Hat Subscription Management")
 
+	var registered bool
 	client, err := subman.NewRHSMClient()
-	if err != nil {
-		systemStatus.returnCode += 1
-		systemStatus.RHSMError = err.Error()
-		return fmt.Errorf("unable to check registration status: %s", err)
+	if err == nil {
+		registered, err = client.IsRegistered()
+	}
+	if err != nil && !systemStatus.isPrivileged() {
+		// RHSM D-Bus API may be restricted to privileged users;
+		// the consumer certificate is world-readable.
+		slog.Debug("Unable to check registration status via D-Bus, checking consumer certificate", "err", err)
+		systemStatus.markLimited("rhsm")
+		registered, err = subman.HasConsumerCertificate()
 	}
-	registered, err := client.IsRegistered()
 	if err != nil {
 		systemStatus.returnCode += 1
 		systemStatus.RHSMError = err.Error()
@@ -39,28 +47,56 @@ func rhsmStatus(systemStatus *SystemStatus) error {
 		systemStatus.RHSMConnected = false
 		infoMsg := "Not connected to Red Hat Subscription Management"
 		slog.Info(infoMsg)
-		ui.Printf("%s[ ] %v\n", ui.Indent.Small, infoMsg)
+		ui.Printf("%s[ ] %v%s\n", ui.Indent.Small, infoMsg, systemStatus.limitedSuffix("rhsm"))
 	} else {
 		systemStatus.RHSMConnected = true
 		infoMsg := "Connected to Red Hat Subscription Management"
 		slog.Info(infoMsg)
-		ui.Printf("%s[%v] %v\n", ui.Indent.Small, ui.Icons.Ok, infoMsg)
+		ui.Printf("%s[%v] %v%s\n", ui.Indent.Small, ui.Icons.Ok, infoMsg, systemStatus.limitedSuffix("rhsm"))
+		connectionStatus(systemStatus)
 	}
 	return nil
 }
 
-// isContentEnabled reports whether the system has access to RHSM content.
+// connectionStatus prints how the system was connected.
+func connectionStatus(systemStatus *SystemStatus) {
+	connection, err := getConnection()
+	if err != nil {
+		slog.Warn(err.Error())
+		return
+	}
+	if connection == nil {
+		slog.Debug("Connection method is unknown")
+		return
+	}
+	systemStatus.Connection = connection
+	server := ""
+	if connection.Server != "" {
+		server = " to " + connection.Server
+	}
+	ui.Printf("%s[%v] Connection ... %s%s\n", ui.Indent.Medium, ui.Icons.Info, connection.describe(), server)
+}
+
+// isContentEnabled reports whether the system has access to RHSM content,
+// and how many repositories are enabled in redhat.repo.
 // It relies on systemStatus.RHSMConnected already being populated by rhsmStatus.
 func isContentEnabled(systemStatus *SystemStatus) error {
 	slog.Info("Checking content status")
 
+	var contentEnabled bool
 	client, err := subman.NewRHSMClient()
-	if err != nil {
-		systemStatus.returnCode += 1
-		systemStatus.ContentError = err.Error()
-		return fmt.Errorf("unable to check content management: %w", err)
+	if err == nil {
+		contentEnabled, err = client.IsContentManagementEnabled()
+	}
+	if err != nil && !systemStatus.isPrivileged() {
+		// RHSM D-Bus API may be restricted to privileged users;
+		// the repository file is world-readable.
+		slog.Debug("Unable to check content management via D-Bus, checking repository file", "err", err)
+		systemStatus.markLimited("content")
+		var repos []subman.Repository
+		repos, err = subman.ReadRepositories(subman.RedHatRepoPath)
+		contentEnabled = len(repos) > 0
 	}
-	contentEnabled, err := client.IsContentManagementEnabled()
 	if err != nil {
 		systemStatus.returnCode += 1
 		systemStatus.ContentError = err.Error()
@@ -69,14 +105,28 @@ func isContentEnabled(systemStatus *SystemStatus) error {
 
 	if contentEnabled && systemStatus.RHSMConnected {
 		systemStatus.ContentEnabled = true
-		infoMsg := "System has access to content"
-		slog.Info(infoMsg)
-		ui.Printf("%s[%v] Content ... %v\n", ui.Indent.Medium, ui.Icons.Ok, infoMsg)
+		repos, err := subman.ReadRepositories(subman.RedHatRepoPath)
+		if err != nil {
+			systemStatus.returnCode += 1
+			systemStatus.ContentError = err.Error()
+			return fmt.Errorf("unable to check enabled repositories: %w", err)
+		}
+		enabledRepos := subman.CountEnabledRepositories(repos)
+		systemStatus.EnabledRepos = &enabledRepos
+		if enabledRepos == 0 {
+			warnMsg := "System has access to content, but no repositories are enabled"
+			slog.Warn(warnMsg)
+			ui.Printf("%s[%v] Content ... %v\n", ui.Indent.Medium, ui.Icons.Warning, warnMsg)
+		} else {
+			infoMsg := fmt.Sprintf("System has access to content (%d of %d repositories enabled)", enabledRepos, len(repos))
+			slog.Info(infoMsg)
+			ui.Printf("%s[%v] Content ... %v%s\n", ui.Indent.Medium, ui.Icons.Ok, infoMsg, systemStatus.limitedSuffix("content"))
+		}
 	} else {
 		systemStatus.ContentEnabled = false
 		infoMsg := "System has no access to content"
 		slog.Info(infoMsg)
-		ui.Printf("%s[ ] Content ... %v\n", ui.Indent.Medium, infoMsg)
+		ui.Printf("%s[ ] Content ... %v%s\n", ui.Indent.Medium, infoMsg, systemStatus.limitedSuffix("content"))
 	}
 	return nil
 }
@@ -87,24 +137,47 @@ func insightStatus(systemStatus *SystemStatus) error {
 
 	var isRegistered bool
 	var err error
-	spinErr := ui.Spinner(func() error {
-		isRegistered, err = datacollection.InsightsClientIsRegistered()
-		return nil
-	}, ui.Indent.Medium, "Checking Red Hat Lightspeed (formerly Insights)...")
-	if spinErr != nil {
-		return spinErr
+	if systemStatus.isPrivileged() {
+		spinErr := ui.Spinner(func() error {
+			isRegistered, err = datacollection.InsightsClientIsRegistered()
+			return nil
+		}, ui.Indent.Medium, "Checking Red Hat Lightspeed (formerly Insights)...")
+		if spinErr != nil {
+			return spinErr
+		}
+	} else {
+		// insights-client cannot be run by unprivileged users
+		systemStatus.markLimited("insights")
+		isRegistered, err = datacollection.InsightsClientHasRegisteredMarker()
 	}
 
 	if isRegistered {
 		systemStatus.InsightsConnected = true
 		slog.Info("Connected to Red Hat Lightspeed")
-		ui.Printf("%s[%v] Analytics ... Connected to Red Hat Lightspeed (formerly Insights)\n", ui.Indent.Medium, ui.Icons.Ok)
+		lastUploadInfo := ""
+		lastUpload, err := datacollection.InsightsClientLastUpload()
+		if err != nil {
+			slog.Debug("Unable to get time of last upload", "err", err)
+		} else if !lastUpload.IsZero() {
+			systemStatus.InsightsLastUpload = &lastUpload
+			lastUploadInfo = fmt.Sprintf(", last upload %s", localization.FormatTimeAgo(localization.GetLocale(), time.Since(lastUpload)))
+		}
+		scheduling, err := datacollection.InsightsSchedulingEnabled()
+		if err != nil {
+			slog.Debug("Unable to get scheduling of insights-client", "err", err)
+		} else {
+			systemStatus.InsightsScheduling = &scheduling
+			if !scheduling {
+				lastUploadInfo += ", scheduled collection disabled"
+			}
+		}
+		ui.Printf("%s[%v] Analytics ... Connected to Red Hat Lightspeed (formerly Insights)%s%s\n", ui.Indent.Medium, ui.Icons.Ok, lastUploadInfo, systemStatus.limitedSuffix("insights"))
 	} else {
 		systemStatus.returnCode += 1
 		if err == nil {
 			systemStatus.InsightsConnected = false
 			slog.Info("Not connected to Red Hat Lightspeed")
-			ui.Printf("%s[ ] Analytics ... Not connected to Red Hat Lightspeed (formerly Insights)\n", ui.Indent.Medium)
+			ui.Printf("%s[ ] Analytics ... Not connected to Red Hat Lightspeed (formerly Insights)%s\n", ui.Indent.Medium, systemStatus.limitedSuffix("insights"))
 		} else {
 			systemStatus.InsightsConnected = false
 			systemStatus.InsightsError = err.Error()
@@ -151,6 +224,110 @@ func serviceStatus(systemStatus *SystemStatus) error {
 	return nil
 }
 
+// checkinStatus prints the schedule of periodic check-ins, and the operations
+// of failed check-ins queued for the following ones.
+func checkinStatus(systemStatus *SystemStatus) {
+	slog.Info("Checking check-in schedule")
+
+	s := checkinSchedule()
+	checkin := getCheckinSchedule()
+	systemStatus.Checkin = &checkin
+
+	locale := localization.GetLocale()
+	nextInfo := ""
+	if checkin.NextCheckin != nil {
+		nextInfo = fmt.Sprintf(", next %s", localization.FormatTimeUntil(locale, time.Until(*checkin.NextCheckin)))
+	}
+	ui.Printf(
+		"%s[%v] Check-in ... every %s (jitter %s, splay %s)%s\n",
+		ui.Indent.Medium,
+		ui.Icons.Info,
+		localization.FormatDuration(locale, s.Interval),
+		localization.FormatDuration(locale, s.Jitter),
+		localization.FormatDuration(locale, s.Splay),
+		nextInfo,
+	)
+
+	queue, err := readQueue(QueuePath)
+	if err != nil {
+		slog.Debug("Unable to read queued operations", "err", err)
+		return
+	}
+	systemStatus.QueuedOperations = len(queue)
+	if len(queue) > 0 {
+		ui.Printf(
+			"%s[%v] Queue ... %d operation(s) waiting for Red Hat services, oldest queued %s\n",
+			ui.Indent.Medium,
+			ui.Icons.Warning,
+			len(queue),
+			localization.FormatTimeAgo(locale, time.Since(queue[0].QueuedAt)),
+		)
+	}
+}
+
+// Base URLs of Red Hat web consoles.
+const (
+	consoleURL        = "https://console.redhat.com"
+	customerPortalURL = "https://access.redhat.com"
+)
+
+// ConsoleLinks are direct links to the records of the host in Red Hat web consoles.
+type ConsoleLinks struct {
+	Inventory     string `json:"inventory,omitempty"`
+	Advisor       string `json:"advisor,omitempty"`
+	Subscriptions string `json:"subscriptions,omitempty"`
+}
+
+// getConsoleLinks builds links to the records of the host. Without the Inventory ID,
+// the link to Inventory searches for the hostname, and no link to Advisor is built.
+func getConsoleLinks(consumerUUID, inventoryID, hostname string) ConsoleLinks {
+	var links ConsoleLinks
+	if inventoryID != "" {
+		links.Inventory = consoleURL + "/insights/inventory/" + url.PathEscape(inventoryID)
+		links.Advisor = consoleURL + "/insights/advisor/systems/" + url.PathEscape(inventoryID)
+	} else if hostname != "" {
+		links.Inventory = consoleURL + "/insights/inventory/?hostname_or_id=" + url.QueryEscape(hostname)
+	}
+	if consumerUUID != "" {
+		links.Subscriptions = customerPortalURL + "/management/systems/" + url.PathEscape(consumerUUID)
+	}
+	return links
+}
+
+// consoleLinksStatus prints links to the records of the host in Red Hat web consoles.
+// The consumer UUID is read from the world-readable consumer certificate.
+func consoleLinksStatus(systemStatus *SystemStatus) {
+	slog.Info("Building console links")
+
+	var consumerUUID string
+	if cert, err := subman.GetConsumerCertificate(); err == nil {
+		consumerUUID = cert.Subject.CommonName
+	} else {
+		slog.Debug("Unable to read consumer UUID", "err", err)
+	}
+	var inventoryID string
+	if systemStatus.InsightsConnected {
+		id, err := datacollection.InsightsInventoryID()
+		if err != nil {
+			slog.Debug("Unable to read Inventory ID", "err", err)
+		}
+		inventoryID = id
+	}
+
+	links := getConsoleLinks(consumerUUID, inventoryID, systemStatus.SystemHostname)
+	systemStatus.ConsoleLinks = &links
+
+	for _, link := range []struct{ name, url string }{
+		{"Inventory", links.Inventory},
+		{"Advisor", links.Advisor},
+		{"Subscriptions", links.Subscriptions},
+	} {
+		if link.url != "" {
+			ui.Printf("%s[%v] %s ... %s\n", ui.Indent.Medium, ui.Icons.Info, link.name, link.url)
+		}
+	}
+}
+
 // SystemStatus is structure holding information about system status
 // When more file format is supported, then add more tags for fields
 // like xml:"hostname"
@@ -161,17 +338,56 @@ type SystemStatus struct {
 	RHSMError         string `json:"rhsm_error,omitempty"`
 	ContentEnabled    bool   `json:"content_enabled"`
 	ContentError      string `json:"content_error,omitempty"`
+	EnabledRepos      *int   `json:"enabled_repos,omitempty"`
 	InsightsConnected bool   `json:"insights_connected"`
 	InsightsError     string `json:"insights_error,omitempty"`
-	YggdrasilRunning  bool   `json:"yggdrasil_running"`
-	YggdrasilError    string `json:"yggdrasil_error,omitempty"`
-	returnCode        int
+	// InsightsLastUpload is the time of the last successful upload of insights-client.
+	InsightsLastUpload *time.Time `json:"insights_last_upload,omitempty"`
+	// InsightsScheduling is whether the scheduled collection of insights-client
+	// is enabled, when it is known.
+	InsightsScheduling *bool  `json:"insights_scheduling,omitempty"`
+	YggdrasilRunning   bool   `json:"yggdrasil_running"`
+	YggdrasilError     string `json:"yggdrasil_error,omitempty"`
+	// Connection is how the system was connected, when it is known.
+	Connection *Connection `json:"connection,omitempty"`
+	// Checkin is the schedule of periodic check-ins of a connected system.
+	Checkin *CheckinSchedule `json:"checkin,omitempty"`
+	// QueuedOperations is the number of failed operations of check-ins
+	// waiting for Red Hat services to be reachable.
+	QueuedOperations int `json:"queued_operations"`
+	// ConsoleLinks are links to the records of the host, printed with --verbose.
+	ConsoleLinks *ConsoleLinks `json:"console_links,omitempty"`
+	// LimitedChecks lists checks performed without the privileges they
+	// require; their results rely on world-readable files only.
+	LimitedChecks []string  `json:"limited_checks,omitempty"`
+	Warnings      []Warning `json:"warnings"`
+	returnCode    int
+	uid           int
+}
+
+// isPrivileged returns true when the status is checked by the root user.
+func (systemStatus *SystemStatus) isPrivileged() bool {
+	return systemStatus.uid == 0
+}
+
+// markLimited records that the check was performed without required privileges.
+func (systemStatus *SystemStatus) markLimited(check string) {
+	systemStatus.LimitedChecks = append(systemStatus.LimitedChecks, check)
+}
+
+// limitedSuffix returns a note appended to the human-readable result of
+// the check, when the check was performed without required privileges.
+func (systemStatus *SystemStatus) limitedSuffix(check string) string {
+	if slices.Contains(systemStatus.LimitedChecks, check) {
+		return " (limited check)"
+	}
+	return ""
 }
 
 // printJSONStatus tries to print the system status as JSON to stdout.
 // When marshaling of systemStatus fails, then error is returned
 func printJSONStatus(systemStatus *SystemStatus) error {
-	data, err := json.MarshalIndent(systemStatus, "", "    ")
+	data, err := ui.MarshalJSON(systemStatus)
 	if err != nil {
 		return err
 	}
@@ -179,59 +395,10 @@ func printJSONStatus(systemStatus *SystemStatus) error {
 	return nil
 }
 
-// beforeStatusAction ensures the user has supplied a correct `--format` flag.
-func beforeStatusAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
-	err := checkFormatFlag(cmd)
-	if err != nil {
-		return ctx, err
-	}
-
-	configureUI(cmd)
-
-	return ctx, checkForUnknownArgs(cmd)
-}
-
-// statusAction tries to print status of system. It means that it gives
-// answer on following questions:
-// 1. Is system registered to Red Hat Subscription Management?
-// 2. Is system connected to Red Hat Lightspeed?
-// 3. Is yggdrasil.service (rhcd.service) running?
-// Status can be printed as human-readable text or machine-readable JSON document.
-// Format is influenced by --format json CLI option stored in CLI context
-func statusAction(ctx context.Context, cmd *cli.Command) (err error) {
-	logCommandStart(cmd)
-
-	var systemStatus SystemStatus
-	var machineReadablePrintFunc func(systemStatus *SystemStatus) error
-
-	format := cmd.String("format")
-	switch format {
-	case "json":
-		machineReadablePrintFunc = printJSONStatus
-	default:
-		break
-	}
-
-	// When printing of status is requested, then print machine-readable file format
-	// at the end of this function
-	if ui.IsOutputMachineReadable() {
-		defer func(systemStatus *SystemStatus) {
-			err = machineReadablePrintFunc(systemStatus)
-			// When it was not possible to print status to machine-readable format, then
-			// change returned error to CLI exit error to be able to set exit code to
-			// a non-zero value
-			if err != nil {
-				err = cli.Exit(
-					fmt.Errorf("unable to print status as %s document: %s", format, err.Error()),
-					exitcode.IOErr)
-			}
-			// When any of status is not correct, then return exitcode.Err exit code
-			if systemStatus.returnCode != 0 {
-				err = cli.Exit("", exitcode.Err)
-			}
-		}(&systemStatus)
-	}
-
+// checkStatus checks the connection of the system to Red Hat services, and
+// prints the results in human-readable format. With verbose, links to the
+// records of the system in Red Hat web consoles are printed too.
+func checkStatus(systemStatus *SystemStatus, verbose bool) error {
 	hostname, err := os.Hostname()
 	if err != nil {
 		if ui.IsOutputMachineReadable() {
@@ -246,7 +413,7 @@ func statusAction(ctx context.Context, cmd *cli.Command) (err error) {
 	slog.Info("Checking system connection status")
 
 	/* 1. Get Status of RHSM */
-	err = rhsmStatus(&systemStatus)
+	err = rhsmStatus(systemStatus)
 	if err != nil {
 		slog.Error(fmt.Sprintf("Cannot detect Red Hat Subscription Management status: %v", err))
 		ui.Printf(
@@ -258,7 +425,7 @@ func statusAction(ctx context.Context, cmd *cli.Command) (err error) {
 	}
 
 	/* 2. Is content enabled */
-	err = isContentEnabled(&systemStatus)
+	err = isContentEnabled(systemStatus)
 	if err != nil {
 		slog.Error(fmt.Sprintf("Cannot detect content management status: %v", err))
 		ui.Printf(
@@ -270,7 +437,7 @@ func statusAction(ctx context.Context, cmd *cli.Command) (err error) {
 	}
 
 	/* 3. Get status of insights-client */
-	err = insightStatus(&systemStatus)
+	err = insightStatus(systemStatus)
 	if err != nil {
 		slog.Error(fmt.Sprintf("Cannot detect Red Hat Lightspeed status: %v", err))
 		ui.Printf("%s[%v] Analytics ... Cannot detect Red Hat Lightspeed (formerly Insights) status: %v\n",
@@ -281,7 +448,7 @@ func statusAction(ctx context.Context, cmd *cli.Command) (err error) {
 	}
 
 	/* 3. Get status of yggdrasil (rhcd) service */
-	err = serviceStatus(&systemStatus)
+	err = serviceStatus(systemStatus)
 	if err != nil {
 		ui.Printf(
 			"%s[%s] Remote Management ... %s\n",
@@ -291,13 +458,120 @@ func statusAction(ctx context.Context, cmd *cli.Command) (err error) {
 		)
 	}
 
+	/* 4. Get schedule of check-ins */
+	if systemStatus.RHSMConnected {
+		checkinStatus(systemStatus)
+	}
+
+	/* 5. Print links to the web consoles */
+	if verbose && systemStatus.RHSMConnected {
+		consoleLinksStatus(systemStatus)
+	}
+
+	return nil
+}
+
+// beforeStatusAction ensures the user has supplied a correct `--format` flag.
+func beforeStatusAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	if cmd.IsSet("until") && !cmd.IsSet("since") {
+		return ctx, cli.Exit("--until requires --since", exitcode.Usage)
+	}
+	if cmd.IsSet("since") && cmd.Bool("upgrade-readiness") {
+		return ctx, cli.Exit("--since cannot be used with --upgrade-readiness", exitcode.Usage)
+	}
+	if err := checkWatchFlags(cmd); err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+
+	return ctx, checkForUnknownArgs(cmd)
+}
+
+// statusAction tries to print status of system. It means that it gives
+// answer on following questions:
+// 1. Is system registered to Red Hat Subscription Management?
+// 2. Is system connected to Red Hat Lightspeed?
+// 3. Is yggdrasil.service (rhcd.service) running?
+// Status can be printed as human-readable text or machine-readable JSON document.
+// Format is influenced by --format json CLI option stored in CLI context
+func statusAction(ctx context.Context, cmd *cli.Command) (err error) {
+	logCommandStart(cmd)
+
+	if cmd.Bool("upgrade-readiness") {
+		return upgradeReadinessAction(ctx, cmd)
+	}
+	if cmd.IsSet("since") {
+		return eventsAction(ctx, cmd)
+	}
+	if cmd.Bool("watch") {
+		return watchStatusAction(ctx, cmd)
+	}
+
+	systemStatus := SystemStatus{uid: os.Getuid(), Warnings: collectWarnings()}
+	var machineReadablePrintFunc func(systemStatus *SystemStatus) error
+
+	format := cmd.String("format")
+	switch format {
+	case "json":
+		machineReadablePrintFunc = printJSONStatus
+	default:
+		break
+	}
+
+	// When printing of status is requested, then print machine-readable file format
+	// at the end of this function
+	if ui.IsOutputMachineReadable() {
+		defer func(systemStatus *SystemStatus) {
+			err = machineReadablePrintFunc(systemStatus)
+			// When it was not possible to print status to machine-readable format, then
+			// change returned error to CLI exit error to be able to set exit code to
+			// a non-zero value
+			if err != nil {
+				err = cli.Exit(
+					fmt.Errorf("unable to print status as %s document: %s", format, err.Error()),
+					exitcode.IOErr)
+			}
+			// When any of status is not correct, then return exitcode.Err exit code
+			if err == nil && systemStatus.returnCode != 0 {
+				err = stateExit(cmd)
+			}
+		}(&systemStatus)
+	}
+
+	if err = checkStatus(&systemStatus, cmd.Bool("verbose")); err != nil {
+		return err
+	}
+
+	printWarnings(systemStatus.Warnings)
+
+	if len(systemStatus.LimitedChecks) > 0 {
+		ui.Printf("\nSome checks were limited, because they require root privileges.\n")
+	}
+
 	ui.Printf("\nManage your connected systems: https://red.ht/connector\n")
 
 	// At the end check if all statuses are correct.
 	// If not, return exitcode.Err exit code without any message.
 	if systemStatus.returnCode != 0 {
-		return cli.Exit("", exitcode.Err)
+		return stateExit(cmd)
 	}
 
 	return nil
 }
+
+// stateExit returns the error of status reporting a system, which is not
+// connected or not ready, by the exit code. With --exit-zero, nil is returned,
+// so monitoring using only the printed state is not mistaken by the exit code.
+func stateExit(cmd *cli.Command) error {
+	if cmd.Bool("exit-zero") {
+		slog.Debug("Exit code of the state suppressed by --exit-zero")
+		return nil
+	}
+	return cli.Exit("", exitcode.Err)
+}
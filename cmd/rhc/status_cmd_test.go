@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+func TestGetConsoleLinks(t *testing.T) {
+	tests := []struct {
+		description  string
+		consumerUUID string
+		inventoryID  string
+		hostname     string
+		want         ConsoleLinks
+	}{
+		{
+			description:  "all identifiers",
+			consumerUUID: "7a5b8e4c-0000-4000-8000-000000000001",
+			inventoryID:  "3c1d2e4f-0000-4000-8000-000000000002",
+			hostname:     "node1.example.com",
+			want: ConsoleLinks{
+				Inventory:     "https://console.redhat.com/insights/inventory/3c1d2e4f-0000-4000-8000-000000000002",
+				Advisor:       "https://console.redhat.com/insights/advisor/systems/3c1d2e4f-0000-4000-8000-000000000002",
+				Subscriptions: "https://access.redhat.com/management/systems/7a5b8e4c-0000-4000-8000-000000000001",
+			},
+		},
+		{
+			description:  "inventory searched by hostname",
+			consumerUUID: "7a5b8e4c-0000-4000-8000-000000000001",
+			hostname:     "node1.example.com",
+			want: ConsoleLinks{
+				Inventory:     "https://console.redhat.com/insights/inventory/?hostname_or_id=node1.example.com",
+				Subscriptions: "https://access.redhat.com/management/systems/7a5b8e4c-0000-4000-8000-000000000001",
+			},
+		},
+		{
+			description: "no identifiers",
+			want:        ConsoleLinks{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := getConsoleLinks(test.consumerUUID, test.inventoryID, test.hostname)
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
+
+func TestStatusWatcherChanged(t *testing.T) {
+	var watcher statusWatcher
+	steps := []struct {
+		status SystemStatus
+		want   bool
+	}{
+		{status: SystemStatus{SystemHostname: "host", RHSMConnected: false}, want: true},
+		{status: SystemStatus{SystemHostname: "host", RHSMConnected: false}, want: false},
+		{status: SystemStatus{SystemHostname: "host", RHSMConnected: true}, want: true},
+		{status: SystemStatus{SystemHostname: "host", RHSMConnected: true}, want: false},
+	}
+	for i, step := range steps {
+		got, err := watcher.changed(&step.status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != step.want {
+			t.Errorf("step %d: got %v, want %v", i, got, step.want)
+		}
+	}
+}
+
+func TestStateExit(t *testing.T) {
+	tests := []struct {
+		description string
+		args        []string
+		wantCode    int
+	}{
+		{description: "not connected", wantCode: exitcode.Err},
+		{description: "exit zero", args: []string{"--exit-zero"}, wantCode: exitcode.OK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			var got error
+			cmd := &cli.Command{
+				Name:  "status",
+				Flags: []cli.Flag{&cli.BoolFlag{Name: "exit-zero"}},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					got = stateExit(cmd)
+					return nil
+				},
+			}
+			if err := cmd.Run(context.Background(), append([]string{"status"}, test.args...)); err != nil {
+				t.Fatal(err)
+			}
+			code := exitcode.OK
+			var exitErr cli.ExitCoder
+			if errors.As(got, &exitErr) {
+				code = exitErr.ExitCode()
+			}
+			if code != test.wantCode {
+				t.Errorf("got exit code %d, want %d", code, test.wantCode)
+			}
+		})
+	}
+}
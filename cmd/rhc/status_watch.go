@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/localization"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// defaultWatchInterval is the interval the status is refreshed at by
+// 'rhc status --watch', unless set by --interval.
+const defaultWatchInterval = 5 * time.Second
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// StatusEvent is emitted by 'rhc status --watch' in machine-readable format,
+// one per line, when the status of the system changes.
+type StatusEvent struct {
+	Time   time.Time     `json:"time"`
+	Status *SystemStatus `json:"status"`
+}
+
+// statusWatcher emits events of changes of the status.
+type statusWatcher struct {
+	last []byte
+}
+
+// changed reports whether systemStatus differs from the status seen last time.
+func (w *statusWatcher) changed(systemStatus *SystemStatus) (bool, error) {
+	data, err := json.Marshal(systemStatus)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(data, w.last) {
+		return false, nil
+	}
+	w.last = data
+	return true, nil
+}
+
+// printStatusEvent prints event as a single line of JSON.
+func printStatusEvent(event StatusEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// checkWatchFlags ensures --watch and --interval are used together, and not
+// with other modes of 'rhc status'.
+func checkWatchFlags(cmd *cli.Command) error {
+	if !cmd.Bool("watch") {
+		if cmd.IsSet("interval") {
+			return fmt.Errorf("--interval requires --watch")
+		}
+		return nil
+	}
+	if cmd.IsSet("since") || cmd.Bool("upgrade-readiness") {
+		return fmt.Errorf("--watch cannot be used with --since or --upgrade-readiness")
+	}
+	if cmd.Duration("interval") < time.Second {
+		return fmt.Errorf("--interval has to be at least 1s")
+	}
+	return nil
+}
+
+// watchStatusAction refreshes the status of the system every --interval,
+// until it is interrupted. The status is redrawn in human-readable format; in
+// machine-readable format, an event is emitted on every change of the status.
+func watchStatusAction(ctx context.Context, cmd *cli.Command) error {
+	interval := cmd.Duration("interval")
+	slog.Info("Watching system connection status", "interval", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var watcher statusWatcher
+	for {
+		systemStatus := SystemStatus{uid: os.Getuid(), Warnings: collectWarnings()}
+		if ui.IsOutputRich() {
+			ui.Printf(clearScreen)
+		}
+		ui.Printf(
+			"Every %s, updated %s (press Ctrl+C to stop)\n\n",
+			localization.FormatDuration(localization.GetLocale(), interval),
+			time.Now().Format(time.TimeOnly),
+		)
+		if err := checkStatus(&systemStatus, cmd.Bool("verbose")); err != nil {
+			return err
+		}
+		printWarnings(systemStatus.Warnings)
+		ui.Printf("\n")
+
+		if ui.IsOutputMachineReadable() {
+			changed, err := watcher.changed(&systemStatus)
+			if err == nil && changed {
+				err = printStatusEvent(StatusEvent{Time: time.Now().UTC(), Status: &systemStatus})
+			}
+			if err != nil {
+				return cli.Exit(
+					fmt.Errorf("unable to print status as %s document: %s", cmd.String("format"), err.Error()),
+					exitcode.IOErr,
+				)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// lookupProfile returns the profile name configured in the '[profiles]' section.
+// A profile used by 'rhc switch' has to hold credentials, because the system
+// cannot be left disconnected waiting for input.
+func lookupProfile(name string) (conf.ProfileConf, error) {
+	profile, ok := conf.Config.Profiles[name]
+	if !ok {
+		if len(conf.Config.Profiles) == 0 {
+			return profile, fmt.Errorf("unknown profile %q: no profiles are configured", name)
+		}
+		return profile, fmt.Errorf(
+			"unknown profile %q: configured profiles are %s",
+			name,
+			strings.Join(slices.Sorted(maps.Keys(conf.Config.Profiles)), ", "),
+		)
+	}
+	if profile.Organization == "" || len(profile.ActivationKeys) == 0 {
+		return profile, fmt.Errorf("profile %q has to set 'organization' and 'activation-keys'", name)
+	}
+	if err := checkFeatureFlags(profile.EnableFeatures, profile.DisableFeatures); err != nil {
+		return profile, fmt.Errorf("profile %q: %w", name, err)
+	}
+	return profile, nil
+}
+
+// profileConnectFlags returns the flags of 'rhc connect' connecting the system
+// with the credentials and features of profile.
+func profileConnectFlags(profile conf.ProfileConf) [][2]string {
+	flags := [][2]string{{"organization", profile.Organization}}
+	for _, key := range profile.ActivationKeys {
+		flags = append(flags, [2]string{"activation-key", key})
+	}
+	for _, template := range profile.ContentTemplates {
+		flags = append(flags, [2]string{"content-template", template})
+	}
+	for _, id := range profile.EnableFeatures {
+		flags = append(flags, [2]string{"enable-feature", id})
+	}
+	for _, id := range profile.DisableFeatures {
+		flags = append(flags, [2]string{"disable-feature", id})
+	}
+	if profile.CACert != "" {
+		flags = append(flags, [2]string{"ca-cert", profile.CACert})
+	}
+	return flags
+}
+
+// runSubcommand runs the command name of the application with flags, as if it
+// was run from the command line.
+func runSubcommand(ctx context.Context, cmd *cli.Command, name string, flags [][2]string) error {
+	sub := cmd.Root().Command(name)
+	if sub == nil {
+		return fmt.Errorf("unknown command %q", name)
+	}
+	for _, flag := range flags {
+		if err := sub.Set(flag[0], flag[1]); err != nil {
+			return fmt.Errorf("cannot set --%s of %s: %w", flag[0], name, err)
+		}
+	}
+	if sub.Before != nil {
+		var err error
+		if ctx, err = sub.Before(ctx, sub); err != nil {
+			return err
+		}
+	}
+	return sub.Action(ctx, sub)
+}
+
+// beforeSwitchAction ensures the profile requested by --profile is usable.
+func beforeSwitchAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	configureUI(cmd)
+
+	if err := checkForUnknownArgs(cmd); err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	if !cmd.IsSet("profile") {
+		return ctx, cli.Exit("--profile is required", exitcode.Usage)
+	}
+	if _, err := lookupProfile(cmd.String("profile")); err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Config)
+	}
+	return ctx, nil
+}
+
+// switchAction disconnects the system, configures the entitlement server of
+// the profile requested by --profile, and connects the system again.
+func switchAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot switch profile", exitcode.NoPerm)
+	}
+
+	name := cmd.String("profile")
+	profile, err := lookupProfile(name)
+	if err != nil {
+		return cli.Exit(err.Error(), exitcode.Config)
+	}
+
+	connected, err := subman.HasConsumerCertificate()
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+	if !connected {
+		return cli.Exit("this system is not connected; use 'rhc connect' instead", exitcode.Usage)
+	}
+
+	rhsmClient, err := subman.NewRHSMClient()
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("unable to read RHSM configuration: %s", err), exitcode.Software)
+	}
+	previousServer, err := rhsmClient.ServerURL()
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("unable to read RHSM configuration: %s", err), exitcode.Software)
+	}
+	previousBaseURL, err := rhsmClient.GetConfigValue("rhsm.baseurl")
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("unable to read RHSM configuration: %s", err), exitcode.Software)
+	}
+	slog.Info("Switching profile", "profile", name, "from", previousServer, "to", profile.ServerURL)
+
+	var disconnectFlags [][2]string
+	if cmd.Bool("force") {
+		disconnectFlags = append(disconnectFlags, [2]string{"ignore-lock", "true"})
+	}
+	if err = runSubcommand(ctx, cmd, "disconnect", disconnectFlags); err != nil {
+		return err
+	}
+	if connected, err = subman.HasConsumerCertificate(); err != nil || connected {
+		return cli.Exit(
+			fmt.Sprintf("cannot switch to profile %q: the system could not be disconnected", name),
+			exitcode.Err,
+		)
+	}
+
+	if err = rhsmClient.SetServer(profile.ServerURL, profile.BaseURL); err != nil {
+		slog.Error(err.Error())
+		if restoreErr := rhsmClient.SetServer(previousServer, previousBaseURL); restoreErr != nil {
+			slog.Warn("Unable to restore RHSM configuration", "err", restoreErr)
+		}
+		return cli.Exit(
+			fmt.Sprintf("cannot switch to profile %q: cannot configure RHSM: %v; the system is disconnected", name, err),
+			exitcode.Software,
+		)
+	}
+	recordAudit("switch", map[string]string{"profile": name, "from": previousServer, "to": profile.ServerURL})
+
+	ui.Printf("\n")
+	if err = runSubcommand(ctx, cmd, "connect", profileConnectFlags(profile)); err != nil {
+		return err
+	}
+	if connected, err = subman.HasConsumerCertificate(); err != nil || !connected {
+		return cli.Exit(
+			fmt.Sprintf("cannot connect with profile %q; the system is disconnected", name),
+			exitcode.Err,
+		)
+	}
+	slog.Info("Switched profile", "profile", name)
+	return nil
+}
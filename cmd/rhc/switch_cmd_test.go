@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+)
+
+func TestLookupProfile(t *testing.T) {
+	oldProfiles := conf.Config.Profiles
+	conf.Config.Profiles = map[string]conf.ProfileConf{
+		"production": {ServerURL: "https://subscription.rhsm.redhat.com/subscription", Organization: "1234", ActivationKeys: []string{"key"}},
+		"staging":    {ServerURL: "https://satellite.example.com/rhsm"},
+		"invalid": {
+			ServerURL:       "https://satellite.example.com/rhsm",
+			Organization:    "1234",
+			ActivationKeys:  []string{"key"},
+			EnableFeatures:  []string{"analytics"},
+			DisableFeatures: []string{"analytics"},
+		},
+	}
+	t.Cleanup(func() { conf.Config.Profiles = oldProfiles })
+
+	tests := []struct {
+		name      string
+		wantError string
+	}{
+		{name: "production"},
+		{name: "staging", wantError: `profile "staging" has to set 'organization' and 'activation-keys'`},
+		{name: "invalid", wantError: `profile "invalid": invalid combination: enable 'analytics', disable 'analytics'`},
+		{name: "unknown", wantError: `unknown profile "unknown": configured profiles are invalid, production, staging`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := lookupProfile(test.name)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != test.wantError {
+				t.Errorf("got error %q, want %q", got, test.wantError)
+			}
+		})
+	}
+}
+
+func TestProfileConnectFlags(t *testing.T) {
+	profile := conf.ProfileConf{
+		ServerURL:        "https://satellite.example.com/rhsm",
+		CACert:           "/etc/rhsm/ca/katello-server-ca.pem",
+		Organization:     "1234",
+		ActivationKeys:   []string{"key1", "key2"},
+		ContentTemplates: []string{"rhel-9"},
+		DisableFeatures:  []string{"remote-management"},
+	}
+	want := [][2]string{
+		{"organization", "1234"},
+		{"activation-key", "key1"},
+		{"activation-key", "key2"},
+		{"content-template", "rhel-9"},
+		{"disable-feature", "remote-management"},
+		{"ca-cert", "/etc/rhsm/ca/katello-server-ca.pem"},
+	}
+	got := profileConnectFlags(profile)
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
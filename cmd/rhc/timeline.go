@@ -0,0 +1,59 @@
+package main
+
+import (
+	"time"
+)
+
+// Results of a step of an operation.
+const (
+	stepSucceeded = "succeeded"
+	stepFailed    = "failed"
+	stepSkipped   = "skipped"
+	stepUnchanged = "unchanged"
+)
+
+// TimelineStep is a step of an operation. The start of the step is the offset
+// from the start of the operation, so timelines of different runs (and
+// versions) of rhc can be compared step by step.
+type TimelineStep struct {
+	Step       string `json:"step"`
+	StartMS    int64  `json:"start_ms"`
+	DurationMS int64  `json:"duration_ms"`
+	Result     string `json:"result"`
+
+	start    time.Duration
+	duration time.Duration
+}
+
+// Timeline is the list of steps of an operation in the order they were run.
+type Timeline []TimelineStep
+
+// record appends step, which ran from start to end, to the timeline of an
+// operation started at started.
+func (t *Timeline) record(started time.Time, step string, start, end time.Time, result string) {
+	offset := start.Sub(started)
+	duration := end.Sub(start)
+	*t = append(*t, TimelineStep{
+		Step:       step,
+		StartMS:    offset.Milliseconds(),
+		DurationMS: duration.Milliseconds(),
+		Result:     result,
+		start:      offset,
+		duration:   duration,
+	})
+}
+
+// skip appends step, which was not run, to the timeline of an operation
+// started at started.
+func (t *Timeline) skip(started time.Time, step string, now time.Time) {
+	t.record(started, step, now, now, stepSkipped)
+}
+
+// stepResult returns the result of a step, which succeeded when successful
+// is true.
+func stepResult(successful bool) string {
+	if successful {
+		return stepSucceeded
+	}
+	return stepFailed
+}
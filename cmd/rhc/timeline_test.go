@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimelineRecord(t *testing.T) {
+	started := time.Date(2025, 1, 31, 2, 0, 0, 0, time.UTC)
+	var timeline Timeline
+	timeline.record(started, "rhsm", started.Add(10*time.Millisecond), started.Add(1510*time.Millisecond), stepResult(true))
+	timeline.skip(started, "insights", started.Add(1520*time.Millisecond))
+	timeline.record(started, "yggdrasil", started.Add(1520*time.Millisecond), started.Add(2*time.Second), stepResult(false))
+
+	data, err := json.Marshal(timeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"step":"rhsm","start_ms":10,"duration_ms":1500,"result":"succeeded"},` +
+		`{"step":"insights","start_ms":1520,"duration_ms":0,"result":"skipped"},` +
+		`{"step":"yggdrasil","start_ms":1520,"duration_ms":480,"result":"failed"}]`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// stepTimeout returns the time limit of a step of connect or disconnect;
+// --timeout overrides the limit configured in the '[timeouts]' section.
+func stepTimeout(cmd *cli.Command, configured time.Duration) time.Duration {
+	if cmd.IsSet("timeout") {
+		return cmd.Duration("timeout")
+	}
+	return configured
+}
+
+// stepContext returns a copy of ctx, which is done after timeout. A zero
+// timeout does not limit the step.
+func stepContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timeoutCode returns the error code "timeout", when err is caused by a step
+// not finished within its time limit.
+func timeoutCode(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	return ""
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestStepContext(t *testing.T) {
+	ctx, cancel := stepContext(context.Background(), 0)
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("zero timeout set a deadline")
+	}
+
+	ctx, cancel = stepContext(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	if got := timeoutCode(fmt.Errorf("registering: %w", ctx.Err())); got != "timeout" {
+		t.Errorf("got %q, want %q", got, "timeout")
+	}
+
+	ctx, cancel = stepContext(context.Background(), time.Hour)
+	cancel()
+	if got := timeoutCode(ctx.Err()); got != "" {
+		t.Errorf("canceled step: got %q, want %q", got, "")
+	}
+}
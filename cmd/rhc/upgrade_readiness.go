@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/datacollection"
+	httpapi "github.com/redhatinsights/rhc/internal/http"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// UpgradeReadinessResult is structure holding pre-upgrade findings of the system.
+// The result could be printed in machine-readable format.
+type UpgradeReadinessResult struct {
+	Hostname string `json:"hostname"`
+	datacollection.UpgradeReadiness
+	Warnings []Warning `json:"warnings"`
+}
+
+// consumerTLSConfig returns TLS configuration authenticating with the consumer
+// certificate of the system.
+func consumerTLSConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(subman.ConsumerCertPath, subman.ConsumerKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load consumer certificate: %w", err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+// apiHTTPClient returns an HTTP client of the Red Hat API. The client
+// authenticates with the service account of the '[api]' section of the
+// configuration file, when it is configured, or with the consumer certificate
+// of the system otherwise; the certificate is reloaded, when it is rejected.
+func apiHTTPClient() (*http.Client, error) {
+	if apiConf := conf.Config.API; apiConf.ClientID != "" {
+		slog.Debug("Authenticating with service account", "client_id", apiConf.ClientID)
+		return httpapi.NewServiceAccountHTTPClient(httpapi.ServiceAccount{
+			APIURL:           apiConf.URL,
+			TokenURL:         apiConf.TokenURL,
+			ClientID:         apiConf.ClientID,
+			ClientSecretFile: apiConf.ClientSecretFile,
+		}), nil
+	}
+	tlsConfig, err := consumerTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	return httpapi.NewReauthHTTPClient(tlsConfig, consumerTLSConfig), nil
+}
+
+// upgradeReadinessAction queries Red Hat Lightspeed advisor for findings relevant
+// to in-place upgrade of the system, and summarizes the blockers.
+func upgradeReadinessAction(ctx context.Context, cmd *cli.Command) error {
+	if os.Getuid() != 0 {
+		return cli.Exit("non-root user cannot check upgrade readiness", exitcode.NoPerm)
+	}
+
+	hostname, _ := os.Hostname()
+	result := UpgradeReadinessResult{Hostname: hostname, Warnings: collectWarnings()}
+
+	machineID, err := datacollection.InsightsMachineID()
+	if errors.Is(err, datacollection.ErrSystemNotFound) {
+		return cli.Exit("this system is not connected to Red Hat Lightspeed", exitcode.Usage)
+	}
+	if err != nil {
+		return cli.Exit(err, exitcode.Software)
+	}
+	client, err := apiHTTPClient()
+	if err != nil {
+		return cli.Exit(err, exitcode.NoInput)
+	}
+
+	slog.Info("Checking upgrade readiness", "machine_id", machineID)
+	err = ui.Spinner(func() error {
+		result.UpgradeReadiness, err = datacollection.GetUpgradeReadiness(ctx, client, machineID)
+		return err
+	}, ui.Indent.Small, "Checking pre-upgrade findings...")
+	if err != nil {
+		slog.Error("Cannot check upgrade readiness", "err", err)
+		return cli.Exit(fmt.Sprintf("unable to check upgrade readiness: %s", err), exitcode.Unavailable)
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print upgrade readiness as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+	} else {
+		printUpgradeReadiness(&result)
+	}
+
+	if !result.Ready {
+		return stateExit(cmd)
+	}
+	return nil
+}
+
+// printUpgradeReadiness prints the blockers and other findings in human-readable form.
+func printUpgradeReadiness(result *UpgradeReadinessResult) {
+	ui.Printf("Upgrade readiness of %v:\n\n", result.Hostname)
+	if result.Ready {
+		ui.Printf("%s[%v] No blockers of in-place upgrade found\n", ui.Indent.Small, ui.Icons.Ok)
+	} else {
+		ui.Printf("%s[%v] In-place upgrade is blocked by %d finding(s)\n", ui.Indent.Small, ui.Icons.Error, len(result.Blockers))
+		for _, finding := range result.Blockers {
+			ui.Printf("%s[%v] %s (%s)\n", ui.Indent.Medium, ui.Icons.Error, finding.Description, finding.RuleID)
+		}
+	}
+	for _, finding := range result.Findings {
+		ui.Printf("%s[%v] %s (%s)\n", ui.Indent.Medium, ui.Icons.Info, finding.Description, finding.RuleID)
+	}
+
+	printWarnings(result.Warnings)
+
+	ui.Printf("\nReview the findings: https://console.redhat.com/insights/advisor\n")
+}
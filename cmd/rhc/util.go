@@ -12,6 +12,8 @@ import (
 	"github.com/urfave/cli/v3"
 	"golang.org/x/sys/unix"
 
+	"github.com/redhatinsights/rhc/internal/audit"
+	"github.com/redhatinsights/rhc/internal/conf"
 	"github.com/redhatinsights/rhc/pkg/exitcode"
 )
 
@@ -81,6 +83,12 @@ func checkForUnknownArgs(cmd *cli.Command) error {
 
 // checkFormatFlag ensures the user has supplied a correct `--format` flag.
 func checkFormatFlag(cmd *cli.Command) error {
+	// The format set in the configuration file applies unless --format is used
+	if !cmd.IsSet("format") && conf.Config.Format != "" {
+		if err := cmd.Set("format", conf.Config.Format); err != nil {
+			return cli.Exit(err, exitcode.Software)
+		}
+	}
 	format := cmd.String("format")
 	switch format {
 	case "", "json":
@@ -116,6 +124,28 @@ func logCommandStart(cmd *cli.Command) {
 	slog.Info(fmt.Sprintf("Command '%s' started", fullCommandName))
 }
 
+// recordAudit appends an entry about a change made to the system to the audit log.
+// When signing is enabled, the entry is signed with the identity key of the host;
+// without the key, the change is still recorded, but the unsigned entry fails
+// 'rhc audit verify'. Failure to write the audit log is logged, but it does not
+// fail the command.
+func recordAudit(action string, details map[string]string) {
+	entry := audit.NewEntry(action, details)
+	if conf.Config.Audit.Sign {
+		signer, cert, err := identitySigner()
+		if err == nil {
+			if err = audit.AppendSigned(AuditLogPath, entry, signer, cert); err != nil {
+				slog.Warn("Unable to record audit entry", "action", action, "err", err)
+			}
+			return
+		}
+		slog.Warn("Unable to sign audit entry", "action", action, "err", err)
+	}
+	if err := audit.Append(AuditLogPath, entry); err != nil {
+		slog.Warn("Unable to record audit entry", "action", action, "err", err)
+	}
+}
+
 // validateCollectorCommand performs common validation for collector commands.
 func validateCollectorCommand(cmd *cli.Command, requiresCollectorID, requiresFormat bool) error {
 	if requiresFormat {
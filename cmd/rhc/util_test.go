@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+)
+
+func TestCheckFormatFlag(t *testing.T) {
+	tests := []struct {
+		description   string
+		args          []string
+		defaultFormat string
+		want          string
+		wantError     bool
+	}{
+		{description: "no format", want: ""},
+		{description: "format of configuration file", defaultFormat: "json", want: "json"},
+		{description: "flag overrides configuration file", args: []string{"--format", "xml"}, defaultFormat: "json", wantError: true},
+		{description: "flag", args: []string{"--format", "json"}, want: "json"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			oldFormat := conf.Config.Format
+			conf.Config.Format = test.defaultFormat
+			t.Cleanup(func() { conf.Config.Format = oldFormat })
+
+			var got string
+			var err error
+			cmd := &cli.Command{
+				Name:  "status",
+				Flags: []cli.Flag{&cli.StringFlag{Name: "format"}},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					err = checkFormatFlag(cmd)
+					got = cmd.String("format")
+					return nil
+				},
+			}
+			if runErr := cmd.Run(context.Background(), append([]string{"status"}, test.args...)); runErr != nil {
+				t.Fatal(runErr)
+			}
+			if test.wantError {
+				if err == nil {
+					t.Errorf("expected error, got format %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
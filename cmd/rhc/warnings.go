@@ -0,0 +1,103 @@
+package main
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/conf"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+)
+
+// certExpirationThreshold is the remaining validity of the consumer certificate
+// below which a warning is reported.
+const certExpirationThreshold = 30 * 24 * time.Hour
+
+// Warning is an advisory included in machine-readable results. Unlike errors,
+// warnings do not indicate a failure of the operation.
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// collectWarnings returns advisories about the environment the command runs in:
+// missing privileges, execution inside a container, consumer certificate close
+// to expiration, clock skew, and audit entries that cannot be signed.
+func collectWarnings() []Warning {
+	warnings := []Warning{}
+
+	if os.Getuid() != 0 {
+		warnings = append(warnings, Warning{
+			Code:    "non-root",
+			Message: "running as non-root user, some information may be limited",
+		})
+	}
+
+	if manager := detectContainer(); manager != "" {
+		warnings = append(warnings, Warning{
+			Code:    "container",
+			Message: fmt.Sprintf("running inside a container (%s), services of the system may not be manageable", manager),
+		})
+	}
+
+	cert, err := subman.GetConsumerCertificate()
+	if err == nil {
+		warnings = append(warnings, certificateWarnings(cert, time.Now())...)
+		if conf.Config.Audit.Sign && os.Getuid() == 0 {
+			if _, _, err = identitySigner(); err != nil {
+				warnings = append(warnings, Warning{
+					Code:    "audit-unsigned",
+					Message: fmt.Sprintf("audit entries are recorded unsigned and fail 'rhc audit verify': %v", err),
+				})
+			}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		slog.Debug("Unable to check consumer certificate", "err", err)
+	}
+
+	for _, warning := range warnings {
+		slog.Warn(warning.Message, "code", warning.Code)
+	}
+	return warnings
+}
+
+// certificateWarnings returns advisories about validity of the consumer certificate at time now.
+func certificateWarnings(cert *x509.Certificate, now time.Time) []Warning {
+	var warnings []Warning
+	if now.Before(cert.NotBefore) {
+		warnings = append(warnings, Warning{
+			Code: "clock-skew",
+			Message: fmt.Sprintf(
+				"system clock is behind: consumer certificate is valid since %s",
+				cert.NotBefore.Format(time.RFC3339),
+			),
+		})
+	}
+	if remaining := cert.NotAfter.Sub(now); remaining < 0 {
+		warnings = append(warnings, Warning{
+			Code:    "cert-expired",
+			Message: fmt.Sprintf("consumer certificate expired on %s", cert.NotAfter.Format(time.RFC3339)),
+		})
+	} else if remaining < certExpirationThreshold {
+		warnings = append(warnings, Warning{
+			Code:    "cert-expiring-soon",
+			Message: fmt.Sprintf("consumer certificate expires on %s", cert.NotAfter.Format(time.RFC3339)),
+		})
+	}
+	return warnings
+}
+
+// printWarnings prints warnings in human-readable format.
+func printWarnings(warnings []Warning) {
+	if len(warnings) == 0 {
+		return
+	}
+	ui.Printf("\n")
+	for _, warning := range warnings {
+		ui.Printf("%s[%v] Warning: %s\n", ui.Indent.Small, ui.Icons.Warning, warning.Message)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCertificateWarnings(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		description string
+		notBefore   time.Time
+		notAfter    time.Time
+		want        []string
+	}{
+		{
+			description: "valid certificate",
+			notBefore:   now.AddDate(-1, 0, 0),
+			notAfter:    now.AddDate(1, 0, 0),
+			want:        nil,
+		},
+		{
+			description: "certificate expiring soon",
+			notBefore:   now.AddDate(-1, 0, 0),
+			notAfter:    now.AddDate(0, 0, 7),
+			want:        []string{"cert-expiring-soon"},
+		},
+		{
+			description: "expired certificate",
+			notBefore:   now.AddDate(-1, 0, 0),
+			notAfter:    now.AddDate(0, 0, -1),
+			want:        []string{"cert-expired"},
+		},
+		{
+			description: "clock behind certificate validity",
+			notBefore:   now.AddDate(0, 0, 1),
+			notAfter:    now.AddDate(1, 0, 0),
+			want:        []string{"clock-skew"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cert := &x509.Certificate{NotBefore: test.notBefore, NotAfter: test.notAfter}
+			var got []string
+			for _, warning := range certificateWarnings(cert, now) {
+				got = append(got, warning.Code)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
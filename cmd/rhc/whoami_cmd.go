@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/ui"
+	"github.com/redhatinsights/rhc/pkg/exitcode"
+)
+
+// WhoamiResult is structure holding the identity of the system printed by
+// 'rhc whoami'. The result could be printed in machine-readable format.
+type WhoamiResult struct {
+	ConsumerUUID string      `json:"consumer_uuid"`
+	Organization string      `json:"organization,omitempty"`
+	Connection   *Connection `json:"connection,omitempty"`
+	Warnings     []Warning   `json:"warnings"`
+}
+
+// beforeWhoamiAction ensures the user has supplied a correct `--format` flag.
+func beforeWhoamiAction(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	err := checkFormatFlag(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	configureUI(cmd)
+
+	err = checkForUnknownArgs(cmd)
+	if err != nil {
+		return ctx, cli.Exit(err.Error(), exitcode.Usage)
+	}
+	return ctx, nil
+}
+
+// whoamiAction prints the identity of the connected system and how it was
+// connected. The consumer certificate is world-readable, so the command does
+// not require root privileges.
+func whoamiAction(ctx context.Context, cmd *cli.Command) error {
+	logCommandStart(cmd)
+
+	connected, err := subman.HasConsumerCertificate()
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.IOErr)
+	}
+	if !connected {
+		return cli.Exit("this system is not connected", exitcode.Usage)
+	}
+	cert, err := subman.GetConsumerCertificate()
+	if err != nil {
+		slog.Error(err.Error())
+		return cli.Exit(err, exitcode.DataErr)
+	}
+
+	result := WhoamiResult{ConsumerUUID: cert.Subject.CommonName, Warnings: collectWarnings()}
+	if len(cert.Subject.Organization) > 0 {
+		result.Organization = cert.Subject.Organization[0]
+	}
+	if result.Connection, err = getConnection(); err != nil {
+		slog.Warn(err.Error())
+	}
+
+	if ui.IsOutputMachineReadable() {
+		if err = ui.PrintJSON(result); err != nil {
+			return cli.Exit(
+				fmt.Errorf("unable to print identity as %s document: %s", cmd.String("format"), err.Error()),
+				exitcode.IOErr,
+			)
+		}
+		return nil
+	}
+
+	ui.Printf("Consumer UUID:  %s\n", result.ConsumerUUID)
+	if result.Organization != "" {
+		ui.Printf("Organization:   %s\n", result.Organization)
+	}
+	if result.Connection == nil {
+		ui.Printf("Connected with: unknown\n")
+	} else {
+		ui.Printf("Connected with: %s\n", result.Connection.describe())
+		if result.Connection.Server != "" {
+			ui.Printf("Server:         %s\n", result.Connection.Server)
+		}
+		if result.Connection.ConnectedAt != nil {
+			ui.Printf("Connected at:   %s\n", result.Connection.ConnectedAt.Local().Format(time.DateTime))
+		}
+	}
+	printWarnings(result.Warnings)
+	return nil
+}
@@ -0,0 +1,122 @@
+// Package audit records changes rhc makes to the system in an append-only log.
+//
+// Each entry is stored as a single JSON document on its own line, so the log
+// can be processed with common line-oriented tools. Entries can be signed and
+// chained to the preceding line, so tampering with the log can be detected.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// Entry is a single record of the audit log.
+type Entry struct {
+	// Time is the moment the change was made.
+	Time time.Time `json:"time"`
+	// Action is a short identifier of the change (e.g. "hostname-sync").
+	Action string `json:"action"`
+	// UID is the user ID of the user that made the change.
+	UID int `json:"uid"`
+	// Details holds action-specific information.
+	Details map[string]string `json:"details,omitempty"`
+	// Previous is the SHA-256 digest of the preceding line of the log. It is
+	// set for signed entries only, see AppendSigned.
+	Previous string `json:"previous,omitempty"`
+	// KeyID identifies the key the entry is signed with.
+	KeyID string `json:"key_id,omitempty"`
+	// Certificate is the base64-encoded DER certificate of the key the entry
+	// is signed with. It is set on the first entry signed with the key, see
+	// AppendSigned.
+	Certificate string `json:"certificate,omitempty"`
+	// Signature is the base64-encoded signature of the entry.
+	Signature string `json:"signature,omitempty"`
+}
+
+// NewEntry returns an Entry for the given action made by the current user now.
+func NewEntry(action string, details map[string]string) Entry {
+	return Entry{
+		Time:    time.Now().UTC(),
+		Action:  action,
+		UID:     os.Getuid(),
+		Details: details,
+	}
+}
+
+// Append writes entry at the end of the audit log at path. The log file and
+// its parent directory are created when they do not exist.
+func Append(path string, entry Entry) error {
+	file, err := openLocked(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	return write(file, entry)
+}
+
+// openLocked opens the audit log at path for reading and appending, and locks
+// it exclusively, so entries of commands running at the same time are not
+// interleaved, and signed entries are chained to the line they were signed
+// after. The lock is released when the file is closed.
+func openLocked(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("cannot create audit log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open audit log: %w", err)
+	}
+	if err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("cannot lock audit log: %w", err)
+	}
+	return file, nil
+}
+
+// write appends entry to the locked audit log file.
+func write(file *os.File, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("cannot encode audit entry: %w", err)
+	}
+	if _, err = file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("cannot write audit log: %w", err)
+	}
+	return file.Sync()
+}
+
+// Read returns all entries of the audit log at path, oldest first.
+// An empty list is returned when the log does not exist.
+func Read(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open audit log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("cannot parse audit log %s, line %d: %w", path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read audit log: %w", err)
+	}
+	return entries, nil
+}
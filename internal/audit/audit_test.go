@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAppendRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rhc", "audit.log")
+
+	entries := []Entry{
+		{
+			Time:   time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+			Action: "hostname-sync",
+			UID:    0,
+			Details: map[string]string{
+				"old": "host.example.com",
+				"new": "node1.example.com",
+			},
+		},
+		{
+			Time:   time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC),
+			Action: "lock",
+			UID:    0,
+		},
+	}
+	for _, entry := range entries {
+		if err := Append(path, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(got, entries) {
+		t.Errorf("%v", cmp.Diff(got, entries))
+	}
+}
+
+func TestReadMissing(t *testing.T) {
+	got, err := Read(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no entries, got %v", got)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	if err := os.WriteFile(path, []byte("{}\nnot json\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(path); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
@@ -0,0 +1,244 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Problem is an entry of the audit log that failed verification.
+type Problem struct {
+	// Line is the line number of the entry in the log.
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// VerifyResult is the result of verification of the audit log.
+type VerifyResult struct {
+	// Entries is the number of entries in the log.
+	Entries int `json:"entries"`
+	// Signed is the number of signed entries in the log.
+	Signed   int       `json:"signed"`
+	Problems []Problem `json:"problems"`
+}
+
+// KeyID returns the identifier of the public key, the hex-encoded SHA-256
+// digest of its DER encoding.
+func KeyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode public key: %w", err)
+	}
+	digest := sha256.Sum256(der)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// AppendSigned writes entry signed by signer, the key of cert, at the end of
+// the audit log at path. The entry is chained to the preceding line of the log,
+// so removed or reordered entries are detected by Verify. The certificate is
+// embedded in the entry, unless the preceding entry is signed with the same
+// key, so entries signed before the certificate is renewed can be verified.
+// The log is locked from reading the preceding line until the entry is written.
+func AppendSigned(path string, entry Entry, signer crypto.Signer, cert *x509.Certificate) error {
+	file, err := openLocked(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	last, err := lastLine(file)
+	if err != nil {
+		return err
+	}
+	if entry.KeyID, err = KeyID(signer.Public()); err != nil {
+		return err
+	}
+	var preceding Entry
+	if last != nil {
+		entry.Previous = lineDigest(last)
+		_ = json.Unmarshal(last, &preceding)
+	}
+	entry.Certificate = ""
+	if preceding.KeyID != entry.KeyID {
+		entry.Certificate = base64.StdEncoding.EncodeToString(cert.Raw)
+	}
+
+	entry.Signature = ""
+	digest, err := entryDigest(entry)
+	if err != nil {
+		return err
+	}
+	signature, err := signer.Sign(rand.Reader, digest, crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("cannot sign audit entry: %w", err)
+	}
+	entry.Signature = base64.StdEncoding.EncodeToString(signature)
+
+	return write(file, entry)
+}
+
+// Verify checks signatures of the entries of the audit log at path, and the
+// chaining of the entries. Entries are verified with the keys of the
+// certificates embedded in the preceding entries, which have to be issued by
+// a CA of roots and valid at the time of the entry, so entries signed before
+// the certificate was renewed remain verifiable. Unsigned entries and entries
+// signed with an unknown key are reported, as they cannot be verified.
+func Verify(path string, roots *x509.CertPool) (VerifyResult, error) {
+	result := VerifyResult{Problems: []Problem{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("cannot open audit log: %w", err)
+	}
+
+	var previous []byte
+	keys := make(map[string]crypto.PublicKey)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		raw := scanner.Bytes()
+		if len(raw) == 0 {
+			continue
+		}
+		result.Entries++
+		problem := verifyLine(raw, previous, keys, roots)
+		if problem != "" {
+			result.Problems = append(result.Problems, Problem{Line: line, Message: problem})
+		}
+		var entry Entry
+		if json.Unmarshal(raw, &entry) == nil && entry.Signature != "" {
+			result.Signed++
+		}
+		previous = bytes.Clone(raw)
+	}
+	if err = scanner.Err(); err != nil {
+		return result, fmt.Errorf("cannot read audit log: %w", err)
+	}
+	return result, nil
+}
+
+// verifyLine verifies a single line of the log, and describes the problem
+// found. An empty string is returned for a valid line. The key of a trusted
+// certificate embedded in the line is added to keys.
+func verifyLine(raw []byte, previous []byte, keys map[string]crypto.PublicKey, roots *x509.CertPool) string {
+	var entry Entry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return fmt.Sprintf("cannot parse entry: %v", err)
+	}
+	if entry.Signature == "" {
+		return "entry is not signed"
+	}
+	if entry.Certificate != "" {
+		if problem := trustCertificate(entry, keys, roots); problem != "" {
+			return problem
+		}
+	}
+	pub, ok := keys[entry.KeyID]
+	if !ok {
+		return fmt.Sprintf("entry is signed with unknown key %s", entry.KeyID)
+	}
+	// The preceding line of the first line is not known after the log is rotated
+	if previous != nil && entry.Previous != lineDigest(previous) {
+		return "entry does not follow the preceding entry"
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(entry.Signature)
+	if err != nil {
+		return "signature is malformed"
+	}
+	entry.Signature = ""
+	digest, err := entryDigest(entry)
+	if err != nil {
+		return err.Error()
+	}
+	if err := verifySignature(pub, digest, signature); err != nil {
+		return fmt.Sprintf("signature does not match: %v", err)
+	}
+	return ""
+}
+
+// trustCertificate adds the key of the certificate embedded in entry to keys,
+// when the certificate is issued by a CA of roots and valid at the time of the
+// entry, and describes the problem otherwise.
+func trustCertificate(entry Entry, keys map[string]crypto.PublicKey, roots *x509.CertPool) string {
+	der, err := base64.StdEncoding.DecodeString(entry.Certificate)
+	if err != nil {
+		return "certificate is malformed"
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return fmt.Sprintf("certificate is malformed: %v", err)
+	}
+	if keyID, err := KeyID(cert.PublicKey); err != nil || keyID != entry.KeyID {
+		return "certificate does not match the key of the entry"
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:       roots,
+		CurrentTime: entry.Time,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Sprintf("certificate is not trusted: %v", err)
+	}
+	keys[entry.KeyID] = cert.PublicKey
+	return ""
+}
+
+// verifySignature verifies the signature of the SHA-256 digest made by the key pub.
+func verifySignature(pub crypto.PublicKey, digest []byte, signature []byte) error {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest, signature) {
+			return errors.New("invalid ECDSA signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported key type %T", pub)
+	}
+}
+
+// entryDigest returns the SHA-256 digest of the JSON encoding of entry.
+func entryDigest(entry Entry) ([]byte, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode audit entry: %w", err)
+	}
+	digest := sha256.Sum256(data)
+	return digest[:], nil
+}
+
+// lineDigest returns the hex-encoded SHA-256 digest of a line of the log.
+func lineDigest(line []byte) string {
+	digest := sha256.Sum256(line)
+	return hex.EncodeToString(digest[:])
+}
+
+// lastLine returns the last non-empty line of the log file, or nil when the
+// log is empty.
+func lastLine(file *os.File) ([]byte, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read audit log: %w", err)
+	}
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	last := lines[len(lines)-1]
+	if len(last) == 0 {
+		return nil, nil
+	}
+	return last, nil
+}
@@ -0,0 +1,268 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// testCA issues certificates of identity keys in tests.
+type testCA struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestCA(t *testing.T) testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Entitlement Master CA"},
+		NotBefore:             time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCA{key: key, cert: cert}
+}
+
+// issue returns an identity key and its certificate issued by ca.
+func (ca testCA) issue(t *testing.T) (*ecdsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "0f5e8b5c-1a2b-4c3d-8e9f-0a1b2c3d4e5f"},
+		NotBefore:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, key.Public(), ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert
+}
+
+func TestVerify(t *testing.T) {
+	ca := newTestCA(t)
+	key, cert := ca.issue(t)
+	renewedKey, renewedCert := ca.issue(t)
+	untrustedKey, untrustedCert := newTestCA(t).issue(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	entry := func(action string) Entry {
+		return Entry{Time: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), Action: action}
+	}
+
+	tests := []struct {
+		description string
+		// write populates the log at path
+		write        func(t *testing.T, path string)
+		wantSigned   int
+		wantProblems []Problem
+	}{
+		{
+			description: "signed entries",
+			write: func(t *testing.T, path string) {
+				_ = AppendSigned(path, entry("lock"), key, cert)
+				_ = AppendSigned(path, entry("unlock"), key, cert)
+			},
+			wantSigned:   2,
+			wantProblems: []Problem{},
+		},
+		{
+			description: "renewed certificate",
+			write: func(t *testing.T, path string) {
+				_ = AppendSigned(path, entry("lock"), key, cert)
+				_ = AppendSigned(path, entry("unlock"), renewedKey, renewedCert)
+				_ = AppendSigned(path, entry("lock"), renewedKey, renewedCert)
+			},
+			wantSigned:   3,
+			wantProblems: []Problem{},
+		},
+		{
+			description: "modified entry",
+			write: func(t *testing.T, path string) {
+				_ = AppendSigned(path, entry("lock"), key, cert)
+				_ = AppendSigned(path, entry("unlock"), key, cert)
+				replaceInFile(t, path, `"action":"unlock"`, `"action":"lock"`)
+			},
+			wantSigned:   2,
+			wantProblems: []Problem{{Line: 2, Message: "signature does not match: invalid ECDSA signature"}},
+		},
+		{
+			description: "removed entry",
+			write: func(t *testing.T, path string) {
+				_ = AppendSigned(path, entry("lock"), key, cert)
+				_ = AppendSigned(path, entry("unlock"), key, cert)
+				_ = AppendSigned(path, entry("lock"), key, cert)
+				data, _ := os.ReadFile(path)
+				lines := bytes.SplitAfter(data, []byte("\n"))
+				_ = os.WriteFile(path, append(lines[0], lines[2]...), 0600)
+			},
+			wantSigned:   2,
+			wantProblems: []Problem{{Line: 2, Message: "entry does not follow the preceding entry"}},
+		},
+		{
+			description: "unsigned entry",
+			write: func(t *testing.T, path string) {
+				_ = Append(path, entry("lock"))
+				_ = AppendSigned(path, entry("unlock"), key, cert)
+			},
+			wantSigned:   1,
+			wantProblems: []Problem{{Line: 1, Message: "entry is not signed"}},
+		},
+		{
+			description: "entry signed with untrusted certificate",
+			write: func(t *testing.T, path string) {
+				_ = AppendSigned(path, entry("lock"), untrustedKey, untrustedCert)
+			},
+			wantSigned: 1,
+		},
+		{
+			description: "entry signed with key of another certificate",
+			write: func(t *testing.T, path string) {
+				_ = AppendSigned(path, entry("lock"), untrustedKey, cert)
+			},
+			wantSigned:   1,
+			wantProblems: []Problem{{Line: 1, Message: "certificate does not match the key of the entry"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.log")
+			test.write(t, path)
+
+			got, err := Verify(path, roots)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Signed != test.wantSigned {
+				t.Errorf("got %d signed entries, want %d", got.Signed, test.wantSigned)
+			}
+			if test.wantProblems == nil {
+				// Only the presence of the problem is checked
+				if len(got.Problems) != 1 {
+					t.Errorf("expected a problem, got %v", got.Problems)
+				}
+				return
+			}
+			if !cmp.Equal(got.Problems, test.wantProblems) {
+				t.Errorf("%v", cmp.Diff(got.Problems, test.wantProblems))
+			}
+		})
+	}
+}
+
+func TestAppendSignedEmbedsCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	key, cert := ca.issue(t)
+	renewedKey, renewedCert := ca.issue(t)
+	path := filepath.Join(t.TempDir(), "audit.log")
+	entry := Entry{Time: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), Action: "lock"}
+	for _, signer := range []struct {
+		key  *ecdsa.PrivateKey
+		cert *x509.Certificate
+	}{{key, cert}, {key, cert}, {renewedKey, renewedCert}} {
+		if err := AppendSigned(path, entry, signer.key, signer.cert); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []bool
+	for _, entry := range entries {
+		got = append(got, entry.Certificate != "")
+	}
+	// The certificate is embedded in the first entry signed with every key
+	want := []bool{true, false, true}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
+
+func TestAppendSignedConcurrently(t *testing.T) {
+	ca := newTestCA(t)
+	key, cert := ca.issue(t)
+	path := filepath.Join(t.TempDir(), "audit.log")
+	entry := Entry{Time: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), Action: "lock"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- AppendSigned(path, entry, key, cert)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	result, err := Verify(path, roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every entry is chained to the entry written before it
+	if result.Entries != 20 || len(result.Problems) != 0 {
+		t.Errorf("got %d entries with problems %v, want 20 entries without problems", result.Entries, result.Problems)
+	}
+}
+
+func replaceInFile(t *testing.T, path, old, new string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte(old)) {
+		t.Fatalf("%s not found in %s", old, path)
+	}
+	if err = os.WriteFile(path, bytes.Replace(data, []byte(old), []byte(new), 1), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -34,6 +34,15 @@ type CanonicalFacts struct {
 	IPAddresses           []string `json:"ip_addresses"`
 	MACAddresses          []string `json:"mac_addresses"`
 	FQDN                  string   `json:"fqdn"`
+	// HypervisorType, VirtUUID and HypervisorHostname are set on virtual
+	// guests, so guests can be mapped to their hosts. VirtUUID is the UUID the
+	// hypervisor knows the guest by, as reported by virt-who.
+	HypervisorType     string `json:"hypervisor_type,omitempty"`
+	VirtUUID           string `json:"virt_uuid,omitempty"`
+	HypervisorHostname string `json:"hypervisor_hostname,omitempty"`
+	// AssetTag is the identifier of the host in the asset management of the
+	// organization, see AssetTag.
+	AssetTag string `json:"asset_tag,omitempty"`
 }
 
 // CanonicalFactsFromMap creates a CanonicalFacts struct from the key-value
@@ -104,9 +113,49 @@ func CanonicalFactsFromMap(m map[string]interface{}) (*CanonicalFacts, error) {
 		}
 	}
 
+	if val, ok := m["hypervisor_type"]; ok {
+		switch val := val.(type) {
+		case string:
+			facts.HypervisorType = val
+		default:
+			return nil, &InvalidValueTypeError{key: "hypervisor_type", val: val}
+		}
+	}
+
+	if val, ok := m["virt_uuid"]; ok {
+		switch val := val.(type) {
+		case string:
+			facts.VirtUUID = val
+		default:
+			return nil, &InvalidValueTypeError{key: "virt_uuid", val: val}
+		}
+	}
+
+	if val, ok := m["hypervisor_hostname"]; ok {
+		switch val := val.(type) {
+		case string:
+			facts.HypervisorHostname = val
+		default:
+			return nil, &InvalidValueTypeError{key: "hypervisor_hostname", val: val}
+		}
+	}
+
+	if val, ok := m["asset_tag"]; ok {
+		switch val := val.(type) {
+		case string:
+			facts.AssetTag = val
+		default:
+			return nil, &InvalidValueTypeError{key: "asset_tag", val: val}
+		}
+	}
+
 	return &facts, nil
 }
 
+// AssetTag is the asset tag configured by the organization, which is reported
+// as a canonical fact; empty when it is not set.
+var AssetTag string
+
 // GetCanonicalFacts attempts to construct a CanonicalFacts struct by collecting
 // data from the localhost.
 func GetCanonicalFacts() (*CanonicalFacts, error) {
@@ -158,6 +207,9 @@ func GetCanonicalFacts() (*CanonicalFacts, error) {
 		return nil, err
 	}
 
+	collectVirtFacts(&facts)
+	facts.AssetTag = AssetTag
+
 	return &facts, nil
 }
 
@@ -48,6 +48,42 @@ func TestCanonicalFactsFromMap(t *testing.T) {
 			},
 			wantError: &InvalidValueTypeError{key: "insights_id", val: 1},
 		},
+		{
+			description: "valid with virtualization facts",
+			input: map[string]interface{}{
+				"machine_id":          "acc046d0-0add-4550-ac7c-5a833b1b6470",
+				"bios_uuid":           "d8ec3cd5-a6bc-4742-bd2f-32940da182b0",
+				"hypervisor_type":     "hyperv",
+				"virt_uuid":           "d8ec3cd5-a6bc-4742-bd2f-32940da182b0",
+				"hypervisor_hostname": "host01.example.com",
+			},
+			want: &CanonicalFacts{
+				MachineID:          "acc046d0-0add-4550-ac7c-5a833b1b6470",
+				BIOSUUID:           "d8ec3cd5-a6bc-4742-bd2f-32940da182b0",
+				HypervisorType:     "hyperv",
+				VirtUUID:           "d8ec3cd5-a6bc-4742-bd2f-32940da182b0",
+				HypervisorHostname: "host01.example.com",
+			},
+		},
+		{
+			description: "valid with asset tag",
+			input: map[string]interface{}{
+				"machine_id": "acc046d0-0add-4550-ac7c-5a833b1b6470",
+				"asset_tag":  "ASSET-000123",
+			},
+			want: &CanonicalFacts{
+				MachineID: "acc046d0-0add-4550-ac7c-5a833b1b6470",
+				AssetTag:  "ASSET-000123",
+			},
+		},
+		{
+			description: "invalid asset tag",
+			input: map[string]interface{}{
+				"machine_id": "acc046d0-0add-4550-ac7c-5a833b1b6470",
+				"asset_tag":  123,
+			},
+			wantError: &InvalidValueTypeError{key: "asset_tag", val: 123},
+		},
 		{
 			description: "valid with absent insights_id",
 			input: map[string]interface{}{
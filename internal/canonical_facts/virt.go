@@ -0,0 +1,100 @@
+package canonical_facts
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Paths exposing the virtualization of the system. They are variables, so
+// tests can point them to fixtures.
+var (
+	// dmiDir is the directory exposing DMI information of the system.
+	dmiDir = "/sys/devices/virtual/dmi/id"
+	// hypervisorDir is the directory exposing the Xen hypervisor to its guests.
+	hypervisorDir = "/sys/hypervisor"
+	// hypervKVPPoolPath is the pool of key-value pairs written by a Hyper-V
+	// host into its guests.
+	hypervKVPPoolPath = "/var/lib/hyperv/.kvp_pool_3"
+)
+
+// Sizes of the key and value of a record in a Hyper-V key-value pair pool.
+const (
+	kvpKeySize   = 512
+	kvpValueSize = 2048
+)
+
+// nilUUID is the UUID of the Xen control domain, which is the host itself.
+const nilUUID = "00000000-0000-0000-0000-000000000000"
+
+// hypervisorType returns the hypervisor running the system based on the DMI
+// system vendor and product name, or an empty string on bare metal.
+func hypervisorType(vendor, product string) string {
+	switch {
+	case strings.Contains(product, "VirtualBox"):
+		return "virtualbox"
+	case strings.HasPrefix(vendor, "VMware"):
+		return "vmware"
+	case vendor == "Microsoft Corporation" && product == "Virtual Machine":
+		return "hyperv"
+	case vendor == "QEMU", strings.Contains(product, "KVM"), vendor == "Red Hat" && strings.Contains(product, "RHEV"):
+		return "kvm"
+	case vendor == "Xen", strings.HasPrefix(product, "HVM domU"):
+		return "xen"
+	case strings.HasPrefix(vendor, "Parallels"):
+		return "parallels"
+	}
+	return ""
+}
+
+// parseKVPPool returns the key-value pairs of a Hyper-V key-value pair pool.
+// A trailing incomplete record is ignored.
+func parseKVPPool(data []byte) map[string]string {
+	pairs := make(map[string]string)
+	for len(data) >= kvpKeySize+kvpValueSize {
+		key, _, _ := bytes.Cut(data[:kvpKeySize], []byte{0})
+		value, _, _ := bytes.Cut(data[kvpKeySize:kvpKeySize+kvpValueSize], []byte{0})
+		if len(key) > 0 {
+			pairs[string(key)] = string(value)
+		}
+		data = data[kvpKeySize+kvpValueSize:]
+	}
+	return pairs
+}
+
+// collectVirtFacts sets the hypervisor type, the UUID the hypervisor knows
+// the system by and the host name of the hypervisor, where they are exposed
+// to the guest. Nothing is set on bare metal.
+func collectVirtFacts(facts *CanonicalFacts) {
+	// Xen paravirtualized guests have no DMI information
+	if xenType, err := readFile(filepath.Join(hypervisorDir, "type")); err == nil && xenType != "" {
+		uuid, err := readFile(filepath.Join(hypervisorDir, "uuid"))
+		if err != nil || uuid == nilUUID {
+			return
+		}
+		facts.HypervisorType = xenType
+		facts.VirtUUID = uuid
+		return
+	}
+
+	vendor, _ := readFile(filepath.Join(dmiDir, "sys_vendor"))
+	product, _ := readFile(filepath.Join(dmiDir, "product_name"))
+	facts.HypervisorType = hypervisorType(vendor, product)
+	if facts.HypervisorType == "" {
+		return
+	}
+	facts.VirtUUID = facts.BIOSUUID
+
+	if facts.HypervisorType == "hyperv" {
+		data, err := os.ReadFile(hypervKVPPoolPath)
+		if err != nil {
+			return
+		}
+		pairs := parseKVPPool(data)
+		facts.HypervisorHostname = pairs["PhysicalHostNameFullyQualified"]
+		if facts.HypervisorHostname == "" {
+			facts.HypervisorHostname = pairs["PhysicalHostName"]
+		}
+	}
+}
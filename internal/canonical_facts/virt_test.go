@@ -0,0 +1,150 @@
+package canonical_facts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHypervisorType(t *testing.T) {
+	tests := []struct {
+		vendor  string
+		product string
+		want    string
+	}{
+		{vendor: "QEMU", product: "Standard PC (Q35 + ICH9, 2009)", want: "kvm"},
+		{vendor: "Red Hat", product: "KVM", want: "kvm"},
+		{vendor: "VMware, Inc.", product: "VMware Virtual Platform", want: "vmware"},
+		{vendor: "Microsoft Corporation", product: "Virtual Machine", want: "hyperv"},
+		{vendor: "Microsoft Corporation", product: "Surface Laptop", want: ""},
+		{vendor: "innotek GmbH", product: "VirtualBox", want: "virtualbox"},
+		{vendor: "Xen", product: "HVM domU", want: "xen"},
+		{vendor: "Dell Inc.", product: "PowerEdge R740", want: ""},
+		{vendor: "", product: "", want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.vendor+"/"+test.product, func(t *testing.T) {
+			got := hypervisorType(test.vendor, test.product)
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// kvpRecord returns a record of a Hyper-V key-value pair pool.
+func kvpRecord(key, value string) []byte {
+	record := make([]byte, kvpKeySize+kvpValueSize)
+	copy(record, key)
+	copy(record[kvpKeySize:], value)
+	return record
+}
+
+func TestParseKVPPool(t *testing.T) {
+	var data []byte
+	data = append(data, kvpRecord("HostName", "host01")...)
+	data = append(data, kvpRecord("PhysicalHostNameFullyQualified", "host01.example.com")...)
+	data = append(data, []byte("truncated")...)
+
+	want := map[string]string{
+		"HostName":                       "host01",
+		"PhysicalHostNameFullyQualified": "host01.example.com",
+	}
+	got := parseKVPPool(data)
+	if !cmp.Equal(got, want) {
+		t.Errorf("diff got want\n%v", cmp.Diff(got, want))
+	}
+}
+
+func TestCollectVirtFacts(t *testing.T) {
+	tests := []struct {
+		description string
+		files       map[string]string
+		want        CanonicalFacts
+	}{
+		{
+			description: "bare metal",
+			files: map[string]string{
+				"dmi/sys_vendor":   "Dell Inc.",
+				"dmi/product_name": "PowerEdge R740",
+			},
+			want: CanonicalFacts{BIOSUUID: "d8ec3cd5-a6bc-4742-bd2f-32940da182b0"},
+		},
+		{
+			description: "kvm guest",
+			files: map[string]string{
+				"dmi/sys_vendor":   "QEMU",
+				"dmi/product_name": "Standard PC (Q35 + ICH9, 2009)",
+			},
+			want: CanonicalFacts{
+				BIOSUUID:       "d8ec3cd5-a6bc-4742-bd2f-32940da182b0",
+				HypervisorType: "kvm",
+				VirtUUID:       "d8ec3cd5-a6bc-4742-bd2f-32940da182b0",
+			},
+		},
+		{
+			description: "hyper-v guest",
+			files: map[string]string{
+				"dmi/sys_vendor":   "Microsoft Corporation",
+				"dmi/product_name": "Virtual Machine",
+				"kvp_pool_3": string(kvpRecord("PhysicalHostName", "HOST01")) +
+					string(kvpRecord("PhysicalHostNameFullyQualified", "host01.example.com")),
+			},
+			want: CanonicalFacts{
+				BIOSUUID:           "d8ec3cd5-a6bc-4742-bd2f-32940da182b0",
+				HypervisorType:     "hyperv",
+				VirtUUID:           "d8ec3cd5-a6bc-4742-bd2f-32940da182b0",
+				HypervisorHostname: "host01.example.com",
+			},
+		},
+		{
+			description: "xen paravirtualized guest",
+			files: map[string]string{
+				"hypervisor/type": "xen\n",
+				"hypervisor/uuid": "6fa33c5b-7f8d-4b2e-9c63-2a4d7c1b0e11\n",
+			},
+			want: CanonicalFacts{
+				BIOSUUID:       "d8ec3cd5-a6bc-4742-bd2f-32940da182b0",
+				HypervisorType: "xen",
+				VirtUUID:       "6fa33c5b-7f8d-4b2e-9c63-2a4d7c1b0e11",
+			},
+		},
+		{
+			description: "xen control domain",
+			files: map[string]string{
+				"hypervisor/type": "xen",
+				"hypervisor/uuid": nilUUID,
+			},
+			want: CanonicalFacts{BIOSUUID: "d8ec3cd5-a6bc-4742-bd2f-32940da182b0"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range test.files {
+				path := filepath.Join(root, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			oldDMIDir, oldHypervisorDir, oldKVPPoolPath := dmiDir, hypervisorDir, hypervKVPPoolPath
+			dmiDir = filepath.Join(root, "dmi")
+			hypervisorDir = filepath.Join(root, "hypervisor")
+			hypervKVPPoolPath = filepath.Join(root, "kvp_pool_3")
+			t.Cleanup(func() { dmiDir, hypervisorDir, hypervKVPPoolPath = oldDMIDir, oldHypervisorDir, oldKVPPoolPath })
+
+			got := CanonicalFacts{BIOSUUID: "d8ec3cd5-a6bc-4742-bd2f-32940da182b0"}
+			collectVirtFacts(&got)
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("diff got test.want\n%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
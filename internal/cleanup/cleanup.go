@@ -0,0 +1,122 @@
+// Package cleanup releases resources, such as temporary files and locks, on
+// every exit path of a program.
+//
+// A resource is registered by Push right after it is acquired. The returned
+// function releases it on the normal path, usually in a defer statement. When
+// the program exits early, e.g. by cli.Exit or a signal, the resources still
+// registered are released by Run in reverse order of registration.
+package cleanup
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// Registry holds functions releasing acquired resources.
+type Registry struct {
+	mu      sync.Mutex
+	next    int
+	entries []entry
+}
+
+// entry is a registered cleanup function.
+type entry struct {
+	id   int
+	name string
+	fn   func() error
+}
+
+// Push registers fn releasing the resource described by name. The returned
+// function runs fn and unregisters it; it runs fn at most once, even when it
+// is called after Run.
+func (r *Registry) Push(name string, fn func() error) func() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next++
+	id := r.next
+	r.entries = append(r.entries, entry{id: id, name: name, fn: fn})
+
+	return func() error {
+		e, ok := r.remove(id)
+		if !ok {
+			return nil
+		}
+		return e.run()
+	}
+}
+
+// remove unregisters the entry with id, and returns it. False is returned
+// when the entry has already been run.
+func (r *Registry) remove(id int) (entry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, e := range r.entries {
+		if e.id == id {
+			r.entries = append(r.entries[:i], r.entries[i+1:]...)
+			return e, true
+		}
+	}
+	return entry{}, false
+}
+
+// Run runs all registered functions in reverse order of registration, and
+// unregisters them. All functions are run, even when some of them fail; the
+// errors are joined.
+func (r *Registry) Run() error {
+	var errs []error
+	for {
+		r.mu.Lock()
+		if len(r.entries) == 0 {
+			r.mu.Unlock()
+			break
+		}
+		e := r.entries[len(r.entries)-1]
+		r.entries = r.entries[:len(r.entries)-1]
+		r.mu.Unlock()
+
+		if err := e.run(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Len returns the number of registered functions.
+func (r *Registry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// run calls the function of the entry.
+func (e entry) run() error {
+	slog.Debug("Running cleanup", "name", e.name)
+	if err := e.fn(); err != nil {
+		return fmt.Errorf("cleanup of %s failed: %w", e.name, err)
+	}
+	return nil
+}
+
+// defaultRegistry is the registry of the program.
+var defaultRegistry = &Registry{}
+
+// Push registers fn in the registry of the program, see Registry.Push.
+func Push(name string, fn func() error) func() error {
+	return defaultRegistry.Push(name, fn)
+}
+
+// Run runs the functions registered in the registry of the program, see Registry.Run.
+func Run() error {
+	return defaultRegistry.Run()
+}
+
+// Remove returns a cleanup function removing path with its content. A missing
+// path is not an error.
+func Remove(path string) func() error {
+	return func() error {
+		return os.RemoveAll(path)
+	}
+}
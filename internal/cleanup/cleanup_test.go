@@ -0,0 +1,57 @@
+package cleanup
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRun(t *testing.T) {
+	var registry Registry
+	var calls []string
+	record := func(name string, err error) func() error {
+		return func() error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	_ = registry.Push("lock", record("lock", nil))
+	releaseTmp := registry.Push("tmp", record("tmp", nil))
+	_ = registry.Push("config", record("config", errors.New("permission denied")))
+	_ = registry.Push("spinner", record("spinner", nil))
+
+	// Released on the normal path before the early exit
+	if err := releaseTmp(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := registry.Run()
+	if err == nil || err.Error() != "cleanup of config failed: permission denied" {
+		t.Errorf("unexpected error %v", err)
+	}
+	want := []string{"tmp", "spinner", "config", "lock"}
+	if !cmp.Equal(calls, want) {
+		t.Errorf("%v", cmp.Diff(calls, want))
+	}
+	if registry.Len() != 0 {
+		t.Errorf("expected empty registry, got %d entries", registry.Len())
+	}
+}
+
+func TestReleaseOnce(t *testing.T) {
+	var registry Registry
+	count := 0
+	release := registry.Push("tmp", func() error {
+		count++
+		return nil
+	})
+
+	_ = registry.Run()
+	_ = release()
+	_ = release()
+	if count != 1 {
+		t.Errorf("cleanup ran %d times, want 1", count)
+	}
+}
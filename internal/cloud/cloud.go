@@ -0,0 +1,71 @@
+// Package cloud detects the public cloud the system runs in.
+package cloud
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Providers of public clouds.
+const (
+	AWS   = "aws"
+	Azure = "azure"
+	GCP   = "gcp"
+)
+
+// DMIDir is the directory exposing DMI information of the system.
+const DMIDir = "/sys/devices/virtual/dmi/id"
+
+// dmiFields are the DMI fields the cloud provider is detected from.
+var dmiFields = []string{"sys_vendor", "product_name", "bios_version", "chassis_asset_tag"}
+
+// azureAssetTag is the DMI chassis asset tag of Azure virtual machines.
+const azureAssetTag = "7783-7084-3265-9085-8269-3286-77"
+
+// DetectProvider returns the cloud provider based on DMI information,
+// or an empty string when the system does not run in a known cloud.
+func DetectProvider(dmi map[string]string) string {
+	switch {
+	case strings.Contains(dmi["sys_vendor"], "Amazon") || strings.HasPrefix(dmi["bios_version"], "amazon"):
+		return AWS
+	case dmi["chassis_asset_tag"] == azureAssetTag:
+		return Azure
+	case strings.Contains(dmi["sys_vendor"], "Google") || strings.Contains(dmi["product_name"], "Google"):
+		return GCP
+	}
+	return ""
+}
+
+// ReadDMI returns the DMI fields used to detect the cloud provider from dir.
+// Fields that cannot be read (e.g. DMI is not available on all architectures)
+// are missing.
+func ReadDMI(dir string) map[string]string {
+	dmi := make(map[string]string)
+	for _, name := range dmiFields {
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			dmi[name] = strings.TrimSpace(string(data))
+		}
+	}
+	return dmi
+}
+
+// Provider returns the cloud provider the system runs in, or an empty string
+// when it does not run in a known cloud.
+func Provider() string {
+	return DetectProvider(ReadDMI(DMIDir))
+}
+
+// Name returns the human-readable name of provider.
+func Name(provider string) string {
+	switch provider {
+	case AWS:
+		return "Amazon Web Services"
+	case Azure:
+		return "Microsoft Azure"
+	case GCP:
+		return "Google Cloud"
+	default:
+		return provider
+	}
+}
@@ -0,0 +1,49 @@
+package cloud
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDetectProvider(t *testing.T) {
+	tests := []struct {
+		description string
+		dmi         map[string]string
+		want        string
+	}{
+		{"aws", map[string]string{"sys_vendor": "Amazon EC2"}, AWS},
+		{"aws xen", map[string]string{"sys_vendor": "Xen", "bios_version": "amazon-4.11"}, AWS},
+		{"azure", map[string]string{"sys_vendor": "Microsoft Corporation", "chassis_asset_tag": azureAssetTag}, Azure},
+		{"gcp", map[string]string{"sys_vendor": "Google", "product_name": "Google Compute Engine"}, GCP},
+		{"bare metal", map[string]string{"sys_vendor": "Dell Inc."}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if got := DetectProvider(test.dmi); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadDMI(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"sys_vendor":   "Amazon EC2\n",
+		"product_name": "m5.large\n",
+		"board_name":   "ignored\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]string{"sys_vendor": "Amazon EC2", "product_name": "m5.large"}
+	if got := ReadDMI(dir); !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
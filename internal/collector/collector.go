@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/redhatinsights/rhc/internal/logging"
+	"github.com/redhatinsights/rhc/internal/schedule"
 	"github.com/redhatinsights/rhc/internal/systemd"
 )
 
@@ -42,12 +44,16 @@ type Config struct {
 	Group string
 	// ContentType is used by rhc when it uploads the data archive to Ingress.
 	ContentType string
+	// Schedule spreads scheduled runs of the collector across hosts. Only
+	// Splay and Jitter are used; the period is set by the systemd timer.
+	Schedule schedule.Schedule
 }
 
 // configDto represents the structure of a TOML configuration file for parsing.
 type configDto struct {
-	Meta    *metaDto    `toml:"meta"`
-	Ingress *ingressDto `toml:"ingress"`
+	Meta     *metaDto     `toml:"meta"`
+	Ingress  *ingressDto  `toml:"ingress"`
+	Schedule *scheduleDto `toml:"schedule"`
 }
 
 // metaDto represents the metadata section of a TOML configuration file.
@@ -64,6 +70,12 @@ type ingressDto struct {
 	ContentType string  `toml:"content_type"`
 }
 
+// scheduleDto represents the schedule section of a TOML configuration file.
+type scheduleDto struct {
+	Splay  *string `toml:"splay,omitempty"`
+	Jitter *string `toml:"jitter,omitempty"`
+}
+
 // Timer represents the execution timing information for a collector.
 type Timer struct {
 	// ID is the unique identifier for the collector.
@@ -243,7 +255,9 @@ func newTimer(id string, timerData []byte) (Timer, error) {
 func createArchive(archiveName, sourceDir, outputDir string) (string, error) {
 	archivePath := filepath.Join(outputDir, archiveName)
 	cmd := exec.Command("tar", "--create", "--xz", "--file", archivePath, "--directory", sourceDir, ".")
+	start := logging.CommandStart(cmd)
 	stdoutStderr, err := cmd.CombinedOutput()
+	logging.Command(cmd, start, err)
 	if err != nil {
 		slog.Debug("tar command failed", "output", string(stdoutStderr))
 		return "", fmt.Errorf("failed to create archive: %v", err)
@@ -335,6 +349,17 @@ func newConfig(id string, dto *configDto) (Config, error) {
 		return Config{}, fmt.Errorf("invalid config: ingress.content_type is required")
 	}
 
+	var runSchedule schedule.Schedule
+	if dto.Schedule != nil {
+		var err error
+		if runSchedule.Splay, err = parseScheduleDuration("schedule.splay", dto.Schedule.Splay); err != nil {
+			return Config{}, err
+		}
+		if runSchedule.Jitter, err = parseScheduleDuration("schedule.jitter", dto.Schedule.Jitter); err != nil {
+			return Config{}, err
+		}
+	}
+
 	// Emit debug log message if meta.feature is present but not 'analytics'
 	if dto.Meta.Feature != nil && *dto.Meta.Feature != "analytics" {
 		slog.Debug("Unsupported collector config feature value ignored", "feature", *dto.Meta.Feature, "supported", "analytics")
@@ -347,9 +372,23 @@ func newConfig(id string, dto *configDto) (Config, error) {
 		User:               user,
 		Group:              group,
 		ContentType:        dto.Ingress.ContentType,
+		Schedule:           runSchedule,
 	}, nil
 }
 
+// parseScheduleDuration parses the optional duration value of the key.
+// Zero is returned when the value is not set.
+func parseScheduleDuration(key string, value *string) (time.Duration, error) {
+	if value == nil {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(*value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid config: %s must be a duration (e.g. \"30m\")", key)
+	}
+	return duration, nil
+}
+
 // ValidateCollectorAndConnect validates that the collector exists and systemd is available,
 // then establishes a systemd connection for timer operations.
 // Returns the systemd connection, timer unit name, and any validation errors.
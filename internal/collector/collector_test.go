@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhatinsights/rhc/internal/schedule"
 )
 
 // stringPtr returns a pointer to the given string value
@@ -274,6 +276,46 @@ func TestParseConfigFromContent(t *testing.T) {
 				ContentType:        "application/test",
 			},
 		},
+		{
+			description: "schedule",
+			content: `
+  [meta]
+  name = "Test Config"
+  type = "ingress"
+
+  [ingress]
+  content_type = "application/test"
+
+  [schedule]
+  splay = "4h"
+  jitter = "10m"
+  `,
+			id: "test.config",
+			want: Config{
+				ID:          "test.config",
+				Name:        "Test Config",
+				User:        "root",
+				Group:       "root",
+				ContentType: "application/test",
+				Schedule:    schedule.Schedule{Splay: 4 * time.Hour, Jitter: 10 * time.Minute},
+			},
+		},
+		{
+			description: "invalid schedule",
+			content: `
+  [meta]
+  name = "Test Config"
+  type = "ingress"
+
+  [ingress]
+  content_type = "application/test"
+
+  [schedule]
+  splay = "daily"
+  `,
+			id:        "test.config",
+			wantError: "invalid config: schedule.splay must be a duration (e.g. \"30m\")",
+		},
 		{
 			description: "invalid TOML syntax",
 			content: `
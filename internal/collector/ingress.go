@@ -53,7 +53,9 @@ func UploadArchive(archive ArchiveDto, config ServiceConfig, userAgent string) e
 	if err != nil {
 		return err
 	}
-	client := httpapi.NewHTTPClient(tlsConfig)
+	client := httpapi.NewReauthHTTPClient(tlsConfig, func() (*tls.Config, error) {
+		return loadClientCertificate(config)
+	})
 	req, err := createUploadRequest(formData, config, userAgent)
 	if err != nil {
 		return err
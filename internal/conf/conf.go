@@ -1,12 +1,224 @@
 package conf
 
-import "log/slog"
+import (
+	"log/slog"
+	"time"
+)
 
 type Conf struct {
 	CertFile string
 	KeyFile  string
 	LogLevel slog.Level
-	CADir    string
+	// Format is the default machine-readable output format of commands
+	// supporting --format; empty for human-readable output.
+	Format string
+	CADir  string
+	UI     UIConf
+	// Tags are applied as Red Hat Lightspeed tags of the host.
+	Tags    map[string]string
+	Facts   FactsConf
+	Proxy   ProxyConf
+	Network NetworkConf
+	Checkin CheckinConf
+	Queue   QueueConf
+	Retry   RetryConf
+	// Timeouts holds the '[timeouts]' section of the configuration file.
+	Timeouts TimeoutsConf
+	Audit    AuditConf
+	Notify   NotifyConf
+	Consent  ConsentConf
+	// Insights holds the '[insights]' section of the configuration file.
+	Insights InsightsConf
+	// API holds the '[api]' section of the configuration file.
+	API APIConf
+	// Syspurpose holds the '[syspurpose]' section of the configuration file.
+	Syspurpose SyspurposeConf
+	// Profiles are environments the system can be switched to, by their names.
+	Profiles map[string]ProfileConf
+	// Servers are entitlement servers (e.g. Satellite capsules) connect and
+	// check-in fail over between, in order of priority; empty when only the
+	// server configured in rhsm.conf is used.
+	Servers []ServerConf
+	// AssetTag is the identifier of the system in the asset management of the
+	// organization; empty when it is not set.
+	AssetTag string
+	// LogRemote is the URL of a remote syslog collector logs are forwarded
+	// to (e.g. "tls://logs.example.com:6514"); empty when logs are not forwarded.
+	LogRemote string
+	// LowBandwidth minimizes the traffic to Red Hat services on constrained
+	// links: the initial upload of Red Hat Lightspeed data is skipped, fact
+	// submissions are compressed, timeouts are widened and check-ins are less
+	// frequent.
+	LowBandwidth bool
+	// ServerURL is the URL of the entitlement server (e.g. Satellite) connect
+	// configures in rhsm.conf; empty when the configured server is kept.
+	ServerURL string
+	// BaseURL is the URL of the content delivery network of ServerURL.
+	BaseURL string
+}
+
+// ProfileConf holds a '[profiles.NAME]' section of the configuration file.
+type ProfileConf struct {
+	// ServerURL is the URL of the entitlement server (e.g. Satellite) of the environment.
+	ServerURL string
+	// BaseURL is the URL of the content delivery network of the environment.
+	BaseURL          string
+	CACert           string
+	Organization     string
+	ActivationKeys   []string
+	ContentTemplates []string
+	EnableFeatures   []string
+	DisableFeatures  []string
+}
+
+// ServerConf holds a '[servers.NAME]' section of the configuration file.
+type ServerConf struct {
+	Name string
+	// URL is the URL of the entitlement server.
+	URL string
+	// BaseURL is the URL of the content delivery network of the server.
+	BaseURL string
+	// Priority orders the servers; servers with lower priority are tried first.
+	Priority int
+}
+
+// ConsentConf holds the '[consent]' section of the configuration file.
+type ConsentConf struct {
+	// NoticeFile is the data collection notice of the organization, which has
+	// to be accepted before the system is connected to Red Hat Lightspeed.
+	NoticeFile string
+}
+
+// InsightsConf holds the '[insights]' section of the configuration file.
+type InsightsConf struct {
+	// GatewayURL is the URL of an on-premise gateway (e.g. the Red Hat
+	// Lightspeed proxy of a Satellite) serving the Red Hat Lightspeed API,
+	// which is used instead of console.redhat.com; empty when the API is
+	// accessed directly.
+	GatewayURL string
+}
+
+// APIConf holds the '[api]' section of the configuration file.
+type APIConf struct {
+	// URL is the base URL of the Red Hat API accessed with the service account.
+	URL string
+	// TokenURL is the endpoint issuing access tokens of the service account.
+	TokenURL string
+	// ClientID identifies the service account; empty when the Red Hat API is
+	// accessed with the consumer certificate of the system.
+	ClientID string
+	// ClientSecretFile holds the client secret of the service account.
+	ClientSecretFile string
+}
+
+// SyspurposeConf holds the '[syspurpose]' section of the configuration file.
+// Empty attributes of the system purpose are not set by 'rhc connect'.
+type SyspurposeConf struct {
+	Role  string
+	SLA   string
+	Usage string
+}
+
+// NotifyConf holds the '[notify]' section of the configuration file.
+type NotifyConf struct {
+	// URL is the webhook the results of connect and disconnect are posted to.
+	URL string
+	// SecretFile holds the key of HMAC-SHA256 signatures of the posted results,
+	// their events and timestamps. Results are posted unsigned without it.
+	SecretFile string
+}
+
+// AuditConf holds the '[audit]' section of the configuration file.
+type AuditConf struct {
+	// Sign enables signing of audit log entries with the identity key of the
+	// host. Entries recorded while the key cannot be loaded, e.g. before the
+	// system is connected, are unsigned; commands report the "audit-unsigned"
+	// warning, when the system is connected.
+	Sign bool
+}
+
+// CheckinConf holds the '[checkin]' section of the configuration file.
+type CheckinConf struct {
+	// Interval is the period of check-ins.
+	Interval time.Duration
+	// Jitter is the upper bound of the random delay of every check-in.
+	Jitter time.Duration
+	// Splay is the upper bound of the per-host offset of check-ins.
+	Splay time.Duration
+}
+
+// QueueConf holds the '[queue]' section of the configuration file.
+type QueueConf struct {
+	// MaxAge is the time failed operations of check-ins are retried for,
+	// before they are discarded; zero retries them until they succeed.
+	MaxAge time.Duration
+}
+
+// RetryConf holds the '[retry]' section of the configuration file.
+type RetryConf struct {
+	// Attempts is the maximum number of attempts of registration steps
+	// failing because of the network.
+	Attempts int
+	// Delay is the upper bound of the random delay of the second attempt;
+	// it doubles with every following attempt.
+	Delay time.Duration
+	// MaxDelay caps the upper bound of the delay of attempts.
+	MaxDelay time.Duration
+}
+
+// TimeoutsConf holds the '[timeouts]' section of the configuration file. A
+// zero timeout means the step is not limited.
+type TimeoutsConf struct {
+	// RHSM limits registration and unregistration with Red Hat Subscription
+	// Management, including retries.
+	RHSM time.Duration
+	// Insights limits a run of insights-client.
+	Insights time.Duration
+	// Activation limits activation and deactivation of the yggdrasil service.
+	Activation time.Duration
+}
+
+// NetworkConf holds the '[network]' section of the configuration file.
+type NetworkConf struct {
+	// Interface is the network interface outbound connections are bound to.
+	Interface string
+	// SourceAddress is the local IP address outbound connections originate from.
+	SourceAddress string
+}
+
+// ProxyConf holds the '[proxy]' section of the configuration file.
+type ProxyConf struct {
+	// URL is the proxy used for connections to Red Hat services.
+	URL string
+	// NoProxy lists hosts connected to directly, in the format of NO_PROXY.
+	NoProxy []string
+	// User and Password authenticate to the proxy.
+	User     string
+	Password string
+	// PasswordFile holds the password of the proxy, instead of Password.
+	PasswordFile string
+}
+
+// FactsConf holds the '[facts]' section of the configuration file.
+type FactsConf struct {
+	// Collectors are names of the facts collectors to run; all collectors
+	// are run when empty.
+	Collectors []string
+	// Timeouts override timeouts of collectors ('[facts.timeouts]' table).
+	Timeouts map[string]time.Duration
+}
+
+// UIConf holds the '[ui]' section of the configuration file.
+type UIConf struct {
+	// Theme is the name of the built-in theme preset.
+	Theme string
+	// Colors override escape sequences of the theme ('[ui.colors]' table).
+	Colors map[string]string
+	// Symbols override symbols of the theme ('[ui.symbols]' table).
+	Symbols map[string]string
+	// JSON is the layout of JSON documents of machine-readable output,
+	// "pretty" or "compact"; empty keeps the default layout.
+	JSON string
 }
 
 var Config = Conf{}
@@ -0,0 +1,161 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+
+	"github.com/redhatinsights/rhc/internal/util"
+)
+
+// Layer is a configuration file read by rhc. Values of later layers override
+// values of earlier layers.
+type Layer struct {
+	Path string
+	Tree *toml.Tree
+}
+
+// DropInPaths returns sorted paths of configuration drop-in files in dir.
+// A missing directory is not an error.
+func DropInPaths(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.toml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// LoadLayers reads the configuration file at path, followed by the drop-in
+// files in dropInDir in lexical order. An empty path skips the configuration
+// file; drop-in files removed meanwhile are skipped as well.
+func LoadLayers(path, dropInDir string) ([]Layer, error) {
+	var layers []Layer
+	if path != "" {
+		tree, err := toml.LoadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		layers = append(layers, Layer{Path: path, Tree: tree})
+	}
+
+	paths, err := DropInPaths(dropInDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, dropInPath := range paths {
+		tree, err := toml.LoadFile(dropInPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("invalid config file %s: %w", dropInPath, err)
+		}
+		layers = append(layers, Layer{Path: dropInPath, Tree: tree})
+	}
+	return layers, nil
+}
+
+// Merge returns the effective configuration of layers. Tables are merged key
+// by key, other values of later layers replace the values of earlier layers.
+// Nil is returned when there are no layers.
+func Merge(layers []Layer) *toml.Tree {
+	if len(layers) == 0 {
+		return nil
+	}
+	merged := newTree()
+	for _, layer := range layers {
+		mergeTree(merged, layer.Tree, nil)
+	}
+	return merged
+}
+
+// mergeTree copies the values of src into dst under the path prefix.
+func mergeTree(dst, src *toml.Tree, prefix []string) {
+	for _, key := range src.Keys() {
+		path := append(append([]string{}, prefix...), key)
+		if subtree, ok := src.GetPath([]string{key}).(*toml.Tree); ok {
+			if _, ok := dst.GetPath(path).(*toml.Tree); !ok {
+				dst.SetPath(path, newTree())
+			}
+			mergeTree(dst, subtree, path)
+			continue
+		}
+		dst.SetPath(path, src.GetPath([]string{key}))
+	}
+}
+
+// Source returns the path of the last layer setting key, or an empty string
+// when no layer sets it.
+func Source(layers []Layer, key string) string {
+	for i := len(layers) - 1; i >= 0; i-- {
+		if layers[i].Tree.Has(key) {
+			return layers[i].Path
+		}
+	}
+	return ""
+}
+
+// LoadDropIn reads the drop-in file at path for modification. An empty
+// configuration is returned when the file does not exist.
+func LoadDropIn(path string) (*toml.Tree, error) {
+	tree, err := toml.LoadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return newTree(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return tree, nil
+}
+
+// Unset removes key from tree, together with the tables left empty. It
+// reports whether the key was set.
+func Unset(tree *toml.Tree, key string) bool {
+	if !tree.Has(key) {
+		return false
+	}
+	_ = tree.Delete(key)
+	parts := strings.Split(key, ".")
+	for i := len(parts) - 1; i > 0; i-- {
+		parent, ok := tree.GetPath(parts[:i]).(*toml.Tree)
+		if !ok || len(parent.Keys()) > 0 {
+			break
+		}
+		_ = tree.DeletePath(parts[:i])
+	}
+	return true
+}
+
+// WriteDropIn writes tree to the drop-in file at path. The file is removed,
+// when tree is empty.
+func WriteDropIn(path string, tree *toml.Tree) error {
+	if len(tree.Keys()) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("cannot remove config file: %w", err)
+		}
+		return nil
+	}
+	data, err := tree.ToTomlString()
+	if err != nil {
+		return fmt.Errorf("cannot encode configuration: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot write config file: %w", err)
+	}
+	if err = util.WriteFileAtomic(path, []byte(data), 0644); err != nil {
+		return fmt.Errorf("cannot write config file: %w", err)
+	}
+	return nil
+}
+
+// newTree returns an empty configuration.
+func newTree() *toml.Tree {
+	tree, _ := toml.TreeFromMap(map[string]any{})
+	return tree
+}
@@ -0,0 +1,116 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.toml")
+	dropInDir := filepath.Join(dir, "config.toml.d")
+	if err := os.Mkdir(dropInDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, configPath, "format = \"json\"\n[proxy]\nurl = \"http://proxy:3128\"\nno-proxy = [\"a.example.com\"]\n")
+	writeFile(t, filepath.Join(dropInDir, "10-site.toml"), "[proxy]\nurl = \"http://site:3128\"\n[tags]\nenv = \"staging\"\n")
+	writeFile(t, filepath.Join(dropInDir, "99-local.toml"), "[tags]\nenv = \"production\"\n")
+	writeFile(t, filepath.Join(dropInDir, "README"), "not a configuration file")
+
+	layers, err := LoadLayers(configPath, dropInDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, layer := range layers {
+		paths = append(paths, filepath.Base(layer.Path))
+	}
+	if want := []string{"config.toml", "10-site.toml", "99-local.toml"}; !cmp.Equal(paths, want) {
+		t.Errorf("%v", cmp.Diff(paths, want))
+	}
+
+	merged := Merge(layers)
+	want := map[string]any{
+		"format": "json",
+		"proxy":  map[string]any{"url": "http://site:3128", "no-proxy": []any{"a.example.com"}},
+		"tags":   map[string]any{"env": "production"},
+	}
+	if got := merged.ToMap(); !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+	if got := Source(layers, "proxy.no-proxy"); got != configPath {
+		t.Errorf("got source %q of proxy.no-proxy, want %q", got, configPath)
+	}
+	if got := Source(layers, "tags.env"); got != filepath.Join(dropInDir, "99-local.toml") {
+		t.Errorf("got source %q of tags.env", got)
+	}
+	if got := Source(layers, "ui.theme"); got != "" {
+		t.Errorf("got source %q of unset key, want none", got)
+	}
+
+	if Merge(nil) != nil {
+		t.Errorf("got configuration without layers, want nil")
+	}
+
+	writeFile(t, filepath.Join(dropInDir, "50-invalid.toml"), "[proxy\n")
+	if _, err = LoadLayers(configPath, dropInDir); err == nil {
+		t.Errorf("got no error for invalid drop-in file")
+	}
+}
+
+func TestWriteDropIn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml.d", "99-local.toml")
+
+	tree, err := LoadDropIn(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree.Set("proxy.url", "http://proxy:3128")
+	tree.Set("tags.env", "production")
+	if err = WriteDropIn(path, tree); err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err = LoadDropIn(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Unset(tree, "proxy.url") {
+		t.Errorf("got proxy.url unset, want set")
+	}
+	if Unset(tree, "proxy.url") {
+		t.Errorf("got proxy.url set after it was removed")
+	}
+	if tree.Has("proxy") {
+		t.Errorf("got empty table 'proxy' kept")
+	}
+	if err = WriteDropIn(path, tree); err != nil {
+		t.Fatal(err)
+	}
+	tree, err = LoadDropIn(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"tags": map[string]any{"env": "production"}}; !cmp.Equal(tree.ToMap(), want) {
+		t.Errorf("%v", cmp.Diff(tree.ToMap(), want))
+	}
+
+	Unset(tree, "tags.env")
+	if err = WriteDropIn(path, tree); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v, want empty drop-in file removed", err)
+	}
+}
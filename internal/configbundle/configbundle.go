@@ -0,0 +1,193 @@
+// Package configbundle verifies and installs signed bundles of configuration
+// drop-in files of rhc.
+//
+// A bundle is a tar archive, optionally compressed by gzip, of '.toml' files.
+// The bundle is signed by a detached GPG signature, which has to be made by a
+// key of a trusted keyring.
+package configbundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+
+	"github.com/redhatinsights/rhc/internal/cleanup"
+	"github.com/redhatinsights/rhc/internal/logging"
+	"github.com/redhatinsights/rhc/internal/util"
+)
+
+// KeyringPath is the keyring of GPG keys trusted to sign configuration bundles.
+var KeyringPath = "/etc/rhc/config-keys.gpg"
+
+// maxFiles is the upper bound of the number of files in a bundle.
+const maxFiles = 64
+
+// managedHeader starts the installed files, so they are recognized as written by rhc.
+const managedHeader = "# This file is managed by rhc; changes will be overwritten.\n"
+
+// File is a configuration drop-in file of a bundle.
+type File struct {
+	Name string
+	Data []byte
+	Tree *toml.Tree
+}
+
+// Verify verifies that signature is a valid detached signature of data made by
+// a key of the keyring at keyringPath. The fingerprint of the signing key is returned.
+func Verify(data, signature []byte, keyringPath string) (string, error) {
+	if _, err := os.Stat(keyringPath); err != nil {
+		return "", fmt.Errorf("cannot read trusted keys: %w", err)
+	}
+
+	// Use an empty home directory, so the keyring of the user is not touched
+	home, err := os.MkdirTemp("", "rhc-gnupg-")
+	if err != nil {
+		return "", fmt.Errorf("cannot verify signature: %w", err)
+	}
+	release := cleanup.Push("temporary directory "+home, cleanup.Remove(home))
+	defer func() { _ = release() }()
+
+	dataPath := filepath.Join(home, "bundle")
+	signaturePath := filepath.Join(home, "bundle.sig")
+	if err = os.WriteFile(dataPath, data, 0600); err != nil {
+		return "", fmt.Errorf("cannot verify signature: %w", err)
+	}
+	if err = os.WriteFile(signaturePath, signature, 0600); err != nil {
+		return "", fmt.Errorf("cannot verify signature: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	slog.Debug(fmt.Sprintf("Executing /usr/bin/gpg --verify using keyring %s", keyringPath))
+	cmd := exec.Command(
+		"/usr/bin/gpg", "--homedir", home, "--batch", "--no-default-keyring",
+		"--keyring", keyringPath, "--trust-model", "always", "--status-fd", "1",
+		"--verify", signaturePath, dataPath,
+	)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := logging.CommandStart(cmd)
+	runErr := cmd.Run()
+	logging.Command(cmd, start, runErr)
+
+	fingerprint, ok := parseValidSignature(stdout.String())
+	if !ok {
+		if stderr.Len() > 0 {
+			return "", fmt.Errorf("invalid signature: %s", strings.TrimSpace(stderr.String()))
+		}
+		if runErr != nil {
+			return "", fmt.Errorf("invalid signature: %w", runErr)
+		}
+		return "", fmt.Errorf("invalid signature: no valid signature by a trusted key")
+	}
+	return fingerprint, nil
+}
+
+// parseValidSignature returns the fingerprint of the key of a valid signature
+// reported by 'gpg --status-fd'. The signature has to be reported both good
+// and valid; expired signatures and signatures by expired or revoked keys are
+// not valid.
+func parseValidSignature(status string) (string, bool) {
+	fingerprint := ""
+	good := false
+	for _, line := range strings.Split(status, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != "[GNUPG:]" {
+			continue
+		}
+		switch fields[1] {
+		case "GOODSIG":
+			good = true
+		case "VALIDSIG":
+			fingerprint = fields[2]
+		case "BADSIG", "EXPSIG", "EXPKEYSIG", "REVKEYSIG", "ERRSIG":
+			return "", false
+		}
+	}
+	if !good || fingerprint == "" {
+		return "", false
+	}
+	return fingerprint, true
+}
+
+// Extract returns the configuration files of the bundle data. Every file has
+// to be a valid TOML document in the top-level directory of the archive.
+func Extract(data []byte) ([]File, error) {
+	var reader io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decompress bundle: %w", err)
+		}
+		defer func() { _ = gz.Close() }()
+		reader = gz
+	}
+
+	var files []File
+	archive := tar.NewReader(bufio.NewReader(reader))
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read bundle: %w", err)
+		}
+		if header.Typeflag == tar.TypeDir && path.Clean(header.Name) == "." {
+			continue
+		}
+
+		name := path.Clean(header.Name)
+		if header.Typeflag != tar.TypeReg || strings.Contains(name, "/") || !strings.HasSuffix(name, ".toml") {
+			return nil, fmt.Errorf("invalid bundle: unexpected entry %q: only .toml files are allowed", header.Name)
+		}
+		if len(files) == maxFiles {
+			return nil, fmt.Errorf("invalid bundle: more than %d files", maxFiles)
+		}
+
+		content, err := io.ReadAll(archive)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read bundle: %w", err)
+		}
+		tree, err := toml.LoadBytes(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundle: %s: %w", name, err)
+		}
+		files = append(files, File{Name: name, Data: content, Tree: tree})
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("invalid bundle: no configuration files")
+	}
+	return files, nil
+}
+
+// Install writes files into dir. Every file starts with a header, which marks
+// it as written by rhc. The paths of the installed files are returned.
+func Install(files []File, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("cannot install configuration: %w", err)
+	}
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		filePath := filepath.Join(dir, file.Name)
+		data := append([]byte(managedHeader), file.Data...)
+		if err := util.WriteFileAtomic(filePath, data, 0644); err != nil {
+			return paths, fmt.Errorf("cannot install configuration: %w", err)
+		}
+		slog.Debug("Installed configuration drop-in", "path", filePath)
+		paths = append(paths, filePath)
+	}
+	return paths, nil
+}
@@ -0,0 +1,184 @@
+package configbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// archive returns a tar archive of entries, compressed by gzip when compress is set.
+func archive(t *testing.T, entries map[string]string, compress bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var gz *gzip.Writer
+	var tw *tar.Writer
+	if compress {
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+	} else {
+		tw = tar.NewWriter(&buf)
+	}
+	for name, content := range entries {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		description string
+		entries     map[string]string
+		compress    bool
+		want        []string
+		wantError   bool
+	}{
+		{
+			description: "tar archive",
+			entries:     map[string]string{"50-proxy.toml": "[proxy]\nurl = \"http://proxy:3128\"\n"},
+			want:        []string{"50-proxy.toml"},
+		},
+		{
+			description: "gzip compressed archive",
+			entries:     map[string]string{"./50-tags.toml": "[tags]\nsite = \"edge\"\n"},
+			compress:    true,
+			want:        []string{"50-tags.toml"},
+		},
+		{
+			description: "file in a subdirectory",
+			entries:     map[string]string{"etc/50-proxy.toml": ""},
+			wantError:   true,
+		},
+		{
+			description: "file outside of the archive",
+			entries:     map[string]string{"../50-proxy.toml": ""},
+			wantError:   true,
+		},
+		{
+			description: "file without the .toml suffix",
+			entries:     map[string]string{"script.sh": "#!/bin/sh\n"},
+			wantError:   true,
+		},
+		{
+			description: "invalid TOML",
+			entries:     map[string]string{"50-proxy.toml": "[proxy\n"},
+			wantError:   true,
+		},
+		{
+			description: "empty archive",
+			entries:     map[string]string{},
+			wantError:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			files, err := Extract(archive(t, test.entries, test.compress))
+			if test.wantError {
+				if err == nil {
+					t.Errorf("expected error, got %v files", len(files))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, file := range files {
+				got = append(got, file.Name)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
+
+func TestParseValidSignature(t *testing.T) {
+	tests := []struct {
+		description string
+		status      string
+		want        string
+		wantOK      bool
+	}{
+		{
+			description: "valid signature",
+			status: "[GNUPG:] NEWSIG\n" +
+				"[GNUPG:] GOODSIG 199E2F91FD431D51 Red Hat, Inc.\n" +
+				"[GNUPG:] VALIDSIG 567E347AD0044ADE55BA8A5F199E2F91FD431D51 2025-01-31 1738281600 0 4 0 1 8 00 567E347AD0044ADE55BA8A5F199E2F91FD431D51\n",
+			want:   "567E347AD0044ADE55BA8A5F199E2F91FD431D51",
+			wantOK: true,
+		},
+		{
+			description: "bad signature",
+			status:      "[GNUPG:] NEWSIG\n[GNUPG:] BADSIG 199E2F91FD431D51 Red Hat, Inc.\n",
+		},
+		{
+			description: "expired key",
+			status: "[GNUPG:] EXPKEYSIG 199E2F91FD431D51 Red Hat, Inc.\n" +
+				"[GNUPG:] VALIDSIG 567E347AD0044ADE55BA8A5F199E2F91FD431D51 2025-01-31 1738281600 0 4 0 1 8 00 567E347AD0044ADE55BA8A5F199E2F91FD431D51\n",
+		},
+		{
+			description: "expired signature",
+			status: "[GNUPG:] EXPSIG 199E2F91FD431D51 Red Hat, Inc.\n" +
+				"[GNUPG:] VALIDSIG 567E347AD0044ADE55BA8A5F199E2F91FD431D51 2025-01-31 1738281600 0 4 0 1 8 00 567E347AD0044ADE55BA8A5F199E2F91FD431D51\n",
+		},
+		{
+			description: "valid without good signature",
+			status:      "[GNUPG:] VALIDSIG 567E347AD0044ADE55BA8A5F199E2F91FD431D51 2025-01-31 1738281600 0 4 0 1 8 00 567E347AD0044ADE55BA8A5F199E2F91FD431D51\n",
+		},
+		{
+			description: "unknown key",
+			status:      "[GNUPG:] ERRSIG 199E2F91FD431D51 1 8 00 1738281600 9 -\n[GNUPG:] NO_PUBKEY 199E2F91FD431D51\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, ok := parseValidSignature(test.status)
+			if ok != test.wantOK || got != test.want {
+				t.Errorf("got (%q, %v), want (%q, %v)", got, ok, test.want, test.wantOK)
+			}
+		})
+	}
+}
+
+func TestInstall(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "config.toml.d")
+	files := []File{{Name: "50-tags.toml", Data: []byte("[tags]\nsite = \"edge\"\n")}}
+
+	paths, err := Install(files, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "50-tags.toml")}
+	if !cmp.Equal(paths, want) {
+		t.Errorf("%v", cmp.Diff(paths, want))
+	}
+	data, err := os.ReadFile(want[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantData := "# This file is managed by rhc; changes will be overwritten.\n[tags]\nsite = \"edge\"\n"
+	if string(data) != wantData {
+		t.Errorf("%v", cmp.Diff(string(data), wantData))
+	}
+}
@@ -0,0 +1,131 @@
+package datacollection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// InsightsAPIURL is the base URL of the Red Hat Lightspeed API authenticated
+// with the consumer certificate, or with a service account when one is configured.
+var InsightsAPIURL = "https://cert.console.redhat.com/api"
+
+// InsightsMachineIDPath is the path to the identifier of the host in Inventory.
+const InsightsMachineIDPath = "/etc/insights-client/machine-id"
+
+// upgradeTags are advisor rule tags marking findings relevant to in-place upgrades.
+var upgradeTags = []string{"leapp", "upgrade", "in_place_upgrade"}
+
+// inhibitorTag is the advisor rule tag marking findings blocking in-place upgrades.
+const inhibitorTag = "inhibitor"
+
+// UpgradeFinding is an advisor finding relevant to in-place upgrade of the system.
+type UpgradeFinding struct {
+	RuleID      string `json:"rule_id"`
+	Description string `json:"description"`
+	// TotalRisk is the risk of the finding from 1 (low) to 4 (critical).
+	TotalRisk int `json:"total_risk"`
+	// Inhibitor is true when the finding blocks the upgrade.
+	Inhibitor bool `json:"inhibitor"`
+}
+
+// UpgradeReadiness summarizes findings relevant to in-place upgrade of the system.
+type UpgradeReadiness struct {
+	// Ready is true when no finding blocks the upgrade.
+	Ready    bool             `json:"ready"`
+	Blockers []UpgradeFinding `json:"blockers"`
+	Findings []UpgradeFinding `json:"findings"`
+}
+
+// advisorReport is a single report of the advisor system reports API.
+type advisorReport struct {
+	Rule struct {
+		RuleID      string `json:"rule_id"`
+		Description string `json:"description"`
+		TotalRisk   int    `json:"total_risk"`
+		// Tags are separated by spaces.
+		Tags string `json:"tags"`
+	} `json:"rule"`
+}
+
+// InsightsMachineID returns the identifier of the host in Inventory.
+func InsightsMachineID() (string, error) {
+	data, err := os.ReadFile(InsightsMachineIDPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrSystemNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not read insights machine-id: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// GetUpgradeReadiness queries the advisor API for findings of the system identified
+// by machineID, and summarizes those relevant to in-place upgrade. The client has to
+// authenticate with the consumer certificate of the system or a service account.
+func GetUpgradeReadiness(ctx context.Context, client *http.Client, machineID string) (UpgradeReadiness, error) {
+	reportsURL, err := url.JoinPath(InsightsAPIURL, "insights/v1/system", machineID, "reports/")
+	if err != nil {
+		return UpgradeReadiness{}, fmt.Errorf("invalid advisor URL: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reportsURL, nil)
+	if err != nil {
+		return UpgradeReadiness{}, fmt.Errorf("could not create advisor request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	slog.Debug("Querying advisor reports", "url", reportsURL)
+	resp, err := client.Do(req)
+	if err != nil {
+		return UpgradeReadiness{}, fmt.Errorf("could not query advisor: %w", &networkError{err: err})
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return UpgradeReadiness{}, ErrSystemNotFound
+	case resp.StatusCode != http.StatusOK:
+		return UpgradeReadiness{}, newAPIError("advisor", resp)
+	}
+
+	var reports []advisorReport
+	if err := json.NewDecoder(resp.Body).Decode(&reports); err != nil {
+		return UpgradeReadiness{}, fmt.Errorf("could not parse advisor reports: %w", err)
+	}
+	return summarizeUpgradeReports(reports), nil
+}
+
+// summarizeUpgradeReports selects reports relevant to in-place upgrade. Findings
+// tagged as inhibitors are blockers; blockers are not repeated in findings.
+func summarizeUpgradeReports(reports []advisorReport) UpgradeReadiness {
+	readiness := UpgradeReadiness{Ready: true, Blockers: []UpgradeFinding{}, Findings: []UpgradeFinding{}}
+	for _, report := range reports {
+		tags := strings.Fields(report.Rule.Tags)
+		relevant := slices.ContainsFunc(tags, func(tag string) bool {
+			return slices.Contains(upgradeTags, tag)
+		})
+		if !relevant {
+			continue
+		}
+		finding := UpgradeFinding{
+			RuleID:      report.Rule.RuleID,
+			Description: report.Rule.Description,
+			TotalRisk:   report.Rule.TotalRisk,
+			Inhibitor:   slices.Contains(tags, inhibitorTag),
+		}
+		if finding.Inhibitor {
+			readiness.Ready = false
+			readiness.Blockers = append(readiness.Blockers, finding)
+		} else {
+			readiness.Findings = append(readiness.Findings, finding)
+		}
+	}
+	return readiness
+}
@@ -0,0 +1,84 @@
+package datacollection
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetUpgradeReadiness(t *testing.T) {
+	tests := []struct {
+		description string
+		status      int
+		body        string
+		want        UpgradeReadiness
+		wantError   error
+	}{
+		{
+			description: "inhibitor blocks upgrade",
+			status:      http.StatusOK,
+			body: `[
+				{"rule": {"rule_id": "el8_to_el9_upgrade|RHEL8_TO_RHEL9_UPGRADE_AVAILABLE", "description": "Upgrade available", "total_risk": 1, "tags": "leapp upgrade"}},
+				{"rule": {"rule_id": "leapp_inhibitor|NFS_MOUNTED", "description": "NFS is mounted", "total_risk": 3, "tags": "leapp inhibitor"}},
+				{"rule": {"rule_id": "kernel_panic|KDUMP", "description": "Kdump is not configured", "total_risk": 2, "tags": "kernel"}}
+			]`,
+			want: UpgradeReadiness{
+				Ready: false,
+				Blockers: []UpgradeFinding{
+					{RuleID: "leapp_inhibitor|NFS_MOUNTED", Description: "NFS is mounted", TotalRisk: 3, Inhibitor: true},
+				},
+				Findings: []UpgradeFinding{
+					{RuleID: "el8_to_el9_upgrade|RHEL8_TO_RHEL9_UPGRADE_AVAILABLE", Description: "Upgrade available", TotalRisk: 1},
+				},
+			},
+		},
+		{
+			description: "no relevant findings",
+			status:      http.StatusOK,
+			body:        `[{"rule": {"rule_id": "kernel_panic|KDUMP", "total_risk": 2, "tags": "kernel"}}]`,
+			want:        UpgradeReadiness{Ready: true, Blockers: []UpgradeFinding{}, Findings: []UpgradeFinding{}},
+		},
+		{
+			description: "unknown system",
+			status:      http.StatusNotFound,
+			wantError:   ErrSystemNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			var path string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			oldURL := InsightsAPIURL
+			InsightsAPIURL = server.URL + "/api"
+			t.Cleanup(func() { InsightsAPIURL = oldURL })
+
+			got, err := GetUpgradeReadiness(context.Background(), server.Client(), "1234")
+			if path != "/api/insights/v1/system/1234/reports/" {
+				t.Errorf("unexpected request path %q", path)
+			}
+			if test.wantError != nil {
+				if !errors.Is(err, test.wantError) {
+					t.Errorf("got error %v, want %v", err, test.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
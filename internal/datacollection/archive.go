@@ -0,0 +1,85 @@
+package datacollection
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/logging"
+)
+
+// InsightsArchiveDir is the directory insights-client keeps uploaded archives
+// in, when 'keep_archive' is enabled in insights-client.conf.
+var InsightsArchiveDir = "/var/cache/insights-client"
+
+// InsightsArchive is an archive uploaded and kept by insights-client.
+type InsightsArchive struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// LastInsightsArchive returns the most recent archive kept by insights-client,
+// or nil when no archive is kept.
+func LastInsightsArchive() (*InsightsArchive, error) {
+	paths, err := filepath.Glob(filepath.Join(InsightsArchiveDir, "insights-*.tar.*"))
+	if err != nil {
+		return nil, err
+	}
+	var last *InsightsArchive
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot check archive: %w", err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		if last == nil || info.ModTime().After(last.Modified) {
+			last = &InsightsArchive{Path: path, Size: info.Size(), Modified: info.ModTime()}
+		}
+	}
+	return last, nil
+}
+
+// ExtractInsightsArchive extracts the archive at path into dir, so its content
+// can be inspected. The directory is created when missing; an existing
+// directory has to be empty. Ownership and permissions of archived files are
+// not restored.
+func ExtractInsightsArchive(path, dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("cannot create directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("cannot read directory: %w", err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("directory %s is not empty", dir)
+	}
+
+	var stderr bytes.Buffer
+	slog.Debug(fmt.Sprintf("Executing /usr/bin/tar --extract --file %s --directory %s", path, dir))
+	cmd := exec.Command(
+		"/usr/bin/tar", "--extract", "--file", path, "--directory", dir,
+		"--no-same-owner", "--no-same-permissions",
+	)
+	cmd.Stderr = &stderr
+	start := logging.CommandStart(cmd)
+	err = cmd.Run()
+	logging.Command(cmd, start, err)
+	if err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) && stderr.Len() > 0 {
+			return fmt.Errorf("cannot extract archive: %s", strings.TrimSpace(stderr.String()))
+		}
+		return fmt.Errorf("cannot extract archive: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package datacollection
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLastInsightsArchive(t *testing.T) {
+	oldDir := InsightsArchiveDir
+	InsightsArchiveDir = t.TempDir()
+	t.Cleanup(func() { InsightsArchiveDir = oldDir })
+
+	archive, err := LastInsightsArchive()
+	if err != nil || archive != nil {
+		t.Fatalf("got (%v, %v) without archives, want (nil, nil)", archive, err)
+	}
+
+	now := time.Now()
+	files := map[string]time.Time{
+		"insights-node1-20250130020000.tar.gz": now.Add(-24 * time.Hour),
+		"insights-node1-20250131020000.tar.gz": now,
+		"insights-client.log":                  now.Add(time.Hour),
+	}
+	for name, modified := range files {
+		path := filepath.Join(InsightsArchiveDir, name)
+		if err := os.WriteFile(path, []byte("archive"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	archive, err = LastInsightsArchive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPath := filepath.Join(InsightsArchiveDir, "insights-node1-20250131020000.tar.gz")
+	if archive == nil || archive.Path != wantPath || archive.Size != 7 {
+		t.Errorf("got %+v, want %s of size 7", archive, wantPath)
+	}
+}
+
+func TestExtractInsightsArchiveNotEmpty(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExtractInsightsArchive(filepath.Join(dir, "missing.tar.gz"), dir); err == nil {
+		t.Error("expected error for non-empty directory")
+	}
+}
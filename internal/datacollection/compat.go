@@ -0,0 +1,111 @@
+package datacollection
+
+import (
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/redhatinsights/rhc/internal/logging"
+)
+
+// UnsupportedFlagError is returned, when the installed insights-client is too
+// old to support a flag rhc relies on.
+type UnsupportedFlagError struct {
+	Flag string
+	// Version is the version reported by insights-client, when it is known.
+	Version string
+}
+
+func (e *UnsupportedFlagError) Error() string {
+	version := ""
+	if e.Version != "" {
+		version = fmt.Sprintf(" (%s)", e.Version)
+	}
+	return fmt.Sprintf("installed insights-client%s does not support %s; update insights-client to a newer version", version, e.Flag)
+}
+
+// insightsClientHelp returns the help of insights-client listing the flags it
+// supports. It is run once per process.
+var insightsClientHelp = sync.OnceValues(func() (string, error) {
+	slog.Debug("Executing /usr/bin/insights-client --help")
+	cmd := exec.Command("/usr/bin/insights-client", "--help")
+	start := logging.CommandStart(cmd)
+	out, err := cmd.Output()
+	logging.Command(cmd, start, err)
+	return string(out), err
+})
+
+// insightsClientVersion returns the output of 'insights-client --version'. It
+// is run once per process.
+var insightsClientVersion = sync.OnceValues(func() (string, error) {
+	slog.Debug("Executing /usr/bin/insights-client --version")
+	cmd := exec.Command("/usr/bin/insights-client", "--version")
+	start := logging.CommandStart(cmd)
+	out, err := cmd.Output()
+	logging.Command(cmd, start, err)
+	if err != nil {
+		return "", fmt.Errorf("cannot get version of insights-client: %w", err)
+	}
+	return string(out), nil
+})
+
+// InsightsClientVersion returns the versions of the insights-client wrapper
+// and of the insights-core egg it runs, e.g. "Client: 3.2.2, Core: 3.2.28".
+func InsightsClientVersion() (string, error) {
+	out, err := insightsClientVersion()
+	if err != nil {
+		return "", err
+	}
+	return formatInsightsClientVersion(out), nil
+}
+
+// formatInsightsClientVersion joins the lines of the output of 'insights-client
+// --version'. Old versions print only the version of insights-core.
+func formatInsightsClientVersion(output string) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, ", ")
+}
+
+// helpListsFlag reports whether the help of a command line tool lists flag,
+// either in the usage line or among the options.
+func helpListsFlag(help string, flag string) bool {
+	pattern := regexp.MustCompile(`(^|[\s,\[])` + regexp.QuoteMeta(flag) + `([\s,=\]]|$)`)
+	return pattern.MatchString(help)
+}
+
+// insightsClientSupports reports whether the installed insights-client supports
+// flag. When its flags cannot be listed, the flag is assumed to be supported,
+// and insights-client reports the error itself.
+func insightsClientSupports(flag string) bool {
+	help, err := insightsClientHelp()
+	if err != nil {
+		slog.Debug("Unable to list flags of insights-client", "err", err)
+		return true
+	}
+	return helpListsFlag(help, flag)
+}
+
+// checkInsightsClientFlags returns UnsupportedFlagError for the first flag of
+// args the installed insights-client does not support.
+func checkInsightsClientFlags(args []string) error {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") || insightsClientSupports(arg) {
+			continue
+		}
+		version, err := InsightsClientVersion()
+		if err != nil {
+			slog.Debug(err.Error())
+		}
+		slog.Error("insights-client does not support flag", "flag", arg, "version", version)
+		return &UnsupportedFlagError{Flag: arg, Version: version}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package datacollection
+
+import "testing"
+
+const insightsClientHelpOutput = `usage: insights-client [-h] [--register] [--unregister] [--display-name DISPLAYNAME]
+
+optional arguments:
+  --register            Register system to the Red Hat Insights Service
+  --display-name DISPLAYNAME
+                        Display name for this system.
+  --checkin             Do a lightweight check-in instead of full upload
+  --no-upload           Do not upload the archive
+`
+
+func TestHelpListsFlag(t *testing.T) {
+	tests := []struct {
+		flag string
+		want bool
+	}{
+		{flag: "--register", want: true},
+		{flag: "--display-name", want: true},
+		{flag: "--checkin", want: true},
+		{flag: "--no-upload", want: true},
+		{flag: "--unregister", want: true},
+		{flag: "--check", want: false},
+		{flag: "--upload", want: false},
+		{flag: "--status", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.flag, func(t *testing.T) {
+			if got := helpListsFlag(insightsClientHelpOutput, test.flag); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatInsightsClientVersion(t *testing.T) {
+	tests := []struct {
+		description string
+		output      string
+		want        string
+	}{
+		{description: "client and core", output: "Client: 3.2.2\nCore: 3.2.28\n", want: "Client: 3.2.2, Core: 3.2.28"},
+		{description: "core only", output: "3.0.8-1\n", want: "3.0.8-1"},
+		{description: "empty", output: "", want: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if got := formatInsightsClientVersion(test.output); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUnsupportedFlagError(t *testing.T) {
+	err := &UnsupportedFlagError{Flag: "--checkin", Version: "3.0.8-1"}
+	want := "installed insights-client (3.0.8-1) does not support --checkin; update insights-client to a newer version"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,224 @@
+package datacollection
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/redhatinsights/rhc/internal/util"
+)
+
+// InsightsConfigPath is the path to the configuration file of insights-client.
+var InsightsConfigPath = "/etc/insights-client/insights-client.conf"
+
+// insightsConfigSection is the section of insights-client.conf holding its options.
+const insightsConfigSection = "insights-client"
+
+// InsightsConfigKeys are the options of insights-client.conf managed by rhc.
+var InsightsConfigKeys = []string{"proxy", "obfuscate", "obfuscate_hostname", "auto_update", "keep_archive", "base_url"}
+
+// NormalizeInsightsConfigValue validates value of the option key and returns it
+// in the form insights-client expects. Boolean options accept common spellings
+// (e.g. "yes", "1") and are converted to "True" or "False". The base URL is
+// accepted as an https URL, too, and it is converted to HOST:PORT/PATH. An
+// empty proxy or base URL removes the option.
+func NormalizeInsightsConfigValue(key, value string) (string, error) {
+	switch key {
+	case "proxy":
+		if value == "" {
+			return "", nil
+		}
+		proxyURL, err := url.Parse(value)
+		if err != nil || proxyURL.Host == "" {
+			return "", fmt.Errorf("invalid proxy URL %q", RedactURL(value))
+		}
+		if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" {
+			return "", fmt.Errorf("unsupported proxy scheme %q (supported schemes: http, https)", proxyURL.Scheme)
+		}
+		return value, nil
+	case "base_url":
+		if value == "" {
+			return "", nil
+		}
+		rawURL := value
+		if !strings.Contains(value, "://") {
+			rawURL = "https://" + value
+		}
+		baseURL, err := url.Parse(rawURL)
+		if err != nil || baseURL.Scheme != "https" || baseURL.Hostname() == "" || baseURL.User != nil {
+			return "", fmt.Errorf("invalid base URL %q: expected an https URL or HOST[:PORT][/PATH]", value)
+		}
+		port := baseURL.Port()
+		if port == "" {
+			port = "443"
+		}
+		return net.JoinHostPort(baseURL.Hostname(), port) + strings.TrimSuffix(baseURL.Path, "/"), nil
+	case "obfuscate", "obfuscate_hostname", "auto_update", "keep_archive":
+		switch strings.ToLower(value) {
+		case "true", "yes", "on", "1":
+			return "True", nil
+		case "false", "no", "off", "0":
+			return "False", nil
+		}
+		return "", fmt.Errorf("invalid value %q of '%s': expected true or false", value, key)
+	}
+	return "", fmt.Errorf("unsupported option '%s' (supported options: %s)", key, strings.Join(InsightsConfigKeys, ", "))
+}
+
+// RedactURL returns rawURL with the password replaced, so it can be logged.
+func RedactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	return parsed.Redacted()
+}
+
+// parseOptionLine returns the key of the option set on line, or an empty
+// string when line does not set an option (e.g. it is a comment).
+func parseOptionLine(line string) string {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "[") {
+		return ""
+	}
+	key, _, found := strings.Cut(line, "=")
+	if !found {
+		key, _, found = strings.Cut(line, ":")
+	}
+	if !found {
+		return ""
+	}
+	return strings.TrimSpace(key)
+}
+
+// parseSectionLine returns the name of the section started on line,
+// or an empty string when line does not start a section.
+func parseSectionLine(line string) string {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+		return strings.TrimSpace(line[1 : len(line)-1])
+	}
+	return ""
+}
+
+// setConfigOption returns content of an INI file with the option key of section
+// set to value. Comments and other options are kept. When value is empty, the option
+// is removed. The section is appended when it does not exist.
+func setConfigOption(content, section, key, value string) string {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+	newLine := fmt.Sprintf("%s=%s", key, value)
+
+	var result []string
+	current := ""
+	sectionEnd := -1
+	done := false
+	for _, line := range lines {
+		if name := parseSectionLine(line); name != "" {
+			if current == section {
+				sectionEnd = len(result)
+			}
+			current = name
+		}
+		if current == section && parseOptionLine(line) == key {
+			if value != "" && !done {
+				result = append(result, newLine)
+			}
+			done = true
+			continue
+		}
+		result = append(result, line)
+	}
+	if current == section {
+		sectionEnd = len(result)
+	}
+
+	if !done && value != "" {
+		if sectionEnd < 0 {
+			if len(result) > 0 && strings.TrimSpace(result[len(result)-1]) != "" {
+				result = append(result, "")
+			}
+			result = append(result, "["+section+"]", newLine)
+		} else {
+			// Keep blank lines separating the section from the next one
+			for sectionEnd > 0 && strings.TrimSpace(result[sectionEnd-1]) == "" {
+				sectionEnd--
+			}
+			result = slices.Insert(result, sectionEnd, newLine)
+		}
+	}
+	return strings.Join(result, "\n") + "\n"
+}
+
+// GetInsightsConfigValues returns the values of options in InsightsConfigKeys
+// set in the insights-client configuration file.
+func GetInsightsConfigValues() (map[string]string, error) {
+	data, err := os.ReadFile(InsightsConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", InsightsConfigPath, err)
+	}
+	values := make(map[string]string)
+	current := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		if name := parseSectionLine(line); name != "" {
+			current = name
+			continue
+		}
+		key := parseOptionLine(line)
+		if current != insightsConfigSection || !slices.Contains(InsightsConfigKeys, key) {
+			continue
+		}
+		_, value, found := strings.Cut(line, "=")
+		if !found {
+			_, value, _ = strings.Cut(line, ":")
+		}
+		values[key] = strings.TrimSpace(value)
+	}
+	return values, nil
+}
+
+// SetInsightsConfigValue validates value of the option key and sets it in the
+// insights-client configuration file. The file is replaced atomically. When
+// the proxy is set with credentials, the file is made readable only by its
+// owner, as insights-client.conf is readable by every user by default.
+func SetInsightsConfigValue(key, value string) error {
+	value, err := NormalizeInsightsConfigValue(key, value)
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	data, err := os.ReadFile(InsightsConfigPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot read %s: %w", InsightsConfigPath, err)
+	}
+	if info, err := os.Stat(InsightsConfigPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	content := setConfigOption(string(data), insightsConfigSection, key, value)
+	logValue := value
+	if key == "proxy" && value != "" {
+		logValue = RedactURL(value)
+		if parsed, err := url.Parse(value); err == nil && parsed.User != nil {
+			mode &^= 0077
+		}
+	}
+	slog.Debug("Setting insights-client option", "path", InsightsConfigPath, "key", key, "value", strconv.Quote(logValue))
+
+	if err = os.MkdirAll(filepath.Dir(InsightsConfigPath), 0755); err != nil {
+		return fmt.Errorf("cannot write %s: %w", InsightsConfigPath, err)
+	}
+	if err = util.WriteFileAtomic(InsightsConfigPath, []byte(content), mode); err != nil {
+		return fmt.Errorf("cannot write %s: %w", InsightsConfigPath, err)
+	}
+	return nil
+}
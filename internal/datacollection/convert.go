@@ -0,0 +1,127 @@
+package datacollection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/redhatinsights/rhc/internal/logging"
+)
+
+// ConvertReportPath is the report of the pre-conversion analysis of convert2rhel.
+const ConvertReportPath = "/var/log/convert2rhel/convert2rhel-pre-conversion.json"
+
+// convertAnalyzeCommand is the pre-conversion analysis run by the "Pre-conversion
+// analysis for converting to RHEL" task of Red Hat Lightspeed.
+var convertAnalyzeCommand = []string{"/usr/bin/convert2rhel", "analyze", "-y"}
+
+// convertInhibitorLevels are levels of results of convert2rhel blocking the conversion.
+var convertInhibitorLevels = []string{"ERROR", "OVERRIDABLE"}
+
+// ConvertFinding is a result of the pre-conversion analysis of convert2rhel.
+type ConvertFinding struct {
+	ActionID     string `json:"action_id"`
+	Level        string `json:"level"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Remediations string `json:"remediations,omitempty"`
+	// Inhibitor is true when the finding blocks the conversion.
+	Inhibitor bool `json:"inhibitor"`
+}
+
+// ConvertAnalysis summarizes the pre-conversion analysis of convert2rhel.
+type ConvertAnalysis struct {
+	// Ready is true when no finding blocks the conversion.
+	Ready    bool             `json:"ready"`
+	Blockers []ConvertFinding `json:"blockers"`
+	Findings []ConvertFinding `json:"findings"`
+}
+
+// convertResult is a result or a message of an action of convert2rhel.
+type convertResult struct {
+	Level        string `json:"level"`
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Remediations string `json:"remediations"`
+}
+
+// convertReport is the report of the pre-conversion analysis of convert2rhel.
+type convertReport struct {
+	Actions map[string]struct {
+		Messages []convertResult `json:"messages"`
+		Result   convertResult   `json:"result"`
+	} `json:"actions"`
+}
+
+// RunConvertAnalysis runs the pre-conversion analysis of convert2rhel, the same
+// analysis the task of Red Hat Lightspeed runs, and summarizes its report. The
+// analysis does not change the system.
+func RunConvertAnalysis(ctx context.Context) (ConvertAnalysis, error) {
+	slog.Debug("Executing " + strings.Join(convertAnalyzeCommand, " "))
+	cmd := exec.CommandContext(ctx, convertAnalyzeCommand[0], convertAnalyzeCommand[1:]...)
+	start := logging.CommandStart(cmd)
+	output, err := cmd.CombinedOutput()
+	logging.Command(cmd, start, err)
+	if ctx.Err() != nil {
+		return ConvertAnalysis{}, fmt.Errorf("convert2rhel did not finish in time: %w", ctx.Err())
+	}
+	// convert2rhel exits with an error when the conversion is blocked; the
+	// report is written in that case too
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return ConvertAnalysis{}, fmt.Errorf("cannot run convert2rhel: %w", err)
+	}
+	data, readErr := os.ReadFile(ConvertReportPath)
+	if readErr != nil {
+		if err != nil {
+			return ConvertAnalysis{}, fmt.Errorf("convert2rhel failed: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		return ConvertAnalysis{}, fmt.Errorf("cannot read report of convert2rhel: %w", readErr)
+	}
+	return parseConvertReport(data)
+}
+
+// parseConvertReport summarizes the report of the pre-conversion analysis.
+// Results and messages of actions of level ERROR or OVERRIDABLE are blockers,
+// those of level WARNING are findings. Findings are sorted by their actions.
+func parseConvertReport(data []byte) (ConvertAnalysis, error) {
+	var report convertReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return ConvertAnalysis{}, fmt.Errorf("could not parse report of convert2rhel: %w", err)
+	}
+
+	analysis := ConvertAnalysis{Ready: true, Blockers: []ConvertFinding{}, Findings: []ConvertFinding{}}
+	actionIDs := make([]string, 0, len(report.Actions))
+	for actionID := range report.Actions {
+		actionIDs = append(actionIDs, actionID)
+	}
+	slices.Sort(actionIDs)
+	for _, actionID := range actionIDs {
+		action := report.Actions[actionID]
+		for _, result := range append([]convertResult{action.Result}, action.Messages...) {
+			finding := ConvertFinding{
+				ActionID:     actionID,
+				Level:        result.Level,
+				Title:        result.Title,
+				Description:  result.Description,
+				Remediations: result.Remediations,
+				Inhibitor:    slices.Contains(convertInhibitorLevels, result.Level),
+			}
+			switch {
+			case finding.Inhibitor:
+				analysis.Ready = false
+				analysis.Blockers = append(analysis.Blockers, finding)
+			case result.Level == "WARNING":
+				analysis.Findings = append(analysis.Findings, finding)
+			}
+		}
+	}
+	return analysis, nil
+}
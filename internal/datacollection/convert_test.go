@@ -0,0 +1,88 @@
+package datacollection
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseConvertReport(t *testing.T) {
+	tests := []struct {
+		description string
+		report      string
+		want        ConvertAnalysis
+		wantError   bool
+	}{
+		{
+			description: "inhibitor blocks conversion",
+			report: `{
+				"format_version": "1.2",
+				"status": "ERROR",
+				"actions": {
+					"IS_LOADED_KERNEL_LATEST": {
+						"messages": [],
+						"result": {"level": "ERROR", "id": "INVALID_KERNEL_VERSION", "title": "Newer kernel available", "description": "The loaded kernel is not the latest.", "diagnosis": "", "remediations": "Update the kernel and reboot.", "variables": {}}
+					},
+					"LIST_THIRD_PARTY_PACKAGES": {
+						"messages": [],
+						"result": {"level": "WARNING", "id": "THIRD_PARTY_PACKAGE_DETECTED", "title": "Third party packages detected", "description": "Packages not signed by the vendor are installed.", "diagnosis": "", "remediations": "", "variables": {}}
+					},
+					"EFI": {
+						"messages": [{"level": "WARNING", "id": "SECURE_BOOT", "title": "Secure Boot", "description": "Secure Boot is enabled.", "remediations": ""}],
+						"result": {"level": "SUCCESS", "id": "SUCCESS", "title": "", "description": "", "remediations": ""}
+					}
+				}
+			}`,
+			want: ConvertAnalysis{
+				Ready: false,
+				Blockers: []ConvertFinding{
+					{
+						ActionID:     "IS_LOADED_KERNEL_LATEST",
+						Level:        "ERROR",
+						Title:        "Newer kernel available",
+						Description:  "The loaded kernel is not the latest.",
+						Remediations: "Update the kernel and reboot.",
+						Inhibitor:    true,
+					},
+				},
+				Findings: []ConvertFinding{
+					{ActionID: "EFI", Level: "WARNING", Title: "Secure Boot", Description: "Secure Boot is enabled."},
+					{
+						ActionID:    "LIST_THIRD_PARTY_PACKAGES",
+						Level:       "WARNING",
+						Title:       "Third party packages detected",
+						Description: "Packages not signed by the vendor are installed.",
+					},
+				},
+			},
+		},
+		{
+			description: "ready",
+			report:      `{"status": "SUCCESS", "actions": {"EFI": {"messages": [], "result": {"level": "SUCCESS", "id": "SUCCESS"}}}}`,
+			want:        ConvertAnalysis{Ready: true, Blockers: []ConvertFinding{}, Findings: []ConvertFinding{}},
+		},
+		{
+			description: "invalid report",
+			report:      `{"actions": []}`,
+			wantError:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := parseConvertReport([]byte(test.report))
+			if test.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package datacollection
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrSystemNotFound is returned when the system is not known to Red Hat Lightspeed.
+var ErrSystemNotFound = errors.New("system is not known to Red Hat Lightspeed")
+
+// ErrAuth is matched by errors of the Red Hat Lightspeed API rejecting the
+// identity of the system.
+var ErrAuth = errors.New("authentication with Red Hat Lightspeed failed")
+
+// ErrNetwork is matched by errors of the Red Hat Lightspeed API that cannot be
+// reached.
+var ErrNetwork = errors.New("Red Hat Lightspeed cannot be reached")
+
+// networkError is a failure to send a request to the Red Hat Lightspeed API. It
+// matches both ErrNetwork and the error of the HTTP client.
+type networkError struct {
+	err error
+}
+
+func (e *networkError) Error() string {
+	return e.err.Error()
+}
+
+func (e *networkError) Unwrap() []error {
+	return []error{e.err, ErrNetwork}
+}
+
+// APIError is an unexpected response of the Red Hat Lightspeed API. Responses
+// rejecting the identity of the system match ErrAuth.
+type APIError struct {
+	// Service is the name of the API, e.g. "advisor".
+	Service    string
+	StatusCode int
+	Status     string
+	// Body is the beginning of the response body.
+	Body string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s responded with %s: %s", e.Service, e.Status, e.Body)
+}
+
+// Is reports whether the response matches target, ErrAuth or ErrSystemNotFound.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrAuth:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrSystemNotFound:
+		return e.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// newAPIError returns APIError of the response resp of service.
+func newAPIError(service string, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return &APIError{
+		Service:    service,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
@@ -0,0 +1,66 @@
+package datacollection
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAPIErrors(t *testing.T) {
+	tests := []struct {
+		description string
+		status      int
+		wantError   error
+		wantAPI     bool
+	}{
+		{description: "unauthorized", status: http.StatusUnauthorized, wantError: ErrAuth, wantAPI: true},
+		{description: "forbidden", status: http.StatusForbidden, wantError: ErrAuth, wantAPI: true},
+		{description: "not found", status: http.StatusNotFound, wantError: ErrSystemNotFound},
+		{description: "server error", status: http.StatusInternalServerError, wantAPI: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "error", test.status)
+			}))
+			defer server.Close()
+
+			oldURL := InsightsAPIURL
+			InsightsAPIURL = server.URL + "/api"
+			t.Cleanup(func() { InsightsAPIURL = oldURL })
+
+			_, err := GetUpgradeReadiness(context.Background(), server.Client(), "1234")
+			if test.wantError != nil && !errors.Is(err, test.wantError) {
+				t.Errorf("got error %v, want %v", err, test.wantError)
+			}
+			var apiErr *APIError
+			if errors.As(err, &apiErr) != test.wantAPI {
+				t.Errorf("got error %v, want APIError: %v", err, test.wantAPI)
+			}
+			if apiErr != nil && (apiErr.StatusCode != test.status || apiErr.Body != "error") {
+				t.Errorf("got %+v, want status %d and body 'error'", apiErr, test.status)
+			}
+			if errors.Is(err, ErrNetwork) {
+				t.Errorf("got error %v, want no network error", err)
+			}
+		})
+	}
+}
+
+func TestNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	oldURL := InsightsAPIURL
+	InsightsAPIURL = server.URL + "/api"
+	t.Cleanup(func() { InsightsAPIURL = oldURL })
+
+	err := SetMaintenanceFacts(context.Background(), server.Client(), "1234", time.Time{})
+	if !errors.Is(err, ErrNetwork) {
+		t.Errorf("got error %v, want %v", err, ErrNetwork)
+	}
+}
@@ -2,25 +2,158 @@ package datacollection
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/logging"
+	"github.com/redhatinsights/rhc/internal/retry"
 )
 
-func RegisterInsightsClient() error {
-	slog.Debug("Executing /usr/bin/insights-client --register")
-	cmd := exec.Command("/usr/bin/insights-client", "--register")
+// SkipInitialUpload makes RegisterInsightsClient register the system without
+// collecting and uploading the initial archive, e.g. on constrained links. The
+// archive is uploaded by the next scheduled run of insights-client.
+var SkipInitialUpload bool
+
+// Retry is the policy the registration of insights-client is retried with. By
+// default, it is attempted once.
+var Retry retry.Policy
+
+// isTransient reports whether insights-client failed to register the system,
+// which may succeed when it is retried. insights-client exits with the same
+// status for all failures, so only failures to run it are not retried.
+func isTransient(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr)
+}
+
+// InsightsRegistration is how the system appears in Inventory once it is
+// registered with Red Hat Lightspeed. Empty fields are left to the defaults of
+// insights-client.
+type InsightsRegistration struct {
+	// DisplayName is the name the system is displayed under in Inventory,
+	// instead of its hostname.
+	DisplayName string
+	// Group is the inventory group the system is added to.
+	Group string
+}
+
+// Args returns the arguments of insights-client registering the system as
+// described by r.
+func (r InsightsRegistration) Args() []string {
+	args := []string{"--register"}
+	if r.DisplayName != "" {
+		args = append(args, "--display-name", r.DisplayName)
+	}
+	if r.Group != "" {
+		args = append(args, "--group", r.Group)
+	}
+	return args
+}
+
+// RegisterInsightsClient registers the system with Red Hat Lightspeed as
+// described by registration, retrying failures of insights-client by Retry.
+// insights-client is killed, when ctx is done. When insights-client does not
+// support the flags of registration, UnsupportedFlagError is returned without
+// running it.
+func RegisterInsightsClient(ctx context.Context, registration InsightsRegistration) error {
+	var flags []string
+	if registration.DisplayName != "" {
+		flags = append(flags, "--display-name")
+	}
+	if registration.Group != "" {
+		flags = append(flags, "--group")
+	}
+	if err := checkInsightsClientFlags(flags); err != nil {
+		return err
+	}
+	args := registration.Args()
+	if SkipInitialUpload {
+		// Old versions always upload the initial archive
+		if insightsClientSupports("--no-upload") {
+			args = append(args, "--no-upload")
+		} else {
+			slog.Warn("insights-client does not support --no-upload, the initial archive is uploaded")
+		}
+	}
+	return Retry.Do(ctx, "insights-register", isTransient, func() error {
+		slog.Debug("Executing /usr/bin/insights-client " + strings.Join(args, " "))
+		return runContext(ctx, exec.CommandContext(ctx, "/usr/bin/insights-client", args...))
+	})
+}
+
+// InsightsUploadRetryCommand returns the command collecting and uploading an
+// archive of insights-client, which is retried after each of delays until the
+// upload succeeds. The command exits with an error, when all attempts fail.
+func InsightsUploadRetryCommand(delays []time.Duration) []string {
+	seconds := make([]string, 0, len(delays))
+	for _, delay := range delays {
+		seconds = append(seconds, strconv.Itoa(int(delay.Seconds())))
+	}
+	script := fmt.Sprintf(
+		`for delay in %s; do sleep "$delay"; /usr/bin/insights-client && exit 0; done; exit 1`,
+		strings.Join(seconds, " "),
+	)
+	return []string{"/bin/sh", "-c", script}
+}
 
-	return cmd.Run()
+// connectionMessages are the messages printed by insights-client when Red Hat
+// Lightspeed cannot be reached.
+var connectionMessages = []string{
+	"Connection refused",
+	"Connection timed out",
+	"Failed to establish a new connection",
+	"Max retries exceeded",
+	"Name or service not known",
+	"Network is unreachable",
+	"Temporary failure in name resolution",
 }
 
-func UnregisterInsightsClient() error {
+// UnregisterInsightsClient unregisters the system from Red Hat Lightspeed.
+// insights-client is killed, when ctx is done. The error matches ErrNetwork,
+// when insights-client cannot reach Red Hat Lightspeed.
+func UnregisterInsightsClient(ctx context.Context) error {
 	slog.Debug("Executing /usr/bin/insights-client --unregister")
-	cmd := exec.Command("/usr/bin/insights-client", "--unregister")
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, "/usr/bin/insights-client", "--unregister")
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := runContext(ctx, cmd)
+	if err != nil && ctx.Err() == nil {
+		return unregisterError(err, output.String())
+	}
+	return err
+}
+
+// unregisterError returns the error of insights-client failing to unregister
+// the system with err and output; it matches ErrNetwork, when output reports
+// that Red Hat Lightspeed cannot be reached.
+func unregisterError(err error, output string) error {
+	for _, message := range connectionMessages {
+		if strings.Contains(output, message) {
+			return &networkError{err: fmt.Errorf("%w: %s", err, message)}
+		}
+	}
+	return err
+}
 
-	return cmd.Run()
+// runContext runs cmd created with ctx. When cmd is killed because ctx is done,
+// the error of ctx is returned instead of the exit status of cmd.
+func runContext(ctx context.Context, cmd *exec.Cmd) error {
+	start := logging.CommandStart(cmd)
+	err := cmd.Run()
+	logging.Command(cmd, start, err)
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("insights-client did not finish in time: %w", ctx.Err())
+	}
+	return err
 }
 
 // InsightsClientIsRegistered checks whether insights-client reports its
@@ -34,7 +167,9 @@ func InsightsClientIsRegistered() (bool, error) {
 	cmd := exec.Command("/usr/bin/insights-client", "--status")
 	cmd.Stderr = &errBuffer
 
+	start := logging.CommandStart(cmd)
 	err := cmd.Run()
+	logging.Command(cmd, start, err)
 
 	if err != nil {
 		// When the error is ExitError, then we know that insights-client only returned
@@ -56,3 +191,138 @@ func InsightsClientIsRegistered() (bool, error) {
 
 	return cmd.ProcessState.Success(), err
 }
+
+// InsightsRegisteredMarkerPath is the path to the file created by insights-client
+// when the system is registered.
+const InsightsRegisteredMarkerPath = "/etc/insights-client/.registered"
+
+// InsightsClientHasRegisteredMarker reports whether insights-client marked the
+// system as registered. Unlike InsightsClientIsRegistered, it does not run
+// insights-client, which requires privileges, and it does not contact the server.
+func InsightsClientHasRegisteredMarker() (bool, error) {
+	_, err := os.Stat(InsightsRegisteredMarkerPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not check registration marker: %w", err)
+	}
+	return true, nil
+}
+
+// InsightsClientCheckIn performs a lightweight check-in of the system
+// to Red Hat Lightspeed, updating its last-seen time in Inventory.
+func InsightsClientCheckIn() error {
+	return runInsightsClient("--checkin")
+}
+
+// SetInsightsDisplayName changes the name the system is displayed under in Inventory.
+func SetInsightsDisplayName(name string) error {
+	return runInsightsClient("--display-name", name)
+}
+
+// runInsightsClient executes insights-client with given arguments. When the command
+// fails, the error contains the standard error output of insights-client. When
+// insights-client does not support the flags, UnsupportedFlagError is returned
+// without running it.
+func runInsightsClient(args ...string) error {
+	if err := checkInsightsClientFlags(args); err != nil {
+		return err
+	}
+	var errBuffer bytes.Buffer
+	slog.Debug(fmt.Sprintf("Executing /usr/bin/insights-client %s", strings.Join(args, " ")))
+	cmd := exec.Command("/usr/bin/insights-client", args...)
+	cmd.Stderr = &errBuffer
+
+	start := logging.CommandStart(cmd)
+	err := cmd.Run()
+	logging.Command(cmd, start, err)
+	if err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) && errBuffer.Len() > 0 {
+			return fmt.Errorf("%s", strings.TrimSpace(errBuffer.String()))
+		}
+		return err
+	}
+	return nil
+}
+
+// InsightsSchedulePaths are the files enabling the scheduled collection of
+// insights-client: the link of its timer on systems with systemd, and the cron
+// job of old versions.
+var InsightsSchedulePaths = []string{
+	"/etc/systemd/system/timers.target.wants/insights-client.timer",
+	"/etc/cron.daily/insights-client",
+}
+
+// InsightsSchedulingEnabled reports whether the scheduled collection and upload
+// of insights-client is enabled. It does not run insights-client, which requires
+// privileges.
+func InsightsSchedulingEnabled() (bool, error) {
+	for _, path := range InsightsSchedulePaths {
+		_, err := os.Lstat(path)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return false, fmt.Errorf("could not check scheduling of insights-client: %w", err)
+		}
+	}
+	return false, nil
+}
+
+// SetInsightsScheduling enables or disables the scheduled collection and upload
+// of insights-client.
+func SetInsightsScheduling(enabled bool) error {
+	if enabled {
+		return runInsightsClient("--enable-schedule")
+	}
+	return runInsightsClient("--disable-schedule")
+}
+
+// InsightsLastUploadPath is the path to the file insights-client touches
+// after each successful upload.
+const InsightsLastUploadPath = "/etc/insights-client/.lastupload"
+
+// InsightsClientLastUpload returns the time of the last successful upload of
+// insights-client. The zero time is returned when no upload has been made yet.
+func InsightsClientLastUpload() (time.Time, error) {
+	info, err := os.Stat(InsightsLastUploadPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not check last upload: %w", err)
+	}
+	return info.ModTime(), nil
+}
+
+// InsightsHostDetailsPath is the path to the Inventory record of the host
+// saved by insights-client after an upload.
+const InsightsHostDetailsPath = "/var/lib/insights/host-details.json"
+
+// InsightsInventoryID returns the identifier of the host record in Inventory,
+// as saved by insights-client. An empty string is returned when insights-client
+// has not saved the record yet.
+func InsightsInventoryID() (string, error) {
+	data, err := os.ReadFile(InsightsHostDetailsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not read host details: %w", err)
+	}
+	var details struct {
+		Results []struct {
+			ID string `json:"id"`
+		} `json:"results"`
+	}
+	if err = json.Unmarshal(data, &details); err != nil {
+		return "", fmt.Errorf("could not parse host details %s: %w", InsightsHostDetailsPath, err)
+	}
+	// The record is looked up by the insights-id; more results are ambiguous
+	if len(details.Results) != 1 {
+		return "", nil
+	}
+	return details.Results[0].ID, nil
+}
@@ -0,0 +1,109 @@
+package datacollection
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInsightsRegistrationArgs(t *testing.T) {
+	tests := []struct {
+		description  string
+		registration InsightsRegistration
+		want         []string
+	}{
+		{
+			description: "defaults",
+			want:        []string{"--register"},
+		},
+		{
+			description:  "display name",
+			registration: InsightsRegistration{DisplayName: "web-01 (production)"},
+			want:         []string{"--register", "--display-name", "web-01 (production)"},
+		},
+		{
+			description:  "display name and group",
+			registration: InsightsRegistration{DisplayName: "web-01", Group: "web-servers"},
+			want:         []string{"--register", "--display-name", "web-01", "--group", "web-servers"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := test.registration.Args()
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
+
+func TestInsightsUploadRetryCommand(t *testing.T) {
+	got := InsightsUploadRetryCommand([]time.Duration{time.Minute, 5 * time.Minute, time.Hour})
+	want := []string{
+		"/bin/sh", "-c",
+		`for delay in 60 300 3600; do sleep "$delay"; /usr/bin/insights-client && exit 0; done; exit 1`,
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
+
+func TestInsightsSchedulingEnabled(t *testing.T) {
+	dir := t.TempDir()
+	timer := filepath.Join(dir, "insights-client.timer")
+	oldPaths := InsightsSchedulePaths
+	InsightsSchedulePaths = []string{timer, filepath.Join(dir, "insights-client")}
+	t.Cleanup(func() { InsightsSchedulePaths = oldPaths })
+
+	if enabled, err := InsightsSchedulingEnabled(); err != nil || enabled {
+		t.Errorf("got (%v, %v) without schedule, want (false, nil)", enabled, err)
+	}
+	// Only the link is checked, not the unit it points to
+	if err := os.Symlink("/nonexistent/insights-client.timer", timer); err != nil {
+		t.Fatal(err)
+	}
+	if enabled, err := InsightsSchedulingEnabled(); err != nil || !enabled {
+		t.Errorf("got (%v, %v) with timer, want (true, nil)", enabled, err)
+	}
+}
+
+func TestUnregisterError(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	tests := []struct {
+		description string
+		output      string
+		wantNetwork bool
+	}{
+		{
+			description: "unreachable",
+			output:      "Failed to establish a new connection: [Errno 111] Connection refused",
+			wantNetwork: true,
+		},
+		{
+			description: "resolution",
+			output:      "[Errno -3] Temporary failure in name resolution",
+			wantNetwork: true,
+		},
+		{
+			description: "other",
+			output:      "This host is not registered",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			err := unregisterError(exitErr, test.output)
+			if got := errors.Is(err, ErrNetwork); got != test.wantNetwork {
+				t.Errorf("got network error %v, want %v: %v", got, test.wantNetwork, err)
+			}
+			if !errors.Is(err, exitErr) {
+				t.Errorf("got error %v, want it to wrap %v", err, exitErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package datacollection
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// InventoryFactsNamespace is the namespace of the facts rhc sets on the host in Inventory.
+const InventoryFactsNamespace = "rhc"
+
+// SetMaintenanceFacts sets the maintenance facts of the host identified by
+// inventoryID. A zero until ends the maintenance. Rules of notifications can
+// use the facts to suppress staleness alerts of the host during the window.
+// The client has to authenticate with the consumer certificate of the system or
+// a service account.
+func SetMaintenanceFacts(ctx context.Context, client *http.Client, inventoryID string, until time.Time) error {
+	factsURL, err := url.JoinPath(InsightsAPIURL, "inventory/v1/hosts", inventoryID, "facts", InventoryFactsNamespace)
+	if err != nil {
+		return fmt.Errorf("invalid inventory URL: %w", err)
+	}
+
+	facts := map[string]any{"maintenance": !until.IsZero()}
+	if !until.IsZero() {
+		facts["maintenance_until"] = until.UTC().Format(time.RFC3339)
+	}
+	body, err := json.Marshal(facts)
+	if err != nil {
+		return err
+	}
+
+	// PATCH merges the facts, so facts of other features in the namespace are kept
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, factsURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not create inventory request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	slog.Debug("Setting maintenance facts", "url", factsURL, "facts", string(body))
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not update inventory: %w", &networkError{err: err})
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrSystemNotFound
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return newAPIError("inventory", resp)
+	}
+	return nil
+}
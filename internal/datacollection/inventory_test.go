@@ -0,0 +1,67 @@
+package datacollection
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSetMaintenanceFacts(t *testing.T) {
+	tests := []struct {
+		description string
+		until       time.Time
+		status      int
+		wantBody    string
+		wantError   error
+	}{
+		{
+			description: "start maintenance",
+			until:       time.Date(2025, 1, 31, 4, 0, 0, 0, time.FixedZone("CET", 3600)),
+			status:      http.StatusOK,
+			wantBody:    `{"maintenance":true,"maintenance_until":"2025-01-31T03:00:00Z"}`,
+		},
+		{
+			description: "end maintenance",
+			status:      http.StatusOK,
+			wantBody:    `{"maintenance":false}`,
+		},
+		{
+			description: "unknown system",
+			status:      http.StatusNotFound,
+			wantBody:    `{"maintenance":false}`,
+			wantError:   ErrSystemNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			var method, path, body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method, path = r.Method, r.URL.Path
+				data, _ := io.ReadAll(r.Body)
+				body = string(data)
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			oldURL := InsightsAPIURL
+			InsightsAPIURL = server.URL + "/api"
+			t.Cleanup(func() { InsightsAPIURL = oldURL })
+
+			err := SetMaintenanceFacts(context.Background(), server.Client(), "1234", test.until)
+			if method != http.MethodPatch || path != "/api/inventory/v1/hosts/1234/facts/rhc" {
+				t.Errorf("unexpected request %s %s", method, path)
+			}
+			if body != test.wantBody {
+				t.Errorf("got body %s, want %s", body, test.wantBody)
+			}
+			if !errors.Is(err, test.wantError) {
+				t.Errorf("got error %v, want %v", err, test.wantError)
+			}
+		})
+	}
+}
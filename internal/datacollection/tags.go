@@ -0,0 +1,146 @@
+package datacollection
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redhatinsights/rhc/internal/util"
+)
+
+// InsightsTagsPath is the path to the file with tags uploaded by insights-client
+// during registration and every check-in.
+var InsightsTagsPath = "/etc/insights-client/tags.yaml"
+
+// formatInsightsTags formats tags as a YAML document understood by insights-client.
+func formatInsightsTags(tags map[string]string) string {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("# Tags set in /etc/rhc/config.toml are merged into this file by rhc.\n")
+	for _, name := range names {
+		_, _ = fmt.Fprintf(&b, "%s: %s\n", strconv.Quote(name), strconv.Quote(tags[name]))
+	}
+	return b.String()
+}
+
+// parseInsightsTags parses the tags of a YAML document mapping names to
+// values, as written by formatInsightsTags or by hand. Other YAML documents,
+// e.g. with nested mappings, are refused, so they are not overwritten.
+func parseInsightsTags(data string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for i, line := range strings.Split(data, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+		name, value, err := parseTagLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		tags[name] = value
+	}
+	return tags, nil
+}
+
+// parseTagLine parses line "name: value" of a tags file.
+func parseTagLine(line string) (string, string, error) {
+	if strings.TrimLeft(line, " \t-") != line {
+		return "", "", errors.New("only a mapping of names to values is supported")
+	}
+	name, rest, err := splitTagName(line)
+	if err != nil {
+		return "", "", err
+	}
+	rest = strings.TrimLeft(rest, " \t")
+	if !strings.HasPrefix(rest, ":") {
+		return "", "", errors.New("missing ':' after name")
+	}
+	value := strings.TrimSpace(rest[1:])
+	if value == "" || strings.ContainsAny(value[:1], "[{|>&*") {
+		return "", "", fmt.Errorf("value of %s is not a string", name)
+	}
+	return unquoteTag(strings.TrimSpace(name)), unquoteTag(value), nil
+}
+
+// splitTagName splits line into the name of the tag, possibly quoted, and the
+// rest of the line.
+func splitTagName(line string) (string, string, error) {
+	switch line[0] {
+	case '"':
+		quoted, err := strconv.QuotedPrefix(line)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid quoted name: %w", err)
+		}
+		return quoted, line[len(quoted):], nil
+	case '\'':
+		for i := 1; i < len(line); i++ {
+			if line[i] != '\'' {
+				continue
+			}
+			if i+1 < len(line) && line[i+1] == '\'' {
+				i++
+				continue
+			}
+			return line[:i+1], line[i+1:], nil
+		}
+		return "", "", errors.New("unterminated quoted name")
+	}
+	name, rest, found := strings.Cut(line, ":")
+	if !found {
+		return "", "", errors.New("missing ':' after name")
+	}
+	return name, ":" + rest, nil
+}
+
+// unquoteTag removes the quotes of a double- or single-quoted YAML scalar.
+func unquoteTag(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil && strings.HasPrefix(s, `"`) {
+		return unquoted
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
+
+// WriteInsightsTags merges tags into the insights-client tags file, so they are
+// applied to the host on registration and on every check-in. Tags in the file,
+// which are not in tags, are kept; a file, which is not a mapping of names to
+// values, is not changed. The file is replaced atomically, so insights-client
+// never reads it partially written. Nothing is written when there are no tags.
+func WriteInsightsTags(tags map[string]string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(tags))
+	data, err := os.ReadFile(InsightsTagsPath)
+	switch {
+	case err == nil:
+		if merged, err = parseInsightsTags(string(data)); err != nil {
+			return fmt.Errorf("cannot merge tags into %s: %w", InsightsTagsPath, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("cannot read tags: %w", err)
+	}
+	maps.Copy(merged, tags)
+
+	slog.Debug("Writing Red Hat Lightspeed tags", "path", InsightsTagsPath, "count", len(merged))
+	if err = os.MkdirAll(filepath.Dir(InsightsTagsPath), 0755); err != nil {
+		return fmt.Errorf("cannot write tags: %w", err)
+	}
+	if err = util.WriteFileAtomic(InsightsTagsPath, []byte(formatInsightsTags(merged)), 0644); err != nil {
+		return fmt.Errorf("cannot write tags: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package datacollection
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseInsightsTags(t *testing.T) {
+	tests := []struct {
+		description string
+		data        string
+		want        map[string]string
+		wantError   bool
+	}{
+		{
+			description: "written by rhc",
+			data:        formatInsightsTags(map[string]string{"env": "prod", "owner": `team "a"`}),
+			want:        map[string]string{"env": "prod", "owner": `team "a"`},
+		},
+		{
+			description: "written by hand",
+			data:        "---\n# Tags of the host\nenv: prod\n'site': 'it''s here'\nurl: https://example.com\n",
+			want:        map[string]string{"env": "prod", "site": "it's here", "url": "https://example.com"},
+		},
+		{
+			description: "nested mapping",
+			data:        "network:\n  zone: dmz\n",
+			wantError:   true,
+		},
+		{
+			description: "list",
+			data:        "owners: [a, b]\n",
+			wantError:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := parseInsightsTags(test.data)
+			if (err != nil) != test.wantError {
+				t.Fatalf("got error %v, want error: %v", err, test.wantError)
+			}
+			if !test.wantError && !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
+
+func TestWriteInsightsTags(t *testing.T) {
+	oldPath := InsightsTagsPath
+	InsightsTagsPath = filepath.Join(t.TempDir(), "tags.yaml")
+	t.Cleanup(func() { InsightsTagsPath = oldPath })
+
+	if err := os.WriteFile(InsightsTagsPath, []byte("owner: ops\nenv: test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteInsightsTags(map[string]string{"env": "prod"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(InsightsTagsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseInsightsTags(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"env": "prod", "owner": "ops"}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+
+	nested := "network:\n  zone: dmz\n"
+	if err = os.WriteFile(InsightsTagsPath, []byte(nested), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = WriteInsightsTags(map[string]string{"env": "prod"}); err == nil {
+		t.Error("expected error of a nested mapping, got nil")
+	}
+	if data, _ = os.ReadFile(InsightsTagsPath); string(data) != nested {
+		t.Errorf("tags file was changed: %q", data)
+	}
+}
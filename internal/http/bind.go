@@ -0,0 +1,55 @@
+package httpapi
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// BindConfig selects the local end of connections to Red Hat services. It is
+// needed on multi-homed systems where only one interface can reach them.
+type BindConfig struct {
+	// Interface is the name of the network interface connections are bound to.
+	Interface string
+	// SourceAddress is the local IP address connections originate from.
+	SourceAddress string
+}
+
+// Bind is the binding of outbound connections made by clients returned by
+// NewHTTPClient. The zero value lets the system choose the route.
+var Bind BindConfig
+
+// IsZero reports whether no binding is configured.
+func (c BindConfig) IsZero() bool {
+	return c.Interface == "" && c.SourceAddress == ""
+}
+
+// Dialer returns a dialer making connections from the configured interface
+// and source address.
+func (c BindConfig) Dialer() (*net.Dialer, error) {
+	dialer := &net.Dialer{}
+	if c.SourceAddress != "" {
+		ip := net.ParseIP(c.SourceAddress)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid source address %q", c.SourceAddress)
+		}
+		dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	}
+	if c.Interface != "" {
+		device := c.Interface
+		dialer.Control = func(network, address string, conn syscall.RawConn) error {
+			var bindErr error
+			err := conn.Control(func(fd uintptr) {
+				bindErr = syscall.BindToDevice(int(fd), device)
+			})
+			if err != nil {
+				return err
+			}
+			if bindErr != nil {
+				return fmt.Errorf("cannot bind to interface %s: %w", device, bindErr)
+			}
+			return nil
+		}
+	}
+	return dialer, nil
+}
@@ -0,0 +1,60 @@
+package httpapi
+
+import (
+	"net"
+	"testing"
+)
+
+func TestBindConfigDialer(t *testing.T) {
+	tests := []struct {
+		description string
+		input       BindConfig
+		wantAddr    net.Addr
+		wantControl bool
+		wantError   bool
+	}{
+		{
+			description: "zero value",
+			input:       BindConfig{},
+		},
+		{
+			description: "source address",
+			input:       BindConfig{SourceAddress: "192.0.2.10"},
+			wantAddr:    &net.TCPAddr{IP: net.ParseIP("192.0.2.10")},
+		},
+		{
+			description: "interface",
+			input:       BindConfig{Interface: "eth1"},
+			wantControl: true,
+		},
+		{
+			description: "invalid source address",
+			input:       BindConfig{SourceAddress: "eth1"},
+			wantError:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			dialer, err := test.input.Dialer()
+			if test.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.wantAddr == nil && dialer.LocalAddr != nil {
+				t.Errorf("unexpected local address %v", dialer.LocalAddr)
+			}
+			if test.wantAddr != nil && (dialer.LocalAddr == nil || dialer.LocalAddr.String() != test.wantAddr.String()) {
+				t.Errorf("local address = %v, want %v", dialer.LocalAddr, test.wantAddr)
+			}
+			if (dialer.Control != nil) != test.wantControl {
+				t.Errorf("control set = %v, want %v", dialer.Control != nil, test.wantControl)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package httpapi
 
 import (
 	"crypto/tls"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -10,11 +13,105 @@ import (
 const uploadTimeout = 60 * time.Second
 
 // NewHTTPClient returns an HTTP client configured with TLS certificates for secure uploads.
+// Connections are made through the proxy configured in Proxy, and bound
+// as configured in Bind. Requests are traced to LogHTTP, when set, and adapted
+// to constrained links, when LowBandwidth is set. The start and the duration of
+// each request are logged at DEBUG level.
 func NewHTTPClient(tlsConfig *tls.Config) *http.Client {
+	return &http.Client{
+		Timeout:   timeout(uploadTimeout),
+		Transport: newTraceTransport(newCompressTransport(timingTransport{base: newTransport(tlsConfig)})),
+	}
+}
+
+// newTransport returns a transport using tlsConfig, Proxy and Bind.
+func newTransport(tlsConfig *tls.Config) *http.Transport {
 	transport := http.DefaultTransport.(*http.Transport).Clone()
 	transport.TLSClientConfig = tlsConfig.Clone()
+	if Proxy.URL != "" {
+		if proxy, err := Proxy.ProxyFunc(); err == nil {
+			transport.Proxy = proxy
+		} else {
+			slog.Warn("Ignoring proxy configuration", "err", err)
+		}
+	}
+	if !Bind.IsZero() {
+		if dialer, err := Bind.Dialer(); err == nil {
+			dialer.Timeout = timeout(30 * time.Second)
+			dialer.KeepAlive = 30 * time.Second
+			transport.DialContext = dialer.DialContext
+		} else {
+			slog.Warn("Ignoring outbound connection binding", "err", err)
+		}
+	}
+	return transport
+}
+
+// ReauthFunc returns TLS configuration with freshly loaded client credentials.
+type ReauthFunc func() (*tls.Config, error)
+
+// NewReauthHTTPClient returns an HTTP client like NewHTTPClient. When a server
+// responds with 401 Unauthorized, the client credentials are reloaded by calling
+// reauth, and the request is sent once more. This covers credentials rotated
+// during a long operation (e.g. the identity certificate renewed by rhsmcertd).
+func NewReauthHTTPClient(tlsConfig *tls.Config, reauth ReauthFunc) *http.Client {
 	return &http.Client{
-		Timeout:   uploadTimeout,
-		Transport: transport,
+		Timeout: timeout(uploadTimeout),
+		Transport: newTraceTransport(newCompressTransport(&reauthTransport{
+			base:   newTransport(tlsConfig),
+			reauth: reauth,
+		})),
 	}
 }
+
+// reauthTransport is an http.RoundTripper retrying requests rejected with
+// 401 Unauthorized once, after the credentials have been reloaded.
+type reauthTransport struct {
+	mu     sync.Mutex
+	base   *http.Transport
+	reauth ReauthFunc
+}
+
+// transport returns the current underlying transport.
+func (t *reauthTransport) transport() *http.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.base
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *reauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := timedRoundTrip(t.transport(), req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		// The request body cannot be sent again
+		return resp, nil
+	}
+
+	slog.Debug("Server responded with 401 Unauthorized, reloading credentials", "url", req.URL.String())
+	tlsConfig, err := t.reauth()
+	if err != nil {
+		slog.Warn("Unable to reload credentials", "error", err)
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, fmt.Errorf("cannot rewind request body: %w", err)
+		}
+	}
+
+	t.mu.Lock()
+	old := t.base
+	t.base = old.Clone()
+	t.base.TLSClientConfig = tlsConfig.Clone()
+	t.mu.Unlock()
+	old.CloseIdleConnections()
+
+	slog.Debug("Retrying request with reloaded credentials", "url", req.URL.String())
+	return timedRoundTrip(t.transport(), retry)
+}
@@ -0,0 +1,97 @@
+package httpapi
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReauthHTTPClient(t *testing.T) {
+	tests := []struct {
+		description  string
+		unauthorized int
+		reauthErr    error
+		wantStatus   int
+		wantRequests int
+		wantReauths  int
+	}{
+		{
+			description:  "authorized",
+			unauthorized: 0,
+			wantStatus:   http.StatusOK,
+			wantRequests: 1,
+			wantReauths:  0,
+		},
+		{
+			description:  "unauthorized once",
+			unauthorized: 1,
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+			wantReauths:  1,
+		},
+		{
+			description:  "unauthorized twice",
+			unauthorized: 2,
+			wantStatus:   http.StatusUnauthorized,
+			wantRequests: 2,
+			wantReauths:  1,
+		},
+		{
+			description:  "reauth failed",
+			unauthorized: 1,
+			reauthErr:    fmt.Errorf("no certificate"),
+			wantStatus:   http.StatusUnauthorized,
+			wantRequests: 1,
+			wantReauths:  1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != "payload" {
+					t.Errorf("got body %q, want %q", string(body), "payload")
+				}
+				if requests <= test.unauthorized {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			reauths := 0
+			client := NewReauthHTTPClient(&tls.Config{}, func() (*tls.Config, error) {
+				reauths++
+				return &tls.Config{}, test.reauthErr
+			})
+
+			req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("got status %d, want %d", resp.StatusCode, test.wantStatus)
+			}
+			if requests != test.wantRequests {
+				t.Errorf("got %d requests, want %d", requests, test.wantRequests)
+			}
+			if reauths != test.wantReauths {
+				t.Errorf("got %d reauths, want %d", reauths, test.wantReauths)
+			}
+		})
+	}
+}
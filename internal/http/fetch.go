@@ -0,0 +1,36 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Fetch downloads the content of url, reading at most limit bytes. Connections
+// are made through Proxy and bound as configured in Bind. Responses other than
+// 2xx are errors.
+func Fetch(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create request: %w", err)
+	}
+
+	res, err := NewHTTPClient(nil).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot download %s: %w", url, err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("cannot download %s: server responded with %s", url, res.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("cannot download %s: %w", url, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("cannot download %s: content exceeds %d bytes", url, limit)
+	}
+	return data, nil
+}
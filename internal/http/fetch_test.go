@@ -0,0 +1,51 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bundle":
+			_, _ = w.Write([]byte("content"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		description string
+		path        string
+		limit       int64
+		want        string
+		wantError   bool
+	}{
+		{description: "success", path: "/bundle", limit: 16, want: "content"},
+		{description: "content at limit", path: "/bundle", limit: 7, want: "content"},
+		{description: "content over limit", path: "/bundle", limit: 6, wantError: true},
+		{description: "not found", path: "/missing", limit: 16, wantError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := Fetch(context.Background(), server.URL+test.path, test.limit)
+			if test.wantError {
+				if err == nil {
+					t.Errorf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
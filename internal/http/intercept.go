@@ -0,0 +1,78 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// probeTimeout limits the duration of ProbeTLS.
+const probeTimeout = 15 * time.Second
+
+// ProbeTLS sends a HEAD request to rawURL through the configured proxy and
+// returns the certificate chain presented by the server. The chain is not
+// verified, so it can be inspected even when it is not trusted.
+func ProbeTLS(ctx context.Context, rawURL string) ([]*x509.Certificate, error) {
+	client := &http.Client{
+		Timeout: timeout(probeTimeout),
+		// The chain is verified by the caller
+		Transport: timingTransport{base: newTransport(&tls.Config{InsecureSkipVerify: true})},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("%s did not present any certificate", req.URL.Host)
+	}
+	return resp.TLS.PeerCertificates, nil
+}
+
+// DetectInterception reports whether the chain presented for host has been
+// issued by an authority unknown to roots, while being valid for host otherwise.
+// This is the signature of a TLS-intercepting proxy (SSL inspection). The returned
+// name identifies the authority of the intercepting proxy.
+func DetectInterception(chain []*x509.Certificate, host string, roots *x509.CertPool) (string, bool) {
+	if len(chain) == 0 {
+		return "", false
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	var unknownAuthority x509.UnknownAuthorityError
+	if !errors.As(err, &unknownAuthority) {
+		return "", false
+	}
+	// A chain of a different host is a misconfiguration, not an interception
+	if chain[0].VerifyHostname(host) != nil {
+		return "", false
+	}
+	return authorityName(chain[len(chain)-1]), true
+}
+
+// authorityName returns a human-readable name of the authority issuing cert.
+func authorityName(cert *x509.Certificate) string {
+	if len(cert.Issuer.Organization) > 0 {
+		return cert.Issuer.Organization[0]
+	}
+	if cert.Issuer.CommonName != "" {
+		return cert.Issuer.CommonName
+	}
+	return cert.Issuer.String()
+}
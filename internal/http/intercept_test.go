@@ -0,0 +1,116 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestCertificate returns a certificate for subject signed by parent. The
+// certificate is self-signed when parent is nil.
+func newTestCertificate(t *testing.T, subject pkix.Name, dnsNames []string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               subject,
+		DNSNames:              dnsNames,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestDetectInterception(t *testing.T) {
+	publicCA, publicKey := newTestCertificate(t, pkix.Name{Organization: []string{"Public CA"}}, nil, true, nil, nil)
+	proxyCA, proxyKey := newTestCertificate(t, pkix.Name{Organization: []string{"Acme Firewall"}}, nil, true, nil, nil)
+	host := "subscription.rhsm.redhat.com"
+	genuine, _ := newTestCertificate(t, pkix.Name{CommonName: host}, []string{host}, false, publicCA, publicKey)
+	forged, _ := newTestCertificate(t, pkix.Name{CommonName: host}, []string{host}, false, proxyCA, proxyKey)
+	other, _ := newTestCertificate(t, pkix.Name{CommonName: "example.com"}, []string{"example.com"}, false, proxyCA, proxyKey)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(publicCA)
+
+	tests := []struct {
+		description     string
+		chain           []*x509.Certificate
+		wantAuthority   string
+		wantIntercepted bool
+	}{
+		{
+			description: "empty chain",
+		},
+		{
+			description: "trusted chain",
+			chain:       []*x509.Certificate{genuine, publicCA},
+		},
+		{
+			description:     "intercepted chain",
+			chain:           []*x509.Certificate{forged, proxyCA},
+			wantAuthority:   "Acme Firewall",
+			wantIntercepted: true,
+		},
+		{
+			description:     "intercepted leaf only",
+			chain:           []*x509.Certificate{forged},
+			wantAuthority:   "Acme Firewall",
+			wantIntercepted: true,
+		},
+		{
+			description: "different host",
+			chain:       []*x509.Certificate{other, proxyCA},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			authority, intercepted := DetectInterception(test.chain, host, roots)
+			if intercepted != test.wantIntercepted {
+				t.Errorf("intercepted = %v, want %v", intercepted, test.wantIntercepted)
+			}
+			if authority != test.wantAuthority {
+				t.Errorf("authority = %q, want %q", authority, test.wantAuthority)
+			}
+		})
+	}
+}
+
+func TestProbeTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	chain, err := ProbeTLS(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) == 0 || !chain[0].Equal(server.Certificate()) {
+		t.Errorf("unexpected chain presented by the server")
+	}
+}
@@ -0,0 +1,75 @@
+package httpapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// LowBandwidth adapts clients returned by NewHTTPClient to constrained links
+// (e.g. edge devices connected through Satellite): JSON request bodies are
+// compressed, and timeouts are widened.
+var LowBandwidth bool
+
+// lowBandwidthTimeoutFactor is the factor timeouts are widened by in the
+// low-bandwidth mode.
+const lowBandwidthTimeoutFactor = 4
+
+// timeout returns d, widened in the low-bandwidth mode.
+func timeout(d time.Duration) time.Duration {
+	if LowBandwidth {
+		return d * lowBandwidthTimeoutFactor
+	}
+	return d
+}
+
+// newCompressTransport returns base wrapped with compression of JSON request
+// bodies in the low-bandwidth mode, or base itself otherwise.
+func newCompressTransport(base http.RoundTripper) http.RoundTripper {
+	if !LowBandwidth {
+		return base
+	}
+	return &compressTransport{base: base}
+}
+
+// compressTransport is an http.RoundTripper sending JSON request bodies
+// compressed with gzip.
+type compressTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *compressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if req.Body == nil || req.Body == http.NoBody || mediaType != "application/json" ||
+		req.Header.Get("Content-Encoding") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	data := compressed.Bytes()
+	compressedReq := req.Clone(req.Context())
+	compressedReq.Header.Set("Content-Encoding", "gzip")
+	compressedReq.ContentLength = int64(len(data))
+	compressedReq.Body = io.NopCloser(bytes.NewReader(data))
+	compressedReq.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return t.base.RoundTrip(compressedReq)
+}
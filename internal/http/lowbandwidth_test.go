@@ -0,0 +1,97 @@
+package httpapi
+
+import (
+	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompressTransport(t *testing.T) {
+	type received struct {
+		encoding string
+		body     string
+	}
+	var got received
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = received{encoding: r.Header.Get("Content-Encoding")}
+		var body io.Reader = r.Body
+		if got.encoding == "gzip" {
+			reader, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("cannot decompress body: %v", err)
+				return
+			}
+			body = reader
+		}
+		data, _ := io.ReadAll(body)
+		got.body = string(data)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	LowBandwidth = true
+	t.Cleanup(func() { LowBandwidth = false })
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	client := NewHTTPClient(&tls.Config{RootCAs: pool})
+
+	tests := []struct {
+		description string
+		contentType string
+		want        received
+	}{
+		{
+			description: "json",
+			contentType: "application/json",
+			want:        received{encoding: "gzip", body: `{"facts":{}}`},
+		},
+		{
+			description: "json with charset",
+			contentType: "application/json; charset=utf-8",
+			want:        received{encoding: "gzip", body: `{"facts":{}}`},
+		},
+		{
+			description: "archive",
+			contentType: "application/octet-stream",
+			want:        received{encoding: "", body: `{"facts":{}}`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got = received{}
+			resp, err := client.Post(server.URL, test.contentType, strings.NewReader(`{"facts":{}}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+			if got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewHTTPClientLowBandwidth(t *testing.T) {
+	if client := NewHTTPClient(&tls.Config{}); client.Timeout != uploadTimeout {
+		t.Errorf("got timeout %v, want %v", client.Timeout, uploadTimeout)
+	}
+
+	LowBandwidth = true
+	t.Cleanup(func() { LowBandwidth = false })
+
+	client := NewHTTPClient(&tls.Config{})
+	if want := 4 * time.Minute; client.Timeout != want {
+		t.Errorf("got timeout %v, want %v", client.Timeout, want)
+	}
+	if _, ok := client.Transport.(*compressTransport); !ok {
+		t.Error("transport does not compress, LowBandwidth is set")
+	}
+}
@@ -0,0 +1,201 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Stages of the preflight check of an endpoint, in the order they are run.
+const (
+	StageDNS     = "dns"
+	StageConnect = "connect"
+	StageTLS     = "tls"
+	StageHTTP    = "http"
+	// StagePath is run only by PreflightResult.CheckPath.
+	StagePath = "path"
+)
+
+// PreflightStage is the outcome of a stage of the preflight check of an
+// endpoint. Stages following a failed stage are skipped.
+type PreflightStage struct {
+	Name       string `json:"name"`
+	Successful bool   `json:"successful"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// PreflightResult is the outcome of the preflight check of an endpoint.
+type PreflightResult struct {
+	URL string `json:"url"`
+	// Proxy is the proxy the endpoint is reached through, with its password
+	// redacted. DNS resolution and connection are checked for the proxy then.
+	Proxy      string           `json:"proxy,omitempty"`
+	Successful bool             `json:"successful"`
+	Stages     []PreflightStage `json:"stages"`
+	// statusCode is the HTTP status the server answered with.
+	statusCode int
+}
+
+// stage records the outcome of the stage name. Once a stage fails, the
+// following stages are recorded as skipped.
+func (r *PreflightResult) stage(name string, detail string, err error) {
+	if len(r.Stages) > 0 && !r.Stages[len(r.Stages)-1].Successful {
+		r.Stages = append(r.Stages, PreflightStage{Name: name, Skipped: true})
+		return
+	}
+	stage := PreflightStage{Name: name, Successful: err == nil, Detail: detail}
+	if err != nil {
+		stage.Error = err.Error()
+	}
+	r.Stages = append(r.Stages, stage)
+}
+
+// PreflightEndpoint checks, stage by stage, that the HTTPS endpoint rawURL can
+// be reached through the configured proxy and binding: that the host (or the
+// proxy) can be resolved and connected to, that the TLS handshake succeeds with
+// the certificate of the server verified against roots, and that the server
+// answers an HTTP request. Any HTTP status is an answer.
+func PreflightEndpoint(ctx context.Context, rawURL string, roots *x509.CertPool) PreflightResult {
+	result := PreflightResult{URL: rawURL, Stages: []PreflightStage{}}
+	target, err := url.Parse(rawURL)
+	if err != nil || target.Scheme != "https" || target.Hostname() == "" {
+		result.stage(StageDNS, "", fmt.Errorf("invalid endpoint URL %q", rawURL))
+		result.stage(StageConnect, "", nil)
+		result.stage(StageTLS, "", nil)
+		result.stage(StageHTTP, "", nil)
+		return result
+	}
+
+	transport := newTransport(&tls.Config{RootCAs: roots})
+	transport.DisableKeepAlives = true
+	ctx, cancel := context.WithTimeout(ctx, timeout(probeTimeout))
+	defer cancel()
+
+	// Connections are made to the proxy, when the endpoint is proxied
+	host, port := target.Hostname(), target.Port()
+	if port == "" {
+		port = "443"
+	}
+	if transport.Proxy != nil {
+		proxyURL, err := transport.Proxy(&http.Request{URL: target})
+		if err != nil {
+			result.stage(StageDNS, "", fmt.Errorf("invalid proxy: %w", err))
+		} else if proxyURL != nil {
+			result.Proxy = proxyURL.Redacted()
+			host, port = proxyURL.Hostname(), proxyURL.Port()
+			if port == "" {
+				port = "80"
+				if proxyURL.Scheme == "https" {
+					port = "443"
+				}
+			}
+		}
+	}
+
+	var addresses []string
+	if len(result.Stages) == 0 {
+		addresses, err = net.DefaultResolver.LookupHost(ctx, host)
+		detail := ""
+		if err == nil {
+			detail = fmt.Sprintf("%s resolved to %s", host, strings.Join(addresses, ", "))
+		}
+		result.stage(StageDNS, detail, err)
+	}
+
+	connected := false
+	if len(addresses) > 0 {
+		start := time.Now()
+		conn, err := transport.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+		detail := ""
+		if err == nil {
+			connected = true
+			detail = fmt.Sprintf("connected to %s in %s", conn.RemoteAddr(), time.Since(start).Round(time.Millisecond))
+			_ = conn.Close()
+		}
+		result.stage(StageConnect, detail, err)
+	} else {
+		result.stage(StageConnect, "", nil)
+	}
+	if !connected {
+		result.stage(StageTLS, "", nil)
+		result.stage(StageHTTP, "", nil)
+		return result
+	}
+
+	// The handshake is traced, so its failures are told apart from failures of
+	// the proxy and of the server
+	var handshakeDone bool
+	var handshakeState tls.ConnectionState
+	var handshakeErr error
+	trace := &httptrace.ClientTrace{
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			handshakeDone, handshakeState, handshakeErr = true, state, err
+		},
+	}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodHead, rawURL, nil)
+	if err != nil {
+		result.stage(StageTLS, "", err)
+		result.stage(StageHTTP, "", nil)
+		return result
+	}
+	resp, err := timedRoundTrip(transport, req)
+	switch {
+	case handshakeErr != nil:
+		result.stage(StageTLS, "", handshakeErr)
+	case !handshakeDone && err != nil:
+		result.stage(StageTLS, "", fmt.Errorf("TLS connection cannot be established: %w", err))
+	default:
+		result.stage(StageTLS, handshakeDetail(&handshakeState), nil)
+	}
+	if err != nil {
+		result.stage(StageHTTP, "", err)
+		return result
+	}
+	_ = resp.Body.Close()
+	result.statusCode = resp.StatusCode
+	result.stage(StageHTTP, resp.Status, nil)
+	result.Successful = true
+	return result
+}
+
+// CheckPath records, whether the server answering the preflight check serves
+// the path of the endpoint, e.g. of an API routed through a gateway. Unlike
+// other answers, "404 Not Found" fails the check.
+func (r *PreflightResult) CheckPath() {
+	path := "/"
+	if target, err := url.Parse(r.URL); err == nil && target.Path != "" {
+		path = target.Path
+	}
+	if !r.Successful {
+		r.stage(StagePath, "", nil)
+		return
+	}
+	if r.statusCode == http.StatusNotFound {
+		r.Successful = false
+		r.stage(StagePath, "", fmt.Errorf("%s is not served", path))
+		return
+	}
+	r.stage(StagePath, fmt.Sprintf("%s is served", path), nil)
+}
+
+// handshakeDetail describes the negotiated TLS session and the authority of
+// the certificate of the server.
+func handshakeDetail(state *tls.ConnectionState) string {
+	if len(state.PeerCertificates) == 0 {
+		return tls.VersionName(state.Version)
+	}
+	leaf := state.PeerCertificates[0]
+	return fmt.Sprintf(
+		"%s, certificate of %s issued by %s",
+		tls.VersionName(state.Version), leaf.Subject.CommonName, authorityName(leaf),
+	)
+}
@@ -0,0 +1,152 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// stageStatus returns the status of stages: "ok", "failed" or "skipped".
+func stageStatus(stages []PreflightStage) []string {
+	var statuses []string
+	for _, stage := range stages {
+		switch {
+		case stage.Skipped:
+			statuses = append(statuses, stage.Name+":skipped")
+		case stage.Successful:
+			statuses = append(statuses, stage.Name+":ok")
+		default:
+			statuses = append(statuses, stage.Name+":failed")
+		}
+	}
+	return statuses
+}
+
+func TestPreflightEndpoint(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	trusted := x509.NewCertPool()
+	trusted.AddCert(server.Certificate())
+
+	// A port nothing listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedURL := "https://" + listener.Addr().String()
+	_ = listener.Close()
+
+	tests := []struct {
+		description    string
+		url            string
+		roots          *x509.CertPool
+		wantSuccessful bool
+		want           []string
+	}{
+		{
+			description:    "reachable",
+			url:            server.URL,
+			roots:          trusted,
+			wantSuccessful: true,
+			want:           []string{"dns:ok", "connect:ok", "tls:ok", "http:ok"},
+		},
+		{
+			description: "untrusted certificate",
+			url:         server.URL,
+			roots:       x509.NewCertPool(),
+			want:        []string{"dns:ok", "connect:ok", "tls:failed", "http:skipped"},
+		},
+		{
+			description: "connection refused",
+			url:         closedURL,
+			roots:       trusted,
+			want:        []string{"dns:ok", "connect:failed", "tls:skipped", "http:skipped"},
+		},
+		{
+			description: "invalid URL",
+			url:         "http://example.com",
+			roots:       trusted,
+			want:        []string{"dns:failed", "connect:skipped", "tls:skipped", "http:skipped"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			result := PreflightEndpoint(context.Background(), test.url, test.roots)
+			if result.Successful != test.wantSuccessful {
+				t.Errorf("got successful %v, want %v: %+v", result.Successful, test.wantSuccessful, result.Stages)
+			}
+			got := stageStatus(result.Stages)
+			if len(got) != len(test.want) {
+				t.Fatalf("got stages %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("got stages %v, want %v", got, test.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestPreflightCheckPath(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/inventory/v1/hosts" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	trusted := x509.NewCertPool()
+	trusted.AddCert(server.Certificate())
+
+	tests := []struct {
+		description    string
+		url            string
+		roots          *x509.CertPool
+		wantSuccessful bool
+		want           []string
+	}{
+		{
+			description:    "served",
+			url:            server.URL + "/api/inventory/v1/hosts",
+			roots:          trusted,
+			wantSuccessful: true,
+			want:           []string{"dns:ok", "connect:ok", "tls:ok", "http:ok", "path:ok"},
+		},
+		{
+			description: "not served",
+			url:         server.URL + "/r/insights/inventory/v1/hosts",
+			roots:       trusted,
+			want:        []string{"dns:ok", "connect:ok", "tls:ok", "http:ok", "path:failed"},
+		},
+		{
+			description: "unreachable",
+			url:         server.URL + "/api/inventory/v1/hosts",
+			roots:       x509.NewCertPool(),
+			want:        []string{"dns:ok", "connect:ok", "tls:failed", "http:skipped", "path:skipped"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			result := PreflightEndpoint(context.Background(), test.url, test.roots)
+			result.CheckPath()
+			if result.Successful != test.wantSuccessful {
+				t.Errorf("got successful %v, want %v: %+v", result.Successful, test.wantSuccessful, result.Stages)
+			}
+			got := stageStatus(result.Stages)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got stages %v, want %v", got, test.want)
+			}
+		})
+	}
+}
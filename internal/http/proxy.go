@@ -0,0 +1,106 @@
+package httpapi
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ProxyConfig holds the proxy used for connections to Red Hat services.
+type ProxyConfig struct {
+	// URL is the proxy URL (e.g. "http://proxy.example.com:3128").
+	URL string
+	// NoProxy lists hosts connected to directly. An entry is a host name
+	// matching the host and its subdomains (a leading dot is optional),
+	// an IP address, a CIDR block, or "*" matching all hosts. Entries may
+	// contain a port.
+	NoProxy []string
+}
+
+// Proxy is the proxy configuration used by clients returned by NewHTTPClient.
+// When Proxy.URL is empty, the proxy is taken from the environment variables
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+var Proxy ProxyConfig
+
+// ProxyFunc returns a function selecting the proxy for a request, suitable
+// for http.Transport. Requests to hosts matching NoProxy are sent directly.
+func (c ProxyConfig) ProxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	proxyURL, err := url.Parse(c.URL)
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL")
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		if MatchNoProxy(req.URL.Host, c.NoProxy) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+// Environment returns the proxy configuration as environment variables
+// understood by insights-client, yggdrasil and other tools.
+// Nil is returned when no proxy is configured.
+func (c ProxyConfig) Environment() []string {
+	if c.URL == "" {
+		return nil
+	}
+	env := []string{
+		"HTTPS_PROXY=" + c.URL,
+		"HTTP_PROXY=" + c.URL,
+		"https_proxy=" + c.URL,
+		"http_proxy=" + c.URL,
+	}
+	if len(c.NoProxy) > 0 {
+		noProxy := strings.Join(c.NoProxy, ",")
+		env = append(env, "NO_PROXY="+noProxy, "no_proxy="+noProxy)
+	}
+	return env
+}
+
+// MatchNoProxy reports whether host (optionally with a port) matches
+// an entry of noProxy. See ProxyConfig.NoProxy for the format of entries.
+func MatchNoProxy(host string, noProxy []string) bool {
+	hostname, port := host, ""
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		hostname, port = h, p
+	}
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	ip := net.ParseIP(hostname)
+
+	for _, entry := range noProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		entryHost, entryPort := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, p
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		entryHost = strings.TrimPrefix(entryHost, "*")
+		entryHost = strings.TrimPrefix(entryHost, ".")
+		if hostname == entryHost || strings.HasSuffix(hostname, "."+entryHost) {
+			return true
+		}
+	}
+	return false
+}
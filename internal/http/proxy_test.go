@@ -0,0 +1,84 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMatchNoProxy(t *testing.T) {
+	noProxy := []string{"example.com", ".internal.test", "10.0.0.0/8", "192.168.1.1", "satellite.lan:8443"}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"example.com", true},
+		{"cert.example.com:443", true},
+		{"notexample.com", false},
+		{"host.internal.test", true},
+		{"internal.test", true},
+		{"10.1.2.3:443", true},
+		{"11.1.2.3", false},
+		{"192.168.1.1", true},
+		{"192.168.1.2", false},
+		{"satellite.lan:8443", true},
+		{"satellite.lan:443", false},
+		{"console.redhat.com:443", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.host, func(t *testing.T) {
+			if got := MatchNoProxy(test.host, noProxy); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	if !MatchNoProxy("console.redhat.com", []string{"*"}) {
+		t.Errorf("wildcard does not match")
+	}
+}
+
+func TestProxyFunc(t *testing.T) {
+	config := ProxyConfig{URL: "http://proxy.example.com:3128", NoProxy: []string{"satellite.lan"}}
+	proxy, err := config.ProxyFunc()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://console.redhat.com/api", nil)
+	proxyURL, err := proxy(req)
+	if err != nil || proxyURL == nil || proxyURL.Host != "proxy.example.com:3128" {
+		t.Errorf("got proxy %v (%v), want proxy.example.com:3128", proxyURL, err)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "https://satellite.lan/api", nil)
+	proxyURL, err = proxy(req)
+	if err != nil || proxyURL != nil {
+		t.Errorf("got proxy %v (%v), want direct connection", proxyURL, err)
+	}
+
+	if _, err = (ProxyConfig{URL: "proxy"}).ProxyFunc(); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestProxyEnvironment(t *testing.T) {
+	got := ProxyConfig{URL: "http://proxy:3128", NoProxy: []string{"a.test", "b.test"}}.Environment()
+	want := []string{
+		"HTTPS_PROXY=http://proxy:3128",
+		"HTTP_PROXY=http://proxy:3128",
+		"https_proxy=http://proxy:3128",
+		"http_proxy=http://proxy:3128",
+		"NO_PROXY=a.test,b.test",
+		"no_proxy=a.test,b.test",
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+	if env := (ProxyConfig{}).Environment(); env != nil {
+		t.Errorf("got %v, want nil", env)
+	}
+}
@@ -0,0 +1,168 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// serviceAccountScope is the scope of access tokens of the Red Hat API.
+const serviceAccountScope = "api.console"
+
+// tokenExpiryMargin is how long before its expiry an access token is renewed,
+// so a token does not expire while a request is in flight.
+const tokenExpiryMargin = 30 * time.Second
+
+// ServiceAccount holds the credentials of a service account of the Red Hat API.
+type ServiceAccount struct {
+	// APIURL is the base URL of the API; access tokens are sent only to its host.
+	APIURL string
+	// TokenURL is the endpoint issuing access tokens.
+	TokenURL string
+	ClientID string
+	// ClientSecretFile holds the client secret. The file is read whenever an
+	// access token is requested, so a rotated secret is picked up.
+	ClientSecretFile string
+}
+
+// tokenResponse is a response of the token endpoint.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// NewServiceAccountHTTPClient returns an HTTP client like NewHTTPClient, which
+// authenticates requests with access tokens of account, obtained with the OAuth
+// client credentials grant, instead of a client certificate. A token is reused
+// until it is about to expire, or until the API rejects it. Requests to other
+// hosts than the one of account.APIURL, e.g. after a redirect, are sent without
+// a token.
+func NewServiceAccountHTTPClient(account ServiceAccount) *http.Client {
+	base := timingTransport{base: newTransport(nil)}
+	return &http.Client{
+		Timeout:   timeout(uploadTimeout),
+		Transport: newTraceTransport(newCompressTransport(&bearerTransport{base: base, account: account})),
+	}
+}
+
+// bearerTransport is an http.RoundTripper setting the access token of a
+// service account on requests.
+type bearerTransport struct {
+	base    http.RoundTripper
+	account ServiceAccount
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.isAPIHost(req.URL) {
+		return t.base.RoundTrip(req)
+	}
+	resp, err := t.authorizedRoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		// The request body cannot be sent again
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+
+	slog.Debug("Server responded with 401 Unauthorized, renewing access token", "url", req.URL.String())
+	t.dropAccessToken()
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, fmt.Errorf("cannot rewind request body: %w", err)
+		}
+	}
+	return t.authorizedRoundTrip(retry)
+}
+
+// authorizedRoundTrip sends req with the access token.
+func (t *bearerTransport) authorizedRoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.accessToken(req)
+	if err != nil {
+		return nil, err
+	}
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(authorized)
+}
+
+// isAPIHost reports whether u is on the host of the API.
+func (t *bearerTransport) isAPIHost(u *url.URL) bool {
+	api, err := url.Parse(t.account.APIURL)
+	return err == nil && api.Host != "" && strings.EqualFold(api.Host, u.Host)
+}
+
+// dropAccessToken forgets the cached access token, so a new one is requested.
+func (t *bearerTransport) dropAccessToken() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.token = ""
+}
+
+// accessToken returns the cached access token, or requests a new one, when
+// there is none or it is about to expire.
+func (t *bearerTransport) accessToken(req *http.Request) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token != "" && time.Now().Before(t.expires) {
+		return t.token, nil
+	}
+
+	secret, err := os.ReadFile(t.account.ClientSecretFile)
+	if err != nil {
+		return "", fmt.Errorf("cannot read client secret of service account: %w", err)
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {t.account.ClientID},
+		"client_secret": {strings.TrimSpace(string(secret))},
+		"scope":         {serviceAccountScope},
+	}
+	tokenReq, err := http.NewRequestWithContext(
+		req.Context(), http.MethodPost, t.account.TokenURL, strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return "", fmt.Errorf("cannot create token request: %w", err)
+	}
+	tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.base.RoundTrip(tokenReq)
+	if err != nil {
+		return "", fmt.Errorf("cannot obtain access token of service account: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("cannot read access token of service account: %w", err)
+	}
+	var token tokenResponse
+	// Error responses of the token endpoint are JSON documents too
+	_ = json.Unmarshal(body, &token)
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		reason := resp.Status
+		if token.Error != "" {
+			reason = strings.TrimSuffix(token.Error+": "+token.ErrorDescription, ": ")
+		}
+		return "", fmt.Errorf("cannot obtain access token of service account %s: %s", t.account.ClientID, reason)
+	}
+
+	t.token = token.AccessToken
+	t.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - tokenExpiryMargin)
+	return t.token, nil
+}
@@ -0,0 +1,123 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServiceAccountHTTPClient(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tokenRequests := 0
+	var revoked bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			if err := r.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+			if r.PostForm.Get("grant_type") != "client_credentials" ||
+				r.PostForm.Get("client_id") != "rhc-test" ||
+				r.PostForm.Get("client_secret") != "s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error":"unauthorized_client","error_description":"Invalid client secret"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token":"token","expires_in":900,"token_type":"Bearer"}`))
+		case "/revoked":
+			if !revoked {
+				revoked = true
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			if got := r.Header.Get("Authorization"); got != "Bearer token" {
+				t.Errorf("got Authorization %q, want %q", got, "Bearer token")
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client := NewServiceAccountHTTPClient(ServiceAccount{
+		APIURL:           server.URL,
+		TokenURL:         server.URL + "/token",
+		ClientID:         "rhc-test",
+		ClientSecretFile: secretFile,
+	})
+	for range 2 {
+		resp, err := client.Get(server.URL + "/api/inventory/v1/hosts")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+	if tokenRequests != 1 {
+		t.Errorf("got %d token requests, want the token to be reused", tokenRequests)
+	}
+
+	// A rejected token is renewed and the request is sent once more
+	resp, err := client.Get(server.URL + "/revoked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || tokenRequests != 2 {
+		t.Errorf("got %v after %d token requests, want the request retried with a new token", resp.Status, tokenRequests)
+	}
+
+	client = NewServiceAccountHTTPClient(ServiceAccount{
+		APIURL:           server.URL,
+		TokenURL:         server.URL + "/token",
+		ClientID:         "other",
+		ClientSecretFile: secretFile,
+	})
+	if _, err := client.Get(server.URL + "/api/inventory/v1/hosts"); err == nil {
+		t.Error("expected error of rejected client credentials")
+	}
+}
+
+func TestServiceAccountHTTPClientOtherHost(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("access token sent to another host: %q", got)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer other.Close()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			_, _ = w.Write([]byte(`{"access_token":"token","expires_in":900,"token_type":"Bearer"}`))
+			return
+		}
+		http.Redirect(w, r, other.URL+"/elsewhere", http.StatusFound)
+	}))
+	defer api.Close()
+
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	client := NewServiceAccountHTTPClient(ServiceAccount{
+		APIURL:           api.URL,
+		TokenURL:         api.URL + "/token",
+		ClientID:         "rhc-test",
+		ClientSecretFile: secretFile,
+	})
+	resp, err := client.Get(api.URL + "/api/inventory/v1/hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("got %v, want the redirect followed", resp.Status)
+	}
+}
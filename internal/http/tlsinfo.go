@@ -0,0 +1,66 @@
+package httpapi
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// CertificateSummary describes a certificate presented by a server.
+type CertificateSummary struct {
+	Subject  string    `json:"subject"`
+	Issuer   string    `json:"issuer"`
+	NotAfter time.Time `json:"not_after"`
+	SHA256   string    `json:"sha256"`
+}
+
+// TLSSummary describes a negotiated TLS session.
+type TLSSummary struct {
+	Version     string               `json:"version"`
+	CipherSuite string               `json:"cipher_suite"`
+	ServerName  string               `json:"server_name"`
+	Chain       []CertificateSummary `json:"chain"`
+}
+
+// SummarizeCertificate returns the summary of cert.
+func SummarizeCertificate(cert *x509.Certificate) CertificateSummary {
+	fingerprint := sha256.Sum256(cert.Raw)
+	return CertificateSummary{
+		Subject:  cert.Subject.String(),
+		Issuer:   cert.Issuer.String(),
+		NotAfter: cert.NotAfter.UTC(),
+		SHA256:   hex.EncodeToString(fingerprint[:]),
+	}
+}
+
+// SummarizeTLS returns the summary of the TLS session state. The chain
+// is in the order presented by the server, starting with the leaf certificate.
+func SummarizeTLS(state *tls.ConnectionState) TLSSummary {
+	summary := TLSSummary{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ServerName:  state.ServerName,
+	}
+	for _, cert := range state.PeerCertificates {
+		summary.Chain = append(summary.Chain, SummarizeCertificate(cert))
+	}
+	return summary
+}
+
+// Lines returns the summary formatted for humans, one line per item.
+func (s TLSSummary) Lines() []string {
+	lines := []string{
+		fmt.Sprintf("TLS version: %s", s.Version),
+		fmt.Sprintf("cipher suite: %s", s.CipherSuite),
+	}
+	for i, cert := range s.Chain {
+		lines = append(lines, fmt.Sprintf(
+			"certificate %d: subject=%q issuer=%q expires=%s sha256=%s",
+			i, cert.Subject, cert.Issuer, cert.NotAfter.Format(time.RFC3339), cert.SHA256,
+		))
+	}
+	return lines
+}
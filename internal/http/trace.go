@@ -0,0 +1,90 @@
+package httpapi
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/redhatinsights/rhc/internal/logging"
+)
+
+// LogHTTP is where clients returned by NewHTTPClient write a trace of their
+// requests, including the TLS session of each endpoint. Nil disables the trace.
+var LogHTTP io.Writer
+
+// traceTransport is an http.RoundTripper writing a trace of requests to out.
+// The TLS session is described once per endpoint.
+type traceTransport struct {
+	base http.RoundTripper
+	out  io.Writer
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// newTraceTransport returns base wrapped in traceTransport when LogHTTP is set,
+// or base itself otherwise.
+func newTraceTransport(base http.RoundTripper) http.RoundTripper {
+	if LogHTTP == nil {
+		return base
+	}
+	return &traceTransport{base: base, out: LogHTTP, seen: make(map[string]bool)}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.printf("> %s %s\n", req.Method, req.URL.Redacted())
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.printf("< %s: %v\n", req.URL.Host, err)
+		return resp, err
+	}
+	t.printf("< %s %s\n", resp.Proto, resp.Status)
+
+	if resp.TLS != nil && t.firstVisit(req.URL.Host) {
+		summary := SummarizeTLS(resp.TLS)
+		for _, line := range summary.Lines() {
+			t.printf("* %s\n", line)
+		}
+		slog.Debug("TLS session established", "host", req.URL.Host, "version", summary.Version,
+			"cipher_suite", summary.CipherSuite)
+	}
+	return resp, nil
+}
+
+// firstVisit reports whether host is traced for the first time.
+func (t *traceTransport) firstVisit(host string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.seen[host] {
+		return false
+	}
+	t.seen[host] = true
+	return true
+}
+
+func (t *traceTransport) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(t.out, format, args...)
+}
+
+// timingTransport is an http.RoundTripper logging the start and the duration
+// of each request at DEBUG level, regardless of LogHTTP.
+type timingTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t timingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return timedRoundTrip(t.base, req)
+}
+
+// timedRoundTrip sends req with base, logging the start and the duration of
+// the request at DEBUG level.
+func timedRoundTrip(base http.RoundTripper, req *http.Request) (*http.Response, error) {
+	start := logging.HTTPRequestStart(req)
+	resp, err := base.RoundTrip(req)
+	logging.HTTPRequest(req, start, resp, err)
+	return resp, err
+}
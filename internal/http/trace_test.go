@@ -0,0 +1,58 @@
+package httpapi
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTraceTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	LogHTTP = &out
+	t.Cleanup(func() { LogHTTP = nil })
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	client := NewHTTPClient(&tls.Config{RootCAs: pool})
+
+	for range 2 {
+		resp, err := client.Get(server.URL + "/api/ping")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	trace := out.String()
+	wants := []string{
+		"> GET " + server.URL + "/api/ping\n",
+		"< HTTP/1.1 204 No Content\n",
+		"* TLS version: TLS 1.3\n",
+		"* cipher suite: ",
+		"* certificate 0: subject=\"O=Acme Co\"",
+	}
+	for _, want := range wants {
+		if !strings.Contains(trace, want) {
+			t.Errorf("trace does not contain %q:\n%s", want, trace)
+		}
+	}
+	if count := strings.Count(trace, "* TLS version"); count != 1 {
+		t.Errorf("TLS session described %d times, want 1", count)
+	}
+}
+
+func TestNewHTTPClientNoTrace(t *testing.T) {
+	client := NewHTTPClient(&tls.Config{})
+	if _, ok := client.Transport.(*traceTransport); ok {
+		t.Error("transport is traced, LogHTTP is not set")
+	}
+}
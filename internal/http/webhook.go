@@ -0,0 +1,73 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// WebhookEventHeader names the event of a webhook payload.
+	WebhookEventHeader = "X-Rhc-Event"
+	// WebhookTimestampHeader holds the time the webhook payload was sent, in
+	// seconds since the Unix epoch, so receivers can reject replayed payloads.
+	WebhookTimestampHeader = "X-Rhc-Timestamp"
+	// WebhookSignatureHeader holds the HMAC-SHA256 signature of the event, the
+	// timestamp and the payload of a webhook, in the format "sha256=<hex digest>".
+	WebhookSignatureHeader = "X-Rhc-Signature"
+)
+
+// webhookTimeout bounds the delivery of a webhook payload.
+const webhookTimeout = 10 * time.Second
+
+// WebhookSignature returns the value of WebhookSignatureHeader for payload of
+// event sent at timestamp, signed with secret. The signed message is the event,
+// the timestamp and the payload, separated by newlines, so neither the event
+// nor the timestamp can be changed, and a signed payload cannot be replayed
+// later without being noticed.
+func WebhookSignature(event, timestamp string, payload, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(event + "\n" + timestamp + "\n"))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// PostWebhook posts the JSON payload of event to url. The payload is signed,
+// when secret is not empty. Responses other than 2xx are errors. The webhook
+// is not a Red Hat endpoint, so Proxy and Bind do not apply.
+func PostWebhook(ctx context.Context, url string, event string, payload []byte, secret []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("cannot create webhook request: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, event)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	if len(secret) > 0 {
+		req.Header.Set(WebhookSignatureHeader, WebhookSignature(event, timestamp, payload, secret))
+	}
+
+	client := &http.Client{
+		Timeout:   timeout(webhookTimeout),
+		Transport: newTraceTransport(timingTransport{base: http.DefaultTransport}),
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot post to webhook: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package httpapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPostWebhook(t *testing.T) {
+	payload := []byte(`{"rhsm_connected":true}`)
+	secret := []byte("secret")
+
+	var gotEvent, gotTimestamp, gotSignature string
+	var gotPayload []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEvent = r.Header.Get(WebhookEventHeader)
+		gotTimestamp = r.Header.Get(WebhookTimestampHeader)
+		gotSignature = r.Header.Get(WebhookSignatureHeader)
+		gotPayload, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := PostWebhook(context.Background(), server.URL, "connect", payload, secret); err != nil {
+		t.Fatal(err)
+	}
+	if gotEvent != "connect" {
+		t.Errorf("got event %q, want %q", gotEvent, "connect")
+	}
+	if string(gotPayload) != string(payload) {
+		t.Errorf("got payload %q, want %q", gotPayload, payload)
+	}
+	if sent, err := strconv.ParseInt(gotTimestamp, 10, 64); err != nil || time.Since(time.Unix(sent, 0)) > time.Minute {
+		t.Errorf("got timestamp %q, want the current time", gotTimestamp)
+	}
+	if want := WebhookSignature("connect", gotTimestamp, payload, secret); gotSignature != want {
+		t.Errorf("got signature %q, want %q", gotSignature, want)
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	// printf 'connect\n1735725600\n{"rhsm_connected":true}' | openssl dgst -sha256 -hmac secret
+	want := "sha256=ca89c045805d1575ec9d64f2f2928c75c8d6bc64fdf74ef05da0f93e613e8254"
+	got := WebhookSignature("connect", "1735725600", []byte(`{"rhsm_connected":true}`), []byte("secret"))
+	if got != want {
+		t.Errorf("got signature %q, want %q", got, want)
+	}
+	// The event is signed, so it cannot be changed
+	if WebhookSignature("disconnect", "1735725600", []byte(`{"rhsm_connected":true}`), []byte("secret")) == want {
+		t.Error("signature does not depend on the event")
+	}
+}
+
+func TestPostWebhookUnsigned(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Header[WebhookSignatureHeader]; ok {
+			t.Error("unexpected signature of unsigned payload")
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	if err := PostWebhook(context.Background(), server.URL, "disconnect", []byte(`{}`), nil); err == nil {
+		t.Error("expected error for 500 response")
+	}
+}
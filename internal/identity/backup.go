@@ -0,0 +1,190 @@
+// Package identity backs up and restores the identity material of the host,
+// so a reinstalled host keeps its identity, and its history in the console.
+package identity
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/redhatinsights/rhc/internal/datacollection"
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/util"
+)
+
+// Path is a file of identity material included in a backup.
+type Path struct {
+	Path string
+	// Required files have to exist for a backup to be created.
+	Required bool
+	// Mode is the mode the file is restored with; the mode stored in a backup
+	// is not trusted.
+	Mode os.FileMode
+}
+
+// Paths are the files of identity material included in a backup. Only these
+// files are restored from a backup.
+var Paths = []Path{
+	{Path: subman.ConsumerCertPath, Required: true, Mode: 0644},
+	{Path: subman.ConsumerKeyPath, Required: true, Mode: 0600},
+	{Path: datacollection.InsightsMachineIDPath, Mode: 0644},
+	{Path: datacollection.InsightsHostDetailsPath, Mode: 0644},
+}
+
+// File is a file of identity material.
+type File struct {
+	Path string      `json:"path"`
+	Mode os.FileMode `json:"mode"`
+	Data []byte      `json:"data"`
+}
+
+// ErrWrongPassphrase is returned when a backup cannot be decrypted with the
+// passphrase.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted backup")
+
+const (
+	backupVersion = 1
+	backupKDF     = "pbkdf2-sha256"
+)
+
+// kdfIterations is the number of PBKDF2 iterations used for new backups.
+var kdfIterations = 600000
+
+// maxKDFIterations bounds the number of PBKDF2 iterations of a backup, so an
+// imported backup cannot make the key derivation run for hours.
+const maxKDFIterations = 10000000
+
+// backup is the format of an encrypted backup. The key is derived from the
+// passphrase and the salt, and the files are encrypted by AES-256-GCM.
+type backup struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Collect reads the files of identity material. Missing optional files are
+// left out.
+func Collect() ([]File, error) {
+	var files []File
+	for _, path := range Paths {
+		info, err := os.Stat(path.Path)
+		if errors.Is(err, os.ErrNotExist) && !path.Required {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read identity: %w", err)
+		}
+		data, err := os.ReadFile(path.Path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read identity: %w", err)
+		}
+		files = append(files, File{Path: path.Path, Mode: info.Mode().Perm(), Data: data})
+	}
+	return files, nil
+}
+
+// Encrypt returns the backup of files encrypted with passphrase.
+func Encrypt(files []File, passphrase string) ([]byte, error) {
+	plaintext, err := json.Marshal(files)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode identity: %w", err)
+	}
+
+	b := backup{Version: backupVersion, KDF: backupKDF, Iterations: kdfIterations, Salt: make([]byte, 16)}
+	if _, err = rand.Read(b.Salt); err != nil {
+		return nil, fmt.Errorf("cannot generate salt: %w", err)
+	}
+	aead, err := newAEAD(passphrase, b.Salt, b.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	b.Nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(b.Nonce); err != nil {
+		return nil, fmt.Errorf("cannot generate nonce: %w", err)
+	}
+	b.Ciphertext = aead.Seal(nil, b.Nonce, plaintext, nil)
+
+	return json.MarshalIndent(b, "", "  ")
+}
+
+// Decrypt returns the files of a backup encrypted with passphrase. Files
+// other than Paths are rejected.
+func Decrypt(data []byte, passphrase string) ([]File, error) {
+	var b backup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("cannot parse identity backup: %w", err)
+	}
+	if b.Version != backupVersion || b.KDF != backupKDF {
+		return nil, fmt.Errorf("unsupported identity backup: version %d, key derivation %q", b.Version, b.KDF)
+	}
+	if b.Iterations > maxKDFIterations {
+		return nil, fmt.Errorf("unsupported identity backup: %d iterations of key derivation", b.Iterations)
+	}
+	aead, err := newAEAD(passphrase, b.Salt, b.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plaintext, err := aead.Open(nil, b.Nonce, b.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	var files []File
+	if err = json.Unmarshal(plaintext, &files); err != nil {
+		return nil, fmt.Errorf("cannot parse identity backup: %w", err)
+	}
+	for _, file := range files {
+		if !slices.ContainsFunc(Paths, func(path Path) bool { return path.Path == file.Path }) {
+			return nil, fmt.Errorf("identity backup contains unexpected file %s", file.Path)
+		}
+	}
+	return files, nil
+}
+
+// Restore writes files of identity material with the modes of Paths. Files
+// other than Paths are rejected.
+func Restore(files []File) error {
+	for _, file := range files {
+		i := slices.IndexFunc(Paths, func(path Path) bool { return path.Path == file.Path })
+		if i < 0 {
+			return fmt.Errorf("cannot restore identity: unexpected file %s", file.Path)
+		}
+		if err := os.MkdirAll(filepath.Dir(file.Path), 0755); err != nil {
+			return fmt.Errorf("cannot restore identity: %w", err)
+		}
+		if err := util.WriteFileAtomic(file.Path, file.Data, Paths[i].Mode); err != nil {
+			return fmt.Errorf("cannot restore identity: %w", err)
+		}
+	}
+	return nil
+}
+
+// newAEAD returns AES-256-GCM keyed by the key derived from passphrase.
+func newAEAD(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations <= 0 || len(salt) == 0 {
+		return nil, fmt.Errorf("invalid key derivation parameters")
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("cannot derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,111 @@
+package identity
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBackupRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	oldPaths, oldIterations := Paths, kdfIterations
+	Paths = []Path{
+		{Path: filepath.Join(dir, "consumer", "cert.pem"), Required: true, Mode: 0644},
+		{Path: filepath.Join(dir, "consumer", "key.pem"), Required: true, Mode: 0600},
+		{Path: filepath.Join(dir, "machine-id"), Mode: 0644},
+	}
+	kdfIterations = 1000
+	t.Cleanup(func() { Paths, kdfIterations = oldPaths, oldIterations })
+
+	if err := os.MkdirAll(filepath.Join(dir, "consumer"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(Paths[0].Path, []byte("cert"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(Paths[1].Path, []byte("key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The optional machine-id is missing
+	files, err := Collect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []File{
+		{Path: Paths[0].Path, Mode: 0644, Data: []byte("cert")},
+		{Path: Paths[1].Path, Mode: 0600, Data: []byte("key")},
+	}
+	if !cmp.Equal(files, want) {
+		t.Fatalf("%v", cmp.Diff(files, want))
+	}
+
+	data, err := Encrypt(files, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Decrypt(data, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	if err = os.RemoveAll(filepath.Join(dir, "consumer")); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := Decrypt(data, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Restore(restored); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Collect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
+
+func TestDecryptUnexpectedFile(t *testing.T) {
+	oldIterations := kdfIterations
+	kdfIterations = 1000
+	t.Cleanup(func() { kdfIterations = oldIterations })
+
+	data, err := Encrypt([]File{{Path: "/etc/shadow", Mode: 0600, Data: []byte("root::")}}, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Decrypt(data, "secret"); err == nil {
+		t.Error("expected error for file outside of identity paths")
+	}
+}
+
+func TestRestoreMode(t *testing.T) {
+	dir := t.TempDir()
+	oldPaths := Paths
+	Paths = []Path{{Path: filepath.Join(dir, "key.pem"), Required: true, Mode: 0600}}
+	t.Cleanup(func() { Paths = oldPaths })
+
+	// The mode stored in the backup is ignored
+	if err := Restore([]File{{Path: Paths[0].Path, Mode: 0644, Data: []byte("key")}}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(Paths[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("got mode %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+}
+
+func TestDecryptIterations(t *testing.T) {
+	data := []byte(`{"version": 1, "kdf": "pbkdf2-sha256", "iterations": 2000000000, "salt": "c2FsdA==", "nonce": "", "ciphertext": ""}`)
+	if _, err := Decrypt(data, "secret"); err == nil || errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected error of too many iterations, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package localization
+
+import (
+	"slices"
+	"strings"
+)
+
+// answers are the words of the answers to yes/no questions in one language.
+// The first word of each answer is shown in the hint of the question.
+type answers struct {
+	yes []string
+	no  []string
+}
+
+var languageAnswers = map[string]answers{
+	"en": {yes: []string{"y", "yes"}, no: []string{"n", "no"}},
+	"cs": {yes: []string{"a", "ano"}, no: []string{"n", "ne"}},
+	"de": {yes: []string{"j", "ja"}, no: []string{"n", "nein"}},
+	"es": {yes: []string{"s", "sí", "si"}, no: []string{"n", "no"}},
+	"fr": {yes: []string{"o", "oui"}, no: []string{"n", "non"}},
+	"ja": {yes: []string{"y", "はい"}, no: []string{"n", "いいえ"}},
+}
+
+// getAnswers returns the answers of the language of the locale. English
+// answers are returned for unsupported locales.
+func getAnswers(locale string) answers {
+	if a, ok := languageAnswers[languageCode(locale)]; ok {
+		return a
+	}
+	return languageAnswers["en"]
+}
+
+// ParseAnswer returns whether answer to a yes/no question is yes in the language
+// of the locale. English answers are recognized in every language; ok is false,
+// when the answer is not recognized.
+func ParseAnswer(locale, answer string) (yes bool, ok bool) {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	for _, a := range []answers{getAnswers(locale), languageAnswers["en"]} {
+		switch {
+		case slices.Contains(a.yes, answer):
+			return true, true
+		case slices.Contains(a.no, answer):
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// AnswerHint returns the hint of the answers to a yes/no question in the
+// language of the locale, with the default answer capitalized (e.g. "[y/N]").
+func AnswerHint(locale string, defaultYes bool) string {
+	a := getAnswers(locale)
+	yes, no := a.yes[0], a.no[0]
+	if defaultYes {
+		yes = strings.ToUpper(yes)
+	} else {
+		no = strings.ToUpper(no)
+	}
+	return "[" + yes + "/" + no + "]"
+}
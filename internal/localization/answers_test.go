@@ -0,0 +1,56 @@
+package localization
+
+import "testing"
+
+func TestParseAnswer(t *testing.T) {
+	tests := []struct {
+		locale  string
+		answer  string
+		wantYes bool
+		wantOK  bool
+	}{
+		{locale: "en_US.UTF-8", answer: "y", wantYes: true, wantOK: true},
+		{locale: "en_US.UTF-8", answer: " Yes\n", wantYes: true, wantOK: true},
+		{locale: "en_US.UTF-8", answer: "no", wantYes: false, wantOK: true},
+		{locale: "en_US.UTF-8", answer: "ano", wantOK: false},
+		{locale: "cs_CZ.UTF-8", answer: "ano", wantYes: true, wantOK: true},
+		{locale: "cs_CZ.UTF-8", answer: "N", wantYes: false, wantOK: true},
+		{locale: "cs_CZ.UTF-8", answer: "yes", wantYes: true, wantOK: true},
+		{locale: "de_DE.UTF-8", answer: "ja", wantYes: true, wantOK: true},
+		{locale: "es_ES.UTF-8", answer: "sí", wantYes: true, wantOK: true},
+		{locale: "fr_FR.UTF-8", answer: "non", wantYes: false, wantOK: true},
+		{locale: "ja_JP.UTF-8", answer: "はい", wantYes: true, wantOK: true},
+		{locale: "C.UTF-8", answer: "maybe", wantOK: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.locale+" "+test.answer, func(t *testing.T) {
+			yes, ok := ParseAnswer(test.locale, test.answer)
+			if yes != test.wantYes || ok != test.wantOK {
+				t.Errorf("got (%v, %v), want (%v, %v)", yes, ok, test.wantYes, test.wantOK)
+			}
+		})
+	}
+}
+
+func TestAnswerHint(t *testing.T) {
+	tests := []struct {
+		locale     string
+		defaultYes bool
+		want       string
+	}{
+		{locale: "en_US.UTF-8", defaultYes: true, want: "[Y/n]"},
+		{locale: "en_US.UTF-8", defaultYes: false, want: "[y/N]"},
+		{locale: "cs_CZ.UTF-8", defaultYes: true, want: "[A/n]"},
+		{locale: "de_DE.UTF-8", defaultYes: false, want: "[j/N]"},
+		{locale: "C", defaultYes: false, want: "[y/N]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.locale, func(t *testing.T) {
+			if got := AnswerHint(test.locale, test.defaultYes); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -4,11 +4,27 @@ import (
 	"os"
 )
 
+// MachineLocale is the locale of machine-readable output. Messages of services
+// (e.g. errors of subscription-manager) included in machine-readable output are
+// requested in this locale, so they are not translated.
+const MachineLocale = "C.UTF-8"
+
+// locale overrides the locale of the environment, when it is not empty.
+var locale string
+
+// SetLocale overrides the locale of the environment returned by GetLocale.
+// An empty value restores the locale of the environment.
+func SetLocale(value string) {
+	locale = value
+}
+
 // GetLocale tries to get current locale
 func GetLocale() string {
+	if locale != "" {
+		return locale
+	}
 	// FIXME: Locale should be detected in more reliable way. We are going to support
 	//        localization in better way. Maybe we could use following go module
 	//        https://github.com/Xuanwo/go-locale. Maybe some other will be better.
-	locale := os.Getenv("LANG")
-	return locale
+	return os.Getenv("LANG")
 }
@@ -0,0 +1,20 @@
+package localization
+
+import "testing"
+
+func TestSetLocale(t *testing.T) {
+	t.Setenv("LANG", "cs_CZ.UTF-8")
+	t.Cleanup(func() { SetLocale("") })
+
+	if got := GetLocale(); got != "cs_CZ.UTF-8" {
+		t.Errorf("got %q, want locale of the environment", got)
+	}
+	SetLocale(MachineLocale)
+	if got := GetLocale(); got != MachineLocale {
+		t.Errorf("got %q, want %q", got, MachineLocale)
+	}
+	SetLocale("")
+	if got := GetLocale(); got != "cs_CZ.UTF-8" {
+		t.Errorf("got %q, want locale of the environment", got)
+	}
+}
@@ -0,0 +1,302 @@
+package localization
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unit identifies a unit of time used in humanized durations.
+type unit int
+
+const (
+	millisecond unit = iota
+	second
+	minute
+	hour
+	day
+)
+
+// language holds the rules and words needed to humanize values in one language.
+type language struct {
+	// plural returns the index of the plural form used with count n.
+	plural func(n int) int
+	// units are the plural forms of time units, as used in a plain duration.
+	units map[unit][]string
+	// agoUnits are the plural forms of time units used in the ago phrase,
+	// when they differ from units.
+	agoUnits map[unit][]string
+	// inUnits are the plural forms of time units used in the in phrase,
+	// when they differ from units.
+	inUnits map[unit][]string
+	// quantity is the format of a count followed by a unit.
+	quantity string
+	// ago is the format of a duration in the past.
+	ago string
+	// in is the format of a duration in the future.
+	in string
+	// decimalSeparator separates the fractional part of a number.
+	decimalSeparator string
+}
+
+// pluralOneOther is the plural rule of languages distinguishing
+// singular for one and plural for other counts (e.g. English).
+func pluralOneOther(n int) int {
+	if n == 1 {
+		return 0
+	}
+	return 1
+}
+
+// pluralZeroOneOther is the plural rule of languages using singular
+// for zero and one (e.g. French).
+func pluralZeroOneOther(n int) int {
+	if n == 0 || n == 1 {
+		return 0
+	}
+	return 1
+}
+
+// pluralCzech is the plural rule of Czech: one, few (2-4), and other.
+func pluralCzech(n int) int {
+	switch {
+	case n == 1:
+		return 0
+	case n >= 2 && n <= 4:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// pluralNone is the plural rule of languages without plural forms (e.g. Japanese).
+func pluralNone(int) int {
+	return 0
+}
+
+// germanDativeUnits are the German time units in dative case,
+// used after both "vor" and "in".
+var germanDativeUnits = map[unit][]string{
+	millisecond: {"Millisekunde", "Millisekunden"},
+	second:      {"Sekunde", "Sekunden"},
+	minute:      {"Minute", "Minuten"},
+	hour:        {"Stunde", "Stunden"},
+	day:         {"Tag", "Tagen"},
+}
+
+var languages = map[string]*language{
+	"en": {
+		plural: pluralOneOther,
+		units: map[unit][]string{
+			millisecond: {"millisecond", "milliseconds"},
+			second:      {"second", "seconds"},
+			minute:      {"minute", "minutes"},
+			hour:        {"hour", "hours"},
+			day:         {"day", "days"},
+		},
+		quantity:         "%d %s",
+		ago:              "%s ago",
+		in:               "in %s",
+		decimalSeparator: ".",
+	},
+	"cs": {
+		plural: pluralCzech,
+		units: map[unit][]string{
+			millisecond: {"milisekunda", "milisekundy", "milisekund"},
+			second:      {"sekunda", "sekundy", "sekund"},
+			minute:      {"minuta", "minuty", "minut"},
+			hour:        {"hodina", "hodiny", "hodin"},
+			day:         {"den", "dny", "dní"},
+		},
+		agoUnits: map[unit][]string{
+			millisecond: {"milisekundou", "milisekundami", "milisekundami"},
+			second:      {"sekundou", "sekundami", "sekundami"},
+			minute:      {"minutou", "minutami", "minutami"},
+			hour:        {"hodinou", "hodinami", "hodinami"},
+			day:         {"dnem", "dny", "dny"},
+		},
+		inUnits: map[unit][]string{
+			millisecond: {"milisekundu", "milisekundy", "milisekund"},
+			second:      {"sekundu", "sekundy", "sekund"},
+			minute:      {"minutu", "minuty", "minut"},
+			hour:        {"hodinu", "hodiny", "hodin"},
+			day:         {"den", "dny", "dní"},
+		},
+		quantity:         "%d %s",
+		ago:              "před %s",
+		in:               "za %s",
+		decimalSeparator: ",",
+	},
+	"de": {
+		plural: pluralOneOther,
+		units: map[unit][]string{
+			millisecond: {"Millisekunde", "Millisekunden"},
+			second:      {"Sekunde", "Sekunden"},
+			minute:      {"Minute", "Minuten"},
+			hour:        {"Stunde", "Stunden"},
+			day:         {"Tag", "Tage"},
+		},
+		agoUnits:         germanDativeUnits,
+		inUnits:          germanDativeUnits,
+		quantity:         "%d %s",
+		ago:              "vor %s",
+		in:               "in %s",
+		decimalSeparator: ",",
+	},
+	"es": {
+		plural: pluralOneOther,
+		units: map[unit][]string{
+			millisecond: {"milisegundo", "milisegundos"},
+			second:      {"segundo", "segundos"},
+			minute:      {"minuto", "minutos"},
+			hour:        {"hora", "horas"},
+			day:         {"día", "días"},
+		},
+		quantity:         "%d %s",
+		ago:              "hace %s",
+		in:               "dentro de %s",
+		decimalSeparator: ",",
+	},
+	"fr": {
+		plural: pluralZeroOneOther,
+		units: map[unit][]string{
+			millisecond: {"milliseconde", "millisecondes"},
+			second:      {"seconde", "secondes"},
+			minute:      {"minute", "minutes"},
+			hour:        {"heure", "heures"},
+			day:         {"jour", "jours"},
+		},
+		quantity:         "%d %s",
+		ago:              "il y a %s",
+		in:               "dans %s",
+		decimalSeparator: ",",
+	},
+	"ja": {
+		plural: pluralNone,
+		units: map[unit][]string{
+			millisecond: {"ミリ秒"},
+			second:      {"秒"},
+			minute:      {"分"},
+			hour:        {"時間"},
+			day:         {"日"},
+		},
+		quantity:         "%d%s",
+		ago:              "%s前",
+		in:               "%s後",
+		decimalSeparator: ".",
+	},
+}
+
+// languageCode returns the code of the language of the locale (e.g. "cs" of
+// "cs_CZ.UTF-8").
+func languageCode(locale string) string {
+	code, _, _ := strings.Cut(locale, "_")
+	code, _, _ = strings.Cut(code, ".")
+	return strings.ToLower(code)
+}
+
+// getLanguage returns the language of the locale (e.g. "cs_CZ.UTF-8").
+// English is returned for unsupported locales.
+func getLanguage(locale string) *language {
+	if lang, ok := languages[languageCode(locale)]; ok {
+		return lang
+	}
+	return languages["en"]
+}
+
+// IsSupported reports whether values are formatted in the language of the
+// locale, rather than falling back to English.
+func IsSupported(locale string) bool {
+	_, ok := languages[languageCode(locale)]
+	return ok
+}
+
+// quantityOf formats count of unit u using plural forms from forms.
+func (lang *language) quantityOf(count int, u unit, forms map[unit][]string) string {
+	if forms == nil {
+		forms = lang.units
+	}
+	return fmt.Sprintf(lang.quantity, count, forms[u][lang.plural(count)])
+}
+
+// largestUnit splits duration d into its largest nonzero unit and the remainder.
+func largestUnit(d time.Duration) (int, unit, time.Duration) {
+	switch {
+	case d >= 24*time.Hour:
+		return int(d / (24 * time.Hour)), day, d % (24 * time.Hour)
+	case d >= time.Hour:
+		return int(d / time.Hour), hour, d % time.Hour
+	case d >= time.Minute:
+		return int(d / time.Minute), minute, d % time.Minute
+	case d >= time.Second:
+		return int(d / time.Second), second, d % time.Second
+	default:
+		return int(d / time.Millisecond), millisecond, 0
+	}
+}
+
+// FormatDuration returns the duration d in human-readable form in the language
+// of locale, using at most two units (e.g. "1 minute 5 seconds").
+func FormatDuration(locale string, d time.Duration) string {
+	lang := getLanguage(locale)
+	if d < 0 {
+		d = -d
+	}
+
+	count, u, rest := largestUnit(d)
+	result := lang.quantityOf(count, u, nil)
+	if u <= second {
+		return result
+	}
+	if restCount, restUnit, _ := largestUnit(rest); restCount > 0 && restUnit == u-1 {
+		result += " " + lang.quantityOf(restCount, restUnit, nil)
+	}
+	return result
+}
+
+// FormatTimeAgo returns the time elapsed since an event, given as duration d,
+// in human-readable form in the language of locale (e.g. "2 minutes ago").
+// Only the largest unit is used.
+func FormatTimeAgo(locale string, d time.Duration) string {
+	lang := getLanguage(locale)
+	if d < 0 {
+		d = -d
+	}
+	count, u, _ := largestUnit(max(d, time.Second))
+	return fmt.Sprintf(lang.ago, lang.quantityOf(count, u, lang.agoUnits))
+}
+
+// FormatTimeUntil returns the time remaining until an event, given as duration d,
+// in human-readable form in the language of locale (e.g. "in 2 minutes").
+// Only the largest unit is used.
+func FormatTimeUntil(locale string, d time.Duration) string {
+	lang := getLanguage(locale)
+	if d < 0 {
+		d = -d
+	}
+	count, u, _ := largestUnit(max(d, time.Second))
+	return fmt.Sprintf(lang.in, lang.quantityOf(count, u, lang.inUnits))
+}
+
+// FormatSize returns the size in bytes in human-readable form using binary
+// prefixes and the decimal separator of the language of locale (e.g. "1.5 MiB").
+func FormatSize(locale string, size int64) string {
+	lang := getLanguage(locale)
+	const base = 1024
+	if size < base && size > -base {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value := float64(size)
+	prefixes := "KMGTPE"
+	i := -1
+	for (value >= base || value <= -base) && i < len(prefixes)-1 {
+		value /= base
+		i++
+	}
+	number := strconv.FormatFloat(value, 'f', 1, 64)
+	number = strings.TrimSuffix(number, ".0")
+	number = strings.Replace(number, ".", lang.decimalSeparator, 1)
+	return fmt.Sprintf("%s %ciB", number, prefixes[i])
+}
@@ -0,0 +1,132 @@
+package localization
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		locale string
+		input  time.Duration
+		want   string
+	}{
+		{"en_US.UTF-8", 350 * time.Millisecond, "350 milliseconds"},
+		{"en_US.UTF-8", 1200 * time.Millisecond, "1 second"},
+		{"en_US.UTF-8", 65 * time.Second, "1 minute 5 seconds"},
+		{"en_US.UTF-8", 2 * time.Hour, "2 hours"},
+		{"en_US.UTF-8", 26*time.Hour + 30*time.Minute, "1 day 2 hours"},
+		{"en_US.UTF-8", -3 * time.Second, "3 seconds"},
+		{"cs_CZ.UTF-8", 1 * time.Minute, "1 minuta"},
+		{"cs_CZ.UTF-8", 3 * time.Minute, "3 minuty"},
+		{"cs_CZ.UTF-8", 5 * time.Minute, "5 minut"},
+		{"de_DE.UTF-8", 2 * 24 * time.Hour, "2 Tage"},
+		{"fr_FR.UTF-8", 0, "0 milliseconde"},
+		{"ja_JP.UTF-8", 3 * time.Hour, "3時間"},
+		{"C", 2 * time.Second, "2 seconds"},
+		{"", 1 * time.Second, "1 second"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.locale+"/"+test.input.String(), func(t *testing.T) {
+			got := FormatDuration(test.locale, test.input)
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatTimeAgo(t *testing.T) {
+	tests := []struct {
+		locale string
+		input  time.Duration
+		want   string
+	}{
+		{"en_US.UTF-8", 2*time.Minute + 10*time.Second, "2 minutes ago"},
+		{"en_US.UTF-8", 1 * time.Hour, "1 hour ago"},
+		{"en_US.UTF-8", 0, "1 second ago"},
+		{"cs_CZ.UTF-8", 1 * time.Minute, "před 1 minutou"},
+		{"cs_CZ.UTF-8", 2 * time.Minute, "před 2 minutami"},
+		{"de_DE.UTF-8", 3 * 24 * time.Hour, "vor 3 Tagen"},
+		{"es_ES.UTF-8", 2 * time.Hour, "hace 2 horas"},
+		{"fr_FR.UTF-8", 1 * time.Hour, "il y a 1 heure"},
+		{"ja_JP.UTF-8", 2 * time.Minute, "2分前"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.locale+"/"+test.input.String(), func(t *testing.T) {
+			got := FormatTimeAgo(test.locale, test.input)
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatTimeUntil(t *testing.T) {
+	tests := []struct {
+		locale string
+		input  time.Duration
+		want   string
+	}{
+		{"en_US.UTF-8", 5 * time.Hour, "in 5 hours"},
+		{"cs_CZ.UTF-8", 1 * time.Hour, "za 1 hodinu"},
+		{"de_DE.UTF-8", 2 * 24 * time.Hour, "in 2 Tagen"},
+		{"ja_JP.UTF-8", 10 * time.Second, "10秒後"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.locale+"/"+test.input.String(), func(t *testing.T) {
+			got := FormatTimeUntil(test.locale, test.input)
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		locale string
+		input  int64
+		want   string
+	}{
+		{"en_US.UTF-8", 512, "512 B"},
+		{"en_US.UTF-8", 1024, "1 KiB"},
+		{"en_US.UTF-8", 1536 * 1024, "1.5 MiB"},
+		{"cs_CZ.UTF-8", 1536 * 1024, "1,5 MiB"},
+		{"en_US.UTF-8", 3 * 1024 * 1024 * 1024, "3 GiB"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.locale+"/"+test.want, func(t *testing.T) {
+			got := FormatSize(test.locale, test.input)
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsSupported(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   bool
+	}{
+		{"en_US.UTF-8", true},
+		{"cs_CZ.UTF-8", true},
+		{"ja_JP", true},
+		{"C.UTF-8", false},
+		{"pt_BR.UTF-8", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.locale, func(t *testing.T) {
+			if got := IsSupported(test.locale); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
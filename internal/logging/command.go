@@ -0,0 +1,28 @@
+package logging
+
+import (
+	"log/slog"
+	"os/exec"
+	"time"
+)
+
+// CommandStart logs the start of the subprocess cmd at DEBUG level, and returns
+// the time it started, to be passed to Command. Arguments of cmd are not
+// logged, because they may hold credentials.
+func CommandStart(cmd *exec.Cmd) time.Time {
+	slog.Debug("Subprocess started", "path", cmd.Path)
+	return time.Now()
+}
+
+// Command logs the finished subprocess cmd at DEBUG level, with the duration
+// since start, its exit code and the error of running it.
+func Command(cmd *exec.Cmd, start time.Time, err error) {
+	attrs := []any{"path", cmd.Path, "duration", time.Since(start)}
+	if cmd.ProcessState != nil {
+		attrs = append(attrs, "exit_code", cmd.ProcessState.ExitCode())
+	}
+	if err != nil {
+		attrs = append(attrs, "err", err)
+	}
+	slog.Debug("Subprocess finished", attrs...)
+}
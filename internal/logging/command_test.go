@@ -0,0 +1,26 @@
+package logging
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	buf := captureLogs(t)
+
+	cmd := exec.Command("/bin/sh", "-c", "exit 3", "secret")
+	start := CommandStart(cmd)
+	err := cmd.Run()
+	Command(cmd, start, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got %q", lines)
+	}
+	checkLogs(t, lines[0], `msg="Subprocess started"`, "path=/bin/sh")
+	checkLogs(t, lines[1], `msg="Subprocess finished"`, "path=/bin/sh", "duration=", "exit_code=3", "err=")
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("arguments logged in %q", buf.String())
+	}
+}
@@ -0,0 +1,26 @@
+package logging
+
+import (
+	"log/slog"
+	"time"
+)
+
+// DBusCallStart logs the start of a D-Bus call of method on the object at path
+// at DEBUG level, with additional attrs, and returns the time the call started,
+// to be passed to DBusCall.
+func DBusCallStart(method string, path string, attrs ...any) time.Time {
+	slog.Debug("D-Bus call started", append([]any{"method", method, "path", path}, attrs...)...)
+	return time.Now()
+}
+
+// DBusCall logs a finished D-Bus call of method on the object at path at
+// DEBUG level, with the duration since start, the error of the call and
+// additional attrs. Arguments of the call are not logged, because they may
+// hold credentials.
+func DBusCall(method string, path string, start time.Time, err error, attrs ...any) {
+	attrs = append([]any{"method", method, "path", path, "duration", time.Since(start)}, attrs...)
+	if err != nil {
+		attrs = append(attrs, "err", err)
+	}
+	slog.Debug("D-Bus call finished", attrs...)
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// captureLogs sets the default logger to write DEBUG records to the returned
+// buffer, until the test finishes.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	oldDefault := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(oldDefault) })
+	return &buf
+}
+
+// checkLogs reports each of want not found in the logs.
+func checkLogs(t *testing.T, logs string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(logs, w) {
+			t.Errorf("%q not found in %q", w, logs)
+		}
+	}
+}
+
+func TestDBusCall(t *testing.T) {
+	buf := captureLogs(t)
+
+	start := DBusCallStart(
+		"org.freedesktop.systemd1.Manager.StartUnit",
+		"/org/freedesktop/systemd1",
+		"unit", "yggdrasil.service",
+	)
+	DBusCall(
+		"org.freedesktop.systemd1.Manager.StartUnit",
+		"/org/freedesktop/systemd1",
+		start,
+		errors.New("access denied"),
+		"unit", "yggdrasil.service",
+	)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got %q", lines)
+	}
+	checkLogs(t, lines[0],
+		`msg="D-Bus call started"`,
+		"method=org.freedesktop.systemd1.Manager.StartUnit",
+		"unit=yggdrasil.service",
+	)
+	checkLogs(t, lines[1],
+		`msg="D-Bus call finished"`,
+		"method=org.freedesktop.systemd1.Manager.StartUnit",
+		"path=/org/freedesktop/systemd1",
+		"duration=",
+		"unit=yggdrasil.service",
+		`err="access denied"`,
+	)
+}
@@ -0,0 +1,29 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// HTTPRequestStart logs the start of req at DEBUG level, and returns the time
+// it started, to be passed to HTTPRequest. The query of the URL is not logged,
+// because it may hold credentials.
+func HTTPRequestStart(req *http.Request) time.Time {
+	slog.Debug("HTTP request started", "method", req.Method, "host", req.URL.Host, "path", req.URL.Path)
+	return time.Now()
+}
+
+// HTTPRequest logs the finished req at DEBUG level, with the duration since
+// start, the status of resp and the error of the request. The duration covers
+// the response headers only, not reading of the response body.
+func HTTPRequest(req *http.Request, start time.Time, resp *http.Response, err error) {
+	attrs := []any{"method", req.Method, "host", req.URL.Host, "path", req.URL.Path, "duration", time.Since(start)}
+	if resp != nil {
+		attrs = append(attrs, "status", resp.StatusCode)
+	}
+	if err != nil {
+		attrs = append(attrs, "err", err)
+	}
+	slog.Debug("HTTP request finished", attrs...)
+}
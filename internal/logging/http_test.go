@@ -0,0 +1,28 @@
+package logging
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHTTPRequest(t *testing.T) {
+	buf := captureLogs(t)
+
+	req, err := http.NewRequest(http.MethodGet, "https://cert.console.redhat.com/api/v1?token=secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := HTTPRequestStart(req)
+	HTTPRequest(req, start, &http.Response{StatusCode: http.StatusNotFound}, nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got %q", lines)
+	}
+	checkLogs(t, lines[0], `msg="HTTP request started"`, "method=GET", "host=cert.console.redhat.com", "path=/api/v1")
+	checkLogs(t, lines[1], `msg="HTTP request finished"`, "duration=", "status=404")
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("query logged in %q", buf.String())
+	}
+}
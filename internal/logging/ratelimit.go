@@ -0,0 +1,155 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimitState is shared by all handlers derived from a single rate limiting handler.
+type rateLimitState struct {
+	mu       sync.Mutex
+	interval time.Duration
+	now      func() time.Time
+	seen     map[string]*occurrence
+}
+
+// occurrence records when a log line was last written, and how many identical
+// log lines were suppressed since then. The last suppressed record and the
+// handler it was passed to are kept, so its summary can be written on Close.
+type occurrence struct {
+	last       time.Time
+	suppressed int
+	record     slog.Record
+	next       slog.Handler
+}
+
+// RateLimitHandler is a slog.Handler deduplicating identical log records of
+// warning or higher level. The first record is passed to the next handler;
+// identical records within the interval are suppressed and counted. The next
+// record written after the interval carries a "repeated" attribute with the
+// number of suppressed records. Close writes the summaries of records, which
+// were suppressed, but not written again.
+type RateLimitHandler struct {
+	next   slog.Handler
+	state  *rateLimitState
+	prefix string
+}
+
+// NewRateLimitHandler returns a handler passing records to next, with
+// identical warnings and errors written at most once per interval.
+func NewRateLimitHandler(next slog.Handler, interval time.Duration) *RateLimitHandler {
+	return &RateLimitHandler{
+		next: next,
+		state: &rateLimitState{
+			interval: interval,
+			now:      time.Now,
+			seen:     make(map[string]*occurrence),
+		},
+	}
+}
+
+// Enabled implements slog.Handler.
+func (h *RateLimitHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *RateLimitHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level < slog.LevelWarn {
+		return h.next.Handle(ctx, record)
+	}
+
+	key := h.key(record)
+	now := h.state.now()
+
+	h.state.mu.Lock()
+	o, ok := h.state.seen[key]
+	if ok && now.Sub(o.last) < h.state.interval {
+		o.suppressed++
+		o.record, o.next = record.Clone(), h.next
+		h.state.mu.Unlock()
+		return nil
+	}
+	suppressed := 0
+	if ok {
+		suppressed = o.suppressed
+	}
+	h.state.seen[key] = &occurrence{last: now}
+	h.forgetExpired(now)
+	h.state.mu.Unlock()
+
+	if suppressed > 0 {
+		record = record.Clone()
+		record.AddAttrs(slog.Int("repeated", suppressed))
+	}
+	return h.next.Handle(ctx, record)
+}
+
+// Close writes the last record of every group of suppressed records, with the
+// number of suppressed records in the "repeated" attribute, so the counts are
+// not lost when the program exits. The handler can be used after Close.
+func (h *RateLimitHandler) Close() error {
+	h.state.mu.Lock()
+	var pending []*occurrence
+	for key, o := range h.state.seen {
+		if o.suppressed > 0 {
+			pending = append(pending, o)
+		}
+		delete(h.state.seen, key)
+	}
+	h.state.mu.Unlock()
+
+	// Summaries are written in the order the records were logged
+	slices.SortFunc(pending, func(a, b *occurrence) int { return a.record.Time.Compare(b.record.Time) })
+	var errs []error
+	for _, o := range pending {
+		record := o.record.Clone()
+		record.AddAttrs(slog.Int("repeated", o.suppressed))
+		errs = append(errs, o.next.Handle(context.Background(), record))
+	}
+	return errors.Join(errs...)
+}
+
+// forgetExpired removes records that were not repeated within the interval.
+// The caller must hold the lock.
+func (h *RateLimitHandler) forgetExpired(now time.Time) {
+	for key, o := range h.state.seen {
+		if o.suppressed == 0 && now.Sub(o.last) >= h.state.interval {
+			delete(h.state.seen, key)
+		}
+	}
+}
+
+// key identifies identical log records by their level, message and attributes.
+func (h *RateLimitHandler) key(record slog.Record) string {
+	var b strings.Builder
+	b.WriteString(h.prefix)
+	b.WriteString(record.Level.String())
+	b.WriteString("|")
+	b.WriteString(record.Message)
+	record.Attrs(func(attr slog.Attr) bool {
+		b.WriteString("|")
+		b.WriteString(attr.String())
+		return true
+	})
+	return b.String()
+}
+
+// WithAttrs implements slog.Handler.
+func (h *RateLimitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	prefix := h.prefix
+	for _, attr := range attrs {
+		prefix += attr.String() + "|"
+	}
+	return &RateLimitHandler{next: h.next.WithAttrs(attrs), state: h.state, prefix: prefix}
+}
+
+// WithGroup implements slog.Handler.
+func (h *RateLimitHandler) WithGroup(name string) slog.Handler {
+	return &RateLimitHandler{next: h.next.WithGroup(name), state: h.state, prefix: h.prefix + name + "."}
+}
@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimitHandler(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	handler := NewRateLimitHandler(
+		slog.NewTextHandler(&buf, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}),
+		time.Minute,
+	)
+	handler.state.now = func() time.Time { return now }
+	logger := slog.New(handler)
+
+	logger.Error("broker unreachable", "error", "timeout")
+	logger.Error("broker unreachable", "error", "timeout")
+	logger.Error("broker unreachable", "error", "timeout")
+	logger.Error("broker unreachable", "error", "refused")
+	logger.Info("retrying")
+	logger.Info("retrying")
+	now = now.Add(time.Minute)
+	logger.Error("broker unreachable", "error", "timeout")
+
+	want := []string{
+		`level=ERROR msg="broker unreachable" error=timeout`,
+		`level=ERROR msg="broker unreachable" error=refused`,
+		`level=INFO msg=retrying`,
+		`level=INFO msg=retrying`,
+		`level=ERROR msg="broker unreachable" error=timeout repeated=2`,
+	}
+	got := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRateLimitHandlerClose(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewRateLimitHandler(
+		slog.NewTextHandler(&buf, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}),
+		time.Minute,
+	)
+	logger := slog.New(handler)
+
+	logger.Error("broker unreachable", "error", "timeout")
+	logger.Error("broker unreachable", "error", "timeout")
+	logger.Error("broker unreachable", "error", "timeout")
+	logger.With("unit", "yggdrasil").Warn("restarting")
+	logger.With("unit", "yggdrasil").Warn("restarting")
+	logger.Warn("not repeated")
+	if err := handler.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`level=ERROR msg="broker unreachable" error=timeout`,
+		`level=WARN msg=restarting unit=yggdrasil`,
+		`level=WARN msg="not repeated"`,
+		`level=ERROR msg="broker unreachable" error=timeout repeated=2`,
+		`level=WARN msg=restarting unit=yggdrasil repeated=1`,
+	}
+	got := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,267 @@
+package logging
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// syslogFacility is the facility of forwarded messages (daemon).
+	syslogFacility = 3
+	// syslogSDID identifies the structured data element holding the attributes
+	// of records, under the private enterprise number of Red Hat.
+	syslogSDID = "rhc@2312"
+	// syslogWriteTimeout bounds writing of a single message, so an unresponsive
+	// collector does not block rhc.
+	syslogWriteTimeout = 5 * time.Second
+)
+
+// syslogDefaultPorts are the ports of syslog collectors by the scheme of their URL.
+var syslogDefaultPorts = map[string]string{
+	"tls": "6514",
+	"tcp": "601",
+	"udp": "514",
+}
+
+// ParseSyslogURL parses the URL of a remote syslog collector, e.g.
+// "tls://logs.example.com:6514". The schemes "tls", "tcp" and "udp" are
+// supported; the port defaults to the well-known port of the scheme.
+func ParseSyslogURL(rawURL string) (scheme string, address string, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+	port, ok := syslogDefaultPorts[u.Scheme]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported scheme %q: use tls, tcp or udp", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return "", "", fmt.Errorf("missing host")
+	}
+	if u.Path != "" || u.RawQuery != "" || u.User != nil {
+		return "", "", fmt.Errorf("only scheme, host and port can be set")
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	return u.Scheme, net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// DialSyslog connects to the remote syslog collector at rawURL using dialer.
+// Connections over TLS verify the certificate of the collector against the
+// system trust store. The returned flag reports whether messages have to be
+// framed by octet counting, as required by stream transports.
+func DialSyslog(rawURL string, dialer *net.Dialer) (net.Conn, bool, error) {
+	scheme, address, err := ParseSyslogURL(rawURL)
+	if err != nil {
+		return nil, false, err
+	}
+	switch scheme {
+	case "tls":
+		host, _, _ := net.SplitHostPort(address)
+		conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{ServerName: host})
+		return conn, true, err
+	case "tcp":
+		conn, err := dialer.Dial("tcp", address)
+		return conn, true, err
+	default:
+		conn, err := dialer.Dial("udp", address)
+		return conn, false, err
+	}
+}
+
+// syslogState is shared by all handlers derived from a single syslog handler.
+type syslogState struct {
+	mu       sync.Mutex
+	w        io.Writer
+	framed   bool
+	hostname string
+	appName  string
+	procID   int
+	// failed is set after the first failed write; later records are not forwarded.
+	failed bool
+}
+
+// SyslogHandler is a slog.Handler forwarding records to a remote syslog
+// collector as RFC 5424 messages, and passing them to the next handler.
+// Attributes of records are forwarded as structured data. A failure of the
+// collector is reported to the next handler once, and the forwarding stops.
+type SyslogHandler struct {
+	next   slog.Handler
+	state  *syslogState
+	attrs  []slog.Attr
+	prefix string
+}
+
+// NewSyslogHandler returns a handler passing records to next, and writing them
+// to w as messages of appName with process ID procID running on hostname. When
+// framed is true, messages are framed by octet counting (RFC 6587).
+func NewSyslogHandler(next slog.Handler, w io.Writer, framed bool, hostname, appName string, procID int) *SyslogHandler {
+	return &SyslogHandler{
+		next: next,
+		state: &syslogState{
+			w:        w,
+			framed:   framed,
+			hostname: hostname,
+			appName:  appName,
+			procID:   procID,
+		},
+	}
+}
+
+// Enabled implements slog.Handler.
+func (h *SyslogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *SyslogHandler) Handle(ctx context.Context, record slog.Record) error {
+	err := h.next.Handle(ctx, record)
+
+	attrs := h.attrs
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = appendSyslogAttr(attrs, h.prefix, attr)
+		return true
+	})
+	message := formatSyslogMessage(record, attrs, h.state.hostname, h.state.appName, h.state.procID)
+	if h.state.framed {
+		message = append([]byte(strconv.Itoa(len(message))+" "), message...)
+	}
+
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	if h.state.failed {
+		return err
+	}
+	if conn, ok := h.state.w.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		_ = conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+	}
+	if _, writeErr := h.state.w.Write(message); writeErr != nil {
+		h.state.failed = true
+		failure := slog.NewRecord(time.Now(), slog.LevelWarn, "Unable to forward logs to remote syslog", 0)
+		failure.AddAttrs(slog.String("err", writeErr.Error()))
+		_ = h.next.Handle(ctx, failure)
+	}
+	return err
+}
+
+// WithAttrs implements slog.Handler.
+func (h *SyslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	own := append([]slog.Attr{}, h.attrs...)
+	for _, attr := range attrs {
+		own = appendSyslogAttr(own, h.prefix, attr)
+	}
+	return &SyslogHandler{next: h.next.WithAttrs(attrs), state: h.state, attrs: own, prefix: h.prefix}
+}
+
+// WithGroup implements slog.Handler.
+func (h *SyslogHandler) WithGroup(name string) slog.Handler {
+	return &SyslogHandler{next: h.next.WithGroup(name), state: h.state, attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// appendSyslogAttr appends attr to attrs, flattening groups into keys joined by dots.
+func appendSyslogAttr(attrs []slog.Attr, prefix string, attr slog.Attr) []slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			attrs = appendSyslogAttr(attrs, prefix, member)
+		}
+		return attrs
+	}
+	if attr.Equal(slog.Attr{}) {
+		return attrs
+	}
+	return append(attrs, slog.Attr{Key: prefix + attr.Key, Value: attr.Value})
+}
+
+// syslogSeverity maps the level of a record to the severity of a syslog message.
+func syslogSeverity(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3
+	case level >= slog.LevelWarn:
+		return 4
+	case level >= slog.LevelInfo:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// formatSyslogMessage formats record as an RFC 5424 message without framing.
+func formatSyslogMessage(record slog.Record, attrs []slog.Attr, hostname, appName string, procID int) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 ", syslogFacility*8+syslogSeverity(record.Level))
+	if record.Time.IsZero() {
+		b.WriteString("-")
+	} else {
+		b.WriteString(record.Time.UTC().Format("2006-01-02T15:04:05.999999Z07:00"))
+	}
+	fmt.Fprintf(&b, " %s %s %d - ", syslogHeaderField(hostname, 255), syslogHeaderField(appName, 48), procID)
+
+	if len(attrs) == 0 {
+		b.WriteString("-")
+	} else {
+		b.WriteString("[" + syslogSDID)
+		for _, attr := range attrs {
+			fmt.Fprintf(&b, ` %s="%s"`, syslogParamName(attr.Key), syslogParamValue(attr.Value.String()))
+		}
+		b.WriteString("]")
+	}
+	if record.Message != "" {
+		b.WriteString(" " + record.Message)
+	}
+	return []byte(b.String())
+}
+
+// syslogHeaderField returns value usable as a header field of at most limit
+// printable ASCII characters, or "-" for an empty value.
+func syslogHeaderField(value string, limit int) string {
+	field := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+	if field == "" {
+		return "-"
+	}
+	if len(field) > limit {
+		field = field[:limit]
+	}
+	return field
+}
+
+// syslogParamName returns key usable as a name of a structured data parameter.
+func syslogParamName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+	if name == "" {
+		return "_"
+	}
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// syslogParamValue escapes the characters of value reserved in structured data.
+func syslogParamValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
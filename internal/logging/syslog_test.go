@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSyslogURL(t *testing.T) {
+	tests := []struct {
+		rawURL      string
+		wantScheme  string
+		wantAddress string
+		wantErr     bool
+	}{
+		{rawURL: "tls://logs.example.com:6514", wantScheme: "tls", wantAddress: "logs.example.com:6514"},
+		{rawURL: "tls://logs.example.com", wantScheme: "tls", wantAddress: "logs.example.com:6514"},
+		{rawURL: "tcp://192.0.2.1", wantScheme: "tcp", wantAddress: "192.0.2.1:601"},
+		{rawURL: "udp://[2001:db8::1]", wantScheme: "udp", wantAddress: "[2001:db8::1]:514"},
+		{rawURL: "https://logs.example.com", wantErr: true},
+		{rawURL: "tls://", wantErr: true},
+		{rawURL: "tls://logs.example.com/path", wantErr: true},
+		{rawURL: "logs.example.com:6514", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.rawURL, func(t *testing.T) {
+			scheme, address, err := ParseSyslogURL(test.rawURL)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got (%q, %q)", scheme, address)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if scheme != test.wantScheme || address != test.wantAddress {
+				t.Errorf("got (%q, %q), want (%q, %q)", scheme, address, test.wantScheme, test.wantAddress)
+			}
+		})
+	}
+}
+
+func TestSyslogHandler(t *testing.T) {
+	var local, remote bytes.Buffer
+	handler := NewSyslogHandler(slog.NewTextHandler(&local, nil), &remote, true, "node1.example.com", "rhc", 42)
+	logger := slog.New(handler).With("command", "connect")
+
+	record := slog.NewRecord(time.Date(2025, 1, 31, 2, 0, 0, 500000000, time.UTC), slog.LevelWarn, "Unable to reach \"broker\"", 0)
+	record.AddAttrs(slog.Group("rhsm", slog.String("server", "[a]\\b")))
+	if err := logger.Handler().Handle(t.Context(), record); err != nil {
+		t.Fatal(err)
+	}
+
+	message := `<28>1 2025-01-31T02:00:00.5Z node1.example.com rhc 42 - ` +
+		`[rhc@2312 command="connect" rhsm.server="[a\]\\b"] Unable to reach "broker"`
+	want := fmt.Sprintf("%d %s", len(message), message)
+	if got := remote.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if !strings.Contains(local.String(), "Unable to reach") {
+		t.Errorf("record was not passed to the next handler: %q", local.String())
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("connection reset")
+}
+
+func TestSyslogHandlerFailure(t *testing.T) {
+	var local bytes.Buffer
+	remote := &failingWriter{}
+	logger := slog.New(NewSyslogHandler(slog.NewTextHandler(&local, nil), remote, false, "", "rhc", 42))
+
+	logger.Info("first")
+	logger.Info("second")
+
+	if remote.writes != 1 {
+		t.Errorf("got %d writes, want 1", remote.writes)
+	}
+	if got := strings.Count(local.String(), "Unable to forward logs to remote syslog"); got != 1 {
+		t.Errorf("failure reported %d times, want 1:\n%s", got, local.String())
+	}
+	if !strings.Contains(local.String(), "msg=second") {
+		t.Errorf("records are not passed to the next handler after failure:\n%s", local.String())
+	}
+}
+
+func TestFormatSyslogMessage(t *testing.T) {
+	record := slog.NewRecord(time.Time{}, slog.LevelDebug, "", 0)
+	got := string(formatSyslogMessage(record, nil, "", "rhc", 1))
+	want := "<31>1 - - rhc 1 - -"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,59 @@
+package remotemanagement
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// YggdrasilClientIDPath is the client ID generated by yggdrasil, when it does
+// not authenticate with a certificate.
+var YggdrasilClientIDPath = "/var/lib/yggdrasil/client-id"
+
+// YggdrasilClientID returns the client ID yggdrasil identifies itself with to
+// the broker. yggdrasil uses the common name of the certificate it
+// authenticates with; without a certificate, it uses the client ID it has
+// generated. An empty string is returned, when yggdrasil has not generated
+// it yet.
+func YggdrasilClientID() (string, error) {
+	tree, err := toml.LoadFile(YggdrasilConfigPath)
+	switch {
+	case err == nil:
+		if certFile, _ := tree.Get("cert-file").(string); certFile != "" {
+			return certificateCommonName(certFile)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return "", fmt.Errorf("cannot read configuration of yggdrasil: %w", err)
+	}
+
+	data, err := os.ReadFile(YggdrasilClientIDPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot read client ID of yggdrasil: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// certificateCommonName returns the common name of the PEM certificate at path.
+func certificateCommonName(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read certificate of yggdrasil: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return "", fmt.Errorf("cannot parse certificate of yggdrasil %s: no PEM data", path)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse certificate of yggdrasil %s: %w", path, err)
+	}
+	return cert.Subject.CommonName, nil
+}
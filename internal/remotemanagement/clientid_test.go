@@ -0,0 +1,62 @@
+package remotemanagement
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestYggdrasilClientID(t *testing.T) {
+	dir := t.TempDir()
+	oldConfigPath, oldClientIDPath := YggdrasilConfigPath, YggdrasilClientIDPath
+	YggdrasilConfigPath = filepath.Join(dir, "config.toml")
+	YggdrasilClientIDPath = filepath.Join(dir, "client-id")
+	t.Cleanup(func() { YggdrasilConfigPath, YggdrasilClientIDPath = oldConfigPath, oldClientIDPath })
+
+	// Not generated yet
+	got, err := YggdrasilClientID()
+	if err != nil || got != "" {
+		t.Errorf("got %q, %v, want no client ID", got, err)
+	}
+
+	// Generated by yggdrasil
+	if err = os.WriteFile(YggdrasilClientIDPath, []byte("4b7e9c4c-generated\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = YggdrasilClientID(); err != nil || got != "4b7e9c4c-generated" {
+		t.Errorf("got %q, %v, want generated client ID", got, err)
+	}
+
+	// Common name of the certificate
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "0a1b2c3d-consumer"},
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath := filepath.Join(dir, "cert.pem")
+	if err = os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(YggdrasilConfigPath, []byte("cert-file = \""+certPath+"\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = YggdrasilClientID(); err != nil || got != "0a1b2c3d-consumer" {
+		t.Errorf("got %q, %v, want common name of certificate", got, err)
+	}
+}
@@ -0,0 +1,119 @@
+package remotemanagement
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/systemd"
+)
+
+// diagnosticJournalLines is the number of journal lines in UnitDiagnostics.
+const diagnosticJournalLines = 10
+
+// exitCodes names the values of the ExecMainCode property of services.
+var exitCodes = map[int32]string{1: "exited", 2: "killed", 3: "dumped"}
+
+// UnitDiagnostics describes the state of a systemd unit that failed to activate.
+type UnitDiagnostics struct {
+	Unit        string `json:"unit"`
+	ActiveState string `json:"active_state"`
+	SubState    string `json:"sub_state"`
+	// Result is the result of the last run of the service (e.g. "exit-code").
+	Result string `json:"result,omitempty"`
+	// ExitCode tells whether the main process "exited", or it was "killed" or
+	// "dumped" by a signal. ExitStatus is the exit status, or the signal number.
+	ExitCode   string   `json:"exit_code,omitempty"`
+	ExitStatus int      `json:"exit_status"`
+	Journal    []string `json:"journal,omitempty"`
+}
+
+// Summary returns a one-line description of the state of the unit.
+func (d *UnitDiagnostics) Summary() string {
+	summary := fmt.Sprintf("%s is %s (%s)", d.Unit, d.ActiveState, d.SubState)
+	if d.Result != "" && d.Result != "success" {
+		summary += fmt.Sprintf(", result: %s", d.Result)
+	}
+	switch d.ExitCode {
+	case "exited":
+		summary += fmt.Sprintf(", exit status: %d", d.ExitStatus)
+	case "killed", "dumped":
+		summary += fmt.Sprintf(", %s by signal %d", d.ExitCode, d.ExitStatus)
+	}
+	if len(d.Journal) > 0 {
+		summary += fmt.Sprintf(", last log: %s", d.Journal[len(d.Journal)-1])
+	}
+	return summary
+}
+
+// ActivationError is returned when a unit cannot be activated. Diagnostics of
+// the unit are included, when they are available.
+type ActivationError struct {
+	Err         error
+	Diagnostics *UnitDiagnostics
+}
+
+func (e *ActivationError) Error() string {
+	if e.Diagnostics == nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v: %s", e.Err, e.Diagnostics.Summary())
+}
+
+func (e *ActivationError) Unwrap() error {
+	return e.Err
+}
+
+// activationError returns err wrapped in ActivationError with diagnostics of
+// the named unit, which has been activated since the time since.
+func activationError(conn *systemd.Conn, name string, since time.Time, err error) error {
+	diagnostics, diagErr := diagnoseUnit(conn, name, since)
+	if diagErr != nil {
+		slog.Debug("Unable to diagnose unit", "unit", name, "err", diagErr)
+	}
+	return &ActivationError{Err: err, Diagnostics: diagnostics}
+}
+
+// diagnoseUnit collects the state of the named unit and its journal lines
+// logged since the time since. Diagnostics collected before an error occurred
+// are returned together with the error.
+func diagnoseUnit(conn *systemd.Conn, name string, since time.Time) (*UnitDiagnostics, error) {
+	props, err := conn.GetUnitProperties(name)
+	if err != nil {
+		return nil, err
+	}
+	diagnostics := &UnitDiagnostics{Unit: name}
+	diagnostics.ActiveState, _ = props["ActiveState"].(string)
+	diagnostics.SubState, _ = props["SubState"].(string)
+
+	serviceProps, err := conn.GetServiceProperties(name)
+	if err != nil {
+		return diagnostics, err
+	}
+	diagnostics.Result, _ = serviceProps["Result"].(string)
+	if code, ok := serviceProps["ExecMainCode"].(int32); ok {
+		diagnostics.ExitCode = exitCodes[code]
+	}
+	if status, ok := serviceProps["ExecMainStatus"].(int32); ok {
+		diagnostics.ExitStatus = int(status)
+	}
+
+	entries, err := systemd.GetJournalEntries([]string{name}, since, time.Now())
+	if err != nil {
+		return diagnostics, err
+	}
+	diagnostics.Journal = journalTail(entries, diagnosticJournalLines)
+	return diagnostics, nil
+}
+
+// journalTail returns the last n entries formatted as lines.
+func journalTail(entries []systemd.JournalEntry, n int) []string {
+	if len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, fmt.Sprintf("%s %s", entry.Time.Local().Format(time.TimeOnly), entry.Message))
+	}
+	return lines
+}
@@ -0,0 +1,194 @@
+package remotemanagement
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+
+	"github.com/redhatinsights/rhc/internal/subman"
+	"github.com/redhatinsights/rhc/internal/systemd"
+)
+
+var (
+	// YggdrasilConfigPath is the configuration file of yggdrasil.
+	YggdrasilConfigPath = "/etc/yggdrasil/config.toml"
+	// runtimeUnitDir is the directory with runtime unit files and drop-ins.
+	runtimeUnitDir = "/run/systemd/system"
+)
+
+// managedHeader starts files written by rhc, which are not local overrides.
+const managedHeader = "# This file is managed by rhc"
+
+// Drift is a local override of the yggdrasil unit or configuration, which
+// commonly breaks remote management.
+type Drift struct {
+	Path    string `json:"path"`
+	Setting string `json:"setting"`
+	Value   string `json:"value,omitempty"`
+	Problem string `json:"problem"`
+}
+
+// unitSetting is an assignment in a unit file.
+type unitSetting struct {
+	Section string
+	Key     string
+	Value   string
+}
+
+// DetectYggdrasilDrift compares the yggdrasil unit, its drop-ins and the
+// configuration of yggdrasil against the settings expected by rhc.
+func DetectYggdrasilDrift() ([]Drift, error) {
+	var drifts []Drift
+
+	for _, dir := range []string{systemd.UnitDir, runtimeUnitDir} {
+		path := filepath.Join(dir, "yggdrasil.service")
+		if _, err := os.Stat(path); err == nil {
+			drifts = append(drifts, Drift{
+				Path:    path,
+				Setting: "unit",
+				Problem: "local copy of the unit replaces the unit installed by the yggdrasil package",
+			})
+		}
+
+		paths, _ := filepath.Glob(filepath.Join(dir, "yggdrasil.service.d", "*.conf"))
+		sort.Strings(paths)
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read drop-in: %w", err)
+			}
+			if strings.HasPrefix(string(data), managedHeader) {
+				continue
+			}
+			drifts = append(drifts, unitDrifts(path, parseUnitSettings(string(data)))...)
+		}
+	}
+
+	configDrifts, err := configDrifts(YggdrasilConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	return append(drifts, configDrifts...), nil
+}
+
+// parseUnitSettings parses assignments of a unit file. Comments and empty
+// lines are skipped; continuation lines are joined.
+func parseUnitSettings(content string) []unitSetting {
+	var settings []unitSetting
+	section := ""
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	var line string
+	for scanner.Scan() {
+		line += strings.TrimSpace(scanner.Text())
+		if strings.HasSuffix(line, `\`) {
+			line = strings.TrimSuffix(line, `\`) + " "
+			continue
+		}
+		current := line
+		line = ""
+		switch {
+		case current == "" || strings.HasPrefix(current, "#") || strings.HasPrefix(current, ";"):
+		case strings.HasPrefix(current, "[") && strings.HasSuffix(current, "]"):
+			section = current[1 : len(current)-1]
+		default:
+			key, value, found := strings.Cut(current, "=")
+			if found {
+				settings = append(settings, unitSetting{
+					Section: section,
+					Key:     strings.TrimSpace(key),
+					Value:   strings.TrimSpace(value),
+				})
+			}
+		}
+	}
+	return settings
+}
+
+// unitDrifts returns the settings of the drop-in at path, which commonly
+// break remote management.
+func unitDrifts(path string, settings []unitSetting) []Drift {
+	var drifts []Drift
+	for _, setting := range settings {
+		if setting.Section != "Service" {
+			continue
+		}
+		problem := ""
+		switch setting.Key {
+		case "User", "Group", "DynamicUser":
+			problem = "yggdrasil runs under a different identity and may not read the identity certificate"
+		case "ExecStart":
+			if setting.Value != "" {
+				problem = "yggdrasil is started with a different command line, which may override its configuration"
+			}
+		case "Environment", "EnvironmentFile":
+			if strings.Contains(setting.Value, "YGG_") {
+				problem = "yggdrasil configuration is overridden by environment variables"
+			}
+		case "ProtectSystem", "ReadOnlyPaths", "InaccessiblePaths":
+			problem = "sandboxing may prevent yggdrasil from reading the identity certificate"
+		}
+		if problem != "" {
+			drifts = append(drifts, Drift{
+				Path:    path,
+				Setting: setting.Section + "." + setting.Key,
+				Value:   setting.Value,
+				Problem: problem,
+			})
+		}
+	}
+	return drifts
+}
+
+// configDrifts returns the settings of the yggdrasil configuration file at
+// path, which differ from the settings expected by rhc.
+func configDrifts(path string) ([]Drift, error) {
+	tree, err := toml.LoadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Drift{{Path: path, Setting: "file", Problem: "configuration file of yggdrasil is missing"}}, nil
+	}
+	if err != nil {
+		return []Drift{{Path: path, Setting: "file", Problem: fmt.Sprintf("configuration file of yggdrasil is invalid: %v", err)}}, nil
+	}
+
+	var drifts []Drift
+	expected := []struct {
+		key  string
+		want string
+	}{
+		{key: "cert-file", want: subman.ConsumerCertPath},
+		{key: "key-file", want: subman.ConsumerKeyPath},
+	}
+	for _, e := range expected {
+		value, _ := tree.Get(e.key).(string)
+		if value != e.want {
+			drifts = append(drifts, Drift{
+				Path:    path,
+				Setting: e.key,
+				Value:   value,
+				Problem: fmt.Sprintf("yggdrasil does not use the identity of the system (%s)", e.want),
+			})
+		}
+	}
+	// The broker is a string, or an array of strings
+	serverSet := false
+	switch server := tree.Get("server").(type) {
+	case string:
+		serverSet = server != ""
+	case []interface{}:
+		serverSet = len(server) > 0
+	}
+	if !serverSet {
+		drifts = append(drifts, Drift{
+			Path:    path,
+			Setting: "server",
+			Problem: "broker of yggdrasil is not set",
+		})
+	}
+	return drifts, nil
+}
@@ -0,0 +1,88 @@
+package remotemanagement
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhatinsights/rhc/internal/systemd"
+)
+
+func TestParseUnitSettings(t *testing.T) {
+	content := `# comment
+[Service]
+User=nobody
+ExecStart=
+ExecStart=/usr/sbin/yggd \
+  --server wss://broker.example.com
+
+; comment
+[Install]
+Alias=rhcd.service
+`
+	want := []unitSetting{
+		{Section: "Service", Key: "User", Value: "nobody"},
+		{Section: "Service", Key: "ExecStart", Value: ""},
+		{Section: "Service", Key: "ExecStart", Value: "/usr/sbin/yggd  --server wss://broker.example.com"},
+		{Section: "Install", Key: "Alias", Value: "rhcd.service"},
+	}
+	got := parseUnitSettings(content)
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
+
+func TestDetectYggdrasilDrift(t *testing.T) {
+	dir := t.TempDir()
+	oldUnitDir, oldRuntimeUnitDir, oldConfigPath := systemd.UnitDir, runtimeUnitDir, YggdrasilConfigPath
+	systemd.UnitDir = filepath.Join(dir, "etc")
+	runtimeUnitDir = filepath.Join(dir, "run")
+	YggdrasilConfigPath = filepath.Join(dir, "config.toml")
+	t.Cleanup(func() {
+		systemd.UnitDir, runtimeUnitDir, YggdrasilConfigPath = oldUnitDir, oldRuntimeUnitDir, oldConfigPath
+	})
+
+	files := map[string]string{
+		filepath.Join(systemd.UnitDir, "yggdrasil.service.d", "override.conf"):  "[Service]\nUser=yggdrasil\nRestart=always\n",
+		filepath.Join(systemd.UnitDir, "yggdrasil.service.d", "rhc-proxy.conf"): "# This file is managed by rhc; changes will be overwritten.\n[Service]\nEnvironment=\"YGG_X=1\"\n",
+		filepath.Join(runtimeUnitDir, "yggdrasil.service"):                      "[Service]\nExecStart=/usr/sbin/yggd\n",
+		YggdrasilConfigPath: "server = [\"wss://connect.cloud.redhat.com:443\"]\ncert-file = \"/etc/pki/consumer/cert.pem\"\nkey-file = \"/root/key.pem\"\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := DetectYggdrasilDrift()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Drift{
+		{
+			Path:    filepath.Join(systemd.UnitDir, "yggdrasil.service.d", "override.conf"),
+			Setting: "Service.User",
+			Value:   "yggdrasil",
+			Problem: "yggdrasil runs under a different identity and may not read the identity certificate",
+		},
+		{
+			Path:    filepath.Join(runtimeUnitDir, "yggdrasil.service"),
+			Setting: "unit",
+			Problem: "local copy of the unit replaces the unit installed by the yggdrasil package",
+		},
+		{
+			Path:    YggdrasilConfigPath,
+			Setting: "key-file",
+			Value:   "/root/key.pem",
+			Problem: "yggdrasil does not use the identity of the system (/etc/pki/consumer/key.pem)",
+		},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
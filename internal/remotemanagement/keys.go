@@ -0,0 +1,170 @@
+package remotemanagement
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/cleanup"
+	"github.com/redhatinsights/rhc/internal/logging"
+	"github.com/redhatinsights/rhc/internal/util"
+)
+
+var (
+	// PlaybookKeyringPath is the keyring of GPG keys used by rhc-worker-playbook
+	// to verify signatures of playbooks before running them.
+	PlaybookKeyringPath = "/etc/insights-client/redhattools.pub.gpg"
+	// PlaybookKeySourceDir is the directory of GPG keys shipped with rhc. They are
+	// installed into PlaybookKeyringPath, when the keyring is missing.
+	PlaybookKeySourceDir = "/usr/share/rhc/playbook-keys"
+)
+
+// PlaybookKey is a GPG key trusted for verification of playbooks.
+type PlaybookKey struct {
+	Fingerprint string     `json:"fingerprint"`
+	UserID      string     `json:"user_id,omitempty"`
+	Expires     *time.Time `json:"expires,omitempty"`
+}
+
+// InstallPlaybookKeys ensures the keyring used for verification of playbooks
+// exists, and verifies that it holds usable keys. When the keyring is missing,
+// it is assembled from the keys in PlaybookKeySourceDir. The verified keys are
+// returned together with a flag reporting whether the keyring was installed.
+func InstallPlaybookKeys() ([]PlaybookKey, bool, error) {
+	installed := false
+	if _, err := os.Stat(PlaybookKeyringPath); errors.Is(err, os.ErrNotExist) {
+		if err := installPlaybookKeyring(); err != nil {
+			return nil, false, err
+		}
+		installed = true
+	} else if err != nil {
+		return nil, false, fmt.Errorf("cannot check playbook verification keys: %w", err)
+	}
+
+	keys, err := VerifyPlaybookKeys()
+	return keys, installed, err
+}
+
+// installPlaybookKeyring concatenates the keys in PlaybookKeySourceDir into
+// PlaybookKeyringPath.
+func installPlaybookKeyring() error {
+	paths, _ := filepath.Glob(filepath.Join(PlaybookKeySourceDir, "*.gpg"))
+	if len(paths) == 0 {
+		return fmt.Errorf("playbook verification keys are missing: %s does not exist", PlaybookKeyringPath)
+	}
+
+	var keyring bytes.Buffer
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("cannot read playbook verification key: %w", err)
+		}
+		keyring.Write(data)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(PlaybookKeyringPath), 0755); err != nil {
+		return fmt.Errorf("cannot install playbook verification keys: %w", err)
+	}
+	if err := util.WriteFileAtomic(PlaybookKeyringPath, keyring.Bytes(), 0644); err != nil {
+		return fmt.Errorf("cannot install playbook verification keys: %w", err)
+	}
+	slog.Debug("Installed playbook verification keys", "path", PlaybookKeyringPath, "sources", paths)
+	return nil
+}
+
+// VerifyPlaybookKeys lists the keys of the keyring used for verification of
+// playbooks. An error is returned when the keyring cannot be read, or when it
+// does not hold any key, which is neither expired nor revoked.
+func VerifyPlaybookKeys() ([]PlaybookKey, error) {
+	// Use an empty home directory, so the keyring of the user is not touched
+	home, err := os.MkdirTemp("", "rhc-gnupg-")
+	if err != nil {
+		return nil, fmt.Errorf("cannot verify playbook verification keys: %w", err)
+	}
+	release := cleanup.Push("temporary directory "+home, cleanup.Remove(home))
+	defer func() { _ = release() }()
+
+	var stdout, stderr bytes.Buffer
+	slog.Debug(fmt.Sprintf("Executing /usr/bin/gpg --show-keys %s", PlaybookKeyringPath))
+	cmd := exec.Command(
+		"/usr/bin/gpg", "--homedir", home, "--batch", "--with-colons", "--show-keys", PlaybookKeyringPath,
+	)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := logging.CommandStart(cmd)
+	err = cmd.Run()
+	logging.Command(cmd, start, err)
+	if err != nil {
+		if stderr.Len() > 0 {
+			return nil, fmt.Errorf("cannot read playbook verification keys: %s", strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("cannot read playbook verification keys: %w", err)
+	}
+
+	keys, err := parseGPGKeys(stdout.String(), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no usable playbook verification key found in %s", PlaybookKeyringPath)
+	}
+	return keys, nil
+}
+
+// parseGPGKeys parses the primary keys listed by 'gpg --with-colons'. Keys that
+// are expired or revoked at the time now are left out.
+func parseGPGKeys(output string, now time.Time) ([]PlaybookKey, error) {
+	var keys []PlaybookKey
+	var current *PlaybookKey
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, ":")
+		switch fields[0] {
+		case "pub":
+			current = nil
+			if len(fields) < 7 {
+				return nil, fmt.Errorf("cannot parse playbook verification key: %q", line)
+			}
+			// The second field holds the validity of the key
+			if fields[1] == "e" || fields[1] == "r" {
+				continue
+			}
+			key := PlaybookKey{}
+			if fields[6] != "" {
+				seconds, err := strconv.ParseInt(fields[6], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("cannot parse expiration of playbook verification key: %w", err)
+				}
+				expires := time.Unix(seconds, 0).UTC()
+				if expires.Before(now) {
+					continue
+				}
+				key.Expires = &expires
+			}
+			keys = append(keys, key)
+			current = &keys[len(keys)-1]
+		case "fpr":
+			// Only the first fingerprint after the primary key belongs to it;
+			// the following ones belong to subkeys
+			if current != nil && current.Fingerprint == "" && len(fields) > 9 {
+				current.Fingerprint = fields[9]
+			}
+		case "uid":
+			if current != nil && current.UserID == "" && len(fields) > 9 {
+				current.UserID = fields[9]
+			}
+		case "sub":
+			if current != nil && current.Fingerprint == "" {
+				return nil, fmt.Errorf("cannot parse playbook verification key: missing fingerprint")
+			}
+			current = nil
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,74 @@
+package remotemanagement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseGPGKeys(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	expires := time.Unix(1893456000, 0).UTC()
+
+	tests := []struct {
+		description string
+		output      string
+		want        []PlaybookKey
+		wantError   bool
+	}{
+		{
+			description: "primary key with subkey",
+			output: `pub:-:4096:1:199E2F91FD431D51:1256212795:::-:::scSC::::::23::0:
+fpr:::::::::567E347AD0044ADE55BA8A5F199E2F91FD431D51:
+uid:-::::1256212795::DC1A1C40ABAC0A6EBD82AA8B0ED7E9E3C3D1C8D7::Red Hat, Inc. (release key 2) <security@redhat.com>::::::::::0:
+sub:-:4096:1:5326810137017186:1256212795:::::e::::::23:
+fpr:::::::::7E4624258C406535D56D6F135326810137017186:
+`,
+			want: []PlaybookKey{
+				{
+					Fingerprint: "567E347AD0044ADE55BA8A5F199E2F91FD431D51",
+					UserID:      "Red Hat, Inc. (release key 2) <security@redhat.com>",
+				},
+			},
+		},
+		{
+			description: "expired and revoked keys are left out",
+			output: `pub:e:4096:1:AAAAAAAAAAAAAAAA:1256212795:1300000000::-:::scSC::::::23::0:
+fpr:::::::::AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA:
+pub:r:4096:1:BBBBBBBBBBBBBBBB:1256212795:::-:::scSC::::::23::0:
+fpr:::::::::BBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBB:
+pub:-:4096:1:CCCCCCCCCCCCCCCC:1256212795:1700000000::-:::scSC::::::23::0:
+fpr:::::::::CCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCC:
+pub:-:4096:1:DDDDDDDDDDDDDDDD:1256212795:1893456000::-:::scSC::::::23::0:
+fpr:::::::::DDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDD:
+`,
+			want: []PlaybookKey{
+				{Fingerprint: "DDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDD", Expires: &expires},
+			},
+		},
+		{
+			description: "invalid expiration",
+			output:      "pub:-:4096:1:AAAAAAAAAAAAAAAA:1256212795:never::-:::scSC::::::23::0:\n",
+			wantError:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := parseGPGKeys(test.output, now)
+			if test.wantError {
+				if err == nil {
+					t.Errorf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
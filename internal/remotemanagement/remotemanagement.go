@@ -2,48 +2,174 @@ package remotemanagement
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"reflect"
+	"regexp"
+	"time"
 
 	"github.com/redhatinsights/rhc/internal/systemd"
 )
 
+// HealthTimeout bounds the wait for yggdrasil to connect to the broker, after
+// yggdrasil.service has been started.
+var HealthTimeout = 30 * time.Second
+
+// healthPollInterval is the period of checks of the health of yggdrasil.
+const healthPollInterval = time.Second
+
+// ErrBrokerUnverified is matched by the error of ActivateServices, when
+// yggdrasil.service keeps running, but yggdrasil does not log its connection to
+// the broker within HealthTimeout. The services are activated then; yggdrasil
+// may still connect, e.g. on a slow network, so the error is a warning.
+var ErrBrokerUnverified = errors.New("connection of yggdrasil to the broker is not verified")
+
+// brokerConnectedPattern matches the message yggdrasil logs, when it has
+// connected to the broker.
+var brokerConnectedPattern = regexp.MustCompile(`(?i)(^|[^a-z])connected to (the )?(mqtt )?broker`)
+
 // ActivateServices tries to enable and start the rhc-canonical-facts.timer,
 // rhc-canonical-facts.service and yggdrasil.service (in this order).
-// Error is returned as soon as one of the calls to systemd fails.
-func ActivateServices() error {
-	conn, err := systemd.NewConnectionContext(context.Background(), systemd.ConnectionTypeSystem)
+// Error is returned as soon as one of the calls to systemd fails, or when ctx
+// is done before yggdrasil connects to the broker. An error matching
+// ErrBrokerUnverified is returned, when the connection is not logged in time.
+func ActivateServices(ctx context.Context) error {
+	conn, err := systemd.NewConnectionContext(ctx, systemd.ConnectionTypeSystem)
 	if err != nil {
-		return fmt.Errorf("cannot connect to systemd: %v", err)
+		return fmt.Errorf("cannot connect to systemd: %w", err)
 	}
 	defer conn.Close()
 
+	started := time.Now()
 	slog.Debug("Enabling rhc-canonical-facts.timer")
 	if err := conn.EnableUnit("rhc-canonical-facts.timer", true, false); err != nil {
-		return fmt.Errorf("cannot enable rhc-canonical-facts.timer: %v", err)
+		return fmt.Errorf("cannot enable rhc-canonical-facts.timer: %w", err)
 	}
 
 	// Start the canonical-facts service immediately, so the facts get generated
 	// and written out before yggdrasil.service starts.
 	slog.Debug("Starting rhc-canonical-facts.service")
 	if err := conn.StartUnit("rhc-canonical-facts.service", false); err != nil {
-		return fmt.Errorf("cannot start rhc-canonical-facts.service: %v", err)
+		return activationError(conn, "rhc-canonical-facts.service", started,
+			fmt.Errorf("cannot start rhc-canonical-facts.service: %w", err))
 	}
 
 	slog.Debug("Enabling yggdrasil.service")
+	started = time.Now()
 	if err := conn.EnableUnit("yggdrasil.service", true, false); err != nil {
-		return fmt.Errorf("cannot enable yggdrasil.service: %v", err)
+		return activationError(conn, "yggdrasil.service", started,
+			fmt.Errorf("cannot enable yggdrasil.service: %w", err))
 	}
 
 	slog.Debug("Reloading systemd")
 	if err := conn.Reload(); err != nil {
-		return fmt.Errorf("cannot reload systemd: %v", err)
+		return fmt.Errorf("cannot reload systemd: %w", err)
+	}
+
+	err = waitForYggdrasilHealth(ctx, conn, started, HealthTimeout)
+	if err != nil && !errors.Is(err, ErrBrokerUnverified) {
+		return activationError(conn, "yggdrasil.service", started, err)
+	}
+	return err
+}
+
+// waitForYggdrasilHealth waits until yggdrasil, started at the time started,
+// reports connection to the broker. Error is returned, when yggdrasil.service
+// stops running or is restarted by systemd after a crash, or when the connection is not reported before ctx is done;
+// when it is not reported within timeout, the error matches ErrBrokerUnverified.
+func waitForYggdrasilHealth(ctx context.Context, conn *systemd.Conn, started time.Time, timeout time.Duration) error {
+	slog.Debug("Waiting for yggdrasil to connect to the broker", "timeout", timeout)
+	deadline := time.Now().Add(timeout)
+	initialRestarts := -1
+	for {
+		state, err := getServiceState(conn, "yggdrasil.service")
+		if err != nil {
+			return err
+		}
+		if initialRestarts < 0 {
+			initialRestarts = state.restarts
+		}
+		if err = state.check(initialRestarts); err != nil {
+			return err
+		}
+
+		entries, err := systemd.GetJournalEntries([]string{"yggdrasil.service"}, started, time.Now())
+		if err != nil {
+			// The connection cannot be verified without the journal; the
+			// unit running is the best evidence available
+			slog.Warn("Unable to verify connection of yggdrasil to the broker", "err", err)
+			return nil
+		}
+		if brokerConnected(entries, started) {
+			slog.Debug("yggdrasil connected to the broker")
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: yggdrasil did not report it within %v", ErrBrokerUnverified, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("yggdrasil did not connect to the broker: %w", ctx.Err())
+		case <-time.After(healthPollInterval):
+		}
+	}
+}
+
+// serviceState is the state of a service polled while waiting for its health.
+type serviceState struct {
+	name        string
+	activeState string
+	subState    string
+	// restarts is the number of automatic restarts of the service (NRestarts).
+	restarts int
+}
+
+// getServiceState returns the state of the service called name.
+func getServiceState(conn *systemd.Conn, name string) (*serviceState, error) {
+	props, err := conn.GetUnitProperties(name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get state of %s: %w", name, err)
+	}
+	serviceProps, err := conn.GetServiceProperties(name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get state of %s: %w", name, err)
+	}
+	state := &serviceState{name: name}
+	state.activeState, _ = props["ActiveState"].(string)
+	state.subState, _ = props["SubState"].(string)
+	if restarts, ok := serviceProps["NRestarts"].(uint32); ok {
+		state.restarts = int(restarts)
 	}
+	return state, nil
+}
 
+// check returns an error, when the service is not running, or when it crashed
+// and is restarted by systemd: it waits for the restart ("auto-restart"), or it
+// was restarted more times than initialRestarts. A crash-looping service is
+// "activating" most of the time, so it has to be told apart from a starting one.
+func (s *serviceState) check(initialRestarts int) error {
+	switch {
+	case s.subState == "auto-restart" || s.restarts > initialRestarts:
+		return fmt.Errorf("%s crashed and is restarted shortly after start", s.name)
+	case s.activeState != "active" && s.activeState != "activating" && s.activeState != "reloading":
+		return fmt.Errorf("%s is %s shortly after start", s.name, s.activeState)
+	}
 	return nil
 }
 
+// brokerConnected reports whether entries logged since started report
+// connection of yggdrasil to the broker.
+func brokerConnected(entries []systemd.JournalEntry, started time.Time) bool {
+	for _, entry := range entries {
+		if !entry.Time.Before(started) && brokerConnectedPattern.MatchString(entry.Message) {
+			return true
+		}
+	}
+	return false
+}
+
 // UnitState holds the state of a systemd unit as reported by systemd.
 type UnitState struct {
 	// ActiveState is the systemd ActiveState property value (e.g. "active", "inactive").
@@ -59,7 +185,7 @@ type UnitState struct {
 func GetUnitState(name string) (*UnitState, error) {
 	conn, err := systemd.NewConnectionContext(context.Background(), systemd.ConnectionTypeSystem)
 	if err != nil {
-		return nil, fmt.Errorf("cannot connect to systemd: %v", err)
+		return nil, fmt.Errorf("cannot connect to systemd: %w", err)
 	}
 	defer conn.Close()
 
@@ -96,14 +222,14 @@ func GetUnitState(name string) (*UnitState, error) {
 func AssertYggdrasilServiceState(wantedState string) (bool, error) {
 	conn, err := systemd.NewConnectionContext(context.Background(), systemd.ConnectionTypeSystem)
 	if err != nil {
-		return false, fmt.Errorf("cannot connect to systemd: %v", err)
+		return false, fmt.Errorf("cannot connect to systemd: %w", err)
 	}
 	defer conn.Close()
 
 	slog.Debug("retrieving yggdrasil.service unit state")
 	state, err := conn.GetUnitState("yggdrasil.service")
 	if err != nil {
-		return false, fmt.Errorf("cannot get unit state: %v", err)
+		return false, fmt.Errorf("cannot get unit state: %w", err)
 	}
 	if state == wantedState {
 		return true, nil
@@ -114,27 +240,28 @@ func AssertYggdrasilServiceState(wantedState string) (bool, error) {
 
 // DeactivateServices tries to stop and disable the rhc-canonical-facts.timer,
 // rhc-canonical-facts.service and yggdrasil.service (in this order).
-// Error is returned as soon as one of the calls to systemd fails.
-func DeactivateServices() error {
-	conn, err := systemd.NewConnectionContext(context.Background(), systemd.ConnectionTypeSystem)
+// Error is returned as soon as one of the calls to systemd fails, or when ctx
+// is done.
+func DeactivateServices(ctx context.Context) error {
+	conn, err := systemd.NewConnectionContext(ctx, systemd.ConnectionTypeSystem)
 	if err != nil {
-		return fmt.Errorf("cannot connect to systemd: %v", err)
+		return fmt.Errorf("cannot connect to systemd: %w", err)
 	}
 	defer conn.Close()
 
 	slog.Debug("Disabling rhc-canonical-facts.service")
 	if err := conn.DisableUnit("rhc-canonical-facts.timer", true, false); err != nil {
-		return fmt.Errorf("cannot disable rhc-canonical-facts.timer: %v", err)
+		return fmt.Errorf("cannot disable rhc-canonical-facts.timer: %w", err)
 	}
 
 	slog.Debug("Disabling yggdrasil.service")
 	if err := conn.DisableUnit("yggdrasil.service", true, false); err != nil {
-		return fmt.Errorf("cannot disable yggdrasil.service: %v", err)
+		return fmt.Errorf("cannot disable yggdrasil.service: %w", err)
 	}
 
 	slog.Debug("Reloading systemd")
 	if err := conn.Reload(); err != nil {
-		return fmt.Errorf("cannot reload systemd: %v", err)
+		return fmt.Errorf("cannot reload systemd: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,135 @@
+package remotemanagement
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/systemd"
+)
+
+func TestBrokerConnected(t *testing.T) {
+	started := time.Date(2026, 1, 1, 10, 0, 0, 500000000, time.UTC)
+
+	tests := []struct {
+		description string
+		entries     []systemd.JournalEntry
+		want        bool
+	}{
+		{
+			description: "connected",
+			entries: []systemd.JournalEntry{
+				{Time: started.Add(time.Second), Message: "starting yggd version 0.4.5"},
+				{Time: started.Add(2 * time.Second), Message: "connected to broker: mqtts://mqtt.example.com:443"},
+			},
+			want: true,
+		},
+		{
+			description: "connected before start",
+			entries: []systemd.JournalEntry{
+				{Time: started.Add(-time.Millisecond), Message: "connected to broker"},
+			},
+		},
+		{
+			description: "disconnected",
+			entries: []systemd.JournalEntry{
+				{Time: started.Add(time.Second), Message: "disconnected from broker"},
+				{Time: started.Add(time.Second), Message: "cannot connect to broker: connection refused"},
+			},
+		},
+		{
+			description: "no entries",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if got := brokerConnected(test.entries, started); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestServiceStateCheck(t *testing.T) {
+	tests := []struct {
+		description     string
+		state           serviceState
+		initialRestarts int
+		wantError       bool
+	}{
+		{description: "running", state: serviceState{activeState: "active", subState: "running"}},
+		{description: "starting", state: serviceState{activeState: "activating", subState: "start"}},
+		{description: "failed", state: serviceState{activeState: "failed", subState: "failed"}, wantError: true},
+		{description: "waiting for restart", state: serviceState{activeState: "activating", subState: "auto-restart", restarts: 1}, initialRestarts: 1, wantError: true},
+		{description: "restarted", state: serviceState{activeState: "active", subState: "running", restarts: 2}, initialRestarts: 1, wantError: true},
+		{description: "restarted before start", state: serviceState{activeState: "active", subState: "running", restarts: 1}, initialRestarts: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			test.state.name = "yggdrasil.service"
+			if err := test.state.check(test.initialRestarts); (err != nil) != test.wantError {
+				t.Errorf("got error %v, want error: %v", err, test.wantError)
+			}
+		})
+	}
+}
+
+func TestUnitDiagnosticsSummary(t *testing.T) {
+	tests := []struct {
+		description string
+		diagnostics UnitDiagnostics
+		want        string
+	}{
+		{
+			description: "exited",
+			diagnostics: UnitDiagnostics{
+				Unit: "yggdrasil.service", ActiveState: "failed", SubState: "failed",
+				Result: "exit-code", ExitCode: "exited", ExitStatus: 1,
+				Journal: []string{"10:00:01 starting yggd", "10:00:02 cannot load certificate: no such file"},
+			},
+			want: "yggdrasil.service is failed (failed), result: exit-code, exit status: 1, last log: 10:00:02 cannot load certificate: no such file",
+		},
+		{
+			description: "killed",
+			diagnostics: UnitDiagnostics{
+				Unit: "yggdrasil.service", ActiveState: "activating", SubState: "auto-restart",
+				Result: "signal", ExitCode: "killed", ExitStatus: 9,
+			},
+			want: "yggdrasil.service is activating (auto-restart), result: signal, killed by signal 9",
+		},
+		{
+			description: "running",
+			diagnostics: UnitDiagnostics{
+				Unit: "yggdrasil.service", ActiveState: "active", SubState: "running", Result: "success",
+			},
+			want: "yggdrasil.service is active (running)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if got := test.diagnostics.Summary(); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestJournalTail(t *testing.T) {
+	now := time.Now()
+	var entries []systemd.JournalEntry
+	for i := range 12 {
+		entries = append(entries, systemd.JournalEntry{Time: now, Message: fmt.Sprintf("line %d", i)})
+	}
+
+	got := journalTail(entries, diagnosticJournalLines)
+	if len(got) != diagnosticJournalLines {
+		t.Fatalf("got %d lines, want %d", len(got), diagnosticJournalLines)
+	}
+	want := now.Local().Format(time.TimeOnly) + " line 11"
+	if got[len(got)-1] != want {
+		t.Errorf("got last line %q, want %q", got[len(got)-1], want)
+	}
+}
@@ -0,0 +1,188 @@
+package remotemanagement
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/redhatinsights/rhc/internal/logging"
+	"github.com/redhatinsights/rhc/internal/util"
+)
+
+// WorkerTemplateDir is the directory of local templates of worker configuration
+// files. A template named <worker>.toml.tmpl replaces the built-in template of
+// the worker.
+var WorkerTemplateDir = "/etc/rhc/worker-templates"
+
+// WorkerConfig is a configuration file of a yggdrasil worker generated by rhc.
+type WorkerConfig struct {
+	// Name is the name of the worker.
+	Name string
+	// Path is the configuration file of the worker. It is only written when
+	// its directory exists, i.e. when the worker is installed.
+	Path string
+	// Template is the built-in template of the configuration file.
+	Template string
+}
+
+// WorkerValues are the values available in templates of worker configuration files.
+type WorkerValues struct {
+	LogLevel string
+	ProxyURL string
+	NoProxy  []string
+}
+
+// workerCommonTemplate sets the options of the rhc configuration common to all
+// workers.
+const workerCommonTemplate = `log-level = {{ quote .LogLevel }}
+{{- if .ProxyURL }}
+http-proxy = {{ quote .ProxyURL }}
+{{- end }}
+{{- if .NoProxy }}
+no-proxy = [{{ range $i, $host := .NoProxy }}{{ if $i }}, {{ end }}{{ quote $host }}{{ end }}]
+{{- end }}
+`
+
+// WorkerConfigs are the configuration files of workers generated during connect.
+// Besides the values of the rhc configuration, the built-in templates keep the
+// options the packages of the workers set.
+var WorkerConfigs = []WorkerConfig{
+	{
+		Name: "rhc-worker-playbook",
+		Path: "/etc/rhc-worker-playbook/rhc-worker-playbook.toml",
+		Template: workerCommonTemplate + `verify-playbook = true
+verify-playbook-version-check = true
+insights-core-gpg-check = true
+`,
+	},
+	{
+		Name: "yggdrasil-worker-package-manager",
+		Path: "/etc/yggdrasil-worker-package-manager/config.toml",
+		Template: workerCommonTemplate + `allow-pattern = ["^rhc-worker-.*", "^ansible-.*"]
+`,
+	},
+}
+
+// packagedDigest returns the SHA-256 digest of the file at path recorded by
+// the package owning it; it is empty when no package owns the file.
+var packagedDigest = func(path string) string {
+	rpm := exec.Command("/usr/bin/rpm", "--query", "--queryformat", "[%{FILENAMES}\t%{FILEDIGESTS}\n]", "--file", path)
+	start := logging.CommandStart(rpm)
+	output, err := rpm.Output()
+	logging.Command(rpm, start, err)
+	if err != nil {
+		return ""
+	}
+	return parsePackagedDigest(string(output), path)
+}
+
+// parsePackagedDigest returns the digest of path in output listing files of
+// a package with their digests, separated by a tab. Only SHA-256 digests are
+// returned, other digests are not comparable.
+func parsePackagedDigest(output, path string) string {
+	for _, line := range strings.Split(output, "\n") {
+		name, digest, found := strings.Cut(line, "\t")
+		if found && name == path && len(digest) == sha256.Size*2 {
+			return digest
+		}
+	}
+	return ""
+}
+
+// isPackaged reports whether data is the unchanged file at path installed by
+// its package.
+func isPackaged(path string, data []byte) bool {
+	digest := packagedDigest(path)
+	sum := sha256.Sum256(data)
+	return digest != "" && strings.EqualFold(digest, hex.EncodeToString(sum[:]))
+}
+
+// workerTemplateFuncs are the functions available in templates of worker
+// configuration files.
+var workerTemplateFuncs = template.FuncMap{
+	"quote": strconv.Quote,
+}
+
+// ConfigureWorkers writes the configuration files of installed workers from
+// their templates. A file that exists, and was neither written by rhc nor
+// installed unchanged by the package of the worker, is a local configuration,
+// and it is kept. The paths of the written files are returned.
+func ConfigureWorkers(values WorkerValues) ([]string, error) {
+	var written []string
+	var errs []error
+	for _, worker := range WorkerConfigs {
+		ok, err := configureWorker(worker, values)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot configure worker %s: %w", worker.Name, err))
+			continue
+		}
+		if ok {
+			written = append(written, worker.Path)
+		}
+	}
+	return written, errors.Join(errs...)
+}
+
+// configureWorker writes the configuration file of worker. False is returned
+// when the worker is not installed, or its local configuration file is kept.
+func configureWorker(worker WorkerConfig, values WorkerValues) (bool, error) {
+	if _, err := os.Stat(filepath.Dir(worker.Path)); errors.Is(err, os.ErrNotExist) {
+		slog.Debug("Worker is not installed, skipping its configuration", "worker", worker.Name)
+		return false, nil
+	}
+
+	current, err := os.ReadFile(worker.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	if err == nil && !strings.HasPrefix(string(current), managedHeader) && !isPackaged(worker.Path, current) {
+		slog.Debug("Keeping local worker configuration", "worker", worker.Name, "path", worker.Path)
+		return false, nil
+	}
+
+	data, err := renderWorkerConfig(worker, values)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(current, data) {
+		return false, nil
+	}
+	if err = util.WriteFileAtomic(worker.Path, data, 0644); err != nil {
+		return false, err
+	}
+	slog.Debug("Wrote worker configuration", "worker", worker.Name, "path", worker.Path)
+	return true, nil
+}
+
+// renderWorkerConfig executes the template of worker with values. The local
+// template in WorkerTemplateDir is preferred over the built-in one.
+func renderWorkerConfig(worker WorkerConfig, values WorkerValues) ([]byte, error) {
+	text := worker.Template
+	path := filepath.Join(WorkerTemplateDir, worker.Name+".toml.tmpl")
+	if local, err := os.ReadFile(path); err == nil {
+		text = string(local)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("cannot read template: %w", err)
+	}
+
+	tmpl, err := template.New(worker.Name).Funcs(workerTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse template: %w", err)
+	}
+	// The header marks the file as written by rhc, so it is overwritten next time
+	var buf bytes.Buffer
+	buf.WriteString(managedHeader + "; changes will be overwritten.\n")
+	if err = tmpl.Execute(&buf, values); err != nil {
+		return nil, fmt.Errorf("cannot render template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
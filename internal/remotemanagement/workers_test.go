@@ -0,0 +1,153 @@
+package remotemanagement
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pelletier/go-toml"
+)
+
+func TestConfigureWorkers(t *testing.T) {
+	dir := t.TempDir()
+	oldConfigs, oldTemplateDir := WorkerConfigs, WorkerTemplateDir
+	WorkerTemplateDir = filepath.Join(dir, "templates")
+	WorkerConfigs = []WorkerConfig{
+		{Name: "generated", Path: filepath.Join(dir, "generated", "config.toml"), Template: "log-level = {{ quote .LogLevel }}\n"},
+		{Name: "overridden", Path: filepath.Join(dir, "overridden", "config.toml"), Template: "unused\n"},
+		{Name: "local", Path: filepath.Join(dir, "local", "config.toml"), Template: "unused\n"},
+		{Name: "missing", Path: filepath.Join(dir, "missing", "config.toml"), Template: "unused\n"},
+		{Name: "packaged", Path: filepath.Join(dir, "packaged", "config.toml"), Template: "log-level = {{ quote .LogLevel }}\n"},
+	}
+	oldPackagedDigest := packagedDigest
+	packagedDigest = func(path string) string {
+		if path != WorkerConfigs[4].Path {
+			return ""
+		}
+		// SHA-256 of the packaged configuration
+		sum := sha256.Sum256([]byte("# Packaged configuration\nlog-level = \"info\"\n"))
+		return hex.EncodeToString(sum[:])
+	}
+	t.Cleanup(func() {
+		WorkerConfigs, WorkerTemplateDir, packagedDigest = oldConfigs, oldTemplateDir, oldPackagedDigest
+	})
+
+	for _, name := range []string{"generated", "overridden", "local", "packaged", "templates"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		filepath.Join(WorkerTemplateDir, "overridden.toml.tmpl"): "http-proxy = {{ quote .ProxyURL }}\n",
+		filepath.Join(dir, "local", "config.toml"):               "log-level = \"trace\"\n",
+		filepath.Join(dir, "packaged", "config.toml"):            "# Packaged configuration\nlog-level = \"info\"\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	values := WorkerValues{LogLevel: "debug", ProxyURL: "http://proxy.example.com:3128"}
+	written, err := ConfigureWorkers(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantWritten := []string{WorkerConfigs[0].Path, WorkerConfigs[1].Path, WorkerConfigs[4].Path}
+	if !cmp.Equal(written, wantWritten) {
+		t.Errorf("%v", cmp.Diff(written, wantWritten))
+	}
+
+	want := map[string]string{
+		WorkerConfigs[0].Path: "# This file is managed by rhc; changes will be overwritten.\nlog-level = \"debug\"\n",
+		WorkerConfigs[1].Path: "# This file is managed by rhc; changes will be overwritten.\nhttp-proxy = \"http://proxy.example.com:3128\"\n",
+		WorkerConfigs[2].Path: "log-level = \"trace\"\n",
+		WorkerConfigs[4].Path: "# This file is managed by rhc; changes will be overwritten.\nlog-level = \"debug\"\n",
+	}
+	for path, content := range want {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("%s: %v", path, cmp.Diff(string(data), content))
+		}
+	}
+	if _, err := os.Stat(WorkerConfigs[3].Path); !os.IsNotExist(err) {
+		t.Errorf("configuration of a missing worker was written: %v", err)
+	}
+
+	// Unchanged files are not written again
+	written, err = ConfigureWorkers(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 0 {
+		t.Errorf("unexpected written files %v", written)
+	}
+}
+
+func TestConfigureWorkersTemplateError(t *testing.T) {
+	dir := t.TempDir()
+	oldConfigs := WorkerConfigs
+	WorkerConfigs = []WorkerConfig{
+		{Name: "broken", Path: filepath.Join(dir, "config.toml"), Template: "log-level = {{ .Unknown }}\n"},
+	}
+	t.Cleanup(func() { WorkerConfigs = oldConfigs })
+
+	if _, err := ConfigureWorkers(WorkerValues{}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestParsePackagedDigest(t *testing.T) {
+	digest := strings.Repeat("ab", sha256.Size)
+	output := "/etc/rhc-worker-playbook\t\n" +
+		"/etc/rhc-worker-playbook/rhc-worker-playbook.toml\t" + digest + "\n" +
+		"/etc/rhc-worker-playbook/other.toml\t" + strings.Repeat("cd", sha256.Size) + "\n"
+	if got := parsePackagedDigest(output, "/etc/rhc-worker-playbook/rhc-worker-playbook.toml"); got != digest {
+		t.Errorf("got %q, want %q", got, digest)
+	}
+	if got := parsePackagedDigest(output, "/etc/rhc-worker-playbook/missing.toml"); got != "" {
+		t.Errorf("got %q for a file not in the package, want none", got)
+	}
+	// MD5 digests of old packages are not comparable
+	if got := parsePackagedDigest("/etc/config.toml\t"+strings.Repeat("ab", 16)+"\n", "/etc/config.toml"); got != "" {
+		t.Errorf("got MD5 digest %q, want none", got)
+	}
+}
+
+// TestWorkerConfigsBuiltIn checks the built-in templates set the values of the
+// rhc configuration.
+func TestWorkerConfigsBuiltIn(t *testing.T) {
+	oldTemplateDir := WorkerTemplateDir
+	WorkerTemplateDir = t.TempDir()
+	t.Cleanup(func() { WorkerTemplateDir = oldTemplateDir })
+
+	values := WorkerValues{LogLevel: "debug", ProxyURL: "http://proxy.example.com:3128", NoProxy: []string{"localhost", ".example.com"}}
+	for _, worker := range WorkerConfigs {
+		t.Run(worker.Name, func(t *testing.T) {
+			data, err := renderWorkerConfig(worker, values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tree, err := toml.LoadBytes(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tree.Get("log-level"); got != values.LogLevel {
+				t.Errorf("got log-level %v, want %v", got, values.LogLevel)
+			}
+			if got := tree.Get("http-proxy"); got != values.ProxyURL {
+				t.Errorf("got http-proxy %v, want %v", got, values.ProxyURL)
+			}
+			if got := tree.Get("no-proxy"); !cmp.Equal(got, []interface{}{"localhost", ".example.com"}) {
+				t.Errorf("got no-proxy %v, want %v", got, values.NoProxy)
+			}
+		})
+	}
+}
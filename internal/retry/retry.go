@@ -0,0 +1,83 @@
+// Package retry repeats operations failing because of transient errors, e.g.
+// of the network while a host is provisioned. Attempts are delayed by an
+// exponential backoff with random jitter, so hosts of a fleet failing at once
+// do not retry at once.
+package retry
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy describes how an operation is retried.
+type Policy struct {
+	// Attempts is the maximum number of attempts of the operation; it is
+	// attempted once, when Attempts is less than 2.
+	Attempts int
+	// Delay is the upper bound of the delay of the second attempt. It doubles
+	// with every following attempt.
+	Delay time.Duration
+	// MaxDelay caps the upper bound of the delay; zero does not cap it.
+	MaxDelay time.Duration
+}
+
+// Backoff returns the upper bound of the delay of the attempt with the number
+// attempt, starting at 1. The first attempt is not delayed.
+func (p Policy) Backoff(attempt int) time.Duration {
+	if attempt < 2 || p.Delay <= 0 {
+		return 0
+	}
+	backoff := p.Delay
+	for i := 2; i < attempt; i++ {
+		backoff *= 2
+		if p.MaxDelay > 0 && backoff >= p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	return backoff
+}
+
+// RandomDelay returns a random delay of the attempt with the number attempt,
+// in [0, Backoff(attempt)).
+func (p Policy) RandomDelay(attempt int) time.Duration {
+	backoff := p.Backoff(attempt)
+	if backoff <= 0 {
+		return 0
+	}
+	return rand.N(backoff)
+}
+
+// sleep waits for d, or until ctx is done.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Do runs operation, until it succeeds, it fails with an error retryable does
+// not report as transient, or the attempts are exhausted. The error of the
+// last attempt is returned. Waiting for an attempt is interrupted, when ctx is
+// done.
+func (p Policy) Do(ctx context.Context, name string, retryable func(error) bool, operation func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = operation(); err == nil || attempt >= p.Attempts || !retryable(err) {
+			return err
+		}
+		delay := p.RandomDelay(attempt + 1)
+		slog.Warn("Retrying failed operation", "operation", name, "attempt", attempt, "delay", delay.Round(time.Millisecond), "err", err)
+		if sleepErr := sleep(ctx, delay); sleepErr != nil {
+			return err
+		}
+	}
+}
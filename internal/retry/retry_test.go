@@ -0,0 +1,107 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errTransient = errors.New("transient")
+
+func TestBackoff(t *testing.T) {
+	policy := Policy{Attempts: 6, Delay: time.Second, MaxDelay: 5 * time.Second}
+	want := []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, wantBackoff := range want {
+		if got := policy.Backoff(i + 1); got != wantBackoff {
+			t.Errorf("attempt %d: got %v, want %v", i+1, got, wantBackoff)
+		}
+	}
+	for attempt := 2; attempt <= 6; attempt++ {
+		if delay := policy.RandomDelay(attempt); delay < 0 || delay >= policy.Backoff(attempt) {
+			t.Errorf("attempt %d: delay %v out of range [0, %v)", attempt, delay, policy.Backoff(attempt))
+		}
+	}
+}
+
+func TestDo(t *testing.T) {
+	defaultSleep := sleep
+	sleep = func(ctx context.Context, d time.Duration) error { return ctx.Err() }
+	t.Cleanup(func() { sleep = defaultSleep })
+
+	tests := []struct {
+		description  string
+		policy       Policy
+		failures     []error
+		wantAttempts int
+		wantError    bool
+	}{
+		{
+			description:  "success",
+			policy:       Policy{Attempts: 3, Delay: time.Second},
+			wantAttempts: 1,
+		},
+		{
+			description:  "transient failure",
+			policy:       Policy{Attempts: 3, Delay: time.Second},
+			failures:     []error{errTransient, errTransient},
+			wantAttempts: 3,
+		},
+		{
+			description:  "attempts exhausted",
+			policy:       Policy{Attempts: 3, Delay: time.Second},
+			failures:     []error{errTransient, errTransient, errTransient, errTransient},
+			wantAttempts: 3,
+			wantError:    true,
+		},
+		{
+			description:  "permanent failure",
+			policy:       Policy{Attempts: 3, Delay: time.Second},
+			failures:     []error{errors.New("permanent")},
+			wantAttempts: 1,
+			wantError:    true,
+		},
+		{
+			description:  "no retries",
+			policy:       Policy{},
+			failures:     []error{errTransient},
+			wantAttempts: 1,
+			wantError:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			attempts := 0
+			err := test.policy.Do(context.Background(), "test",
+				func(err error) bool { return errors.Is(err, errTransient) },
+				func() error {
+					attempts++
+					if attempts <= len(test.failures) {
+						return test.failures[attempts-1]
+					}
+					return nil
+				},
+			)
+			if (err != nil) != test.wantError {
+				t.Errorf("got error %v, want error %v", err, test.wantError)
+			}
+			if attempts != test.wantAttempts {
+				t.Errorf("got %d attempts, want %d", attempts, test.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestDoCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	attempts := 0
+	err := Policy{Attempts: 3, Delay: time.Hour}.Do(ctx, "test",
+		func(error) bool { return true },
+		func() error { attempts++; return errTransient },
+	)
+	if !errors.Is(err, errTransient) || attempts != 1 {
+		t.Errorf("got error %v after %d attempts, want %v after 1 attempt", err, attempts, errTransient)
+	}
+}
@@ -0,0 +1,67 @@
+// Package schedule computes when periodic operations run. Operations of a fleet
+// of hosts are spread over time, so they do not reach Red Hat services at once:
+// each host is shifted by a stable offset (splay), and every run is delayed
+// by a random duration (jitter).
+package schedule
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+)
+
+// MachineIDPath is the path of the file identifying the host.
+var MachineIDPath = "/etc/machine-id"
+
+// Schedule describes a periodic operation.
+type Schedule struct {
+	// Interval is the period of the operation.
+	Interval time.Duration
+	// Jitter is the upper bound of the random delay of every run.
+	Jitter time.Duration
+	// Splay is the upper bound of the per-host offset of the runs.
+	Splay time.Duration
+}
+
+// HostID returns the identifier of the host splay is derived from: the
+// machine ID, or the hostname when the machine ID is not available.
+func HostID() string {
+	if data, err := os.ReadFile(MachineIDPath); err == nil {
+		if id := strings.TrimSpace(string(data)); id != "" {
+			return id
+		}
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// Offset returns the offset of runs of the host identified by hostID. The
+// offset is stable for the host, and evenly distributed in [0, Splay).
+func (s Schedule) Offset(hostID string) time.Duration {
+	if s.Splay <= 0 {
+		return 0
+	}
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(hostID))
+	return time.Duration(hash.Sum64() % uint64(s.Splay))
+}
+
+// RandomDelay returns a random delay of a run, in [0, Jitter).
+func (s Schedule) RandomDelay() time.Duration {
+	if s.Jitter <= 0 {
+		return 0
+	}
+	return rand.N(s.Jitter)
+}
+
+// NextDelay returns the time to wait for the next run of the host identified
+// by hostID. The first run is shifted by the offset of the host; the following
+// runs are separated by Interval. Both are delayed by a random jitter.
+func (s Schedule) NextDelay(hostID string, first bool) time.Duration {
+	if first {
+		return s.Offset(hostID) + s.RandomDelay()
+	}
+	return s.Interval + s.RandomDelay()
+}
@@ -0,0 +1,106 @@
+package schedule
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOffset(t *testing.T) {
+	tests := []struct {
+		description string
+		splay       time.Duration
+	}{
+		{description: "no splay", splay: 0},
+		{description: "one hour", splay: time.Hour},
+		{description: "one day", splay: 24 * time.Hour},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			s := Schedule{Interval: 24 * time.Hour, Splay: test.splay}
+			offset := s.Offset("4c4c4544-0042-3510-8052-b4c04f4e5932")
+			if offset < 0 || (test.splay > 0 && offset >= test.splay) || (test.splay == 0 && offset != 0) {
+				t.Errorf("offset %v out of range [0, %v)", offset, test.splay)
+			}
+			if again := s.Offset("4c4c4544-0042-3510-8052-b4c04f4e5932"); again != offset {
+				t.Errorf("offset is not stable: %v != %v", again, offset)
+			}
+		})
+	}
+}
+
+func TestOffsetSpread(t *testing.T) {
+	s := Schedule{Splay: time.Hour}
+	offsets := make(map[time.Duration]bool)
+	for _, id := range []string{"host-a", "host-b", "host-c", "host-d"} {
+		offsets[s.Offset(id)] = true
+	}
+	if len(offsets) < 2 {
+		t.Errorf("offsets of different hosts are identical")
+	}
+}
+
+func TestNextDelay(t *testing.T) {
+	tests := []struct {
+		description string
+		schedule    Schedule
+		first       bool
+		wantMin     time.Duration
+		wantMax     time.Duration
+	}{
+		{
+			description: "first run without splay and jitter",
+			schedule:    Schedule{Interval: time.Hour},
+			first:       true,
+			wantMin:     0,
+			wantMax:     0,
+		},
+		{
+			description: "following run without jitter",
+			schedule:    Schedule{Interval: time.Hour, Splay: time.Minute},
+			first:       false,
+			wantMin:     time.Hour,
+			wantMax:     time.Hour,
+		},
+		{
+			description: "following run with jitter",
+			schedule:    Schedule{Interval: time.Hour, Jitter: time.Minute},
+			first:       false,
+			wantMin:     time.Hour,
+			wantMax:     time.Hour + time.Minute - 1,
+		},
+		{
+			description: "first run with splay and jitter",
+			schedule:    Schedule{Interval: time.Hour, Jitter: time.Minute, Splay: time.Minute},
+			first:       true,
+			wantMin:     0,
+			wantMax:     2*time.Minute - 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			for range 100 {
+				delay := test.schedule.NextDelay("host", test.first)
+				if delay < test.wantMin || delay > test.wantMax {
+					t.Fatalf("delay %v out of range [%v, %v]", delay, test.wantMin, test.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func TestHostID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "machine-id")
+	if err := os.WriteFile(path, []byte("4c4c4544004235108052b4c04f4e5932\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	MachineIDPath = path
+	t.Cleanup(func() { MachineIDPath = "/etc/machine-id" })
+
+	if got := HostID(); got != "4c4c4544004235108052b4c04f4e5932" {
+		t.Errorf("got %q", got)
+	}
+}
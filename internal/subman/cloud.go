@@ -0,0 +1,75 @@
+package subman
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+
+	"github.com/redhatinsights/rhc/internal/logging"
+)
+
+// autoRegistrationCommand is the worker of rhsmcertd registering the system
+// with the instance identity document of the cloud it runs in. The com.redhat.RHSM1
+// D-Bus API does not provide an interface for the cloud registration flow.
+var autoRegistrationCommand = []string{"/usr/libexec/rhsmcertd-worker", "--auto-registration"}
+
+// RegisterWithCloudIdentity registers the system using the instance identity
+// document of AWS, Azure or Google Cloud instead of credentials. The entitlement
+// server registers the system with the organization the cloud account is linked
+// to.
+//
+// Returns [ErrRegistrationPending] if the cloud account is not linked to an
+// organization yet; rhsmcertd completes the registration once it is.
+func (c *RHSMClient) RegisterWithCloudIdentity(ctx context.Context) error {
+	slog.Debug("Registering system with the cloud instance identity")
+
+	err := Retry.Do(ctx, "register", isTransient, func() error {
+		var stderr bytes.Buffer
+		slog.Debug("Executing " + strings.Join(autoRegistrationCommand, " "))
+		cmd := exec.CommandContext(ctx, autoRegistrationCommand[0], autoRegistrationCommand[1:]...)
+		cmd.Stderr = &stderr
+		start := logging.CommandStart(cmd)
+		err := cmd.Run()
+		logging.Command(cmd, start, err)
+		if ctx.Err() != nil {
+			return fmt.Errorf("registering with RHSM: %w", ctx.Err())
+		}
+		if err != nil {
+			return fmt.Errorf("registering with RHSM: %w", cloudRegistrationError(err, stderr.String()))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	registered, err := c.IsRegistered()
+	if err != nil {
+		return err
+	}
+	if !registered {
+		return ErrRegistrationPending
+	}
+	return nil
+}
+
+// cloudRegistrationError returns the error of the worker registering the
+// system, which failed with err and printed stderr. Errors of the entitlement
+// server that cannot be reached match [ErrNetwork].
+func cloudRegistrationError(err error, stderr string) error {
+	var exitErr *exec.ExitError
+	stderr = strings.TrimSpace(stderr)
+	if !errors.As(err, &exitErr) || stderr == "" {
+		return err
+	}
+	for _, message := range connectionMessages {
+		if strings.Contains(stderr, message) {
+			return fmt.Errorf("%w: %s", ErrNetwork, stderr)
+		}
+	}
+	return errors.New(stderr)
+}
@@ -0,0 +1,59 @@
+package subman
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+func TestCloudRegistrationError(t *testing.T) {
+	exitErr := exec.Command("/bin/sh", "-c", "exit 1").Run()
+	if exitErr == nil {
+		t.Fatal("expected exit error, got nil")
+	}
+
+	tests := []struct {
+		description string
+		err         error
+		stderr      string
+		want        string
+		wantNetwork bool
+	}{
+		{
+			description: "server unreachable",
+			err:         exitErr,
+			stderr:      "Unable to reach the server at subscription.rhsm.redhat.com:443/subscription\n",
+			want:        "entitlement server cannot be reached: Unable to reach the server at subscription.rhsm.redhat.com:443/subscription",
+			wantNetwork: true,
+		},
+		{
+			description: "unsupported cloud",
+			err:         exitErr,
+			stderr:      "Unable to detect cloud provider\n",
+			want:        "Unable to detect cloud provider",
+		},
+		{
+			description: "no output",
+			err:         exitErr,
+			want:        exitErr.Error(),
+		},
+		{
+			description: "worker not installed",
+			err:         exec.ErrNotFound,
+			stderr:      "",
+			want:        exec.ErrNotFound.Error(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := cloudRegistrationError(test.err, test.stderr)
+			if got.Error() != test.want {
+				t.Errorf("got %q, want %q", got.Error(), test.want)
+			}
+			if errors.Is(got, ErrNetwork) != test.wantNetwork {
+				t.Errorf("got network error %v, want %v", errors.Is(got, ErrNetwork), test.wantNetwork)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package subman
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/redhatinsights/rhc/internal/localization"
+)
+
+// DefaultCACertDir is the directory with CA certificates trusted by RHSM,
+// unless rhsm.ca_cert_dir says otherwise.
+const DefaultCACertDir = "/etc/rhsm/ca"
+
+// GetConfigValue returns the value of the rhsm.conf option key (e.g. "server.hostname").
+func (c *RHSMClient) GetConfigValue(key string) (string, error) {
+	var value string
+	err := call(
+		c.conn,
+		"/com/redhat/RHSM1/Config",
+		"com.redhat.RHSM1.Config.Get",
+		key,
+		localization.GetLocale(),
+	).Store(&value)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", key, newDbusError(err))
+	}
+	return value, nil
+}
+
+// ServerURL returns the URL of the entitlement server configured in rhsm.conf.
+func (c *RHSMClient) ServerURL() (string, error) {
+	hostname, err := c.GetConfigValue("server.hostname")
+	if err != nil {
+		return "", err
+	}
+	port, err := c.GetConfigValue("server.port")
+	if err != nil {
+		return "", err
+	}
+	prefix, err := c.GetConfigValue("server.prefix")
+	if err != nil {
+		return "", err
+	}
+	return serverURL(hostname, port, prefix), nil
+}
+
+// serverURL builds the URL of the entitlement server from rhsm.conf options.
+func serverURL(hostname, port, prefix string) string {
+	host := hostname
+	if port != "" && port != "443" {
+		host = net.JoinHostPort(hostname, port)
+	}
+	u := url.URL{Scheme: "https", Host: host, Path: "/" + strings.Trim(prefix, "/")}
+	return u.String()
+}
+
+// CACertDir returns the directory with CA certificates trusted by RHSM.
+func (c *RHSMClient) CACertDir() (string, error) {
+	dir, err := c.GetConfigValue("rhsm.ca_cert_dir")
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		return DefaultCACertDir, nil
+	}
+	return dir, nil
+}
+
+// SetConfigValue sets the rhsm.conf option key (e.g. "server.hostname") to value.
+func (c *RHSMClient) SetConfigValue(key, value string) error {
+	err := call(
+		c.conn,
+		"/com/redhat/RHSM1/Config",
+		"com.redhat.RHSM1.Config.Set",
+		key,
+		value,
+		localization.GetLocale(),
+	).Err
+	if err != nil {
+		return fmt.Errorf("setting %s: %w", key, newDbusError(err))
+	}
+	return nil
+}
+
+// SetServer configures the entitlement server at rawURL in rhsm.conf, and the
+// content delivery network at baseURL, unless it is empty.
+func (c *RHSMClient) SetServer(rawURL, baseURL string) error {
+	hostname, port, prefix, err := parseServerURL(rawURL)
+	if err != nil {
+		return err
+	}
+	options := [][2]string{
+		{"server.hostname", hostname},
+		{"server.port", port},
+		{"server.prefix", prefix},
+	}
+	if baseURL != "" {
+		options = append(options, [2]string{"rhsm.baseurl", baseURL})
+	}
+	for _, option := range options {
+		if err = c.SetConfigValue(option[0], option[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SameServer reports whether the URLs a and b point at the same entitlement
+// server. The prefixes are not compared, as the same server is often given
+// without its prefix (e.g. "https://subscription.rhsm.redhat.com").
+func SameServer(a, b string) bool {
+	hostA, portA, _, errA := parseServerURL(a)
+	hostB, portB, _, errB := parseServerURL(b)
+	return errA == nil && errB == nil && strings.EqualFold(hostA, hostB) && portA == portB
+}
+
+// parseServerURL splits the URL of the entitlement server into rhsm.conf
+// options; it is the inverse of serverURL.
+func parseServerURL(rawURL string) (hostname, port, prefix string, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return "", "", "", fmt.Errorf("invalid server URL %q: has to be an https URL", rawURL)
+	}
+	port = u.Port()
+	if port == "" {
+		port = "443"
+	}
+	prefix = "/" + strings.Trim(u.Path, "/")
+	return u.Hostname(), port, prefix, nil
+}
@@ -0,0 +1,111 @@
+package subman
+
+import "testing"
+
+func TestServerURL(t *testing.T) {
+	tests := []struct {
+		description string
+		hostname    string
+		port        string
+		prefix      string
+		want        string
+	}{
+		{
+			description: "default port",
+			hostname:    "subscription.rhsm.redhat.com",
+			port:        "443",
+			prefix:      "/subscription",
+			want:        "https://subscription.rhsm.redhat.com/subscription",
+		},
+		{
+			description: "custom port",
+			hostname:    "satellite.example.com",
+			port:        "8443",
+			prefix:      "/rhsm/",
+			want:        "https://satellite.example.com:8443/rhsm",
+		},
+		{
+			description: "empty prefix",
+			hostname:    "satellite.example.com",
+			port:        "",
+			prefix:      "",
+			want:        "https://satellite.example.com/",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := serverURL(test.hostname, test.port, test.prefix)
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseServerURL(t *testing.T) {
+	tests := []struct {
+		description string
+		url         string
+		want        [3]string
+		wantError   bool
+	}{
+		{
+			description: "default port",
+			url:         "https://subscription.rhsm.redhat.com/subscription",
+			want:        [3]string{"subscription.rhsm.redhat.com", "443", "/subscription"},
+		},
+		{
+			description: "custom port",
+			url:         "https://satellite.example.com:8443/rhsm/",
+			want:        [3]string{"satellite.example.com", "8443", "/rhsm"},
+		},
+		{
+			description: "empty prefix",
+			url:         "https://satellite.example.com",
+			want:        [3]string{"satellite.example.com", "443", "/"},
+		},
+		{
+			description: "insecure scheme",
+			url:         "http://satellite.example.com/rhsm",
+			wantError:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			hostname, port, prefix, err := parseServerURL(test.url)
+			if test.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := [3]string{hostname, port, prefix}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSameServer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://subscription.rhsm.redhat.com/subscription", "https://subscription.rhsm.redhat.com", true},
+		{"https://Satellite.example.com/rhsm", "https://satellite.example.com:443/rhsm", true},
+		{"https://subscription.rhsm.redhat.com/subscription", "https://satellite.example.com/rhsm", false},
+		{"https://satellite.example.com/rhsm", "https://satellite.example.com:8443/rhsm", false},
+		{"https://satellite.example.com/rhsm", "satellite.example.com", false},
+	}
+	for _, test := range tests {
+		if got := SameServer(test.a, test.b); got != test.want {
+			t.Errorf("SameServer(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
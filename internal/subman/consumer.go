@@ -0,0 +1,94 @@
+package subman
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	httpapi "github.com/redhatinsights/rhc/internal/http"
+)
+
+// renameConsumerTimeout limits the duration of the update of the consumer name.
+const renameConsumerTimeout = 30 * time.Second
+
+// RenameConsumer sets the name of the consumer of the system to name on the
+// entitlement server configured in rhsm.conf, so the system record in Red Hat
+// Subscription Management follows changes of the hostname. The request is
+// authenticated with the consumer certificate, and the server has to present a
+// certificate issued by a CA of the CA directory of rhsm.conf or of the system.
+//
+// Returns [ErrNotRegistered] if the system is not currently registered.
+func (c *RHSMClient) RenameConsumer(ctx context.Context, name string) error {
+	uuid, err := c.GetConsumerUUID()
+	if err != nil {
+		return err
+	}
+	serverURL, err := c.ServerURL()
+	if err != nil {
+		return fmt.Errorf("renaming consumer: %w", err)
+	}
+	caCertDir, err := c.CACertDir()
+	if err != nil {
+		return fmt.Errorf("renaming consumer: %w", err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(ConsumerCertPath, ConsumerKeyPath)
+	if err != nil {
+		return fmt.Errorf("renaming consumer: cannot load consumer certificate: %w", err)
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	paths, _ := filepath.Glob(filepath.Join(caCertDir, "*.pem"))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			slog.Debug("Unable to read CA certificate", "path", path, "err", err)
+			continue
+		}
+		roots.AppendCertsFromPEM(data)
+	}
+
+	client := httpapi.NewHTTPClient(&tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: roots})
+	client.Timeout = renameConsumerTimeout
+	return renameConsumer(ctx, client, serverURL, uuid, name)
+}
+
+// renameConsumer sends the new name of the consumer uuid to the entitlement
+// server at serverURL with client.
+func renameConsumer(ctx context.Context, client *http.Client, serverURL, uuid, name string) error {
+	slog.Debug("Renaming consumer", "uuid", uuid, "name", name)
+	body, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return err
+	}
+	endpoint := strings.TrimSuffix(serverURL, "/") + "/consumers/" + url.PathEscape(uuid)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("renaming consumer: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("renaming consumer: %w: %v", ErrNetwork, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("renaming consumer: entitlement server returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package subman
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRenameConsumer(t *testing.T) {
+	var gotMethod, gotPath, gotName string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		var body struct {
+			Name string `json:"name"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotName = body.Name
+		if body.Name == "forbidden.example.com" {
+			http.Error(w, `{"displayMessage": "Insufficient permissions"}`, http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	err := renameConsumer(context.Background(), server.Client(), server.URL+"/subscription/", "1234-abcd", "host.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotMethod != http.MethodPut || gotPath != "/subscription/consumers/1234-abcd" || gotName != "host.example.com" {
+		t.Errorf("got %s %s with name %q, want PUT /subscription/consumers/1234-abcd with name %q",
+			gotMethod, gotPath, gotName, "host.example.com")
+	}
+
+	err = renameConsumer(context.Background(), server.Client(), server.URL, "1234-abcd", "forbidden.example.com")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("got %v, want error of the entitlement server", err)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log/slog"
 
-	"github.com/godbus/dbus/v5"
 	"github.com/redhatinsights/rhc/internal/localization"
 )
 
@@ -14,12 +13,11 @@ func (c *RHSMClient) IsContentManagementEnabled() (bool, error) {
 	slog.Debug("Checking content management status")
 
 	locale := localization.GetLocale()
-	config := c.conn.Object("com.redhat.RHSM1", "/com/redhat/RHSM1/Config")
-
 	var value string
-	err := config.Call(
+	err := call(
+		c.conn,
+		"/com/redhat/RHSM1/Config",
 		"com.redhat.RHSM1.Config.Get",
-		dbus.Flags(0),
 		"rhsm.manage_repos",
 		locale,
 	).Store(&value)
@@ -36,16 +34,15 @@ func (c *RHSMClient) SetContentManagement(enabled bool) error {
 	slog.Debug("Setting content management", "enabled", enabled)
 
 	locale := localization.GetLocale()
-	config := c.conn.Object("com.redhat.RHSM1", "/com/redhat/RHSM1/Config")
-
 	value := "0"
 	if enabled {
 		value = "1"
 	}
 
-	err := config.Call(
+	err := call(
+		c.conn,
+		"/com/redhat/RHSM1/Config",
 		"com.redhat.RHSM1.Config.Set",
-		dbus.Flags(0),
 		"rhsm.manage_repos",
 		value,
 		locale,
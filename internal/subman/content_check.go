@@ -0,0 +1,120 @@
+package subman
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	httpapi "github.com/redhatinsights/rhc/internal/http"
+)
+
+// contentCheckTimeout limits the duration of the content access smoke test.
+const contentCheckTimeout = 15 * time.Second
+
+var (
+	// dnfReleaseVerPath is the path to the dnf variable overriding $releasever.
+	dnfReleaseVerPath = "/etc/dnf/vars/releasever"
+	// osReleasePath is the path to the os-release file.
+	osReleasePath = "/etc/os-release"
+)
+
+// basearch returns the value of the $basearch dnf variable.
+func basearch() string {
+	switch runtime.GOARCH {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	default:
+		return runtime.GOARCH
+	}
+}
+
+// releasever returns the value of the $releasever dnf variable: the value set
+// by 'rhc release set', or the major version of the operating system.
+func releasever() (string, error) {
+	if data, err := os.ReadFile(dnfReleaseVerPath); err == nil {
+		if version := strings.TrimSpace(string(data)); version != "" {
+			return version, nil
+		}
+	}
+
+	data, err := os.ReadFile(osReleasePath)
+	if err != nil {
+		return "", fmt.Errorf("cannot determine release version: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, found := strings.CutPrefix(line, "VERSION_ID="); found {
+			value = strings.Trim(value, `"'`)
+			major, _, _ := strings.Cut(value, ".")
+			return major, nil
+		}
+	}
+	return "", fmt.Errorf("cannot determine release version: VERSION_ID not found in %s", osReleasePath)
+}
+
+// expandRepoURL replaces dnf variables in the base URL of a repository.
+func expandRepoURL(baseURL string, releasever string, basearch string) string {
+	return strings.NewReplacer(
+		"$releasever", releasever,
+		"${releasever}", releasever,
+		"$basearch", basearch,
+		"${basearch}", basearch,
+	).Replace(baseURL)
+}
+
+// CheckContentAccess sends a HEAD request for the repository metadata of repo
+// using its entitlement certificate. An error is returned when the CDN
+// cannot be reached or refuses access to the repository.
+func CheckContentAccess(repo Repository) error {
+	if repo.BaseURL == "" {
+		return fmt.Errorf("repository %s has no base URL", repo.ID)
+	}
+
+	tlsConfig := &tls.Config{}
+	if repo.SSLClientCert != "" && repo.SSLClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(repo.SSLClientCert, repo.SSLClientKey)
+		if err != nil {
+			return fmt.Errorf("cannot load entitlement certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if repo.SSLCACert != "" {
+		data, err := os.ReadFile(repo.SSLCACert)
+		if err != nil {
+			return fmt.Errorf("cannot load CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("cannot load CA certificate: no certificate found in %s", repo.SSLCACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	version, err := releasever()
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(expandRepoURL(repo.BaseURL, version, basearch()), "/") + "/repodata/repomd.xml"
+
+	client := httpapi.NewHTTPClient(tlsConfig)
+	client.Timeout = contentCheckTimeout
+
+	slog.Debug("Checking content access", "repository", repo.ID, "url", url)
+	resp, err := client.Head(url)
+	if err != nil {
+		return fmt.Errorf("cannot reach content delivery network: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("content delivery network returned %s for repository %s", resp.Status, repo.ID)
+	}
+	return nil
+}
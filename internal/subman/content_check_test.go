@@ -0,0 +1,45 @@
+package subman
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandRepoURL(t *testing.T) {
+	got := expandRepoURL("https://cdn.redhat.com/content/dist/rhel9/$releasever/$basearch/baseos/os", "9", "x86_64")
+	want := "https://cdn.redhat.com/content/dist/rhel9/9/x86_64/baseos/os"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestReleasever(t *testing.T) {
+	dir := t.TempDir()
+	oldDnf, oldOS := dnfReleaseVerPath, osReleasePath
+	defer func() { dnfReleaseVerPath, osReleasePath = oldDnf, oldOS }()
+	dnfReleaseVerPath = filepath.Join(dir, "releasever")
+	osReleasePath = filepath.Join(dir, "os-release")
+
+	if err := os.WriteFile(osReleasePath, []byte("NAME=\"Red Hat Enterprise Linux\"\nVERSION_ID=\"9.4\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := releasever()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "9" {
+		t.Errorf("got %q, want %q", got, "9")
+	}
+
+	if err := os.WriteFile(dnfReleaseVerPath, []byte("9.2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = releasever()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "9.2" {
+		t.Errorf("got %q, want %q", got, "9.2")
+	}
+}
@@ -1,12 +1,14 @@
 package subman
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 
 	"github.com/godbus/dbus/v5"
 	"github.com/redhatinsights/rhc/internal/localization"
+	"github.com/redhatinsights/rhc/internal/logging"
 )
 
 // bus returns the shared system D-Bus connection.
@@ -20,6 +22,21 @@ func bus() (*dbus.Conn, error) {
 	return conn, nil
 }
 
+// call calls method of the RHSM object at path over conn, and waits for the
+// reply. The call is logged at DEBUG level.
+func call(conn *dbus.Conn, path dbus.ObjectPath, method string, args ...any) *dbus.Call {
+	return callContext(context.Background(), conn, path, method, args...)
+}
+
+// callContext is call, which stops waiting for the reply when ctx is done; the
+// error of the call is the error of ctx then.
+func callContext(ctx context.Context, conn *dbus.Conn, path dbus.ObjectPath, method string, args ...any) *dbus.Call {
+	start := logging.DBusCallStart(method, string(path))
+	result := conn.Object("com.redhat.RHSM1", path).CallWithContext(ctx, method, dbus.Flags(0), args...)
+	logging.DBusCall(method, string(path), start, result.Err)
+	return result
+}
+
 // unpackOrganizations unmarshals the JSON list of organizations returned by the
 // D-Bus GetOrgs method into a plain slice of organization key strings.
 func unpackOrganizations(s string) ([]string, error) {
@@ -38,19 +55,37 @@ func unpackOrganizations(s string) ([]string, error) {
 	return keys, nil
 }
 
+// unpackOwner unmarshals the JSON organization returned by the D-Bus GetOrg
+// method.
+func unpackOwner(s string) (*Owner, error) {
+	var owner struct {
+		Key         string `json:"key"`
+		DisplayName string `json:"displayName"`
+	}
+	if err := json.Unmarshal([]byte(s), &owner); err != nil {
+		return nil, fmt.Errorf("cannot parse owner of subscriptions: %w", err)
+	}
+	if owner.Key == "" {
+		return nil, ErrNotRegistered
+	}
+	return &Owner{Key: owner.Key, DisplayName: owner.DisplayName}, nil
+}
+
 // withPrivateRegisterSocket opens the private RHSM registration socket and
 // calls fn with the live connection and the resolved locale string.
 // It ensures the socket is stopped and closed on return regardless of outcome.
-// fn must not retain the connection after it returns.
-func withPrivateRegisterSocket(conn *dbus.Conn, fn func(*dbus.Conn, string) error) error {
+// fn must not retain the connection after it returns. The socket is not
+// opened, when ctx is done.
+func withPrivateRegisterSocket(ctx context.Context, conn *dbus.Conn, fn func(*dbus.Conn, string) error) error {
 	locale := localization.GetLocale()
-	registerServer := conn.Object("com.redhat.RHSM1", "/com/redhat/RHSM1/RegisterServer")
 
 	slog.Debug("Opening private D-Bus UNIX socket")
 	var socketURI string
-	err := registerServer.Call(
+	err := callContext(
+		ctx,
+		conn,
+		"/com/redhat/RHSM1/RegisterServer",
 		"com.redhat.RHSM1.RegisterServer.Start",
-		dbus.Flags(0),
 		locale,
 	).Store(&socketURI)
 	if err != nil {
@@ -58,7 +93,11 @@ func withPrivateRegisterSocket(conn *dbus.Conn, fn func(*dbus.Conn, string) erro
 	}
 	defer func() {
 		slog.Debug("Closing private UNIX socket", "socket", socketURI)
-		registerServer.Call("com.redhat.RHSM1.RegisterServer.Stop", dbus.FlagNoReplyExpected, locale)
+		start := logging.DBusCallStart("com.redhat.RHSM1.RegisterServer.Stop", "/com/redhat/RHSM1/RegisterServer")
+		result := conn.Object("com.redhat.RHSM1", "/com/redhat/RHSM1/RegisterServer").Call(
+			"com.redhat.RHSM1.RegisterServer.Stop", dbus.FlagNoReplyExpected, locale,
+		)
+		logging.DBusCall("com.redhat.RHSM1.RegisterServer.Stop", "/com/redhat/RHSM1/RegisterServer", start, result.Err)
 	}()
 
 	slog.Debug("Connecting to private D-Bus UNIX socket", "socket", socketURI)
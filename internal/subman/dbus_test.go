@@ -0,0 +1,44 @@
+package subman
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnpackOwner(t *testing.T) {
+	tests := []struct {
+		description string
+		input       string
+		want        *Owner
+		wantError   error
+	}{
+		{
+			description: "owner",
+			input:       `{"key": "12345678", "displayName": "ACME Corp.", "contentAccessMode": "org_environment"}`,
+			want:        &Owner{Key: "12345678", DisplayName: "ACME Corp."},
+		},
+		{
+			description: "not registered",
+			input:       `{}`,
+			wantError:   ErrNotRegistered,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := unpackOwner(test.input)
+			if !errors.Is(err, test.wantError) {
+				t.Fatalf("got error %v, want %v", err, test.wantError)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+
+	if _, err := unpackOwner("not json"); err == nil {
+		t.Errorf("got no error for invalid document")
+	}
+}
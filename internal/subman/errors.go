@@ -1,9 +1,12 @@
 package subman
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"slices"
+	"strings"
 
 	"github.com/godbus/dbus/v5"
 )
@@ -19,6 +22,19 @@ var ErrNotRegistered = errors.New("system is not registered with RHSM")
 // but was not.
 var ErrOrganizationRequired = errors.New("organization is required")
 
+// ErrRegistrationPending is returned when the system is registered with the
+// identity of its cloud instance, but the cloud account is not linked to an
+// organization yet.
+var ErrRegistrationPending = errors.New("the cloud account is not linked to an organization yet")
+
+// ErrAuth is matched by errors of the entitlement server rejecting the
+// credentials or the identity of the system.
+var ErrAuth = errors.New("authentication with the entitlement server failed")
+
+// ErrNetwork is matched by errors of the entitlement server that cannot be
+// reached, or fails to serve the request.
+var ErrNetwork = errors.New("entitlement server cannot be reached")
+
 // dbusError holds the structured error body returned by com.redhat.RHSM1 D-Bus methods.
 type dbusError struct {
 	Exception string `json:"exception"`
@@ -30,6 +46,18 @@ func (e dbusError) Error() string {
 	return e.Message
 }
 
+// Is reports whether the exception raised by RHSM matches target, one of
+// ErrAuth and ErrNetwork.
+func (e dbusError) Is(target error) bool {
+	switch target {
+	case ErrAuth:
+		return slices.Contains(authExceptions, e.Exception)
+	case ErrNetwork:
+		return slices.Contains(connectionExceptions, e.Exception)
+	}
+	return false
+}
+
 // newDbusError translates a raw D-Bus error into a structured dbusError when
 // the error originates from com.redhat.RHSM1. Returns the original error
 // unchanged for all other cases or when the body cannot be parsed.
@@ -50,3 +78,84 @@ func newDbusError(err error) error {
 	}
 	return d
 }
+
+// connectionExceptions are the exceptions raised by RHSM when the entitlement
+// server cannot be reached, or fails to serve the request.
+var connectionExceptions = []string{
+	"ConnectionException",
+	"ConnectionRefusedError",
+	"ConnectionError",
+	"NetworkException",
+	"ProxyException",
+	"RemoteServerException",
+	"SSLError",
+	"TimeoutError",
+	"gaierror",
+	"timeout",
+}
+
+// authExceptions are the exceptions raised by RHSM when the entitlement server
+// rejects the credentials or the identity of the system.
+var authExceptions = []string{
+	"UnauthorizedException",
+	"ForbiddenException",
+}
+
+// connectionMessages are the messages printed by subscription-manager when the
+// entitlement server cannot be reached.
+var connectionMessages = []string{
+	"Unable to reach the server",
+	"Network error",
+	"Remote server error",
+	"Proxy connection failed",
+}
+
+// IsConnectionError reports whether err means the entitlement server cannot
+// be reached, so the operation may succeed with another server.
+func IsConnectionError(err error) bool {
+	if errors.Is(err, ErrNetwork) {
+		return true
+	}
+	var d dbusError
+	if err == nil || errors.As(err, &d) {
+		return false
+	}
+	for _, message := range connectionMessages {
+		if strings.Contains(err.Error(), message) {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorCode returns a stable identifier of err for machine-readable output.
+// Unlike the message of err, which subscription-manager may translate, the
+// identifier does not depend on the locale. An empty string is returned for
+// errors not originating from RHSM.
+func ErrorCode(err error) string {
+	var d dbusError
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, ErrDBusUnavailable):
+		return "dbus-unavailable"
+	case errors.Is(err, ErrNotRegistered):
+		return "not-registered"
+	case errors.Is(err, ErrOrganizationRequired):
+		return "organization-required"
+	case errors.Is(err, ErrRegistrationPending):
+		return "registration-pending"
+	case errors.Is(err, ErrNetwork):
+		return "server-unreachable"
+	case errors.Is(err, ErrAuth):
+		return "authentication-failed"
+	case errors.As(err, &d):
+		if d.Exception == "OrgNotSpecifiedException" {
+			return "organization-required"
+		}
+		return "rhsm-error"
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package subman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestErrorCode(t *testing.T) {
+	rhsmError := func(exception string) error {
+		return newDbusError(dbus.Error{
+			Name: "com.redhat.RHSM1.Error",
+			Body: []any{fmt.Sprintf(`{"exception": %q, "severity": "error", "message": "Neplatné přihlašovací údaje"}`, exception)},
+		})
+	}
+
+	tests := []struct {
+		description string
+		err         error
+		want        string
+	}{
+		{description: "no error", err: nil, want: ""},
+		{description: "D-Bus unavailable", err: fmt.Errorf("connecting: %w", ErrDBusUnavailable), want: "dbus-unavailable"},
+		{description: "not registered", err: ErrNotRegistered, want: "not-registered"},
+		{description: "organization required", err: ErrOrganizationRequired, want: "organization-required"},
+		{description: "registration pending", err: ErrRegistrationPending, want: "registration-pending"},
+		{description: "organization not specified", err: rhsmError("OrgNotSpecifiedException"), want: "organization-required"},
+		{description: "server unreachable", err: rhsmError("NetworkException"), want: "server-unreachable"},
+		{description: "invalid credentials", err: fmt.Errorf("registering: %w", rhsmError("UnauthorizedException")), want: "authentication-failed"},
+		{description: "translated RHSM error", err: fmt.Errorf("registering: %w", rhsmError("RestlibException")), want: "rhsm-error"},
+		{description: "deadline exceeded", err: fmt.Errorf("registering: %w", context.DeadlineExceeded), want: "timeout"},
+		{description: "other error", err: errors.New("timeout"), want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if got := ErrorCode(test.err); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	rhsmError := func(exception string) error {
+		return newDbusError(dbus.Error{
+			Name: "com.redhat.RHSM1.Error",
+			Body: []any{fmt.Sprintf(`{"exception": %q, "severity": "error", "message": "Unable to reach the server"}`, exception)},
+		})
+	}
+
+	tests := []struct {
+		description string
+		err         error
+		want        bool
+	}{
+		{description: "no error", err: nil, want: false},
+		{description: "connection refused", err: fmt.Errorf("registering: %w", rhsmError("ConnectionRefusedError")), want: true},
+		{description: "remote server error", err: rhsmError("RemoteServerException"), want: true},
+		{description: "invalid credentials", err: rhsmError("RestlibException"), want: false},
+		{description: "subscription-manager output", err: errors.New("Unable to reach the server at capsule.example.com:443/rhsm"), want: true},
+		{description: "other error", err: errors.New("permission denied"), want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if got := IsConnectionError(test.err); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	rhsmError := func(exception string) error {
+		return fmt.Errorf("registering with RHSM: %w", newDbusError(dbus.Error{
+			Name: "com.redhat.RHSM1.Error",
+			Body: []any{fmt.Sprintf(`{"exception": %q, "severity": "error", "message": "error"}`, exception)},
+		}))
+	}
+
+	tests := []struct {
+		description string
+		err         error
+		wantAuth    bool
+		wantNetwork bool
+	}{
+		{description: "unauthorized", err: rhsmError("UnauthorizedException"), wantAuth: true},
+		{description: "forbidden", err: rhsmError("ForbiddenException"), wantAuth: true},
+		{description: "timeout", err: rhsmError("TimeoutError"), wantNetwork: true},
+		{description: "other exception", err: rhsmError("RestlibException")},
+		{description: "D-Bus unavailable", err: fmt.Errorf("%w: no bus", ErrDBusUnavailable)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if got := errors.Is(test.err, ErrAuth); got != test.wantAuth {
+				t.Errorf("errors.Is(ErrAuth) = %v, want %v", got, test.wantAuth)
+			}
+			if got := errors.Is(test.err, ErrNetwork); got != test.wantNetwork {
+				t.Errorf("errors.Is(ErrNetwork) = %v, want %v", got, test.wantNetwork)
+			}
+		})
+	}
+}
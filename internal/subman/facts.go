@@ -0,0 +1,17 @@
+package subman
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// UpdateFacts collects system facts and uploads them to the entitlement server,
+// refreshing the facts of the system record, e.g. the network.fqdn fact. The
+// name of the consumer is changed by [RHSMClient.RenameConsumer].
+func UpdateFacts() error {
+	slog.Debug("Updating system facts")
+	if _, err := runSubscriptionManager("facts", "--update"); err != nil {
+		return fmt.Errorf("updating system facts: %w", err)
+	}
+	return nil
+}
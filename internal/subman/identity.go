@@ -1,14 +1,20 @@
 package subman
 
 import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"os"
 	"strconv"
 	"strings"
 
 	"github.com/godbus/dbus/v5"
 	"github.com/redhatinsights/rhc/internal/localization"
+	"github.com/redhatinsights/rhc/internal/retry"
 )
 
 // RegisterOptions groups the options common to the registration methods.
@@ -32,17 +38,64 @@ func buildOptions(opts RegisterOptions) map[string]string {
 	return options
 }
 
+// ProxyOptions is the proxy RHSM connects to the entitlement server through
+// during registration.
+type ProxyOptions struct {
+	// URL is the proxy URL (e.g. "http://proxy.example.com:3128"), without
+	// credentials.
+	URL      string
+	User     string
+	Password string
+	// NoProxy lists hosts connected to directly, in the format of NO_PROXY.
+	NoProxy []string
+}
+
+// Proxy is the proxy passed to the RHSM registration methods. When Proxy.URL
+// is empty, RHSM uses the proxy configured in rhsm.conf.
+var Proxy ProxyOptions
+
+// Retry is the policy registration methods are retried with, when the
+// entitlement server cannot be reached. By default, they are attempted once.
+var Retry retry.Policy
+
+// isTransient reports whether err of a registration method may not recur, when
+// the method is retried.
+func isTransient(err error) bool {
+	return errors.Is(err, ErrNetwork)
+}
+
+// buildConnectionOptions converts ProxyOptions into the D-Bus connection
+// options map expected by the RHSM registration methods.
+func buildConnectionOptions(proxy ProxyOptions) map[string]string {
+	options := make(map[string]string)
+	proxyURL, err := url.Parse(proxy.URL)
+	if proxy.URL == "" || err != nil || proxyURL.Hostname() == "" {
+		return options
+	}
+	options["proxy_hostname"] = proxyURL.Hostname()
+	if port := proxyURL.Port(); port != "" {
+		options["proxy_port"] = port
+	}
+	if proxy.User != "" {
+		options["proxy_user"] = proxy.User
+		options["proxy_password"] = proxy.Password
+	}
+	if len(proxy.NoProxy) != 0 {
+		options["no_proxy"] = strings.Join(proxy.NoProxy, ",")
+	}
+	return options
+}
+
 // GetConsumerUUID returns the RHSM consumer UUID.
 // Returns [ErrNotRegistered] if the system is not currently registered.
 func (c *RHSMClient) GetConsumerUUID() (string, error) {
 	slog.Debug("Getting consumer UUID")
 	var uuid string
 	locale := localization.GetLocale()
-	err := c.conn.Object(
-		"com.redhat.RHSM1",
-		"/com/redhat/RHSM1/Consumer").Call(
+	err := call(
+		c.conn,
+		"/com/redhat/RHSM1/Consumer",
 		"com.redhat.RHSM1.Consumer.GetUuid",
-		dbus.Flags(0),
 		locale,
 	).Store(&uuid)
 	if err != nil {
@@ -54,6 +107,29 @@ func (c *RHSMClient) GetConsumerUUID() (string, error) {
 	return uuid, nil
 }
 
+// Owner is the organization owning the subscriptions of the system.
+type Owner struct {
+	Key         string `json:"key"`
+	DisplayName string `json:"display_name"`
+}
+
+// GetOwner returns the owner of the subscriptions of the registered system.
+func (c *RHSMClient) GetOwner() (*Owner, error) {
+	slog.Debug("Getting owner of subscriptions")
+	var data string
+	locale := localization.GetLocale()
+	err := call(
+		c.conn,
+		"/com/redhat/RHSM1/Consumer",
+		"com.redhat.RHSM1.Consumer.GetOrg",
+		locale,
+	).Store(&data)
+	if err != nil {
+		return nil, fmt.Errorf("getting owner of subscriptions: %w", newDbusError(err))
+	}
+	return unpackOwner(data)
+}
+
 // IsRegistered reports whether the system is currently registered with RHSM.
 func (c *RHSMClient) IsRegistered() (bool, error) {
 	slog.Debug("Checking if system is registered to Red Hat Subscription Management")
@@ -71,21 +147,21 @@ func (c *RHSMClient) IsRegistered() (bool, error) {
 
 // GetOrganizations returns the list of organization names available for the
 // given username and password.
-func (c *RHSMClient) GetOrganizations(username, password string) ([]string, error) {
+func (c *RHSMClient) GetOrganizations(ctx context.Context, username, password string) ([]string, error) {
 	slog.Debug("Retrieving available organizations")
 
 	var organizations []string
 	getOrganizations := func(privConn *dbus.Conn, locale string) error {
 		slog.Debug("Calling method com.redhat.RHSM1.Register.GetOrgs")
 		var raw string
-		if err := privConn.Object(
-			"com.redhat.RHSM1",
-			"/com/redhat/RHSM1/Register").Call(
+		if err := callContext(
+			ctx,
+			privConn,
+			"/com/redhat/RHSM1/Register",
 			"com.redhat.RHSM1.Register.GetOrgs",
-			dbus.Flags(0),
 			username,
 			password,
-			map[string]string{},
+			buildConnectionOptions(Proxy),
 			locale,
 		).Store(&raw); err != nil {
 			return fmt.Errorf("retrieving available organizations: %w", newDbusError(err))
@@ -100,7 +176,9 @@ func (c *RHSMClient) GetOrganizations(username, password string) ([]string, erro
 		return nil
 	}
 
-	if err := withPrivateRegisterSocket(c.conn, getOrganizations); err != nil {
+	if err := Retry.Do(ctx, "get-organizations", isTransient, func() error {
+		return withPrivateRegisterSocket(ctx, c.conn, getOrganizations)
+	}); err != nil {
 		return nil, err
 	}
 
@@ -113,22 +191,22 @@ func (c *RHSMClient) GetOrganizations(username, password string) ([]string, erro
 // passed in, [ErrOrganizationRequired] is returned; the caller should call
 // [RHSMClient.GetOrganizations] to retrieve the available organization names,
 // prompt the user, and retry with an explicit value.
-func (c *RHSMClient) RegisterWithPassword(username, password, organization string, opts RegisterOptions) error {
+func (c *RHSMClient) RegisterWithPassword(ctx context.Context, username, password, organization string, opts RegisterOptions) error {
 	slog.Debug("Registering system with username and password")
 
 	registerWithPassword := func(privConn *dbus.Conn, locale string) error {
 		options := buildOptions(opts)
 		slog.Debug("Calling method com.redhat.RHSM1.Register.Register")
-		if err := privConn.Object(
-			"com.redhat.RHSM1",
-			"/com/redhat/RHSM1/Register").Call(
+		if err := callContext(
+			ctx,
+			privConn,
+			"/com/redhat/RHSM1/Register",
 			"com.redhat.RHSM1.Register.Register",
-			dbus.Flags(0),
 			organization,
 			username,
 			password,
 			options,
-			map[string]string{},
+			buildConnectionOptions(Proxy),
 			locale,
 		).Err; err != nil {
 			unpacked := newDbusError(err)
@@ -143,13 +221,15 @@ func (c *RHSMClient) RegisterWithPassword(username, password, organization strin
 		return nil
 	}
 
-	return withPrivateRegisterSocket(c.conn, registerWithPassword)
+	return Retry.Do(ctx, "register", isTransient, func() error {
+		return withPrivateRegisterSocket(ctx, c.conn, registerWithPassword)
+	})
 }
 
 // RegisterWithActivationKeys registers the system using activation keys.
 //
 // Returns [ErrOrganizationRequired] if organization is empty.
-func (c *RHSMClient) RegisterWithActivationKeys(organization string, activationKeys []string, opts RegisterOptions) error {
+func (c *RHSMClient) RegisterWithActivationKeys(ctx context.Context, organization string, activationKeys []string, opts RegisterOptions) error {
 	slog.Debug("Registering system with activation keys")
 	if organization == "" {
 		return ErrOrganizationRequired
@@ -158,15 +238,15 @@ func (c *RHSMClient) RegisterWithActivationKeys(organization string, activationK
 	registerWithActivationKeys := func(privConn *dbus.Conn, locale string) error {
 		options := buildOptions(opts)
 		slog.Debug("Calling method com.redhat.RHSM1.Register.RegisterWithActivationKeys")
-		if err := privConn.Object(
-			"com.redhat.RHSM1",
-			"/com/redhat/RHSM1/Register").Call(
+		if err := callContext(
+			ctx,
+			privConn,
+			"/com/redhat/RHSM1/Register",
 			"com.redhat.RHSM1.Register.RegisterWithActivationKeys",
-			dbus.Flags(0),
 			organization,
 			activationKeys,
 			options,
-			map[string]string{},
+			buildConnectionOptions(Proxy),
 			locale,
 		).Err; err != nil {
 			return fmt.Errorf("registering with RHSM: %w", newDbusError(err))
@@ -174,19 +254,21 @@ func (c *RHSMClient) RegisterWithActivationKeys(organization string, activationK
 		return nil
 	}
 
-	return withPrivateRegisterSocket(c.conn, registerWithActivationKeys)
+	return Retry.Do(ctx, "register", isTransient, func() error {
+		return withPrivateRegisterSocket(ctx, c.conn, registerWithActivationKeys)
+	})
 }
 
 // Unregister removes the system's RHSM registration.
-func (c *RHSMClient) Unregister() error {
+func (c *RHSMClient) Unregister(ctx context.Context) error {
 	slog.Debug("Unregistering system from Red Hat Subscription Management")
 	slog.Debug("Calling method com.redhat.RHSM1.Unregister.Unregister")
 	locale := localization.GetLocale()
-	if err := c.conn.Object(
-		"com.redhat.RHSM1",
-		"/com/redhat/RHSM1/Unregister").Call(
+	if err := callContext(
+		ctx,
+		c.conn,
+		"/com/redhat/RHSM1/Unregister",
 		"com.redhat.RHSM1.Unregister.Unregister",
-		dbus.Flags(0),
 		map[string]string{}, // reserved for future use
 		locale,
 	).Err; err != nil {
@@ -195,3 +277,51 @@ func (c *RHSMClient) Unregister() error {
 
 	return nil
 }
+
+// ConsumerCertPath is the path to the consumer (identity) certificate of the system.
+// The certificate is world-readable, unlike its private key.
+const ConsumerCertPath = "/etc/pki/consumer/cert.pem"
+
+// ConsumerKeyPath is the path to the private key of the consumer certificate.
+const ConsumerKeyPath = "/etc/pki/consumer/key.pem"
+
+// CleanLocalData removes the consumer certificate and the entitlement data of
+// the system with 'subscription-manager clean', without contacting the
+// entitlement server. The consumer stays registered at the server.
+func CleanLocalData() error {
+	if _, err := runSubscriptionManager("clean"); err != nil {
+		return fmt.Errorf("cannot clean local data of subscription-manager: %w", err)
+	}
+	return nil
+}
+
+// HasConsumerCertificate reports whether the consumer certificate is present.
+// Unlike [RHSMClient.IsRegistered], it does not require access to the RHSM
+// D-Bus API, which may be restricted to privileged users.
+func HasConsumerCertificate() (bool, error) {
+	_, err := os.Stat(ConsumerCertPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not check consumer certificate: %w", err)
+	}
+	return true, nil
+}
+
+// GetConsumerCertificate parses the consumer certificate of the system.
+func GetConsumerCertificate() (*x509.Certificate, error) {
+	data, err := os.ReadFile(ConsumerCertPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read consumer certificate: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("could not parse consumer certificate: no PEM data found in %s", ConsumerCertPath)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse consumer certificate: %w", err)
+	}
+	return cert, nil
+}
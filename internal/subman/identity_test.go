@@ -0,0 +1,69 @@
+package subman
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildConnectionOptions(t *testing.T) {
+	tests := []struct {
+		description string
+		input       ProxyOptions
+		want        map[string]string
+	}{
+		{
+			description: "no proxy",
+			input:       ProxyOptions{},
+			want:        map[string]string{},
+		},
+		{
+			description: "proxy",
+			input:       ProxyOptions{URL: "http://proxy.example.com:3128", NoProxy: []string{"satellite.lan", ".example.com"}},
+			want: map[string]string{
+				"proxy_hostname": "proxy.example.com",
+				"proxy_port":     "3128",
+				"no_proxy":       "satellite.lan,.example.com",
+			},
+		},
+		{
+			description: "proxy with credentials",
+			input:       ProxyOptions{URL: "http://proxy.example.com", User: "admin", Password: "s3cret"},
+			want: map[string]string{
+				"proxy_hostname": "proxy.example.com",
+				"proxy_user":     "admin",
+				"proxy_password": "s3cret",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := buildConnectionOptions(test.input)
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		description string
+		err         error
+		want        bool
+	}{
+		{description: "connection", err: fmt.Errorf("registering with RHSM: %w", dbusError{Exception: "ConnectionException"}), want: true},
+		{description: "authentication", err: fmt.Errorf("registering with RHSM: %w", dbusError{Exception: "UnauthorizedException"})},
+		{description: "organization required", err: ErrOrganizationRequired},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if got := isTransient(test.err); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package subman
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/redhatinsights/rhc/internal/logging"
+)
+
+// subscriptionManagerPath is the path to the subscription-manager executable.
+// The com.redhat.RHSM1 D-Bus API does not provide an interface for managing
+// the release version or updating facts, so these operations are passed
+// through to the CLI.
+const subscriptionManagerPath = "/usr/sbin/subscription-manager"
+
+// subscriptionManagerCommand returns the command executing subscription-manager
+// with given arguments. The output is not translated, so it can be parsed.
+func subscriptionManagerCommand(args ...string) *exec.Cmd {
+	cmd := exec.Command(subscriptionManagerPath, args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C.UTF-8")
+	return cmd
+}
+
+// runSubscriptionManager executes subscription-manager with given arguments and
+// returns its standard output. When the command fails, the error contains
+// the standard error output of subscription-manager.
+func runSubscriptionManager(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	slog.Debug(fmt.Sprintf("Executing %s %s", subscriptionManagerPath, strings.Join(args, " ")))
+	cmd := subscriptionManagerCommand(args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := logging.CommandStart(cmd)
+	err := cmd.Run()
+	logging.Command(cmd, start, err)
+	if err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) && stderr.Len() > 0 {
+			return "", fmt.Errorf("%s", strings.TrimSpace(stderr.String()))
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// parseRelease extracts the release version from the output of
+// 'subscription-manager release --show' in the C locale. An empty string is
+// returned when the release is not set.
+func parseRelease(output string) string {
+	output = strings.TrimSpace(output)
+	if version, found := strings.CutPrefix(output, "Release:"); found {
+		return strings.TrimSpace(version)
+	}
+	return ""
+}
+
+// GetRelease returns the release version the system is locked to.
+// An empty string is returned when the release is not set.
+func GetRelease() (string, error) {
+	slog.Debug("Getting release version")
+	output, err := runSubscriptionManager("release", "--show")
+	if err != nil {
+		return "", fmt.Errorf("getting release version: %w", err)
+	}
+	return parseRelease(output), nil
+}
+
+// SetRelease locks the system to the given release version.
+func SetRelease(version string) error {
+	slog.Debug("Setting release version", "release", version)
+	if _, err := runSubscriptionManager("release", "--set="+version); err != nil {
+		return fmt.Errorf("setting release version: %w", err)
+	}
+	return nil
+}
+
+// UnsetRelease removes the release version lock of the system.
+func UnsetRelease() error {
+	slog.Debug("Unsetting release version")
+	if _, err := runSubscriptionManager("release", "--unset"); err != nil {
+		return fmt.Errorf("unsetting release version: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package subman
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRelease(t *testing.T) {
+	tests := []struct {
+		description string
+		output      string
+		want        string
+	}{
+		{
+			description: "release set",
+			output:      "Release: 9.2\n",
+			want:        "9.2",
+		},
+		{
+			description: "translated output",
+			output:      "Vydání: 9.2\n",
+			want:        "",
+		},
+		{
+			description: "release not set",
+			output:      "Release not set\n",
+			want:        "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := parseRelease(test.output)
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionManagerCommandLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "cs_CZ.UTF-8")
+	cmd := subscriptionManagerCommand("release", "--show")
+
+	// The last value of a variable set more than once is used
+	var got string
+	for _, variable := range cmd.Env {
+		if value, found := strings.CutPrefix(variable, "LC_ALL="); found {
+			got = value
+		}
+	}
+	if got != "C.UTF-8" {
+		t.Errorf("got LC_ALL=%q, want %q", got, "C.UTF-8")
+	}
+}
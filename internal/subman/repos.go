@@ -0,0 +1,135 @@
+package subman
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RedHatRepoPath is the path to the repository file generated by subscription-manager
+// from the entitlement certificates of the system.
+const RedHatRepoPath = "/etc/yum.repos.d/redhat.repo"
+
+// Repository describes a single repository defined in redhat.repo.
+type Repository struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	BaseURL string `json:"baseurl"`
+	Enabled bool   `json:"enabled"`
+	// MirrorList and Metalink are URLs of lists of mirrors of the repository.
+	MirrorList string `json:"-"`
+	Metalink   string `json:"-"`
+	// SSLClientCert, SSLClientKey and SSLCACert are paths to the entitlement
+	// certificate, its key, and the CA certificate used to access the repository.
+	SSLClientCert string `json:"-"`
+	SSLClientKey  string `json:"-"`
+	SSLCACert     string `json:"-"`
+}
+
+// ReadRepositories returns the repositories defined in the repository file at path.
+// A missing file is not an error; subscription-manager does not generate
+// the file until the system is registered with content enabled.
+func ReadRepositories(path string) ([]Repository, error) {
+	slog.Debug("Reading repositories", "path", path)
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Repository{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading repositories: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	repos, err := parseRepositories(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading repositories from %s: %w", path, err)
+	}
+	return repos, nil
+}
+
+// RepositoryDir is the directory of repository files of dnf.
+const RepositoryDir = "/etc/yum.repos.d"
+
+// ReadRepositoryDir returns the repositories defined in the repository files
+// ("*.repo") in dir, in the order of the names of the files.
+func ReadRepositoryDir(dir string) ([]Repository, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.repo"))
+	if err != nil {
+		return nil, fmt.Errorf("reading repositories: %w", err)
+	}
+	repos := []Repository{}
+	for _, path := range paths {
+		fileRepos, err := ReadRepositories(path)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, fileRepos...)
+	}
+	return repos, nil
+}
+
+// parseRepositories parses yum repository file in INI format. Repositories are
+// enabled, unless they are disabled explicitly, as they are by dnf.
+func parseRepositories(r io.Reader) ([]Repository, error) {
+	repos := []Repository{}
+	var current *Repository
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			repos = append(repos, Repository{ID: strings.TrimSpace(line[1 : len(line)-1]), Enabled: true})
+			current = &repos[len(repos)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "name":
+			current.Name = value
+		case "baseurl":
+			current.BaseURL = value
+		case "mirrorlist":
+			current.MirrorList = value
+		case "metalink":
+			current.Metalink = value
+		case "enabled":
+			current.Enabled = value == "1" || strings.EqualFold(value, "true")
+		case "sslclientcert":
+			current.SSLClientCert = value
+		case "sslclientkey":
+			current.SSLClientKey = value
+		case "sslcacert":
+			current.SSLCACert = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// CountEnabledRepositories returns the number of enabled repositories.
+func CountEnabledRepositories(repos []Repository) int {
+	count := 0
+	for _, repo := range repos {
+		if repo.Enabled {
+			count++
+		}
+	}
+	return count
+}
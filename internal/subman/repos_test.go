@@ -0,0 +1,77 @@
+package subman
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseRepositories(t *testing.T) {
+	input := `#
+# Certificate-Based Repositories
+# Managed by (rhsm) subscription-manager
+#
+[rhel-9-for-x86_64-baseos-rpms]
+name = Red Hat Enterprise Linux 9 for x86_64 - BaseOS (RPMs)
+baseurl = https://cdn.redhat.com/content/dist/rhel9/$releasever/x86_64/baseos/os
+enabled = 1
+gpgcheck = 1
+sslclientkey = /etc/pki/entitlement/123-key.pem
+sslclientcert = /etc/pki/entitlement/123.pem
+sslcacert = /etc/rhsm/ca/redhat-uep.pem
+
+[rhel-9-for-x86_64-baseos-debug-rpms]
+name = Red Hat Enterprise Linux 9 for x86_64 - BaseOS (Debug RPMs)
+baseurl = https://cdn.redhat.com/content/dist/rhel9/$releasever/x86_64/baseos/debug
+enabled = 0
+
+[epel]
+name = Extra Packages for Enterprise Linux 9 - $basearch
+metalink = https://mirrors.fedoraproject.org/metalink?repo=epel-9&arch=$basearch
+`
+	want := []Repository{
+		{
+			ID:            "rhel-9-for-x86_64-baseos-rpms",
+			Name:          "Red Hat Enterprise Linux 9 for x86_64 - BaseOS (RPMs)",
+			BaseURL:       "https://cdn.redhat.com/content/dist/rhel9/$releasever/x86_64/baseos/os",
+			Enabled:       true,
+			SSLClientCert: "/etc/pki/entitlement/123.pem",
+			SSLClientKey:  "/etc/pki/entitlement/123-key.pem",
+			SSLCACert:     "/etc/rhsm/ca/redhat-uep.pem",
+		},
+		{
+			ID:      "rhel-9-for-x86_64-baseos-debug-rpms",
+			Name:    "Red Hat Enterprise Linux 9 for x86_64 - BaseOS (Debug RPMs)",
+			BaseURL: "https://cdn.redhat.com/content/dist/rhel9/$releasever/x86_64/baseos/debug",
+			Enabled: false,
+		},
+		{
+			ID:       "epel",
+			Name:     "Extra Packages for Enterprise Linux 9 - $basearch",
+			Metalink: "https://mirrors.fedoraproject.org/metalink?repo=epel-9&arch=$basearch",
+			Enabled:  true,
+		},
+	}
+
+	got, err := parseRepositories(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+	if count := CountEnabledRepositories(got); count != 2 {
+		t.Errorf("got %d enabled repositories, want 2", count)
+	}
+}
+
+func TestReadRepositoriesMissingFile(t *testing.T) {
+	got, err := ReadRepositories(t.TempDir() + "/redhat.repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d repositories, want 0", len(got))
+	}
+}
@@ -1,6 +1,10 @@
 package subman
 
-import "github.com/godbus/dbus/v5"
+import (
+	"context"
+
+	"github.com/godbus/dbus/v5"
+)
 
 // Service defines the contract for subscription-manager D-Bus operations.
 // The concrete implementation is [RHSMClient]. A mock implementation can be
@@ -21,20 +25,20 @@ type Service interface {
 	SetContentManagement(enabled bool) error
 
 	// Unregister removes the system's RHSM registration.
-	Unregister() error
+	Unregister(ctx context.Context) error
 
 	// RegisterWithPassword registers the system using username/password credentials.
 	// Returns [ErrOrganizationRequired] if the account belongs to multiple
 	// organizations and none was specified; the caller should call
 	// [Service.GetOrganizations] and retry with an explicit value.
-	RegisterWithPassword(username, password, organization string, opts RegisterOptions) error
+	RegisterWithPassword(ctx context.Context, username, password, organization string, opts RegisterOptions) error
 
 	// RegisterWithActivationKeys registers the system using activation keys.
 	// Returns [ErrOrganizationRequired] if organization is empty.
-	RegisterWithActivationKeys(organization string, activationKeys []string, opts RegisterOptions) error
+	RegisterWithActivationKeys(ctx context.Context, organization string, activationKeys []string, opts RegisterOptions) error
 
 	// GetOrganizations returns the organization keys available for the credentials.
-	GetOrganizations(username, password string) ([]string, error)
+	GetOrganizations(ctx context.Context, username, password string) ([]string, error)
 }
 
 // RHSMClient implements [Service] using D-Bus calls to subscription-manager.
@@ -0,0 +1,51 @@
+package subman
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/redhatinsights/rhc/internal/localization"
+)
+
+// Syspurpose is the system purpose of the system. It is sent to the entitlement
+// server on registration, which selects subscriptions of the system by it.
+type Syspurpose struct {
+	Role         string `json:"role,omitempty"`
+	ServiceLevel string `json:"service_level_agreement,omitempty"`
+	Usage        string `json:"usage,omitempty"`
+}
+
+// values returns the attributes of purpose, which are set, keyed by their names
+// in the syspurpose file of RHSM.
+func (purpose Syspurpose) values() map[string]dbus.Variant {
+	values := make(map[string]dbus.Variant)
+	for key, value := range map[string]string{
+		"role":                    purpose.Role,
+		"service_level_agreement": purpose.ServiceLevel,
+		"usage":                   purpose.Usage,
+	} {
+		if value != "" {
+			values[key] = dbus.MakeVariant(value)
+		}
+	}
+	return values
+}
+
+// SetSyspurpose sets the attributes of the system purpose, which are set in
+// purpose; the other attributes are kept.
+func (c *RHSMClient) SetSyspurpose(purpose Syspurpose) error {
+	slog.Debug("Setting system purpose", "role", purpose.Role, "sla", purpose.ServiceLevel, "usage", purpose.Usage)
+	err := call(
+		c.conn,
+		"/com/redhat/RHSM1/Syspurpose",
+		"com.redhat.RHSM1.Syspurpose.SetSyspurpose",
+		purpose.values(),
+		localization.GetLocale(),
+	).Err
+	if err != nil {
+		return fmt.Errorf("setting system purpose: %w", newDbusError(err))
+	}
+	return nil
+}
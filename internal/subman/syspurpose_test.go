@@ -0,0 +1,40 @@
+package subman
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSyspurposeValues(t *testing.T) {
+	tests := []struct {
+		description string
+		purpose     Syspurpose
+		want        map[string]dbus.Variant
+	}{
+		{
+			description: "all attributes",
+			purpose:     Syspurpose{Role: "Red Hat Enterprise Linux Server", ServiceLevel: "Premium", Usage: "Production"},
+			want: map[string]dbus.Variant{
+				"role":                    dbus.MakeVariant("Red Hat Enterprise Linux Server"),
+				"service_level_agreement": dbus.MakeVariant("Premium"),
+				"usage":                   dbus.MakeVariant("Production"),
+			},
+		},
+		{
+			description: "usage only",
+			purpose:     Syspurpose{Usage: "Development/Test"},
+			want:        map[string]dbus.Variant{"usage": dbus.MakeVariant("Development/Test")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := test.purpose.values()
+			if !cmp.Equal(got, test.want, cmp.Comparer(func(a, b dbus.Variant) bool { return a.String() == b.String() })) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
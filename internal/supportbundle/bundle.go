@@ -0,0 +1,68 @@
+// Package supportbundle writes the logs and configuration of rhc and the
+// services it manages to an archive, which can be attached to a support case.
+package supportbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File is a file of the support bundle.
+type File struct {
+	// Name is the path of the file in the bundle.
+	Name string
+	Data []byte
+}
+
+// Write writes files with secrets redacted to a gzip-compressed tar archive at
+// path. Logs identify the system, so the archive is readable only by its owner.
+func Write(path string, files []File, modTime time.Time) (err error) {
+	temp, err := os.CreateTemp(filepath.Dir(path), ".rhc-logs-*")
+	if err != nil {
+		return fmt.Errorf("cannot create support bundle: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = temp.Close()
+			_ = os.Remove(temp.Name())
+		}
+	}()
+
+	compressed := gzip.NewWriter(temp)
+	archive := tar.NewWriter(compressed)
+	for _, file := range files {
+		data := Redact(file.Data)
+		header := &tar.Header{
+			Name:    file.Name,
+			Mode:    0600,
+			Size:    int64(len(data)),
+			ModTime: modTime,
+		}
+		if err = archive.WriteHeader(header); err != nil {
+			return fmt.Errorf("cannot write %s to support bundle: %w", file.Name, err)
+		}
+		if _, err = archive.Write(data); err != nil {
+			return fmt.Errorf("cannot write %s to support bundle: %w", file.Name, err)
+		}
+	}
+	if err = archive.Close(); err != nil {
+		return fmt.Errorf("cannot write support bundle: %w", err)
+	}
+	if err = compressed.Close(); err != nil {
+		return fmt.Errorf("cannot write support bundle: %w", err)
+	}
+	if err = temp.Sync(); err != nil {
+		return fmt.Errorf("cannot write support bundle: %w", err)
+	}
+	if err = temp.Close(); err != nil {
+		return fmt.Errorf("cannot write support bundle: %w", err)
+	}
+	if err = os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("cannot write support bundle: %w", err)
+	}
+	return nil
+}
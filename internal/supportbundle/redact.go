@@ -0,0 +1,42 @@
+package supportbundle
+
+import (
+	"regexp"
+)
+
+// redacted replaces secrets removed from the support bundle. It is the
+// replacement of passwords used by url.URL.Redacted.
+const redacted = "xxxxx"
+
+var (
+	// urlPasswordPattern matches the password of URLs, e.g. of proxies.
+	urlPasswordPattern = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@]*):[^/\s@]+@`)
+	// secretValuePattern matches assignments of secrets in configuration files
+	// and logs, e.g. 'proxy_password = secret' or 'password=secret'.
+	secretValuePattern = regexp.MustCompile(
+		`(?i)([\w.-]*(?:password|passwd|passphrase|secret|token|activation[_-]?keys?)[\w.-]*"?\s*[=:]\s*)` +
+			`("[^"]*"|'[^']*'|\[[^\]]*\]|\S+)`,
+	)
+	// secretKeyValuePattern matches values of secret keys logged or audited
+	// as separate attributes, e.g. 'key=proxy.password value=secret'.
+	secretKeyValuePattern = regexp.MustCompile(
+		`(?i)(key=[\w.-]*(?:password|passwd|passphrase|secret|token)[\w.-]*\s+value=)("[^"]*"|\S+)`,
+	)
+	// authorizationPattern matches credentials of HTTP Authorization headers,
+	// e.g. 'Authorization: Basic dXNlcjpwYXNz'.
+	authorizationPattern = regexp.MustCompile(`(?i)(authorization"?\s*[=:]\s*"?(?:(?:basic|bearer|negotiate)\s+)?)[^\s"]+`)
+	// secretFlagPattern matches secrets passed as flags on command lines, e.g.
+	// '--password secret'.
+	secretFlagPattern = regexp.MustCompile(`(--(?:password|activation-key|passphrase)\s+)\S+`)
+)
+
+// Redact returns data with passwords, tokens, activation keys and other secrets
+// replaced, so it can be attached to a support case.
+func Redact(data []byte) []byte {
+	data = urlPasswordPattern.ReplaceAll(data, []byte("${1}:"+redacted+"@"))
+	data = secretKeyValuePattern.ReplaceAll(data, []byte("${1}"+redacted))
+	data = secretValuePattern.ReplaceAll(data, []byte("${1}"+redacted))
+	data = authorizationPattern.ReplaceAll(data, []byte("${1}"+redacted))
+	data = secretFlagPattern.ReplaceAll(data, []byte("${1}"+redacted))
+	return data
+}
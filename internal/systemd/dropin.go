@@ -0,0 +1,77 @@
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/redhatinsights/rhc/internal/util"
+)
+
+// UnitDir is the directory with unit files and drop-ins of the system administrator.
+var UnitDir = "/etc/systemd/system"
+
+// DropInPath returns the path of the drop-in called name of unit.
+func DropInPath(unit, name string) string {
+	return filepath.Join(UnitDir, unit+".d", name+".conf")
+}
+
+// EnvironmentFilePath returns the path of the environment file of the drop-in
+// called name of unit.
+func EnvironmentFilePath(unit, name string) string {
+	return filepath.Join(UnitDir, unit+".d", name+".env")
+}
+
+// formatEnvironmentDropIn returns a drop-in reading environment variables of a
+// service from envPath. The variables are not set in the drop-in itself, as
+// any user can read the environment of a unit from systemd.
+func formatEnvironmentDropIn(envPath string) string {
+	var b strings.Builder
+	b.WriteString("# This file is managed by rhc; changes will be overwritten.\n")
+	b.WriteString("[Service]\n")
+	_, _ = fmt.Fprintf(&b, "EnvironmentFile=%s\n", strings.ReplaceAll(envPath, "%", "%%"))
+	return b.String()
+}
+
+// formatEnvironmentFile returns an environment file with variables env. Values
+// are double-quoted according to systemd.exec(7).
+func formatEnvironmentFile(env []string) string {
+	var b strings.Builder
+	b.WriteString("# This file is managed by rhc; changes will be overwritten.\n")
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	for _, variable := range env {
+		key, value, _ := strings.Cut(variable, "=")
+		_, _ = fmt.Fprintf(&b, "%s=\"%s\"\n", key, quote.Replace(value))
+	}
+	return b.String()
+}
+
+// WriteEnvironmentDropIn writes the drop-in called name, which sets environment
+// variables env (in the form "KEY=value") of unit. The variables are written
+// to an environment file readable only by root, as they may contain
+// credentials. The drop-in and the file are removed when env is empty. systemd
+// has to be reloaded for the change to take effect.
+func WriteEnvironmentDropIn(unit, name string, env []string) error {
+	path := DropInPath(unit, name)
+	envPath := EnvironmentFilePath(unit, name)
+	if len(env) == 0 {
+		for _, p := range []string{path, envPath} {
+			if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("cannot remove drop-in %s: %w", p, err)
+			}
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot write drop-in %s: %w", path, err)
+	}
+	if err := util.WriteFileAtomic(envPath, []byte(formatEnvironmentFile(env)), 0600); err != nil {
+		return fmt.Errorf("cannot write drop-in %s: %w", envPath, err)
+	}
+	if err := util.WriteFileAtomic(path, []byte(formatEnvironmentDropIn(envPath)), 0644); err != nil {
+		return fmt.Errorf("cannot write drop-in %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package systemd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/logging"
+)
+
+// JournalEntry is a single entry of the systemd journal.
+type JournalEntry struct {
+	Time time.Time
+	// Unit is the systemd unit that logged the entry.
+	Unit    string
+	Message string
+	// Priority is the syslog priority of the entry, from 0 (emerg) to 7 (debug).
+	Priority int
+}
+
+// GetJournalEntries returns entries of the units logged between since and until, oldest first.
+func GetJournalEntries(units []string, since, until time.Time) ([]JournalEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	args := []string{
+		"--output=json", "--no-pager", "--quiet",
+		fmt.Sprintf("--since=@%d", since.Unix()),
+		fmt.Sprintf("--until=@%d", until.Unix()),
+	}
+	for _, unit := range units {
+		args = append(args, "--unit="+unit)
+	}
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := logging.CommandStart(cmd)
+	err := cmd.Run()
+	logging.Command(cmd, start, err)
+	if err != nil {
+		slog.Debug("journalctl command failed", "error", err, "stderr", stderr.String())
+		return nil, fmt.Errorf("journalctl failed: %w (stderr: %s)", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseJournal(stdout.Bytes())
+}
+
+// parseJournal parses the output of 'journalctl --output=json', a JSON document per line.
+func parseJournal(output []byte) ([]JournalEntry, error) {
+	entries := []JournalEntry{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var raw map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &raw); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal journal entry: %w", err)
+		}
+
+		timestamp, _ := raw["__REALTIME_TIMESTAMP"].(string)
+		usec, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid journal timestamp %q", timestamp)
+		}
+		entry := JournalEntry{Time: time.UnixMicro(usec), Priority: 6}
+		entry.Unit, _ = raw["_SYSTEMD_UNIT"].(string)
+		if priority, ok := raw["PRIORITY"].(string); ok {
+			if value, err := strconv.Atoi(priority); err == nil {
+				entry.Priority = value
+			}
+		}
+		switch message := raw["MESSAGE"].(type) {
+		case string:
+			entry.Message = message
+		case []any:
+			// Messages that are not valid UTF-8 are serialized as arrays of bytes
+			data := make([]byte, 0, len(message))
+			for _, b := range message {
+				if value, ok := b.(float64); ok {
+					data = append(data, byte(value))
+				}
+			}
+			entry.Message = strings.ToValidUTF8(string(data), "�")
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
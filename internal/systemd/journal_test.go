@@ -0,0 +1,72 @@
+package systemd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseJournal(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		want        []JournalEntry
+		errContains string
+	}{
+		{
+			name:  "empty input",
+			input: "",
+			want:  []JournalEntry{},
+		},
+		{
+			name: "entries",
+			input: `{"__REALTIME_TIMESTAMP":"1735725600000000","_SYSTEMD_UNIT":"yggdrasil.service","PRIORITY":"3","MESSAGE":"cannot connect to broker"}
+{"__REALTIME_TIMESTAMP":"1735725601000000","_SYSTEMD_UNIT":"insights-client.service","MESSAGE":[104,105,255]}
+`,
+			want: []JournalEntry{
+				{
+					Time:     time.UnixMicro(1735725600000000),
+					Unit:     "yggdrasil.service",
+					Message:  "cannot connect to broker",
+					Priority: 3,
+				},
+				{
+					Time:     time.UnixMicro(1735725601000000),
+					Unit:     "insights-client.service",
+					Message:  "hi�",
+					Priority: 6,
+				},
+			},
+		},
+		{
+			name:        "invalid JSON",
+			input:       `{"invalid json`,
+			errContains: "failed to unmarshal journal entry",
+		},
+		{
+			name:        "missing timestamp",
+			input:       `{"MESSAGE":"hello"}`,
+			errContains: "invalid journal timestamp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJournal([]byte(tt.input))
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("parseJournal() error = %v, want error containing %q", err, tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("%v", cmp.Diff(got, tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package systemd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/redhatinsights/rhc/internal/logging"
+)
+
+// ManageUnitsAction is the polkit action authorizing to start, stop, enable and
+// disable units of the system manager.
+const ManageUnitsAction = "org.freedesktop.systemd1.manage-units"
+
+const (
+	polkitAuthorityPath      = "/org/freedesktop/PolicyKit1/Authority"
+	polkitCheckAuthorization = "org.freedesktop.PolicyKit1.Authority.CheckAuthorization"
+)
+
+// Authorization is the answer of polkit to whether the process is authorized
+// to perform an action.
+type Authorization struct {
+	// Authorized is set, when the action is authorized without authentication.
+	Authorized bool
+	// Challenge is set, when the action is authorized after the user
+	// authenticates (e.g. with the password of an administrator).
+	Challenge bool
+}
+
+// polkitSubject identifies the process, whose authorization is checked.
+type polkitSubject struct {
+	Kind    string
+	Details map[string]dbus.Variant
+}
+
+// CheckAuthorization asks polkit whether this process is authorized to perform
+// action. The user is never asked to authenticate.
+func CheckAuthorization(action string) (Authorization, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return Authorization{}, fmt.Errorf("cannot connect to system D-Bus: %w", err)
+	}
+	subject := polkitSubject{
+		Kind: "unix-process",
+		Details: map[string]dbus.Variant{
+			"pid":        dbus.MakeVariant(uint32(os.Getpid())),
+			"start-time": dbus.MakeVariant(uint64(0)),
+		},
+	}
+	var result struct {
+		Authorized bool
+		Challenge  bool
+		Details    map[string]string
+	}
+	start := logging.DBusCallStart(polkitCheckAuthorization, polkitAuthorityPath, "action", action)
+	err = conn.Object("org.freedesktop.PolicyKit1", polkitAuthorityPath).Call(
+		polkitCheckAuthorization,
+		dbus.Flags(0),
+		subject, action, map[string]string{}, uint32(0), "",
+	).Store(&result)
+	logging.DBusCall(polkitCheckAuthorization, polkitAuthorityPath, start, err, "action", action)
+	if err != nil {
+		return Authorization{}, fmt.Errorf("cannot check authorization of %s: %w", action, err)
+	}
+	return Authorization{Authorized: result.Authorized, Challenge: result.Challenge}, nil
+}
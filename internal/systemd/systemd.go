@@ -5,13 +5,61 @@ package systemd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
 	systemd "github.com/coreos/go-systemd/v22/dbus"
+	"github.com/godbus/dbus/v5"
+
+	"github.com/redhatinsights/rhc/internal/logging"
 )
 
+const (
+	// managerInterface is the D-Bus interface of the systemd manager.
+	managerInterface = "org.freedesktop.systemd1.Manager"
+	// managerPath is the D-Bus object path of the systemd manager.
+	managerPath = "/org/freedesktop/systemd1"
+)
+
+// unitPath returns the D-Bus object path of the named unit.
+func unitPath(name string) string {
+	return managerPath + "/unit/" + systemd.PathBusEscape(name)
+}
+
+// ErrUnitNotFound is matched by errors of operations on units whose unit
+// file does not exist.
+var ErrUnitNotFound = errors.New("unit does not exist")
+
+// noSuchUnitError is the name of the D-Bus error returned by systemd for
+// units that do not exist.
+const noSuchUnitError = "org.freedesktop.systemd1.NoSuchUnit"
+
+// unitError is an error of systemd about a unit, which does not exist. It
+// matches both ErrUnitNotFound and the D-Bus error.
+type unitError struct {
+	err error
+}
+
+func (e *unitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unitError) Unwrap() []error {
+	return []error{e.err, ErrUnitNotFound}
+}
+
+// newUnitError returns err, wrapped so it matches ErrUnitNotFound, when it
+// reports a unit that does not exist.
+func newUnitError(err error) error {
+	var e dbus.Error
+	if errors.As(err, &e) && e.Name == noSuchUnitError {
+		return &unitError{err: err}
+	}
+	return err
+}
+
 type ConnectionType int
 
 const (
@@ -34,7 +82,7 @@ func NewConnectionContext(ctx context.Context, connectionType ConnectionType) (*
 		conn, err = systemd.NewUserConnectionContext(ctx)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("cannot establish connection to systemd: %v", err)
+		return nil, fmt.Errorf("cannot establish connection to systemd: %w", err)
 	}
 
 	return &Conn{
@@ -49,20 +97,26 @@ func (c *Conn) Close() {
 
 // Reload instructs systemd to scan for and reload unit files.
 func (c *Conn) Reload() error {
-	return c.conn.ReloadContext(c.ctx)
+	start := logging.DBusCallStart(managerInterface+".Reload", managerPath)
+	err := c.conn.ReloadContext(c.ctx)
+	logging.DBusCall(managerInterface+".Reload", managerPath, start, err)
+	return err
 }
 
 // EnableUnit enables the named unit. If activate is true, it also starts the
 // unit. If runtime is true, the unit is enabled for the runtime only (/run). If
 // false, it is enabled persistently (/etc).
 func (c *Conn) EnableUnit(name string, activate bool, runtime bool) error {
-	if _, _, err := c.conn.EnableUnitFilesContext(c.ctx, []string{name}, runtime, true); err != nil {
-		return fmt.Errorf("cannot enable unit %v: %v", name, err)
+	start := logging.DBusCallStart(managerInterface+".EnableUnitFiles", managerPath, "unit", name)
+	_, _, err := c.conn.EnableUnitFilesContext(c.ctx, []string{name}, runtime, true)
+	logging.DBusCall(managerInterface+".EnableUnitFiles", managerPath, start, err, "unit", name)
+	if err != nil {
+		return fmt.Errorf("cannot enable unit %v: %w", name, newUnitError(err))
 	}
 
 	if activate {
 		if err := c.StartUnit(name, true); err != nil {
-			return fmt.Errorf("cannot start unit %v: %v", name, err)
+			return fmt.Errorf("cannot start unit %v: %w", name, err)
 		}
 	}
 
@@ -72,12 +126,17 @@ func (c *Conn) EnableUnit(name string, activate bool, runtime bool) error {
 // StartUnit starts the named unit. If wait is true, the method waits until the
 // unit state becomes "active".
 func (c *Conn) StartUnit(name string, wait bool) error {
-	jobComplete := make(chan string)
+	jobComplete := make(chan string, 1)
+	start := logging.DBusCallStart(managerInterface+".StartUnit", managerPath, "unit", name)
 	_, err := c.conn.StartUnitContext(c.ctx, name, "replace", jobComplete)
+	logging.DBusCall(managerInterface+".StartUnit", managerPath, start, err, "unit", name)
+	if err != nil {
+		return fmt.Errorf("cannot start unit %v: %w", name, newUnitError(err))
+	}
+	result, err := c.waitForJob(jobComplete)
 	if err != nil {
-		return fmt.Errorf("cannot start unit %v: %v", name, err)
+		return fmt.Errorf("cannot start unit %v: %w", name, err)
 	}
-	result := <-jobComplete
 	switch result {
 	case "done":
 		// The job successfully started, break to proceed
@@ -99,13 +158,16 @@ func (c *Conn) StartUnit(name string, wait bool) error {
 // unit. If runtime is true, the unit is disabled for the runtime only (/run).
 // If false, it is disabled persistently (/etc).
 func (c *Conn) DisableUnit(name string, deactivate bool, runtime bool) error {
-	if _, err := c.conn.DisableUnitFilesContext(c.ctx, []string{name}, runtime); err != nil {
-		return fmt.Errorf("cannot disable unit %v: %v", name, err)
+	start := logging.DBusCallStart(managerInterface+".DisableUnitFiles", managerPath, "unit", name)
+	_, err := c.conn.DisableUnitFilesContext(c.ctx, []string{name}, runtime)
+	logging.DBusCall(managerInterface+".DisableUnitFiles", managerPath, start, err, "unit", name)
+	if err != nil {
+		return fmt.Errorf("cannot disable unit %v: %w", name, newUnitError(err))
 	}
 
 	if deactivate {
 		if err := c.StopUnit(name, true); err != nil {
-			return fmt.Errorf("cannot stop unit %v: %v", name, err)
+			return fmt.Errorf("cannot stop unit %v: %w", name, err)
 		}
 	}
 
@@ -115,12 +177,17 @@ func (c *Conn) DisableUnit(name string, deactivate bool, runtime bool) error {
 // StopUnit stops the named unit. If wait is true, the method waits until the
 // unit state becomes "inactive".
 func (c *Conn) StopUnit(name string, wait bool) error {
-	jobComplete := make(chan string)
+	jobComplete := make(chan string, 1)
+	start := logging.DBusCallStart(managerInterface+".StopUnit", managerPath, "unit", name)
 	_, err := c.conn.StopUnitContext(c.ctx, name, "replace", jobComplete)
+	logging.DBusCall(managerInterface+".StopUnit", managerPath, start, err, "unit", name)
+	if err != nil {
+		return fmt.Errorf("cannot stop unit %v: %w", name, newUnitError(err))
+	}
+	result, err := c.waitForJob(jobComplete)
 	if err != nil {
-		return fmt.Errorf("cannot stop unit %v: %v", name, err)
+		return fmt.Errorf("cannot stop unit %v: %w", name, err)
 	}
-	result := <-jobComplete
 	switch result {
 	case "done":
 		break
@@ -137,18 +204,60 @@ func (c *Conn) StopUnit(name string, wait bool) error {
 	return nil
 }
 
+// StartTransientService starts command as the transient service name, which is
+// released by systemd once command exits. The method does not wait for command
+// to finish.
+func (c *Conn) StartTransientService(name string, description string, command []string) error {
+	jobComplete := make(chan string, 1)
+	properties := []systemd.Property{
+		systemd.PropDescription(description),
+		systemd.PropType("simple"),
+		systemd.PropExecStart(command, true),
+	}
+	start := logging.DBusCallStart(managerInterface+".StartTransientUnit", managerPath, "unit", name)
+	_, err := c.conn.StartTransientUnitContext(c.ctx, name, "fail", properties, jobComplete)
+	logging.DBusCall(managerInterface+".StartTransientUnit", managerPath, start, err, "unit", name)
+	if err != nil {
+		return fmt.Errorf("cannot start transient unit %v: %w", name, err)
+	}
+	result, err := c.waitForJob(jobComplete)
+	if err != nil {
+		return fmt.Errorf("cannot start transient unit %v: %w", name, err)
+	}
+	if result != "done" {
+		return fmt.Errorf("failed to start transient unit with reason: %v", result)
+	}
+	return nil
+}
+
 // GetUnitProperties returns all properties of the given unit as a map.
 func (c *Conn) GetUnitProperties(name string) (map[string]interface{}, error) {
+	start := logging.DBusCallStart("org.freedesktop.DBus.Properties.GetAll", unitPath(name))
 	props, err := c.conn.GetUnitPropertiesContext(c.ctx, name)
+	logging.DBusCall("org.freedesktop.DBus.Properties.GetAll", unitPath(name), start, err)
 	if err != nil {
 		return nil, fmt.Errorf("cannot get unit properties for %q: %v", name, err)
 	}
 	return props, nil
 }
 
+// GetServiceProperties returns all properties of the given service unit
+// specific to services (e.g. "ExecMainStatus") as a map.
+func (c *Conn) GetServiceProperties(name string) (map[string]interface{}, error) {
+	start := logging.DBusCallStart("org.freedesktop.DBus.Properties.GetAll", unitPath(name))
+	props, err := c.conn.GetUnitTypePropertiesContext(c.ctx, name, "Service")
+	logging.DBusCall("org.freedesktop.DBus.Properties.GetAll", unitPath(name), start, err)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get service properties for %q: %v", name, err)
+	}
+	return props, nil
+}
+
 // GetUnitState checks the given unit's "ActiveState" property.
 func (c *Conn) GetUnitState(name string) (string, error) {
+	start := logging.DBusCallStart("org.freedesktop.DBus.Properties.Get", unitPath(name))
 	prop, err := c.conn.GetUnitPropertyContext(c.ctx, name, "ActiveState")
+	logging.DBusCall("org.freedesktop.DBus.Properties.Get", unitPath(name), start, err)
 	if err != nil {
 		return "", fmt.Errorf("cannot get unit property 'ActiveState': %v", err)
 	}
@@ -159,8 +268,20 @@ func (c *Conn) GetUnitState(name string) (string, error) {
 	return state, nil
 }
 
+// waitForJob returns the result of a job reported to jobComplete, e.g. "done".
+// The error of the context of the connection is returned, when it is done
+// before the job completes.
+func (c *Conn) waitForJob(jobComplete <-chan string) (string, error) {
+	select {
+	case result := <-jobComplete:
+		return result, nil
+	case <-c.ctx.Done():
+		return "", c.ctx.Err()
+	}
+}
+
 // waitForState checks the unit state, waiting until it matches the given state,
-// or the timeout occurs.
+// the timeout occurs, or the context of the connection is done.
 func (c *Conn) waitForState(unit string, wantState string, timeout time.Duration) error {
 	slog.Debug("waiting for unit state", "unit", unit, "wantState", wantState, "timeout", timeout)
 	after := time.After(timeout)
@@ -169,6 +290,8 @@ func (c *Conn) waitForState(unit string, wantState string, timeout time.Duration
 		case <-after:
 			slog.Debug("timed out waiting for unit state", "unit", unit, "wantState", wantState)
 			return fmt.Errorf("timed out waiting %v for unit state '%v'", timeout, wantState)
+		case <-c.ctx.Done():
+			return c.ctx.Err()
 		default:
 			state, err := c.GetUnitState(unit)
 			if err != nil {
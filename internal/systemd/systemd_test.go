@@ -2,9 +2,13 @@ package systemd
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/godbus/dbus/v5"
 )
 
 func TestNewConnectionContext(t *testing.T) {
@@ -300,3 +304,23 @@ func TestIsSystemdAvailable(t *testing.T) {
 		})
 	}
 }
+
+func TestNewUnitError(t *testing.T) {
+	noSuchUnit := dbus.Error{Name: noSuchUnitError, Body: []any{"Unit file rhc-collector.timer does not exist."}}
+	err := fmt.Errorf("cannot enable unit rhc-collector.timer: %w", newUnitError(noSuchUnit))
+	if !errors.Is(err, ErrUnitNotFound) {
+		t.Errorf("got error %v, want %v", err, ErrUnitNotFound)
+	}
+	var dbusErr dbus.Error
+	if !errors.As(err, &dbusErr) || dbusErr.Name != noSuchUnitError {
+		t.Errorf("got error %v, want D-Bus error %s", err, noSuchUnitError)
+	}
+	if err.Error() != "cannot enable unit rhc-collector.timer: Unit file rhc-collector.timer does not exist." {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	accessDenied := dbus.Error{Name: "org.freedesktop.DBus.Error.AccessDenied", Body: []any{"Access denied"}}
+	if errors.Is(newUnitError(accessDenied), ErrUnitNotFound) {
+		t.Error("access denied matches ErrUnitNotFound")
+	}
+}
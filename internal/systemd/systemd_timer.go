@@ -10,6 +10,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/redhatinsights/rhc/internal/logging"
 )
 
 // TimerInfo represents the parsed output of a single systemd timer entry from systemctl list-timers.
@@ -69,7 +71,10 @@ func getSystemdTimerRaw(timer string) (string, error) {
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	start := logging.CommandStart(cmd)
+	err := cmd.Run()
+	logging.Command(cmd, start, err)
+	if err != nil {
 		slog.Debug("systemctl list-timers command failed", "error", err, "stderr", stderr.String())
 		return "", fmt.Errorf("systemctl list-timers failed: %w (stderr: %s)", err, stderr.String())
 	}
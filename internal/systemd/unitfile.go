@@ -0,0 +1,33 @@
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/redhatinsights/rhc/internal/util"
+)
+
+// WriteUnit writes the unit file name into UnitDir. systemd has to be reloaded
+// for the change to take effect.
+func WriteUnit(name string, content string) error {
+	path := filepath.Join(UnitDir, name)
+	if err := os.MkdirAll(UnitDir, 0755); err != nil {
+		return fmt.Errorf("cannot write unit %s: %w", path, err)
+	}
+	if err := util.WriteFileAtomic(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("cannot write unit %s: %w", path, err)
+	}
+	return nil
+}
+
+// RemoveUnit removes the unit file name from UnitDir. A missing unit file is
+// not an error.
+func RemoveUnit(name string) error {
+	path := filepath.Join(UnitDir, name)
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cannot remove unit %s: %w", path, err)
+	}
+	return nil
+}
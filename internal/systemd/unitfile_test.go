@@ -0,0 +1,36 @@
+package systemd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteUnit(t *testing.T) {
+	UnitDir = t.TempDir()
+	t.Cleanup(func() { UnitDir = "/etc/systemd/system" })
+
+	content := "[Timer]\nOnUnitActiveSec=86400s\n"
+	if err := WriteUnit("rhc-checkin.timer", content); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(UnitDir, "rhc-checkin.timer")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("got %q, want %q", string(data), content)
+	}
+
+	if err = RemoveUnit("rhc-checkin.timer"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("unit was not removed: %v", err)
+	}
+	// Removing a missing unit is not an error
+	if err = RemoveUnit("rhc-checkin.timer"); err != nil {
+		t.Fatal(err)
+	}
+}
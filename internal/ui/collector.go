@@ -6,47 +6,26 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/redhatinsights/rhc/internal/localization"
 	"github.com/redhatinsights/rhc/varlink/collectorapi"
 )
 
 const timeFormat = "Mon 2006-01-02 15:04 MST"
 
-// formatRelativeTime converts a duration into a human-readable relative time string.
-func formatRelativeTime(d time.Duration) string {
-	if d < 0 {
-		d = -d
-	}
-
-	hours := int(d.Hours())
-	minutes := int(d.Minutes()) % 60
-	if hours > 24 {
-		days := hours / 24
-		if hours%24 > 0 {
-			return fmt.Sprintf("%dd %dh", days, hours%24)
-		}
-		return fmt.Sprintf("%dd", days)
-	}
-	if hours > 0 {
-		if minutes > 0 {
-			return fmt.Sprintf("%dh %dm", hours, minutes)
-		}
-		return fmt.Sprintf("%dh", hours)
-	}
-	if minutes > 0 {
-		return fmt.Sprintf("%dm", minutes)
-	}
-
-	seconds := int(d.Seconds())
-	return fmt.Sprintf("%ds", seconds)
-}
-
 // printMachineReadable marshals data to JSON and prints it to stdout.
 func printMachineReadable(data interface{}) {
 	if slice, ok := data.([]*collectorapi.CollectorInfo); ok && len(slice) == 0 {
 		fmt.Println("{}")
 		return
 	}
-	jsonData, err := json.Marshal(data)
+	// Collectors are listed on a single line, unless indentation is requested
+	var jsonData []byte
+	var err error
+	if jsonStyle == JSONPretty {
+		jsonData, err = json.MarshalIndent(data, "", "    ")
+	} else {
+		jsonData, err = json.Marshal(data)
+	}
 	if err != nil {
 		slog.Error("Failed to marshal data to JSON", "error", err)
 		return
@@ -72,14 +51,14 @@ func PrintCollectorInfo(info *collectorapi.CollectorInfo) {
 
 	if info.LastRun != nil {
 		lastRunTime := time.Unix(int64(*info.LastRun), 0)
-		relativeTime := formatRelativeTime(time.Since(lastRunTime))
-		fmt.Printf("Last run:  %s (%s ago)\n", lastRunTime.Format(timeFormat), relativeTime)
+		relativeTime := localization.FormatTimeAgo(localization.GetLocale(), time.Since(lastRunTime))
+		fmt.Printf("Last run:  %s (%s)\n", lastRunTime.Format(timeFormat), relativeTime)
 	} else {
 		fmt.Printf("Last run:  -\n")
 	}
 	if info.NextRun != nil {
 		nextRunTime := time.Unix(int64(*info.NextRun), 0)
-		relativeTime := formatRelativeTime(time.Until(nextRunTime))
+		relativeTime := localization.FormatTimeUntil(localization.GetLocale(), time.Until(nextRunTime))
 		fmt.Printf("Next run:  %s (%s)\n\n", nextRunTime.Format(timeFormat), relativeTime)
 	} else {
 		fmt.Printf("Next run:  -\n\n")
@@ -109,12 +88,12 @@ func PrintCollectorTimers(infos []*collectorapi.CollectorInfo) {
 		lastRun := "-"
 		if info.LastRun != nil {
 			lastRunTime := time.Unix(int64(*info.LastRun), 0)
-			lastRun = formatRelativeTime(time.Since(lastRunTime))
+			lastRun = localization.FormatTimeAgo(localization.GetLocale(), time.Since(lastRunTime))
 		}
 		nextRun := "-"
 		if info.NextRun != nil {
 			nextRunTime := time.Unix(int64(*info.NextRun), 0)
-			nextRun = formatRelativeTime(time.Until(nextRunTime))
+			nextRun = localization.FormatTimeUntil(localization.GetLocale(), time.Until(nextRunTime))
 		}
 		rows = append(rows, []string{info.Id, lastRun, nextRun})
 	}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/briandowns/spinner"
+)
+
+// Progress tracks an operation made of a known number of steps. While the
+// progress is active, every spinner is a step of the operation, and displays
+// the number of the step and the time elapsed since the operation started,
+// e.g. "[⣾] (2/3, 12s) Connecting to Red Hat Lightspeed...". The single line
+// of the spinner then communicates the overall completion.
+type Progress struct {
+	mu      sync.Mutex
+	total   int
+	current int
+	started time.Time
+}
+
+// activeProgress is the progress of the running operation, or nil.
+var activeProgress *Progress
+
+// StartProgress starts the progress of an operation made of total steps.
+// The progress has to be stopped by Stop when the operation ends.
+func StartProgress(total int) *Progress {
+	progress := &Progress{total: total, started: time.Now()}
+	activeProgress = progress
+	return progress
+}
+
+// Stop ends the progress, so spinners are displayed without it again.
+func (p *Progress) Stop() {
+	if activeProgress == p {
+		activeProgress = nil
+	}
+}
+
+// next advances the progress to the next step. The number of the step does not
+// exceed the total, when the operation has more steps than expected.
+func (p *Progress) next() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current < p.total {
+		p.current++
+	}
+}
+
+// label returns the number of the current step and the time elapsed since the
+// start of the operation at now, e.g. "(2/3, 12s)".
+func (p *Progress) label(now time.Time) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return fmt.Sprintf("(%d/%d, %s)", p.current, p.total, now.Sub(p.started).Round(time.Second))
+}
+
+// NewSpinner returns a spinner displaying message, which has to be started by
+// the caller. When a progress is active, the spinner is the next step of it.
+func NewSpinner(prefix string, message string) *spinner.Spinner {
+	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+	s.Prefix = prefix + "["
+	s.Suffix = "]" + " " + message
+	if progress := activeProgress; progress != nil {
+		progress.next()
+		s.Suffix = "] " + progress.label(time.Now()) + " " + message
+		s.PreUpdate = func(s *spinner.Spinner) {
+			s.Suffix = "] " + progress.label(time.Now()) + " " + message
+		}
+	}
+	return s
+}
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	progress := StartProgress(2)
+	defer progress.Stop()
+
+	s := NewSpinner(Indent.Small, "Connecting...")
+	if want := "] (1/2, 0s) Connecting..."; s.Suffix != want {
+		t.Errorf("got suffix %q, want %q", s.Suffix, want)
+	}
+	_ = NewSpinner(Indent.Small, "Activating...")
+	_ = NewSpinner(Indent.Small, "Unexpected step...")
+	if got, want := progress.label(progress.started.Add(75*time.Second)), "(2/2, 1m15s)"; got != want {
+		t.Errorf("got label %q, want %q", got, want)
+	}
+
+	progress.Stop()
+	s = NewSpinner(Indent.Small, "Disconnecting...")
+	if strings.Contains(s.Suffix, "(") || s.PreUpdate != nil {
+		t.Errorf("got suffix %q after the progress stopped, want no progress", s.Suffix)
+	}
+}
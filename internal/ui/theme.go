@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultThemeName is the name of the theme used when no theme is configured.
+const DefaultThemeName = "default"
+
+// Theme holds escape sequences and symbols used to decorate the output.
+type Theme struct {
+	Green   string
+	Yellow  string
+	Red     string
+	Ok      string
+	Info    string
+	Warning string
+	Error   string
+}
+
+// themes contains built-in theme presets that can be selected by name
+// using the 'ui.theme' configuration key.
+var themes = map[string]Theme{
+	DefaultThemeName: {
+		Green:   colorGreen,
+		Yellow:  colorYellow,
+		Red:     colorRed,
+		Ok:      "✓",
+		Info:    "●",
+		Warning: "!",
+		Error:   "𐄂",
+	},
+	// colorblind replaces red/green pair with blue/orange, which is
+	// distinguishable with the most common forms of color vision deficiency.
+	"colorblind": {
+		Green:   "\u001B[34m",
+		Yellow:  "\u001B[36m",
+		Red:     "\u001B[38;5;208m",
+		Ok:      "✓",
+		Info:    "●",
+		Warning: "!",
+		Error:   "𐄂",
+	},
+	// ascii uses symbols that are displayed correctly on any terminal.
+	"ascii": {
+		Green:   colorGreen,
+		Yellow:  colorYellow,
+		Red:     colorRed,
+		Ok:      "+",
+		Info:    "*",
+		Warning: "!",
+		Error:   "x",
+	},
+}
+
+// ThemeNames returns sorted names of built-in themes.
+func ThemeNames() []string {
+	var names []string
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewTheme returns built-in theme identified by name with escape sequences
+// and symbols replaced by values from colors and symbols. Keys of colors are
+// "green", "yellow" and "red"; keys of symbols are "ok", "info", "warning"
+// and "error". An empty name selects the default theme.
+func NewTheme(name string, colors map[string]string, symbols map[string]string) (Theme, error) {
+	if name == "" {
+		name = DefaultThemeName
+	}
+	theme, ok := themes[name]
+	if !ok {
+		return themes[DefaultThemeName], fmt.Errorf(
+			"unknown theme '%s' (supported themes: %s)", name, strings.Join(ThemeNames(), ", "),
+		)
+	}
+
+	for key, value := range colors {
+		switch key {
+		case "green":
+			theme.Green = value
+		case "yellow":
+			theme.Yellow = value
+		case "red":
+			theme.Red = value
+		default:
+			return themes[DefaultThemeName], fmt.Errorf("unknown theme color '%s'", key)
+		}
+	}
+	for key, value := range symbols {
+		switch key {
+		case "ok":
+			theme.Ok = value
+		case "info":
+			theme.Info = value
+		case "warning":
+			theme.Warning = value
+		case "error":
+			theme.Error = value
+		default:
+			return themes[DefaultThemeName], fmt.Errorf("unknown theme symbol '%s'", key)
+		}
+	}
+	return theme, nil
+}
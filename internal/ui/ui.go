@@ -6,10 +6,11 @@ import (
 	"log/slog"
 	"os"
 	"text/tabwriter"
-	"time"
 
 	"github.com/briandowns/spinner"
 	"golang.org/x/sys/unix"
+
+	"github.com/redhatinsights/rhc/internal/cleanup"
 )
 
 const (
@@ -39,10 +40,40 @@ type icons struct {
 var Icons icons
 var isOutputRich bool
 var isOutputMachineReadable bool
+var jsonStyle JSONStyle
+
+// JSONStyle is the layout of JSON documents of machine-readable output.
+type JSONStyle string
+
+const (
+	// JSONDefault keeps the layout each document is printed in by default.
+	JSONDefault JSONStyle = ""
+	// JSONPretty indents documents, e.g. for humans debugging the output.
+	JSONPretty JSONStyle = "pretty"
+	// JSONCompact prints every document on a single line, e.g. for log shippers.
+	JSONCompact JSONStyle = "compact"
+)
+
+// Settings describes how information is communicated to the user during
+// a single invocation of the program.
+type Settings struct {
+	// Rich represents the output's ability to display animations or colors.
+	Rich bool
+	// Colored represents the user's preference to display colors, and requires Rich to be true.
+	Colored bool
+	// MachineReadable is true when the output is formatted as JSON or similar machine-readable format.
+	MachineReadable bool
+	// Theme holds escape sequences and symbols; the zero value selects the default theme.
+	Theme Theme
+	// NoTruncate disables truncation and wrapping of long messages to the terminal width.
+	NoTruncate bool
+	// JSONStyle is the layout of JSON documents of machine-readable output.
+	JSONStyle JSONStyle
+}
 
 func init() {
 	// Default to colored and animated terminal experience
-	ConfigureOutput(true, true, false)
+	ConfigureOutput(Settings{Rich: true, Colored: true})
 }
 
 // IsInteractive returns true if the standard output is a terminal.
@@ -50,6 +81,12 @@ func IsInteractive() bool {
 	return isTerminal(os.Stdout.Fd())
 }
 
+// CanPrompt returns true if the user can be prompted for input, i.e. both the
+// standard input and the standard output are terminals.
+func CanPrompt() bool {
+	return isTerminal(os.Stdin.Fd()) && IsInteractive()
+}
+
 // isTerminal returns true if the file descriptor is a terminal.
 func isTerminal(fd uintptr) bool {
 	_, err := unix.IoctlGetTermios(int(fd), unix.TCGETS)
@@ -57,29 +94,30 @@ func isTerminal(fd uintptr) bool {
 }
 
 // ConfigureOutput sets up a global state for communicating information to the user.
-// 'rich' represents the output's ability to display animations or colors,
-// 'colored' represents the user's preference to display colors, and requires 'rich' to be true,
-// 'machine' is true when the output is formatted as JSON or similar machine-readable format.
-func ConfigureOutput(rich bool, colored bool, machine bool) {
-	if machine {
-		isOutputMachineReadable = true
-		isOutputRich = false
-	}
-	if rich {
-		isOutputRich = true
+// The whole state is replaced, so calling it repeatedly with the same settings
+// always yields the same result.
+func ConfigureOutput(settings Settings) {
+	isOutputMachineReadable = settings.MachineReadable
+	isOutputRich = settings.Rich && !settings.MachineReadable
+	isTruncationEnabled = !settings.NoTruncate
+	jsonStyle = settings.JSONStyle
+
+	theme := settings.Theme
+	if theme == (Theme{}) {
+		theme = themes[DefaultThemeName]
 	}
 
 	Icons = icons{
-		Ok:      "✓",
-		Info:    "●",
-		Warning: "!",
-		Error:   "𐄂",
+		Ok:      theme.Ok,
+		Info:    theme.Info,
+		Warning: theme.Warning,
+		Error:   theme.Error,
 	}
-	if rich && colored {
-		Icons.Ok = colorGreen + Icons.Ok + colorReset
-		Icons.Info = colorYellow + Icons.Info + colorReset
-		Icons.Error = colorRed + Icons.Error + colorReset
-		Icons.Warning = colorRed + Icons.Warning + colorReset
+	if isOutputRich && settings.Colored {
+		Icons.Ok = theme.Green + Icons.Ok + colorReset
+		Icons.Info = theme.Yellow + Icons.Info + colorReset
+		Icons.Error = theme.Red + Icons.Error + colorReset
+		Icons.Warning = theme.Red + Icons.Warning + colorReset
 	}
 }
 
@@ -108,7 +146,8 @@ func Printf(
 }
 
 // Spinner calls a function and displays a spinner with an explanatory message.
-// The spinner is not displayed if the output isn't a rich terminal.
+// The spinner is not displayed if the output isn't a rich terminal. While a
+// progress is active, the function is the next step of it.
 func Spinner(
 	function func() error,
 	prefix string,
@@ -116,20 +155,31 @@ func Spinner(
 ) error {
 	var s *spinner.Spinner
 	if IsOutputRich() {
-		s = spinner.New(spinner.CharSets[9], 100*time.Millisecond)
-		s.Prefix = prefix + "["
-		s.Suffix = "]" + " " + message
+		s = NewSpinner(prefix, message)
 		s.Start()
-		// Stop the spinner when the function exits.
-		defer func() { s.Stop() }()
+		// Stop the spinner when the function exits, or the program exits early.
+		release := cleanup.Push("spinner", func() error {
+			s.Stop()
+			return nil
+		})
+		defer func() { _ = release() }()
 	}
 	return function()
 }
 
+// MarshalJSON returns the JSON encoding of v in the configured JSON style;
+// documents are indented, unless JSONCompact is configured.
+func MarshalJSON(v any) ([]byte, error) {
+	if jsonStyle == JSONCompact {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "    ")
+}
+
 // PrintJSON prints the given data as JSON to stdout.
 // When marshaling of data fails, then error is returned.
 func PrintJSON(v any) error {
-	data, err := json.MarshalIndent(v, "", "    ")
+	data, err := MarshalJSON(v)
 	if err != nil {
 		return err
 	}
@@ -149,7 +199,10 @@ func PrintTable(headers []string, rows [][]string) {
 		return
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	const padding = 2
+	lastWidth := lastColumnWidth(headers, rows, padding)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, padding, ' ', 0)
 	defer func(w *tabwriter.Writer) {
 		err := w.Flush()
 		if err != nil {
@@ -170,7 +223,7 @@ func PrintTable(headers []string, rows [][]string) {
 	for _, row := range rows {
 		for i, cell := range row {
 			if i == len(row)-1 {
-				_, _ = fmt.Fprint(w, cell)
+				_, _ = fmt.Fprint(w, Truncate(cell, lastWidth))
 			} else {
 				_, _ = fmt.Fprint(w, cell+"\t")
 			}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	tests := []struct {
+		description string
+		style       JSONStyle
+		want        string
+	}{
+		{
+			description: "default",
+			style:       JSONDefault,
+			want:        "{\n    \"hostname\": \"db1\"\n}",
+		},
+		{
+			description: "pretty",
+			style:       JSONPretty,
+			want:        "{\n    \"hostname\": \"db1\"\n}",
+		},
+		{
+			description: "compact",
+			style:       JSONCompact,
+			want:        `{"hostname":"db1"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			ConfigureOutput(Settings{MachineReadable: true, JSONStyle: test.style})
+			t.Cleanup(func() { ConfigureOutput(Settings{Rich: true, Colored: true}) })
+
+			got, err := MarshalJSON(map[string]string{"hostname": "db1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
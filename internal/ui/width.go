@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/sys/unix"
+)
+
+// ellipsis is appended to truncated text.
+const ellipsis = "…"
+
+// minColumnWidth is the narrowest column that is still worth truncating
+// or wrapping to. Narrower columns are printed as they are.
+const minColumnWidth = 16
+
+var isTruncationEnabled bool
+
+// TerminalWidth returns the number of columns of the terminal connected to
+// the standard output. Zero is returned when the output is not a terminal,
+// or when truncation was disabled by the user.
+func TerminalWidth() int {
+	if !isTruncationEnabled || IsOutputMachineReadable() {
+		return 0
+	}
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0
+	}
+	return int(ws.Col)
+}
+
+// Truncate shortens text to fit into width columns. When the text is
+// shortened, its last character is replaced with an ellipsis.
+// The text is returned unchanged when width is smaller than minColumnWidth.
+func Truncate(text string, width int) string {
+	if width < minColumnWidth || utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:width-1]) + ellipsis
+}
+
+// Wrap splits text into lines not longer than width columns. Lines are
+// broken at whitespace when possible; words longer than width are split.
+// A single line is returned when width is smaller than minColumnWidth.
+func Wrap(text string, width int) []string {
+	if width < minColumnWidth || utf8.RuneCountInString(text) <= width {
+		return []string{text}
+	}
+
+	var lines []string
+	var line []rune
+	for _, word := range strings.Fields(text) {
+		w := []rune(word)
+		if len(line) > 0 && len(line)+1+len(w) > width {
+			lines = append(lines, string(line))
+			line = nil
+		}
+		for len(w) > width {
+			lines = append(lines, string(w[:width]))
+			w = w[width:]
+		}
+		if len(line) > 0 {
+			line = append(line, ' ')
+		}
+		line = append(line, w...)
+	}
+	if len(line) > 0 {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// lastColumnWidth returns the width available for the last column of a table
+// with given headers and rows, or zero when the width is not limited.
+// Columns are separated by padding spaces.
+func lastColumnWidth(headers []string, rows [][]string, padding int) int {
+	width := TerminalWidth()
+	if width == 0 || len(headers) == 0 {
+		return 0
+	}
+	widths := make([]int, len(headers)-1)
+	for i := range widths {
+		widths[i] = utf8.RuneCountInString(headers[i])
+		for _, row := range rows {
+			if i < len(row) {
+				widths[i] = max(widths[i], utf8.RuneCountInString(row[i]))
+			}
+		}
+		width -= widths[i] + padding
+	}
+	return max(width, 0)
+}
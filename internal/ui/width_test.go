@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		description string
+		text        string
+		width       int
+		want        string
+	}{
+		{
+			description: "short text",
+			text:        "unable to connect",
+			width:       20,
+			want:        "unable to connect",
+		},
+		{
+			description: "long text",
+			text:        "unable to connect to the server",
+			width:       20,
+			want:        "unable to connect t…",
+		},
+		{
+			description: "too narrow column",
+			text:        "unable to connect to the server",
+			width:       10,
+			want:        "unable to connect to the server",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := Truncate(test.text, test.width)
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		description string
+		text        string
+		width       int
+		want        []string
+	}{
+		{
+			description: "short text",
+			text:        "unable to connect",
+			width:       20,
+			want:        []string{"unable to connect"},
+		},
+		{
+			description: "wrap at whitespace",
+			text:        "cannot connect to Red Hat Subscription Management: timeout",
+			width:       20,
+			want:        []string{"cannot connect to", "Red Hat Subscription", "Management: timeout"},
+		},
+		{
+			description: "split long word",
+			text:        "error https://subscription.rhsm.redhat.com/subscription",
+			width:       20,
+			want:        []string{"error", "https://subscription", ".rhsm.redhat.com/sub", "scription"},
+		},
+		{
+			description: "width not limited",
+			text:        "cannot connect to Red Hat Subscription Management",
+			width:       0,
+			want:        []string{"cannot connect to Red Hat Subscription Management"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got := Wrap(test.text, test.width)
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+		})
+	}
+}
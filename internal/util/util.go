@@ -1,9 +1,13 @@
 package util
 
 import (
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
+
+	"github.com/redhatinsights/rhc/internal/cleanup"
 )
 
 // MustReadFile returns whitespace-trimmed content of a file.
@@ -15,3 +19,29 @@ func MustReadFile(file *os.File) string {
 	}
 	return strings.TrimSpace(string(raw))
 }
+
+// WriteFileAtomic writes data to a temporary file next to path, and renames it
+// to path, so the file is never left partially written. The temporary file is
+// registered for cleanup until it is renamed.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	release := cleanup.Push("temporary file "+tmp.Name(), cleanup.Remove(tmp.Name()))
+	defer func() { _ = release() }()
+
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("cannot rename %s: %w", tmp.Name(), err)
+	}
+	return nil
+}
@@ -23,4 +23,8 @@ const (
 	Protocol    = 76 // remote error in protocol
 	NoPerm      = 77 // permission denied
 	Config      = 78 // configuration error
+
+	// Unchanged is not defined by sysexits.h; it is used when nothing was
+	// changed, because the system already was in the requested state.
+	Unchanged = 79 // nothing changed
 )
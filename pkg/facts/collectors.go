@@ -0,0 +1,318 @@
+package facts
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/canonical_facts"
+	"github.com/redhatinsights/rhc/internal/cloud"
+	"github.com/redhatinsights/rhc/internal/logging"
+)
+
+// CustomFactsDir is the directory with custom facts. Every '*.json' file holds
+// a JSON object stored in the facts document under the name of the file.
+const CustomFactsDir = "/etc/rhc/facts.d"
+
+// dmiDir is the directory exposing DMI information of the system.
+const dmiDir = "/sys/devices/virtual/dmi/id"
+
+// DefaultRegistry returns a registry with the built-in collectors. Custom facts
+// are read from customDir, and fact providers are run from providerDir.
+func DefaultRegistry(customDir, providerDir string) *Registry {
+	registry := NewRegistry()
+	for _, collector := range []Collector{
+		{Name: "canonical", Timeout: 5 * time.Second, Collect: collectCanonical},
+		{Name: "hardware", Timeout: 5 * time.Second, Collect: collectHardware},
+		{Name: "network", Timeout: 5 * time.Second, Collect: collectNetwork},
+		{Name: "cloud", Timeout: 5 * time.Second, Collect: collectCloud},
+		{Name: "custom", Timeout: 5 * time.Second, Collect: func(ctx context.Context) (map[string]any, error) {
+			return collectCustom(customDir)
+		}},
+		{Name: "providers", Timeout: 10 * time.Second, Collect: func(ctx context.Context) (map[string]any, error) {
+			return collectProviders(ctx, providerDir)
+		}},
+	} {
+		// Names of built-in collectors are unique
+		_ = registry.Register(collector)
+	}
+	return registry
+}
+
+// toMap converts a structure with JSON tags into a map.
+func toMap(value any) (map[string]any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]any
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// readTrimmed returns the content of the file at path without surrounding whitespace.
+func readTrimmed(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// collectCanonical gathers facts identifying the system in Inventory.
+func collectCanonical(context.Context) (map[string]any, error) {
+	facts, err := canonical_facts.GetCanonicalFacts()
+	if err != nil {
+		return nil, err
+	}
+	return toMap(facts)
+}
+
+// collectHardware gathers information about processors, memory and DMI.
+func collectHardware(context.Context) (map[string]any, error) {
+	facts := map[string]any{"architecture": runtime.GOARCH}
+
+	file, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return nil, err
+	}
+	count, model := parseCPUInfo(file)
+	_ = file.Close()
+	facts["cpu_count"] = count
+	if model != "" {
+		facts["cpu_model"] = model
+	}
+
+	file, err = os.Open("/proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+	memTotal, err := parseMemTotal(file)
+	_ = file.Close()
+	if err != nil {
+		return nil, err
+	}
+	facts["memory_total_bytes"] = memTotal
+
+	for _, name := range []string{"sys_vendor", "product_name", "bios_vendor", "bios_version"} {
+		// DMI is not available on all architectures
+		if value, err := readTrimmed(filepath.Join(dmiDir, name)); err == nil && value != "" {
+			facts[name] = value
+		}
+	}
+	return facts, nil
+}
+
+// parseCPUInfo returns the number of processors and the model of the first one
+// from the content of /proc/cpuinfo.
+func parseCPUInfo(r io.Reader) (int, string) {
+	count := 0
+	model := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "processor":
+			count++
+		case "model name":
+			if model == "" {
+				model = strings.TrimSpace(value)
+			}
+		}
+	}
+	return count, model
+}
+
+// parseMemTotal returns the total memory in bytes from the content of /proc/meminfo.
+func parseMemTotal(r io.Reader) (uint64, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemTotal:" {
+			continue
+		}
+		kib, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid MemTotal value %q: %w", fields[1], err)
+		}
+		return kib * 1024, nil
+	}
+	return 0, fmt.Errorf("MemTotal not found")
+}
+
+// networkInterface is the description of a network interface in the facts document.
+type networkInterface struct {
+	Name      string   `json:"name"`
+	MAC       string   `json:"mac_address,omitempty"`
+	MTU       int      `json:"mtu"`
+	Up        bool     `json:"up"`
+	Addresses []string `json:"addresses"`
+}
+
+// collectNetwork gathers network interfaces and their addresses.
+func collectNetwork(context.Context) (map[string]any, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(ifaces, func(a, b net.Interface) int { return strings.Compare(a.Name, b.Name) })
+
+	interfaces := make([]networkInterface, 0, len(ifaces))
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		description := networkInterface{
+			Name:      iface.Name,
+			MAC:       iface.HardwareAddr.String(),
+			MTU:       iface.MTU,
+			Up:        iface.Flags&net.FlagUp != 0,
+			Addresses: make([]string, 0, len(addrs)),
+		}
+		for _, addr := range addrs {
+			description.Addresses = append(description.Addresses, addr.String())
+		}
+		interfaces = append(interfaces, description)
+	}
+	return toMap(struct {
+		Interfaces []networkInterface `json:"interfaces"`
+	}{interfaces})
+}
+
+// collectCloud gathers the cloud provider and instance metadata from
+// the instance metadata service (IMDS) of the provider.
+func collectCloud(ctx context.Context) (map[string]any, error) {
+	provider := cloud.DetectProvider(cloud.ReadDMI(dmiDir))
+	if provider == "" {
+		return nil, fmt.Errorf("no cloud provider detected: %w", ErrNotApplicable)
+	}
+
+	facts := map[string]any{"provider": provider}
+	var metadata map[string]any
+	var err error
+	switch provider {
+	case cloud.AWS:
+		metadata, err = queryAWSMetadata(ctx)
+	case cloud.Azure:
+		metadata, err = queryIMDS(ctx, "http://169.254.169.254/metadata/instance/compute?api-version=2021-02-01",
+			map[string]string{"Metadata": "true"})
+	case cloud.GCP:
+		metadata, err = queryIMDS(ctx, "http://metadata.google.internal/computeMetadata/v1/instance/?recursive=false",
+			map[string]string{"Metadata-Flavor": "Google"})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot query %s instance metadata: %w", provider, err)
+	}
+	for _, key := range []string{"instanceId", "id", "vmId", "region", "location", "zone", "instanceType", "vmSize", "machineType"} {
+		if value, ok := metadata[key]; ok {
+			facts[key] = value
+		}
+	}
+	return facts, nil
+}
+
+// queryAWSMetadata returns the instance identity document of an AWS instance (IMDSv2).
+func queryAWSMetadata(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, "http://169.254.169.254/latest/api/token", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	token, err := doIMDSRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return queryIMDS(ctx, "http://169.254.169.254/latest/dynamic/instance-identity/document",
+		map[string]string{"X-aws-ec2-metadata-token": string(token)})
+}
+
+// queryIMDS returns the JSON object served by the instance metadata service at url.
+func queryIMDS(ctx context.Context, url string, headers map[string]string) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	body, err := doIMDSRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	var metadata map[string]any
+	if err = json.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// doIMDSRequest sends req to the instance metadata service and returns the response body.
+// Proxies are never used for the link-local metadata service.
+func doIMDSRequest(req *http.Request) ([]byte, error) {
+	client := &http.Client{Transport: &http.Transport{Proxy: nil}}
+	start := logging.HTTPRequestStart(req)
+	resp, err := client.Do(req)
+	logging.HTTPRequest(req, start, resp, err)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+}
+
+// collectCustom gathers facts from '*.json' files in dir.
+func collectCustom(dir string) (map[string]any, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no custom facts in %s: %w", dir, ErrNotApplicable)
+	}
+	slices.Sort(paths)
+
+	facts := make(map[string]any)
+	var errs []error
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var value map[string]any
+		if err = json.Unmarshal(data, &value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid custom facts %s: %w", path, err))
+			continue
+		}
+		facts[strings.TrimSuffix(filepath.Base(path), ".json")] = value
+	}
+	if len(facts) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		slog.Warn("Skipping custom facts", "err", err)
+	}
+	return facts, nil
+}
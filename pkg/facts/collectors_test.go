@@ -0,0 +1,66 @@
+package facts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCPUInfo(t *testing.T) {
+	input := `processor	: 0
+model name	: Intel(R) Xeon(R) CPU E5-2680 v4 @ 2.40GHz
+
+processor	: 1
+model name	: Intel(R) Xeon(R) CPU E5-2680 v4 @ 2.40GHz
+`
+	count, model := parseCPUInfo(strings.NewReader(input))
+	if count != 2 {
+		t.Errorf("got count %v, want 2", count)
+	}
+	if model != "Intel(R) Xeon(R) CPU E5-2680 v4 @ 2.40GHz" {
+		t.Errorf("got model %q", model)
+	}
+}
+
+func TestParseMemTotal(t *testing.T) {
+	input := "MemTotal:       16252048 kB\nMemFree:         1075568 kB\n"
+	got, err := parseMemTotal(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 16252048*1024 {
+		t.Errorf("got %v, want %v", got, 16252048*1024)
+	}
+
+	if _, err = parseMemTotal(strings.NewReader("MemFree: 1 kB\n")); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestCollectCustom(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := collectCustom(dir)
+	if !errors.Is(err, ErrNotApplicable) {
+		t.Errorf("got error %v, want %v", err, ErrNotApplicable)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "owner.json"), []byte(`{"team": "databases"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := collectCustom(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"owner": map[string]any{"team": "databases"}}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
@@ -0,0 +1,29 @@
+/*
+Package facts collects facts about the system using a registry of named collectors.
+
+# Collectors
+
+Every collector gathers one group of facts and runs with its own timeout.
+Collectors run concurrently and are isolated from each other: a collector
+that fails, times out or panics does not prevent the others from reporting
+their facts. The default registry provides these collectors:
+  - canonical: facts uniquely identifying the system in Inventory
+  - hardware: processors, memory and DMI information
+  - network: network interfaces and their addresses
+  - cloud: cloud provider and instance metadata (IMDS)
+  - custom: facts defined by the administrator in JSON files
+  - providers: facts printed as JSON by executables other packages install
+    into ProviderDir, each stored under the name of its executable
+
+The facts document holds facts of every successful collector under its name,
+and the "metadata" section with the status, duration and error of every
+collector. Consumers can use it to tell facts missing because they do not
+apply to the system (e.g. cloud facts on bare metal) from failed collectors.
+
+# Package usage
+
+	registry := facts.DefaultRegistry(facts.CustomFactsDir, facts.ProviderDir)
+	results := registry.Run(ctx, []string{"canonical", "hardware"})
+	document := facts.Document(results)
+*/
+package facts
@@ -0,0 +1,147 @@
+package facts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/redhatinsights/rhc/internal/logging"
+)
+
+// ProviderDir is the directory of fact providers. Other packages install
+// executables there; every executable prints a JSON object, which is stored
+// in the facts document under the name of the executable in the "providers"
+// section.
+const ProviderDir = "/usr/libexec/rhc-facts.d"
+
+// providerOutputLimit is the maximum size of the output of a fact provider.
+const providerOutputLimit = 1024 * 1024
+
+// collectProviders runs the fact providers in dir concurrently and gathers their
+// facts. Providers that are not executable, or that could be modified by users
+// other than the owner of rhc, are skipped. A failed provider does not prevent
+// the others from reporting their facts.
+func collectProviders(ctx context.Context, dir string) (map[string]any, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no fact providers in %s: %w", dir, ErrNotApplicable)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if err := checkProvider(path); err != nil {
+			slog.Warn("Skipping fact provider", "path", path, "err", err)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no fact providers in %s: %w", dir, ErrNotApplicable)
+	}
+	slices.Sort(paths)
+
+	facts := make(map[string]any)
+	var errs []error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, path := range paths {
+		wg.Go(func() {
+			value, err := runProvider(ctx, path)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("fact provider %s failed: %w", path, err))
+				return
+			}
+			facts[filepath.Base(path)] = value
+		})
+	}
+	wg.Wait()
+
+	if len(facts) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		slog.Warn("Skipping fact provider", "err", err)
+	}
+	return facts, nil
+}
+
+// checkProvider verifies that path is an executable file, which is owned by
+// root or the current user, and cannot be written by anyone else.
+func checkProvider(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("not a regular file")
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		return fmt.Errorf("not executable")
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		return fmt.Errorf("writable by group or others")
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		if stat.Uid != 0 && int(stat.Uid) != os.Geteuid() {
+			return fmt.Errorf("owned by user %d", stat.Uid)
+		}
+	}
+	return nil
+}
+
+// runProvider executes the fact provider at path and parses its output.
+func runProvider(ctx context.Context, path string) (map[string]any, error) {
+	var stdout, stderr bytes.Buffer
+	slog.Debug(fmt.Sprintf("Executing %s", path))
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdout = &limitedWriter{w: &stdout, limit: providerOutputLimit}
+	cmd.Stderr = &limitedWriter{w: &stderr, limit: providerOutputLimit}
+	start := logging.CommandStart(cmd)
+	err := cmd.Run()
+	logging.Command(cmd, start, err)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if stderr.Len() > 0 {
+			return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+
+	var value map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &value); err != nil {
+		return nil, fmt.Errorf("invalid output: %w", err)
+	}
+	return value, nil
+}
+
+// limitedWriter writes to w until limit bytes were written, and fails afterwards.
+type limitedWriter struct {
+	w     io.Writer
+	limit int
+}
+
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > lw.limit {
+		return 0, fmt.Errorf("output exceeds %d bytes", providerOutputLimit)
+	}
+	lw.limit -= len(p)
+	return lw.w.Write(p)
+}
@@ -0,0 +1,59 @@
+package facts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCollectProviders(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := collectProviders(t.Context(), filepath.Join(dir, "missing"))
+	if !errors.Is(err, ErrNotApplicable) {
+		t.Errorf("got error %v, want %v", err, ErrNotApplicable)
+	}
+
+	providers := map[string]struct {
+		script string
+		mode   os.FileMode
+	}{
+		"rhel-ai":        {script: "#!/bin/sh\necho '{\"accelerators\": 2}'\n", mode: 0755},
+		"broken":         {script: "#!/bin/sh\necho 'not json'\n", mode: 0755},
+		"failing":        {script: "#!/bin/sh\necho 'no GPU' >&2\nexit 1\n", mode: 0755},
+		"not-executable": {script: "#!/bin/sh\necho '{}'\n", mode: 0644},
+		"world-writable": {script: "#!/bin/sh\necho '{}'\n", mode: 0757},
+	}
+	for name, provider := range providers {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(provider.script), provider.mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, provider.mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := collectProviders(t.Context(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"rhel-ai": map[string]any{"accelerators": float64(2)}}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
+
+func TestCollectProvidersFailed(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "failing"), []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, err := collectProviders(t.Context(), dir)
+	if err == nil || errors.Is(err, ErrNotApplicable) {
+		t.Errorf("got error %v, want failure", err)
+	}
+}
@@ -0,0 +1,241 @@
+package facts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// DefaultTimeout is the timeout of collectors not declaring their own.
+const DefaultTimeout = 10 * time.Second
+
+// ErrNotApplicable is returned by a collector when its facts do not exist
+// on the system (e.g. cloud facts on bare metal).
+var ErrNotApplicable = errors.New("not applicable")
+
+// ErrNotSelected is the reason of collectors skipped, because they were not selected.
+var ErrNotSelected = errors.New("not selected")
+
+// CollectFunc gathers facts of a single collector. It should return
+// as soon as possible when ctx is done.
+type CollectFunc func(ctx context.Context) (map[string]any, error)
+
+// Collector is a named source of facts.
+type Collector struct {
+	// Name identifies the collector in the registry and in the facts document.
+	Name string
+	// Timeout limits the time the collector is allowed to run.
+	Timeout time.Duration
+	// Collect gathers the facts.
+	Collect CollectFunc
+}
+
+// Status is the outcome of a collector run.
+type Status string
+
+const (
+	// StatusOK means the collector gathered its facts.
+	StatusOK Status = "ok"
+	// StatusFailed means the collector returned an error or timed out.
+	StatusFailed Status = "failed"
+	// StatusSkipped means the collector was not selected, or its facts
+	// do not exist on the system.
+	StatusSkipped Status = "skipped"
+)
+
+// Result is the outcome of a single collector.
+type Result struct {
+	Name     string
+	Status   Status
+	Duration time.Duration
+	// Err is the reason of failure, or the reason a collector was skipped.
+	Err   error
+	Facts map[string]any
+}
+
+// Registry holds collectors in the order they were registered.
+type Registry struct {
+	collectors []Collector
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds collector to the registry. An error is returned when
+// a collector with the same name has already been registered.
+func (r *Registry) Register(collector Collector) error {
+	if collector.Name == "" || collector.Collect == nil {
+		return fmt.Errorf("invalid collector: name and collect function are required")
+	}
+	if collector.Name == MetadataKey {
+		return fmt.Errorf("invalid collector: name %q is reserved", MetadataKey)
+	}
+	if slices.Contains(r.Names(), collector.Name) {
+		return fmt.Errorf("collector %q is already registered", collector.Name)
+	}
+	r.collectors = append(r.collectors, collector)
+	return nil
+}
+
+// Names returns names of all registered collectors.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.collectors))
+	for _, collector := range r.collectors {
+		names = append(names, collector.Name)
+	}
+	return names
+}
+
+// SetTimeout changes the timeout of the collector called name.
+func (r *Registry) SetTimeout(name string, timeout time.Duration) error {
+	for i := range r.collectors {
+		if r.collectors[i].Name == name {
+			r.collectors[i].Timeout = timeout
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown collector %q", name)
+}
+
+// Run runs the collectors listed in names concurrently, each limited by its
+// own timeout. All collectors are run when names is empty. A result is
+// returned for every registered collector, in the order of registration;
+// collectors not listed in names are skipped.
+func (r *Registry) Run(ctx context.Context, names []string) []Result {
+	results := make([]Result, len(r.collectors))
+	done := make(chan struct{})
+	running := 0
+
+	for i, collector := range r.collectors {
+		if len(names) > 0 && !slices.Contains(names, collector.Name) {
+			results[i] = Result{Name: collector.Name, Status: StatusSkipped, Err: ErrNotSelected}
+			continue
+		}
+		running++
+		go func() {
+			results[i] = runCollector(ctx, collector)
+			done <- struct{}{}
+		}()
+	}
+
+	for range running {
+		<-done
+	}
+	return results
+}
+
+// runCollector runs a single collector. The collector is abandoned when it does
+// not finish within its timeout; errors and panics are converted into a failed result.
+func runCollector(ctx context.Context, collector Collector) Result {
+	timeout := collector.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		facts map[string]any
+		err   error
+	}
+	finished := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				finished <- outcome{err: fmt.Errorf("collector panicked: %v", r)}
+			}
+		}()
+		facts, err := collector.Collect(ctx)
+		finished <- outcome{facts: facts, err: err}
+	}()
+
+	result := Result{Name: collector.Name}
+	select {
+	case out := <-finished:
+		result.Facts, result.Err = out.facts, out.err
+	case <-ctx.Done():
+		result.Err = fmt.Errorf("timed out after %v", timeout)
+	}
+	result.Duration = time.Since(start)
+
+	switch {
+	case result.Err == nil:
+		result.Status = StatusOK
+	case errors.Is(result.Err, ErrNotApplicable):
+		result.Status = StatusSkipped
+		result.Facts = nil
+	default:
+		result.Status = StatusFailed
+		result.Facts = nil
+		slog.Warn("Facts collector failed", "collector", collector.Name, "err", result.Err)
+	}
+	slog.Debug("Facts collector finished", "collector", collector.Name, "status", result.Status, "duration", result.Duration)
+	return result
+}
+
+// MetadataKey is the key of the metadata section in the facts document.
+const MetadataKey = "metadata"
+
+// CollectorMetadata describes the outcome of a collector in the metadata
+// section of the facts document.
+type CollectorMetadata struct {
+	Status Status `json:"status"`
+	// DurationMS is the time the collector ran, in milliseconds.
+	DurationMS int64 `json:"duration_ms"`
+	// Error is the reason the collector failed.
+	Error string `json:"error,omitempty"`
+	// Reason explains why the collector was skipped.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Metadata is the metadata section of the facts document.
+type Metadata struct {
+	// GeneratedAt is the time the facts document was created.
+	GeneratedAt time.Time `json:"generated_at"`
+	// Collectors holds the outcome of every registered collector.
+	Collectors map[string]CollectorMetadata `json:"collectors"`
+}
+
+// NewMetadata returns the metadata describing results.
+func NewMetadata(results []Result) Metadata {
+	metadata := Metadata{
+		GeneratedAt: time.Now().UTC(),
+		Collectors:  make(map[string]CollectorMetadata, len(results)),
+	}
+	for _, result := range results {
+		collector := CollectorMetadata{
+			Status:     result.Status,
+			DurationMS: result.Duration.Milliseconds(),
+		}
+		if result.Err != nil {
+			if result.Status == StatusSkipped {
+				collector.Reason = result.Err.Error()
+			} else {
+				collector.Error = result.Err.Error()
+			}
+		}
+		metadata.Collectors[result.Name] = collector
+	}
+	return metadata
+}
+
+// Document returns the facts document built from results. Facts of every
+// successful collector are stored under the name of the collector; the outcome
+// of all collectors is stored in the metadata section (see Metadata), so
+// consumers can tell missing facts from failed collectors.
+func Document(results []Result) map[string]any {
+	document := make(map[string]any)
+	for _, result := range results {
+		if result.Status == StatusOK {
+			document[result.Name] = result.Facts
+		}
+	}
+	document[MetadataKey] = NewMetadata(results)
+	return document
+}
@@ -0,0 +1,122 @@
+package facts
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRegistryRun(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	registry := NewRegistry()
+	collectors := []Collector{
+		{
+			Name: "ok",
+			Collect: func(context.Context) (map[string]any, error) {
+				return map[string]any{"key": "value"}, nil
+			},
+		},
+		{
+			Name: "failed",
+			Collect: func(context.Context) (map[string]any, error) {
+				return nil, errors.New("broken")
+			},
+		},
+		{
+			Name: "not-applicable",
+			Collect: func(context.Context) (map[string]any, error) {
+				return nil, ErrNotApplicable
+			},
+		},
+		{
+			Name:    "stuck",
+			Timeout: 10 * time.Millisecond,
+			Collect: func(ctx context.Context) (map[string]any, error) {
+				// Ignores ctx, like a collector stuck in a system call
+				<-release
+				return map[string]any{}, nil
+			},
+		},
+		{
+			Name: "panicking",
+			Collect: func(context.Context) (map[string]any, error) {
+				panic("unexpected")
+			},
+		},
+		{
+			Name: "not-selected",
+			Collect: func(context.Context) (map[string]any, error) {
+				t.Error("collector not selected, but run")
+				return nil, nil
+			},
+		},
+	}
+	for _, collector := range collectors {
+		if err := registry.Register(collector); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results := registry.Run(context.Background(), []string{"ok", "failed", "not-applicable", "stuck", "panicking"})
+
+	got := make(map[string]Status)
+	for _, result := range results {
+		got[result.Name] = result.Status
+	}
+	want := map[string]Status{
+		"ok":             StatusOK,
+		"failed":         StatusFailed,
+		"not-applicable": StatusSkipped,
+		"stuck":          StatusFailed,
+		"panicking":      StatusFailed,
+		"not-selected":   StatusSkipped,
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+
+	document := Document(results)
+	if got := document["ok"]; !cmp.Equal(got, map[string]any{"key": "value"}) {
+		t.Errorf("got facts %v", got)
+	}
+	if _, ok := document["failed"]; ok {
+		t.Errorf("facts of failed collector present in document")
+	}
+}
+
+func TestNewMetadata(t *testing.T) {
+	results := []Result{
+		{Name: "canonical", Status: StatusOK, Duration: 15 * time.Millisecond},
+		{Name: "cloud", Status: StatusFailed, Duration: 5 * time.Second, Err: errors.New("timed out after 5s")},
+		{Name: "custom", Status: StatusSkipped, Err: ErrNotApplicable},
+	}
+
+	got := NewMetadata(results).Collectors
+	want := map[string]CollectorMetadata{
+		"canonical": {Status: StatusOK, DurationMS: 15},
+		"cloud":     {Status: StatusFailed, DurationMS: 5000, Error: "timed out after 5s"},
+		"custom":    {Status: StatusSkipped, Reason: "not applicable"},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("%v", cmp.Diff(got, want))
+	}
+}
+
+func TestRegistryRegisterDuplicate(t *testing.T) {
+	registry := NewRegistry()
+	collector := Collector{
+		Name:    "canonical",
+		Collect: func(context.Context) (map[string]any, error) { return nil, nil },
+	}
+	if err := registry.Register(collector); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register(collector); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
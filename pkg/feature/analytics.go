@@ -1,6 +1,7 @@
 package feature
 
 import (
+	"context"
 	"github.com/redhatinsights/rhc/internal/datacollection"
 )
 
@@ -23,12 +24,12 @@ func (a Analytics) RequiredBy() []string {
 	return []string{"remote-management"}
 }
 
-func (a Analytics) Enable() error {
-	return datacollection.RegisterInsightsClient()
+func (a Analytics) Enable(ctx context.Context) error {
+	return datacollection.RegisterInsightsClient(ctx, datacollection.InsightsRegistration{})
 }
 
-func (a Analytics) Disable() error {
-	return datacollection.UnregisterInsightsClient()
+func (a Analytics) Disable(ctx context.Context) error {
+	return datacollection.UnregisterInsightsClient(ctx)
 }
 
 func (a Analytics) IsEnabled() (bool, error) {
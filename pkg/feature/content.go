@@ -1,6 +1,7 @@
 package feature
 
 import (
+	"context"
 	"github.com/redhatinsights/rhc/internal/subman"
 )
 
@@ -23,7 +24,7 @@ func (c Content) RequiredBy() []string {
 	return []string{"remote-management"}
 }
 
-func (c Content) Enable() error {
+func (c Content) Enable(ctx context.Context) error {
 	client, err := subman.NewRHSMClient()
 	if err != nil {
 		return err
@@ -31,7 +32,7 @@ func (c Content) Enable() error {
 	return client.SetContentManagement(true)
 }
 
-func (c Content) Disable() error {
+func (c Content) Disable(ctx context.Context) error {
 	client, err := subman.NewRHSMClient()
 	if err != nil {
 		return err
@@ -26,7 +26,7 @@ Feature objects can be retrieved using Get() or MustGet():
 	if err != nil {
 		// handle error
 	}
-	if err := feature.Enable(); err != nil {
+	if err := feature.Enable(ctx); err != nil {
 		// handle error
 	}
 */
@@ -1,6 +1,7 @@
 package feature
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -13,9 +14,9 @@ type IFeature interface {
 	RequiredBy() []string
 
 	// Enable enables a feature. Acts as a no-op if it is already enabled.
-	Enable() error
+	Enable(ctx context.Context) error
 	// Disable disables a feature. Acts as a no-op if it is already disabled.
-	Disable() error
+	Disable(ctx context.Context) error
 	// IsEnabled returns true if the feature is enabled, false otherwise.
 	// Returns an error if the feature misbehaves.
 	IsEnabled() (bool, error)
@@ -0,0 +1,89 @@
+package feature
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// Reconciliation is the outcome of reconciling the actual state of a feature
+// with the intended one.
+type Reconciliation struct {
+	ID      string `json:"id"`
+	Wanted  bool   `json:"wanted"`
+	Enabled bool   `json:"enabled"`
+	Changed bool   `json:"changed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Reconcile enables and disables features, so their state matches wanted.
+// Features missing in wanted are left untouched. Features are enabled in the
+// order of features, after their requirements; they are disabled in reverse
+// order, before the features they are required by. A feature is skipped, when
+// reconciling a feature it depends on fails.
+func Reconcile(ctx context.Context, features []IFeature, wanted map[string]bool) []Reconciliation {
+	results := make([]Reconciliation, len(features))
+	failed := map[string]bool{}
+
+	reconcile := func(i int, enable bool) {
+		f := features[i]
+		result := &results[i]
+		result.ID = f.ID()
+		result.Wanted = enable
+
+		dependencies := f.Requires()
+		if !enable {
+			dependencies = f.RequiredBy()
+		}
+		for _, dependency := range dependencies {
+			if failed[dependency] {
+				result.Error = fmt.Sprintf("skipped: dependency '%s' failed", dependency)
+				failed[result.ID] = true
+				return
+			}
+		}
+
+		enabled, err := f.IsEnabled()
+		if err != nil {
+			result.Error = fmt.Sprintf("cannot check state: %v", err)
+			failed[result.ID] = true
+			return
+		}
+		result.Enabled = enabled
+		if enabled == enable {
+			return
+		}
+
+		if enable {
+			slog.Info("Re-enabling feature", "feature", result.ID)
+			err = f.Enable(ctx)
+		} else {
+			slog.Info("Re-disabling feature", "feature", result.ID)
+			err = f.Disable(ctx)
+		}
+		if err != nil {
+			result.Error = err.Error()
+			failed[result.ID] = true
+			return
+		}
+		result.Enabled = enable
+		result.Changed = true
+	}
+
+	for i, f := range features {
+		if enable, ok := wanted[f.ID()]; ok && enable {
+			reconcile(i, true)
+		}
+	}
+	for i, f := range slices.Backward(features) {
+		if enable, ok := wanted[f.ID()]; ok && !enable {
+			reconcile(i, false)
+		}
+	}
+
+	// Features missing in wanted are not reported
+	return slices.DeleteFunc(results, func(result Reconciliation) bool {
+		return result.ID == ""
+	})
+}
@@ -0,0 +1,114 @@
+package feature
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeFeature is an IFeature holding its state in memory.
+type fakeFeature struct {
+	id         string
+	requires   []string
+	requiredBy []string
+	enabled    *bool
+	enableErr  error
+}
+
+func (f fakeFeature) ID() string           { return f.id }
+func (f fakeFeature) Description() string  { return f.id }
+func (f fakeFeature) Requires() []string   { return f.requires }
+func (f fakeFeature) RequiredBy() []string { return f.requiredBy }
+func (f fakeFeature) IsEnabled() (bool, error) {
+	return *f.enabled, nil
+}
+func (f fakeFeature) Enable(ctx context.Context) error {
+	if f.enableErr != nil {
+		return f.enableErr
+	}
+	*f.enabled = true
+	return nil
+}
+func (f fakeFeature) Disable(ctx context.Context) error {
+	*f.enabled = false
+	return nil
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		description string
+		enabled     [3]bool
+		wanted      map[string]bool
+		enableErr   error
+		want        []Reconciliation
+		wantEnabled [3]bool
+	}{
+		{
+			description: "remote management disabled manually",
+			enabled:     [3]bool{true, true, false},
+			wanted:      map[string]bool{"content": true, "analytics": true, "remote-management": true},
+			want: []Reconciliation{
+				{ID: "content", Wanted: true, Enabled: true},
+				{ID: "analytics", Wanted: true, Enabled: true},
+				{ID: "remote-management", Wanted: true, Enabled: true, Changed: true},
+			},
+			wantEnabled: [3]bool{true, true, true},
+		},
+		{
+			description: "analytics enabled manually",
+			enabled:     [3]bool{true, true, true},
+			wanted:      map[string]bool{"content": true, "analytics": false, "remote-management": false},
+			want: []Reconciliation{
+				{ID: "content", Wanted: true, Enabled: true},
+				{ID: "analytics", Wanted: false, Enabled: false, Changed: true},
+				{ID: "remote-management", Wanted: false, Enabled: false, Changed: true},
+			},
+			wantEnabled: [3]bool{true, false, false},
+		},
+		{
+			description: "dependency fails",
+			enabled:     [3]bool{true, false, false},
+			wanted:      map[string]bool{"content": true, "analytics": true, "remote-management": true},
+			enableErr:   errors.New("insights-client failed"),
+			want: []Reconciliation{
+				{ID: "content", Wanted: true, Enabled: true},
+				{ID: "analytics", Wanted: true, Enabled: false, Error: "insights-client failed"},
+				{ID: "remote-management", Wanted: true, Error: "skipped: dependency 'analytics' failed"},
+			},
+			wantEnabled: [3]bool{true, false, false},
+		},
+		{
+			description: "feature without selection",
+			enabled:     [3]bool{true, false, false},
+			wanted:      map[string]bool{"content": true},
+			want: []Reconciliation{
+				{ID: "content", Wanted: true, Enabled: true},
+			},
+			wantEnabled: [3]bool{true, false, false},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			enabled := test.enabled
+			features := []IFeature{
+				fakeFeature{id: "content", requiredBy: []string{"analytics", "remote-management"}, enabled: &enabled[0]},
+				fakeFeature{
+					id: "analytics", requires: []string{"content"}, requiredBy: []string{"remote-management"},
+					enabled: &enabled[1], enableErr: test.enableErr,
+				},
+				fakeFeature{id: "remote-management", requires: []string{"content", "analytics"}, enabled: &enabled[2]},
+			}
+
+			got := Reconcile(context.Background(), features, test.wanted)
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("%v", cmp.Diff(got, test.want))
+			}
+			if enabled != test.wantEnabled {
+				t.Errorf("got state %v, want %v", enabled, test.wantEnabled)
+			}
+		})
+	}
+}
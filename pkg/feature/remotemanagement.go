@@ -1,6 +1,10 @@
 package feature
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+
 	"github.com/redhatinsights/rhc/internal/remotemanagement"
 )
 
@@ -23,12 +27,17 @@ func (r RemoteManagement) RequiredBy() []string {
 	return []string{}
 }
 
-func (r RemoteManagement) Enable() error {
-	return remotemanagement.ActivateServices()
+func (r RemoteManagement) Enable(ctx context.Context) error {
+	err := remotemanagement.ActivateServices(ctx)
+	if errors.Is(err, remotemanagement.ErrBrokerUnverified) {
+		slog.Warn(err.Error())
+		return nil
+	}
+	return err
 }
 
-func (r RemoteManagement) Disable() error {
-	return remotemanagement.DeactivateServices()
+func (r RemoteManagement) Disable(ctx context.Context) error {
+	return remotemanagement.DeactivateServices(ctx)
 }
 
 func (r RemoteManagement) IsEnabled() (bool, error) {
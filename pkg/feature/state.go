@@ -0,0 +1,52 @@
+package feature
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/redhatinsights/rhc/internal/util"
+)
+
+// State is the selection of features made when the system was connected.
+// It records the intended state of features, which Reconcile re-applies.
+type State struct {
+	Features map[string]bool `json:"features"`
+	Updated  time.Time       `json:"updated"`
+}
+
+// LoadState reads the selection of features from path. The error wraps
+// os.ErrNotExist, when no selection has been saved.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read feature selection: %w", err)
+	}
+	var state State
+	if err = json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("cannot parse feature selection %s: %w", path, err)
+	}
+	for id := range state.Features {
+		if _, err = Get(id); err != nil {
+			return nil, fmt.Errorf("invalid feature selection %s: %w", path, err)
+		}
+	}
+	return &state, nil
+}
+
+// SaveState writes the selection of features to path.
+func SaveState(path string, features map[string]bool) error {
+	data, err := json.MarshalIndent(State{Features: features, Updated: time.Now().UTC()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode feature selection: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot save feature selection: %w", err)
+	}
+	if err = util.WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("cannot save feature selection: %w", err)
+	}
+	return nil
+}
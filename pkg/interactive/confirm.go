@@ -0,0 +1,90 @@
+// Package interactive asks the user to confirm operations changing the system.
+package interactive
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/redhatinsights/rhc/internal/localization"
+	"github.com/redhatinsights/rhc/internal/ui"
+)
+
+// ErrPromptDisabled is returned by Confirm, when the operation has to be
+// confirmed, but prompts are disabled by --batch, or the user cannot be asked.
+var ErrPromptDisabled = errors.New("confirmation is required, but the user cannot be asked; use --assume-yes")
+
+// Settings control how operations are confirmed.
+type Settings struct {
+	// AssumeYes confirms operations without asking (--assume-yes).
+	AssumeYes bool
+	// Batch disables prompts (--batch); operations, which are not confirmed by
+	// default, have to be confirmed by AssumeYes.
+	Batch bool
+}
+
+var settings Settings
+
+// Configure sets how subsequent operations are confirmed.
+func Configure(s Settings) {
+	settings = s
+}
+
+// Confirm asks the user question and returns whether the operation is
+// confirmed. The details of the operation, e.g. what it removes, are listed
+// before the question is asked. An empty answer is defaultYes. When the user
+// cannot be asked (e.g. prompts are disabled by --batch, standard input is not
+// a terminal, or the output is machine-readable), operations with defaultYes
+// are confirmed without asking, so scripts run without prompts keep working;
+// other operations, e.g. destructive ones, fail with ErrPromptDisabled.
+func Confirm(question string, defaultYes bool, details ...string) (bool, error) {
+	switch {
+	case settings.AssumeYes:
+		return true, nil
+	case settings.Batch || !ui.CanPrompt() || ui.IsOutputMachineReadable():
+		if !defaultYes {
+			return false, ErrPromptDisabled
+		}
+		return true, nil
+	}
+	return ask(os.Stdin, os.Stdout, localization.GetLocale(), question, defaultYes, details...), nil
+}
+
+// ConfirmByUser asks the user question like Confirm, but the operation is
+// confirmed only by an answer of the user; --assume-yes does not confirm it.
+// It is meant for decisions, which cannot be made on behalf of the user, e.g.
+// trusting an unverified certificate. When the user cannot be asked, it fails
+// with ErrPromptDisabled.
+func ConfirmByUser(question string, details ...string) (bool, error) {
+	if settings.Batch || !ui.CanPrompt() || ui.IsOutputMachineReadable() {
+		return false, ErrPromptDisabled
+	}
+	return ask(os.Stdin, os.Stdout, localization.GetLocale(), question, false, details...), nil
+}
+
+// ask writes details and question to w and reads answers in the language of the
+// locale from r, until a known answer is read; only the question is repeated.
+// An empty answer is defaultYes, the end of r is no.
+func ask(r io.Reader, w io.Writer, locale, question string, defaultYes bool, details ...string) bool {
+	for _, detail := range details {
+		_, _ = fmt.Fprintf(w, " - %s\n", detail)
+	}
+	scanner := bufio.NewScanner(r)
+	hint := localization.AnswerHint(locale, defaultYes)
+	for {
+		_, _ = fmt.Fprintf(w, "%s %s: ", question, hint)
+		if !scanner.Scan() {
+			_, _ = fmt.Fprintln(w)
+			return false
+		}
+		answer := scanner.Text()
+		if answer == "" {
+			return defaultYes
+		}
+		if yes, ok := localization.ParseAnswer(locale, answer); ok {
+			return yes
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package interactive
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/redhatinsights/rhc/internal/ui"
+)
+
+func TestAsk(t *testing.T) {
+	tests := []struct {
+		description string
+		locale      string
+		input       string
+		defaultYes  bool
+		want        bool
+		wantPrompts int
+	}{
+		{description: "yes", locale: "en_US.UTF-8", input: "y\n", want: true, wantPrompts: 1},
+		{description: "no", locale: "en_US.UTF-8", input: "no\n", defaultYes: true, want: false, wantPrompts: 1},
+		{description: "default yes", locale: "en_US.UTF-8", input: "\n", defaultYes: true, want: true, wantPrompts: 1},
+		{description: "default no", locale: "en_US.UTF-8", input: "\n", want: false, wantPrompts: 1},
+		{description: "localized", locale: "cs_CZ.UTF-8", input: "ano\n", want: true, wantPrompts: 1},
+		{description: "unknown answer is asked again", locale: "en_US.UTF-8", input: "maybe\ny\n", want: true, wantPrompts: 2},
+		{description: "end of input", locale: "en_US.UTF-8", input: "", defaultYes: true, want: false, wantPrompts: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			var output strings.Builder
+			got := ask(strings.NewReader(test.input), &output, test.locale, "Disconnect?", test.defaultYes)
+			if got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+			if prompts := strings.Count(output.String(), "Disconnect?"); prompts != test.wantPrompts {
+				t.Errorf("got %d prompts, want %d", prompts, test.wantPrompts)
+			}
+		})
+	}
+}
+
+func TestAskDetails(t *testing.T) {
+	var output strings.Builder
+	ask(strings.NewReader("maybe\nn\n"), &output, "en_US.UTF-8", "Disconnect?", true, "the registration is removed")
+	if got := strings.Count(output.String(), " - the registration is removed\n"); got != 1 {
+		t.Errorf("details listed %d times in %q, want once", got, output.String())
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	defer Configure(Settings{})
+
+	Configure(Settings{AssumeYes: true, Batch: true})
+	if confirmed, err := Confirm("Disconnect?", false); err != nil || !confirmed {
+		t.Errorf("got (%v, %v) with --assume-yes, want (true, nil)", confirmed, err)
+	}
+
+	Configure(Settings{Batch: true})
+	if confirmed, err := Confirm("Disconnect?", true); err != nil || !confirmed {
+		t.Errorf("got (%v, %v) with --batch, want (true, nil)", confirmed, err)
+	}
+	if confirmed, err := Confirm("Decommission?", false); !errors.Is(err, ErrPromptDisabled) || confirmed {
+		t.Errorf("got (%v, %v) with --batch, want (false, %v)", confirmed, err, ErrPromptDisabled)
+	}
+
+	ui.ConfigureOutput(ui.Settings{MachineReadable: true})
+	if confirmed, err := Confirm("Disconnect?", true); err != nil || !confirmed {
+		t.Errorf("got (%v, %v) with --batch --format json, want (true, nil)", confirmed, err)
+	}
+	ui.ConfigureOutput(ui.Settings{})
+
+	// Standard input of tests is not a terminal
+	Configure(Settings{})
+	if confirmed, err := Confirm("Disconnect?", true); err != nil || !confirmed {
+		t.Errorf("got (%v, %v) without terminal, want (true, nil)", confirmed, err)
+	}
+	if confirmed, err := Confirm("Decommission?", false); !errors.Is(err, ErrPromptDisabled) || confirmed {
+		t.Errorf("got (%v, %v) without terminal, want (false, %v)", confirmed, err, ErrPromptDisabled)
+	}
+}
+
+func TestConfirmByUser(t *testing.T) {
+	defer Configure(Settings{})
+
+	Configure(Settings{AssumeYes: true})
+	if confirmed, err := ConfirmByUser("Trust the CA certificate?"); !errors.Is(err, ErrPromptDisabled) || confirmed {
+		t.Errorf("got (%v, %v) with --assume-yes, want (false, %v)", confirmed, err, ErrPromptDisabled)
+	}
+}
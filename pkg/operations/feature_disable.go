@@ -1,6 +1,7 @@
 package operations
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/redhatinsights/rhc/internal/datacollection"
@@ -46,7 +47,7 @@ const (
 //
 // The operation is idempotent: if the feature is already disabled, it returns
 // immediately with Status="already-disabled" and no error.
-func DisableFeature(opts FeatureOperationOptions) DisableFeatureResult {
+func DisableFeature(ctx context.Context, opts FeatureOperationOptions) DisableFeatureResult {
 	result := DisableFeatureResult{
 		Feature:            opts.Feature,
 		Status:             DisableStatusFailed,
@@ -78,7 +79,7 @@ func DisableFeature(opts FeatureOperationOptions) DisableFeatureResult {
 			return result
 		}
 		if rmStatus.Enabled {
-			rmResult := DisableFeature(FeatureOperationOptions{Feature: RemoteManagement})
+			rmResult := DisableFeature(ctx, FeatureOperationOptions{Feature: RemoteManagement})
 			result.DependentsDisabled = append(result.DependentsDisabled, rmResult)
 			if rmResult.Err != nil {
 				result.Err = fmt.Errorf("disabling dependent %s: %w", RemoteManagement, rmResult.Err)
@@ -98,7 +99,7 @@ func DisableFeature(opts FeatureOperationOptions) DisableFeatureResult {
 	var err error
 	switch opts.Feature {
 	case Analytics:
-		err = datacollection.UnregisterInsightsClient()
+		err = datacollection.UnregisterInsightsClient(ctx)
 	case Content:
 		var client *subman.RHSMClient
 		client, err = subman.NewRHSMClient()
@@ -106,7 +107,7 @@ func DisableFeature(opts FeatureOperationOptions) DisableFeatureResult {
 			err = client.SetContentManagement(false)
 		}
 	case RemoteManagement:
-		err = remotemanagement.DeactivateServices()
+		err = remotemanagement.DeactivateServices(ctx)
 	default:
 		err = fmt.Errorf("unknown feature: %s", opts.Feature)
 	}
@@ -1,13 +1,14 @@
 package operations
 
 import (
+	"context"
 	"strings"
 	"testing"
 )
 
 // TestDisableRemoteManagement tests disabling RemoteManagement when it has no enabled dependents.
 func TestDisableRemoteManagement(t *testing.T) {
-	result := DisableFeature(FeatureOperationOptions{Feature: RemoteManagement})
+	result := DisableFeature(context.Background(), FeatureOperationOptions{Feature: RemoteManagement})
 	if len(result.DependentsDisabled) != 0 {
 		t.Errorf("DependentsDisabled length = %d, want 0 for RemoteManagement",
 			len(result.DependentsDisabled))
@@ -39,7 +40,7 @@ func TestDisableAnalytics(t *testing.T) {
 		t.Skipf("Cannot determine RemoteManagement status: %v", rmStatus.Err)
 	}
 
-	result := DisableFeature(FeatureOperationOptions{Feature: Analytics})
+	result := DisableFeature(context.Background(), FeatureOperationOptions{Feature: Analytics})
 	if result.Feature != Analytics {
 		t.Errorf("Feature = %v, want %v", result.Feature, Analytics)
 	}
@@ -71,7 +72,7 @@ func TestDisableContent(t *testing.T) {
 		t.Skipf("Cannot determine RemoteManagement status: %v", rmStatus.Err)
 	}
 
-	result := DisableFeature(FeatureOperationOptions{Feature: Content})
+	result := DisableFeature(context.Background(), FeatureOperationOptions{Feature: Content})
 	if result.Feature != Content {
 		t.Errorf("Feature = %v, want %v", result.Feature, Content)
 	}
@@ -109,7 +110,7 @@ func TestDisableResultStructure(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := DisableFeature(FeatureOperationOptions{Feature: tt.feature})
+			result := DisableFeature(context.Background(), FeatureOperationOptions{Feature: tt.feature})
 			if result.Feature != tt.feature {
 				t.Errorf("Feature = %v, want %v", result.Feature, tt.feature)
 			}
@@ -136,7 +137,7 @@ func TestDisableResultStructure(t *testing.T) {
 func TestDisableUnknownFeature(t *testing.T) {
 	invalidFeature := Feature(999)
 
-	result := DisableFeature(FeatureOperationOptions{Feature: invalidFeature})
+	result := DisableFeature(context.Background(), FeatureOperationOptions{Feature: invalidFeature})
 	if result.Feature != invalidFeature {
 		t.Errorf("Feature = %v, want %v", result.Feature, invalidFeature)
 	}
@@ -156,9 +157,9 @@ func TestDisableIdempotency(t *testing.T) {
 	feature := RemoteManagement
 
 	// First call
-	result1 := DisableFeature(FeatureOperationOptions{Feature: feature})
+	result1 := DisableFeature(context.Background(), FeatureOperationOptions{Feature: feature})
 	// Second call (should be idempotent)
-	result2 := DisableFeature(FeatureOperationOptions{Feature: feature})
+	result2 := DisableFeature(context.Background(), FeatureOperationOptions{Feature: feature})
 
 	// If the first call succeeded or indicated already disabled,
 	// the second call should indicate already disabled
@@ -1,7 +1,10 @@
 package operations
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 
 	"github.com/redhatinsights/rhc/internal/datacollection"
 	"github.com/redhatinsights/rhc/internal/remotemanagement"
@@ -46,7 +49,10 @@ const (
 //
 // The operation is idempotent: if the feature is already enabled, it returns
 // immediately with Status="already-enabled" and no error.
-func EnableFeature(opts FeatureOperationOptions) EnableFeatureResult {
+//
+// Enabling Analytics registers insights-client; callers have to make sure the
+// data collection notice of the organization was accepted before.
+func EnableFeature(ctx context.Context, opts FeatureOperationOptions) EnableFeatureResult {
 	result := EnableFeatureResult{
 		Feature:             opts.Feature,
 		Status:              EnableStatusFailed,
@@ -72,7 +78,7 @@ func EnableFeature(opts FeatureOperationOptions) EnableFeatureResult {
 		// No dependencies
 	case RemoteManagement:
 		// RemoteManagement requires Content and Analytics
-		contentResult := EnableFeature(FeatureOperationOptions{Feature: Content})
+		contentResult := EnableFeature(ctx, FeatureOperationOptions{Feature: Content})
 		result.DependenciesEnabled = append(result.DependenciesEnabled, contentResult)
 		if contentResult.Err != nil {
 			result.Err = fmt.Errorf("enabling dependency %s: %w", Content, contentResult.Err)
@@ -80,7 +86,7 @@ func EnableFeature(opts FeatureOperationOptions) EnableFeatureResult {
 			return result
 		}
 
-		analyticsResult := EnableFeature(FeatureOperationOptions{Feature: Analytics})
+		analyticsResult := EnableFeature(ctx, FeatureOperationOptions{Feature: Analytics})
 		result.DependenciesEnabled = append(result.DependenciesEnabled, analyticsResult)
 		if analyticsResult.Err != nil {
 			result.Err = fmt.Errorf("enabling dependency %s: %w", Analytics, analyticsResult.Err)
@@ -97,7 +103,7 @@ func EnableFeature(opts FeatureOperationOptions) EnableFeatureResult {
 	var err error
 	switch opts.Feature {
 	case Analytics:
-		err = datacollection.RegisterInsightsClient()
+		err = datacollection.RegisterInsightsClient(ctx, datacollection.InsightsRegistration{})
 	case Content:
 		var client *subman.RHSMClient
 		client, err = subman.NewRHSMClient()
@@ -105,7 +111,11 @@ func EnableFeature(opts FeatureOperationOptions) EnableFeatureResult {
 			err = client.SetContentManagement(true)
 		}
 	case RemoteManagement:
-		err = remotemanagement.ActivateServices()
+		err = remotemanagement.ActivateServices(ctx)
+		if errors.Is(err, remotemanagement.ErrBrokerUnverified) {
+			slog.Warn(err.Error())
+			err = nil
+		}
 	default:
 		err = fmt.Errorf("unknown feature: %s", opts.Feature)
 	}
@@ -1,13 +1,14 @@
 package operations
 
 import (
+	"context"
 	"strings"
 	"testing"
 )
 
 // TestEnableAnalytics tests enabling the Analytics feature with no dependencies.
 func TestEnableAnalytics(t *testing.T) {
-	result := EnableFeature(FeatureOperationOptions{Feature: Analytics})
+	result := EnableFeature(context.Background(), FeatureOperationOptions{Feature: Analytics})
 	if len(result.DependenciesEnabled) != 0 {
 		t.Errorf("expected 0 dependencies enabled, got %d: %v",
 			len(result.DependenciesEnabled), result.DependenciesEnabled)
@@ -31,7 +32,7 @@ func TestEnableAnalytics(t *testing.T) {
 
 // TestEnableContent tests enabling the Content feature with no dependencies.
 func TestEnableContent(t *testing.T) {
-	result := EnableFeature(FeatureOperationOptions{Feature: Content})
+	result := EnableFeature(context.Background(), FeatureOperationOptions{Feature: Content})
 	if len(result.DependenciesEnabled) != 0 {
 		t.Errorf("expected 0 dependencies enabled, got %d: %v",
 			len(result.DependenciesEnabled), result.DependenciesEnabled)
@@ -55,7 +56,7 @@ func TestEnableContent(t *testing.T) {
 
 // TestEnableRemoteManagement tests enabling RemoteManagement which requires both Content and Analytics dependencies.
 func TestEnableRemoteManagement(t *testing.T) {
-	result := EnableFeature(FeatureOperationOptions{Feature: RemoteManagement})
+	result := EnableFeature(context.Background(), FeatureOperationOptions{Feature: RemoteManagement})
 	if result.Feature != RemoteManagement {
 		t.Errorf("expected feature to be RemoteManagement, got %v", result.Feature)
 	}
@@ -83,7 +84,7 @@ func TestEnableResultStructure(t *testing.T) {
 
 	for _, feature := range features {
 		t.Run(feature.String(), func(t *testing.T) {
-			result := EnableFeature(FeatureOperationOptions{Feature: feature})
+			result := EnableFeature(context.Background(), FeatureOperationOptions{Feature: feature})
 			if result.Feature != feature {
 				t.Errorf("expected Feature=%v, got %v", feature, result.Feature)
 			}
@@ -116,7 +117,7 @@ func TestEnableResultStructure(t *testing.T) {
 func TestEnableUnknownFeature(t *testing.T) {
 	invalidFeature := Feature(999)
 
-	result := EnableFeature(FeatureOperationOptions{Feature: invalidFeature})
+	result := EnableFeature(context.Background(), FeatureOperationOptions{Feature: invalidFeature})
 	if result.Err == nil {
 		t.Error("expected error for unknown feature, got nil")
 	}